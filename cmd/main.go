@@ -4,33 +4,92 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
 	"os"
+	"reflect"
+	"sort"
+	"sync"
 	"time"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
 	"github.com/vmware-tanzu/nsx-operator/pkg/config"
 	commonctl "github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/configuration"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/gctrigger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/health"
+	ipaddressallocationcontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/ipaddressallocation"
+	ippoolcontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/ippool"
+	lbvipcontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/lbvip"
+	networkinfocontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/networkinfo"
 	nsxserviceaccountcontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/nsxserviceaccount"
+	pausecontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/pause"
+	prefixlistcontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/prefixlist"
+	routeadvertisementcontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/routeadvertisement"
+	routemapcontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/routemap"
 	securitypolicycontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/securitypolicy"
+	staticroutecontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/staticroute"
+	subnetcontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/subnet"
+	subnetportcontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/subnetport"
+	subnetsetcontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/subnetset"
+	vpccontroller "github.com/vmware-tanzu/nsx-operator/pkg/controllers/vpc"
+	"github.com/vmware-tanzu/nsx-operator/pkg/debug"
 	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
 	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/audit"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/configcheck"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipaddressallocation"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ippool"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/lbvip"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/nsxserviceaccount"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/routeadvertisement"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/routemap"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/securitypolicy"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/staticroute"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/subnet"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/subnetport"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+	"github.com/vmware-tanzu/nsx-operator/pkg/tracing"
+	"github.com/vmware-tanzu/nsx-operator/pkg/webhook"
+	cidrwebhook "github.com/vmware-tanzu/nsx-operator/pkg/webhook/cidr"
+	defaultingwebhook "github.com/vmware-tanzu/nsx-operator/pkg/webhook/defaulting"
+	deletionwebhook "github.com/vmware-tanzu/nsx-operator/pkg/webhook/deletion"
+	namespacewebhook "github.com/vmware-tanzu/nsx-operator/pkg/webhook/namespace"
+	securitypolicywebhook "github.com/vmware-tanzu/nsx-operator/pkg/webhook/securitypolicy"
+	staticroutewebhook "github.com/vmware-tanzu/nsx-operator/pkg/webhook/staticroute"
+)
+
+const (
+	// credentialCheckInterval is the interval to check if the client certificates and the NSX credentials are rotated.
+	credentialCheckInterval = time.Minute
+	// gcReportInterval is the interval to report the runs of the garbage collectors in the NSXOperatorHealth CR.
+	gcReportInterval = time.Minute
+	// validateConfigCommand checks the config instead of starting NSX Operator, e.g. in an init container.
+	validateConfigCommand = "validate-config"
+	// dumpStoresCommand prints the dump of the debug endpoint of the NSX Operator running in the pod, e.g. with
+	// kubectl exec for the support bundles.
+	dumpStoresCommand = "dump-stores"
 )
 
 var (
 	scheme                 = runtime.NewScheme()
 	probeAddr, metricsAddr string
+	debugAddr              string
 	log                    = logger.Log
 	cf                     *config.NSXOperatorConfig
 )
@@ -38,21 +97,38 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8384", "The address the probe endpoint binds to.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8093", "The address the metrics endpoint binds to.")
+	flag.StringVar(&debugAddr, "debug-bind-address", "127.0.0.1:8385", "The address the debug endpoints bind to, 0 disables them.")
 	config.AddFlags()
 	flag.Parse()
 	var err error
 
 	logf.SetLogger(logger.ZapLogger())
+	if flag.Arg(0) == validateConfigCommand || flag.Arg(0) == dumpStoresCommand {
+		// the config is loaded and checked by the command, or not needed
+		return
+	}
 	cf, err = config.NewNSXOperatorConfigFromFile()
 	if err != nil {
 		log.Error(err, "load config file error")
 		os.Exit(1)
 	}
 
+	logger.SetLogLevel(cf.LogLevel)
+	moduleLogLevels, _ := cf.GetModuleLogLevels()
+	logger.SetModuleLogLevels(moduleLogLevels)
+	logger.SetDedupInterval(time.Duration(cf.LogDedupInterval) * time.Second)
+	common.SetGCInterval(time.Duration(cf.GCInterval) * time.Second)
+	commonctl.SetGCDryRun(cf.GCDryRun, cf.GCDryRunResources)
+	commonctl.SetGCDeletionLimits(cf.GCMaxDeletions, cf.GCMaxDeletionPercent)
+	common.SetStoreSnapshotDir(cf.StoreSnapshotDir)
+
+	log.Info("feature gates", "gates", config.DefaultFeatureGates.String())
 	if metrics.AreMetricsExposed(cf) {
 		metrics.InitializePrometheusMetrics()
+		metrics.UpdateFeatureGateMetrics(config.DefaultFeatureGates)
 	}
 }
 
@@ -92,61 +168,985 @@ func StartNSXServiceAccountController(mgr ctrl.Manager, commonService common.Ser
 	}
 }
 
+func StartVPCController(mgr ctrl.Manager, commonService common.Service) {
+	log.Info("starting VPCController")
+	commonctl.RequireCRDs(mgr.GetScheme(), &v1alpha1.VPCNetworkConfiguration{})
+	vpcReconcile := &vpccontroller.VPCReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("vpc-controller"),
+	}
+	if vpcService, err := vpc.InitializeVPC(commonService); err != nil {
+		log.Error(err, "failed to initialize service", "controller", "VPC")
+		os.Exit(1)
+	} else {
+		vpcReconcile.Service = vpcService
+		commonctl.ServiceMediator.VPCService = vpcService
+	}
+	if err := vpcReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "VPC")
+		os.Exit(1)
+	}
+}
+
+func StartSubnetController(mgr ctrl.Manager, commonService common.Service) *subnet.SubnetService {
+	log.Info("starting SubnetController")
+	subnetReconcile := &subnetcontroller.SubnetReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("subnet-controller"),
+	}
+	if subnetService, err := subnet.InitializeSubnetService(commonService); err != nil {
+		log.Error(err, "failed to initialize service", "controller", "Subnet")
+		os.Exit(1)
+	} else {
+		subnetReconcile.Service = subnetService
+	}
+	if err := subnetReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "Subnet")
+		os.Exit(1)
+	}
+	return subnetReconcile.Service
+}
+
+func StartSubnetSetController(mgr ctrl.Manager, subnetService *subnet.SubnetService) {
+	log.Info("starting SubnetSetController")
+	subnetSetReconcile := &subnetsetcontroller.SubnetSetReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Service:  subnetService,
+		Recorder: mgr.GetEventRecorderFor("subnetset-controller"),
+	}
+	if err := subnetSetReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "SubnetSet")
+		os.Exit(1)
+	}
+}
+
+func StartSubnetPortController(mgr ctrl.Manager, commonService common.Service, subnetService *subnet.SubnetService) {
+	log.Info("starting SubnetPortController")
+	subnetPortReconcile := &subnetportcontroller.SubnetPortReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		SubnetService: subnetService,
+		Recorder:      mgr.GetEventRecorderFor("subnetport-controller"),
+	}
+	if subnetPortService, err := subnetport.InitializeSubnetPort(commonService); err != nil {
+		log.Error(err, "failed to initialize service", "controller", "SubnetPort")
+		os.Exit(1)
+	} else {
+		subnetPortReconcile.Service = subnetPortService
+	}
+	if err := subnetPortReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "SubnetPort")
+		os.Exit(1)
+	}
+}
+
+func StartIPPoolController(mgr ctrl.Manager, commonService common.Service) *ippool.IPPoolService {
+	log.Info("starting IPPoolController")
+	ipPoolReconcile := &ippoolcontroller.IPPoolReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("ippool-controller"),
+	}
+	if ipPoolService, err := ippool.InitializeIPPool(commonService); err != nil {
+		log.Error(err, "failed to initialize service", "controller", "IPPool")
+		os.Exit(1)
+	} else {
+		ipPoolReconcile.Service = ipPoolService
+	}
+	if err := ipPoolReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "IPPool")
+		os.Exit(1)
+	}
+	return ipPoolReconcile.Service
+}
+
+func StartIPAddressAllocationController(mgr ctrl.Manager, commonService common.Service, ipPoolService *ippool.IPPoolService) {
+	log.Info("starting IPAddressAllocationController")
+	ipAllocationReconcile := &ipaddressallocationcontroller.IPAddressAllocationReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		IPPoolService: ipPoolService,
+		Recorder:      mgr.GetEventRecorderFor("ipaddressallocation-controller"),
+	}
+	if ipAllocationService, err := ipaddressallocation.InitializeIPAddressAllocation(commonService); err != nil {
+		log.Error(err, "failed to initialize service", "controller", "IPAddressAllocation")
+		os.Exit(1)
+	} else {
+		ipAllocationReconcile.Service = ipAllocationService
+	}
+	if err := ipAllocationReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "IPAddressAllocation")
+		os.Exit(1)
+	}
+}
+
+func StartStaticRouteController(mgr ctrl.Manager, commonService common.Service, nsxManagerServices map[string]common.Service) {
+	log.Info("starting StaticRouteController")
+	staticRouteReconcile := &staticroutecontroller.StaticRouteReconciler{
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		NSXManagerServices: map[string]*staticroute.StaticRouteService{},
+	}
+	if staticRouteService, err := staticroute.InitializeStaticRoute(commonService); err != nil {
+		log.Error(err, "failed to initialize service", "controller", "StaticRoute")
+		os.Exit(1)
+	} else {
+		staticRouteReconcile.Service = staticRouteService
+	}
+	for name, nsxManagerService := range nsxManagerServices {
+		if staticRouteService, err := staticroute.InitializeStaticRoute(nsxManagerService); err != nil {
+			log.Error(err, "failed to initialize service", "controller", "StaticRoute", "nsxManager", name)
+			os.Exit(1)
+		} else {
+			staticRouteReconcile.NSXManagerServices[name] = staticRouteService
+		}
+	}
+	if err := staticRouteReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "StaticRoute")
+		os.Exit(1)
+	}
+}
+
+func StartRouteAdvertisementController(mgr ctrl.Manager, commonService common.Service) {
+	log.Info("starting RouteAdvertisementController")
+	routeAdvertisementReconcile := &routeadvertisementcontroller.RouteAdvertisementReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+	if routeAdvertisementService, err := routeadvertisement.InitializeRouteAdvertisement(commonService); err != nil {
+		log.Error(err, "failed to initialize service", "controller", "RouteAdvertisement")
+		os.Exit(1)
+	} else {
+		routeAdvertisementReconcile.Service = routeAdvertisementService
+	}
+	if err := routeAdvertisementReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "RouteAdvertisement")
+		os.Exit(1)
+	}
+}
+
+func StartPrefixListController(mgr ctrl.Manager, commonService common.Service) *routemap.RouteMapService {
+	log.Info("starting PrefixListController")
+	prefixListReconcile := &prefixlistcontroller.PrefixListReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+	if routeMapService, err := routemap.InitializeRouteMap(commonService); err != nil {
+		log.Error(err, "failed to initialize service", "controller", "PrefixList")
+		os.Exit(1)
+	} else {
+		prefixListReconcile.Service = routeMapService
+	}
+	if err := prefixListReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "PrefixList")
+		os.Exit(1)
+	}
+	return prefixListReconcile.Service
+}
+
+func StartRouteMapController(mgr ctrl.Manager, routeMapService *routemap.RouteMapService) {
+	log.Info("starting RouteMapController")
+	routeMapReconcile := &routemapcontroller.RouteMapReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Service: routeMapService,
+	}
+	if err := routeMapReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "RouteMap")
+		os.Exit(1)
+	}
+}
+
+func StartLBVIPController(mgr ctrl.Manager, commonService common.Service) {
+	log.Info("starting LBVIPController")
+	lbVIPReconcile := &lbvipcontroller.LBVIPReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("lbvip-controller"),
+	}
+	if lbVIPService, err := lbvip.InitializeLBVIP(commonService); err != nil {
+		log.Error(err, "failed to initialize service", "controller", "LBVIP")
+		os.Exit(1)
+	} else {
+		lbVIPReconcile.Service = lbVIPService
+	}
+	if err := lbVIPReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "LBVIP")
+		os.Exit(1)
+	}
+}
+
+func StartCIDRWebhook(mgr ctrl.Manager, cf *config.NSXOperatorConfig) {
+	log.Info("starting CIDR validating webhook")
+	validator, err := cidrwebhook.NewValidator(cf, mgr.GetScheme())
+	if err != nil {
+		log.Error(err, "failed to initialize webhook", "webhook", "CIDR")
+		os.Exit(1)
+	}
+	if err := validator.SetupWithManager(mgr); err != nil {
+		log.Error(err, "failed to create webhook", "webhook", "CIDR")
+		os.Exit(1)
+	}
+}
+
+func StartStaticRouteWebhook(mgr ctrl.Manager) {
+	log.Info("starting StaticRoute validating webhook")
+	validator, err := staticroutewebhook.NewValidator(mgr.GetScheme(), mgr.GetClient())
+	if err != nil {
+		log.Error(err, "failed to initialize webhook", "webhook", "StaticRoute")
+		os.Exit(1)
+	}
+	if err := validator.SetupWithManager(mgr); err != nil {
+		log.Error(err, "failed to create webhook", "webhook", "StaticRoute")
+		os.Exit(1)
+	}
+}
+
+func StartDefaultingWebhook(mgr ctrl.Manager, cf *config.NSXOperatorConfig) {
+	log.Info("starting defaulting webhook")
+	defaulter, err := defaultingwebhook.NewDefaulter(cf, mgr.GetScheme())
+	if err != nil {
+		log.Error(err, "failed to initialize webhook", "webhook", "Defaulting")
+		os.Exit(1)
+	}
+	if err := defaulter.SetupWithManager(mgr); err != nil {
+		log.Error(err, "failed to create webhook", "webhook", "Defaulting")
+		os.Exit(1)
+	}
+}
+
+func StartSecurityPolicyWebhook(mgr ctrl.Manager) {
+	log.Info("starting SecurityPolicy validating webhook")
+	commonctl.RequireCRDs(mgr.GetScheme(), &v1alpha1.SecurityPolicyPriorityRange{})
+	validator, err := securitypolicywebhook.NewValidator(mgr.GetScheme(), mgr.GetClient())
+	if err != nil {
+		log.Error(err, "failed to initialize webhook", "webhook", "SecurityPolicy")
+		os.Exit(1)
+	}
+	if err := validator.SetupWithManager(mgr); err != nil {
+		log.Error(err, "failed to create webhook", "webhook", "SecurityPolicy")
+		os.Exit(1)
+	}
+}
+
+func StartSecurityPolicyPreviewWebhook(mgr ctrl.Manager) {
+	log.Info("starting SecurityPolicy realization preview webhook")
+	previewer, err := securitypolicywebhook.NewPreviewer(mgr.GetScheme(), commonctl.ServiceMediator.SecurityPolicyService)
+	if err != nil {
+		log.Error(err, "failed to initialize webhook", "webhook", "SecurityPolicyPreview")
+		os.Exit(1)
+	}
+	if err := previewer.SetupWithManager(mgr); err != nil {
+		log.Error(err, "failed to create webhook", "webhook", "SecurityPolicyPreview")
+		os.Exit(1)
+	}
+}
+
+func StartImmutableWebhook(mgr ctrl.Manager) {
+	log.Info("starting immutable fields validating webhook")
+	webhook.RegisterImmutableWebhook(mgr)
+}
+
+func StartNamespaceWebhook(mgr ctrl.Manager, cf *config.NSXOperatorConfig) {
+	log.Info("starting Namespace validating webhook")
+	validator, err := namespacewebhook.NewValidator(cf, mgr.GetScheme(), mgr.GetClient())
+	if err != nil {
+		log.Error(err, "failed to initialize webhook", "webhook", "Namespace")
+		os.Exit(1)
+	}
+	if err := validator.SetupWithManager(mgr); err != nil {
+		log.Error(err, "failed to create webhook", "webhook", "Namespace")
+		os.Exit(1)
+	}
+}
+
+func StartDeletionWebhook(mgr ctrl.Manager) {
+	log.Info("starting deletion validating webhook")
+	if err := deletionwebhook.NewValidator(mgr.GetClient()).SetupWithManager(mgr); err != nil {
+		log.Error(err, "failed to create webhook", "webhook", "Deletion")
+		os.Exit(1)
+	}
+}
+
+func StartNetworkInfoController(mgr ctrl.Manager, vpcService *vpc.VPCService) {
+	log.Info("starting NetworkInfoController")
+	commonctl.RequireCRDs(mgr.GetScheme(), &v1alpha1.VPC{})
+	networkInfoReconcile := &networkinfocontroller.NetworkInfoReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Service: vpcService,
+	}
+	if err := networkInfoReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "NetworkInfo")
+		os.Exit(1)
+	}
+}
+
+func StartNSXOperatorConfigurationController(mgr ctrl.Manager, apply configuration.ApplyFunc) {
+	log.Info("starting NSXOperatorConfigurationController")
+	configurationReconcile := &configuration.NSXOperatorConfigurationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Apply:  apply,
+	}
+	if err := configurationReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "NSXOperatorConfiguration")
+		os.Exit(1)
+	}
+}
+
+func StartGCTriggerController(mgr ctrl.Manager) {
+	gcTriggerReconcile := &gctrigger.GCTriggerReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+	if err := gcTriggerReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "GCTrigger")
+		os.Exit(1)
+	}
+}
+
+func StartPauseController(mgr ctrl.Manager, commonService common.Service) {
+	pauseReconcile := &pausecontroller.PauseReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		NSXConfig: commonService.NSXConfig,
+	}
+	// Load the pause switch before the controllers mutating NSX are started.
+	if err := pauseReconcile.LoadPauseState(context.Background(), mgr.GetAPIReader()); err != nil {
+		log.Error(err, "failed to load pause state", "controller", "Pause")
+		os.Exit(1)
+	}
+	if err := pauseReconcile.Start(mgr); err != nil {
+		log.Error(err, "failed to create controller", "controller", "Pause")
+		os.Exit(1)
+	}
+}
+
+// prefetchResourceTypes returns the resource types of the stores of the security policy and the VPC networking
+// controllers which are not disabled.
+func prefetchResourceTypes(cf *config.NSXOperatorConfig) []string {
+	var resourceTypes []string
+	if cf.ControllerEnabled(config.ControllerSecurityPolicy) {
+		resourceTypes = append(resourceTypes, common.ResourceTypeSecurityPolicy, common.ResourceTypeGroup, common.ResourceTypeRule)
+	}
+	if !cf.EnableVPCNetwork || !config.DefaultFeatureGates.Enabled(config.FeatureVPCNetworking) {
+		return resourceTypes
+	}
+	for _, controller := range []struct {
+		name          string
+		resourceTypes []string
+	}{
+		{config.ControllerVPC, []string{common.ResourceTypeVPC}},
+		{config.ControllerSubnet, []string{common.ResourceTypeSubnet}},
+		{config.ControllerSubnetPort, []string{common.ResourceTypeSubnetPort}},
+		{config.ControllerIPPool, []string{common.ResourceTypeIPPool, common.ResourceTypeIPPoolSubnet}},
+		{config.ControllerIPAddressAllocation, []string{common.ResourceTypeIPAllocation, common.ResourceTypeVPCIPAllocation}},
+	} {
+		if cf.ControllerEnabled(controller.name) {
+			resourceTypes = append(resourceTypes, controller.resourceTypes...)
+		}
+	}
+	return resourceTypes
+}
+
 func main() {
+	if flag.Arg(0) == validateConfigCommand {
+		os.Exit(validateConfig())
+	}
+	if flag.Arg(0) == dumpStoresCommand {
+		if err := debug.Fetch(debugAddr, os.Stdout); err != nil {
+			log.Error(err, "failed to dump the stores", "address", debugAddr)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	log.Info("starting NSX Operator")
 
+	// Every CR is reconciled again every cr_resync_period even if it doesn't change, 10 hours by default.
+	var syncPeriod *time.Duration
+	if cf.CRResyncPeriod > 0 {
+		period := time.Duration(cf.CRResyncPeriod) * time.Second
+		syncPeriod = &period
+	}
+	// Only the leader reconciles the CRs and runs the garbage collectors if enable_leader_election is set, the other
+	// replicas take over once its Lease expires, or at once if it stops gracefully and releases the Lease.
+	leaseDuration, renewDeadline, retryPeriod := cf.GetLeaderElectionDurations()
+	// The manager waits for the reconciles and the NSX writes in flight to drain and for the snapshots of the stores
+	// before it returns, see GracefulShutdown.
+	shutdownTimeout := cf.GetShutdownGracePeriod() + commonctl.ShutdownSnapshotTimeout
+	// The active NSX Operator among the ones of the management clusters sharing NSX is elected through the heartbeat
+	// in NSX instead of a Lease if enable_standby is set, the standby ones keep their stores synced.
+	var heartbeatLock *common.HeartbeatLock
+	var resourceLock resourcelock.Interface
+	if cf.EnableStandby {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Error(err, "failed to get the identity of the heartbeat")
+			os.Exit(1)
+		}
+		heartbeatLock = common.NewHeartbeatLock(cf.GetStandbyHeartbeatGroup(), cf.Cluster+"/"+hostname)
+		resourceLock = heartbeatLock
+	}
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		HealthProbeBindAddress: probeAddr,
-		MetricsBindAddress:     metricsAddr,
-		LeaderElectionID:       "nsx-operator",
+		Scheme:                              scheme,
+		SyncPeriod:                          syncPeriod,
+		HealthProbeBindAddress:              probeAddr,
+		MetricsBindAddress:                  metricsAddr,
+		LeaderElection:                      cf.EnableLeaderElection || cf.EnableStandby,
+		LeaderElectionResourceLockInterface: resourceLock,
+		LeaderElectionNamespace:             cf.GetLeaderElectionNamespace(),
+		LeaderElectionID:                    cf.GetLeaderElectionID(),
+		LeaderElectionReleaseOnCancel:       true,
+		LeaseDuration:                       &leaseDuration,
+		RenewDeadline:                       &renewDeadline,
+		RetryPeriod:                         &retryPeriod,
+		GracefulShutdownTimeout:             &shutdownTimeout,
+		NewCache:                            cache.BuilderWithOptions(cache.Options{SelectorsByObject: pausecontroller.CacheSelectors(cf)}),
 	})
 	if err != nil {
 		log.Error(err, "failed to init manager")
 		os.Exit(1)
 	}
 
+	// Wait for the CRDs and NSX before the stores are listed and the controllers are started instead of failing on the
+	// errors of the informers and of NSX.
+	ctx := ctrl.SetupSignalHandler()
+	startupCtx := ctx
+	if cf.StartupTimeout > 0 {
+		var cancelStartup context.CancelFunc
+		startupCtx, cancelStartup = context.WithTimeout(ctx, time.Duration(cf.StartupTimeout)*time.Second)
+		defer cancelStartup()
+	}
+	commonctl.RequireCRDs(scheme, &v1alpha1.NSXOperatorConfiguration{}, &v1alpha1.NSXOperatorHealth{})
+	if err := commonctl.WaitForCRDs(startupCtx, mgr.GetAPIReader()); err != nil {
+		log.Error(err, "failed to wait for CRDs")
+		os.Exit(1)
+	}
+
+	// Load the options of the NSXOperatorConfiguration CR before the NSX clients and the controllers are created,
+	// the cache is not available before the manager starts.
+	if loaded, err := configuration.LoadOverrides(context.Background(), mgr.GetAPIReader()); err != nil {
+		log.Error(err, "failed to load NSXOperatorConfiguration, the config file is used")
+	} else if loaded {
+		if cf, err = config.NewNSXOperatorConfigFromFile(); err != nil {
+			log.Error(err, "load config error")
+			os.Exit(1)
+		}
+	}
+
+	// Trace the reconciles and the NSX requests sent for them if an OTLP endpoint is configured, the spans not exported
+	// yet are flushed when the manager stops.
+	shutdownTracing := tracing.Init(cf)
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error(err, "failed to flush traces")
+		}
+	}()
+
 	// nsxClient is used to interact with NSX API.
-	nsxClient := nsx.GetClient(cf)
+	nsxClient := getNSXClient(cf, mgr.GetAPIReader())
 	if nsxClient == nil {
 		log.Error(err, "failed to get nsx client")
 		os.Exit(1)
 	}
+	nsxClients := []*nsx.Client{nsxClient}
+	if heartbeatLock != nil {
+		heartbeatLock.SetNSXClient(nsxClient)
+	}
 
 	//  Embed the common commonService to sub-services.
 	var commonService = common.Service{
-		Client:    mgr.GetClient(),
-		NSXClient: nsxClient,
-		NSXConfig: cf,
+		Client:     mgr.GetClient(),
+		NSXClient:  nsxClient,
+		NSXConfig:  cf,
+		WriteQueue: common.NewWriteQueue(cf),
+	}
+
+	// Embed the NSX clients of the additional NSX managers to the services of the controllers which program the CRs
+	// on the NSX manager selecting their Namespaces.
+	nsxManagerServices := map[string]common.Service{}
+	for name := range cf.NSXManagers {
+		nsxManagerConfig := cf.ForNSXManager(name)
+		nsxManagerClient := getNSXClient(nsxManagerConfig, mgr.GetAPIReader())
+		nsxClients = append(nsxClients, nsxManagerClient)
+		nsxManagerServices[name] = common.Service{
+			Client:     mgr.GetClient(),
+			NSXClient:  nsxManagerClient,
+			NSXConfig:  nsxManagerConfig,
+			WriteQueue: common.NewWriteQueue(nsxManagerConfig),
+		}
+	}
+	if err := commonctl.WaitForNSX(startupCtx, nsxClients...); err != nil {
+		log.Error(err, "failed to wait for NSX")
+		os.Exit(1)
+	}
+	// List the NSX resources of the largest stores in parallel while the services are initialized one after another.
+	commonService.PrefetchResources(cf.StorePrefetchWorkers, prefetchResourceTypes(cf)...)
+	// Register the rotated Principal Identity certificates, and re-establish the sessions with the rotated NSX
+	// credentials NSX Operator authenticates with.
+	go rotateCredentialsPeriodically(nsxClients)
+
+	// Apply the config changes which take effect without restarting NSX Operator when the config file changes, and
+	// report the ones which don't in the NSXOperatorHealth CR.
+	// The config is reloaded by both the config file watcher and the NSXOperatorConfiguration controller.
+	runningConfig := cf
+	var applyLock sync.Mutex
+	applyConfig := func(newConfig *config.NSXOperatorConfig) []string {
+		applyLock.Lock()
+		defer applyLock.Unlock()
+		reloadNSXManagers(nsxClient, newConfig)
+		applyReloadableConfig(nsxClients, runningConfig, newConfig)
+		runningConfig = newConfig
+		changes := config.RestartRequiredChanges(cf, newConfig)
+		if len(changes) > 0 {
+			log.Info("config changes are not applied until NSX Operator is restarted", "options", changes)
+		}
+		if err := health.ReportConfigReload(context.TODO(), mgr.GetClient(), changes); err != nil {
+			log.Error(err, "failed to report the config reload")
+		}
+		return changes
 	}
+	go config.WatchConfigFile(ctx.Done(), config.WatchInterval, func(newConfig *config.NSXOperatorConfig) {
+		applyConfig(newConfig)
+	})
+	// Apply the options of the NSXOperatorConfiguration CR over the config file when it changes.
+	StartNSXOperatorConfigurationController(mgr, applyConfig)
+
+	// Report the runs of the garbage collectors in the NSXOperatorHealth CR and the NSXOrphanReport CR.
+	go reportGarbageCollectionPeriodically(mgr.GetClient())
+	// Trigger the garbage collectors the NSXOperatorHealth CR is annotated with.
+	StartGCTriggerController(mgr)
 
+	// Start the pause controller which watches the global NSX mutation pause switch.
+	StartPauseController(mgr, commonService)
 	// Start the security policy controller.
-	StartSecurityPolicyController(mgr, commonService)
+	if cf.ControllerEnabled(config.ControllerSecurityPolicy) {
+		StartSecurityPolicyController(mgr, commonService)
+	}
 	// Start the NSXServiceAccount controller.
-	if cf.EnableAntreaNSXInterworking {
+	if cf.EnableAntreaNSXInterworking && cf.ControllerEnabled(config.ControllerNSXServiceAccount) {
 		StartNSXServiceAccountController(mgr, commonService)
 	}
+	// Start the VPC, Subnet, SubnetSet, SubnetPort, IPPool, IPAddressAllocation and NetworkInfo controllers which are
+	// not disabled, the controllers they depend on can't be disabled.
+	if cf.EnableVPCNetwork && config.DefaultFeatureGates.Enabled(config.FeatureVPCNetworking) {
+		if cf.ControllerEnabled(config.ControllerVPC) {
+			StartVPCController(mgr, commonService)
+		}
+		if cf.ControllerEnabled(config.ControllerSubnet) {
+			subnetService := StartSubnetController(mgr, commonService)
+			if cf.ControllerEnabled(config.ControllerSubnetSet) {
+				StartSubnetSetController(mgr, subnetService)
+			}
+			if cf.ControllerEnabled(config.ControllerSubnetPort) {
+				StartSubnetPortController(mgr, commonService, subnetService)
+			}
+		}
+		if cf.ControllerEnabled(config.ControllerIPPool) {
+			ipPoolService := StartIPPoolController(mgr, commonService)
+			if cf.ControllerEnabled(config.ControllerIPAddressAllocation) {
+				StartIPAddressAllocationController(mgr, commonService, ipPoolService)
+			}
+		}
+		if cf.ControllerEnabled(config.ControllerNetworkInfo) {
+			StartNetworkInfoController(mgr, commonctl.ServiceMediator.VPCService)
+		}
+	}
+	// Start the StaticRoute controller which programs the static routes on the VPCs or the Tier-1 and Tier-0 gateways.
+	staticRouteEnabled := config.DefaultFeatureGates.Enabled(config.FeatureStaticRoutes) && cf.ControllerEnabled(config.ControllerStaticRoute)
+	if (cf.EnableVPCNetwork || cf.Tier1Gateway != "" || cf.Tier0Gateway != "") && staticRouteEnabled {
+		StartStaticRouteController(mgr, commonService, nsxManagerServices)
+	}
+	// Start the RouteAdvertisement controller which configures the route advertisement of the Tier-1 gateway.
+	if cf.Tier1Gateway != "" && config.DefaultFeatureGates.Enabled(config.FeatureRoutingPolicies) && cf.ControllerEnabled(config.ControllerRouteAdvertisement) {
+		StartRouteAdvertisementController(mgr, commonService)
+	}
+	// Start the PrefixList and RouteMap controllers which share the service managing the Tier-0 routing policies.
+	if cf.Tier0Gateway != "" && config.DefaultFeatureGates.Enabled(config.FeatureRoutingPolicies) && cf.ControllerEnabled(config.ControllerPrefixList) {
+		routeMapService := StartPrefixListController(mgr, commonService)
+		if cf.ControllerEnabled(config.ControllerRouteMap) {
+			StartRouteMapController(mgr, routeMapService)
+		}
+	}
+
+	// Start the LBVIP controller which allocates the VIPs of the LoadBalancer Services from the LB VIP pools.
+	if len(cf.LBVIPPools) > 0 && config.DefaultFeatureGates.Enabled(config.FeatureLBVIPAllocation) && cf.ControllerEnabled(config.ControllerLBVIP) {
+		StartLBVIPController(mgr, commonService)
+	}
+	// All the stores are initialized, release the NSX resources prefetched for the ones of the controllers not started.
+	common.ReleasePrefetches()
+	// The webhooks admit the requests of the excluded Namespaces and the bypassed objects without handling them.
+	webhook.SetGuard(webhook.NewGuard(cf, mgr.GetClient()))
+	// Start the webhook which rejects the Subnets, IPPools and StaticRoutes with overlapping CIDRs, the CRs of the
+	// disabled controllers are neither watched nor validated.
+	webhookEnabled := false
+	if cf.EnableCIDRWebhook && (cf.ControllerEnabled(config.ControllerSubnet) || cf.ControllerEnabled(config.ControllerIPPool) || staticRouteEnabled) {
+		StartCIDRWebhook(mgr, cf)
+		webhookEnabled = true
+	}
+	// Start the webhook which rejects the StaticRoutes conflicting with the routes of their Namespace.
+	if cf.EnableStaticRouteWebhook && staticRouteEnabled {
+		StartStaticRouteWebhook(mgr)
+		webhookEnabled = true
+	}
+	// Start the webhook which fills in the defaults of the fields omitted in the CRs.
+	if cf.EnableDefaultingWebhook {
+		StartDefaultingWebhook(mgr, cf)
+		webhookEnabled = true
+	}
+
+	// Start the webhook which rejects the SecurityPolicies with priorities out of the ranges of their Namespaces.
+	if cf.EnableSecurityPolicyWebhook && cf.ControllerEnabled(config.ControllerSecurityPolicy) {
+		StartSecurityPolicyWebhook(mgr)
+		webhookEnabled = true
+	}
+	// Start the webhook which annotates the SecurityPolicies of the dry-run requests with their realization preview.
+	if cf.EnableSecurityPolicyPreviewWebhook && cf.ControllerEnabled(config.ControllerSecurityPolicy) {
+		StartSecurityPolicyPreviewWebhook(mgr)
+		webhookEnabled = true
+	}
+	// Start the webhook which rejects the Namespaces with invalid network selections.
+	if cf.EnableNamespaceWebhook {
+		StartNamespaceWebhook(mgr, cf)
+		webhookEnabled = true
+	}
+
+	// Start the webhook which rejects the deletion of the CRs other CRs depend on.
+	if cf.EnableDeletionWebhook {
+		StartDeletionWebhook(mgr)
+		webhookEnabled = true
+	}
+	// Start the webhook which rejects the updates of the immutable fields of the CRs, the other webhooks reject them
+	// too for the CRs they validate.
+	if cf.EnableImmutableWebhook {
+		StartImmutableWebhook(mgr)
+		webhookEnabled = true
+	}
 
 	if metrics.AreMetricsExposed(cf) {
 		go updateHealthMetricsPeriodically(nsxClient)
 	}
 
-	if err := mgr.AddHealthzCheck("healthz", nsxClient.NSXChecker.CheckNSXHealth); err != nil {
+	// Serve the log levels which can be changed without restarting NSX Operator on the metrics server.
+	if err := mgr.AddMetricsExtraHandler(logger.LogLevelPath, logger.LogLevelHandler{}); err != nil {
+		log.Error(err, "failed to set up log level endpoint")
+		os.Exit(1)
+	}
+
+	// Record the NSX mutations as Events on the CRs initiating them besides the audit trail.
+	if cf.EnableAuditEvents {
+		audit.SetEventRecorder(mgr.GetEventRecorderFor("nsx-operator"))
+	}
+
+	// Record the NSX resources the garbage collectors in dry run would delete as Events on the NSXOperatorHealth CR.
+	commonctl.SetGCEventRecorder(mgr.GetEventRecorderFor("nsx-operator"))
+
+	// Run the garbage collectors registered by the controllers until the manager stops.
+	if err := mgr.Add(commonctl.GarbageCollectors{}); err != nil {
+		log.Error(err, "failed to set up garbage collectors")
+		os.Exit(1)
+	}
+
+	// Share the namespaces among the replicas holding a Lease, each one only reconciles the CRs of the namespaces it
+	// owns, identified by the hostname, i.e. the name of its pod.
+	if cf.EnableSharding {
+		identity, err := os.Hostname()
+		if err != nil {
+			log.Error(err, "failed to get the identity of the shard member")
+			os.Exit(1)
+		}
+		commonctl.EnableSharding(identity, mgr.GetClient())
+		if err := mgr.Add(&commonctl.ShardMembership{
+			Client:        mgr.GetClient(),
+			Reader:        mgr.GetAPIReader(),
+			Namespace:     cf.GetLeaderElectionNamespace(),
+			Identity:      identity,
+			LeaseDuration: leaseDuration,
+			RetryPeriod:   retryPeriod,
+		}); err != nil {
+			log.Error(err, "failed to set up sharding")
+			os.Exit(1)
+		}
+	}
+
+	// Resync the stores with the NSX resources modified since their last sync instead of listing them all again.
+	storeResyncInterval := common.StoreResyncInterval
+	if cf.StoreResyncInterval > 0 {
+		storeResyncInterval = time.Duration(cf.StoreResyncInterval) * time.Second
+	}
+	if err := mgr.Add(common.StoreResyncer{Interval: storeResyncInterval}); err != nil {
+		log.Error(err, "failed to set up store resync")
+		os.Exit(1)
+	}
+
+	// Snapshot the stores so that they are loaded from the snapshots when NSX Operator restarts.
+	if cf.StoreSnapshotDir != "" {
+		if err := mgr.Add(common.StoreSnapshotter{Interval: common.StoreSnapshotInterval}); err != nil {
+			log.Error(err, "failed to set up store snapshots")
+			os.Exit(1)
+		}
+	}
+
+	// Drain the reconciles and the NSX writes in flight when NSX Operator is asked to stop.
+	if err := mgr.Add(commonctl.GracefulShutdown{GracePeriod: cf.GetShutdownGracePeriod()}); err != nil {
+		log.Error(err, "failed to set up graceful shutdown")
+		os.Exit(1)
+	}
+
+	// Serve the dumps of the stores and the audit trail on the debug address, which is only reachable from the pod by
+	// default.
+	if debugAddr != "0" {
+		if err := mgr.Add(&debug.Server{Addr: debugAddr}); err != nil {
+			log.Error(err, "failed to set up debug endpoints")
+			os.Exit(1)
+		}
+	}
+
+	// The pod is restarted if a reconcile is stuck, it is not ready while NSX can't be programmed, the stores are not
+	// synced or the webhooks can't be served.
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		log.Error(err, "failed to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		log.Error(err, "failed to set up ready check")
+	stuckReconcileTimeout := time.Duration(cf.StuckReconcileTimeout) * time.Second
+	if err := mgr.AddHealthzCheck("reconciles", commonctl.StuckReconcileChecker(stuckReconcileTimeout)); err != nil {
+		log.Error(err, "failed to set up health check")
+		os.Exit(1)
+	}
+	readyChecks := map[string]healthz.Checker{
+		"readyz":      healthz.Ping,
+		"nsx-session": nsxClient.NSXChecker.CheckNSXSession,
+		"stores":      common.CheckStoresSynced,
+	}
+	if webhookEnabled {
+		readyChecks["webhook-cert"] = webhook.CertChecker(mgr.GetWebhookServer())
+	}
+	for name, check := range readyChecks {
+		if err := mgr.AddReadyzCheck(name, check); err != nil {
+			log.Error(err, "failed to set up ready check", "check", name)
+			os.Exit(1)
+		}
+	}
+
+	// Generate the webhook certificate, or take the one of the Secret, before the webhook server starts serving it, and
+	// rotate it before it expires.
+	if webhookEnabled && cf.WebhookCertSecret != "" {
+		certRotator := webhook.NewCertRotator(cf, mgr.GetWebhookServer(), mgr.GetClient(), mgr.GetAPIReader())
+		if err := certRotator.Ensure(startupCtx); err != nil {
+			log.Error(err, "failed to set up the webhook certificate")
+			os.Exit(1)
+		}
+		if err := mgr.Add(certRotator); err != nil {
+			log.Error(err, "failed to add the webhook certificate rotator")
+			os.Exit(1)
+		}
+	}
+	// Set the failure policy and the Namespace exclusions of the config in the webhook configurations.
+	if webhookEnabled && (cf.WebhookFailurePolicy != "" || len(cf.WebhookExcludedNamespaces) > 0) {
+		if err := mgr.Add(webhook.NewConfigurator(cf, mgr.GetClient(), mgr.GetAPIReader())); err != nil {
+			log.Error(err, "failed to add the webhook configurator")
+			os.Exit(1)
+		}
+	}
+
+	// The CRDs of the controllers set up are waited for before the manager starts their informers.
+	if err := commonctl.WaitForCRDs(startupCtx, mgr.GetAPIReader()); err != nil {
+		log.Error(err, "failed to wait for CRDs")
 		os.Exit(1)
 	}
 
 	log.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		log.Error(err, "failed to start manager")
+		shutdownTracing(context.Background())
 		os.Exit(1)
 	}
 }
 
+// getNSXClient creates the NSX client authenticating with the Principal Identity certificate, or the credentials of the
+// credential Secret or Vault if one is configured, and reaching NSX through the proxy with the credentials of the proxy
+// auth Secret if one is configured.
+func getNSXClient(cf *config.NSXOperatorConfig, reader client.Reader) *nsx.Client {
+	opts, err := nsxClientOptions(cf, reader)
+	if err != nil {
+		os.Exit(1)
+	}
+	return nsx.GetClientWithOptions(cf, opts)
+}
+
+// nsxClientOptions reads the client certificate, the proxy settings and the NSX credentials of the NSX client.
+func nsxClientOptions(cf *config.NSXOperatorConfig, reader client.Reader) (nsx.ClientOptions, error) {
+	certProvider, err := nsx.NewClientCertProvider(cf, reader)
+	if err != nil {
+		log.Error(err, "failed to load the client certificate")
+		return nsx.ClientOptions{}, err
+	}
+	proxyURL, err := nsx.NewProxyURL(cf, reader)
+	if err != nil {
+		log.Error(err, "failed to load the proxy settings")
+		return nsx.ClientOptions{}, err
+	}
+	credentialProvider, err := nsx.NewCredentialProvider(cf, reader)
+	if err != nil {
+		log.Error(err, "failed to create the NSX credential provider")
+		return nsx.ClientOptions{}, err
+	}
+	return nsx.ClientOptions{CertProvider: certProvider, ProxyURL: proxyURL, CredentialProvider: credentialProvider}, nil
+}
+
+// validateConfig checks the config, that NSX is reachable with the credentials configured and holds the NSX objects
+// the config refers to, and prints the findings as JSON. It returns 1 if the config is invalid.
+func validateConfig() int {
+	report := checkConfig()
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+	if !report.Valid {
+		return 1
+	}
+	return 0
+}
+
+func checkConfig() configcheck.Report {
+	operatorConfig, err := config.NewNSXOperatorConfigFromFile()
+	if err != nil {
+		return configcheck.ConfigError(err)
+	}
+	// the Secrets and the VPCNetworkConfigurations are read from the cluster if there is one to reach
+	var reader client.Reader
+	if restConfig, err := ctrl.GetConfig(); err == nil {
+		if reader, err = client.New(restConfig, client.Options{Scheme: scheme}); err != nil {
+			log.Info("the cluster is not checked", "error", err.Error())
+			reader = nil
+		}
+	}
+	check := func(cf *config.NSXOperatorConfig, reader client.Reader) configcheck.Report {
+		opts, err := nsxClientOptions(cf, reader)
+		if err != nil {
+			return configcheck.ConfigError(err)
+		}
+		return configcheck.Check(context.TODO(), cf, nsx.GetClientWithOptions(cf, opts), reader)
+	}
+	report := check(operatorConfig, reader)
+	names := make([]string, 0, len(operatorConfig.NSXManagers))
+	for name := range operatorConfig.NSXManagers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// the VPCNetworkConfigurations are served by the NSX manager of the nsx_v3 section
+		report.Merge(name, check(operatorConfig.ForNSXManager(name), nil))
+	}
+	return report
+}
+
+// rotateCredentialsPeriodically registers the rotated client certificates of the NSX clients, and applies the rotated
+// NSX credentials to them.
+func rotateCredentialsPeriodically(nsxClients []*nsx.Client) {
+	for {
+		time.Sleep(credentialCheckInterval)
+		for _, nsxClient := range nsxClients {
+			if err := nsxClient.RotateClientCertificate(); err != nil {
+				log.Error(err, "failed to rotate the client certificate")
+			}
+			if err := nsxClient.RotateCredentials(); err != nil {
+				log.Error(err, "failed to rotate the NSX credentials")
+			}
+		}
+	}
+}
+
+// reportGarbageCollectionPeriodically reports the runs of the garbage collectors in the NSXOperatorHealth CR, and the
+// NSX resources orphaned they found in the NSXOrphanReport CR, when they change.
+func reportGarbageCollectionPeriodically(c client.Client) {
+	var reported []common.GCStatus
+	var reportedOrphans []common.OrphanedResource
+	for {
+		time.Sleep(gcReportInterval)
+		statuses := common.GCStatuses()
+		if len(statuses) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(statuses, reported) {
+			if err := health.ReportGarbageCollection(context.TODO(), c, statuses); err != nil {
+				log.Error(err, "failed to report the garbage collection")
+			} else {
+				reported = statuses
+			}
+		}
+		if orphans := common.OrphanedResources(); reportedOrphans == nil || !reflect.DeepEqual(orphans, reportedOrphans) {
+			if err := health.ReportOrphanedResources(context.TODO(), c, orphans); err != nil {
+				log.Error(err, "failed to report the NSX resources orphaned")
+			} else {
+				reportedOrphans = orphans
+			}
+		}
+	}
+}
+
+// applyReloadableConfig applies the options changed in the new config which take effect without restarting NSX
+// Operator, the log levels, the log dedup interval, the interval, the dry run and the deletion limits of the garbage
+// collectors, the API rate limits and the feature gates.
+func applyReloadableConfig(nsxClients []*nsx.Client, oldConfig, newConfig *config.NSXOperatorConfig) {
+	if oldConfig.LogLevel != newConfig.LogLevel {
+		log.Info("log level changed", "old", oldConfig.LogLevel, "new", newConfig.LogLevel)
+		logger.SetLogLevel(newConfig.LogLevel)
+	}
+	if !reflect.DeepEqual(oldConfig.ModuleLogLevels, newConfig.ModuleLogLevels) {
+		log.Info("module log levels changed", "old", oldConfig.ModuleLogLevels, "new", newConfig.ModuleLogLevels)
+		moduleLogLevels, _ := newConfig.GetModuleLogLevels()
+		logger.SetModuleLogLevels(moduleLogLevels)
+	}
+	if oldConfig.LogDedupInterval != newConfig.LogDedupInterval {
+		log.Info("log dedup interval changed", "old", oldConfig.LogDedupInterval, "new", newConfig.LogDedupInterval)
+		logger.SetDedupInterval(time.Duration(newConfig.LogDedupInterval) * time.Second)
+	}
+	if oldConfig.GCInterval != newConfig.GCInterval {
+		log.Info("garbage collection interval changed", "old", oldConfig.GCInterval, "new", newConfig.GCInterval)
+		common.SetGCInterval(time.Duration(newConfig.GCInterval) * time.Second)
+	}
+	if oldConfig.GCDryRun != newConfig.GCDryRun || !reflect.DeepEqual(oldConfig.GCDryRunResources, newConfig.GCDryRunResources) {
+		log.Info("garbage collection dry run changed", "old", oldConfig.GCDryRun, "new", newConfig.GCDryRun,
+			"oldResources", oldConfig.GCDryRunResources, "newResources", newConfig.GCDryRunResources)
+		commonctl.SetGCDryRun(newConfig.GCDryRun, newConfig.GCDryRunResources)
+	}
+	if oldConfig.GCMaxDeletions != newConfig.GCMaxDeletions || oldConfig.GCMaxDeletionPercent != newConfig.GCMaxDeletionPercent {
+		log.Info("garbage collection deletion limits changed", "oldMaxDeletions", oldConfig.GCMaxDeletions,
+			"newMaxDeletions", newConfig.GCMaxDeletions, "oldMaxDeletionPercent", oldConfig.GCMaxDeletionPercent,
+			"newMaxDeletionPercent", newConfig.GCMaxDeletionPercent)
+		commonctl.SetGCDeletionLimits(newConfig.GCMaxDeletions, newConfig.GCMaxDeletionPercent)
+	}
+	if oldConfig.APIPolicyReadRateLimit != newConfig.APIPolicyReadRateLimit || oldConfig.APIPolicyWriteRateLimit != newConfig.APIPolicyWriteRateLimit ||
+		oldConfig.APIMPReadRateLimit != newConfig.APIMPReadRateLimit || oldConfig.APIMPWriteRateLimit != newConfig.APIMPWriteRateLimit {
+		log.Info("API rate limits changed")
+		for _, nsxClient := range nsxClients {
+			nsxClient.UpdateRateLimits(newConfig)
+		}
+	}
+	if !reflect.DeepEqual(oldConfig.FeatureGates, newConfig.FeatureGates) {
+		log.Info("feature gates changed", "old", oldConfig.FeatureGates, "new", newConfig.FeatureGates)
+		gates, _ := newConfig.GetFeatureGates()
+		for _, nsxClient := range nsxClients {
+			nsxClient.Capabilities().SetFeatureGates(gates)
+		}
+	}
+}
+
+// reloadNSXManagers applies the NSX manager list of the new config to the running client.
+func reloadNSXManagers(nsxClient *nsx.Client, newConfig *config.NSXOperatorConfig) {
+	managers, thumbprint := nsxClient.NSXManagers()
+	if reflect.DeepEqual(managers, newConfig.NsxApiManagers) && reflect.DeepEqual(thumbprint, newConfig.Thumbprint) {
+		return
+	}
+	log.Info("NSX managers changed", "old", managers, "new", newConfig.NsxApiManagers)
+	if err := nsxClient.UpdateNSXManagers(newConfig.NsxApiManagers, newConfig.Thumbprint); err != nil {
+		log.Error(err, "failed to update NSX managers")
+	}
+}
+
 // Function for fetching nsx health status and feeding it to the prometheus metric.
 func getHealthStatus(nsxClient *nsx.Client) error {
 	status := 1
@@ -155,9 +1155,42 @@ func getHealthStatus(nsxClient *nsx.Client) error {
 	}
 	// Record the new health status in metric.
 	metrics.NSXOperatorHealthStats.Set(float64(status))
+	updateEndpointMetrics(nsxClient)
 	return nil
 }
 
+// lastFailovers is the number of the NSX failovers already added to the failover metric.
+var lastFailovers uint64
+
+// breakerStates are the values of the circuit breaker states in the metric.
+var breakerStates = map[nsx.BreakerState]float64{nsx.BreakerClosed: 0, nsx.BreakerHalfOpen: 1, nsx.BreakerOpen: 2}
+
+// updateEndpointMetrics records the health and the circuit breakers of the NSX manager endpoints and the one in use
+// in metrics.
+func updateEndpointMetrics(nsxClient *nsx.Client) {
+	stats, failovers := nsxClient.NSXEndpointStats()
+	// endpoints removed by a reload of the NSX managers are dropped from the metrics
+	metrics.NSXEndpointUp.Reset()
+	metrics.NSXEndpointInUse.Reset()
+	metrics.NSXEndpointBreakerState.Reset()
+	for _, ep := range stats {
+		up, inUse := 0, 0
+		if ep.Status == nsx.UP {
+			up = 1
+		}
+		if ep.InUse {
+			inUse = 1
+		}
+		metrics.NSXEndpointUp.WithLabelValues(ep.Host).Set(float64(up))
+		metrics.NSXEndpointInUse.WithLabelValues(ep.Host).Set(float64(inUse))
+		metrics.NSXEndpointBreakerState.WithLabelValues(ep.Host).Set(breakerStates[ep.Breaker])
+	}
+	if failovers > lastFailovers {
+		metrics.NSXEndpointFailoverTotal.Add(float64(failovers - lastFailovers))
+		lastFailovers = failovers
+	}
+}
+
 // Periodically fetches health info.
 func updateHealthMetricsPeriodically(nsxClient *nsx.Client) {
 	for {
@@ -9,6 +9,21 @@ type ConditionType string
 
 const (
 	Ready ConditionType = "Ready"
+	// IPExhausted is True when all the Subnets of a SubnetSet run out of IPs, or when the IP pool or IP block the CR
+	// requests IPs from runs out of IPs.
+	IPExhausted ConditionType = "IPExhausted"
+	// ExternalIPBlockUsageHigh is True when the utilization of an external IP Block crosses the configured threshold.
+	ExternalIPBlockUsageHigh ConditionType = "ExternalIPBlockUsageHigh"
+	// IPAMDriftDetected is True when the last IPAM drift audit found discrepancies between NSX Operator and NSX.
+	IPAMDriftDetected ConditionType = "IPAMDriftDetected"
+	// NextHopUnreachable is True when a StaticRoute is realized but some of its next hops are not forwarding.
+	NextHopUnreachable ConditionType = "NextHopUnreachable"
+	// RestartRequired is True when some options changed in the config file are not applied until NSX Operator is
+	// restarted.
+	RestartRequired ConditionType = "RestartRequired"
+	// GarbageCollectionFailed is True when the last run of some garbage collectors failed, e.g. some NSX resources
+	// failed to be deleted.
+	GarbageCollectionFailed ConditionType = "GarbageCollectionFailed"
 )
 
 // Condition defines condition of custom resource.
@@ -0,0 +1,25 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+// The defaults of the fields omitted in the CRs, the defaulting webhook fills them in at admission and the controllers
+// fall back to them for the CRs admitted without it.
+
+// DefaultAdminDistance is the administrative distance of the next hops of the StaticRoutes, the one NSX uses for
+// static routes by default.
+const DefaultAdminDistance = 1
+
+// DefaultDHCPMode returns the DHCP mode of the config, which is derived from the other fields if not specified.
+func DefaultDHCPMode(cfg DHCPConfig) DHCPMode {
+	switch {
+	case cfg.Mode != "":
+		return cfg.Mode
+	case cfg.EnableDHCP:
+		return DHCPModeServer
+	case cfg.DHCPRelayConfigPath != "":
+		return DHCPModeRelay
+	default:
+		return DHCPModeStatic
+	}
+}
@@ -0,0 +1,54 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPAddressAllocationSpec defines the desired state of IPAddressAllocation.
+type IPAddressAllocationSpec struct {
+	// IPPoolName is the IPPool in the same Namespace the IPs are allocated from,
+	// the IPs are allocated from the external IP blocks of the VPC if it is empty.
+	IPPoolName string `json:"ipPoolName,omitempty"`
+	// Count of the IPs to allocate. Defaults to 1.
+	// +kubebuilder:validation:Maximum:=64
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:default:=1
+	Count int `json:"count,omitempty"`
+}
+
+// IPAddressAllocationStatus defines the observed state of IPAddressAllocation.
+type IPAddressAllocationStatus struct {
+	Conditions []Condition `json:"conditions"`
+	// IPAddresses allocated.
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// IPAddressAllocation is the Schema for the ipaddressallocations API.
+// +kubebuilder:printcolumn:name="IPPool",type=string,JSONPath=`.spec.ipPoolName`,description="IPPool the IPs are allocated from"
+// +kubebuilder:printcolumn:name="IPAddresses",type=string,JSONPath=`.status.ipAddresses`,description="IPs allocated"
+type IPAddressAllocation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPAddressAllocationSpec   `json:"spec,omitempty"`
+	Status IPAddressAllocationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// IPAddressAllocationList contains a list of IPAddressAllocation.
+type IPAddressAllocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPAddressAllocation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPAddressAllocation{}, &IPAddressAllocationList{})
+}
@@ -0,0 +1,70 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPAMDriftType is the kind of discrepancy between the allocations known to NSX Operator and the ones in NSX.
+type IPAMDriftType string
+
+const (
+	// IPAMDriftUnknown is an NSX IP allocation of the cluster NSX Operator does not know.
+	IPAMDriftUnknown IPAMDriftType = "Unknown"
+	// IPAMDriftMissing is an IP allocation known to NSX Operator but missing from NSX.
+	IPAMDriftMissing IPAMDriftType = "Missing"
+)
+
+// IPAMDrift is an IP allocation found by the audit.
+type IPAMDrift struct {
+	Type IPAMDriftType `json:"type"`
+	// NSX path of the IP allocation.
+	Path string `json:"path"`
+	// IP of the allocation if it is allocated.
+	IP string `json:"ip,omitempty"`
+	// UID of the IPAddressAllocation CR the IP is allocated for.
+	OwnerUID string `json:"ownerUID,omitempty"`
+	// Repaired is true if the drift was repaired by the audit.
+	Repaired bool `json:"repaired,omitempty"`
+}
+
+// IPAMDriftReportStatus is the result of the last IPAM drift audit.
+type IPAMDriftReportStatus struct {
+	Conditions []Condition `json:"conditions,omitempty"`
+	// Time of the last audit.
+	LastAuditTime metav1.Time `json:"lastAuditTime,omitempty"`
+	// Mode of the audit, report or repair.
+	Mode string `json:"mode,omitempty"`
+	// Drifts found by the last audit.
+	Drifts []IPAMDrift `json:"drifts,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// IPAMDriftReport reports the discrepancies between the IP allocations known to NSX Operator and the NSX IP allocations,
+// it is created and updated by NSX Operator.
+// +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.status.mode`,description="Mode of the audit"
+// +kubebuilder:printcolumn:name="LastAuditTime",type=date,JSONPath=`.status.lastAuditTime`,description="Time of the last audit"
+type IPAMDriftReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status IPAMDriftReportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// IPAMDriftReportList contains a list of IPAMDriftReport.
+type IPAMDriftReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPAMDriftReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPAMDriftReport{}, &IPAMDriftReportList{})
+}
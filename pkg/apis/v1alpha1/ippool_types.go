@@ -0,0 +1,85 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPPoolType is where the Subnets of an IPPool are allocated from.
+type IPPoolType string
+
+const (
+	// IPPoolTypePrivate allocates the Subnets from the private IP blocks of the VPC.
+	IPPoolTypePrivate IPPoolType = "private"
+	// IPPoolTypePublic allocates the Subnets from the public IP blocks of the VPC.
+	IPPoolTypePublic IPPoolType = "public"
+)
+
+// IPPoolSpec defines the desired state of IPPool.
+type IPPoolSpec struct {
+	// Type of the IPPool, public or private. Defaults to private.
+	// +kubebuilder:validation:Enum=public;private
+	// +kubebuilder:default:=private
+	Type IPPoolType `json:"type,omitempty"`
+	// Path of an existing NSX IP block the Subnets are allocated from instead of the IP blocks of the VPC, e.g.
+	// /orgs/default/projects/p1/infra/ip-blocks/b1 or /infra/ip-blocks/b1. Type is ignored if it is set.
+	// +kubebuilder:validation:Pattern=`^(/orgs/[^/]+/projects/[^/]+)?/infra/ip-blocks/[^/]+$`
+	IPBlockPath string `json:"ipBlockPath,omitempty"`
+	// Subnets requested from the IP blocks.
+	Subnets []SubnetRequest `json:"subnets,omitempty"`
+}
+
+// SubnetRequest is a Subnet requested in an IPPool.
+type SubnetRequest struct {
+	// Name of the Subnet, unique in the IPPool.
+	Name string `json:"name"`
+	// Prefix length of the Subnet. Defaults to 24.
+	// +kubebuilder:validation:Maximum:=32
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:default:=24
+	PrefixLength int `json:"prefixLength,omitempty"`
+}
+
+// IPPoolStatus defines the observed state of IPPool.
+type IPPoolStatus struct {
+	Conditions []Condition `json:"conditions"`
+	// Subnets allocated to the IPPool.
+	Subnets []SubnetResult `json:"subnets,omitempty"`
+}
+
+// SubnetResult is a Subnet allocated to an IPPool.
+type SubnetResult struct {
+	// Name of the Subnet request.
+	Name string `json:"name"`
+	// CIDR allocated from the IP block.
+	CIDR string `json:"cidr"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// IPPool is the Schema for the ippools API.
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`,description="Type of IPPool"
+// +kubebuilder:printcolumn:name="Subnets",type=string,JSONPath=`.status.subnets[*].cidr`,description="CIDRs of the Subnets"
+type IPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPPoolSpec   `json:"spec,omitempty"`
+	Status IPPoolStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// IPPoolList contains a list of IPPool.
+type IPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPPool{}, &IPPoolList{})
+}
@@ -0,0 +1,49 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//+kubebuilder:object:root=true
+
+// NetworkInfo is used to report the network information of a Namespace, it is maintained by NSX Operator.
+// +kubebuilder:printcolumn:name="VPCPath",type=string,JSONPath=`.vpcs[0].vpcPath`,description="NSX VPC path of the Namespace"
+// +kubebuilder:printcolumn:name="DefaultSNATIP",type=string,JSONPath=`.vpcs[0].defaultSNATIP`,description="Default SNAT IP of the Namespace"
+type NetworkInfo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	VPCs []VPCState `json:"vpcs"`
+}
+
+//+kubebuilder:object:root=true
+
+// NetworkInfoList contains a list of NetworkInfo.
+type NetworkInfoList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NetworkInfo `json:"items"`
+}
+
+// VPCState defines information for VPC.
+type VPCState struct {
+	// VPC name.
+	Name string `json:"name"`
+	// NSX VPC Policy API resource path.
+	VPCPath string `json:"vpcPath"`
+	// Default SNAT IP for Private Subnets.
+	DefaultSNATIP string `json:"defaultSNATIP"`
+	// PolicyPath of the Tier0 or Tier0 VRF gateway the VPC is connected to.
+	DefaultGatewayPath string `json:"defaultGatewayPath,omitempty"`
+	// Gateway addresses of the Subnets in the VPC.
+	GatewayAddresses []string `json:"gatewayAddresses,omitempty"`
+	// DNS server IPs configured for the VPC.
+	DNSServers []string `json:"dnsServers,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NetworkInfo{}, &NetworkInfoList{})
+}
@@ -0,0 +1,61 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NSXOperatorConfigurationSpec holds the options of the config file sections to override, keyed by option, the values
+// are in the format of the config file, e.g. gc_interval: "120" or nsx_api_managers: "10.0.0.1,10.0.0.2".
+type NSXOperatorConfigurationSpec struct {
+	// Options of the DEFAULT section.
+	Default map[string]string `json:"default,omitempty"`
+	// Options of the coe section.
+	Coe map[string]string `json:"coe,omitempty"`
+	// Options of the nsx_v3 section.
+	NSXV3 map[string]string `json:"nsxV3,omitempty"`
+	// Options of the k8s section.
+	K8s map[string]string `json:"k8s,omitempty"`
+	// Options of the vc section.
+	VC map[string]string `json:"vc,omitempty"`
+}
+
+// NSXOperatorConfigurationStatus reports whether the options are applied.
+type NSXOperatorConfigurationStatus struct {
+	Conditions []Condition `json:"conditions,omitempty"`
+	// Generation of the spec last applied or rejected.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Options changed which are not applied until NSX Operator is restarted, in the form of section.option.
+	PendingRestartOptions []string `json:"pendingRestartOptions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// NSXOperatorConfiguration overrides the options of the NSX Operator config file, only the one named nsx-operator is
+// applied. The options which can be changed without restarting NSX Operator are applied when it changes.
+// +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,description="Whether the options are applied"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type NSXOperatorConfiguration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NSXOperatorConfigurationSpec   `json:"spec,omitempty"`
+	Status NSXOperatorConfigurationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NSXOperatorConfigurationList contains a list of NSXOperatorConfiguration.
+type NSXOperatorConfigurationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NSXOperatorConfiguration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NSXOperatorConfiguration{}, &NSXOperatorConfigurationList{})
+}
@@ -0,0 +1,65 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NSXOperatorHealthStatus is the health of NSX Operator.
+type NSXOperatorHealthStatus struct {
+	Conditions []Condition `json:"conditions,omitempty"`
+	// Time the config file was last reloaded.
+	LastConfigReloadTime metav1.Time `json:"lastConfigReloadTime,omitempty"`
+	// Options changed in the config file which are not applied until NSX Operator is restarted, in the form of
+	// section.option.
+	PendingRestartOptions []string `json:"pendingRestartOptions,omitempty"`
+	// Outcome of the last run of the garbage collector of each resource type.
+	GarbageCollectors []GarbageCollectorStatus `json:"garbageCollectors,omitempty"`
+}
+
+// GarbageCollectorStatus is the outcome of the last run of the garbage collector of a resource type, which deletes the
+// NSX resources whose CRs no longer exist.
+type GarbageCollectorStatus struct {
+	// Type of the resources collected, e.g. subnetport.
+	ResourceType string `json:"resourceType"`
+	// Time the garbage collector last ran.
+	LastRunTime metav1.Time `json:"lastRunTime,omitempty"`
+	// Time the garbage collector last ran without errors.
+	LastSuccessTime metav1.Time `json:"lastSuccessTime,omitempty"`
+	// Number of the NSX resources scanned in the last run.
+	Scanned int `json:"scanned,omitempty"`
+	// Number of the NSX resources deleted in the last run.
+	Deleted int `json:"deleted,omitempty"`
+	// Number of the NSX resources failed to be deleted in the last run.
+	Errors int `json:"errors,omitempty"`
+	// Error of the last run, empty if it succeeded.
+	LastError string `json:"lastError,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// NSXOperatorHealth reports the health of NSX Operator, it is created and updated by NSX Operator.
+// +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:printcolumn:name="LastConfigReloadTime",type=date,JSONPath=`.status.lastConfigReloadTime`,description="Time the config file was last reloaded"
+type NSXOperatorHealth struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status NSXOperatorHealthStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NSXOperatorHealthList contains a list of NSXOperatorHealth.
+type NSXOperatorHealthList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NSXOperatorHealth `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NSXOperatorHealth{}, &NSXOperatorHealthList{})
+}
@@ -0,0 +1,63 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NSXOrphanedResource is an NSX resource tagged with the cluster whose CR no longer exists, which the garbage
+// collector didn't delete, e.g. in dry run or because the run was aborted.
+type NSXOrphanedResource struct {
+	// NSX path of the resource, or its ID if it has no path.
+	Path string `json:"path"`
+	// NSX resource type, e.g. VpcSubnet.
+	Type string `json:"type"`
+	// Resource type of the garbage collector which found the resource, e.g. subnet.
+	GarbageCollector string `json:"garbageCollector"`
+	// UID of the CR the resource was created for.
+	OwnerUID string `json:"ownerUID,omitempty"`
+	// Time the resource was created in NSX.
+	CreationTime metav1.Time `json:"creationTime,omitempty"`
+	// Tags of the resource, in the form of scope=tag.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// NSXOrphanReportStatus is the NSX resources orphaned found by the last runs of the garbage collectors.
+type NSXOrphanReportStatus struct {
+	// Time the report was last updated.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Number of the NSX resources orphaned.
+	Count int `json:"count"`
+	// NSX resources orphaned.
+	Resources []NSXOrphanedResource `json:"resources,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// NSXOrphanReport lists the NSX resources tagged with the cluster whose CRs no longer exist, so that they are reviewed
+// before the garbage collectors delete them, it is created and updated by NSX Operator.
+// +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:printcolumn:name="Count",type=integer,JSONPath=`.status.count`,description="Number of the NSX resources orphaned"
+// +kubebuilder:printcolumn:name="LastUpdateTime",type=date,JSONPath=`.status.lastUpdateTime`,description="Time the report was last updated"
+type NSXOrphanReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status NSXOrphanReportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NSXOrphanReportList contains a list of NSXOrphanReport.
+type NSXOrphanReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NSXOrphanReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NSXOrphanReport{}, &NSXOrphanReportList{})
+}
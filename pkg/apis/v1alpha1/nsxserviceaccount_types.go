@@ -8,6 +8,7 @@ import (
 )
 
 // NSXServiceAccountSpec defines the desired state of NSXServiceAccount
+// +kubebuilder:validation:XValidation:rule="has(self.vpcName) == has(oldSelf.vpcName) && (!has(self.vpcName) || self.vpcName == oldSelf.vpcName)",message="vpcName is immutable"
 type NSXServiceAccountSpec struct {
 	VPCName string `json:"vpcName,omitempty"`
 }
@@ -0,0 +1,70 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PrefixListEntry permits or denies the prefixes matching a network.
+// +kubebuilder:validation:XValidation:rule="!has(self.ge) || !has(self.le) || self.ge <= self.le",message="ge must not be greater than le"
+type PrefixListEntry struct {
+	// Network in CIDR format, or ANY to match all the prefixes.
+	// +kubebuilder:validation:MaxLength=43
+	// +kubebuilder:validation:XValidation:rule="self == 'ANY' || self.matches('^((25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])[.]){3}(25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])/([0-9]|[12][0-9]|3[0-2])$') || self.matches('^[0-9a-fA-F:]*:[0-9a-fA-F:.]*/([0-9]|[1-9][0-9]|1[01][0-9]|12[0-8])$')",message="network must be ANY or an IPv4 or IPv6 CIDR"
+	Network string `json:"network"`
+	// Action of the entry, defaults to Permit.
+	Action RouteAdvertisementAction `json:"action,omitempty"`
+	// GE matches the prefixes with lengths greater than or equal to it.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=128
+	GE int `json:"ge,omitempty"`
+	// LE matches the prefixes with lengths less than or equal to it.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=128
+	LE int `json:"le,omitempty"`
+}
+
+// PrefixListSpec defines the entries of the NSX prefix list.
+type PrefixListSpec struct {
+	// ID of the NSX Tier-0 gateway, defaults to the Tier-0 gateway NSX Operator is configured with.
+	Tier0Gateway string `json:"tier0Gateway,omitempty"`
+	// Entries of the prefix list, evaluated in order.
+	// +kubebuilder:validation:MinItems=1
+	Prefixes []PrefixListEntry `json:"prefixes"`
+}
+
+// PrefixListStatus defines the observed state of PrefixList.
+type PrefixListStatus struct {
+	Conditions []Condition `json:"conditions,omitempty"`
+	// Policy path of the NSX prefix list.
+	Path string `json:"path,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PrefixList is the Schema for an NSX prefix list on a Tier-0 gateway, it is matched by the RouteMaps.
+// +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:printcolumn:name="Path",type=string,JSONPath=`.status.path`,description="Policy path of the NSX prefix list"
+type PrefixList struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PrefixListSpec   `json:"spec,omitempty"`
+	Status PrefixListStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PrefixListList contains a list of PrefixList.
+type PrefixListList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PrefixList `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PrefixList{}, &PrefixListList{})
+}
@@ -0,0 +1,108 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RouteAdvertisementPrefixType is a kind of prefixes created by NSX Operator.
+// +kubebuilder:validation:Enum=PodCIDRs;LBVIPs;NATIPs;StaticRoutes
+type RouteAdvertisementPrefixType string
+
+const (
+	// RouteAdvertisementPodCIDRs are the CIDRs of the segments connected to the gateway.
+	RouteAdvertisementPodCIDRs RouteAdvertisementPrefixType = "PodCIDRs"
+	// RouteAdvertisementLBVIPs are the VIPs of the LoadBalancer Services.
+	RouteAdvertisementLBVIPs RouteAdvertisementPrefixType = "LBVIPs"
+	// RouteAdvertisementNATIPs are the translated IPs of the NAT rules.
+	RouteAdvertisementNATIPs RouteAdvertisementPrefixType = "NATIPs"
+	// RouteAdvertisementStaticRoutes are the networks of the StaticRoutes.
+	RouteAdvertisementStaticRoutes RouteAdvertisementPrefixType = "StaticRoutes"
+)
+
+// RouteAdvertisementAction is the action of a route advertisement rule.
+// +kubebuilder:validation:Enum=Permit;Deny
+type RouteAdvertisementAction string
+
+const (
+	RouteAdvertisementPermit RouteAdvertisementAction = "Permit"
+	RouteAdvertisementDeny   RouteAdvertisementAction = "Deny"
+)
+
+// RouteAdvertisementPrefixOperator selects the prefixes matched by a route advertisement rule.
+// +kubebuilder:validation:Enum=GE;EQ
+type RouteAdvertisementPrefixOperator string
+
+const (
+	// RouteAdvertisementPrefixGE matches the prefixes within the subnets with longer or equal prefix lengths.
+	RouteAdvertisementPrefixGE RouteAdvertisementPrefixOperator = "GE"
+	// RouteAdvertisementPrefixEQ matches the prefixes within the subnets with equal prefix lengths.
+	RouteAdvertisementPrefixEQ RouteAdvertisementPrefixOperator = "EQ"
+)
+
+// RouteAdvertisementRule permits or denies the advertisement of the prefixes within the subnets.
+type RouteAdvertisementRule struct {
+	// Name of the rule, unique in the RouteAdvertisement.
+	// +kubebuilder:validation:MaxLength=64
+	Name string `json:"name"`
+	// Action of the rule, defaults to Permit.
+	Action RouteAdvertisementAction `json:"action,omitempty"`
+	// Subnets in CIDR format the prefixes are matched against.
+	// +kubebuilder:validation:MinItems=1
+	Subnets []string `json:"subnets"`
+	// Operator matching the prefixes against the subnets, defaults to GE.
+	PrefixOperator RouteAdvertisementPrefixOperator `json:"prefixOperator,omitempty"`
+	// Types of the prefixes the rule applies to, all the advertised types if empty.
+	Types []RouteAdvertisementPrefixType `json:"types,omitempty"`
+}
+
+// RouteAdvertisementSpec defines the prefixes advertised from the Tier-1 gateway to the Tier-0 gateway.
+type RouteAdvertisementSpec struct {
+	// ID of the NSX Tier-1 gateway, defaults to the Tier-1 gateway NSX Operator is configured with.
+	Tier1Gateway string `json:"tier1Gateway,omitempty"`
+	// Types of the prefixes advertised, the types not listed are not advertised.
+	Advertise []RouteAdvertisementPrefixType `json:"advertise,omitempty"`
+	// Rules filtering the advertised prefixes, evaluated in order.
+	Rules []RouteAdvertisementRule `json:"rules,omitempty"`
+	// Name of the RouteMap filtering the advertised prefixes when they are redistributed by its Tier-0 gateway.
+	RouteMap string `json:"routeMap,omitempty"`
+}
+
+// RouteAdvertisementStatus defines the observed state of RouteAdvertisement.
+type RouteAdvertisementStatus struct {
+	Conditions []Condition `json:"conditions,omitempty"`
+	// ID of the NSX Tier-1 gateway the advertisement is configured on.
+	Tier1Gateway string `json:"tier1Gateway,omitempty"`
+	// Policy path of the NSX route map the redistribution is configured with.
+	RouteMapPath string `json:"routeMapPath,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// RouteAdvertisement configures which prefixes created by NSX Operator are advertised from an NSX Tier-1 gateway, one
+// RouteAdvertisement is allowed per gateway.
+// +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:printcolumn:name="Tier1Gateway",type=string,JSONPath=`.status.tier1Gateway`,description="Tier-1 gateway the advertisement is configured on"
+type RouteAdvertisement struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RouteAdvertisementSpec   `json:"spec,omitempty"`
+	Status RouteAdvertisementStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RouteAdvertisementList contains a list of RouteAdvertisement.
+type RouteAdvertisementList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RouteAdvertisement `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RouteAdvertisement{}, &RouteAdvertisementList{})
+}
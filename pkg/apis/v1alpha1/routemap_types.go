@@ -0,0 +1,61 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RouteMapEntry permits or denies the prefixes matched by the PrefixLists.
+type RouteMapEntry struct {
+	// Action of the entry, defaults to Permit.
+	Action RouteAdvertisementAction `json:"action,omitempty"`
+	// Names of the PrefixLists matched by the entry, they must be on the Tier-0 gateway of the RouteMap.
+	// +kubebuilder:validation:MinItems=1
+	PrefixLists []string `json:"prefixLists"`
+}
+
+// RouteMapSpec defines the entries of the NSX route map.
+type RouteMapSpec struct {
+	// ID of the NSX Tier-0 gateway, defaults to the Tier-0 gateway NSX Operator is configured with.
+	Tier0Gateway string `json:"tier0Gateway,omitempty"`
+	// Entries of the route map, evaluated in order.
+	// +kubebuilder:validation:MinItems=1
+	Entries []RouteMapEntry `json:"entries"`
+}
+
+// RouteMapStatus defines the observed state of RouteMap.
+type RouteMapStatus struct {
+	Conditions []Condition `json:"conditions,omitempty"`
+	// Policy path of the NSX route map.
+	Path string `json:"path,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// RouteMap is the Schema for an NSX route map on a Tier-0 gateway, it is referenced by the RouteAdvertisements to
+// filter the prefixes redistributed by the Tier-0 gateway.
+// +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:printcolumn:name="Path",type=string,JSONPath=`.status.path`,description="Policy path of the NSX route map"
+type RouteMap struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RouteMapSpec   `json:"spec,omitempty"`
+	Status RouteMapStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RouteMapList contains a list of RouteMap.
+type RouteMapList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RouteMap `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RouteMap{}, &RouteMapList{})
+}
@@ -93,10 +93,13 @@ type SecurityPolicyPeer struct {
 type IPBlock struct {
 	// CIDR is a string representing the IP Block.
 	// A valid example is "192.168.1.1/24".
+	// +kubebuilder:validation:MaxLength=43
+	// +kubebuilder:validation:XValidation:rule="self.matches('^((25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])[.]){3}(25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])/([0-9]|[12][0-9]|3[0-2])$') || self.matches('^[0-9a-fA-F:]*:[0-9a-fA-F:.]*/([0-9]|[1-9][0-9]|1[01][0-9]|12[0-8])$')",message="cidr must be an IPv4 or IPv6 CIDR"
 	CIDR string `json:"cidr"`
 }
 
 // SecurityPolicyPort describes protocol and ports for traffic.
+// +kubebuilder:validation:XValidation:rule="!has(self.endPort) || (has(self.port) && type(self.port) == int && self.endPort >= self.port)",message="endPort requires a port number not greater than it"
 type SecurityPolicyPort struct {
 	// Protocol(TCP, UDP) is the protocol to match traffic.
 	// It is TCP by default.
@@ -104,6 +107,8 @@ type SecurityPolicyPort struct {
 	// Port is the name or port number.
 	Port intstr.IntOrString `json:"port,omitempty"`
 	// EndPort defines the end of port range.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	EndPort int `json:"endPort,omitempty"`
 }
 
@@ -0,0 +1,51 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecurityPolicyPriorityRangeSpec defines the priorities the SecurityPolicies of the selected Namespaces may have.
+// +kubebuilder:validation:XValidation:rule="self.min <= self.max",message="min must not be greater than max"
+type SecurityPolicyPriorityRangeSpec struct {
+	// NamespaceSelector selects the Namespaces the range applies to, it applies to all the Namespaces if it is empty.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Lowest priority the SecurityPolicies of the Namespaces may have.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	Min int `json:"min"`
+	// Highest priority the SecurityPolicies of the Namespaces may have.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	Max int `json:"max"`
+}
+
+//+kubebuilder:object:root=true
+
+// SecurityPolicyPriorityRange restricts the priorities of the SecurityPolicies of the Namespaces it selects, e.g. so
+// that the SecurityPolicies of the application teams are enforced after the baseline ones of the cluster admins. The
+// priority of a SecurityPolicy must be within all the ranges selecting its Namespace.
+// +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:printcolumn:name="Min",type=integer,JSONPath=`.spec.min`,description="Lowest priority allowed"
+// +kubebuilder:printcolumn:name="Max",type=integer,JSONPath=`.spec.max`,description="Highest priority allowed"
+type SecurityPolicyPriorityRange struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SecurityPolicyPriorityRangeSpec `json:"spec"`
+}
+
+//+kubebuilder:object:root=true
+
+// SecurityPolicyPriorityRangeList contains a list of SecurityPolicyPriorityRange.
+type SecurityPolicyPriorityRangeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SecurityPolicyPriorityRange `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SecurityPolicyPriorityRange{}, &SecurityPolicyPriorityRangeList{})
+}
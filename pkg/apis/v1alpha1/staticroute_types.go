@@ -12,12 +12,32 @@ type StaticRouteStatusCondition string
 // StaticRouteCondition defines condition of StaticRoute.
 type StaticRouteCondition Condition
 
+// StaticRouteScope is the gateway a static route is programmed on.
+// +kubebuilder:validation:Enum=VPC;Tier1;Tier0
+type StaticRouteScope string
+
+const (
+	// StaticRouteScopeVPC programs the route on the VPC of the Namespace, or on the configured Tier-1 gateway for the
+	// Namespaces without VPCs.
+	StaticRouteScopeVPC StaticRouteScope = "VPC"
+	// StaticRouteScopeTier1 programs the route on a Tier-1 gateway.
+	StaticRouteScopeTier1 StaticRouteScope = "Tier1"
+	// StaticRouteScopeTier0 programs the route on a Tier-0 gateway.
+	StaticRouteScopeTier0 StaticRouteScope = "Tier0"
+)
+
 // StaticRouteSpec defines static routes configuration on VPC.
+// +kubebuilder:validation:XValidation:rule="!has(self.gateway) || (has(self.scope) && self.scope != 'VPC')",message="gateway is only allowed in the Tier1 and Tier0 scopes"
 type StaticRouteSpec struct {
+	// Scope of the route, defaults to VPC. The Tier1 and Tier0 scopes are only allowed for the users permitted to
+	// create the staticroutes/tier1 or staticroutes/tier0 subresource in the Namespace.
+	Scope StaticRouteScope `json:"scope,omitempty"`
+	// ID of the NSX gateway of the Tier1 or Tier0 scope, defaults to the gateway NSX Operator is configured with.
+	Gateway string `json:"gateway,omitempty"`
 	// Specify network address in CIDR format.
 	// +kubebuilder:validation:Format=cidr
 	Network string `json:"network"`
-	// Next hop gateway
+	// Next hop gateway, the traffic is balanced with ECMP across the next hops with the lowest admin distance.
 	// +kubebuilder:validation:MinItems=1
 	NextHops []NextHop `json:"nextHops"`
 }
@@ -27,6 +47,10 @@ type NextHop struct {
 	// Next hop gateway IP address.
 	// +kubebuilder:validation:Format=ip
 	IPAddress string `json:"ipAddress"`
+	// Admin distance of the next hop, defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=255
+	AdminDistance int `json:"adminDistance,omitempty"`
 }
 
 // StaticRouteStatus defines the observed state of StaticRoute.
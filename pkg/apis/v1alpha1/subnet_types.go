@@ -9,11 +9,22 @@ import (
 
 type AccessMode string
 
+// DHCPMode is the way the workloads of a Subnet get their IPs.
+type DHCPMode string
+
+const (
+	// DHCPModeServer serves DHCP with the DHCP server of the Subnet.
+	DHCPModeServer DHCPMode = "server"
+	// DHCPModeRelay relays DHCP to the servers of the DHCP relay config.
+	DHCPModeRelay DHCPMode = "relay"
+	// DHCPModeStatic allocates static IPs to the Subnet ports without DHCP.
+	DHCPModeStatic DHCPMode = "static"
+)
+
 // SubnetSpec defines the desired state of Subnet.
 type SubnetSpec struct {
 	// Size of Subnet based upon estimated workload count.
-	// Defaults to 64.
-	// +kubebuilder:default:=64
+	// Defaults to the default_subnet_prefix_length of NSX Operator, which is 64 IPs if not configured.
 	// +kubebuilder:validation:Maximum:=65536
 	// +kubebuilder:validation:Minimum:=16
 	IPv4SubnetSize int `json:"ipv4SubnetSize,omitempty"`
@@ -30,13 +41,33 @@ type SubnetSpec struct {
 	AdvancedConfig AdvancedConfig `json:"advancedConfig,omitempty"`
 	// DHCPConfig DHCP configuration.
 	DHCPConfig DHCPConfig `json:"DHCPConfig,omitempty"`
+	// PortSecurity defines the default security features of the SubnetPorts in the Subnet.
+	PortSecurity *PortSecurity `json:"portSecurity,omitempty"`
 }
 
 // SubnetStatus defines the observed state of Subnet.
 type SubnetStatus struct {
-	NSXResourcePath string      `json:"nsxResourcePath"`
-	IPAddresses     []string    `json:"ipAddresses"`
-	Conditions      []Condition `json:"conditions"`
+	NSXResourcePath string   `json:"nsxResourcePath"`
+	IPAddresses     []string `json:"ipAddresses"`
+	// Gateway addresses of the Subnet CIDRs.
+	GatewayAddresses []string `json:"gatewayAddresses,omitempty"`
+	// DHCP server addresses of the Subnet CIDRs, only set in DHCP server mode.
+	DHCPServerAddresses []string `json:"dhcpServerAddresses,omitempty"`
+	// IP usage of the Subnet, synced from NSX periodically.
+	IPUsage    *SubnetIPUsage `json:"ipUsage,omitempty"`
+	Conditions []Condition    `json:"conditions"`
+}
+
+// SubnetIPUsage shows how many IPs of the Subnet are allocated.
+type SubnetIPUsage struct {
+	// Total number of IPs in the Subnet.
+	Total int64 `json:"total"`
+	// Number of IPs allocated.
+	Used int64 `json:"used"`
+	// Number of IPs free to allocate.
+	Available int64 `json:"available"`
+	// Percentage of the IPs allocated.
+	Utilization int `json:"utilization"`
 }
 
 //+kubebuilder:object:root=true
@@ -74,10 +105,16 @@ type StaticIPAllocation struct {
 }
 
 // DHCPConfig is DHCP configuration.
+// +kubebuilder:validation:XValidation:rule="!(has(self.enableDHCP) && self.enableDHCP && has(self.dhcpRelayConfigPath))",message="enableDHCP and dhcpRelayConfigPath are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.mode) || self.mode != 'relay' || has(self.dhcpRelayConfigPath)",message="dhcpRelayConfigPath is required in relay mode"
 type DHCPConfig struct {
+	// Mode of DHCP, server, relay or static.
+	// Defaults to server if enableDHCP is true, relay if dhcpRelayConfigPath is set, or static otherwise.
+	// +kubebuilder:validation:Enum=server;relay;static
+	Mode DHCPMode `json:"mode,omitempty"`
 	// +kubebuilder:default:=false
 	EnableDHCP bool `json:"enableDHCP,omitempty"`
-	// DHCPRelayConfigPath is policy path of DHCP-relay-config.
+	// DHCPRelayConfigPath is policy path of DHCP-relay-config, it is required in relay mode.
 	DHCPRelayConfigPath string `json:"dhcpRelayConfigPath,omitempty"`
 	// DHCPV4PoolSize IPs in % to be reserved for DHCP ranges.
 	// By default, 80% of IPv4 IPs will be reserved for DHCP.
@@ -16,6 +16,34 @@ type SubnetPortSpec struct {
 	SubnetSet string `json:"subnetSet,omitempty"`
 	// AttachmentRef refers to the virtual machine which the SubnetPort is attached.
 	AttachmentRef corev1.ObjectReference `json:"attachmentRef,omitempty"`
+	// AddressBindings defines the IP and MAC addresses bound to the SubnetPort.
+	// The IP or MAC address is not allocated from the pool of the Subnet if it is bound.
+	// +kubebuilder:validation:MaxItems=1
+	AddressBindings []PortAddressBinding `json:"addressBindings,omitempty"`
+	// Security defines the security features of the SubnetPort, the features not set follow the portSecurity of the Subnet.
+	Security *PortSecurity `json:"security,omitempty"`
+}
+
+// PortSecurity defines the security features of the ports, they are applied with the segment security and
+// SpoofGuard profiles created by NSX Operator. The features not set follow the defaults of NSX, that is,
+// SpoofGuard is disabled, and DHCP server block, RA guard and BPDU filter are enabled.
+type PortSecurity struct {
+	// SpoofGuard drops the traffic not sourced from the addresses bound to the port.
+	SpoofGuard *bool `json:"spoofGuard,omitempty"`
+	// DHCPServerBlock drops the DHCP server traffic from the port.
+	DHCPServerBlock *bool `json:"dhcpServerBlock,omitempty"`
+	// RAGuard drops the IPv6 router advertisements from the port.
+	RAGuard *bool `json:"raGuard,omitempty"`
+	// BPDUFilter drops the BPDUs from the port.
+	BPDUFilter *bool `json:"bpduFilter,omitempty"`
+}
+
+// PortAddressBinding defines an IP and MAC address bound to the SubnetPort.
+type PortAddressBinding struct {
+	// IPAddress is the IP address bound to the SubnetPort.
+	IPAddress string `json:"ipAddress,omitempty"`
+	// MACAddress is the MAC address bound to the SubnetPort.
+	MACAddress string `json:"macAddress,omitempty"`
 }
 
 // SubnetPortStatus defines the observed state of SubnetPort.
@@ -24,6 +52,8 @@ type SubnetPortStatus struct {
 	Conditions []Condition `json:"conditions,omitempty"`
 	// VIFID describes the attachment VIF ID owned by the SubnetPort in NSX-T.
 	VIFID string `json:"vifID,omitempty"`
+	// AttachmentState describes the state of the VIF attachment in NSX-T, e.g. ATTACHED.
+	AttachmentState string `json:"attachmentState,omitempty"`
 	// IPAddresses describes the IP addresses of the SubnetPort.
 	IPAddresses []SubnetPortIPAddress `json:"ipAddresses,omitempty"`
 	// MACAddress describes the MAC address of the SubnetPort.
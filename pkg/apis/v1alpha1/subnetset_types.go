@@ -10,8 +10,7 @@ import (
 // SubnetSetSpec defines the desired state of SubnetSet.
 type SubnetSetSpec struct {
 	// Size of Subnet based upon estimated workload count.
-	// Defaults to 64.
-	// +kubebuilder:default:=64
+	// Defaults to the default_subnet_prefix_length of NSX Operator, which is 64 IPs if not configured.
 	// +kubebuilder:validation:Maximum:=65536
 	// +kubebuilder:validation:Minimum:=16
 	IPv4SubnetSize int `json:"ipv4SubnetSize,omitempty"`
@@ -30,6 +29,12 @@ type SubnetSetSpec struct {
 type SubnetInfo struct {
 	NSXResourcePath string   `json:"nsxResourcePath"`
 	IPAddresses     []string `json:"ipAddresses"`
+	// Total number of IPs of the Subnet, it is not set until NSX reports the usage.
+	Total int64 `json:"total,omitempty"`
+	// Number of IPs in use.
+	Used int64 `json:"used,omitempty"`
+	// Percentage of the IPs in use.
+	Utilization int `json:"utilization,omitempty"`
 }
 
 // SubnetSetStatus defines the observed state of SubnetSet.
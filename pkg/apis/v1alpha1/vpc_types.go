@@ -29,8 +29,23 @@ type VPCList struct {
 	Items           []VPC `json:"items"`
 }
 
+// DeletionPolicy decides what happens to the NSX VPC when the VPC CR is deleted.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the NSX VPC with the VPC CR.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the NSX VPC, it is tagged as retained and no longer managed by the cluster.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
 // VPCSpec defines VPC configuration
 type VPCSpec struct {
+	// DeletionPolicy decides whether the NSX VPC is deleted or retained when the VPC is deleted.
+	// Defaults to Delete.
+	// +kubebuilder:default:=Delete
+	// +kubebuilder:validation:Enum=Delete;Retain
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // VPCStatus defines the observed state of VPC
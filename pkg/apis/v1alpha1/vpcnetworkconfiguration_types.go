@@ -56,6 +56,22 @@ type VPCNetworkConfigurationSpec struct {
 type VPCNetworkConfigurationStatus struct {
 	// Conditions describes current state of VPCNetworkConfiguration.
 	Conditions []Condition `json:"conditions"`
+	// ExternalIPv4BlocksUsage shows the usage of the external IPv4 Blocks.
+	ExternalIPv4BlocksUsage []IPBlockUsage `json:"externalIPv4BlocksUsage,omitempty"`
+}
+
+// IPBlockUsage shows how many IPs of an NSX IP Block are used by SNAT IPs, NAT rules and IP allocations.
+type IPBlockUsage struct {
+	// NSX-T IP Block path.
+	Path string `json:"path"`
+	// CIDR of the IP Block.
+	CIDR string `json:"cidr,omitempty"`
+	// Total number of IPs in the IP Block.
+	Total int64 `json:"total"`
+	// Number of IPs in use.
+	Used int64 `json:"used"`
+	// Percentage of the IPs in use.
+	Utilization int `json:"utilization"`
 }
 
 //+kubebuilder:object:root=true
@@ -96,6 +96,225 @@ func (in *DNSClientConfig) DeepCopy() *DNSClientConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollectorStatus) DeepCopyInto(out *GarbageCollectorStatus) {
+	*out = *in
+	in.LastRunTime.DeepCopyInto(&out.LastRunTime)
+	in.LastSuccessTime.DeepCopyInto(&out.LastSuccessTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollectorStatus.
+func (in *GarbageCollectorStatus) DeepCopy() *GarbageCollectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAMDrift) DeepCopyInto(out *IPAMDrift) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMDrift.
+func (in *IPAMDrift) DeepCopy() *IPAMDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAMDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAMDriftReport) DeepCopyInto(out *IPAMDriftReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMDriftReport.
+func (in *IPAMDriftReport) DeepCopy() *IPAMDriftReport {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAMDriftReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPAMDriftReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAMDriftReportList) DeepCopyInto(out *IPAMDriftReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IPAMDriftReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMDriftReportList.
+func (in *IPAMDriftReportList) DeepCopy() *IPAMDriftReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAMDriftReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPAMDriftReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAMDriftReportStatus) DeepCopyInto(out *IPAMDriftReportStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastAuditTime.DeepCopyInto(&out.LastAuditTime)
+	if in.Drifts != nil {
+		in, out := &in.Drifts, &out.Drifts
+		*out = make([]IPAMDrift, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMDriftReportStatus.
+func (in *IPAMDriftReportStatus) DeepCopy() *IPAMDriftReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAMDriftReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAddressAllocation) DeepCopyInto(out *IPAddressAllocation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAddressAllocation.
+func (in *IPAddressAllocation) DeepCopy() *IPAddressAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAddressAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPAddressAllocation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAddressAllocationList) DeepCopyInto(out *IPAddressAllocationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IPAddressAllocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAddressAllocationList.
+func (in *IPAddressAllocationList) DeepCopy() *IPAddressAllocationList {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAddressAllocationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPAddressAllocationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAddressAllocationSpec) DeepCopyInto(out *IPAddressAllocationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAddressAllocationSpec.
+func (in *IPAddressAllocationSpec) DeepCopy() *IPAddressAllocationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAddressAllocationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAddressAllocationStatus) DeepCopyInto(out *IPAddressAllocationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IPAddresses != nil {
+		in, out := &in.IPAddresses, &out.IPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAddressAllocationStatus.
+func (in *IPAddressAllocationStatus) DeepCopy() *IPAddressAllocationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAddressAllocationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPBlock) DeepCopyInto(out *IPBlock) {
 	*out = *in
@@ -112,111 +331,1041 @@ func (in *IPBlock) DeepCopy() *IPBlock {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoadBalancerVPCEndpoint) DeepCopyInto(out *LoadBalancerVPCEndpoint) {
+func (in *IPBlockUsage) DeepCopyInto(out *IPBlockUsage) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerVPCEndpoint.
-func (in *LoadBalancerVPCEndpoint) DeepCopy() *LoadBalancerVPCEndpoint {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPBlockUsage.
+func (in *IPBlockUsage) DeepCopy() *IPBlockUsage {
 	if in == nil {
 		return nil
 	}
-	out := new(LoadBalancerVPCEndpoint)
+	out := new(IPBlockUsage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NSXProxyEndpoint) DeepCopyInto(out *NSXProxyEndpoint) {
+func (in *IPPool) DeepCopyInto(out *IPPool) {
 	*out = *in
-	if in.Addresses != nil {
-		in, out := &in.Addresses, &out.Addresses
-		*out = make([]NSXProxyEndpointAddress, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPool.
+func (in *IPPool) DeepCopy() *IPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolList) DeepCopyInto(out *IPPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IPPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolList.
+func (in *IPPoolList) DeepCopy() *IPPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolSpec) DeepCopyInto(out *IPPoolSpec) {
+	*out = *in
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]SubnetRequest, len(*in))
 		copy(*out, *in)
 	}
-	if in.Ports != nil {
-		in, out := &in.Ports, &out.Ports
-		*out = make([]NSXProxyEndpointPort, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolSpec.
+func (in *IPPoolSpec) DeepCopy() *IPPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolStatus) DeepCopyInto(out *IPPoolStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]SubnetResult, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXProxyEndpoint.
-func (in *NSXProxyEndpoint) DeepCopy() *NSXProxyEndpoint {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolStatus.
+func (in *IPPoolStatus) DeepCopy() *IPPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerVPCEndpoint) DeepCopyInto(out *LoadBalancerVPCEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerVPCEndpoint.
+func (in *LoadBalancerVPCEndpoint) DeepCopy() *LoadBalancerVPCEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerVPCEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOperatorConfiguration) DeepCopyInto(out *NSXOperatorConfiguration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOperatorConfiguration.
+func (in *NSXOperatorConfiguration) DeepCopy() *NSXOperatorConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOperatorConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NSXOperatorConfiguration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOperatorConfigurationList) DeepCopyInto(out *NSXOperatorConfigurationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NSXOperatorConfiguration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOperatorConfigurationList.
+func (in *NSXOperatorConfigurationList) DeepCopy() *NSXOperatorConfigurationList {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOperatorConfigurationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NSXOperatorConfigurationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOperatorConfigurationSpec) DeepCopyInto(out *NSXOperatorConfigurationSpec) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Coe != nil {
+		in, out := &in.Coe, &out.Coe
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NSXV3 != nil {
+		in, out := &in.NSXV3, &out.NSXV3
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.K8s != nil {
+		in, out := &in.K8s, &out.K8s
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VC != nil {
+		in, out := &in.VC, &out.VC
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOperatorConfigurationSpec.
+func (in *NSXOperatorConfigurationSpec) DeepCopy() *NSXOperatorConfigurationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOperatorConfigurationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOperatorConfigurationStatus) DeepCopyInto(out *NSXOperatorConfigurationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PendingRestartOptions != nil {
+		in, out := &in.PendingRestartOptions, &out.PendingRestartOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOperatorConfigurationStatus.
+func (in *NSXOperatorConfigurationStatus) DeepCopy() *NSXOperatorConfigurationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOperatorConfigurationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOperatorHealth) DeepCopyInto(out *NSXOperatorHealth) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOperatorHealth.
+func (in *NSXOperatorHealth) DeepCopy() *NSXOperatorHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOperatorHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NSXOperatorHealth) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOperatorHealthList) DeepCopyInto(out *NSXOperatorHealthList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NSXOperatorHealth, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOperatorHealthList.
+func (in *NSXOperatorHealthList) DeepCopy() *NSXOperatorHealthList {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOperatorHealthList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NSXOperatorHealthList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOperatorHealthStatus) DeepCopyInto(out *NSXOperatorHealthStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastConfigReloadTime.DeepCopyInto(&out.LastConfigReloadTime)
+	if in.PendingRestartOptions != nil {
+		in, out := &in.PendingRestartOptions, &out.PendingRestartOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GarbageCollectors != nil {
+		in, out := &in.GarbageCollectors, &out.GarbageCollectors
+		*out = make([]GarbageCollectorStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOperatorHealthStatus.
+func (in *NSXOperatorHealthStatus) DeepCopy() *NSXOperatorHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOperatorHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOrphanReport) DeepCopyInto(out *NSXOrphanReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOrphanReport.
+func (in *NSXOrphanReport) DeepCopy() *NSXOrphanReport {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOrphanReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NSXOrphanReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOrphanReportList) DeepCopyInto(out *NSXOrphanReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NSXOrphanReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOrphanReportList.
+func (in *NSXOrphanReportList) DeepCopy() *NSXOrphanReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOrphanReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NSXOrphanReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOrphanReportStatus) DeepCopyInto(out *NSXOrphanReportStatus) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]NSXOrphanedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOrphanReportStatus.
+func (in *NSXOrphanReportStatus) DeepCopy() *NSXOrphanReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOrphanReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXOrphanedResource) DeepCopyInto(out *NSXOrphanedResource) {
+	*out = *in
+	in.CreationTime.DeepCopyInto(&out.CreationTime)
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXOrphanedResource.
+func (in *NSXOrphanedResource) DeepCopy() *NSXOrphanedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXOrphanedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXProxyEndpoint) DeepCopyInto(out *NSXProxyEndpoint) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]NSXProxyEndpointAddress, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]NSXProxyEndpointPort, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXProxyEndpoint.
+func (in *NSXProxyEndpoint) DeepCopy() *NSXProxyEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXProxyEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXProxyEndpointAddress) DeepCopyInto(out *NSXProxyEndpointAddress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXProxyEndpointAddress.
+func (in *NSXProxyEndpointAddress) DeepCopy() *NSXProxyEndpointAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXProxyEndpointAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXProxyEndpointPort) DeepCopyInto(out *NSXProxyEndpointPort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXProxyEndpointPort.
+func (in *NSXProxyEndpointPort) DeepCopy() *NSXProxyEndpointPort {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXProxyEndpointPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXSecret) DeepCopyInto(out *NSXSecret) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXSecret.
+func (in *NSXSecret) DeepCopy() *NSXSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXServiceAccount) DeepCopyInto(out *NSXServiceAccount) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXServiceAccount.
+func (in *NSXServiceAccount) DeepCopy() *NSXServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NSXServiceAccount) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXServiceAccountList) DeepCopyInto(out *NSXServiceAccountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NSXServiceAccount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXServiceAccountList.
+func (in *NSXServiceAccountList) DeepCopy() *NSXServiceAccountList {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXServiceAccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NSXServiceAccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXServiceAccountSpec) DeepCopyInto(out *NSXServiceAccountSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXServiceAccountSpec.
+func (in *NSXServiceAccountSpec) DeepCopy() *NSXServiceAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXServiceAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NSXServiceAccountStatus) DeepCopyInto(out *NSXServiceAccountStatus) {
+	*out = *in
+	if in.NSXManagers != nil {
+		in, out := &in.NSXManagers, &out.NSXManagers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.ProxyEndpoints.DeepCopyInto(&out.ProxyEndpoints)
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]NSXSecret, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXServiceAccountStatus.
+func (in *NSXServiceAccountStatus) DeepCopy() *NSXServiceAccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NSXServiceAccountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInfo) DeepCopyInto(out *NetworkInfo) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.VPCs != nil {
+		in, out := &in.VPCs, &out.VPCs
+		*out = make([]VPCState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInfo.
+func (in *NetworkInfo) DeepCopy() *NetworkInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkInfo) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInfoList) DeepCopyInto(out *NetworkInfoList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInfoList.
+func (in *NetworkInfoList) DeepCopy() *NetworkInfoList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInfoList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkInfoList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextHop) DeepCopyInto(out *NextHop) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextHop.
+func (in *NextHop) DeepCopy() *NextHop {
+	if in == nil {
+		return nil
+	}
+	out := new(NextHop)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortAddressBinding) DeepCopyInto(out *PortAddressBinding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortAddressBinding.
+func (in *PortAddressBinding) DeepCopy() *PortAddressBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(PortAddressBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortSecurity) DeepCopyInto(out *PortSecurity) {
+	*out = *in
+	if in.SpoofGuard != nil {
+		in, out := &in.SpoofGuard, &out.SpoofGuard
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DHCPServerBlock != nil {
+		in, out := &in.DHCPServerBlock, &out.DHCPServerBlock
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RAGuard != nil {
+		in, out := &in.RAGuard, &out.RAGuard
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BPDUFilter != nil {
+		in, out := &in.BPDUFilter, &out.BPDUFilter
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortSecurity.
+func (in *PortSecurity) DeepCopy() *PortSecurity {
+	if in == nil {
+		return nil
+	}
+	out := new(PortSecurity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixList) DeepCopyInto(out *PrefixList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixList.
+func (in *PrefixList) DeepCopy() *PrefixList {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PrefixList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixListEntry) DeepCopyInto(out *PrefixListEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixListEntry.
+func (in *PrefixListEntry) DeepCopy() *PrefixListEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixListEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixListList) DeepCopyInto(out *PrefixListList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PrefixList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixListList.
+func (in *PrefixListList) DeepCopy() *PrefixListList {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixListList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PrefixListList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixListSpec) DeepCopyInto(out *PrefixListSpec) {
+	*out = *in
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]PrefixListEntry, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixListSpec.
+func (in *PrefixListSpec) DeepCopy() *PrefixListSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixListSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixListStatus) DeepCopyInto(out *PrefixListStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixListStatus.
+func (in *PrefixListStatus) DeepCopy() *PrefixListStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixListStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteAdvertisement) DeepCopyInto(out *RouteAdvertisement) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteAdvertisement.
+func (in *RouteAdvertisement) DeepCopy() *RouteAdvertisement {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteAdvertisement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RouteAdvertisement) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteAdvertisementList) DeepCopyInto(out *RouteAdvertisementList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RouteAdvertisement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteAdvertisementList.
+func (in *RouteAdvertisementList) DeepCopy() *RouteAdvertisementList {
 	if in == nil {
 		return nil
 	}
-	out := new(NSXProxyEndpoint)
+	out := new(RouteAdvertisementList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RouteAdvertisementList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NSXProxyEndpointAddress) DeepCopyInto(out *NSXProxyEndpointAddress) {
+func (in *RouteAdvertisementRule) DeepCopyInto(out *RouteAdvertisementRule) {
 	*out = *in
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]RouteAdvertisementPrefixType, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXProxyEndpointAddress.
-func (in *NSXProxyEndpointAddress) DeepCopy() *NSXProxyEndpointAddress {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteAdvertisementRule.
+func (in *RouteAdvertisementRule) DeepCopy() *RouteAdvertisementRule {
 	if in == nil {
 		return nil
 	}
-	out := new(NSXProxyEndpointAddress)
+	out := new(RouteAdvertisementRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NSXProxyEndpointPort) DeepCopyInto(out *NSXProxyEndpointPort) {
+func (in *RouteAdvertisementSpec) DeepCopyInto(out *RouteAdvertisementSpec) {
 	*out = *in
+	if in.Advertise != nil {
+		in, out := &in.Advertise, &out.Advertise
+		*out = make([]RouteAdvertisementPrefixType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]RouteAdvertisementRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXProxyEndpointPort.
-func (in *NSXProxyEndpointPort) DeepCopy() *NSXProxyEndpointPort {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteAdvertisementSpec.
+func (in *RouteAdvertisementSpec) DeepCopy() *RouteAdvertisementSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NSXProxyEndpointPort)
+	out := new(RouteAdvertisementSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NSXSecret) DeepCopyInto(out *NSXSecret) {
+func (in *RouteAdvertisementStatus) DeepCopyInto(out *RouteAdvertisementStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXSecret.
-func (in *NSXSecret) DeepCopy() *NSXSecret {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteAdvertisementStatus.
+func (in *RouteAdvertisementStatus) DeepCopy() *RouteAdvertisementStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NSXSecret)
+	out := new(RouteAdvertisementStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NSXServiceAccount) DeepCopyInto(out *NSXServiceAccount) {
+func (in *RouteMap) DeepCopyInto(out *RouteMap) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXServiceAccount.
-func (in *NSXServiceAccount) DeepCopy() *NSXServiceAccount {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMap.
+func (in *RouteMap) DeepCopy() *RouteMap {
 	if in == nil {
 		return nil
 	}
-	out := new(NSXServiceAccount)
+	out := new(RouteMap)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NSXServiceAccount) DeepCopyObject() runtime.Object {
+func (in *RouteMap) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -224,31 +1373,51 @@ func (in *NSXServiceAccount) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NSXServiceAccountList) DeepCopyInto(out *NSXServiceAccountList) {
+func (in *RouteMapEntry) DeepCopyInto(out *RouteMapEntry) {
+	*out = *in
+	if in.PrefixLists != nil {
+		in, out := &in.PrefixLists, &out.PrefixLists
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMapEntry.
+func (in *RouteMapEntry) DeepCopy() *RouteMapEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteMapEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteMapList) DeepCopyInto(out *RouteMapList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NSXServiceAccount, len(*in))
+		*out = make([]RouteMap, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXServiceAccountList.
-func (in *NSXServiceAccountList) DeepCopy() *NSXServiceAccountList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMapList.
+func (in *RouteMapList) DeepCopy() *RouteMapList {
 	if in == nil {
 		return nil
 	}
-	out := new(NSXServiceAccountList)
+	out := new(RouteMapList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NSXServiceAccountList) DeepCopyObject() runtime.Object {
+func (in *RouteMapList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -256,57 +1425,45 @@ func (in *NSXServiceAccountList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NSXServiceAccountSpec) DeepCopyInto(out *NSXServiceAccountSpec) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXServiceAccountSpec.
-func (in *NSXServiceAccountSpec) DeepCopy() *NSXServiceAccountSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(NSXServiceAccountSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NSXServiceAccountStatus) DeepCopyInto(out *NSXServiceAccountStatus) {
+func (in *RouteMapSpec) DeepCopyInto(out *RouteMapSpec) {
 	*out = *in
-	if in.NSXManagers != nil {
-		in, out := &in.NSXManagers, &out.NSXManagers
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	in.ProxyEndpoints.DeepCopyInto(&out.ProxyEndpoints)
-	if in.Secrets != nil {
-		in, out := &in.Secrets, &out.Secrets
-		*out = make([]NSXSecret, len(*in))
-		copy(*out, *in)
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]RouteMapEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NSXServiceAccountStatus.
-func (in *NSXServiceAccountStatus) DeepCopy() *NSXServiceAccountStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMapSpec.
+func (in *RouteMapSpec) DeepCopy() *RouteMapSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NSXServiceAccountStatus)
+	out := new(RouteMapSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NextHop) DeepCopyInto(out *NextHop) {
+func (in *RouteMapStatus) DeepCopyInto(out *RouteMapStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextHop.
-func (in *NextHop) DeepCopy() *NextHop {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMapStatus.
+func (in *RouteMapStatus) DeepCopy() *RouteMapStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NextHop)
+	out := new(RouteMapStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -421,6 +1578,84 @@ func (in *SecurityPolicyPort) DeepCopy() *SecurityPolicyPort {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityPolicyPriorityRange) DeepCopyInto(out *SecurityPolicyPriorityRange) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityPolicyPriorityRange.
+func (in *SecurityPolicyPriorityRange) DeepCopy() *SecurityPolicyPriorityRange {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityPolicyPriorityRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SecurityPolicyPriorityRange) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityPolicyPriorityRangeList) DeepCopyInto(out *SecurityPolicyPriorityRangeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SecurityPolicyPriorityRange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityPolicyPriorityRangeList.
+func (in *SecurityPolicyPriorityRangeList) DeepCopy() *SecurityPolicyPriorityRangeList {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityPolicyPriorityRangeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SecurityPolicyPriorityRangeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityPolicyPriorityRangeSpec) DeepCopyInto(out *SecurityPolicyPriorityRangeSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityPolicyPriorityRangeSpec.
+func (in *SecurityPolicyPriorityRangeSpec) DeepCopy() *SecurityPolicyPriorityRangeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityPolicyPriorityRangeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityPolicyRule) DeepCopyInto(out *SecurityPolicyRule) {
 	*out = *in
@@ -707,6 +1942,21 @@ func (in *Subnet) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetIPUsage) DeepCopyInto(out *SubnetIPUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetIPUsage.
+func (in *SubnetIPUsage) DeepCopy() *SubnetIPUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetIPUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubnetInfo) DeepCopyInto(out *SubnetInfo) {
 	*out = *in
@@ -764,7 +2014,7 @@ func (in *SubnetPort) DeepCopyInto(out *SubnetPort) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -837,6 +2087,16 @@ func (in *SubnetPortList) DeepCopyObject() runtime.Object {
 func (in *SubnetPortSpec) DeepCopyInto(out *SubnetPortSpec) {
 	*out = *in
 	out.AttachmentRef = in.AttachmentRef
+	if in.AddressBindings != nil {
+		in, out := &in.AddressBindings, &out.AddressBindings
+		*out = make([]PortAddressBinding, len(*in))
+		copy(*out, *in)
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(PortSecurity)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetPortSpec.
@@ -876,6 +2136,36 @@ func (in *SubnetPortStatus) DeepCopy() *SubnetPortStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetRequest) DeepCopyInto(out *SubnetRequest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetRequest.
+func (in *SubnetRequest) DeepCopy() *SubnetRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetResult) DeepCopyInto(out *SubnetResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetResult.
+func (in *SubnetResult) DeepCopy() *SubnetResult {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubnetSet) DeepCopyInto(out *SubnetSet) {
 	*out = *in
@@ -991,6 +2281,11 @@ func (in *SubnetSpec) DeepCopyInto(out *SubnetSpec) {
 	}
 	out.AdvancedConfig = in.AdvancedConfig
 	in.DHCPConfig.DeepCopyInto(&out.DHCPConfig)
+	if in.PortSecurity != nil {
+		in, out := &in.PortSecurity, &out.PortSecurity
+		*out = new(PortSecurity)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetSpec.
@@ -1011,6 +2306,21 @@ func (in *SubnetStatus) DeepCopyInto(out *SubnetStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.GatewayAddresses != nil {
+		in, out := &in.GatewayAddresses, &out.GatewayAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DHCPServerAddresses != nil {
+		in, out := &in.DHCPServerAddresses, &out.DHCPServerAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPUsage != nil {
+		in, out := &in.IPUsage, &out.IPUsage
+		*out = new(SubnetIPUsage)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]Condition, len(*in))
@@ -1201,6 +2511,11 @@ func (in *VPCNetworkConfigurationStatus) DeepCopyInto(out *VPCNetworkConfigurati
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExternalIPv4BlocksUsage != nil {
+		in, out := &in.ExternalIPv4BlocksUsage, &out.ExternalIPv4BlocksUsage
+		*out = make([]IPBlockUsage, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPCNetworkConfigurationStatus.
@@ -1228,6 +2543,31 @@ func (in *VPCSpec) DeepCopy() *VPCSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPCState) DeepCopyInto(out *VPCState) {
+	*out = *in
+	if in.GatewayAddresses != nil {
+		in, out := &in.GatewayAddresses, &out.GatewayAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSServers != nil {
+		in, out := &in.DNSServers, &out.DNSServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPCState.
+func (in *VPCState) DeepCopy() *VPCState {
+	if in == nil {
+		return nil
+	}
+	out := new(VPCState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VPCStatus) DeepCopyInto(out *VPCStatus) {
 	*out = *in
@@ -6,25 +6,108 @@ package config
 import (
 	"errors"
 	"flag"
+	"fmt"
 	"io/ioutil"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	ini "gopkg.in/ini.v1"
+	"k8s.io/apimachinery/pkg/labels"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth/csp"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth/jwt"
 )
 
 // TODO replace to yaml
 const (
-	nsxOperatorDefaultConf = "/etc/nsx-operator/nsxop.ini"
-	vcHostCACertPath       = "/etc/vmware/wcp/tls/vmca.pem"
+	nsxOperatorDefaultConf   = "/etc/nsx-operator/nsxop.ini"
+	vcHostCACertPath         = "/etc/vmware/wcp/tls/vmca.pem"
+	defaultOperatorNamespace = "vmware-system-nsx"
+
+	defaultStandbyHeartbeatGroup       = "nsx-operator-heartbeat"
+	defaultLeaderElectionID            = "nsx-operator"
+	defaultLeaderElectionLeaseDuration = 15 * time.Second
+	defaultLeaderElectionRenewDeadline = 10 * time.Second
+	defaultLeaderElectionRetryPeriod   = 2 * time.Second
+
+	// the defaults of the rate limiters of the work queues of controller-runtime
+	defaultWorkqueueBaseDelay  = 5 * time.Millisecond
+	defaultWorkqueueMaxDelay   = 1000 * time.Second
+	defaultWorkqueueQPS        = 10
+	defaultWorkqueueBurst      = 100
+	defaultShutdownGracePeriod = 30 * time.Second
+	defaultWebhookCertValidity = 365 * 24 * time.Hour
+)
+
+const (
+	// DefaultSubnetPrefixLength gives Subnets 64 IPs when they don't specify a size.
+	DefaultSubnetPrefixLength = 26
+	// SubnetExhaustionPolicyFail reports the SubnetSet as exhausted when all its Subnets run out of IPs.
+	SubnetExhaustionPolicyFail = "fail"
+	// SubnetExhaustionPolicyAutoCreate creates one more Subnet for the SubnetSet when all its Subnets run out of IPs.
+	SubnetExhaustionPolicyAutoCreate = "auto_create"
+	// DefaultSubnetSetScaleThreshold is the utilization percentage of the Subnets of a SubnetSet at which one more Subnet is created.
+	DefaultSubnetSetScaleThreshold = 80
+
+	// DefaultExternalIPBlockUsageThreshold is the utilization percentage of an external IP block above which a warning is raised.
+	DefaultExternalIPBlockUsageThreshold = 80
+
+	// LabelTagOverflowPolicyDrop drops the labels which don't fit in the NSX tag limit.
+	LabelTagOverflowPolicyDrop = "drop"
+	// LabelTagOverflowPolicyMerge merges the labels which don't fit in the NSX tag limit into one tag.
+	LabelTagOverflowPolicyMerge = "merge"
+
+	// WebhookFailurePolicyFail rejects the requests the webhooks fail to admit, e.g. while NSX Operator is down.
+	WebhookFailurePolicyFail = "Fail"
+	// WebhookFailurePolicyIgnore admits the requests the webhooks fail to admit, e.g. while NSX Operator is down.
+	WebhookFailurePolicyIgnore = "Ignore"
+
+	// IPAMDriftModeReport reports the IP allocations drifted between NSX Operator and NSX.
+	IPAMDriftModeReport = "report"
+	// IPAMDriftModeRepair reports and repairs the IP allocations drifted between NSX Operator and NSX.
+	IPAMDriftModeRepair = "repair"
+	// IPAMDriftModeDisabled disables the IPAM drift audit.
+	IPAMDriftModeDisabled = "disabled"
+
+	// APIRateModeAIMD halves the API rate limits on 429/503 responses and raises them back step by step.
+	APIRateModeAIMD = "AIMD"
+	// APIRateModeFixRate keeps the API rate limits fixed.
+	APIRateModeFixRate = "FIXRATE"
+
+	// DefaultNsxOrg is the NSX Org holding the NSX Projects.
+	DefaultNsxOrg = "default"
+	// DefaultEnforcementPoint is the enforcement point used if none is configured.
+	DefaultEnforcementPoint = "default"
+	// DefaultTracingSampleRatio traces all the reconciles if tracing is enabled.
+	DefaultTracingSampleRatio = 1.0
+	// DefaultLogDedupInterval is the seconds the repeated identical errors are summarized over.
+	DefaultLogDedupInterval = 60
+
+	minSubnetPrefixLength = 16
+	maxSubnetPrefixLength = 28
+
+	// vpcConnectivityProfileSectionPrefix is the prefix of the sections defining VPC connectivity profiles,
+	// e.g. [vpc_connectivity_profile:dmz] defines the profile dmz.
+	vpcConnectivityProfileSectionPrefix = "vpc_connectivity_profile:"
+	// nsxManagerSectionPrefix is the prefix of the sections defining additional NSX managers, e.g. [nsx_manager:site-b]
+	// defines the NSX manager site-b.
+	nsxManagerSectionPrefix = "nsx_manager:"
 )
 
 var (
 	configFilePath = ""
 	log            = logf.Log.WithName("config")
 	tokenProvider  auth.TokenProvider
+	// disabledControllers is the value of --disable-controllers, it overrides disabled_controllers of the coe section
+	disabledControllers = ""
+	// ipPoolPathPattern matches the paths of the NSX IP pools under /infra or in an NSX Project.
+	ipPoolPathPattern = regexp.MustCompile(`^(/orgs/[^/]+/projects/[^/]+)?/infra/ip-pools/[^/]+$`)
 )
 
 //TODO delete unnecessary config
@@ -35,14 +118,140 @@ type NSXOperatorConfig struct {
 	*NsxConfig
 	*K8sConfig
 	*VCConfig
+	// VPCConnectivityProfiles are the VPC connectivity profiles defined in the config file, keyed by name
+	VPCConnectivityProfiles map[string]*VPCConnectivityProfile `ini:"-"`
+	// NSXManagers are the additional NSX managers defined in the config file, keyed by name
+	NSXManagers map[string]*NSXManagerConfig `ini:"-"`
 }
 
 type DefaultConfig struct {
 	Debug bool `ini:"debug"`
+	// Verbosity of the logs, the level of the --log-level flag is used if it is unset
+	LogLevel int `ini:"log_level"`
+	// Verbosity of the logs of the modules set apart from log_level, in the form of module=verbosity, e.g. nsx.wire=2
+	ModuleLogLevels []string `ini:"module_log_levels"`
+	// Seconds the repeated identical errors are summarized over instead of being logged each time, 0 logs them all
+	LogDedupInterval int `ini:"log_dedup_interval"`
+	// OTLP/HTTP endpoint the traces are exported to, e.g. http://otel-collector:4318, tracing is disabled if it is unset
+	TracingEndpoint string `ini:"tracing_endpoint"`
+	// Ratio of the reconciles traced, in [0, 1]
+	TracingSampleRatio float64 `ini:"tracing_sample_ratio"`
 }
 
 type CoeConfig struct {
-	Cluster string `ini:"cluster"`
+	Cluster          string `ini:"cluster"`
+	EnableVPCNetwork bool   `ini:"enable_vpc_network"`
+	// Prefix length of the Subnets which don't specify ipv4SubnetSize
+	DefaultSubnetPrefixLength int `ini:"default_subnet_prefix_length"`
+	// What to do when all the Subnets of a SubnetSet run out of IPs, fail or auto_create
+	SubnetExhaustionPolicy string `ini:"subnet_exhaustion_policy"`
+	// Utilization percentage of all the Subnets of a SubnetSet at which one more Subnet is created, only with auto_create
+	SubnetSetScaleThreshold int `ini:"subnetset_scale_threshold"`
+	// Utilization percentage of an external IP block above which a warning is raised, 80 if it is not set
+	ExternalIPBlockUsageThreshold *int `ini:"external_ip_block_usage_threshold"`
+	// MAC ranges the MACs of the SubnetPorts are allocated from, e.g. 02:50:56:00:00:00-02:50:56:00:ff:ff,
+	// NSX allocates the MACs if it is empty
+	MACPoolRanges []string `ini:"mac_pool_ranges"`
+	// Keys of the Namespace and Pod labels propagated to the tags of the NSX Subnets, ports and groups
+	LabelTagKeys []string `ini:"label_tag_keys"`
+	// What to do with the labels beyond the NSX tag limit, drop or merge
+	LabelTagOverflowPolicy string `ini:"label_tag_overflow_policy"`
+	// Paths of the NSX IP pools the VIPs of the LoadBalancer Services are allocated from, in the order of preference
+	LBVIPPools []string `ini:"lb_vip_pools"`
+	// Enable the admission webhook rejecting the Subnets, IPPools and StaticRoutes whose CIDRs overlap
+	EnableCIDRWebhook bool `ini:"enable_cidr_webhook"`
+	// Enable the admission webhook rejecting the StaticRoutes which duplicate or shadow the routes of their Namespace
+	EnableStaticRouteWebhook bool `ini:"enable_staticroute_webhook"`
+	// Enable the admission webhook filling in the defaults of the fields omitted in the CRs
+	EnableDefaultingWebhook bool `ini:"enable_defaulting_webhook"`
+	// Enable the admission webhook rejecting the SecurityPolicies whose priorities are out of the
+	// SecurityPolicyPriorityRanges of their Namespaces
+	EnableSecurityPolicyWebhook bool `ini:"enable_securitypolicy_webhook"`
+	// Enable the admission webhook annotating the SecurityPolicies of the dry-run requests with what realizing them
+	// would create on NSX
+	EnableSecurityPolicyPreviewWebhook bool `ini:"enable_securitypolicy_preview_webhook"`
+	// Enable the admission webhook rejecting the Namespaces whose annotations select what NSX Operator can't realize
+	EnableNamespaceWebhook bool `ini:"enable_namespace_webhook"`
+	// Enable the admission webhook rejecting the deletion of the IPPools, Subnets, SubnetSets and VPCs in use
+	EnableDeletionWebhook bool `ini:"enable_deletion_webhook"`
+	// Enable the admission webhook rejecting the updates of the immutable fields of the CRs no other webhook validates
+	EnableImmutableWebhook bool `ini:"enable_immutable_webhook"`
+	// Namespace/name of the Secret of the webhook serving certificate NSX Operator generates and rotates itself, the
+	// certificate is provisioned by others, e.g. cert-manager, if it is unset
+	WebhookCertSecret string `ini:"webhook_cert_secret"`
+	// Name of the Service of the webhooks in the Namespace of WebhookCertSecret, or of NSX Operator if it is unset
+	WebhookService string `ini:"webhook_service"`
+	// Days the generated webhook certificates are valid for, they are rotated once two thirds of it elapsed
+	WebhookCertValidity int `ini:"webhook_cert_validity"`
+	// Failure policy of the webhooks of WebhookService, Fail or Ignore, the one of the webhook configurations is kept
+	// if it is unset
+	WebhookFailurePolicy string `ini:"webhook_failure_policy"`
+	// Namespaces the webhooks of WebhookService don't intercept the requests of, e.g. kube-system
+	WebhookExcludedNamespaces []string `ini:"webhook_excluded_namespaces"`
+	// CIDRs of the transport network, the CIDRs of the Subnets, IPPools and StaticRoutes must not overlap with them
+	TransportCIDRs []string `ini:"transport_cidrs"`
+	// CIDRs of the external network, the CIDRs of the Subnets, IPPools and StaticRoutes must not overlap with them
+	ExternalCIDRs []string `ini:"external_cidrs"`
+	// What the periodic IPAM drift audit does with the drifted IP allocations, report, repair or disabled
+	IPAMDriftMode string `ini:"ipam_drift_mode"`
+	// Seconds between the runs of the garbage collectors deleting the NSX resources whose CRs no longer exist,
+	// 60 if it is unset
+	GCInterval int `ini:"gc_interval"`
+	// Whether the garbage collectors only log and record as Events the NSX resources they would delete
+	GCDryRun bool `ini:"gc_dry_run"`
+	// Resource types whose garbage collectors run in dry run even if gc_dry_run is unset, e.g. vpc,subnet, the
+	// resource types are the names of their controllers
+	GCDryRunResources []string `ini:"gc_dry_run_resources"`
+	// Most NSX resources a garbage collector deletes in one run, the run is aborted if it would delete more, 0 if there
+	// is no limit
+	GCMaxDeletions int `ini:"gc_max_deletions"`
+	// Most NSX resources a garbage collector deletes in one run in percent of the NSX resources it scans, the run is
+	// aborted if it would delete more, 0 if there is no limit
+	GCMaxDeletionPercent int `ini:"gc_max_deletion_percent"`
+	// Seconds between the resyncs of the stores with the NSX resources modified since their last sync, 300 if it is
+	// unset
+	StoreResyncInterval int `ini:"store_resync_interval"`
+	// Seconds between the resyncs of all the CRs, every CR is reconciled again to repair the NSX resources changed out
+	// of band even if the CR doesn't change, 36000 if it is unset
+	CRResyncPeriod int `ini:"cr_resync_period"`
+	// Directory the snapshots of the stores are written to and loaded from when NSX Operator starts, e.g. the mount path
+	// of a PersistentVolumeClaim, the stores are not snapshotted if it is unset
+	StoreSnapshotDir string `ini:"store_snapshot_dir"`
+	// Seconds a reconcile can run before the liveness probe fails and NSX Operator is restarted, 1800 if it is unset
+	StuckReconcileTimeout int `ini:"stuck_reconcile_timeout"`
+	// Features turned on or off regardless of the NSX capabilities, in the form of feature=true|false, e.g. VPC=false
+	FeatureGates []string `ini:"feature_gates"`
+	// Controllers which are not started, e.g. staticroute, they register neither watches nor webhooks
+	DisabledControllers []string `ini:"disabled_controllers"`
+	// Numbers of the workers reconciling the CRs of the controllers, in the form of controller=workers, e.g.
+	// securitypolicy=16, the defaults are used for the controllers absent
+	MaxConcurrentReconciles []string `ini:"max_concurrent_reconciles"`
+	// Milliseconds the CRs failing to reconcile are first requeued after, the delay doubles on each failure up to
+	// workqueue_max_delay, 5 if it is unset
+	WorkqueueBaseDelay int `ini:"workqueue_base_delay"`
+	// Most seconds the CRs failing to reconcile are requeued after, 1000 if it is unset
+	WorkqueueMaxDelay int `ini:"workqueue_max_delay"`
+	// Rate per second and burst of the requeues of the CRs failing to reconcile of each controller, 10 and 100 if they
+	// are unset
+	WorkqueueQPS   float64 `ini:"workqueue_qps"`
+	WorkqueueBurst int     `ini:"workqueue_burst"`
+	// Seconds the reconciles and the NSX writes in flight are given to complete once NSX Operator is asked to stop,
+	// before the stores are snapshotted and it exits, 30 if it is unset
+	ShutdownGracePeriod int `ini:"shutdown_grace_period"`
+	// Seconds NSX Operator waits for the CRDs of its controllers to be established and for NSX to answer before it
+	// exits, it waits until they are ready if it is unset
+	StartupTimeout int `ini:"startup_timeout"`
+	// Whether the stores of the NSX rules and groups only hold their IDs, paths, revisions, tags and the hashes of their
+	// compared fields instead of the whole NSX resources, to reduce the memory of NSX Operator in large clusters
+	CompactStores bool `ini:"compact_stores"`
+	// Number of the NSX resource types listed in parallel into the stores when NSX Operator starts, 8 if it is unset
+	StorePrefetchWorkers int `ini:"store_prefetch_workers"`
+}
+
+// MACRange is a range of MACs from Start to End inclusive, the MACs are represented as integers.
+type MACRange struct {
+	Start uint64
+	End   uint64
 }
 
 type NsxConfig struct {
@@ -56,6 +265,74 @@ type NsxConfig struct {
 	Insecure             bool     `ini:"insecure"`
 	SingleTierSrTopology bool     `ini:"single_tier_sr_topology"`
 	EnforcementPoint     string   `ini:"enforcement_point"`
+	// Enforcement points of the default site the Namespaces may select with the nsx.vmware.com/enforcement-point
+	// annotation, EnforcementPoint is used for the Namespaces without the annotation
+	EnforcementPoints []string `ini:"enforcement_points"`
+	// NSX Org and Project the resources are created in, the resources are created under /infra if NsxProject is empty
+	NsxOrg     string `ini:"nsx_org"`
+	NsxProject string `ini:"nsx_project"`
+	// ID of the NSX Tier-1 gateway the StaticRoutes of the Namespaces without VPCs are programmed on
+	Tier1Gateway string `ini:"tier1_gateway"`
+	// ID of the NSX Tier-0 gateway the PrefixLists and RouteMaps are created on
+	Tier0Gateway string `ini:"tier0_gateway"`
+	// Algorithm adjusting the API rate limits, AIMD lowers the rates on 429/503 responses while FIXRATE keeps them
+	APIRateMode string `ini:"api_rate_mode"`
+	// Max rates per second of the read and write requests of the Policy and MP APIs sent to each NSX manager,
+	// the defaults are used if they are unset
+	APIPolicyReadRateLimit  int `ini:"api_policy_read_rate_limit"`
+	APIPolicyWriteRateLimit int `ini:"api_policy_write_rate_limit"`
+	APIMPReadRateLimit      int `ini:"api_mp_read_rate_limit"`
+	APIMPWriteRateLimit     int `ini:"api_mp_write_rate_limit"`
+	// File of the CSP refresh token, if it is set the requests to VMC NSX carry the access tokens exchanged for it
+	// instead of the basic auth or VC JWT
+	CSPRefreshTokenFile string `ini:"csp_refresh_token_file"`
+	// URL of the CSP issuing the access tokens, csp.DefaultCSPURL is used if it is unset
+	CSPURL string `ini:"csp_url"`
+	// kubernetes.io/tls Secret in the form of namespace/name holding the certificate and private key of the Principal
+	// Identity NSX Operator authenticates with, instead of NsxApiCertFile and NsxApiPrivateKeyFile
+	NsxApiCertSecret string `ini:"nsx_api_cert_secret"`
+	// kubernetes.io/basic-auth Secret in the form of namespace/name holding the username and password NSX Operator
+	// authenticates with, instead of NsxApiUser and NsxApiPassword
+	NsxApiCredentialSecret string `ini:"nsx_api_credential_secret"`
+	// Address of the HashiCorp Vault holding the username and password NSX Operator authenticates with, instead of
+	// NsxApiUser and NsxApiPassword, e.g. https://vault:8200. NSX Operator logs in to Vault with its service account
+	// token and VaultRole, and reads the username and password fields of the secret at VaultSecretPath
+	VaultAddr string `ini:"vault_addr"`
+	// Vault role NSX Operator logs in with
+	VaultRole string `ini:"vault_role"`
+	// Path of the Vault secret, e.g. secret/data/nsx for a KV version 2 secrets engine
+	VaultSecretPath string `ini:"vault_secret_path"`
+	// Path the Kubernetes auth method is enabled at in Vault, kubernetes if it is unset
+	VaultAuthPath string `ini:"vault_auth_path"`
+	// CA file verifying the certificate of Vault, the system CAs are used if it is unset
+	VaultCAFile string `ini:"vault_ca_file"`
+	// Name of the Principal Identity the rotated certificates are registered to
+	PrincipalIdentity string `ini:"principal_identity"`
+	// Settings of the HTTP connections to the NSX managers, the defaults are used if they are unset.
+	// MaxIdleConns bounds the idle connections to all the NSX managers, MaxIdleConnsPerHost and MaxConnsPerHost
+	// bound the idle and total connections to each NSX manager
+	MaxIdleConns        int `ini:"max_idle_conns"`
+	MaxIdleConnsPerHost int `ini:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int `ini:"max_conns_per_host"`
+	// Seconds an idle connection is kept before it is closed
+	ConnIdleTimeout int `ini:"conn_idle_timeout"`
+	// Seconds between the TCP keep-alive probes of the connections
+	TCPKeepAlive int `ini:"tcp_keep_alive"`
+	// Seconds the read and write requests to NSX may take, the default HTTP timeout is used if they are unset
+	ReadTimeout  int `ini:"read_timeout"`
+	WriteTimeout int `ini:"write_timeout"`
+	// Seconds NSX Operator waits for NSX to realize a resource before it checks again later, the resource is checked
+	// once if it is unset
+	RealizationTimeout int `ini:"realization_timeout"`
+	// URL of the HTTP, HTTPS or SOCKS5 proxy the NSX managers are reached through, e.g. http://proxy:3128
+	ProxyURL string `ini:"proxy_url"`
+	// Hosts, domains and CIDRs of the NSX managers reached directly instead of through the proxy
+	NoProxy []string `ini:"no_proxy"`
+	// kubernetes.io/basic-auth Secret in the form of namespace/name holding the username and password of the proxy
+	ProxyAuthSecret string `ini:"proxy_auth_secret"`
+	// Milliseconds the writes to an NSX intent path are held to coalesce the successive writes to it into one PATCH,
+	// the writes are sent right away if it is unset
+	WriteCoalesceDelay int `ini:"write_coalesce_delay"`
 }
 
 type K8sConfig struct {
@@ -65,6 +342,35 @@ type K8sConfig struct {
 	EnableRestore      bool   `ini:"enable_restore"`
 	EnablePromMetrics  bool   `ini:"enable_prometheus_metrics"`
 	KubeConfigFile     string `ini:"kubeconfig"`
+	// Record the mutations sent to NSX as Events on the CRs initiating them too
+	EnableAuditEvents bool `ini:"enable_audit_events"`
+	// Namespace where NSX Operator is deployed
+	OperatorNamespace string `ini:"operator_namespace"`
+	// Elect a leader among the replicas of NSX Operator, only the leader reconciles the CRs and mutates NSX, it must be
+	// set if NSX Operator runs with more than one replica
+	EnableLeaderElection bool `ini:"enable_leader_election"`
+	// Namespace of the Lease of the leader election, operator_namespace if it is unset
+	LeaderElectionNamespace string `ini:"leader_election_namespace"`
+	// Name of the Lease of the leader election, nsx-operator if it is unset
+	LeaderElectionID string `ini:"leader_election_id"`
+	// Seconds the replicas which are not the leader wait before acquiring the Lease not renewed, 15 if it is unset
+	LeaderElectionLeaseDuration int `ini:"leader_election_lease_duration"`
+	// Seconds the leader retries renewing the Lease before it gives up leading, 10 if it is unset
+	LeaderElectionRenewDeadline int `ini:"leader_election_renew_deadline"`
+	// Seconds between the tries to acquire or renew the Lease, 2 if it is unset
+	LeaderElectionRetryPeriod int `ini:"leader_election_retry_period"`
+	// Share the namespaces among the replicas of NSX Operator, each replica reconciles the CRs of the namespaces it
+	// owns, it can't be set with enable_leader_election. The replicas hold Leases in leader_election_namespace with the
+	// durations of the leader election
+	EnableSharding bool `ini:"enable_sharding"`
+	// Elect the active NSX Operator among the ones of the management clusters sharing NSX, e.g. in the stretched
+	// topologies, the standby ones keep their stores synced and take over once the heartbeat of the active one in NSX
+	// expires. The heartbeat is renewed with the durations of the leader election, it can't be set with
+	// enable_sharding
+	EnableStandby bool `ini:"enable_standby"`
+	// ID of the NSX Group of the default domain holding the heartbeat, it must be the same in all the clusters,
+	// nsx-operator-heartbeat if it is unset
+	StandbyHeartbeatGroup string `ini:"standby_heartbeat_group"`
 	// Controlled by FSS
 	EnableAntreaNSXInterworking bool `ini:"enable_antrea_nsx_interworking"`
 }
@@ -75,6 +381,27 @@ type VCConfig struct {
 	HttpsPort  int    `ini:"https_port"`
 }
 
+// VPCConnectivityProfile decides how the VPCs of the Namespaces selecting it are connected and protected.
+type VPCConnectivityProfile struct {
+	Name string `ini:"-"`
+	// Connect the VPCs to the default gateway of their VPCNetworkConfiguration
+	ExternalConnectivity bool `ini:"external_connectivity"`
+	// SNAT the traffic leaving the VPCs, it requires external_connectivity
+	EnableSNAT bool `ini:"enable_snat"`
+	// Action of the default rule applied to the VPCs, ALLOW, DROP or REJECT, no default rule if empty
+	DefaultRuleAction string `ini:"default_rule_action"`
+}
+
+// NSXManagerConfig is an additional NSX manager serving the Namespaces selected by its Namespace selector, the other
+// Namespaces are served by the NSX manager of the nsx_v3 section.
+type NSXManagerConfig struct {
+	Name string `ini:"-"`
+	// Label selector of the Namespaces served by the NSX manager, e.g. site=b
+	NamespaceSelector string `ini:"namespace_selector"`
+	// NSX options of the NSX manager, the ones absent from its section are inherited from the nsx_v3 section
+	NsxConfig *NsxConfig `ini:"-"`
+}
+
 type Validate interface {
 	validate() error
 }
@@ -85,6 +412,8 @@ type NsxVersion struct {
 
 func AddFlags() {
 	flag.StringVar(&configFilePath, "nsxconfig", nsxOperatorDefaultConf, "NSX Operator configuration file path")
+	flag.Var(DefaultFeatureGates, "feature-gates", DefaultFeatureGates.usage())
+	flag.StringVar(&disabledControllers, "disable-controllers", "", "Comma separated controllers which are not started, one of "+strings.Join(Controllers, ", "))
 }
 
 func UpdateConfigFilePath(configFile string) {
@@ -103,6 +432,11 @@ func NewNSXOperatorConfigFromFile() (*NSXOperatorConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	applyEnvOverrides(cfg, nsxOperatorConfig)
+	applyOverrides(cfg)
+	if disabledControllers != "" {
+		cfg.Section("coe").Key("disabled_controllers").SetValue(disabledControllers)
+	}
 	err = cfg.Section("DEFAULT").MapTo(nsxOperatorConfig.DefaultConfig)
 	if err != nil {
 		return nil, err
@@ -123,6 +457,33 @@ func NewNSXOperatorConfigFromFile() (*NSXOperatorConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	for _, section := range cfg.Sections() {
+		if !strings.HasPrefix(section.Name(), vpcConnectivityProfileSectionPrefix) {
+			continue
+		}
+		profile := &VPCConnectivityProfile{Name: strings.TrimPrefix(section.Name(), vpcConnectivityProfileSectionPrefix)}
+		if err := section.MapTo(profile); err != nil {
+			return nil, err
+		}
+		nsxOperatorConfig.VPCConnectivityProfiles[profile.Name] = profile
+	}
+	for _, section := range cfg.Sections() {
+		if !strings.HasPrefix(section.Name(), nsxManagerSectionPrefix) {
+			continue
+		}
+		nsxConfig := *nsxOperatorConfig.NsxConfig
+		manager := &NSXManagerConfig{Name: strings.TrimPrefix(section.Name(), nsxManagerSectionPrefix), NsxConfig: &nsxConfig}
+		// the NSX managers and thumbprints are specific to each NSX manager
+		manager.NsxConfig.NsxApiManagers = nil
+		manager.NsxConfig.Thumbprint = nil
+		if err := section.MapTo(manager); err != nil {
+			return nil, err
+		}
+		if err := section.MapTo(manager.NsxConfig); err != nil {
+			return nil, err
+		}
+		nsxOperatorConfig.NSXManagers[manager.Name] = manager
+	}
 
 	if err := nsxOperatorConfig.validate(); err != nil {
 		return nil, err
@@ -133,24 +494,49 @@ func NewNSXOperatorConfigFromFile() (*NSXOperatorConfig, error) {
 
 func NewNSXOpertorConfig() *NSXOperatorConfig {
 	defaultNSXOperatorConfig := &NSXOperatorConfig{
-		&DefaultConfig{},
+		&DefaultConfig{
+			TracingSampleRatio: DefaultTracingSampleRatio,
+			LogDedupInterval:   DefaultLogDedupInterval,
+		},
 		&CoeConfig{
-			"",
+			Cluster:                   "",
+			EnableVPCNetwork:          false,
+			DefaultSubnetPrefixLength: DefaultSubnetPrefixLength,
+			SubnetExhaustionPolicy:    SubnetExhaustionPolicyFail,
+			SubnetSetScaleThreshold:   DefaultSubnetSetScaleThreshold,
 		},
 		&NsxConfig{},
 		&K8sConfig{},
 		&VCConfig{},
+		map[string]*VPCConnectivityProfile{},
+		map[string]*NSXManagerConfig{},
 	}
 	return defaultNSXOperatorConfig
 }
 
 func (operatorConfig *NSXOperatorConfig) validate() error {
+	if err := operatorConfig.DefaultConfig.validate(); err != nil {
+		return err
+	}
 	if err := operatorConfig.CoeConfig.validate(); err != nil {
 		return err
 	}
 	if err := operatorConfig.NsxConfig.validate(); err != nil {
 		return err
 	}
+	if err := operatorConfig.K8sConfig.validate(); err != nil {
+		return err
+	}
+	for _, profile := range operatorConfig.VPCConnectivityProfiles {
+		if err := profile.validate(); err != nil {
+			return err
+		}
+	}
+	for _, manager := range operatorConfig.NSXManagers {
+		if err := manager.validate(); err != nil {
+			return err
+		}
+	}
 	// TODO, verify if user&pwd, cert, jwt has any of them provided
 	return nil
 }
@@ -164,6 +550,15 @@ func (operatorConfig *NSXOperatorConfig) GetTokenProvider() auth.TokenProvider {
 }
 
 func (operatorConfig *NSXOperatorConfig) createTokenProvider() error {
+	if operatorConfig.CSPRefreshTokenFile != "" {
+		log.V(2).Info("use CSP token provider", "url", operatorConfig.CSPURL)
+		provider, err := csp.NewTokenProvider(operatorConfig.CSPURL, operatorConfig.CSPRefreshTokenFile)
+		if err != nil {
+			return err
+		}
+		tokenProvider = provider
+		return nil
+	}
 	log.V(2).Info("try to load VC host CA")
 	var vcCaCert []byte
 	var err error
@@ -182,6 +577,75 @@ func (operatorConfig *NSXOperatorConfig) createTokenProvider() error {
 	return nil
 }
 
+// GetOperatorNamespace returns the namespace where NSX Operator is deployed.
+func (k8sConfig *K8sConfig) GetOperatorNamespace() string {
+	if k8sConfig.OperatorNamespace == "" {
+		return defaultOperatorNamespace
+	}
+	return k8sConfig.OperatorNamespace
+}
+
+// GetLeaderElectionNamespace returns the namespace of the Lease of the leader election.
+func (k8sConfig *K8sConfig) GetLeaderElectionNamespace() string {
+	if k8sConfig.LeaderElectionNamespace == "" {
+		return k8sConfig.GetOperatorNamespace()
+	}
+	return k8sConfig.LeaderElectionNamespace
+}
+
+// GetLeaderElectionID returns the name of the Lease of the leader election.
+func (k8sConfig *K8sConfig) GetLeaderElectionID() string {
+	if k8sConfig.LeaderElectionID == "" {
+		return defaultLeaderElectionID
+	}
+	return k8sConfig.LeaderElectionID
+}
+
+// GetStandbyHeartbeatGroup returns the ID of the NSX Group holding the heartbeat of the active NSX Operator.
+func (k8sConfig *K8sConfig) GetStandbyHeartbeatGroup() string {
+	if k8sConfig.StandbyHeartbeatGroup == "" {
+		return defaultStandbyHeartbeatGroup
+	}
+	return k8sConfig.StandbyHeartbeatGroup
+}
+
+// GetLeaderElectionDurations returns the lease duration, the renew deadline and the retry period of the leader
+// election.
+func (k8sConfig *K8sConfig) GetLeaderElectionDurations() (time.Duration, time.Duration, time.Duration) {
+	seconds := func(value int, defaultValue time.Duration) time.Duration {
+		if value == 0 {
+			return defaultValue
+		}
+		return time.Duration(value) * time.Second
+	}
+	return seconds(k8sConfig.LeaderElectionLeaseDuration, defaultLeaderElectionLeaseDuration),
+		seconds(k8sConfig.LeaderElectionRenewDeadline, defaultLeaderElectionRenewDeadline),
+		seconds(k8sConfig.LeaderElectionRetryPeriod, defaultLeaderElectionRetryPeriod)
+}
+
+func (k8sConfig *K8sConfig) validate() error {
+	if k8sConfig.EnableSharding && (k8sConfig.EnableLeaderElection || k8sConfig.EnableStandby) {
+		err := errors.New("invalid field " + "EnableSharding")
+		log.Error(err, "validate K8sConfig failed, the sharding and the leader election can't be both enabled")
+		return err
+	}
+	if k8sConfig.LeaderElectionLeaseDuration < 0 || k8sConfig.LeaderElectionRenewDeadline < 0 || k8sConfig.LeaderElectionRetryPeriod < 0 {
+		err := errors.New("invalid field " + "LeaderElection")
+		log.Error(err, "validate K8sConfig failed", "LeaseDuration", k8sConfig.LeaderElectionLeaseDuration,
+			"RenewDeadline", k8sConfig.LeaderElectionRenewDeadline, "RetryPeriod", k8sConfig.LeaderElectionRetryPeriod)
+		return err
+	}
+	// the leader must give up leading before the other replicas acquire the Lease
+	leaseDuration, renewDeadline, retryPeriod := k8sConfig.GetLeaderElectionDurations()
+	if renewDeadline >= leaseDuration || retryPeriod >= renewDeadline {
+		err := errors.New("invalid field " + "LeaderElection")
+		log.Error(err, "validate K8sConfig failed, the retry period must be shorter than the renew deadline, which must be shorter than the lease duration",
+			"LeaseDuration", leaseDuration, "RenewDeadline", renewDeadline, "RetryPeriod", retryPeriod)
+		return err
+	}
+	return nil
+}
+
 func (vcConfig *VCConfig) validate() error {
 	if len(vcConfig.VCEndPoint) == 0 {
 		err := errors.New("invalid field " + "VcEndPoint")
@@ -210,6 +674,105 @@ func (nsxConfig *NsxConfig) validate() error {
 		log.Error(err, "validate NsxConfig failed", "NsxApiManagers", nsxConfig.NsxApiManagers)
 		return err
 	}
+	if len(nsxConfig.NsxOrg) > 0 && len(nsxConfig.NsxProject) == 0 {
+		err := errors.New("invalid field " + "NsxProject")
+		log.Error(err, "validate NsxConfig failed", "NsxOrg", nsxConfig.NsxOrg)
+		return err
+	}
+	if (nsxConfig.NsxApiCertFile == "") != (nsxConfig.NsxApiPrivateKeyFile == "") {
+		err := errors.New("invalid field " + "NsxApiPrivateKeyFile")
+		log.Error(err, "validate NsxConfig failed", "NsxApiCertFile", nsxConfig.NsxApiCertFile, "NsxApiPrivateKeyFile", nsxConfig.NsxApiPrivateKeyFile)
+		return err
+	}
+	if nsxConfig.NsxApiCertSecret != "" {
+		if nsxConfig.NsxApiCertFile != "" {
+			err := errors.New("only one of NsxApiCertFile and NsxApiCertSecret can be set")
+			log.Error(err, "validate NsxConfig failed")
+			return err
+		}
+		if parts := strings.Split(nsxConfig.NsxApiCertSecret, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			err := errors.New("invalid field " + "NsxApiCertSecret")
+			log.Error(err, "validate NsxConfig failed", "NsxApiCertSecret", nsxConfig.NsxApiCertSecret)
+			return err
+		}
+	}
+	if nsxConfig.NsxApiCredentialSecret != "" {
+		if nsxConfig.NsxApiUser != "" || nsxConfig.NsxApiPassword != "" {
+			err := errors.New("only one of NsxApiUser/NsxApiPassword and NsxApiCredentialSecret can be set")
+			log.Error(err, "validate NsxConfig failed")
+			return err
+		}
+		if parts := strings.Split(nsxConfig.NsxApiCredentialSecret, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			err := errors.New("invalid field " + "NsxApiCredentialSecret")
+			log.Error(err, "validate NsxConfig failed", "NsxApiCredentialSecret", nsxConfig.NsxApiCredentialSecret)
+			return err
+		}
+	}
+	if nsxConfig.VaultAddr != "" {
+		if nsxConfig.NsxApiUser != "" || nsxConfig.NsxApiPassword != "" || nsxConfig.NsxApiCredentialSecret != "" {
+			err := errors.New("only one of NsxApiUser/NsxApiPassword, NsxApiCredentialSecret and VaultAddr can be set")
+			log.Error(err, "validate NsxConfig failed")
+			return err
+		}
+		if nsxConfig.VaultRole == "" || nsxConfig.VaultSecretPath == "" {
+			err := errors.New("invalid field " + "VaultRole/VaultSecretPath")
+			log.Error(err, "validate NsxConfig failed", "VaultRole", nsxConfig.VaultRole, "VaultSecretPath", nsxConfig.VaultSecretPath)
+			return err
+		}
+	}
+	if len(nsxConfig.EnforcementPoints) > 0 && !nsxConfig.EnforcementPointAllowed(nsxConfig.GetEnforcementPoint()) {
+		err := errors.New("invalid field " + "EnforcementPoint")
+		log.Error(err, "validate NsxConfig failed", "EnforcementPoint", nsxConfig.GetEnforcementPoint(), "EnforcementPoints", nsxConfig.EnforcementPoints)
+		return err
+	}
+	if nsxConfig.APIRateMode != "" && nsxConfig.APIRateMode != APIRateModeAIMD && nsxConfig.APIRateMode != APIRateModeFixRate {
+		err := errors.New("invalid field " + "APIRateMode")
+		log.Error(err, "validate NsxConfig failed", "APIRateMode", nsxConfig.APIRateMode)
+		return err
+	}
+	for _, limit := range []int{nsxConfig.APIPolicyReadRateLimit, nsxConfig.APIPolicyWriteRateLimit, nsxConfig.APIMPReadRateLimit, nsxConfig.APIMPWriteRateLimit} {
+		if limit < 0 {
+			err := errors.New("invalid API rate limit")
+			log.Error(err, "validate NsxConfig failed", "limit", limit)
+			return err
+		}
+	}
+	for _, v := range []int{nsxConfig.MaxIdleConns, nsxConfig.MaxIdleConnsPerHost, nsxConfig.MaxConnsPerHost, nsxConfig.ConnIdleTimeout, nsxConfig.TCPKeepAlive} {
+		if v < 0 {
+			err := errors.New("invalid HTTP connection setting")
+			log.Error(err, "validate NsxConfig failed", "value", v)
+			return err
+		}
+	}
+	for _, v := range []int{nsxConfig.ReadTimeout, nsxConfig.WriteTimeout, nsxConfig.RealizationTimeout, nsxConfig.WriteCoalesceDelay} {
+		if v < 0 {
+			err := errors.New("invalid timeout")
+			log.Error(err, "validate NsxConfig failed", "value", v)
+			return err
+		}
+	}
+	if nsxConfig.ProxyURL != "" {
+		proxyURL, err := url.Parse(nsxConfig.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			err := errors.New("invalid field " + "ProxyURL")
+			log.Error(err, "validate NsxConfig failed", "ProxyURL", nsxConfig.ProxyURL)
+			return err
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			err := errors.New("invalid field " + "ProxyURL")
+			log.Error(err, "validate NsxConfig failed", "ProxyURL", nsxConfig.ProxyURL)
+			return err
+		}
+	}
+	if nsxConfig.ProxyAuthSecret != "" {
+		if parts := strings.Split(nsxConfig.ProxyAuthSecret, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			err := errors.New("invalid field " + "ProxyAuthSecret")
+			log.Error(err, "validate NsxConfig failed", "ProxyAuthSecret", nsxConfig.ProxyAuthSecret)
+			return err
+		}
+	}
 	tpCount := len(nsxConfig.Thumbprint)
 	if tpCount == 0 {
 		log.V(1).Info("no thumbprint provided")
@@ -227,11 +790,476 @@ func (nsxConfig *NsxConfig) validate() error {
 	return nil
 }
 
+func (defaultConfig *DefaultConfig) validate() error {
+	if _, err := defaultConfig.GetModuleLogLevels(); err != nil {
+		log.Error(err, "validate DefaultConfig failed", "ModuleLogLevels", defaultConfig.ModuleLogLevels)
+		return err
+	}
+	if defaultConfig.TracingEndpoint != "" {
+		if u, err := url.Parse(defaultConfig.TracingEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			err := errors.New("invalid field TracingEndpoint, it should be an http or https URL")
+			log.Error(err, "validate DefaultConfig failed", "TracingEndpoint", defaultConfig.TracingEndpoint)
+			return err
+		}
+	}
+	if defaultConfig.TracingSampleRatio < 0 || defaultConfig.TracingSampleRatio > 1 {
+		err := errors.New("invalid field TracingSampleRatio, it should be in [0, 1]")
+		log.Error(err, "validate DefaultConfig failed", "TracingSampleRatio", defaultConfig.TracingSampleRatio)
+		return err
+	}
+	if defaultConfig.LogDedupInterval < 0 {
+		err := errors.New("invalid field LogDedupInterval, it should not be negative")
+		log.Error(err, "validate DefaultConfig failed", "LogDedupInterval", defaultConfig.LogDedupInterval)
+		return err
+	}
+	return nil
+}
+
 func (coeConfig *CoeConfig) validate() error {
 	if len(coeConfig.Cluster) == 0 {
 		err := errors.New("invalid field " + "Cluster")
 		log.Error(err, "validate coeConfig failed")
 		return err
 	}
+	if coeConfig.DefaultSubnetPrefixLength != 0 && (coeConfig.DefaultSubnetPrefixLength < minSubnetPrefixLength || coeConfig.DefaultSubnetPrefixLength > maxSubnetPrefixLength) {
+		err := fmt.Errorf("invalid field DefaultSubnetPrefixLength, it should be in [%d, %d]", minSubnetPrefixLength, maxSubnetPrefixLength)
+		log.Error(err, "validate coeConfig failed", "DefaultSubnetPrefixLength", coeConfig.DefaultSubnetPrefixLength)
+		return err
+	}
+	switch coeConfig.SubnetExhaustionPolicy {
+	case "", SubnetExhaustionPolicyFail, SubnetExhaustionPolicyAutoCreate:
+	default:
+		err := errors.New("invalid field " + "SubnetExhaustionPolicy")
+		log.Error(err, "validate coeConfig failed", "SubnetExhaustionPolicy", coeConfig.SubnetExhaustionPolicy)
+		return err
+	}
+	if coeConfig.SubnetSetScaleThreshold < 0 || coeConfig.SubnetSetScaleThreshold > 100 {
+		err := errors.New("invalid field SubnetSetScaleThreshold, it should be in [0, 100]")
+		log.Error(err, "validate coeConfig failed", "SubnetSetScaleThreshold", coeConfig.SubnetSetScaleThreshold)
+		return err
+	}
+	if threshold := coeConfig.ExternalIPBlockUsageThreshold; threshold != nil && (*threshold < 0 || *threshold > 100) {
+		err := errors.New("invalid field ExternalIPBlockUsageThreshold, it should be in [0, 100]")
+		log.Error(err, "validate coeConfig failed", "ExternalIPBlockUsageThreshold", *threshold)
+		return err
+	}
+	for _, r := range coeConfig.MACPoolRanges {
+		if _, err := ParseMACRange(r); err != nil {
+			log.Error(err, "validate coeConfig failed", "MACPoolRanges", coeConfig.MACPoolRanges)
+			return err
+		}
+	}
+	switch coeConfig.LabelTagOverflowPolicy {
+	case "", LabelTagOverflowPolicyDrop, LabelTagOverflowPolicyMerge:
+	default:
+		err := errors.New("invalid field " + "LabelTagOverflowPolicy")
+		log.Error(err, "validate coeConfig failed", "LabelTagOverflowPolicy", coeConfig.LabelTagOverflowPolicy)
+		return err
+	}
+	switch coeConfig.IPAMDriftMode {
+	case "", IPAMDriftModeReport, IPAMDriftModeRepair, IPAMDriftModeDisabled:
+	default:
+		err := errors.New("invalid field " + "IPAMDriftMode")
+		log.Error(err, "validate coeConfig failed", "IPAMDriftMode", coeConfig.IPAMDriftMode)
+		return err
+	}
+	for _, pool := range coeConfig.LBVIPPools {
+		if !ipPoolPathPattern.MatchString(pool) {
+			err := fmt.Errorf("invalid field LBVIPPools, %s is not an IP pool path", pool)
+			log.Error(err, "validate coeConfig failed", "LBVIPPools", coeConfig.LBVIPPools)
+			return err
+		}
+	}
+	if coeConfig.GCInterval < 0 {
+		err := errors.New("invalid field " + "GCInterval")
+		log.Error(err, "validate coeConfig failed", "GCInterval", coeConfig.GCInterval)
+		return err
+	}
+	if coeConfig.StoreResyncInterval < 0 {
+		err := errors.New("invalid field " + "StoreResyncInterval")
+		log.Error(err, "validate coeConfig failed", "StoreResyncInterval", coeConfig.StoreResyncInterval)
+		return err
+	}
+	if coeConfig.CRResyncPeriod < 0 {
+		err := errors.New("invalid field " + "CRResyncPeriod")
+		log.Error(err, "validate coeConfig failed", "CRResyncPeriod", coeConfig.CRResyncPeriod)
+		return err
+	}
+	if coeConfig.StuckReconcileTimeout < 0 {
+		err := errors.New("invalid field " + "StuckReconcileTimeout")
+		log.Error(err, "validate coeConfig failed", "StuckReconcileTimeout", coeConfig.StuckReconcileTimeout)
+		return err
+	}
+	if _, err := coeConfig.GetFeatureGates(); err != nil {
+		log.Error(err, "validate coeConfig failed", "FeatureGates", coeConfig.FeatureGates)
+		return err
+	}
+	if coeConfig.GCMaxDeletions < 0 {
+		err := errors.New("invalid field " + "GCMaxDeletions")
+		log.Error(err, "validate coeConfig failed", "GCMaxDeletions", coeConfig.GCMaxDeletions)
+		return err
+	}
+	if coeConfig.GCMaxDeletionPercent < 0 || coeConfig.GCMaxDeletionPercent > 100 {
+		err := errors.New("invalid field " + "GCMaxDeletionPercent")
+		log.Error(err, "validate coeConfig failed", "GCMaxDeletionPercent", coeConfig.GCMaxDeletionPercent)
+		return err
+	}
+	if err := coeConfig.validateGCDryRunResources(); err != nil {
+		log.Error(err, "validate coeConfig failed", "GCDryRunResources", coeConfig.GCDryRunResources)
+		return err
+	}
+	if err := coeConfig.validateDisabledControllers(); err != nil {
+		log.Error(err, "validate coeConfig failed", "DisabledControllers", coeConfig.DisabledControllers)
+		return err
+	}
+	if coeConfig.WorkqueueBaseDelay < 0 || coeConfig.WorkqueueMaxDelay < 0 || coeConfig.WorkqueueQPS < 0 || coeConfig.WorkqueueBurst < 0 {
+		err := errors.New("invalid field " + "Workqueue")
+		log.Error(err, "validate coeConfig failed", "WorkqueueBaseDelay", coeConfig.WorkqueueBaseDelay, "WorkqueueMaxDelay",
+			coeConfig.WorkqueueMaxDelay, "WorkqueueQPS", coeConfig.WorkqueueQPS, "WorkqueueBurst", coeConfig.WorkqueueBurst)
+		return err
+	}
+	if baseDelay, maxDelay, _, _ := coeConfig.GetWorkqueueRateLimits(); baseDelay > maxDelay {
+		err := errors.New("invalid field " + "WorkqueueBaseDelay")
+		log.Error(err, "validate coeConfig failed, the base delay is longer than the max delay", "WorkqueueBaseDelay",
+			coeConfig.WorkqueueBaseDelay, "WorkqueueMaxDelay", coeConfig.WorkqueueMaxDelay)
+		return err
+	}
+	if coeConfig.ShutdownGracePeriod < 0 {
+		err := errors.New("invalid field " + "ShutdownGracePeriod")
+		log.Error(err, "validate coeConfig failed", "ShutdownGracePeriod", coeConfig.ShutdownGracePeriod)
+		return err
+	}
+	if coeConfig.WebhookCertSecret != "" {
+		if parts := strings.Split(coeConfig.WebhookCertSecret, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			err := errors.New("invalid field " + "WebhookCertSecret")
+			log.Error(err, "validate coeConfig failed", "WebhookCertSecret", coeConfig.WebhookCertSecret)
+			return err
+		}
+		if coeConfig.WebhookService == "" {
+			err := errors.New("WebhookService must be set with WebhookCertSecret")
+			log.Error(err, "validate coeConfig failed")
+			return err
+		}
+	}
+	if coeConfig.WebhookFailurePolicy != "" && coeConfig.WebhookFailurePolicy != WebhookFailurePolicyFail &&
+		coeConfig.WebhookFailurePolicy != WebhookFailurePolicyIgnore {
+		err := errors.New("invalid field " + "WebhookFailurePolicy")
+		log.Error(err, "validate coeConfig failed", "WebhookFailurePolicy", coeConfig.WebhookFailurePolicy)
+		return err
+	}
+	if (coeConfig.WebhookFailurePolicy != "" || len(coeConfig.WebhookExcludedNamespaces) > 0) && coeConfig.WebhookService == "" {
+		err := errors.New("WebhookService must be set with WebhookFailurePolicy or WebhookExcludedNamespaces")
+		log.Error(err, "validate coeConfig failed")
+		return err
+	}
+	if coeConfig.WebhookCertValidity < 0 {
+		err := errors.New("invalid field " + "WebhookCertValidity")
+		log.Error(err, "validate coeConfig failed", "WebhookCertValidity", coeConfig.WebhookCertValidity)
+		return err
+	}
+	if coeConfig.StartupTimeout < 0 {
+		err := errors.New("invalid field " + "StartupTimeout")
+		log.Error(err, "validate coeConfig failed", "StartupTimeout", coeConfig.StartupTimeout)
+		return err
+	}
+	if coeConfig.StorePrefetchWorkers < 0 {
+		err := errors.New("invalid field " + "StorePrefetchWorkers")
+		log.Error(err, "validate coeConfig failed", "StorePrefetchWorkers", coeConfig.StorePrefetchWorkers)
+		return err
+	}
+	if _, err := coeConfig.getMaxConcurrentReconciles(); err != nil {
+		log.Error(err, "validate coeConfig failed", "MaxConcurrentReconciles", coeConfig.MaxConcurrentReconciles)
+		return err
+	}
+	for _, cidr := range append(append([]string{}, coeConfig.TransportCIDRs...), coeConfig.ExternalCIDRs...) {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			log.Error(err, "validate coeConfig failed", "TransportCIDRs", coeConfig.TransportCIDRs, "ExternalCIDRs", coeConfig.ExternalCIDRs)
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultSubnetSize returns the number of IPs of the Subnets which don't specify ipv4SubnetSize.
+func (coeConfig *CoeConfig) DefaultSubnetSize() int {
+	prefixLength := coeConfig.DefaultSubnetPrefixLength
+	if prefixLength == 0 {
+		prefixLength = DefaultSubnetPrefixLength
+	}
+	return 1 << (32 - prefixLength)
+}
+
+// GetIPAMDriftMode returns what the IPAM drift audit does with the drifted IP allocations.
+func (coeConfig *CoeConfig) GetIPAMDriftMode() string {
+	if coeConfig.IPAMDriftMode == "" {
+		return IPAMDriftModeReport
+	}
+	return coeConfig.IPAMDriftMode
+}
+
+// GetModuleLogLevels returns the verbosity of the logs of the modules set apart, keyed by module.
+func (defaultConfig *DefaultConfig) GetModuleLogLevels() (map[string]int, error) {
+	levels := make(map[string]int, len(defaultConfig.ModuleLogLevels))
+	for _, moduleLevel := range defaultConfig.ModuleLogLevels {
+		parts := strings.Split(moduleLevel, "=")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid field ModuleLogLevels, %s is not in the form of module=verbosity", moduleLevel)
+		}
+		verbosity, err := strconv.Atoi(parts[1])
+		if err != nil || verbosity < 0 {
+			return nil, fmt.Errorf("invalid field ModuleLogLevels, %s is not in the form of module=verbosity", moduleLevel)
+		}
+		levels[parts[0]] = verbosity
+	}
+	return levels, nil
+}
+
+// GetFeatureGates returns whether the features of the feature gates are turned on, keyed by feature.
+func (coeConfig *CoeConfig) GetFeatureGates() (map[string]bool, error) {
+	gates := make(map[string]bool, len(coeConfig.FeatureGates))
+	for _, gate := range coeConfig.FeatureGates {
+		feature, enabled, err := parseFeatureGate(gate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field FeatureGates, %v", err)
+		}
+		gates[feature] = enabled
+	}
+	return gates, nil
+}
+
+// GetWorkqueueRateLimits returns the base and the max delays of the requeues of the CRs failing to reconcile, and the
+// rate per second and the burst of the requeues of each controller.
+func (coeConfig *CoeConfig) GetWorkqueueRateLimits() (time.Duration, time.Duration, float64, int) {
+	baseDelay, maxDelay, qps, burst := defaultWorkqueueBaseDelay, defaultWorkqueueMaxDelay, float64(defaultWorkqueueQPS), defaultWorkqueueBurst
+	if coeConfig.WorkqueueBaseDelay > 0 {
+		baseDelay = time.Duration(coeConfig.WorkqueueBaseDelay) * time.Millisecond
+	}
+	if coeConfig.WorkqueueMaxDelay > 0 {
+		maxDelay = time.Duration(coeConfig.WorkqueueMaxDelay) * time.Second
+	}
+	if coeConfig.WorkqueueQPS > 0 {
+		qps = coeConfig.WorkqueueQPS
+	}
+	if coeConfig.WorkqueueBurst > 0 {
+		burst = coeConfig.WorkqueueBurst
+	}
+	return baseDelay, maxDelay, qps, burst
+}
+
+// GetWebhookCertSecret returns the Namespace and the name of the Secret of the self-managed webhook certificate.
+func (coeConfig *CoeConfig) GetWebhookCertSecret() (string, string) {
+	parts := strings.SplitN(coeConfig.WebhookCertSecret, "/", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// GetWebhookCertValidity returns how long the generated webhook certificates are valid for.
+func (coeConfig *CoeConfig) GetWebhookCertValidity() time.Duration {
+	if coeConfig.WebhookCertValidity > 0 {
+		return time.Duration(coeConfig.WebhookCertValidity) * 24 * time.Hour
+	}
+	return defaultWebhookCertValidity
+}
+
+// GetWebhookService returns the Namespace and the name of the Service of the webhooks.
+func (operatorConfig *NSXOperatorConfig) GetWebhookService() (string, string) {
+	namespace, _ := operatorConfig.GetWebhookCertSecret()
+	if namespace == "" {
+		namespace = operatorConfig.GetOperatorNamespace()
+	}
+	return namespace, operatorConfig.WebhookService
+}
+
+// GetShutdownGracePeriod returns how long the reconciles and the NSX writes in flight are given to complete once NSX
+// Operator is asked to stop.
+func (coeConfig *CoeConfig) GetShutdownGracePeriod() time.Duration {
+	if coeConfig.ShutdownGracePeriod > 0 {
+		return time.Duration(coeConfig.ShutdownGracePeriod) * time.Second
+	}
+	return defaultShutdownGracePeriod
+}
+
+// GetLabelTagOverflowPolicy returns the policy applied to the labels beyond the NSX tag limit.
+func (coeConfig *CoeConfig) GetLabelTagOverflowPolicy() string {
+	if coeConfig.LabelTagOverflowPolicy == "" {
+		return LabelTagOverflowPolicyDrop
+	}
+	return coeConfig.LabelTagOverflowPolicy
+}
+
+// GetSubnetExhaustionPolicy returns the policy applied when all the Subnets of a SubnetSet run out of IPs.
+func (coeConfig *CoeConfig) GetSubnetExhaustionPolicy() string {
+	if coeConfig.SubnetExhaustionPolicy == "" {
+		return SubnetExhaustionPolicyFail
+	}
+	return coeConfig.SubnetExhaustionPolicy
+}
+
+// GetSubnetSetScaleThreshold returns the utilization percentage of the Subnets of a SubnetSet at which one more Subnet is created.
+func (coeConfig *CoeConfig) GetSubnetSetScaleThreshold() int {
+	if coeConfig.SubnetSetScaleThreshold == 0 {
+		return DefaultSubnetSetScaleThreshold
+	}
+	return coeConfig.SubnetSetScaleThreshold
+}
+
+// GetMACPoolRanges returns the MAC ranges the MACs of the SubnetPorts are allocated from.
+func (coeConfig *CoeConfig) GetMACPoolRanges() []MACRange {
+	var ranges []MACRange
+	for _, r := range coeConfig.MACPoolRanges {
+		// the ranges are validated when the config is loaded
+		if macRange, err := ParseMACRange(r); err == nil {
+			ranges = append(ranges, macRange)
+		}
+	}
+	return ranges
+}
+
+// ParseMACRange parses a MAC range in the form <start MAC>-<end MAC>.
+func ParseMACRange(r string) (MACRange, error) {
+	bounds := strings.Split(r, "-")
+	if len(bounds) != 2 {
+		return MACRange{}, fmt.Errorf("invalid MAC range %q", r)
+	}
+	start, err := ParseMAC(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return MACRange{}, fmt.Errorf("invalid MAC range %q: %v", r, err)
+	}
+	end, err := ParseMAC(strings.TrimSpace(bounds[1]))
+	if err != nil {
+		return MACRange{}, fmt.Errorf("invalid MAC range %q: %v", r, err)
+	}
+	if start > end {
+		return MACRange{}, fmt.Errorf("invalid MAC range %q: start is greater than end", r)
+	}
+	return MACRange{Start: start, End: end}, nil
+}
+
+// ParseMAC parses an EUI-48 MAC as an integer.
+func ParseMAC(mac string) (uint64, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return 0, err
+	}
+	if len(hw) != 6 {
+		return 0, fmt.Errorf("%s is not an EUI-48 MAC", mac)
+	}
+	var value uint64
+	for _, b := range hw {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
+
+// FormatMAC formats the integer as an EUI-48 MAC.
+func FormatMAC(value uint64) string {
+	hw := make(net.HardwareAddr, 6)
+	for i := 5; i >= 0; i-- {
+		hw[i] = byte(value)
+		value >>= 8
+	}
+	return hw.String()
+}
+
+// GetNsxOrg returns the NSX Org of the NSX Project, it is the default Org if not configured.
+// UsesClientCert returns whether NSX Operator authenticates with the certificate of a Principal Identity.
+func (nsxConfig *NsxConfig) UsesClientCert() bool {
+	return nsxConfig.NsxApiCertFile != "" || nsxConfig.NsxApiCertSecret != ""
+}
+
+func (nsxConfig *NsxConfig) GetNsxOrg() string {
+	if nsxConfig == nil || len(nsxConfig.NsxOrg) == 0 {
+		return DefaultNsxOrg
+	}
+	return nsxConfig.NsxOrg
+}
+
+// GetEnforcementPoint returns the enforcement point used for the Namespaces which don't select one.
+func (nsxConfig *NsxConfig) GetEnforcementPoint() string {
+	if nsxConfig == nil || len(nsxConfig.EnforcementPoint) == 0 {
+		return DefaultEnforcementPoint
+	}
+	return nsxConfig.EnforcementPoint
+}
+
+// EnforcementPointAllowed tells if the Namespaces may select the enforcement point, only the default one is allowed if
+// EnforcementPoints is empty.
+func (nsxConfig *NsxConfig) EnforcementPointAllowed(enforcementPoint string) bool {
+	if len(nsxConfig.EnforcementPoints) == 0 {
+		return enforcementPoint == nsxConfig.GetEnforcementPoint()
+	}
+	for _, ep := range nsxConfig.EnforcementPoints {
+		if ep == enforcementPoint {
+			return true
+		}
+	}
+	return false
+}
+
+// InProject tells if the resources are created in an NSX Project rather than under /infra.
+func (nsxConfig *NsxConfig) InProject() bool {
+	return nsxConfig != nil && len(nsxConfig.NsxProject) > 0
+}
+
+// GetExternalIPBlockUsageThreshold returns the utilization percentage of an external IP block above which a warning is
+// raised, the default if it is not set. A threshold set to 0 raises the warning for all the IP blocks.
+func (coeConfig *CoeConfig) GetExternalIPBlockUsageThreshold() int {
+	if coeConfig.ExternalIPBlockUsageThreshold == nil {
+		return DefaultExternalIPBlockUsageThreshold
+	}
+	return *coeConfig.ExternalIPBlockUsageThreshold
+}
+
+func (profile *VPCConnectivityProfile) validate() error {
+	if len(profile.Name) == 0 {
+		err := errors.New("invalid VPC connectivity profile without name")
+		log.Error(err, "validate VPCConnectivityProfile failed")
+		return err
+	}
+	if profile.EnableSNAT && !profile.ExternalConnectivity {
+		err := fmt.Errorf("enable_snat requires external_connectivity in VPC connectivity profile %s", profile.Name)
+		log.Error(err, "validate VPCConnectivityProfile failed")
+		return err
+	}
+	switch profile.DefaultRuleAction {
+	case "", "ALLOW", "DROP", "REJECT":
+	default:
+		err := fmt.Errorf("invalid default_rule_action %s in VPC connectivity profile %s", profile.DefaultRuleAction, profile.Name)
+		log.Error(err, "validate VPCConnectivityProfile failed")
+		return err
+	}
 	return nil
 }
+
+func (manager *NSXManagerConfig) validate() error {
+	if len(manager.Name) == 0 {
+		err := errors.New("invalid NSX manager without name")
+		log.Error(err, "validate NSXManagerConfig failed")
+		return err
+	}
+	if len(manager.NamespaceSelector) == 0 {
+		err := fmt.Errorf("namespace_selector is required in NSX manager %s", manager.Name)
+		log.Error(err, "validate NSXManagerConfig failed")
+		return err
+	}
+	if _, err := labels.Parse(manager.NamespaceSelector); err != nil {
+		err = fmt.Errorf("invalid namespace_selector in NSX manager %s: %w", manager.Name, err)
+		log.Error(err, "validate NSXManagerConfig failed")
+		return err
+	}
+	return manager.NsxConfig.validate()
+}
+
+// ForNSXManager returns the config of the NSX manager of the name, it shares the options of NSX Operator with the
+// config of the default NSX manager and has the NSX options of its section.
+func (operatorConfig *NSXOperatorConfig) ForNSXManager(name string) *NSXOperatorConfig {
+	manager, ok := operatorConfig.NSXManagers[name]
+	if !ok {
+		return operatorConfig
+	}
+	managerConfig := *operatorConfig
+	managerConfig.NsxConfig = manager.NsxConfig
+	return &managerConfig
+}
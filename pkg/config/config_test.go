@@ -6,9 +6,14 @@ package config
 import (
 	"errors"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth/csp"
 )
 
 func TestConfig_VCConfig(t *testing.T) {
@@ -41,7 +46,256 @@ func TestConfig_CoeConfig(t *testing.T) {
 	coeConfig.Cluster = "10.0.0.1"
 	err = coeConfig.validate()
 	assert.Equal(t, err, nil)
+	assert.Equal(t, 64, coeConfig.DefaultSubnetSize())
+	assert.Equal(t, SubnetExhaustionPolicyFail, coeConfig.GetSubnetExhaustionPolicy())
+
+	coeConfig.DefaultSubnetPrefixLength = 24
+	coeConfig.SubnetExhaustionPolicy = SubnetExhaustionPolicyAutoCreate
+	err = coeConfig.validate()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, 256, coeConfig.DefaultSubnetSize())
+	assert.Equal(t, SubnetExhaustionPolicyAutoCreate, coeConfig.GetSubnetExhaustionPolicy())
+
+	coeConfig.DefaultSubnetPrefixLength = 30
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.DefaultSubnetPrefixLength = 24
+	coeConfig.SubnetExhaustionPolicy = "retry"
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.SubnetExhaustionPolicy = ""
+
+	assert.Equal(t, DefaultSubnetSetScaleThreshold, coeConfig.GetSubnetSetScaleThreshold())
+	coeConfig.SubnetSetScaleThreshold = 60
+	assert.Nil(t, coeConfig.validate())
+	assert.Equal(t, 60, coeConfig.GetSubnetSetScaleThreshold())
+	coeConfig.SubnetSetScaleThreshold = -1
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.SubnetSetScaleThreshold = 0
+
+	assert.Equal(t, DefaultExternalIPBlockUsageThreshold, coeConfig.GetExternalIPBlockUsageThreshold())
+	threshold := 90
+	coeConfig.ExternalIPBlockUsageThreshold = &threshold
+	assert.Nil(t, coeConfig.validate())
+	assert.Equal(t, 90, coeConfig.GetExternalIPBlockUsageThreshold())
+	threshold = 0
+	assert.Nil(t, coeConfig.validate())
+	assert.Equal(t, 0, coeConfig.GetExternalIPBlockUsageThreshold())
+	threshold = 101
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.ExternalIPBlockUsageThreshold = nil
+
+	coeConfig.MACPoolRanges = []string{"02:50:56:00:00:00-02:50:56:00:00:ff", "02:50:56:00:01:00 - 02:50:56:00:01:0f"}
+	assert.Nil(t, coeConfig.validate())
+	assert.Equal(t, []MACRange{{Start: 0x025056000000, End: 0x0250560000ff}, {Start: 0x025056000100, End: 0x02505600010f}}, coeConfig.GetMACPoolRanges())
+	for _, r := range []string{"02:50:56:00:00:00", "02:50:56:00:00:ff-02:50:56:00:00:00", "02:50:56:00:00:00-02:50:56", "00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01-00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:02"} {
+		coeConfig.MACPoolRanges = []string{r}
+		assert.NotNil(t, coeConfig.validate(), r)
+	}
+	coeConfig.MACPoolRanges = nil
+
+	assert.Equal(t, LabelTagOverflowPolicyDrop, coeConfig.GetLabelTagOverflowPolicy())
+	coeConfig.LabelTagOverflowPolicy = LabelTagOverflowPolicyMerge
+	assert.Nil(t, coeConfig.validate())
+	assert.Equal(t, LabelTagOverflowPolicyMerge, coeConfig.GetLabelTagOverflowPolicy())
+	coeConfig.LabelTagOverflowPolicy = "truncate"
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.LabelTagOverflowPolicy = ""
+
+	assert.Equal(t, IPAMDriftModeReport, coeConfig.GetIPAMDriftMode())
+	coeConfig.IPAMDriftMode = IPAMDriftModeRepair
+	assert.Nil(t, coeConfig.validate())
+	assert.Equal(t, IPAMDriftModeRepair, coeConfig.GetIPAMDriftMode())
+	coeConfig.IPAMDriftMode = "fix"
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.IPAMDriftMode = ""
+
+	coeConfig.LBVIPPools = []string{"/infra/ip-pools/vip", "/orgs/default/projects/p1/infra/ip-pools/vip"}
+	assert.Nil(t, coeConfig.validate())
+	coeConfig.LBVIPPools = []string{"/infra/ip-blocks/vip"}
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.LBVIPPools = nil
+
+	coeConfig.TransportCIDRs = []string{"192.168.0.0/16"}
+	coeConfig.ExternalCIDRs = []string{"100.64.0.0/10", "fd00::/64"}
+	assert.Nil(t, coeConfig.validate())
+	coeConfig.ExternalCIDRs = []string{"100.64.0.0"}
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.ExternalCIDRs = nil
+
+	coeConfig.GCInterval = -1
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.GCInterval = 0
+
+	coeConfig.GCMaxDeletions = -1
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.GCMaxDeletions = 0
+	coeConfig.GCMaxDeletionPercent = 101
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.GCMaxDeletionPercent = 50
+	assert.Nil(t, coeConfig.validate())
+	coeConfig.GCMaxDeletionPercent = 0
+
+	coeConfig.StoreResyncInterval = -1
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.StoreResyncInterval = 0
+
+	coeConfig.CRResyncPeriod = -1
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.CRResyncPeriod = 0
+
+	coeConfig.StuckReconcileTimeout = -1
+	assert.NotNil(t, coeConfig.validate())
+	coeConfig.StuckReconcileTimeout = 0
+
+	coeConfig.FeatureGates = []string{"VPC=false", "SECURITY_POLICY=true"}
+	assert.Nil(t, coeConfig.validate())
+	gates, err := coeConfig.GetFeatureGates()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{"VPC": false, "SECURITY_POLICY": true}, gates)
+	for _, invalid := range []string{"VPC", "VPC=off", "=true"} {
+		coeConfig.FeatureGates = []string{invalid}
+		assert.NotNil(t, coeConfig.validate(), invalid)
+	}
+}
+
+func TestCoeConfig_WorkqueueRateLimits(t *testing.T) {
+	coeConfig := &CoeConfig{Cluster: "k8scl-one"}
+	assert.Nil(t, coeConfig.validate())
+	baseDelay, maxDelay, qps, burst := coeConfig.GetWorkqueueRateLimits()
+	assert.Equal(t, 5*time.Millisecond, baseDelay)
+	assert.Equal(t, 1000*time.Second, maxDelay)
+	assert.Equal(t, 10.0, qps)
+	assert.Equal(t, 100, burst)
+
+	coeConfig.WorkqueueBaseDelay = 2000
+	coeConfig.WorkqueueMaxDelay = 300
+	coeConfig.WorkqueueQPS = 0.5
+	coeConfig.WorkqueueBurst = 5
+	assert.Nil(t, coeConfig.validate())
+	baseDelay, maxDelay, qps, burst = coeConfig.GetWorkqueueRateLimits()
+	assert.Equal(t, 2*time.Second, baseDelay)
+	assert.Equal(t, 300*time.Second, maxDelay)
+	assert.Equal(t, 0.5, qps)
+	assert.Equal(t, 5, burst)
+
+	coeConfig.WorkqueueMaxDelay = 1
+	assert.Equal(t, errors.New("invalid field "+"WorkqueueBaseDelay"), coeConfig.validate())
+	coeConfig.WorkqueueMaxDelay = 0
+	coeConfig.WorkqueueQPS = -1
+	assert.Equal(t, errors.New("invalid field "+"Workqueue"), coeConfig.validate())
+}
+
+func TestCoeConfig_ShutdownGracePeriod(t *testing.T) {
+	coeConfig := &CoeConfig{Cluster: "k8scl-one"}
+	assert.Nil(t, coeConfig.validate())
+	assert.Equal(t, 30*time.Second, coeConfig.GetShutdownGracePeriod())
+	coeConfig.ShutdownGracePeriod = 90
+	assert.Nil(t, coeConfig.validate())
+	assert.Equal(t, 90*time.Second, coeConfig.GetShutdownGracePeriod())
+	coeConfig.ShutdownGracePeriod = -1
+	assert.Equal(t, errors.New("invalid field "+"ShutdownGracePeriod"), coeConfig.validate())
+}
+
+func TestCoeConfig_StartupTimeout(t *testing.T) {
+	coeConfig := &CoeConfig{Cluster: "k8scl-one", StartupTimeout: 600}
+	assert.Nil(t, coeConfig.validate())
+	coeConfig.StartupTimeout = -1
+	assert.Equal(t, errors.New("invalid field "+"StartupTimeout"), coeConfig.validate())
+}
+
+func TestCoeConfig_StorePrefetchWorkers(t *testing.T) {
+	coeConfig := &CoeConfig{Cluster: "k8scl-one", StorePrefetchWorkers: 4}
+	assert.Nil(t, coeConfig.validate())
+	coeConfig.StorePrefetchWorkers = -1
+	assert.Equal(t, errors.New("invalid field "+"StorePrefetchWorkers"), coeConfig.validate())
+}
+
+func TestCoeConfig_WebhookCert(t *testing.T) {
+	coeConfig := &CoeConfig{Cluster: "k8scl-one"}
+	assert.Equal(t, 365*24*time.Hour, coeConfig.GetWebhookCertValidity())
+	coeConfig.WebhookCertSecret = "vmware-system-nsx/nsx-operator-webhook-cert"
+	assert.Equal(t, errors.New("WebhookService must be set with WebhookCertSecret"), coeConfig.validate())
+	coeConfig.WebhookService = "nsx-operator-webhook"
+	coeConfig.WebhookCertValidity = 90
+	assert.Nil(t, coeConfig.validate())
+	namespace, name := coeConfig.GetWebhookCertSecret()
+	assert.Equal(t, []string{"vmware-system-nsx", "nsx-operator-webhook-cert"}, []string{namespace, name})
+	assert.Equal(t, 90*24*time.Hour, coeConfig.GetWebhookCertValidity())
+	coeConfig.WebhookCertSecret = "nsx-operator-webhook-cert"
+	assert.Equal(t, errors.New("invalid field "+"WebhookCertSecret"), coeConfig.validate())
+}
+
+func TestCoeConfig_WebhookFailurePolicy(t *testing.T) {
+	cf := &NSXOperatorConfig{CoeConfig: &CoeConfig{Cluster: "k8scl-one"}, K8sConfig: &K8sConfig{}}
+	cf.WebhookExcludedNamespaces = []string{"kube-system"}
+	assert.Equal(t, errors.New("WebhookService must be set with WebhookFailurePolicy or WebhookExcludedNamespaces"), cf.CoeConfig.validate())
+	cf.WebhookService = "nsx-operator-webhook"
+	cf.WebhookFailurePolicy = "Fail"
+	assert.Nil(t, cf.CoeConfig.validate())
+	namespace, name := cf.GetWebhookService()
+	assert.Equal(t, []string{defaultOperatorNamespace, "nsx-operator-webhook"}, []string{namespace, name})
+	cf.WebhookCertSecret = "nsx-system/nsx-operator-webhook-cert"
+	namespace, _ = cf.GetWebhookService()
+	assert.Equal(t, "nsx-system", namespace)
+	cf.WebhookFailurePolicy = "ignore"
+	assert.Equal(t, errors.New("invalid field "+"WebhookFailurePolicy"), cf.CoeConfig.validate())
+}
+
+func TestConfig_K8sConfig(t *testing.T) {
+	k8sConfig := &K8sConfig{}
+	assert.Nil(t, k8sConfig.validate())
+	assert.Equal(t, defaultOperatorNamespace, k8sConfig.GetLeaderElectionNamespace())
+	assert.Equal(t, "nsx-operator", k8sConfig.GetLeaderElectionID())
+	leaseDuration, renewDeadline, retryPeriod := k8sConfig.GetLeaderElectionDurations()
+	assert.Equal(t, []time.Duration{15 * time.Second, 10 * time.Second, 2 * time.Second}, []time.Duration{leaseDuration, renewDeadline, retryPeriod})
+
+	k8sConfig.OperatorNamespace = "nsx-system"
+	k8sConfig.LeaderElectionID = "nsx-operator-cluster1"
+	assert.Equal(t, "nsx-system", k8sConfig.GetLeaderElectionNamespace())
+	assert.Equal(t, "nsx-operator-cluster1", k8sConfig.GetLeaderElectionID())
+	k8sConfig.LeaderElectionNamespace = "kube-system"
+	assert.Equal(t, "kube-system", k8sConfig.GetLeaderElectionNamespace())
+
+	k8sConfig.LeaderElectionLeaseDuration = 30
+	k8sConfig.LeaderElectionRenewDeadline = 20
+	k8sConfig.LeaderElectionRetryPeriod = 5
+	assert.Nil(t, k8sConfig.validate())
+	leaseDuration, _, _ = k8sConfig.GetLeaderElectionDurations()
+	assert.Equal(t, 30*time.Second, leaseDuration)
+
+	expect := errors.New("invalid field " + "LeaderElection")
+	k8sConfig.LeaderElectionRetryPeriod = -1
+	assert.Equal(t, expect, k8sConfig.validate())
+	k8sConfig.LeaderElectionRetryPeriod = 20
+	assert.Equal(t, expect, k8sConfig.validate())
+	k8sConfig.LeaderElectionRetryPeriod = 5
+	k8sConfig.LeaderElectionRenewDeadline = 30
+	assert.Equal(t, expect, k8sConfig.validate())
+	// the defaults of the durations unset must be consistent with the ones set
+	k8sConfig.LeaderElectionLeaseDuration = 0
+	k8sConfig.LeaderElectionRenewDeadline = 0
+	k8sConfig.LeaderElectionRetryPeriod = 10
+	assert.Equal(t, expect, k8sConfig.validate())
+
+	k8sConfig = &K8sConfig{EnableSharding: true}
+	assert.Nil(t, k8sConfig.validate())
+	k8sConfig.EnableLeaderElection = true
+	assert.Equal(t, errors.New("invalid field "+"EnableSharding"), k8sConfig.validate())
+	k8sConfig.EnableLeaderElection = false
+	k8sConfig.EnableStandby = true
+	assert.Equal(t, errors.New("invalid field "+"EnableSharding"), k8sConfig.validate())
+
+	k8sConfig = &K8sConfig{EnableStandby: true}
+	assert.Nil(t, k8sConfig.validate())
+	assert.Equal(t, "nsx-operator-heartbeat", k8sConfig.GetStandbyHeartbeatGroup())
+	k8sConfig.StandbyHeartbeatGroup = "heartbeat-site1"
+	assert.Equal(t, "heartbeat-site1", k8sConfig.GetStandbyHeartbeatGroup())
+}
 
+func TestFormatMAC(t *testing.T) {
+	mac, err := ParseMAC("02:50:56:AB:cd:0F")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0x025056abcd0f), mac)
+	assert.Equal(t, "02:50:56:ab:cd:0f", FormatMAC(mac))
 }
 
 func TestConfig_NsxConfig(t *testing.T) {
@@ -62,6 +316,83 @@ func TestConfig_NsxConfig(t *testing.T) {
 	expect = errors.New("thumbprint count not match manager count")
 	err = nsxConfig.validate()
 	assert.Equal(t, err, expect)
+	nsxConfig.Thumbprint = nil
+
+	assert.False(t, nsxConfig.InProject())
+	assert.Equal(t, DefaultNsxOrg, nsxConfig.GetNsxOrg())
+	nsxConfig.NsxOrg = "org1"
+	assert.Equal(t, errors.New("invalid field "+"NsxProject"), nsxConfig.validate())
+	nsxConfig.NsxProject = "tenant1"
+	assert.Nil(t, nsxConfig.validate())
+	assert.True(t, nsxConfig.InProject())
+	assert.Equal(t, "org1", nsxConfig.GetNsxOrg())
+
+	nsxConfig.APIRateMode = "fast"
+	assert.Equal(t, errors.New("invalid field "+"APIRateMode"), nsxConfig.validate())
+	nsxConfig.APIRateMode = APIRateModeFixRate
+	nsxConfig.APIMPWriteRateLimit = -1
+	assert.Equal(t, errors.New("invalid API rate limit"), nsxConfig.validate())
+	nsxConfig.APIMPWriteRateLimit = 10
+	assert.Nil(t, nsxConfig.validate())
+
+	nsxConfig.TCPKeepAlive = -1
+	assert.Equal(t, errors.New("invalid HTTP connection setting"), nsxConfig.validate())
+	nsxConfig.TCPKeepAlive = 30
+	assert.Nil(t, nsxConfig.validate())
+	nsxConfig.RealizationTimeout = -1
+	assert.Equal(t, errors.New("invalid timeout"), nsxConfig.validate())
+	nsxConfig.RealizationTimeout = 10
+	assert.Nil(t, nsxConfig.validate())
+
+	nsxConfig.ProxyURL = "ftp://proxy:21"
+	assert.Equal(t, errors.New("invalid field "+"ProxyURL"), nsxConfig.validate())
+	nsxConfig.ProxyURL = "proxy:3128"
+	assert.Equal(t, errors.New("invalid field "+"ProxyURL"), nsxConfig.validate())
+	nsxConfig.ProxyURL = "socks5://proxy:1080"
+	assert.Nil(t, nsxConfig.validate())
+	nsxConfig.ProxyURL = "http://proxy:3128"
+	nsxConfig.ProxyAuthSecret = "proxy-auth"
+	assert.Equal(t, errors.New("invalid field "+"ProxyAuthSecret"), nsxConfig.validate())
+	nsxConfig.ProxyAuthSecret = "vmware-system-nsx/proxy-auth"
+	assert.Nil(t, nsxConfig.validate())
+	nsxConfig.ProxyURL, nsxConfig.ProxyAuthSecret = "", ""
+
+	assert.False(t, nsxConfig.UsesClientCert())
+	nsxConfig.NsxApiCertFile = "/etc/nsx-ujo/tls.crt"
+	assert.Equal(t, errors.New("invalid field "+"NsxApiPrivateKeyFile"), nsxConfig.validate())
+	nsxConfig.NsxApiPrivateKeyFile = "/etc/nsx-ujo/tls.key"
+	assert.Nil(t, nsxConfig.validate())
+	assert.True(t, nsxConfig.UsesClientCert())
+	nsxConfig.NsxApiCertSecret = "vmware-system-nsx/nsx-operator-cert"
+	assert.Equal(t, errors.New("only one of NsxApiCertFile and NsxApiCertSecret can be set"), nsxConfig.validate())
+	nsxConfig.NsxApiCertFile, nsxConfig.NsxApiPrivateKeyFile = "", ""
+	assert.Nil(t, nsxConfig.validate())
+	assert.True(t, nsxConfig.UsesClientCert())
+	nsxConfig.NsxApiCertSecret = "nsx-operator-cert"
+	assert.Equal(t, errors.New("invalid field "+"NsxApiCertSecret"), nsxConfig.validate())
+	nsxConfig.NsxApiCertSecret = ""
+
+	nsxConfig.NsxApiCredentialSecret = "vmware-system-nsx/nsx-credentials"
+	assert.Nil(t, nsxConfig.validate())
+	nsxConfig.NsxApiPassword = "admin"
+	assert.Equal(t, errors.New("only one of NsxApiUser/NsxApiPassword and NsxApiCredentialSecret can be set"), nsxConfig.validate())
+	nsxConfig.NsxApiPassword = ""
+	nsxConfig.NsxApiCredentialSecret = "nsx-credentials"
+	assert.Equal(t, errors.New("invalid field "+"NsxApiCredentialSecret"), nsxConfig.validate())
+	nsxConfig.NsxApiCredentialSecret = ""
+
+	nsxConfig.EnforcementPoints = []string{"default", "site-b"}
+	assert.Nil(t, nsxConfig.validate())
+	nsxConfig.EnforcementPoint = "vmc-enforcementpoint"
+	assert.Equal(t, errors.New("invalid field "+"EnforcementPoint"), nsxConfig.validate())
+	nsxConfig.EnforcementPoint, nsxConfig.EnforcementPoints = "", nil
+
+	nsxConfig.VaultAddr = "https://vault:8200"
+	assert.Equal(t, errors.New("invalid field "+"VaultRole/VaultSecretPath"), nsxConfig.validate())
+	nsxConfig.VaultRole, nsxConfig.VaultSecretPath = "nsx-operator", "secret/data/nsx"
+	assert.Nil(t, nsxConfig.validate())
+	nsxConfig.NsxApiUser = "admin"
+	assert.Equal(t, errors.New("only one of NsxApiUser/NsxApiPassword, NsxApiCredentialSecret and VaultAddr can be set"), nsxConfig.validate())
 }
 
 func TestConfig_NewNSXOperatorConfigFromFile(t *testing.T) {
@@ -75,6 +406,84 @@ func TestConfig_NewNSXOperatorConfigFromFile(t *testing.T) {
 	assert.Equal(t, err, nil)
 }
 
+func TestConfig_VPCConnectivityProfiles(t *testing.T) {
+	content := `[coe]
+cluster = k8scl-one
+
+[nsx_v3]
+nsx_api_managers = 127.0.0.1
+
+[vpc_connectivity_profile:dmz]
+external_connectivity = true
+
+[vpc_connectivity_profile:restricted]
+default_rule_action = DROP
+`
+	configFilePath = filepath.Join(t.TempDir(), "nsxop.ini")
+	assert.Nil(t, os.WriteFile(configFilePath, []byte(content), 0o600))
+	defer func() { configFilePath = "" }()
+
+	cf, err := NewNSXOperatorConfigFromFile()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*VPCConnectivityProfile{
+		"dmz":        {Name: "dmz", ExternalConnectivity: true},
+		"restricted": {Name: "restricted", DefaultRuleAction: "DROP"},
+	}, cf.VPCConnectivityProfiles)
+
+	// SNAT requires external connectivity
+	assert.Nil(t, os.WriteFile(configFilePath, []byte(content+"enable_snat = true\n"), 0o600))
+	_, err = NewNSXOperatorConfigFromFile()
+	assert.NotNil(t, err)
+
+	profile := &VPCConnectivityProfile{Name: "internal", DefaultRuleAction: "DENY"}
+	assert.NotNil(t, profile.validate())
+}
+
+func TestConfig_NSXManagers(t *testing.T) {
+	content := `[coe]
+cluster = k8scl-one
+
+[nsx_v3]
+nsx_api_managers = 127.0.0.1
+nsx_api_user = admin
+thumbprint = aa:bb
+tier1_gateway = t1
+
+[nsx_manager:site-b]
+namespace_selector = site=b
+nsx_api_managers = 127.0.0.2,127.0.0.3
+tier1_gateway = t1-b
+`
+	configFilePath = filepath.Join(t.TempDir(), "nsxop.ini")
+	assert.Nil(t, os.WriteFile(configFilePath, []byte(content), 0o600))
+	defer func() { configFilePath = "" }()
+
+	cf, err := NewNSXOperatorConfigFromFile()
+	assert.Nil(t, err)
+	assert.Len(t, cf.NSXManagers, 1)
+	manager := cf.NSXManagers["site-b"]
+	assert.Equal(t, "site=b", manager.NamespaceSelector)
+	assert.Equal(t, []string{"127.0.0.2", "127.0.0.3"}, manager.NsxConfig.NsxApiManagers)
+	assert.Nil(t, manager.NsxConfig.Thumbprint)
+	assert.Equal(t, "admin", manager.NsxConfig.NsxApiUser)
+	assert.Equal(t, "t1-b", manager.NsxConfig.Tier1Gateway)
+
+	managerConfig := cf.ForNSXManager("site-b")
+	assert.Equal(t, "t1-b", managerConfig.Tier1Gateway)
+	assert.Equal(t, "k8scl-one", managerConfig.Cluster)
+	assert.Equal(t, "t1", cf.Tier1Gateway)
+	assert.Equal(t, cf, cf.ForNSXManager("site-c"))
+
+	// the NSX managers are required
+	assert.Nil(t, os.WriteFile(configFilePath, []byte(content+"[nsx_manager:site-c]\nnamespace_selector = site=c\n"), 0o600))
+	_, err = NewNSXOperatorConfigFromFile()
+	assert.NotNil(t, err)
+
+	assert.Nil(t, os.WriteFile(configFilePath, []byte(content+"[nsx_manager:site-c]\nnsx_api_managers = 127.0.0.4\nnamespace_selector = site in (c\n"), 0o600))
+	_, err = NewNSXOperatorConfigFromFile()
+	assert.NotNil(t, err)
+}
+
 func TestConfig_GetTokenProvider(t *testing.T) {
 	vcConfig := &VCConfig{}
 	vcConfig.VCEndPoint = "127.0.0.1"
@@ -84,3 +493,61 @@ func TestConfig_GetTokenProvider(t *testing.T) {
 	tokenProvider := nsxConfig.GetTokenProvider()
 	assert.NotNil(t, tokenProvider)
 }
+
+func TestConfig_GetTokenProviderCSP(t *testing.T) {
+	tokenProvider = nil
+	defer func() { tokenProvider = nil }()
+	nsxConfig := &NSXOperatorConfig{VCConfig: &VCConfig{}, NsxConfig: &NsxConfig{CSPRefreshTokenFile: "/etc/nsx-ujo/csp/refresh_token"}}
+	_, ok := nsxConfig.GetTokenProvider().(*csp.CSPTokenProvider)
+	assert.True(t, ok)
+}
+
+func TestNsxConfig_EnforcementPoints(t *testing.T) {
+	nsxConfig := &NsxConfig{}
+	assert.Equal(t, DefaultEnforcementPoint, nsxConfig.GetEnforcementPoint())
+	assert.True(t, nsxConfig.EnforcementPointAllowed("default"))
+	assert.False(t, nsxConfig.EnforcementPointAllowed("site-b"))
+
+	nsxConfig.EnforcementPoint = "site-a"
+	assert.Equal(t, "site-a", nsxConfig.GetEnforcementPoint())
+	assert.False(t, nsxConfig.EnforcementPointAllowed("default"))
+
+	nsxConfig.EnforcementPoints = []string{"site-a", "site-b"}
+	assert.True(t, nsxConfig.EnforcementPointAllowed("site-b"))
+	assert.False(t, nsxConfig.EnforcementPointAllowed("site-c"))
+}
+
+func TestDefaultConfig_GetModuleLogLevels(t *testing.T) {
+	defaultConfig := &DefaultConfig{ModuleLogLevels: []string{"nsx.wire=2", "nsx-operator=0"}}
+	levels, err := defaultConfig.GetModuleLogLevels()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"nsx.wire": 2, "nsx-operator": 0}, levels)
+	for _, invalid := range []string{"nsx", "nsx=-1", "=1", "nsx=debug"} {
+		defaultConfig.ModuleLogLevels = []string{invalid}
+		_, err = defaultConfig.GetModuleLogLevels()
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestDefaultConfig_validateTracing(t *testing.T) {
+	defaultConfig := &DefaultConfig{TracingSampleRatio: DefaultTracingSampleRatio}
+	assert.Nil(t, defaultConfig.validate())
+	defaultConfig.TracingEndpoint = "http://otel-collector:4318"
+	assert.Nil(t, defaultConfig.validate())
+	for _, invalid := range []string{"otel-collector:4318", "grpc://otel-collector:4317", "http://"} {
+		defaultConfig.TracingEndpoint = invalid
+		assert.NotNil(t, defaultConfig.validate(), invalid)
+	}
+	defaultConfig.TracingEndpoint = ""
+	defaultConfig.TracingSampleRatio = 1.5
+	assert.NotNil(t, defaultConfig.validate())
+}
+
+func TestDefaultConfig_validateLogDedupInterval(t *testing.T) {
+	defaultConfig := &DefaultConfig{TracingSampleRatio: DefaultTracingSampleRatio, LogDedupInterval: DefaultLogDedupInterval}
+	assert.Nil(t, defaultConfig.validate())
+	defaultConfig.LogDedupInterval = 0
+	assert.Nil(t, defaultConfig.validate())
+	defaultConfig.LogDedupInterval = -1
+	assert.NotNil(t, defaultConfig.validate())
+}
@@ -0,0 +1,154 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package config
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// The controllers which can be disabled by disabled_controllers or --disable-controllers.
+const (
+	ControllerSecurityPolicy      = "securitypolicy"
+	ControllerNSXServiceAccount   = "nsxserviceaccount"
+	ControllerVPC                 = "vpc"
+	ControllerSubnet              = "subnet"
+	ControllerSubnetSet           = "subnetset"
+	ControllerSubnetPort          = "subnetport"
+	ControllerIPPool              = "ippool"
+	ControllerIPAddressAllocation = "ipaddressallocation"
+	ControllerNetworkInfo         = "networkinfo"
+	ControllerStaticRoute         = "staticroute"
+	ControllerRouteAdvertisement  = "routeadvertisement"
+	ControllerPrefixList          = "prefixlist"
+	ControllerRouteMap            = "routemap"
+	ControllerLBVIP               = "lbvip"
+)
+
+// Controllers are the controllers which can be disabled.
+var Controllers = []string{
+	ControllerSecurityPolicy, ControllerNSXServiceAccount, ControllerVPC, ControllerSubnet, ControllerSubnetSet,
+	ControllerSubnetPort, ControllerIPPool, ControllerIPAddressAllocation, ControllerNetworkInfo, ControllerStaticRoute,
+	ControllerRouteAdvertisement, ControllerPrefixList, ControllerRouteMap, ControllerLBVIP,
+}
+
+// controllerDependencies are the controllers whose services the controllers use, they can't be disabled unless the
+// controllers using them are disabled too.
+var controllerDependencies = map[string][]string{
+	ControllerSubnet:              {ControllerVPC},
+	ControllerSubnetSet:           {ControllerSubnet},
+	ControllerSubnetPort:          {ControllerSubnet},
+	ControllerIPPool:              {ControllerVPC},
+	ControllerIPAddressAllocation: {ControllerIPPool},
+	ControllerNetworkInfo:         {ControllerVPC},
+	ControllerRouteMap:            {ControllerPrefixList},
+}
+
+// ControllerEnabled returns whether the controller is not disabled. The controllers are started only if the options
+// they require are set too, e.g. enable_vpc_network for the vpc controller.
+func (coeConfig *CoeConfig) ControllerEnabled(controller string) bool {
+	for _, disabled := range coeConfig.DisabledControllers {
+		if disabled == controller {
+			return false
+		}
+	}
+	return true
+}
+
+// knownController returns whether name is the name of a controller.
+func knownController(name string) bool {
+	for _, controller := range Controllers {
+		if controller == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (coeConfig *CoeConfig) validateGCDryRunResources() error {
+	for _, resource := range coeConfig.GCDryRunResources {
+		if !knownController(resource) {
+			return fmt.Errorf("invalid field GCDryRunResources, unknown resource type %s", resource)
+		}
+	}
+	return nil
+}
+
+func (coeConfig *CoeConfig) validateDisabledControllers() error {
+	for _, disabled := range coeConfig.DisabledControllers {
+		if !knownController(disabled) {
+			return fmt.Errorf("invalid field DisabledControllers, unknown controller %s", disabled)
+		}
+	}
+	dependencies := controllerDependencies
+	if coeConfig.EnableVPCNetwork {
+		// the StaticRoutes of the VPC scope are programmed on the VPCs of their Namespaces
+		dependencies = map[string][]string{ControllerStaticRoute: {ControllerVPC}}
+		for controller, dependency := range controllerDependencies {
+			dependencies[controller] = dependency
+		}
+	}
+	for _, controller := range Controllers {
+		if !coeConfig.ControllerEnabled(controller) {
+			continue
+		}
+		for _, dependency := range dependencies[controller] {
+			if !coeConfig.ControllerEnabled(dependency) {
+				return fmt.Errorf("invalid field DisabledControllers, %s requires %s", controller, dependency)
+			}
+		}
+	}
+	return nil
+}
+
+// defaultMaxConcurrentReconciles returns the number of the workers of the controller if max_concurrent_reconciles
+// doesn't set it, the reconciles of the controllers are throttled by the NSX API rate limits differently.
+func defaultMaxConcurrentReconciles(controller string) int {
+	switch controller {
+	case ControllerSecurityPolicy:
+		// the reconciles of the SecurityPolicies mostly wait for the realization of their rules, the Policy writes
+		// are limited to 50 per second
+		return 2 * runtime.NumCPU()
+	case ControllerNSXServiceAccount:
+		// the NSXServiceAccounts create the Principal Identities with the MP API, its writes are limited to 20 per
+		// second
+		return 2
+	case ControllerPrefixList, ControllerRouteMap, ControllerRouteAdvertisement:
+		// they all update the same Tier-0 gateway
+		return 1
+	}
+	return runtime.NumCPU()
+}
+
+// getMaxConcurrentReconciles returns the numbers of the workers of the controllers set by max_concurrent_reconciles.
+func (coeConfig *CoeConfig) getMaxConcurrentReconciles() (map[string]int, error) {
+	workers := make(map[string]int, len(coeConfig.MaxConcurrentReconciles))
+	for _, value := range coeConfig.MaxConcurrentReconciles {
+		parts := strings.Split(value, "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid field MaxConcurrentReconciles, %s is not in the form of controller=workers", value)
+		}
+		controller := strings.TrimSpace(parts[0])
+		if !knownController(controller) {
+			return nil, fmt.Errorf("invalid field MaxConcurrentReconciles, unknown controller %s", controller)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid field MaxConcurrentReconciles, %s is not in the form of controller=workers", value)
+		}
+		workers[controller] = n
+	}
+	return workers, nil
+}
+
+// GetMaxConcurrentReconciles returns the number of the workers reconciling the CRs of the controller.
+func (coeConfig *CoeConfig) GetMaxConcurrentReconciles(controller string) int {
+	workers, _ := coeConfig.getMaxConcurrentReconciles()
+	if n, ok := workers[controller]; ok {
+		return n
+	}
+	return defaultMaxConcurrentReconciles(controller)
+}
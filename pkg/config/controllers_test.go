@@ -0,0 +1,99 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoeConfig_DisabledControllers(t *testing.T) {
+	coeConfig := &CoeConfig{Cluster: "k8scl-one"}
+	for _, controller := range Controllers {
+		assert.True(t, coeConfig.ControllerEnabled(controller), controller)
+	}
+
+	coeConfig.DisabledControllers = []string{ControllerStaticRoute, ControllerNSXServiceAccount}
+	assert.Nil(t, coeConfig.validate())
+	assert.False(t, coeConfig.ControllerEnabled(ControllerStaticRoute))
+	assert.True(t, coeConfig.ControllerEnabled(ControllerSecurityPolicy))
+
+	coeConfig.DisabledControllers = []string{"pause"}
+	assert.EqualError(t, coeConfig.validate(), "invalid field DisabledControllers, unknown controller pause")
+
+	// the controllers can't be disabled unless the controllers using their services are disabled too
+	coeConfig.DisabledControllers = []string{ControllerSubnet}
+	assert.EqualError(t, coeConfig.validate(), "invalid field DisabledControllers, subnetset requires subnet")
+	coeConfig.DisabledControllers = []string{ControllerSubnet, ControllerSubnetSet, ControllerSubnetPort}
+	assert.Nil(t, coeConfig.validate())
+
+	// the StaticRoutes depend on the VPCs only with VPC networking
+	coeConfig.DisabledControllers = []string{ControllerVPC, ControllerSubnet, ControllerSubnetSet, ControllerSubnetPort,
+		ControllerIPPool, ControllerIPAddressAllocation, ControllerNetworkInfo}
+	assert.Nil(t, coeConfig.validate())
+	coeConfig.EnableVPCNetwork = true
+	assert.EqualError(t, coeConfig.validate(), "invalid field DisabledControllers, staticroute requires vpc")
+}
+
+func TestCoeConfig_GCDryRunResources(t *testing.T) {
+	coeConfig := &CoeConfig{Cluster: "k8scl-one", GCDryRunResources: []string{ControllerVPC, ControllerSubnet}}
+	assert.Nil(t, coeConfig.validate())
+	coeConfig.GCDryRunResources = []string{"vpcs"}
+	assert.EqualError(t, coeConfig.validate(), "invalid field GCDryRunResources, unknown resource type vpcs")
+}
+
+func TestCoeConfig_MaxConcurrentReconciles(t *testing.T) {
+	coeConfig := &CoeConfig{Cluster: "k8scl-one"}
+	assert.Equal(t, 2*runtime.NumCPU(), coeConfig.GetMaxConcurrentReconciles(ControllerSecurityPolicy))
+	assert.Equal(t, 2, coeConfig.GetMaxConcurrentReconciles(ControllerNSXServiceAccount))
+	assert.Equal(t, 1, coeConfig.GetMaxConcurrentReconciles(ControllerRouteMap))
+	assert.Equal(t, runtime.NumCPU(), coeConfig.GetMaxConcurrentReconciles(ControllerSubnet))
+
+	coeConfig.MaxConcurrentReconciles = []string{"securitypolicy=16", " nsxserviceaccount = 1"}
+	assert.Nil(t, coeConfig.validate())
+	assert.Equal(t, 16, coeConfig.GetMaxConcurrentReconciles(ControllerSecurityPolicy))
+	assert.Equal(t, 1, coeConfig.GetMaxConcurrentReconciles(ControllerNSXServiceAccount))
+	assert.Equal(t, runtime.NumCPU(), coeConfig.GetMaxConcurrentReconciles(ControllerSubnet))
+
+	for _, invalid := range []string{"securitypolicy", "securitypolicy=0", "securitypolicy=many", "pause=2"} {
+		coeConfig.MaxConcurrentReconciles = []string{invalid}
+		assert.NotNil(t, coeConfig.validate(), invalid)
+	}
+	coeConfig.MaxConcurrentReconciles = []string{"pause=2"}
+	assert.EqualError(t, coeConfig.validate(), "invalid field MaxConcurrentReconciles, unknown controller pause")
+}
+
+func TestConfig_DisableControllersFlag(t *testing.T) {
+	content := `[coe]
+cluster = k8scl-one
+disabled_controllers = lbvip
+
+[nsx_v3]
+nsx_api_managers = 127.0.0.1
+insecure = true
+`
+	configFilePath = filepath.Join(t.TempDir(), "nsxop.ini")
+	assert.Nil(t, os.WriteFile(configFilePath, []byte(content), 0o600))
+	defer func() { configFilePath = "" }()
+
+	cf, err := NewNSXOperatorConfigFromFile()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ControllerLBVIP}, cf.DisabledControllers)
+
+	// --disable-controllers overrides the config file
+	disabledControllers = "staticroute,nsxserviceaccount"
+	defer func() { disabledControllers = "" }()
+	cf, err = NewNSXOperatorConfigFromFile()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ControllerStaticRoute, ControllerNSXServiceAccount}, cf.DisabledControllers)
+	assert.True(t, cf.ControllerEnabled(ControllerLBVIP))
+
+	disabledControllers = "subnet"
+	_, err = NewNSXOperatorConfigFromFile()
+	assert.NotNil(t, err)
+}
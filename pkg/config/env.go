@@ -0,0 +1,64 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// EnvPrefix is the prefix of the environment variables overriding the config options.
+const EnvPrefix = "NSX_OPERATOR_"
+
+// EnvName returns the environment variable overriding the option of the section, e.g. NSX_OPERATOR_NSX_V3_NSX_API_MANAGERS
+// for nsx_api_managers of the nsx_v3 section.
+func EnvName(section, option string) string {
+	return EnvPrefix + strings.ToUpper(section) + "_" + strings.ToUpper(option)
+}
+
+// applyEnvOverrides sets the options of the DEFAULT, coe, nsx_v3, k8s and vc sections to the environment variables
+// overriding them, so that the environment variables take precedence over the config file, which takes precedence over
+// the defaults. The options are parsed like the ones of the config file, e.g. the lists are separated by commas. The
+// additional NSX managers inherit the nsx_v3 options overridden unless their sections set them.
+func applyEnvOverrides(cfg *ini.File, operatorConfig *NSXOperatorConfig) {
+	for section, options := range sectionOptions(operatorConfig) {
+		for _, option := range options {
+			value, ok := os.LookupEnv(EnvName(section, option))
+			if !ok {
+				continue
+			}
+			log.Info("config option is overridden by the environment variable", "section", section, "option", option, "env", EnvName(section, option))
+			cfg.Section(section).Key(option).SetValue(value)
+		}
+	}
+}
+
+// sectionOptions returns the options of the DEFAULT, coe, nsx_v3, k8s and vc sections, keyed by section.
+func sectionOptions(operatorConfig *NSXOperatorConfig) map[string][]string {
+	sections := []struct {
+		name   string
+		config interface{}
+	}{
+		{"DEFAULT", operatorConfig.DefaultConfig},
+		{"coe", operatorConfig.CoeConfig},
+		{"nsx_v3", operatorConfig.NsxConfig},
+		{"k8s", operatorConfig.K8sConfig},
+		{"vc", operatorConfig.VCConfig},
+	}
+	options := make(map[string][]string, len(sections))
+	for _, section := range sections {
+		t := reflect.TypeOf(section.config).Elem()
+		for i := 0; i < t.NumField(); i++ {
+			option := t.Field(i).Tag.Get("ini")
+			if option == "" || option == "-" {
+				continue
+			}
+			options[section.name] = append(options[section.name], option)
+		}
+	}
+	return options
+}
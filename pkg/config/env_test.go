@@ -0,0 +1,65 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "NSX_OPERATOR_NSX_V3_NSX_API_MANAGERS", EnvName("nsx_v3", "nsx_api_managers"))
+	assert.Equal(t, "NSX_OPERATOR_DEFAULT_LOG_LEVEL", EnvName("DEFAULT", "log_level"))
+}
+
+func TestConfig_EnvOverrides(t *testing.T) {
+	content := `[DEFAULT]
+log_level = 1
+
+[coe]
+cluster = k8scl-one
+enable_vpc_network = false
+
+[nsx_v3]
+nsx_api_managers = 127.0.0.1
+nsx_api_user = admin
+insecure = true
+
+[nsx_manager:site-b]
+nsx_api_managers = 10.0.0.1
+namespace_selector = site=b
+nsx_api_user = site-b-admin
+`
+	configFilePath = filepath.Join(t.TempDir(), "nsxop.ini")
+	assert.Nil(t, os.WriteFile(configFilePath, []byte(content), 0o600))
+	defer func() { configFilePath = "" }()
+
+	t.Setenv("NSX_OPERATOR_DEFAULT_LOG_LEVEL", "2")
+	t.Setenv("NSX_OPERATOR_COE_ENABLE_VPC_NETWORK", "true")
+	t.Setenv("NSX_OPERATOR_NSX_V3_NSX_API_MANAGERS", "10.0.0.2,10.0.0.3")
+	t.Setenv("NSX_OPERATOR_NSX_V3_NSX_API_USER", "operator")
+	t.Setenv("NSX_OPERATOR_NSX_V3_NSX_API_PASSWORD", "passw0rd")
+	t.Setenv("NSX_OPERATOR_K8S_ENABLE_PROMETHEUS_METRICS", "true")
+	cf, err := NewNSXOperatorConfigFromFile()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, cf.LogLevel)
+	assert.True(t, cf.EnableVPCNetwork)
+	assert.Equal(t, "k8scl-one", cf.Cluster)
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3"}, cf.NsxApiManagers)
+	assert.Equal(t, "operator", cf.NsxApiUser)
+	assert.Equal(t, "passw0rd", cf.NsxApiPassword)
+	assert.True(t, cf.Insecure)
+	assert.True(t, cf.EnablePromMetrics)
+	// the additional NSX managers inherit the overridden options their sections don't set
+	assert.Equal(t, "site-b-admin", cf.NSXManagers["site-b"].NsxConfig.NsxApiUser)
+	assert.Equal(t, "passw0rd", cf.NSXManagers["site-b"].NsxConfig.NsxApiPassword)
+
+	// the overridden options are validated
+	t.Setenv("NSX_OPERATOR_COE_SUBNETSET_SCALE_THRESHOLD", "101")
+	_, err = NewNSXOperatorConfigFromFile()
+	assert.NotNil(t, err)
+}
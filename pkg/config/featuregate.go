@@ -0,0 +1,153 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The subsystems of NSX Operator which can be turned on or off by --feature-gates, e.g.
+// --feature-gates=VPCNetworking=true,IPAMDriftAudit=false.
+const (
+	// FeatureVPCNetworking starts the VPC, Subnet, SubnetSet, SubnetPort, IPPool, IPAddressAllocation and NetworkInfo
+	// controllers if enable_vpc_network is set too.
+	FeatureVPCNetworking = "VPCNetworking"
+	// FeatureStaticRoutes starts the StaticRoute controller and webhook.
+	FeatureStaticRoutes = "StaticRoutes"
+	// FeatureRoutingPolicies starts the RouteAdvertisement, PrefixList and RouteMap controllers.
+	FeatureRoutingPolicies = "RoutingPolicies"
+	// FeatureLBVIPAllocation starts the LBVIP controller if lb_vip_pools is set too.
+	FeatureLBVIPAllocation = "LBVIPAllocation"
+	// FeatureIPAMDriftAudit starts the IPAM drift auditor if ipam_drift_mode is not disabled.
+	FeatureIPAMDriftAudit = "IPAMDriftAudit"
+)
+
+// The maturity of the features, the alpha features are off by default so that they ship dark.
+const (
+	Alpha = "alpha"
+	Beta  = "beta"
+	GA    = "ga"
+)
+
+// FeatureSpec is the default state and maturity of a feature.
+type FeatureSpec struct {
+	Default    bool
+	PreRelease string
+}
+
+// defaultFeatures is the registry of the features which can be gated.
+var defaultFeatures = map[string]FeatureSpec{
+	FeatureVPCNetworking:   {Default: true, PreRelease: Beta},
+	FeatureStaticRoutes:    {Default: true, PreRelease: Beta},
+	FeatureRoutingPolicies: {Default: true, PreRelease: Beta},
+	FeatureLBVIPAllocation: {Default: true, PreRelease: Beta},
+	FeatureIPAMDriftAudit:  {Default: true, PreRelease: Beta},
+}
+
+// FeatureGates is the state of the features set by --feature-gates, it implements flag.Value.
+type FeatureGates struct {
+	features map[string]FeatureSpec
+	enabled  map[string]bool
+	sync.RWMutex
+}
+
+// DefaultFeatureGates are the feature gates of --feature-gates shared by all the controllers.
+var DefaultFeatureGates = NewFeatureGates()
+
+// NewFeatureGates returns the feature gates of the registered features in their default states.
+func NewFeatureGates() *FeatureGates {
+	return &FeatureGates{features: defaultFeatures, enabled: map[string]bool{}}
+}
+
+// Set turns on or off the features of the comma separated list of feature=true|false.
+func (f *FeatureGates) Set(value string) error {
+	enabled := map[string]bool{}
+	for _, gate := range strings.Split(value, ",") {
+		gate = strings.TrimSpace(gate)
+		if gate == "" {
+			continue
+		}
+		feature, on, err := parseFeatureGate(gate)
+		if err != nil {
+			return err
+		}
+		if _, ok := f.features[feature]; !ok {
+			return fmt.Errorf("unknown feature gate %s, the features are %s", feature, strings.Join(f.KnownFeatures(), ", "))
+		}
+		enabled[feature] = on
+	}
+	f.Lock()
+	defer f.Unlock()
+	for feature, on := range enabled {
+		f.enabled[feature] = on
+	}
+	return nil
+}
+
+// String returns the features set by --feature-gates in the form of --feature-gates.
+func (f *FeatureGates) String() string {
+	if f == nil {
+		return ""
+	}
+	f.RLock()
+	defer f.RUnlock()
+	gates := make([]string, 0, len(f.enabled))
+	for feature, on := range f.enabled {
+		gates = append(gates, feature+"="+strconv.FormatBool(on))
+	}
+	sort.Strings(gates)
+	return strings.Join(gates, ",")
+}
+
+// Enabled returns whether the feature is turned on, the unknown features are off.
+func (f *FeatureGates) Enabled(feature string) bool {
+	f.RLock()
+	defer f.RUnlock()
+	if on, ok := f.enabled[feature]; ok {
+		return on
+	}
+	return f.features[feature].Default
+}
+
+// KnownFeatures returns the sorted names of the features which can be gated.
+func (f *FeatureGates) KnownFeatures() []string {
+	features := make([]string, 0, len(f.features))
+	for feature := range f.features {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// Spec returns the default state and maturity of the feature.
+func (f *FeatureGates) Spec(feature string) FeatureSpec {
+	return f.features[feature]
+}
+
+// usage is the help of --feature-gates listing the features with their maturity and default state.
+func (f *FeatureGates) usage() string {
+	var features []string
+	for _, feature := range f.KnownFeatures() {
+		spec := f.features[feature]
+		features = append(features, fmt.Sprintf("%s=true|false (%s - default=%t)", feature, spec.PreRelease, spec.Default))
+	}
+	return "A set of key=value pairs that describe feature gates for alpha/experimental features. Options are:\n" + strings.Join(features, "\n")
+}
+
+// parseFeatureGate parses a feature gate in the form of feature=true|false.
+func parseFeatureGate(gate string) (string, bool, error) {
+	parts := strings.Split(gate, "=")
+	if len(parts) != 2 || parts[0] == "" {
+		return "", false, fmt.Errorf("%s is not in the form of feature=true|false", gate)
+	}
+	enabled, err := strconv.ParseBool(parts[1])
+	if err != nil {
+		return "", false, fmt.Errorf("%s is not in the form of feature=true|false", gate)
+	}
+	return parts[0], enabled, nil
+}
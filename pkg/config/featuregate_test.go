@@ -0,0 +1,48 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package config
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureGates(t *testing.T) {
+	gates := NewFeatureGates()
+	gates.features = map[string]FeatureSpec{
+		"Dark":   {Default: false, PreRelease: Alpha},
+		"Stable": {Default: true, PreRelease: GA},
+	}
+	assert.False(t, gates.Enabled("Dark"))
+	assert.True(t, gates.Enabled("Stable"))
+	assert.False(t, gates.Enabled("Unknown"))
+	assert.Equal(t, []string{"Dark", "Stable"}, gates.KnownFeatures())
+	assert.Equal(t, "", gates.String())
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(gates, "feature-gates", gates.usage())
+	assert.Nil(t, fs.Parse([]string{"--feature-gates=Dark=true, Stable=false"}))
+	assert.True(t, gates.Enabled("Dark"))
+	assert.False(t, gates.Enabled("Stable"))
+	assert.Equal(t, "Dark=true,Stable=false", gates.String())
+
+	// the gates are not changed by an invalid value
+	for _, invalid := range []string{"Unknown=true", "Dark=true,Stable", "Dark=yes"} {
+		assert.NotNil(t, gates.Set(invalid), invalid)
+	}
+	assert.True(t, gates.Enabled("Dark"))
+	assert.False(t, gates.Enabled("Stable"))
+}
+
+func TestDefaultFeatureGates(t *testing.T) {
+	gates := NewFeatureGates()
+	for _, feature := range gates.KnownFeatures() {
+		spec := gates.Spec(feature)
+		assert.Contains(t, []string{Alpha, Beta, GA}, spec.PreRelease, feature)
+		assert.Equal(t, spec.Default, gates.Enabled(feature), feature)
+	}
+	assert.Contains(t, gates.usage(), "VPCNetworking=true|false (beta - default=true)")
+}
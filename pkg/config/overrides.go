@@ -0,0 +1,82 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// Overrides are the options overriding the config file, keyed by section and option, e.g. the options of the
+// NSXOperatorConfiguration CR.
+type Overrides map[string]map[string]string
+
+var (
+	overrides     Overrides
+	overridesLock sync.Mutex
+)
+
+// Validate returns an error if a section or an option can't be overridden. The values are validated when the config is
+// loaded with them.
+func (o Overrides) Validate() error {
+	known := sectionOptions(NewNSXOpertorConfig())
+	sections := make([]string, 0, len(o))
+	for section := range o {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		options, ok := known[section]
+		if !ok {
+			return fmt.Errorf("section %s can't be overridden", section)
+		}
+		for option := range o[section] {
+			if !containsString(options, option) {
+				return fmt.Errorf("unknown option %s of section %s", option, section)
+			}
+		}
+	}
+	return nil
+}
+
+// SetOverrides sets the options overriding the config file and the environment variables, they are applied when the
+// config is loaded.
+func SetOverrides(o Overrides) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	overridesLock.Lock()
+	defer overridesLock.Unlock()
+	overrides = o
+	return nil
+}
+
+// GetOverrides returns the options overriding the config file.
+func GetOverrides() Overrides {
+	overridesLock.Lock()
+	defer overridesLock.Unlock()
+	return overrides
+}
+
+// applyOverrides sets the options of the config file to the overrides.
+func applyOverrides(cfg *ini.File) {
+	for section, options := range GetOverrides() {
+		for option, value := range options {
+			log.V(1).Info("config option is overridden", "section", section, "option", option)
+			cfg.Section(section).Key(option).SetValue(value)
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
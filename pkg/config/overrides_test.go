@@ -0,0 +1,51 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverrides_Validate(t *testing.T) {
+	assert.Nil(t, Overrides{}.Validate())
+	assert.Nil(t, Overrides{"coe": {"gc_interval": "120"}, "nsx_v3": {"nsx_api_managers": "10.0.0.1"}}.Validate())
+	assert.EqualError(t, Overrides{"nsx_manager:site-b": {"nsx_api_managers": "10.0.0.1"}}.Validate(), "section nsx_manager:site-b can't be overridden")
+	assert.EqualError(t, Overrides{"coe": {"gc_intervals": "120"}}.Validate(), "unknown option gc_intervals of section coe")
+}
+
+func TestConfig_Overrides(t *testing.T) {
+	content := `[coe]
+cluster = k8scl-one
+gc_interval = 60
+
+[nsx_v3]
+nsx_api_managers = 127.0.0.1
+insecure = true
+`
+	configFilePath = filepath.Join(t.TempDir(), "nsxop.ini")
+	assert.Nil(t, os.WriteFile(configFilePath, []byte(content), 0o600))
+	defer func() { configFilePath = "" }()
+	defer SetOverrides(nil)
+
+	// the overrides take precedence over the environment variables
+	t.Setenv("NSX_OPERATOR_COE_GC_INTERVAL", "90")
+	assert.Nil(t, SetOverrides(Overrides{"coe": {"gc_interval": "120"}, "DEFAULT": {"log_level": "2"}}))
+	cf, err := NewNSXOperatorConfigFromFile()
+	assert.Nil(t, err)
+	assert.Equal(t, 120, cf.GCInterval)
+	assert.Equal(t, 2, cf.LogLevel)
+	assert.Equal(t, "k8scl-one", cf.Cluster)
+
+	// the overridden options are validated
+	assert.Nil(t, SetOverrides(Overrides{"coe": {"gc_interval": "-1"}}))
+	_, err = NewNSXOperatorConfigFromFile()
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, SetOverrides(Overrides{"coe": {"unknown": "1"}}))
+	assert.Equal(t, Overrides{"coe": {"gc_interval": "-1"}}, GetOverrides())
+}
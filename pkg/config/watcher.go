@@ -0,0 +1,113 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"time"
+)
+
+// WatchInterval is the interval to check the configuration file for changes.
+const WatchInterval = 30 * time.Second
+
+// reloadableOptions are the options applied without restarting NSX Operator.
+var reloadableOptions = map[string]bool{
+	"DEFAULT.log_level":                  true,
+	"DEFAULT.module_log_levels":          true,
+	"DEFAULT.log_dedup_interval":         true,
+	"coe.gc_interval":                    true,
+	"coe.gc_dry_run":                     true,
+	"coe.gc_dry_run_resources":           true,
+	"coe.gc_max_deletions":               true,
+	"coe.gc_max_deletion_percent":        true,
+	"coe.feature_gates":                  true,
+	"nsx_v3.nsx_api_managers":            true,
+	"nsx_v3.thumbprint":                  true,
+	"nsx_v3.api_policy_read_rate_limit":  true,
+	"nsx_v3.api_policy_write_rate_limit": true,
+	"nsx_v3.api_mp_read_rate_limit":      true,
+	"nsx_v3.api_mp_write_rate_limit":     true,
+}
+
+// ConfigChangeHandler is invoked with the newly loaded configuration when the configuration file changes.
+type ConfigChangeHandler func(newConfig *NSXOperatorConfig)
+
+// WatchConfigFile polls the configuration file every interval and invokes handler once the file content
+// changes and the new configuration passes validation. An invalid configuration is logged and ignored, the
+// running configuration is kept until a valid one is written. It returns when stop is closed.
+func WatchConfigFile(stop <-chan struct{}, interval time.Duration, handler ConfigChangeHandler) {
+	lastSum, _ := configFileChecksum()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			log.Info("config file watcher stopped")
+			return
+		case <-ticker.C:
+			sum, err := configFileChecksum()
+			if err != nil {
+				log.Error(err, "failed to read config file", "path", configFilePath)
+				continue
+			}
+			if bytes.Equal(sum, lastSum) {
+				continue
+			}
+			lastSum = sum
+			newConfig, err := NewNSXOperatorConfigFromFile()
+			if err != nil {
+				log.Error(err, "ignore invalid config file change", "path", configFilePath)
+				continue
+			}
+			log.Info("config file changed", "path", configFilePath)
+			handler(newConfig)
+		}
+	}
+}
+
+func configFileChecksum() ([]byte, error) {
+	content, err := ioutil.ReadFile(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	return sum[:], nil
+}
+
+// RestartRequiredChanges returns the options, in the form of section.option, which differ between the running and
+// the reloaded configuration but only take effect after NSX Operator is restarted.
+func RestartRequiredChanges(running, reloaded *NSXOperatorConfig) []string {
+	sections := []struct {
+		name              string
+		running, reloaded interface{}
+	}{
+		{"DEFAULT", running.DefaultConfig, reloaded.DefaultConfig},
+		{"coe", running.CoeConfig, reloaded.CoeConfig},
+		{"nsx_v3", running.NsxConfig, reloaded.NsxConfig},
+		{"k8s", running.K8sConfig, reloaded.K8sConfig},
+		{"vc", running.VCConfig, reloaded.VCConfig},
+	}
+	var changes []string
+	for _, section := range sections {
+		rv, nv := reflect.ValueOf(section.running).Elem(), reflect.ValueOf(section.reloaded).Elem()
+		for i := 0; i < rv.NumField(); i++ {
+			option := fmt.Sprintf("%s.%s", section.name, rv.Type().Field(i).Tag.Get("ini"))
+			if reloadableOptions[option] || reflect.DeepEqual(rv.Field(i).Interface(), nv.Field(i).Interface()) {
+				continue
+			}
+			changes = append(changes, option)
+		}
+	}
+	if !reflect.DeepEqual(running.VPCConnectivityProfiles, reloaded.VPCConnectivityProfiles) {
+		changes = append(changes, "vpc_connectivity_profile")
+	}
+	if !reflect.DeepEqual(running.NSXManagers, reloaded.NSXManagers) {
+		changes = append(changes, "nsx_manager")
+	}
+	return changes
+}
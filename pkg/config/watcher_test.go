@@ -0,0 +1,79 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "nsxop.ini")
+	content := "[coe]\ncluster = k8scl-one\n[nsx_v3]\nnsx_api_managers = 10.0.0.1\n"
+	assert.Nil(t, ioutil.WriteFile(file, []byte(content), 0644))
+	oldPath := configFilePath
+	UpdateConfigFilePath(file)
+	defer UpdateConfigFilePath(oldPath)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	changed := make(chan *NSXOperatorConfig, 1)
+	go WatchConfigFile(stop, 10*time.Millisecond, func(newConfig *NSXOperatorConfig) {
+		changed <- newConfig
+	})
+	time.Sleep(50 * time.Millisecond)
+
+	// invalid config is ignored
+	assert.Nil(t, ioutil.WriteFile(file, []byte("[coe]\ncluster = \n"), 0644))
+	select {
+	case <-changed:
+		t.Fatal("handler should not be invoked for invalid config")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	content = "[coe]\ncluster = k8scl-one\n[nsx_v3]\nnsx_api_managers = 10.0.0.1,10.0.0.2\n"
+	assert.Nil(t, ioutil.WriteFile(file, []byte(content), 0644))
+	select {
+	case newConfig := <-changed:
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, newConfig.NsxApiManagers)
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler is not invoked after config file changed")
+	}
+}
+
+func TestRestartRequiredChanges(t *testing.T) {
+	running := NewNSXOpertorConfig()
+	running.Cluster = "k8scl-one"
+	running.NsxApiManagers = []string{"10.0.0.1"}
+	running.NsxApiPassword = "old"
+	reloaded := NewNSXOpertorConfig()
+	reloaded.Cluster = "k8scl-one"
+	reloaded.NsxApiManagers = []string{"10.0.0.1", "10.0.0.2"}
+	reloaded.Thumbprint = []string{"0a:fc"}
+	reloaded.NsxApiPassword = "new"
+	reloaded.CaFile = []string{"/etc/ca.pem"}
+	// the options applied without restarting NSX Operator are not reported
+	reloaded.LogLevel = 2
+	reloaded.APIPolicyWriteRateLimit = 10
+	reloaded.FeatureGates = []string{"VPC=false"}
+
+	assert.Equal(t, []string{"nsx_v3.nsx_api_password", "nsx_v3.ca_file"}, RestartRequiredChanges(running, reloaded))
+	assert.Empty(t, RestartRequiredChanges(running, running))
+
+	reloaded = NewNSXOpertorConfig()
+	reloaded.Cluster = "k8scl-one"
+	reloaded.NsxApiManagers = []string{"10.0.0.1"}
+	reloaded.NsxApiPassword = "old"
+	reloaded.VPCConnectivityProfiles["dmz"] = &VPCConnectivityProfile{Name: "dmz", ExternalConnectivity: true}
+	assert.Equal(t, []string{"vpc_connectivity_profile"}, RestartRequiredChanges(running, reloaded))
+
+	reloaded.VPCConnectivityProfiles = running.VPCConnectivityProfiles
+	reloaded.NSXManagers["site-b"] = &NSXManagerConfig{Name: "site-b", NamespaceSelector: "site=b", NsxConfig: &NsxConfig{NsxApiManagers: []string{"10.0.1.1"}}}
+	assert.Equal(t, []string{"nsx_manager"}, RestartRequiredChanges(running, reloaded))
+}
@@ -0,0 +1,178 @@
+package common
+
+import (
+	"context"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+)
+
+// deleting returns whether the CR is being deleted, i.e. it waits for the finalizers of NSX Operator.
+func deleting(obj client.Object) bool {
+	return obj != nil && !obj.GetDeletionTimestamp().IsZero()
+}
+
+// terminating returns whether the CR is being deleted or its namespace is, the CRs of a terminating namespace are
+// deleted next. The namespace is read from c, the CR is not terminating if it can't be read.
+func terminating(c client.Reader, obj client.Object) bool {
+	if deleting(obj) {
+		return true
+	}
+	if obj == nil || c == nil || obj.GetNamespace() == "" {
+		return false
+	}
+	ns := &v1.Namespace{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: obj.GetNamespace()}, ns); err != nil {
+		return false
+	}
+	return deleting(ns)
+}
+
+// NotDeletingPredicate filters out the CRs being deleted and the CRs of the terminating namespaces read from c from
+// the work queue of a controller, they are reconciled by its deletion controller instead. The Delete events are kept.
+func NotDeletingPredicate(c client.Reader) predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return !terminating(c, e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return !terminating(c, e.ObjectNew) },
+		GenericFunc: func(e event.GenericEvent) bool { return !terminating(c, e.Object) },
+	}
+}
+
+// DeletingPredicate only lets the CRs being deleted and the CRs of the terminating namespaces read from c into the
+// work queue of a deletion controller.
+func DeletingPredicate(c client.Reader) predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return terminating(c, e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return terminating(c, e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return terminating(c, e.Object) },
+	}
+}
+
+// terminatingNamespacePredicate only lets the namespaces which start terminating through, and the ones already
+// terminating once the controller starts.
+var terminatingNamespacePredicate = predicate.Funcs{
+	CreateFunc:  func(e event.CreateEvent) bool { return deleting(e.Object) },
+	UpdateFunc:  func(e event.UpdateEvent) bool { return !deleting(e.ObjectOld) && deleting(e.ObjectNew) },
+	DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+	GenericFunc: func(e event.GenericEvent) bool { return deleting(e.Object) },
+}
+
+// requestsForNamespace returns the func mapping a terminating namespace to the CRs of list's type in it read from c
+// which pass predicates, so that they are moved to the deletion controller at once.
+func requestsForNamespace(c client.Reader, list client.ObjectList, predicates []predicate.Predicate) handler.MapFunc {
+	return func(ns client.Object) []reconcile.Request {
+		objs := list.DeepCopyObject().(client.ObjectList)
+		if err := c.List(context.TODO(), objs, client.InNamespace(ns.GetName())); err != nil {
+			logger.Log.Error(err, "failed to list the CRs of the terminating namespace", "namespace", ns.GetName())
+			return nil
+		}
+		items, err := meta.ExtractList(objs)
+		if err != nil {
+			logger.Log.Error(err, "failed to list the CRs of the terminating namespace", "namespace", ns.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, item := range items {
+			if obj, ok := item.(client.Object); ok && passes(predicates, obj) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+			}
+		}
+		return requests
+	}
+}
+
+// passes returns whether obj passes all the predicates.
+func passes(predicates []predicate.Predicate, obj client.Object) bool {
+	for _, p := range predicates {
+		if !p.Generic(event.GenericEvent{Object: obj}) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetupDeletionController sets up the deletion controller of the controller of name, it reconciles the CRs of obj's
+// type being deleted and the ones of the terminating namespaces with r in its own work queue, so the deletions, e.g.
+// of all the CRs of a terminating namespace, are not queued behind the creations and updates of a large rollout and
+// don't block the finalizers. The CRs of a namespace are moved to it once the namespace starts terminating. The
+// controller must filter them out with NotDeletingPredicate. predicates are the other predicates of the CRs of the
+// controller. The CRD of obj is registered to be waited for before the manager starts, see RequireCRDs.
+func SetupDeletionController(mgr ctrl.Manager, cf *config.NSXOperatorConfig, name string, obj client.Object, r reconcile.Reconciler,
+	predicates ...predicate.Predicate) error {
+	RequireCRDs(mgr.GetScheme(), obj)
+	gvk, err := apiutil.GVKForObject(obj, mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	list, err := mgr.GetScheme().New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name+"-deletion").
+		For(obj, builder.WithPredicates(append([]predicate.Predicate{DeletingPredicate(mgr.GetClient())}, predicates...)...)).
+		Watches(
+			&source.Kind{Type: &v1.Namespace{}},
+			handler.EnqueueRequestsFromMapFunc(requestsForNamespace(mgr.GetClient(), list.(client.ObjectList), predicates)),
+			builder.WithPredicates(terminatingNamespacePredicate),
+		).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: cf.GetMaxConcurrentReconciles(name),
+				RateLimiter:             RateLimiter(cf),
+			}).
+		Complete(Instrument(cf, name, r))
+}
+
+// reconcileLocks serialize the reconciles of a CR by a controller and its deletion controller, the work queue of each
+// only serializes its own.
+var reconcileLocks = struct {
+	locks map[string]*reconcileLock
+	sync.Mutex
+}{locks: map[string]*reconcileLock{}}
+
+type reconcileLock struct {
+	sync.Mutex
+	// holders are the reconciles holding or waiting for the lock
+	holders int
+}
+
+// lockReconcile locks the reconciles of the CR of key by the controllers of name, the returned func unlocks them.
+func lockReconcile(name string, key string) func() {
+	key = name + "/" + key
+	reconcileLocks.Lock()
+	lock, ok := reconcileLocks.locks[key]
+	if !ok {
+		lock = &reconcileLock{}
+		reconcileLocks.locks[key] = lock
+	}
+	lock.holders++
+	reconcileLocks.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		reconcileLocks.Lock()
+		defer reconcileLocks.Unlock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(reconcileLocks.locks, key)
+		}
+	}
+}
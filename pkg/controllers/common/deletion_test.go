@@ -0,0 +1,115 @@
+package common
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+)
+
+func TestDeletingPredicates(t *testing.T) {
+	now := metav1.Now()
+	active := &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "subnet1"}}
+	deleted := &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "subnet1", DeletionTimestamp: &now,
+		Finalizers: []string{"test"}}}
+	notDeleting, deletingPredicate := NotDeletingPredicate(nil), DeletingPredicate(nil)
+
+	assert.True(t, notDeleting.Create(event.CreateEvent{Object: active}))
+	assert.False(t, deletingPredicate.Create(event.CreateEvent{Object: active}))
+	assert.False(t, notDeleting.Create(event.CreateEvent{Object: deleted}))
+	assert.True(t, deletingPredicate.Create(event.CreateEvent{Object: deleted}))
+
+	assert.True(t, notDeleting.Update(event.UpdateEvent{ObjectOld: active, ObjectNew: active}))
+	assert.False(t, deletingPredicate.Update(event.UpdateEvent{ObjectOld: active, ObjectNew: active}))
+	assert.False(t, notDeleting.Update(event.UpdateEvent{ObjectOld: active, ObjectNew: deleted}))
+	assert.True(t, deletingPredicate.Update(event.UpdateEvent{ObjectOld: active, ObjectNew: deleted}))
+
+	assert.False(t, notDeleting.Generic(event.GenericEvent{Object: deleted}))
+	assert.True(t, deletingPredicate.Generic(event.GenericEvent{Object: deleted}))
+
+	// the Delete events are left to the controllers
+	assert.True(t, notDeleting.Delete(event.DeleteEvent{Object: deleted}))
+	assert.False(t, deletingPredicate.Delete(event.DeleteEvent{Object: deleted}))
+}
+
+func TestTerminatingNamespace(t *testing.T) {
+	now := metav1.Now()
+	scheme := runtime.NewScheme()
+	require.Nil(t, v1alpha1.AddToScheme(scheme))
+	require.Nil(t, v1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2", DeletionTimestamp: &now, Finalizers: []string{"test"}}},
+		&v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "subnet1"}},
+		&v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "subnet1"}},
+		&v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "subnet2"}},
+		&v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "skipped"}},
+	).Build()
+	notDeleting, deletingPredicate := NotDeletingPredicate(c), DeletingPredicate(c)
+
+	// the CRs of a terminating namespace are reconciled by the deletion controller
+	active := &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "subnet1"}}
+	terminated := &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "subnet1"}}
+	unknown := &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns3", Name: "subnet1"}}
+	assert.True(t, notDeleting.Update(event.UpdateEvent{ObjectOld: active, ObjectNew: active}))
+	assert.False(t, deletingPredicate.Update(event.UpdateEvent{ObjectOld: active, ObjectNew: active}))
+	assert.False(t, notDeleting.Update(event.UpdateEvent{ObjectOld: terminated, ObjectNew: terminated}))
+	assert.True(t, deletingPredicate.Update(event.UpdateEvent{ObjectOld: terminated, ObjectNew: terminated}))
+	assert.True(t, notDeleting.Create(event.CreateEvent{Object: unknown}))
+	assert.False(t, deletingPredicate.Create(event.CreateEvent{Object: unknown}))
+
+	// the CRs are moved to the deletion controller once their namespace starts terminating
+	ns1 := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}}
+	ns2 := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2", DeletionTimestamp: &now}}
+	assert.False(t, terminatingNamespacePredicate.Create(event.CreateEvent{Object: ns1}))
+	assert.True(t, terminatingNamespacePredicate.Create(event.CreateEvent{Object: ns2}))
+	assert.True(t, terminatingNamespacePredicate.Update(event.UpdateEvent{ObjectOld: ns1, ObjectNew: ns2}))
+	assert.False(t, terminatingNamespacePredicate.Update(event.UpdateEvent{ObjectOld: ns2, ObjectNew: ns2}))
+	notSkipped := predicate.NewPredicateFuncs(func(obj client.Object) bool { return obj.GetName() != "skipped" })
+	requests := requestsForNamespace(c, &v1alpha1.SubnetList{}, []predicate.Predicate{notSkipped})(ns2)
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "ns2", Name: "subnet1"}},
+		{NamespacedName: types.NamespacedName{Namespace: "ns2", Name: "subnet2"}},
+	}, requests)
+	assert.Empty(t, requestsForNamespace(c, &v1alpha1.SubnetList{}, nil)(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns3"}}))
+}
+
+func TestLockReconcile(t *testing.T) {
+	unlock := lockReconcile("subnet", "ns1/subnet1")
+	// the other CRs and controllers are not locked
+	lockReconcile("subnet", "ns1/subnet2")()
+	lockReconcile("vpc", "ns1/subnet1")()
+
+	var wg sync.WaitGroup
+	locked := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		unlockOther := lockReconcile("subnet", "ns1/subnet1")
+		close(locked)
+		unlockOther()
+	}()
+	select {
+	case <-locked:
+		t.Fatal("the reconciles of the CR are not serialized")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	wg.Wait()
+	reconcileLocks.Lock()
+	assert.Empty(t, reconcileLocks.locks)
+	reconcileLocks.Unlock()
+}
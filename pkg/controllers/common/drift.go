@@ -0,0 +1,84 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+)
+
+// DriftSource compares the NSX resources of a resource type with their CRs.
+type DriftSource struct {
+	// ResourceType labels the drift metrics, e.g. subnetport.
+	ResourceType string
+	Client       client.Client
+	// List is the empty list the CRs are listed in, e.g. &v1alpha1.SubnetPortList{}.
+	List client.ObjectList
+	// NSXUIDs returns the UIDs of the CRs the NSX resources in the stores are tagged with.
+	NSXUIDs func() sets.String
+}
+
+// Drift is the number of the CRs whose NSX resources are orphaned since the CRs no longer exist, and of the CRs
+// without NSX resources realized.
+type Drift struct {
+	Orphaned   int
+	Unrealized int
+}
+
+// Compute lists the CRs and compares them with the NSX resources, the CRs being deleted are not expected to be
+// realized.
+func (s *DriftSource) Compute(ctx context.Context) (Drift, error) {
+	list := s.List.DeepCopyObject().(client.ObjectList)
+	if err := s.Client.List(ctx, list); err != nil {
+		return Drift{}, err
+	}
+	objs, err := meta.ExtractList(list)
+	if err != nil {
+		return Drift{}, err
+	}
+	crUIDs, deletingUIDs := sets.NewString(), sets.NewString()
+	for _, obj := range objs {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return Drift{}, err
+		}
+		if accessor.GetDeletionTimestamp() != nil {
+			deletingUIDs.Insert(string(accessor.GetUID()))
+			continue
+		}
+		crUIDs.Insert(string(accessor.GetUID()))
+	}
+	nsxUIDs := s.NSXUIDs()
+	return Drift{
+		Orphaned:   nsxUIDs.Difference(crUIDs).Difference(deletingUIDs).Len(),
+		Unrealized: crUIDs.Difference(nsxUIDs).Len(),
+	}, nil
+}
+
+// ReportDrift exports the drift of the source as gauges periodically, giving a signal that the reconciles have fallen
+// behind or the NSX resources leaked.
+// cancel is used to break the loop during UT
+func ReportDrift(cancel chan bool, interval time.Duration, cf *config.NSXOperatorConfig, source DriftSource) {
+	ctx := context.Background()
+	for {
+		select {
+		case <-cancel:
+			return
+		case <-time.After(interval):
+		}
+		drift, err := source.Compute(ctx)
+		if err != nil {
+			logger.Log.Error(err, "failed to compute the drift between NSX and the CRs", "resourceType", source.ResourceType)
+			continue
+		}
+		metrics.GaugeSet(cf, metrics.DriftOrphanedNSXResources, float64(drift.Orphaned), source.ResourceType)
+		metrics.GaugeSet(cf, metrics.DriftUnrealizedCRs, float64(drift.Unrealized), source.ResourceType)
+		logger.Log.V(1).Info("computed the drift between NSX and the CRs", "resourceType", source.ResourceType, "orphaned", drift.Orphaned, "unrealized", drift.Unrealized)
+	}
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+)
+
+func TestDriftSource(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.Nil(t, v1alpha1.AddToScheme(scheme))
+	now := metav1.Now()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha1.IPPool{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "realized", UID: "uid1"}},
+		&v1alpha1.IPPool{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "unrealized", UID: "uid2"}},
+		&v1alpha1.IPPool{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "deleting", UID: "uid3",
+			DeletionTimestamp: &now, Finalizers: []string{"test"}}},
+	).Build()
+	source := DriftSource{
+		ResourceType: "drift-test",
+		Client:       c,
+		List:         &v1alpha1.IPPoolList{},
+		// uid3 is being deleted, uid4 is orphaned
+		NSXUIDs: func() sets.String { return sets.NewString("uid1", "uid3", "uid4") },
+	}
+
+	drift, err := source.Compute(context.TODO())
+	require.Nil(t, err)
+	assert.Equal(t, Drift{Orphaned: 1, Unrealized: 1}, drift)
+
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{EnablePromMetrics: true}, NsxConfig: &config.NsxConfig{}}
+	cancel := make(chan bool)
+	go ReportDrift(cancel, 10*time.Millisecond, cf, source)
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.DriftOrphanedNSXResources.WithLabelValues("drift-test")) == 1 &&
+			testutil.ToFloat64(metrics.DriftUnrealizedCRs.WithLabelValues("drift-test")) == 1
+	}, time.Second, 10*time.Millisecond)
+	cancel <- true
+}
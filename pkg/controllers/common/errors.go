@@ -0,0 +1,62 @@
+package common
+
+import (
+	"errors"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// The reasons of the conditions reporting the CRs which failed to be reconciled, chosen by the kind of the failure.
+const (
+	ReasonNSXNotFound         = "NSXNotFound"
+	ReasonNSXConflict         = "NSXConflict"
+	ReasonNSXUnauthorized     = "NSXUnauthorized"
+	ReasonNSXThrottled        = "NSXThrottled"
+	ReasonNSXUnavailable      = "NSXUnavailable"
+	ReasonNSXValidationFailed = "NSXValidationFailed"
+	ReasonReconcileFailed     = "ReconcileFailed"
+	// ReasonNotRealized is the reason of the conditions reporting the NSX resources which are not realized yet.
+	ReasonNotRealized = "NotRealized"
+)
+
+// ErrorReason returns the reason of the condition reporting err.
+func ErrorReason(err error) string {
+	switch nsxutil.TransNSXError(err).(type) {
+	case nsxutil.NotFoundError:
+		return ReasonNSXNotFound
+	case nsxutil.ConflictError:
+		return ReasonNSXConflict
+	case nsxutil.UnauthorizedError:
+		return ReasonNSXUnauthorized
+	case nsxutil.ThrottledError:
+		return ReasonNSXThrottled
+	case nsxutil.UnavailableError:
+		return ReasonNSXUnavailable
+	case nsxutil.ValidationFailedError:
+		return ReasonNSXValidationFailed
+	}
+	if errors.As(err, &nsxutil.NSXUnavailableError{}) {
+		return ReasonNSXUnavailable
+	}
+	exhausted := nsxutil.IPExhaustedError{}
+	if errors.As(err, &exhausted) {
+		return ReasonIPExhausted
+	}
+	return ReasonReconcileFailed
+}
+
+// ResultForError returns the result of a reconcile failed with err. The requests throttled by NSX are requeued after
+// a delay instead of with the exponential backoff, and the requests NSX rejects as invalid or unauthorized are only
+// checked again after 5 minutes as they fail until the CR or the credentials are changed. The requests failed for an
+// outage of NSX are requeued with the exponential backoff like the other failures.
+func ResultForError(err error) (ctrl.Result, error) {
+	switch nsxutil.TransNSXError(err).(type) {
+	case nsxutil.ThrottledError:
+		return ResultRequeueAfterThrottled, nil
+	case nsxutil.UnauthorizedError, nsxutil.ValidationFailedError:
+		return ResultRequeueAfter5mins, nil
+	}
+	return ResultRequeue, err
+}
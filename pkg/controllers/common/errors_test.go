@@ -0,0 +1,67 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// tooManyRequestsData is the error body of a 429 response.
+func tooManyRequestsData() *data.StructValue {
+	return data.NewStructValue("", map[string]data.DataValue{
+		"httpStatus": data.NewStringValue("TOO_MANY_REQUESTS"),
+		"error_code": data.NewIntegerValue(102),
+	})
+}
+
+func TestErrorReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{vapierrors.NotFound{}, ReasonNSXNotFound},
+		{vapierrors.ConcurrentChange{}, ReasonNSXConflict},
+		{vapierrors.Unauthenticated{}, ReasonNSXUnauthorized},
+		{vapierrors.ServiceUnavailable{Data: tooManyRequestsData()}, ReasonNSXThrottled},
+		{vapierrors.ServiceUnavailable{}, ReasonNSXUnavailable},
+		{fmt.Errorf("list VPCs: %w", nsxutil.NSXUnavailableError{Desc: "all the circuit breakers are open"}), ReasonNSXUnavailable},
+		{vapierrors.InvalidRequest{}, ReasonNSXValidationFailed},
+		{fmt.Errorf("allocate IP: %w", nsxutil.IPExhaustedError{Pool: "pool1"}), ReasonIPExhausted},
+		{errors.New("connection refused"), ReasonReconcileFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, ErrorReason(tt.err))
+		})
+	}
+}
+
+func TestResultForError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantResult ctrl.Result
+		wantErr    bool
+	}{
+		{"throttled", vapierrors.ServiceUnavailable{Data: tooManyRequestsData()}, ResultRequeueAfterThrottled, false},
+		{"unavailable", vapierrors.ServiceUnavailable{}, ResultRequeue, true},
+		{"unauthorized", vapierrors.Unauthorized{}, ResultRequeueAfter5mins, false},
+		{"validation failed", vapierrors.InvalidArgument{}, ResultRequeueAfter5mins, false},
+		{"conflict", vapierrors.ConcurrentChange{}, ResultRequeue, true},
+		{"other", errors.New("connection refused"), ResultRequeue, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ResultForError(tt.err)
+			assert.Equal(t, tt.wantResult, result)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
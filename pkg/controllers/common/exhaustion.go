@@ -0,0 +1,49 @@
+package common
+
+import (
+	"errors"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// ReasonIPExhausted is the reason of the Warning Events and the IPExhausted conditions reported on the CRs whose
+// requests cannot be satisfied by the IP allocators.
+const ReasonIPExhausted = "IPExhausted"
+
+// ReportIPExhaustion reports err on the requesting obj if the IP allocator runs out of IPs: it emits a Warning Event,
+// increments the exhaustion counter of the pool, and returns the IPExhausted condition to set. It returns nothing for
+// the other errors.
+func ReportIPExhaustion(recorder record.EventRecorder, cf *config.NSXOperatorConfig, obj runtime.Object, err error) []v1alpha1.Condition {
+	exhausted := nsxutil.IPExhaustedError{}
+	if !errors.As(err, &exhausted) {
+		return nil
+	}
+	recorder.Event(obj, v1.EventTypeWarning, ReasonIPExhausted, exhausted.Desc)
+	metrics.CounterInc(cf, metrics.IPExhaustionTotal, exhausted.Pool)
+	return []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.IPExhausted,
+			Status:  v1.ConditionTrue,
+			Reason:  ReasonIPExhausted,
+			Message: exhausted.Desc,
+		},
+	}
+}
+
+// ClearIPExhaustion returns the IPExhausted condition to set once the request is satisfied, if the conditions report
+// the exhaustion.
+func ClearIPExhaustion(conditions []v1alpha1.Condition) []v1alpha1.Condition {
+	for _, condition := range conditions {
+		if condition.Type == v1alpha1.IPExhausted && condition.Status == v1.ConditionTrue {
+			return []v1alpha1.Condition{{Type: v1alpha1.IPExhausted, Status: v1.ConditionFalse}}
+		}
+	}
+	return nil
+}
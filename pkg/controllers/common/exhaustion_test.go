@@ -0,0 +1,41 @@
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func TestReportIPExhaustion(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	cf := &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}}
+	obj := &v1alpha1.IPPool{ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1"}}
+
+	assert.Nil(t, ReportIPExhaustion(recorder, cf, obj, errors.New("connection refused")))
+	assert.Equal(t, 0, len(recorder.Events))
+
+	err := nsxutil.IPExhaustedError{Pool: "/infra/ip-blocks/block1", Desc: "no IP available in /infra/ip-blocks/block1"}
+	conditions := ReportIPExhaustion(recorder, cf, obj, err)
+	assert.Equal(t, []v1alpha1.Condition{{
+		Type:    v1alpha1.IPExhausted,
+		Status:  v1.ConditionTrue,
+		Reason:  ReasonIPExhausted,
+		Message: err.Desc,
+	}}, conditions)
+	assert.Equal(t, "Warning IPExhausted no IP available in /infra/ip-blocks/block1", <-recorder.Events)
+}
+
+func TestClearIPExhaustion(t *testing.T) {
+	assert.Nil(t, ClearIPExhaustion(nil))
+	assert.Nil(t, ClearIPExhaustion([]v1alpha1.Condition{{Type: v1alpha1.IPExhausted, Status: v1.ConditionFalse}}))
+	assert.Equal(t, []v1alpha1.Condition{{Type: v1alpha1.IPExhausted, Status: v1.ConditionFalse}},
+		ClearIPExhaustion([]v1alpha1.Condition{{Type: v1alpha1.Ready, Status: v1.ConditionFalse}, {Type: v1alpha1.IPExhausted, Status: v1.ConditionTrue}}))
+}
@@ -0,0 +1,255 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/health"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// ReasonGCDryRun is the reason of the Events recorded for the NSX resources the garbage collectors in dry run would
+// delete.
+const ReasonGCDryRun = "GarbageCollectionDryRun"
+
+// ReasonGCAborted is the reason of the Events recorded for the runs of the garbage collectors aborted because they
+// would delete too many NSX resources.
+const ReasonGCAborted = "GarbageCollectionAborted"
+
+// GCFunc runs the garbage collection of a resource type once, it counts the NSX resources it scans and deletes in run.
+// The run fails if it returns an error.
+type GCFunc func(ctx context.Context, run *servicecommon.GCRun) error
+
+// GarbageCollector deletes the NSX resources of a resource type whose CRs no longer exist, every interval.
+type GarbageCollector struct {
+	ResourceType string
+	// Interval between the runs, the collectors run every gc_interval if it is servicecommon.GCInterval.
+	Interval  time.Duration
+	NSXConfig *config.NSXOperatorConfig
+	Collect   GCFunc
+	// trigger runs the garbage collection before the interval ends, see TriggerGarbageCollection.
+	trigger chan struct{}
+}
+
+// NewGarbageCollector returns the garbage collector of resourceType running collect every interval.
+func NewGarbageCollector(resourceType string, interval time.Duration, cf *config.NSXOperatorConfig, collect GCFunc) *GarbageCollector {
+	return &GarbageCollector{ResourceType: resourceType, Interval: interval, NSXConfig: cf, Collect: collect, trigger: make(chan struct{}, 1)}
+}
+
+// Run runs the garbage collection every interval, or once triggered, until stop receives or is closed. The runs are
+// skipped while the NSX mutations are paused, or by the replicas which are not the shard coordinator.
+func (gc *GarbageCollector) Run(stop <-chan bool) {
+	ctx := context.Background()
+	logger.Log.Info("garbage collector started", "resourceType", gc.ResourceType)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(servicecommon.GCWait(gc.Interval)):
+		case <-gc.trigger:
+			logger.Log.Info("garbage collection triggered", "resourceType", gc.ResourceType)
+		}
+		if !ShardCoordinator() {
+			logger.Log.V(1).Info("garbage collection is run by the shard coordinator, skip it", "resourceType", gc.ResourceType)
+			continue
+		}
+		if paused, _ := nsxutil.MutationsPaused(); paused {
+			logger.Log.V(1).Info("NSX mutations are paused, skip garbage collection", "resourceType", gc.ResourceType)
+			continue
+		}
+		gc.RunOnce(ctx)
+	}
+}
+
+// RunOnce runs the garbage collection once and reports the run in the metrics and the GC statuses, the errors are
+// logged by Collect. A panic fails the run instead of stopping the collector.
+func (gc *GarbageCollector) RunOnce(ctx context.Context) {
+	run := servicecommon.StartGCRun(gc.NSXConfig, gc.ResourceType)
+	defer func() {
+		if p := recover(); p != nil {
+			err := fmt.Errorf("garbage collector panicked: %v", p)
+			logger.Log.Error(err, "garbage collection failed", "resourceType", gc.ResourceType, "stack", string(debug.Stack()))
+			run.Finish(err)
+		}
+	}()
+	run.Finish(gc.Collect(ctx, run))
+}
+
+// garbageCollectors are the garbage collectors registered by the controllers, they share the stop channel closed when
+// the manager stops.
+var garbageCollectors = struct {
+	collectors []*GarbageCollector
+	stop       chan bool
+	sync.Mutex
+}{}
+
+// RegisterGarbageCollector registers the garbage collector of a controller, it runs once GarbageCollectors is started
+// by the manager, or at once if they are already started.
+func RegisterGarbageCollector(gc *GarbageCollector) {
+	garbageCollectors.Lock()
+	defer garbageCollectors.Unlock()
+	garbageCollectors.collectors = append(garbageCollectors.collectors, gc)
+	if garbageCollectors.stop != nil {
+		go gc.Run(garbageCollectors.stop)
+	}
+}
+
+// TriggerGarbageCollection runs the garbage collectors of resourceType at once instead of waiting for their next run,
+// e.g. once the NSX resources are cleaned up manually. The collectors already triggered run only once. It fails if no
+// garbage collector of resourceType is registered, e.g. its controller is disabled.
+func TriggerGarbageCollection(resourceType string) error {
+	garbageCollectors.Lock()
+	defer garbageCollectors.Unlock()
+	triggered := false
+	for _, gc := range garbageCollectors.collectors {
+		if gc.ResourceType != resourceType || gc.trigger == nil {
+			continue
+		}
+		select {
+		case gc.trigger <- struct{}{}:
+		default:
+		}
+		triggered = true
+	}
+	if !triggered {
+		return fmt.Errorf("no garbage collector of %s", resourceType)
+	}
+	return nil
+}
+
+// GarbageCollectors runs the registered garbage collectors, it is added to the manager.
+type GarbageCollectors struct{}
+
+// Start runs the registered garbage collectors until ctx is done.
+func (GarbageCollectors) Start(ctx context.Context) error {
+	garbageCollectors.Lock()
+	stop := make(chan bool)
+	garbageCollectors.stop = stop
+	for _, gc := range garbageCollectors.collectors {
+		go gc.Run(stop)
+	}
+	garbageCollectors.Unlock()
+
+	<-ctx.Done()
+	garbageCollectors.Lock()
+	garbageCollectors.stop = nil
+	garbageCollectors.Unlock()
+	close(stop)
+	return nil
+}
+
+// NeedLeaderElection returns true, the garbage collectors only run in the leader like the controllers, the replicas
+// would otherwise delete the same NSX resources concurrently.
+func (GarbageCollectors) NeedLeaderElection() bool {
+	return true
+}
+
+// gcSettings are the resource types whose garbage collectors run in dry run, all of them if dryRunAll is set, the
+// limits of the NSX resources deleted in one run, and the recorder of the Events of the garbage collectors.
+var gcSettings = struct {
+	dryRunAll           bool
+	dryRunResourceTypes sets.String
+	maxDeletions        int
+	maxDeletionPercent  int
+	recorder            record.EventRecorder
+	sync.RWMutex
+}{dryRunResourceTypes: sets.NewString()}
+
+// SetGCDryRun sets the garbage collectors of all the resource types if all is set, or of resourceTypes, in dry run, it
+// takes effect on their next run.
+func SetGCDryRun(all bool, resourceTypes []string) {
+	gcSettings.Lock()
+	defer gcSettings.Unlock()
+	gcSettings.dryRunAll = all
+	gcSettings.dryRunResourceTypes = sets.NewString(resourceTypes...)
+}
+
+// SetGCDeletionLimits sets the most NSX resources a garbage collector can delete in one run, and the most in percent
+// of the NSX resources it scans, there is no limit if they are 0. It takes effect on their next run.
+func SetGCDeletionLimits(maxDeletions, maxDeletionPercent int) {
+	gcSettings.Lock()
+	defer gcSettings.Unlock()
+	gcSettings.maxDeletions = maxDeletions
+	gcSettings.maxDeletionPercent = maxDeletionPercent
+}
+
+// SetGCEventRecorder records the NSX resources the garbage collectors in dry run would delete and the aborted runs as
+// Events on the NSXOperatorHealth CR, nil stops recording them.
+func SetGCEventRecorder(recorder record.EventRecorder) {
+	gcSettings.Lock()
+	defer gcSettings.Unlock()
+	gcSettings.recorder = recorder
+}
+
+// recordGCEvent records an Event of the garbage collectors on the NSXOperatorHealth CR.
+func recordGCEvent(recorder record.EventRecorder, eventType, reason, messageFmt string, args ...interface{}) {
+	if recorder == nil {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		APIVersion: v1alpha1.GroupVersion.String(),
+		Kind:       "NSXOperatorHealth",
+		Name:       health.NSXOperatorHealthName,
+	}
+	recorder.Eventf(ref, eventType, reason, messageFmt, args...)
+}
+
+// GCDryRun returns whether the garbage collector of resourceType runs in dry run, the collector must then skip deleting
+// the NSX resources of id, e.g. the UID of the deleted CR, which are logged and recorded as an Event instead.
+func GCDryRun(resourceType, id string) bool {
+	gcSettings.RLock()
+	dryRun := gcSettings.dryRunAll || gcSettings.dryRunResourceTypes.Has(resourceType)
+	recorder := gcSettings.recorder
+	gcSettings.RUnlock()
+	if !dryRun {
+		return false
+	}
+
+	logger.Log.Info("garbage collection dry run, skip deleting NSX resources", "resourceType", resourceType, "id", id)
+	recordGCEvent(recorder, corev1.EventTypeNormal, ReasonGCDryRun, "Garbage collector of %s would delete the NSX resources of %s",
+		resourceType, id)
+	return true
+}
+
+// CheckGCDeletions returns an error if the garbage collector of resourceType would delete more NSX resources in one run
+// than the limits set by SetGCDeletionLimits, the NSX resources of nsxSet whose CRs are not in crSet are deleted out of
+// the ones of nsxSet scanned. The collector must then abort the run before deleting any of them, e.g. the CRs look
+// deleted while the API server is restored from an outdated backup. The aborted run is logged and recorded as an
+// Event.
+func CheckGCDeletions(cf *config.NSXOperatorConfig, resourceType string, nsxSet, crSet sets.String) error {
+	gcSettings.RLock()
+	maxDeletions, maxDeletionPercent := gcSettings.maxDeletions, gcSettings.maxDeletionPercent
+	recorder := gcSettings.recorder
+	gcSettings.RUnlock()
+	deletions := nsxSet.Difference(crSet).Len()
+	if deletions == 0 {
+		return nil
+	}
+
+	var err error
+	if maxDeletions > 0 && deletions > maxDeletions {
+		err = fmt.Errorf("garbage collection aborted, %d NSX resources would be deleted, more than %d", deletions, maxDeletions)
+	} else if maxDeletionPercent > 0 && deletions*100 > maxDeletionPercent*nsxSet.Len() {
+		err = fmt.Errorf("garbage collection aborted, %d of %d NSX resources would be deleted, more than %d%%", deletions,
+			nsxSet.Len(), maxDeletionPercent)
+	}
+	if err == nil {
+		return nil
+	}
+	logger.Log.Error(err, "too many NSX resources to delete", "resourceType", resourceType)
+	metrics.CounterInc(cf, metrics.GCAbortedTotal, resourceType)
+	recordGCEvent(recorder, corev1.EventTypeWarning, ReasonGCAborted, "Garbage collector of %s: %v", resourceType, err)
+	return err
+}
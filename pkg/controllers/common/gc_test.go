@@ -0,0 +1,155 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func gcStatus(resourceType string) servicecommon.GCStatus {
+	for _, status := range servicecommon.GCStatuses() {
+		if status.ResourceType == resourceType {
+			return status
+		}
+	}
+	return servicecommon.GCStatus{}
+}
+
+func TestGarbageCollector(t *testing.T) {
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	runs := 0
+	gc := NewGarbageCollector("gc-test", 10*time.Millisecond, cf, func(ctx context.Context, run *servicecommon.GCRun) error {
+		runs++
+		run.Scanned(2)
+		switch runs {
+		case 1:
+			run.Deleted(nil)
+			return nil
+		case 2:
+			return errors.New("failed to list")
+		default:
+			panic("nil pointer")
+		}
+	})
+
+	gc.RunOnce(context.TODO())
+	status := gcStatus("gc-test")
+	assert.Equal(t, 2, status.Scanned)
+	assert.Equal(t, 1, status.Deleted)
+	assert.Equal(t, "", status.LastError)
+	gc.RunOnce(context.TODO())
+	assert.Equal(t, "failed to list", gcStatus("gc-test").LastError)
+	// the panic fails the run without stopping the collector
+	gc.RunOnce(context.TODO())
+	assert.Contains(t, gcStatus("gc-test").LastError, "garbage collector panicked: nil pointer")
+	assert.Equal(t, 3, runs)
+
+	last := gcStatus("gc-test").LastRunTime
+	stop := make(chan bool)
+	go gc.Run(stop)
+	assert.Eventually(t, func() bool { return gcStatus("gc-test").LastRunTime.After(last) }, time.Second, 10*time.Millisecond)
+	stop <- true
+}
+
+func TestGarbageCollectors(t *testing.T) {
+	defer func() { garbageCollectors.collectors = nil }()
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	runs := make(chan string, 1)
+	collect := func(name string) GCFunc {
+		return func(ctx context.Context, run *servicecommon.GCRun) error {
+			select {
+			case runs <- name:
+			default:
+			}
+			return nil
+		}
+	}
+	RegisterGarbageCollector(NewGarbageCollector("gc-before", 10*time.Millisecond, cf, collect("gc-before")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- GarbageCollectors{}.Start(ctx) }()
+	assert.Equal(t, "gc-before", <-runs)
+
+	// the collectors registered once the others are started are started at once
+	RegisterGarbageCollector(NewGarbageCollector("gc-after", time.Hour, cf, collect("gc-after")))
+	garbageCollectors.Lock()
+	assert.Equal(t, 2, len(garbageCollectors.collectors))
+	assert.NotNil(t, garbageCollectors.stop)
+	garbageCollectors.Unlock()
+
+	cancel()
+	assert.Nil(t, <-done)
+	assert.True(t, GarbageCollectors{}.NeedLeaderElection())
+}
+
+func TestTriggerGarbageCollection(t *testing.T) {
+	defer func() { garbageCollectors.collectors = nil }()
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	runs := make(chan struct{}, 1)
+	gc := NewGarbageCollector("gc-trigger", time.Hour, cf, func(ctx context.Context, run *servicecommon.GCRun) error {
+		runs <- struct{}{}
+		return nil
+	})
+	RegisterGarbageCollector(gc)
+	assert.EqualError(t, TriggerGarbageCollection("gc-unknown"), "no garbage collector of gc-unknown")
+
+	// the triggers before the collector runs are merged
+	assert.Nil(t, TriggerGarbageCollection("gc-trigger"))
+	assert.Nil(t, TriggerGarbageCollection("gc-trigger"))
+	stop := make(chan bool)
+	defer close(stop)
+	go gc.Run(stop)
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("expected the garbage collector to run once triggered")
+	}
+	assert.Eventually(t, func() bool { return len(gc.trigger) == 0 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, len(runs))
+}
+
+func TestGCDryRun(t *testing.T) {
+	defer SetGCDryRun(false, nil)
+	defer SetGCEventRecorder(nil)
+	recorder := record.NewFakeRecorder(10)
+	SetGCEventRecorder(recorder)
+
+	assert.False(t, GCDryRun(MetricResTypeVPC, "uid-1"))
+	SetGCDryRun(false, []string{MetricResTypeSubnet})
+	assert.False(t, GCDryRun(MetricResTypeVPC, "uid-1"))
+	assert.True(t, GCDryRun(MetricResTypeSubnet, "uid-2"))
+	assert.Equal(t, "Normal GarbageCollectionDryRun Garbage collector of subnet would delete the NSX resources of uid-2", <-recorder.Events)
+	SetGCDryRun(true, nil)
+	assert.True(t, GCDryRun(MetricResTypeVPC, "uid-1"))
+	assert.Equal(t, 1, len(recorder.Events))
+}
+
+func TestCheckGCDeletions(t *testing.T) {
+	defer SetGCDeletionLimits(0, 0)
+	defer SetGCEventRecorder(nil)
+	recorder := record.NewFakeRecorder(10)
+	SetGCEventRecorder(recorder)
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	nsxSet := sets.NewString("uid1", "uid2", "uid3", "uid4")
+
+	assert.Nil(t, CheckGCDeletions(cf, MetricResTypeVPC, nsxSet, sets.NewString()))
+	SetGCDeletionLimits(2, 0)
+	assert.Nil(t, CheckGCDeletions(cf, MetricResTypeVPC, nsxSet, sets.NewString("uid1", "uid2")))
+	assert.EqualError(t, CheckGCDeletions(cf, MetricResTypeVPC, nsxSet, sets.NewString("uid1")),
+		"garbage collection aborted, 3 NSX resources would be deleted, more than 2")
+	assert.Equal(t, "Warning GarbageCollectionAborted Garbage collector of vpc: garbage collection aborted, 3 NSX resources would be deleted, more than 2",
+		<-recorder.Events)
+	SetGCDeletionLimits(0, 50)
+	assert.Nil(t, CheckGCDeletions(cf, MetricResTypeVPC, nsxSet, sets.NewString("uid1", "uid2")))
+	assert.EqualError(t, CheckGCDeletions(cf, MetricResTypeVPC, nsxSet, sets.NewString("uid1", "uid5")),
+		"garbage collection aborted, 3 of 4 NSX resources would be deleted, more than 50%")
+}
@@ -0,0 +1,194 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	"github.com/vmware-tanzu/nsx-operator/pkg/tracing"
+)
+
+// The reasons of the requeues reported by the reconcile metrics, chosen by the result of the reconcile.
+const (
+	RequeueReasonError          = "error"
+	RequeueReasonRequeue        = "requeue"
+	RequeueReasonRequeueAfter   = "requeue_after"
+	RequeueReasonNotRealized    = "not_realized"
+	RequeueReasonPaused         = "paused"
+	RequeueReasonNSXUnavailable = "nsx_unavailable"
+	RequeueReasonThrottled      = "throttled"
+	RequeueReasonRetryLater     = "retry_later"
+)
+
+// requeueReasons are the reasons of the results the controllers share.
+var requeueReasons = map[ctrl.Result]string{
+	ResultRequeue:                    RequeueReasonRequeue,
+	ResultRequeueAfter10sec:          RequeueReasonNotRealized,
+	ResultRequeueAfterPaused:         RequeueReasonPaused,
+	ResultRequeueAfterNSXUnavailable: RequeueReasonNSXUnavailable,
+	ResultRequeueAfterThrottled:      RequeueReasonThrottled,
+	ResultRequeueAfter5mins:          RequeueReasonRetryLater,
+}
+
+// DefaultStuckReconcileTimeout is how long a reconcile can run before the liveness probe fails if
+// stuck_reconcile_timeout is unset.
+const DefaultStuckReconcileTimeout = 30 * time.Minute
+
+// inflightReconcile is a reconcile being run by a worker of a controller.
+type inflightReconcile struct {
+	controller string
+	request    ctrl.Request
+	start      time.Time
+}
+
+// inflightReconciles are the reconciles of all the instrumented controllers being run, a reconcile which never returns
+// blocks a worker of the work queue of its controller.
+var inflightReconciles = struct {
+	reconciles map[uint64]inflightReconcile
+	next       uint64
+	sync.Mutex
+}{reconciles: map[uint64]inflightReconcile{}}
+
+func startReconcile(controller string, req ctrl.Request, start time.Time) uint64 {
+	inflightReconciles.Lock()
+	defer inflightReconciles.Unlock()
+	inflightReconciles.next++
+	inflightReconciles.reconciles[inflightReconciles.next] = inflightReconcile{controller: controller, request: req, start: start}
+	return inflightReconciles.next
+}
+
+func finishReconcile(id uint64) {
+	inflightReconciles.Lock()
+	defer inflightReconciles.Unlock()
+	delete(inflightReconciles.reconciles, id)
+}
+
+// Reconcile is a reconcile being run, as dumped for the support bundles.
+type Reconcile struct {
+	Controller string    `json:"controller"`
+	Namespace  string    `json:"namespace,omitempty"`
+	Name       string    `json:"name"`
+	Start      time.Time `json:"start"`
+}
+
+// InflightReconciles returns the reconciles of the instrumented controllers being run, the earliest first.
+func InflightReconciles() []Reconcile {
+	inflightReconciles.Lock()
+	defer inflightReconciles.Unlock()
+	reconciles := make([]Reconcile, 0, len(inflightReconciles.reconciles))
+	for _, r := range inflightReconciles.reconciles {
+		reconciles = append(reconciles, Reconcile{Controller: r.controller, Namespace: r.request.Namespace, Name: r.request.Name, Start: r.start})
+	}
+	sort.Slice(reconciles, func(i, j int) bool {
+		return reconciles[i].Start.Before(reconciles[j].Start)
+	})
+	return reconciles
+}
+
+// StuckReconcileChecker returns the liveness check failing once a reconcile has run for longer than timeout, the work
+// queue of its controller is stuck and NSX Operator is restarted. DefaultStuckReconcileTimeout is used if timeout is 0.
+func StuckReconcileChecker(timeout time.Duration) healthz.Checker {
+	if timeout <= 0 {
+		timeout = DefaultStuckReconcileTimeout
+	}
+	return func(_ *http.Request) error {
+		inflightReconciles.Lock()
+		defer inflightReconciles.Unlock()
+		now := time.Now()
+		for _, r := range inflightReconciles.reconciles {
+			if elapsed := now.Sub(r.start); elapsed > timeout {
+				return fmt.Errorf("reconcile of %s %s is running for %s", r.controller, r.request.NamespacedName, elapsed.Round(time.Second))
+			}
+		}
+		return nil
+	}
+}
+
+// instrumentedReconciler records the reconcile metrics of the controller around the reconciles of its Reconciler, and
+// traces each reconcile in a span the spans of the NSX requests sent for it are children of.
+type instrumentedReconciler struct {
+	reconcile.Reconciler
+	cf         *config.NSXOperatorConfig
+	controller string
+}
+
+// Instrument wraps the Reconciler of the controller to record the number, the duration, the errors and the requeues of
+// its reconciles, they are labelled with controller, e.g. MetricResTypeVPC, to trace them and to detect the stuck ones
+// with StuckReconcileChecker. The CRs of the namespaces owned by the other replicas are skipped if the sharding is
+// enabled, see OwnsNamespace, and all the CRs once the manager stops, see GracefulShutdown. The context of the
+// reconciles carries the reconcile ID and the namespace and name of the CR to correlate the logs with if the logs are
+// in the JSON format.
+func Instrument(cf *config.NSXOperatorConfig, controller string, r reconcile.Reconciler) reconcile.Reconciler {
+	return &instrumentedReconciler{Reconciler: r, cf: cf, controller: controller}
+}
+
+func (r *instrumentedReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if !OwnsNamespace(req.Namespace) {
+		// reconciled by the replica owning the namespace
+		return ResultNormal, nil
+	}
+	if shuttingDown.Load() {
+		// reconciled again once NSX Operator starts
+		logger.Log.V(1).Info("NSX Operator is shutting down, skip reconcile", "controller", r.controller, "request", req)
+		return ResultNormal, nil
+	}
+	// the CR may be queued by the deletion controller too
+	unlock := lockReconcile(r.controller, req.String())
+	defer unlock()
+	start := time.Now()
+	id := startReconcile(r.controller, req, start)
+	defer finishReconcile(id)
+	if logger.CorrelationEnabled() {
+		ctx = logger.WithCorrelation(ctx,
+			logger.CorrelationKeyController, r.controller,
+			logger.CorrelationKeyReconcileID, string(controller.ReconcileIDFromContext(ctx)),
+			logger.CorrelationKeyNamespace, req.Namespace,
+			logger.CorrelationKeyName, req.Name,
+		)
+	}
+	ctx, span := tracing.Start(ctx, "Reconcile "+r.controller, trace.SpanKindInternal,
+		attribute.String("controller", r.controller),
+		attribute.String("k8s.namespace.name", req.Namespace),
+		attribute.String("name", req.Name),
+	)
+	result, err := r.Reconciler.Reconcile(ctx, req)
+	outcome, errorClass, requeueReason := observeResult(result, err)
+	if requeueReason != "" {
+		span.SetAttributes(attribute.String("requeue.reason", requeueReason))
+	}
+	tracing.End(span, err)
+	duration := time.Since(start)
+	metrics.ObserveReconcile(r.cf, r.controller, duration, outcome, errorClass, requeueReason)
+	logger.FromContext(ctx, logger.Log).V(1).Info("reconciled", "result", outcome, "errorClass", errorClass, "requeueReason", requeueReason, "duration", duration)
+	return result, err
+}
+
+// observeResult returns the result label of the reconcile, the class of its error and the reason of its requeue.
+func observeResult(result ctrl.Result, err error) (string, string, string) {
+	if err != nil {
+		return metrics.ReconcileResultError, ErrorReason(err), RequeueReasonError
+	}
+	if !result.Requeue && result.RequeueAfter == 0 {
+		return metrics.ReconcileResultSuccess, "", ""
+	}
+	if reason, ok := requeueReasons[result]; ok {
+		return metrics.ReconcileResultRequeue, "", reason
+	}
+	if result.RequeueAfter > 0 {
+		return metrics.ReconcileResultRequeue, "", RequeueReasonRequeueAfter
+	}
+	return metrics.ReconcileResultRequeue, "", RequeueReasonRequeue
+}
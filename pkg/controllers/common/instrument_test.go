@@ -0,0 +1,104 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+)
+
+func TestObserveResult(t *testing.T) {
+	tests := []struct {
+		name          string
+		result        ctrl.Result
+		err           error
+		outcome       string
+		errorClass    string
+		requeueReason string
+	}{
+		{"success", ResultNormal, nil, metrics.ReconcileResultSuccess, "", ""},
+		{"error", ResultRequeue, vapierrors.ConcurrentChange{}, metrics.ReconcileResultError, ReasonNSXConflict, RequeueReasonError},
+		{"requeue", ResultRequeue, nil, metrics.ReconcileResultRequeue, "", RequeueReasonRequeue},
+		{"throttled", ResultRequeueAfterThrottled, nil, metrics.ReconcileResultRequeue, "", RequeueReasonThrottled},
+		{"paused", ResultRequeueAfterPaused, nil, metrics.ReconcileResultRequeue, "", RequeueReasonPaused},
+		{"not realized", ResultRequeueAfter10sec, nil, metrics.ReconcileResultRequeue, "", RequeueReasonNotRealized},
+		{"requeue after", ctrl.Result{RequeueAfter: time.Hour}, nil, metrics.ReconcileResultRequeue, "", RequeueReasonRequeueAfter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, errorClass, requeueReason := observeResult(tt.result, tt.err)
+			assert.Equal(t, tt.outcome, outcome)
+			assert.Equal(t, tt.errorClass, errorClass)
+			assert.Equal(t, tt.requeueReason, requeueReason)
+		})
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{EnablePromMetrics: true}, NsxConfig: &config.NsxConfig{}}
+	failed := errors.New("connection refused")
+	r := Instrument(cf, "test", reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		if req.Name == "failed" {
+			return ResultRequeue, failed
+		}
+		return ResultNormal, nil
+	}))
+
+	_, err := r.Reconcile(context.TODO(), ctrl.Request{})
+	assert.Nil(t, err)
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{})
+	assert.Nil(t, err)
+	result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "failed"}})
+	assert.Equal(t, failed, err)
+	assert.Equal(t, ResultRequeue, result)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.ReconcileTotal.WithLabelValues("test", metrics.ReconcileResultSuccess)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.ReconcileTotal.WithLabelValues("test", metrics.ReconcileResultError)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.ReconcileErrors.WithLabelValues("test", ReasonReconcileFailed)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.RequeueTotal.WithLabelValues("test", RequeueReasonError)))
+
+	// nothing is recorded unless the metrics are exposed
+	cf.EnablePromMetrics = false
+	_, _ = r.Reconcile(context.TODO(), ctrl.Request{})
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.ReconcileTotal.WithLabelValues("test", metrics.ReconcileResultSuccess)))
+}
+
+func TestStuckReconcileChecker(t *testing.T) {
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	running, release := make(chan struct{}), make(chan struct{})
+	r := Instrument(cf, "test", reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		close(running)
+		<-release
+		return ResultNormal, nil
+	}))
+	check := StuckReconcileChecker(10 * time.Millisecond)
+	assert.Nil(t, check(nil))
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "stuck"}})
+		close(done)
+	}()
+	<-running
+	assert.Nil(t, StuckReconcileChecker(0)(nil))
+	time.Sleep(20 * time.Millisecond)
+	assert.ErrorContains(t, check(nil), "reconcile of test ns1/stuck is running for")
+	reconciles := InflightReconciles()
+	assert.Len(t, reconciles, 1)
+	assert.Equal(t, "stuck", reconciles[0].Name)
+
+	close(release)
+	<-done
+	assert.Nil(t, check(nil))
+	assert.Empty(t, InflightReconciles())
+}
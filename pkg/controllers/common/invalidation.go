@@ -0,0 +1,48 @@
+package common
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// storeInvalidationBuffer is the number of the CRs queued for reconcile by a StoreInvalidationSource before the
+// controller reads them, the CRs beyond are dropped.
+const storeInvalidationBuffer = 1024
+
+// StoreInvalidationSource returns the source of the CRs whose NSX resources are changed out of band, the controllers
+// watch it to reconcile them without waiting for the CRs to change. The CRs are read from the tags of the NSX
+// resources, nameScope is the scope of the tag of their names, e.g. nsx-op/subnet_cr_name, and newObject returns an
+// empty CR.
+func StoreInvalidationSource(nameScope string, newObject func() client.Object) source.Source {
+	events := make(chan event.GenericEvent, storeInvalidationBuffer)
+	servicecommon.OnStoreInvalidation(func(invalidation servicecommon.StoreInvalidation) {
+		var namespace, name string
+		for _, tag := range invalidation.Tags {
+			switch *tag.Scope {
+			case servicecommon.TagScopeNamespace:
+				namespace = *tag.Tag
+			case nameScope:
+				name = *tag.Tag
+			}
+		}
+		if name == "" {
+			return
+		}
+		obj := newObject()
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		select {
+		case events <- event.GenericEvent{Object: obj}:
+			logger.Log.V(1).Info("NSX resource changed out of band, reconcile its CR", "resourceType", invalidation.ResourceType,
+				"namespace", namespace, "name", name)
+		default:
+			logger.Log.Info("too many NSX resources changed out of band, drop the reconcile of their CR",
+				"resourceType", invalidation.ResourceType, "namespace", namespace, "name", name)
+		}
+	})
+	return &source.Channel{Source: events}
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type fakeQueryClient struct {
+	results []*data.StructValue
+}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	resultCount := int64(len(c.results))
+	return model.SearchResponse{Results: c.results, ResultCount: &resultCount}, nil
+}
+
+type fakeSubnetStore struct {
+	*servicecommon.ResourceStore
+}
+
+func (s *fakeSubnetStore) Operate(_ interface{}) error {
+	return nil
+}
+
+func TestStoreInvalidationSource(t *testing.T) {
+	src := StoreInvalidationSource(servicecommon.TagScopeSubnetCRName, func() client.Object { return &v1alpha1.Subnet{} })
+	events := src.(*source.Channel).Source
+
+	id, user := "subnet1", "someone"
+	scopeNamespace, namespace := servicecommon.TagScopeNamespace, "ns1"
+	scopeName, name := servicecommon.TagScopeSubnetCRName, "subnet1"
+	value, errs := servicecommon.NewConverter().ConvertToVapi(model.VpcSubnet{
+		Id:               &id,
+		LastModifiedUser: &user,
+		Tags:             []model.Tag{{Scope: &scopeNamespace, Tag: &namespace}, {Scope: &scopeName, Tag: &name}},
+	}, model.VpcSubnetBindingType())
+	assert.Nil(t, errs)
+
+	cf := &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{NsxApiUser: "admin"}, CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}}
+	queryClient := &fakeQueryClient{results: []*data.StructValue{value.(*data.StructValue)}}
+	service := &servicecommon.Service{NSXClient: &nsx.Client{QueryClient: queryClient, NsxConfig: cf}, NSXConfig: cf}
+	store := &fakeSubnetStore{ResourceStore: &servicecommon.ResourceStore{Indexer: cache.NewIndexer(func(obj interface{}) (string, error) {
+		return *obj.(model.VpcSubnet).Id, nil
+	}, cache.Indexers{}), BindingType: model.VpcSubnetBindingType()}}
+	_, changed, err := service.SearchModifiedResource(servicecommon.ResourceTypeSubnet, store, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), changed)
+
+	select {
+	case e := <-events:
+		assert.IsType(t, &v1alpha1.Subnet{}, e.Object)
+		assert.Equal(t, "ns1", e.Object.GetNamespace())
+		assert.Equal(t, "subnet1", e.Object.GetName())
+	default:
+		t.Fatal("expected the Subnet to be reconciled")
+	}
+}
@@ -0,0 +1,11 @@
+package common
+
+// RunWhenElected runs f in a goroutine once elected is closed, i.e. the manager is elected as the leader of the
+// replicas, or at once if the leader election is disabled. The background loops of the controllers which mutate NSX or
+// the CRs, e.g. the reporters and the auditors, must only run in the leader like the reconciles.
+func RunWhenElected(elected <-chan struct{}, f func()) {
+	go func() {
+		<-elected
+		f()
+	}()
+}
@@ -0,0 +1,26 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWhenElected(t *testing.T) {
+	elected := make(chan struct{})
+	ran := make(chan struct{})
+	RunWhenElected(elected, func() { close(ran) })
+
+	select {
+	case <-ran:
+		t.Fatal("ran before elected")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(elected)
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		assert.Fail(t, "not run once elected")
+	}
+}
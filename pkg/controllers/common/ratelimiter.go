@@ -0,0 +1,20 @@
+package common
+
+import (
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+// RateLimiter returns the rate limiter of the work queue of a controller, like the default one of controller-runtime
+// with the delays and the rate of workqueue_base_delay, workqueue_max_delay, workqueue_qps and workqueue_burst. The
+// CRs failing to reconcile are requeued after a delay doubling on each failure, and the requeues of all the CRs of the
+// controller are limited to the rate. Each controller must get its own.
+func RateLimiter(cf *config.NSXOperatorConfig) workqueue.RateLimiter {
+	baseDelay, maxDelay, qps, burst := cf.GetWorkqueueRateLimits()
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
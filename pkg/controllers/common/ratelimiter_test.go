@@ -0,0 +1,37 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestRateLimiter(t *testing.T) {
+	cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{}}
+	limiter := RateLimiter(cf)
+	assert.Equal(t, 5*time.Millisecond, limiter.When("cr1"))
+	assert.Equal(t, 10*time.Millisecond, limiter.When("cr1"))
+	limiter.Forget("cr1")
+	assert.Equal(t, 5*time.Millisecond, limiter.When("cr1"))
+
+	cf.WorkqueueBaseDelay = 1000
+	cf.WorkqueueMaxDelay = 3
+	limiter = RateLimiter(cf)
+	assert.Equal(t, time.Second, limiter.When("cr1"))
+	assert.Equal(t, 2*time.Second, limiter.When("cr1"))
+	assert.Equal(t, 3*time.Second, limiter.When("cr1"))
+	assert.Equal(t, 3*time.Second, limiter.When("cr1"))
+	assert.Equal(t, 4, limiter.NumRequeues("cr1"))
+
+	// the requeues beyond the burst are delayed by the rate
+	cf.WorkqueueBaseDelay = 1
+	cf.WorkqueueQPS = 1
+	cf.WorkqueueBurst = 2
+	limiter = RateLimiter(cf)
+	assert.Equal(t, time.Millisecond, limiter.When("cr1"))
+	assert.Equal(t, time.Millisecond, limiter.When("cr2"))
+	assert.Greater(t, limiter.When("cr3"), 900*time.Millisecond)
+}
@@ -0,0 +1,301 @@
+package common
+
+import (
+	"context"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+const (
+	// LabelShardMember labels the Leases of the replicas sharing the namespaces, its value is the identity of the
+	// replica.
+	LabelShardMember = "nsx.vmware.com/shard-member"
+	// shardLeasePrefix prefixes the identity of a replica in the name of its Lease.
+	shardLeasePrefix = "nsx-operator-shard-"
+)
+
+// shards are the replicas of NSX Operator sharing the namespaces if the sharding is enabled, the members are the
+// identities of the replicas holding a Lease, sorted.
+var shards = struct {
+	enabled  bool
+	identity string
+	reader   client.Reader
+	members  []string
+	// syncedMembers are the members the stores are resynced for, the namespaces gained since are not owned until the
+	// stores are resynced for the members.
+	syncedMembers []string
+	handlers      []func(ShardRebalance)
+	sync.RWMutex
+}{}
+
+// resyncStores applies the NSX resources modified by the other replicas to the stores before the CRs of the namespaces
+// gained are reconciled, the stores hold all the NSX resources of the cluster but they are only kept up to date with
+// the changes of the replica otherwise.
+var resyncStores = servicecommon.ResyncStores
+
+// EnableSharding makes the replica of identity, e.g. the name of its pod, reconcile only the CRs of the namespaces it
+// owns, it owns none until ShardMembership finds the members. The CRs of the namespaces it gains are listed by reader,
+// usually the cached client of the manager.
+func EnableSharding(identity string, reader client.Reader) {
+	shards.Lock()
+	defer shards.Unlock()
+	shards.enabled = true
+	shards.identity = identity
+	shards.reader = reader
+	shards.members = nil
+	shards.syncedMembers = nil
+}
+
+// shardOwner returns the member owning namespace, the one with the highest hash of itself and namespace. Only the
+// namespaces of a member joining or leaving move to or from the other members.
+func shardOwner(members []string, namespace string) string {
+	owner, highest := "", uint64(0)
+	for _, member := range members {
+		h := fnv.New64a()
+		h.Write([]byte(member))
+		h.Write([]byte{0})
+		h.Write([]byte(namespace))
+		if sum := h.Sum64(); owner == "" || sum > highest {
+			owner, highest = member, sum
+		}
+	}
+	return owner
+}
+
+// OwnsNamespace returns whether the replica reconciles the CRs of namespace, the cluster scoped CRs have an empty
+// namespace. The namespaces gained are owned once the stores are resynced, ShardRebalanceSource sends their CRs then.
+// It returns true if the sharding is disabled.
+func OwnsNamespace(namespace string) bool {
+	shards.RLock()
+	defer shards.RUnlock()
+	if !shards.enabled {
+		return true
+	}
+	if shardOwner(shards.members, namespace) != shards.identity {
+		return false
+	}
+	return reflect.DeepEqual(shards.members, shards.syncedMembers) || shardOwner(shards.syncedMembers, namespace) == shards.identity
+}
+
+// ShardCoordinator returns whether the replica runs the garbage collectors and the background reporters scanning all
+// the namespaces, the one owning the cluster scoped CRs. It returns true if the sharding is disabled.
+func ShardCoordinator() bool {
+	return OwnsNamespace("")
+}
+
+// ShardRebalance is a change of the members sharing the namespaces.
+type ShardRebalance struct {
+	identity string
+	Previous []string
+	Current  []string
+	// storesSynced is closed once the stores are resynced for the namespaces gained, it is nil if the replica is not
+	// a member.
+	storesSynced chan struct{}
+}
+
+// WaitForStores waits until the stores are resynced with the NSX resources of the namespaces gained, which the other
+// replicas may have changed while they owned them.
+func (r ShardRebalance) WaitForStores() {
+	if r.storesSynced != nil {
+		<-r.storesSynced
+	}
+}
+
+// Gained returns whether the replica owns namespace since the rebalance but didn't before.
+func (r ShardRebalance) Gained(namespace string) bool {
+	return shardOwner(r.Current, namespace) == r.identity && shardOwner(r.Previous, namespace) != r.identity
+}
+
+// OnShardRebalance registers handler to be invoked when the members sharing the namespaces change, e.g. to reconcile
+// the CRs of the namespaces the replica gains.
+func OnShardRebalance(handler func(ShardRebalance)) {
+	shards.Lock()
+	defer shards.Unlock()
+	shards.handlers = append(shards.handlers, handler)
+}
+
+// setShardMembers sets the members sharing the namespaces and invokes the handlers if they change.
+func setShardMembers(members []string) {
+	members = append([]string{}, members...)
+	sort.Strings(members)
+	shards.Lock()
+	if reflect.DeepEqual(members, shards.members) || (len(members) == 0 && len(shards.members) == 0) {
+		shards.Unlock()
+		return
+	}
+	rebalance := ShardRebalance{identity: shards.identity, Previous: shards.members, Current: members}
+	shards.members = members
+	handlers := append([]func(ShardRebalance){}, shards.handlers...)
+	shards.Unlock()
+
+	logger.Log.Info("shard members changed", "previous", rebalance.Previous, "current", rebalance.Current)
+	if i := sort.SearchStrings(members, rebalance.identity); i < len(members) && members[i] == rebalance.identity {
+		rebalance.storesSynced = make(chan struct{})
+		resync := resyncStores
+		go func() {
+			defer close(rebalance.storesSynced)
+			resync()
+			setSyncedShardMembers(members)
+		}()
+	} else {
+		// no namespace is gained
+		setSyncedShardMembers(members)
+	}
+	for _, handler := range handlers {
+		handler(rebalance)
+	}
+}
+
+// setSyncedShardMembers records that the stores are resynced for members, unless the members changed again meanwhile
+// and the stores are being resynced for the new ones.
+func setSyncedShardMembers(members []string) {
+	shards.Lock()
+	defer shards.Unlock()
+	if reflect.DeepEqual(members, shards.members) {
+		shards.syncedMembers = members
+	}
+}
+
+// ShardRebalanceSource returns the source of the CRs of list, e.g. an empty SubnetList, in the namespaces the replica
+// gains when the members change, the controllers watch it to reconcile them without waiting for the CRs to change.
+// The CRs are sent once the stores are resynced, so that they are not reconciled against the NSX resources the
+// replica saw before the other replicas changed them.
+func ShardRebalanceSource(list client.ObjectList) source.Source {
+	events := make(chan event.GenericEvent)
+	OnShardRebalance(func(rebalance ShardRebalance) {
+		shards.RLock()
+		reader := shards.reader
+		shards.RUnlock()
+		if reader == nil {
+			return
+		}
+		go func() {
+			rebalance.WaitForStores()
+			objs := list.DeepCopyObject().(client.ObjectList)
+			if err := reader.List(context.TODO(), objs); err != nil {
+				logger.Log.Error(err, "failed to list the CRs of the namespaces gained")
+				return
+			}
+			items, err := meta.ExtractList(objs)
+			if err != nil {
+				logger.Log.Error(err, "failed to list the CRs of the namespaces gained")
+				return
+			}
+			for _, item := range items {
+				obj, ok := item.(client.Object)
+				if !ok || !rebalance.Gained(obj.GetNamespace()) {
+					continue
+				}
+				events <- event.GenericEvent{Object: obj}
+			}
+		}()
+	})
+	return &source.Channel{Source: events}
+}
+
+// ShardMembership holds the Lease of the replica in Namespace and finds the members sharing the namespaces from the
+// Leases renewed within their durations, every RetryPeriod. The Lease is deleted once the manager stops so that the
+// other replicas take over the namespaces at once. It is added to the manager if the sharding is enabled.
+type ShardMembership struct {
+	Client        client.Client
+	Reader        client.Reader
+	Namespace     string
+	Identity      string
+	LeaseDuration time.Duration
+	RetryPeriod   time.Duration
+}
+
+// Start renews the Lease and syncs the members every RetryPeriod until ctx is done.
+func (m *ShardMembership) Start(ctx context.Context) error {
+	var renewed time.Time
+	for {
+		if err := m.renew(ctx); err != nil {
+			logger.Log.Error(err, "failed to renew shard Lease", "identity", m.Identity)
+		} else {
+			renewed = time.Now()
+		}
+		if err := m.sync(ctx); err != nil {
+			logger.Log.Error(err, "failed to sync shard members")
+			// the other replicas take over the namespaces once the Lease expires, stop reconciling them before
+			if time.Since(renewed) > m.LeaseDuration {
+				setShardMembers(nil)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			setShardMembers(nil)
+			lease := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Namespace: m.Namespace, Name: shardLeasePrefix + m.Identity}}
+			if err := m.Client.Delete(context.Background(), lease); client.IgnoreNotFound(err) != nil {
+				logger.Log.Error(err, "failed to release shard Lease", "identity", m.Identity)
+			}
+			return nil
+		case <-time.After(m.RetryPeriod):
+		}
+	}
+}
+
+// NeedLeaderElection returns false, all the replicas are members.
+func (m *ShardMembership) NeedLeaderElection() bool {
+	return false
+}
+
+// renew creates or renews the Lease of the replica.
+func (m *ShardMembership) renew(ctx context.Context) error {
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32(m.LeaseDuration / time.Second)
+	lease := &coordinationv1.Lease{}
+	err := m.Reader.Get(ctx, client.ObjectKey{Namespace: m.Namespace, Name: shardLeasePrefix + m.Identity}, lease)
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: m.Namespace,
+				Name:      shardLeasePrefix + m.Identity,
+				Labels:    map[string]string{LabelShardMember: m.Identity},
+			},
+			Spec: coordinationv1.LeaseSpec{HolderIdentity: &m.Identity, LeaseDurationSeconds: &seconds, AcquireTime: &now, RenewTime: &now},
+		}
+		return m.Client.Create(ctx, lease)
+	}
+	if err != nil {
+		return err
+	}
+	lease.Spec.HolderIdentity = &m.Identity
+	lease.Spec.LeaseDurationSeconds = &seconds
+	lease.Spec.RenewTime = &now
+	return m.Client.Update(ctx, lease)
+}
+
+// sync sets the members to the holders of the Leases renewed within their durations.
+func (m *ShardMembership) sync(ctx context.Context) error {
+	leases := &coordinationv1.LeaseList{}
+	if err := m.Reader.List(ctx, leases, client.InNamespace(m.Namespace), client.HasLabels{LabelShardMember}); err != nil {
+		return err
+	}
+	now := time.Now()
+	var members []string
+	for _, lease := range leases.Items {
+		spec := lease.Spec
+		if spec.HolderIdentity == nil || spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+			continue
+		}
+		if spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second).After(now) {
+			members = append(members, *spec.HolderIdentity)
+		}
+	}
+	setShardMembers(members)
+	return nil
+}
@@ -0,0 +1,211 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func disableSharding() {
+	shards.Lock()
+	defer shards.Unlock()
+	shards.enabled = false
+	shards.identity = ""
+	shards.reader = nil
+	shards.members = nil
+	shards.syncedMembers = nil
+	shards.handlers = nil
+}
+
+// setShardMembersAndWait sets the members and waits until the stores are resynced for them.
+func setShardMembersAndWait(t *testing.T, members []string) {
+	setShardMembers(members)
+	assert.Eventually(t, func() bool {
+		shards.RLock()
+		defer shards.RUnlock()
+		return reflect.DeepEqual(shards.members, shards.syncedMembers)
+	}, time.Second, time.Millisecond)
+}
+
+func TestShardOwner(t *testing.T) {
+	assert.Equal(t, "", shardOwner(nil, "ns"))
+	members := []string{"a", "b", "c"}
+	owned := map[string]int{}
+	moved := 0
+	for i := 0; i < 300; i++ {
+		namespace := fmt.Sprintf("ns-%d", i)
+		owner := shardOwner(members, namespace)
+		assert.Equal(t, owner, shardOwner([]string{"c", "b", "a"}, namespace))
+		owned[owner]++
+		// only the namespaces of the member leaving move
+		if newOwner := shardOwner([]string{"a", "b"}, namespace); newOwner != owner {
+			assert.Equal(t, "c", owner)
+			moved++
+		}
+	}
+	assert.Equal(t, owned["c"], moved)
+	for _, member := range members {
+		assert.Greater(t, owned[member], 50, member)
+	}
+}
+
+func TestOwnsNamespace(t *testing.T) {
+	defer disableSharding()
+	assert.True(t, OwnsNamespace("ns"))
+	assert.True(t, ShardCoordinator())
+
+	EnableSharding("a", nil)
+	assert.False(t, OwnsNamespace("ns"))
+	var rebalances []ShardRebalance
+	OnShardRebalance(func(rebalance ShardRebalance) { rebalances = append(rebalances, rebalance) })
+
+	setShardMembersAndWait(t, []string{"a"})
+	assert.True(t, OwnsNamespace("ns"))
+	assert.True(t, ShardCoordinator())
+	assert.Equal(t, 1, len(rebalances))
+	assert.True(t, rebalances[0].Gained("ns"))
+
+	setShardMembers([]string{"a"})
+	assert.Equal(t, 1, len(rebalances))
+
+	setShardMembersAndWait(t, []string{"b", "a"})
+	assert.Equal(t, 2, len(rebalances))
+	assert.Equal(t, []string{"a", "b"}, rebalances[1].Current)
+	for i := 0; i < 20; i++ {
+		namespace := fmt.Sprintf("ns-%d", i)
+		assert.Equal(t, shardOwner([]string{"a", "b"}, namespace) == "a", OwnsNamespace(namespace))
+		assert.False(t, rebalances[1].Gained(namespace))
+	}
+
+	setShardMembers(nil)
+	assert.False(t, OwnsNamespace("ns"))
+	assert.False(t, ShardCoordinator())
+}
+
+func TestInstrumentSkipsOtherShards(t *testing.T) {
+	defer disableSharding()
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	reconciled := 0
+	r := Instrument(cf, "test", reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		reconciled++
+		return ResultNormal, nil
+	}))
+	EnableSharding("a", nil)
+	_, err := r.Reconcile(context.TODO(), ctrl.Request{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, reconciled)
+	setShardMembersAndWait(t, []string{"a"})
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, reconciled)
+}
+
+func TestShardRebalanceSource(t *testing.T) {
+	defer disableSharding()
+	scheme := runtime.NewScheme()
+	require.Nil(t, v1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "subnet1"}},
+		&v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "subnet2"}},
+	).Build()
+	EnableSharding("a", c)
+	src := ShardRebalanceSource(&v1alpha1.SubnetList{})
+	resynced := make(chan struct{})
+	resyncStores = func() { <-resynced }
+	defer func() { resyncStores = servicecommon.ResyncStores }()
+
+	setShardMembers([]string{"a"})
+	events := src.(*source.Channel).Source
+	// the CRs are not sent until the stores are resynced
+	select {
+	case e := <-events:
+		assert.Fail(t, "CR sent before the stores are resynced", e.Object.GetName())
+	case <-time.After(100 * time.Millisecond):
+	}
+	// the namespaces gained are not owned until the stores are resynced
+	assert.False(t, OwnsNamespace("ns1"))
+	close(resynced)
+	received := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case e := <-events:
+			received[e.Object.GetName()] = true
+		case <-time.After(time.Second):
+			t.Fatal("CRs of the namespaces gained not received")
+		}
+	}
+	assert.Equal(t, map[string]bool{"subnet1": true, "subnet2": true}, received)
+	assert.True(t, OwnsNamespace("ns1"))
+
+	// no namespace is gained
+	setShardMembers([]string{"a", "b"})
+	select {
+	case e := <-events:
+		assert.Fail(t, "unexpected CR", e.Object.GetName())
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestShardMembership(t *testing.T) {
+	defer disableSharding()
+	expired := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+	seconds := int32(15)
+	holder := "b"
+	scheme := runtime.NewScheme()
+	require.Nil(t, coordinationv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "nsx", Name: shardLeasePrefix + "b", Labels: map[string]string{LabelShardMember: "b"}},
+			Spec:       coordinationv1.LeaseSpec{HolderIdentity: &holder, LeaseDurationSeconds: &seconds, RenewTime: &expired},
+		},
+	).Build()
+	EnableSharding("a", nil)
+	m := &ShardMembership{Client: c, Reader: c, Namespace: "nsx", Identity: "a", LeaseDuration: 15 * time.Second, RetryPeriod: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Start(ctx) }()
+
+	assert.Eventually(t, func() bool { return OwnsNamespace("ns") }, time.Second, 10*time.Millisecond)
+	shards.RLock()
+	assert.Equal(t, []string{"a"}, shards.members)
+	shards.RUnlock()
+	lease := &coordinationv1.Lease{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "nsx", Name: shardLeasePrefix + "a"}, lease))
+	assert.Equal(t, "a", lease.Labels[LabelShardMember])
+
+	// b renews its Lease and joins
+	renewed := &coordinationv1.Lease{}
+	assert.Nil(t, c.Get(ctx, client.ObjectKey{Namespace: "nsx", Name: shardLeasePrefix + "b"}, renewed))
+	now := metav1.NewMicroTime(time.Now())
+	renewed.Spec.RenewTime = &now
+	assert.Nil(t, c.Update(ctx, renewed))
+	assert.Eventually(t, func() bool {
+		shards.RLock()
+		defer shards.RUnlock()
+		return len(shards.members) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.Nil(t, <-done)
+	assert.False(t, OwnsNamespace("ns"))
+	err := c.Get(context.TODO(), client.ObjectKey{Namespace: "nsx", Name: shardLeasePrefix + "a"}, lease)
+	assert.True(t, client.IgnoreNotFound(err) == nil && err != nil)
+	assert.False(t, m.NeedLeaderElection())
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// ShutdownSnapshotTimeout is the time given to write the snapshots of the stores after the grace period of the
+// shutdown, the manager waits for the grace period and it before it returns.
+const ShutdownSnapshotTimeout = 10 * time.Second
+
+// shuttingDown is set once the manager stops, the instrumented controllers then skip the reconciles which are not
+// started yet, they are reconciled again when NSX Operator starts.
+var shuttingDown atomic.Bool
+
+// GracefulShutdown drains NSX Operator once the manager stops, it is added to the manager. The reconciles in flight
+// and the NSX writes queued are given GracePeriod to complete instead of being cut mid-PATCH, then the snapshots of the
+// stores are written.
+type GracefulShutdown struct {
+	GracePeriod time.Duration
+}
+
+// Start waits until ctx is done and drains NSX Operator.
+func (s GracefulShutdown) Start(ctx context.Context) error {
+	<-ctx.Done()
+	shuttingDown.Store(true)
+	logger.Log.Info("draining reconciles and NSX writes in flight", "gracePeriod", s.GracePeriod)
+	drainCtx, cancel := context.WithTimeout(context.Background(), s.GracePeriod)
+	defer cancel()
+	if err := waitForReconciles(drainCtx); err != nil {
+		logger.Log.Error(err, "reconciles not completed in the grace period", "reconciles", InflightReconciles())
+	}
+	if err := servicecommon.DrainWriteQueues(drainCtx); err != nil {
+		logger.Log.Error(err, "NSX writes not sent in the grace period")
+	}
+	servicecommon.SaveStoreSnapshots()
+	logger.Log.Info("drained reconciles and NSX writes")
+	return nil
+}
+
+// NeedLeaderElection returns false, all the replicas are drained.
+func (GracefulShutdown) NeedLeaderElection() bool {
+	return false
+}
+
+// waitForReconciles waits until the reconciles in flight return or ctx is done.
+func waitForReconciles(ctx context.Context) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		inflightReconciles.Lock()
+		inflight := len(inflightReconciles.reconciles)
+		inflightReconciles.Unlock()
+		if inflight == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d reconciles are in flight: %w", inflight, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	defer shuttingDown.Store(false)
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	reconciled := 0
+	r := Instrument(cf, "test", reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		reconciled++
+		return ResultNormal, nil
+	}))
+	id := startReconcile("test", ctrl.Request{}, time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	s := GracefulShutdown{GracePeriod: time.Second}
+	go func() { done <- s.Start(ctx) }()
+	cancel()
+	// the reconcile in flight is waited for
+	select {
+	case <-done:
+		t.Fatal("shutdown didn't wait for the reconcile in flight")
+	case <-time.After(200 * time.Millisecond):
+	}
+	// the reconciles not started yet are skipped
+	_, err := r.Reconcile(context.TODO(), ctrl.Request{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, reconciled)
+
+	finishReconcile(id)
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("shutdown not completed")
+	}
+	assert.False(t, s.NeedLeaderElection())
+}
+
+func TestWaitForReconciles(t *testing.T) {
+	id := startReconcile("test", ctrl.Request{}, time.Now())
+	defer finishReconcile(id)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, waitForReconciles(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+)
+
+// startupBackoff are the first and the longest delays between the checks of the dependencies of NSX Operator, the
+// delay doubles after each failed check.
+var startupBackoff = struct {
+	initial time.Duration
+	max     time.Duration
+}{initial: time.Second, max: 30 * time.Second}
+
+// requiredCRDs are the names of the CRDs of the CRs the controllers set up watch, e.g. subnets.nsx.vmware.com.
+var requiredCRDs = struct {
+	names sets.String
+	sync.Mutex
+}{names: sets.NewString()}
+
+// RequireCRDs registers the CRDs of the CRs of objs to be waited for by WaitForCRDs, the kinds of the other API groups
+// are served by the API server itself.
+func RequireCRDs(scheme *runtime.Scheme, objs ...client.Object) {
+	requiredCRDs.Lock()
+	defer requiredCRDs.Unlock()
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil || gvk.Group != v1alpha1.GroupVersion.Group {
+			continue
+		}
+		resource, _ := meta.UnsafeGuessKindToResource(gvk)
+		requiredCRDs.names.Insert(resource.Resource + "." + gvk.Group)
+	}
+}
+
+// WaitForCRDs waits until the CRDs registered by RequireCRDs are established, the informers of the CRs would otherwise
+// fail to start. It checks them with backoff and logs the ones not established until ctx is done.
+func WaitForCRDs(ctx context.Context, reader client.Reader) error {
+	requiredCRDs.Lock()
+	names := requiredCRDs.names.List()
+	requiredCRDs.Unlock()
+	return waitForDependency(ctx, "CRDs", func() error {
+		var missing []string
+		for _, name := range names {
+			crd := &apiextensionsv1.CustomResourceDefinition{}
+			if err := reader.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return err
+				}
+				missing = append(missing, name)
+				continue
+			}
+			if !crdEstablished(crd) {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("CRDs %v are not established", missing)
+		}
+		return nil
+	})
+}
+
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established {
+			return condition.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// WaitForNSX waits until the NSX managers of the NSX clients answer the version endpoint, the services would
+// otherwise fail to list the NSX resources into their stores. It checks them with backoff until ctx is done.
+func WaitForNSX(ctx context.Context, nsxClients ...*nsx.Client) error {
+	return waitForDependency(ctx, "NSX", func() error {
+		for _, nsxClient := range nsxClients {
+			if err := nsxClient.Capabilities().Refresh(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// waitForDependency runs check until it succeeds, the delay between the checks doubles up to startupBackoff.max. It
+// returns the error of the last check once ctx is done.
+func waitForDependency(ctx context.Context, dependency string, check func() error) error {
+	delay := startupBackoff.initial
+	for {
+		err := check()
+		if err == nil {
+			logger.Log.Info("dependency is ready", "dependency", dependency)
+			return nil
+		}
+		logger.Log.Info("waiting for dependency", "dependency", dependency, "reason", err.Error(), "retryAfter", delay)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not ready: %w", dependency, err)
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > startupBackoff.max {
+			delay = startupBackoff.max
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+)
+
+func resetRequiredCRDs() {
+	requiredCRDs.Lock()
+	defer requiredCRDs.Unlock()
+	requiredCRDs.names = sets.NewString()
+}
+
+func TestRequireCRDs(t *testing.T) {
+	defer resetRequiredCRDs()
+	scheme := runtime.NewScheme()
+	require.Nil(t, v1alpha1.AddToScheme(scheme))
+	require.Nil(t, v1.AddToScheme(scheme))
+	RequireCRDs(scheme, &v1alpha1.SecurityPolicy{}, &v1alpha1.NSXOperatorHealth{}, &v1.Service{})
+	assert.Equal(t, []string{"nsxoperatorhealths.nsx.vmware.com", "securitypolicies.nsx.vmware.com"}, requiredCRDs.names.List())
+}
+
+func TestWaitForCRDs(t *testing.T) {
+	defer resetRequiredCRDs()
+	initial := startupBackoff.initial
+	startupBackoff.initial = 10 * time.Millisecond
+	defer func() { startupBackoff.initial = initial }()
+
+	scheme := runtime.NewScheme()
+	require.Nil(t, v1alpha1.AddToScheme(scheme))
+	require.Nil(t, apiextensionsv1.AddToScheme(scheme))
+	crd := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "subnets.nsx.vmware.com"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(crd).Build()
+	RequireCRDs(scheme, &v1alpha1.Subnet{})
+
+	// the CRD is not established yet
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := WaitForCRDs(ctx, c)
+	assert.ErrorContains(t, err, "subnets.nsx.vmware.com")
+
+	crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+		{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue},
+	}
+	require.Nil(t, c.Status().Update(context.TODO(), crd))
+	assert.Nil(t, WaitForCRDs(context.Background(), c))
+}
+
+func TestWaitForDependency(t *testing.T) {
+	initial, max := startupBackoff.initial, startupBackoff.max
+	startupBackoff.initial, startupBackoff.max = time.Millisecond, 4*time.Millisecond
+	defer func() { startupBackoff.initial, startupBackoff.max = initial, max }()
+
+	checks := 0
+	err := waitForDependency(context.Background(), "test", func() error {
+		if checks++; checks < 5 {
+			return errors.New("not ready")
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, checks)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = waitForDependency(ctx, "test", func() error { return errors.New("not ready") })
+	assert.EqualError(t, err, "test not ready: not ready")
+}
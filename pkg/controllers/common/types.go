@@ -9,14 +9,36 @@ import (
 )
 
 const (
-	MetricResTypeSecurityPolicy    = "securitypolicy"
-	MetricResTypeNSXServiceAccount = "nsxserviceaccount"
+	MetricResTypeSecurityPolicy     = "securitypolicy"
+	MetricResTypeNSXServiceAccount  = "nsxserviceaccount"
+	MetricResTypeNetworkInfo        = "networkinfo"
+	MetricResTypeVPC                = "vpc"
+	MetricResTypeSubnet             = "subnet"
+	MetricResTypeSubnetSet          = "subnetset"
+	MetricResTypeSubnetPort         = "subnetport"
+	MetricResTypeIPPool             = "ippool"
+	MetricResTypeIPAllocation       = "ipaddressallocation"
+	MetricResTypeLBVIP              = "lbvip"
+	MetricResTypeStaticRoute        = "staticroute"
+	MetricResTypeRouteAdvertisement = "routeadvertisement"
+	MetricResTypePrefixList         = "prefixlist"
+	MetricResTypeRouteMap           = "routemap"
 )
 
 var (
 	ResultNormal            = ctrl.Result{}
 	ResultRequeue           = ctrl.Result{Requeue: true}
 	ResultRequeueAfter5mins = ctrl.Result{Requeue: true, RequeueAfter: 5 * time.Minute}
+	// ResultRequeueAfter10sec is used to check again the resources which NSX is still realizing.
+	ResultRequeueAfter10sec = ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}
+	// ResultRequeueAfterPaused is used when the NSX mutations are paused, the CR is checked again later.
+	ResultRequeueAfterPaused = ctrl.Result{Requeue: true, RequeueAfter: time.Minute}
+	// ResultRequeueAfterNSXUnavailable is used when no NSX manager can serve the requests, the CR is checked again
+	// after the circuit breakers of the NSX managers let the requests through again.
+	ResultRequeueAfterNSXUnavailable = ctrl.Result{Requeue: true, RequeueAfter: 2 * time.Minute}
+	// ResultRequeueAfterThrottled is used when NSX is too busy to serve the requests, the CR is checked again after
+	// the NSX API rate limits recover.
+	ResultRequeueAfterThrottled = ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}
 
 	ServiceMediator = mediator.ServiceMediator{}
 )
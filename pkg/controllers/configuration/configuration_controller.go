@@ -0,0 +1,178 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+)
+
+const (
+	// NSXOperatorConfigurationName is the name of the NSXOperatorConfiguration CR which is applied.
+	NSXOperatorConfigurationName = "nsx-operator"
+
+	ReasonConfigApplied = "ConfigApplied"
+	ReasonInvalidConfig = "InvalidConfig"
+	ReasonConfigIgnored = "ConfigIgnored"
+)
+
+var (
+	log          = logger.Log
+	ResultNormal = common.ResultNormal
+)
+
+// ApplyFunc applies the config reloaded with the options of the NSXOperatorConfiguration, it returns the options, in
+// the form of section.option, which are not applied until NSX Operator is restarted.
+type ApplyFunc func(newConfig *config.NSXOperatorConfig) []string
+
+// NSXOperatorConfigurationReconciler applies the options of the NSXOperatorConfiguration over the config file, and
+// reports in its status whether they are valid and applied.
+type NSXOperatorConfigurationReconciler struct {
+	Client client.Client
+	Scheme *apimachineryruntime.Scheme
+	Apply  ApplyFunc
+}
+
+func (r *NSXOperatorConfigurationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.NSXOperatorConfiguration{}
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to fetch NSXOperatorConfiguration", "req", req.NamespacedName)
+			return ResultNormal, err
+		}
+		if req.Name == NSXOperatorConfigurationName {
+			// the config file applies again once the CR is deleted
+			log.Info("NSXOperatorConfiguration is deleted, applying the config file", "req", req.NamespacedName)
+			_, err := r.apply(nil)
+			return ResultNormal, err
+		}
+		return ResultNormal, nil
+	}
+	if obj.Name != NSXOperatorConfigurationName {
+		log.Info("ignore NSXOperatorConfiguration", "name", obj.Name)
+		return ResultNormal, r.updateStatus(ctx, obj, v1.ConditionFalse, ReasonConfigIgnored,
+			fmt.Sprintf("only the NSXOperatorConfiguration named %s is applied", NSXOperatorConfigurationName), nil)
+	}
+
+	pending, err := r.apply(overridesOf(obj))
+	if err != nil {
+		log.Error(err, "invalid NSXOperatorConfiguration", "name", obj.Name)
+		return ResultNormal, r.updateStatus(ctx, obj, v1.ConditionFalse, ReasonInvalidConfig, err.Error(), nil)
+	}
+	message := "all the options are applied"
+	if len(pending) > 0 {
+		message = fmt.Sprintf("options %s are not applied until NSX Operator is restarted", strings.Join(pending, ", "))
+	}
+	return ResultNormal, r.updateStatus(ctx, obj, v1.ConditionTrue, ReasonConfigApplied, message, pending)
+}
+
+// LoadOverrides reads the NSXOperatorConfiguration with reader and sets its options as the config overrides, it is used
+// to load them before the controllers are started, since the cache is not available before the manager starts. It
+// returns whether any option is overridden, the invalid options are reported in the status by the controller.
+func LoadOverrides(ctx context.Context, reader client.Reader) (bool, error) {
+	obj := &v1alpha1.NSXOperatorConfiguration{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: NSXOperatorConfigurationName}, obj); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	overrides := overridesOf(obj)
+	if len(overrides) == 0 {
+		return false, nil
+	}
+	if err := config.SetOverrides(overrides); err != nil {
+		return false, err
+	}
+	if _, err := config.NewNSXOperatorConfigFromFile(); err != nil {
+		config.SetOverrides(nil)
+		return false, err
+	}
+	return true, nil
+}
+
+// apply loads the config with the overrides and applies it, the previous overrides are kept if the config is invalid.
+func (r *NSXOperatorConfigurationReconciler) apply(overrides config.Overrides) ([]string, error) {
+	previous := config.GetOverrides()
+	if err := config.SetOverrides(overrides); err != nil {
+		return nil, err
+	}
+	newConfig, err := config.NewNSXOperatorConfigFromFile()
+	if err != nil {
+		config.SetOverrides(previous)
+		return nil, err
+	}
+	return r.Apply(newConfig), nil
+}
+
+// overridesOf returns the options of the sections set by the NSXOperatorConfiguration.
+func overridesOf(obj *v1alpha1.NSXOperatorConfiguration) config.Overrides {
+	overrides := config.Overrides{}
+	for section, options := range map[string]map[string]string{
+		"DEFAULT": obj.Spec.Default,
+		"coe":     obj.Spec.Coe,
+		"nsx_v3":  obj.Spec.NSXV3,
+		"k8s":     obj.Spec.K8s,
+		"vc":      obj.Spec.VC,
+	} {
+		if len(options) > 0 {
+			overrides[section] = options
+		}
+	}
+	return overrides
+}
+
+func (r *NSXOperatorConfigurationReconciler) updateStatus(ctx context.Context, obj *v1alpha1.NSXOperatorConfiguration, status v1.ConditionStatus, reason, message string, pending []string) error {
+	condition := v1alpha1.Condition{
+		Type:    v1alpha1.Ready,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+	var conditions []v1alpha1.Condition
+	for _, existing := range obj.Status.Conditions {
+		if existing.Type != condition.Type {
+			conditions = append(conditions, existing)
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+	if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = metav1.Now()
+	}
+	obj.Status.Conditions = append(conditions, condition)
+	obj.Status.ObservedGeneration = obj.Generation
+	obj.Status.PendingRestartOptions = pending
+	if err := r.Client.Status().Update(ctx, obj); err != nil {
+		log.Error(err, "failed to update NSXOperatorConfiguration status", "name", obj.Name)
+		return err
+	}
+	return nil
+}
+
+func (r *NSXOperatorConfigurationReconciler) setupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.NSXOperatorConfiguration{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+// Start setup manager
+func (r *NSXOperatorConfigurationReconciler) Start(mgr ctrl.Manager) error {
+	return r.setupWithManager(mgr)
+}
@@ -0,0 +1,143 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package configuration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+const configFile = `[coe]
+cluster = k8scl-one
+gc_interval = 60
+
+[nsx_v3]
+nsx_api_managers = 127.0.0.1
+insecure = true
+`
+
+func setupConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nsxop.ini")
+	require.Nil(t, os.WriteFile(path, []byte(configFile), 0o600))
+	config.UpdateConfigFilePath(path)
+	t.Cleanup(func() {
+		config.UpdateConfigFilePath("")
+		config.SetOverrides(nil)
+	})
+}
+
+func TestNSXOperatorConfigurationReconciler_Reconcile(t *testing.T) {
+	setupConfigFile(t)
+	scheme := runtime.NewScheme()
+	require.Nil(t, v1alpha1.AddToScheme(scheme))
+	obj := &v1alpha1.NSXOperatorConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: NSXOperatorConfigurationName, Generation: 1},
+		Spec: v1alpha1.NSXOperatorConfigurationSpec{
+			Coe:   map[string]string{"gc_interval": "120", "cluster": "k8scl-two"},
+			NSXV3: map[string]string{"api_policy_write_rate_limit": "20"},
+		},
+	}
+	ignored := &v1alpha1.NSXOperatorConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj, ignored).Build()
+	var applied *config.NSXOperatorConfig
+	r := &NSXOperatorConfigurationReconciler{Client: c, Scheme: scheme, Apply: func(newConfig *config.NSXOperatorConfig) []string {
+		applied = newConfig
+		if newConfig.Cluster != "k8scl-one" {
+			return []string{"coe.cluster"}
+		}
+		return nil
+	}}
+	ctx := context.TODO()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: NSXOperatorConfigurationName}}
+
+	_, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	require.NotNil(t, applied)
+	assert.Equal(t, 120, applied.GCInterval)
+	assert.Equal(t, 20, applied.APIPolicyWriteRateLimit)
+	require.Nil(t, c.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, int64(1), obj.Status.ObservedGeneration)
+	assert.Equal(t, []string{"coe.cluster"}, obj.Status.PendingRestartOptions)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+	assert.Equal(t, ReasonConfigApplied, obj.Status.Conditions[0].Reason)
+	assert.Contains(t, obj.Status.Conditions[0].Message, "coe.cluster")
+
+	// the invalid options are reported and not applied
+	applied = nil
+	obj.Spec.Coe = map[string]string{"gc_interval": "-1"}
+	require.Nil(t, c.Update(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Nil(t, applied)
+	require.Nil(t, c.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+	assert.Equal(t, ReasonInvalidConfig, obj.Status.Conditions[0].Reason)
+	assert.Equal(t, "120", config.GetOverrides()["coe"]["gc_interval"])
+
+	obj.Spec.Coe = map[string]string{"gc_intervals": "120"}
+	require.Nil(t, c.Update(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	require.Nil(t, c.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, "unknown option gc_intervals of section coe", obj.Status.Conditions[0].Message)
+
+	// only the CR named nsx-operator is applied
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "other"}})
+	assert.Nil(t, err)
+	assert.Nil(t, applied)
+	require.Nil(t, c.Get(ctx, types.NamespacedName{Name: "other"}, ignored))
+	assert.Equal(t, ReasonConfigIgnored, ignored.Status.Conditions[0].Reason)
+
+	// the config file applies again once the CR is deleted
+	require.Nil(t, c.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	require.NotNil(t, applied)
+	assert.Equal(t, 60, applied.GCInterval)
+	assert.Empty(t, config.GetOverrides())
+}
+
+func TestLoadOverrides(t *testing.T) {
+	setupConfigFile(t)
+	scheme := runtime.NewScheme()
+	require.Nil(t, v1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	loaded, err := LoadOverrides(context.TODO(), c)
+	assert.Nil(t, err)
+	assert.False(t, loaded)
+
+	obj := &v1alpha1.NSXOperatorConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: NSXOperatorConfigurationName},
+		Spec:       v1alpha1.NSXOperatorConfigurationSpec{Coe: map[string]string{"gc_interval": "-1"}},
+	}
+	require.Nil(t, c.Create(context.TODO(), obj))
+	loaded, err = LoadOverrides(context.TODO(), c)
+	assert.NotNil(t, err)
+	assert.False(t, loaded)
+	assert.Empty(t, config.GetOverrides())
+
+	obj.Spec.Coe["gc_interval"] = "120"
+	require.Nil(t, c.Update(context.TODO(), obj))
+	loaded, err = LoadOverrides(context.TODO(), c)
+	assert.Nil(t, err)
+	assert.True(t, loaded)
+	cf, err := config.NewNSXOperatorConfigFromFile()
+	assert.Nil(t, err)
+	assert.Equal(t, 120, cf.GCInterval)
+}
@@ -0,0 +1,89 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package gctrigger
+
+import (
+	"context"
+	"strings"
+
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/health"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+)
+
+// AnnotationTriggerGC set on the NSXOperatorHealth CR triggers the garbage collectors of the resource types of its
+// value, separated by commas, e.g. vpc,subnet. The annotation is removed once they are triggered.
+const AnnotationTriggerGC = "nsx.vmware.com/trigger-gc"
+
+var (
+	log          = logger.Log
+	ResultNormal = common.ResultNormal
+)
+
+// GCTriggerReconciler watches the NSXOperatorHealth CR and triggers the garbage collectors it is annotated with.
+type GCTriggerReconciler struct {
+	Client client.Client
+	Scheme *apimachineryruntime.Scheme
+}
+
+func (r *GCTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if !common.ShardCoordinator() {
+		// the garbage collectors only run in the shard coordinator
+		return ResultNormal, nil
+	}
+	obj := &v1alpha1.NSXOperatorHealth{}
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			log.Error(err, "unable to fetch NSXOperatorHealth", "req", req.NamespacedName)
+		}
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	value, ok := obj.Annotations[AnnotationTriggerGC]
+	if !ok {
+		return ResultNormal, nil
+	}
+
+	for _, resourceType := range strings.Split(value, ",") {
+		resourceType = strings.TrimSpace(resourceType)
+		if resourceType == "" {
+			continue
+		}
+		if err := common.TriggerGarbageCollection(resourceType); err != nil {
+			log.Error(err, "failed to trigger garbage collection", "req", req.NamespacedName, "resourceType", resourceType)
+			continue
+		}
+		log.Info("garbage collection triggered by annotation", "req", req.NamespacedName, "resourceType", resourceType)
+	}
+
+	// Remove the annotation, so that the garbage collectors are triggered again when it is set again.
+	patch := client.MergeFrom(obj.DeepCopy())
+	delete(obj.Annotations, AnnotationTriggerGC)
+	if err := r.Client.Patch(ctx, obj, patch); err != nil {
+		log.Error(err, "failed to remove annotation", "req", req.NamespacedName, "annotation", AnnotationTriggerGC)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	return ResultNormal, nil
+}
+
+func (r *GCTriggerReconciler) setupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("gctrigger").
+		For(&v1alpha1.NSXOperatorHealth{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			_, ok := obj.GetAnnotations()[AnnotationTriggerGC]
+			return obj.GetName() == health.NSXOperatorHealthName && ok
+		}))).
+		Complete(r)
+}
+
+// Start setup manager
+func (r *GCTriggerReconciler) Start(mgr ctrl.Manager) error {
+	return r.setupWithManager(mgr)
+}
@@ -0,0 +1,65 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package gctrigger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/health"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestGCTriggerReconciler_Reconcile(t *testing.T) {
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	runs := make(chan struct{}, 1)
+	gc := common.NewGarbageCollector("gctrigger-test", time.Hour, cf, func(ctx context.Context, run *servicecommon.GCRun) error {
+		runs <- struct{}{}
+		return nil
+	})
+	common.RegisterGarbageCollector(gc)
+	stop := make(chan bool)
+	defer close(stop)
+	go gc.Run(stop)
+
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	obj := &v1alpha1.NSXOperatorHealth{ObjectMeta: metav1.ObjectMeta{
+		Name:        health.NSXOperatorHealthName,
+		Annotations: map[string]string{AnnotationTriggerGC: "gctrigger-test, unknown"},
+	}}
+	r := &GCTriggerReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj).Build(), Scheme: scheme}
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Name: health.NSXOperatorHealthName}}
+
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("expected the garbage collector to run once triggered")
+	}
+	// the annotation is removed once the garbage collectors are triggered
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	_, ok := obj.Annotations[AnnotationTriggerGC]
+	assert.False(t, ok)
+
+	// the NSXOperatorHealth CR is deleted
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	result, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+}
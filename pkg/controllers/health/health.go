@@ -0,0 +1,174 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+const (
+	// NSXOperatorHealthName is the name of the NSXOperatorHealth CR the health of NSX Operator is reported in.
+	NSXOperatorHealthName = "nsx-operator"
+	// NSXOrphanReportName is the name of the NSXOrphanReport CR the NSX resources orphaned are listed in.
+	NSXOrphanReportName = "nsx-operator"
+
+	ReasonConfigApplied           = "ConfigApplied"
+	ReasonConfigChangesNotApplied = "ConfigChangesNotApplied"
+	ReasonGarbageCollected        = "GarbageCollected"
+	ReasonGarbageCollectionFailed = "GarbageCollectionFailed"
+)
+
+var log = logger.Log
+
+// getOrCreate returns the NSXOperatorHealth CR, it is created if it doesn't exist.
+func getOrCreate(ctx context.Context, c client.Client) (*v1alpha1.NSXOperatorHealth, error) {
+	health := &v1alpha1.NSXOperatorHealth{}
+	if err := c.Get(ctx, types.NamespacedName{Name: NSXOperatorHealthName}, health); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		health = &v1alpha1.NSXOperatorHealth{ObjectMeta: metav1.ObjectMeta{Name: NSXOperatorHealthName}}
+		if err := c.Create(ctx, health); err != nil {
+			return nil, err
+		}
+		log.Info("created NSXOperatorHealth", "name", NSXOperatorHealthName)
+	}
+	return health, nil
+}
+
+// setCondition replaces the condition of the same type in health, its last transition time is kept if its status
+// doesn't change.
+func setCondition(health *v1alpha1.NSXOperatorHealth, condition v1alpha1.Condition) {
+	var conditions []v1alpha1.Condition
+	for _, existing := range health.Status.Conditions {
+		if existing.Type != condition.Type {
+			conditions = append(conditions, existing)
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+	if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = metav1.Now()
+	}
+	health.Status.Conditions = append(conditions, condition)
+}
+
+// ReportConfigReload reports the reload of the config file in the NSXOperatorHealth CR, the RestartRequired
+// condition is True if some options changed are not applied until NSX Operator is restarted.
+func ReportConfigReload(ctx context.Context, c client.Client, pendingRestartOptions []string) error {
+	health, err := getOrCreate(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	condition := v1alpha1.Condition{
+		Type:    v1alpha1.RestartRequired,
+		Status:  v1.ConditionFalse,
+		Reason:  ReasonConfigApplied,
+		Message: "all the config changes are applied",
+	}
+	if len(pendingRestartOptions) > 0 {
+		condition.Status = v1.ConditionTrue
+		condition.Reason = ReasonConfigChangesNotApplied
+		condition.Message = fmt.Sprintf("config changes of %s are not applied until NSX Operator is restarted", strings.Join(pendingRestartOptions, ", "))
+	}
+	setCondition(health, condition)
+	health.Status.LastConfigReloadTime = metav1.Now()
+	health.Status.PendingRestartOptions = pendingRestartOptions
+	return c.Status().Update(ctx, health)
+}
+
+// ReportGarbageCollection reports the last runs of the garbage collectors in the NSXOperatorHealth CR, the
+// GarbageCollectionFailed condition is True if the last run of some of them failed.
+func ReportGarbageCollection(ctx context.Context, c client.Client, statuses []servicecommon.GCStatus) error {
+	health, err := getOrCreate(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	condition := v1alpha1.Condition{
+		Type:    v1alpha1.GarbageCollectionFailed,
+		Status:  v1.ConditionFalse,
+		Reason:  ReasonGarbageCollected,
+		Message: "the last runs of the garbage collectors succeeded",
+	}
+	var failed []string
+	collectors := make([]v1alpha1.GarbageCollectorStatus, 0, len(statuses))
+	for _, status := range statuses {
+		collector := v1alpha1.GarbageCollectorStatus{
+			ResourceType: status.ResourceType,
+			LastRunTime:  metav1.NewTime(status.LastRunTime),
+			Scanned:      status.Scanned,
+			Deleted:      status.Deleted,
+			Errors:       status.Errors,
+			LastError:    status.LastError,
+		}
+		if !status.LastSuccessTime.IsZero() {
+			collector.LastSuccessTime = metav1.NewTime(status.LastSuccessTime)
+		}
+		if status.LastError != "" {
+			failed = append(failed, status.ResourceType)
+		}
+		collectors = append(collectors, collector)
+	}
+	if len(failed) > 0 {
+		condition.Status = v1.ConditionTrue
+		condition.Reason = ReasonGarbageCollectionFailed
+		condition.Message = fmt.Sprintf("the last runs of the garbage collectors of %s failed", strings.Join(failed, ", "))
+	}
+	setCondition(health, condition)
+	health.Status.GarbageCollectors = collectors
+	return c.Status().Update(ctx, health)
+}
+
+// ReportOrphanedResources lists the NSX resources orphaned found by the last runs of the garbage collectors in the
+// NSXOrphanReport CR, it is created if it doesn't exist.
+func ReportOrphanedResources(ctx context.Context, c client.Client, orphans []servicecommon.OrphanedResource) error {
+	report := &v1alpha1.NSXOrphanReport{}
+	if err := c.Get(ctx, types.NamespacedName{Name: NSXOrphanReportName}, report); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		report = &v1alpha1.NSXOrphanReport{ObjectMeta: metav1.ObjectMeta{Name: NSXOrphanReportName}}
+		if err := c.Create(ctx, report); err != nil {
+			return err
+		}
+		log.Info("created NSXOrphanReport", "name", NSXOrphanReportName)
+	}
+
+	resources := make([]v1alpha1.NSXOrphanedResource, 0, len(orphans))
+	for _, orphan := range orphans {
+		resource := v1alpha1.NSXOrphanedResource{
+			Path:             orphan.Path,
+			Type:             orphan.ResourceType,
+			GarbageCollector: orphan.GarbageCollector,
+			OwnerUID:         orphan.OwnerUID,
+			Tags:             orphan.Tags,
+		}
+		if !orphan.CreateTime.IsZero() {
+			resource.CreationTime = metav1.NewTime(orphan.CreateTime)
+		}
+		resources = append(resources, resource)
+	}
+	report.Status = v1alpha1.NSXOrphanReportStatus{
+		LastUpdateTime: metav1.Now(),
+		Count:          len(resources),
+		Resources:      resources,
+	}
+	return c.Status().Update(ctx, report)
+}
@@ -0,0 +1,113 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestReportConfigReload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.Nil(t, v1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	ctx := context.Background()
+	key := types.NamespacedName{Name: NSXOperatorHealthName}
+
+	require.Nil(t, ReportConfigReload(ctx, c, []string{"coe.cluster", "nsx_v3.insecure"}))
+	health := &v1alpha1.NSXOperatorHealth{}
+	require.Nil(t, c.Get(ctx, key, health))
+	assert.Equal(t, []string{"coe.cluster", "nsx_v3.insecure"}, health.Status.PendingRestartOptions)
+	assert.Equal(t, 1, len(health.Status.Conditions))
+	condition := health.Status.Conditions[0]
+	assert.Equal(t, v1alpha1.RestartRequired, condition.Type)
+	assert.Equal(t, v1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonConfigChangesNotApplied, condition.Reason)
+	assert.Contains(t, condition.Message, "coe.cluster, nsx_v3.insecure")
+	assert.False(t, health.Status.LastConfigReloadTime.IsZero())
+
+	// the changes are reverted in the config file
+	require.Nil(t, ReportConfigReload(ctx, c, nil))
+	require.Nil(t, c.Get(ctx, key, health))
+	assert.Empty(t, health.Status.PendingRestartOptions)
+	assert.Equal(t, 1, len(health.Status.Conditions))
+	assert.Equal(t, v1.ConditionFalse, health.Status.Conditions[0].Status)
+	assert.Equal(t, ReasonConfigApplied, health.Status.Conditions[0].Reason)
+}
+
+func TestReportGarbageCollection(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.Nil(t, v1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	ctx := context.Background()
+	key := types.NamespacedName{Name: NSXOperatorHealthName}
+	now := time.Now()
+
+	require.Nil(t, ReportConfigReload(ctx, c, nil))
+	require.Nil(t, ReportGarbageCollection(ctx, c, []servicecommon.GCStatus{
+		{ResourceType: "subnetport", LastRunTime: now, Scanned: 3, Deleted: 1, Errors: 1, LastError: "conflict"},
+		{ResourceType: "vpc", LastRunTime: now, LastSuccessTime: now, Scanned: 2},
+	}))
+	health := &v1alpha1.NSXOperatorHealth{}
+	require.Nil(t, c.Get(ctx, key, health))
+	assert.Equal(t, 2, len(health.Status.GarbageCollectors))
+	assert.Equal(t, "conflict", health.Status.GarbageCollectors[0].LastError)
+	assert.True(t, health.Status.GarbageCollectors[0].LastSuccessTime.IsZero())
+	assert.False(t, health.Status.GarbageCollectors[1].LastSuccessTime.IsZero())
+	// the condition of the config reload is kept
+	assert.Equal(t, 2, len(health.Status.Conditions))
+	condition := health.Status.Conditions[1]
+	assert.Equal(t, v1alpha1.GarbageCollectionFailed, condition.Type)
+	assert.Equal(t, v1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonGarbageCollectionFailed, condition.Reason)
+	assert.Contains(t, condition.Message, "subnetport")
+
+	require.Nil(t, ReportGarbageCollection(ctx, c, []servicecommon.GCStatus{
+		{ResourceType: "subnetport", LastRunTime: now, LastSuccessTime: now, Scanned: 2, Deleted: 1},
+	}))
+	require.Nil(t, c.Get(ctx, key, health))
+	assert.Equal(t, v1.ConditionFalse, health.Status.Conditions[1].Status)
+	assert.Equal(t, ReasonGarbageCollected, health.Status.Conditions[1].Reason)
+}
+
+func TestReportOrphanedResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.Nil(t, v1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	ctx := context.Background()
+	key := types.NamespacedName{Name: NSXOrphanReportName}
+	created := time.UnixMilli(1700000000000)
+
+	require.Nil(t, ReportOrphanedResources(ctx, c, []servicecommon.OrphanedResource{
+		{GarbageCollector: "subnet", ResourceType: "VpcSubnet", Path: "/orgs/default/projects/p1/vpcs/vpc1/subnets/s1",
+			OwnerUID: "uid-1", CreateTime: created, Tags: []string{"nsx-op/subnet_cr_uid=uid-1"}},
+	}))
+	report := &v1alpha1.NSXOrphanReport{}
+	require.Nil(t, c.Get(ctx, key, report))
+	assert.Equal(t, 1, report.Status.Count)
+	resource := report.Status.Resources[0]
+	assert.Equal(t, "VpcSubnet", resource.Type)
+	assert.Equal(t, "subnet", resource.GarbageCollector)
+	assert.Equal(t, "uid-1", resource.OwnerUID)
+	assert.True(t, created.Equal(resource.CreationTime.Time))
+	assert.Equal(t, []string{"nsx-op/subnet_cr_uid=uid-1"}, resource.Tags)
+	assert.False(t, report.Status.LastUpdateTime.IsZero())
+
+	// the orphaned resources are deleted
+	require.Nil(t, ReportOrphanedResources(ctx, c, nil))
+	require.Nil(t, c.Get(ctx, key, report))
+	assert.Equal(t, 0, report.Status.Count)
+	assert.Empty(t, report.Status.Resources)
+}
@@ -0,0 +1,120 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ipaddressallocation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+const (
+	// IPAMDriftReportName is the name of the IPAMDriftReport CR the results of the IPAM drift audit are reported in.
+	IPAMDriftReportName = "nsx-operator-ipam-drift"
+
+	ReasonIPAMDriftDetected = "IPAMDriftDetected"
+	ReasonNoIPAMDrift       = "NoIPAMDrift"
+)
+
+var ipamDriftTypes = []v1alpha1.IPAMDriftType{v1alpha1.IPAMDriftUnknown, v1alpha1.IPAMDriftMissing}
+
+// DriftAuditor audits the NSX IP allocations of the IPAddressAllocation CRs against NSX periodically.
+// cancel is used to break the loop during UT
+func (r *IPAddressAllocationReconciler) DriftAuditor(cancel chan bool, interval time.Duration) {
+	ctx := context.Background()
+	log.Info("IPAM drift auditor started", "mode", r.Service.NSXConfig.GetIPAMDriftMode())
+	for {
+		select {
+		case <-cancel:
+			return
+		case <-time.After(interval):
+		}
+		if !common.ShardCoordinator() {
+			continue
+		}
+		if err := r.auditDrift(ctx); err != nil {
+			log.Error(err, "failed to audit IPAM drift")
+		}
+	}
+}
+
+// auditDrift publishes the drifted IP allocations as metrics and in the IPAMDriftReport CR, they are repaired in the
+// repair mode unless the NSX mutations are paused.
+func (r *IPAddressAllocationReconciler) auditDrift(ctx context.Context) error {
+	cf := r.Service.NSXConfig
+	mode := cf.GetIPAMDriftMode()
+	repair := mode == config.IPAMDriftModeRepair
+	if paused, _ := nsxutil.MutationsPaused(); paused && repair {
+		log.V(1).Info("NSX mutations are paused, skip repairing IPAM drift")
+		repair = false
+	}
+	drifts, err := r.Service.AuditAllocations(repair)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[v1alpha1.IPAMDriftType]int)
+	for _, drift := range drifts {
+		counts[drift.Type]++
+		if drift.Repaired {
+			metrics.CounterInc(cf, metrics.IPAMDriftRepairedTotal, string(drift.Type))
+		}
+	}
+	for _, driftType := range ipamDriftTypes {
+		metrics.GaugeSet(cf, metrics.IPAMDriftAllocations, float64(counts[driftType]), string(driftType))
+	}
+	log.V(1).Info("audited IPAM drift", "unknown", counts[v1alpha1.IPAMDriftUnknown], "missing", counts[v1alpha1.IPAMDriftMissing])
+	return r.updateDriftReport(ctx, mode, drifts, counts)
+}
+
+// updateDriftReport creates the IPAMDriftReport CR if it does not exist, and updates its status with the drifts.
+func (r *IPAddressAllocationReconciler) updateDriftReport(ctx context.Context, mode string, drifts []v1alpha1.IPAMDrift, counts map[v1alpha1.IPAMDriftType]int) error {
+	report := &v1alpha1.IPAMDriftReport{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: IPAMDriftReportName}, report); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		report = &v1alpha1.IPAMDriftReport{ObjectMeta: metav1.ObjectMeta{Name: IPAMDriftReportName}}
+		if err := r.Client.Create(ctx, report); err != nil {
+			return err
+		}
+		log.Info("created IPAMDriftReport", "name", IPAMDriftReportName)
+	}
+
+	condition := v1alpha1.Condition{
+		Type:    v1alpha1.IPAMDriftDetected,
+		Status:  v1.ConditionFalse,
+		Reason:  ReasonNoIPAMDrift,
+		Message: "IP allocations are in sync with NSX",
+	}
+	if len(drifts) > 0 {
+		condition.Status = v1.ConditionTrue
+		condition.Reason = ReasonIPAMDriftDetected
+		condition.Message = fmt.Sprintf("%d IP allocations unknown to NSX Operator and %d IP allocations missing from NSX",
+			counts[v1alpha1.IPAMDriftUnknown], counts[v1alpha1.IPAMDriftMissing])
+	}
+	if len(report.Status.Conditions) == 0 || report.Status.Conditions[0].Status != condition.Status {
+		condition.LastTransitionTime = metav1.Now()
+	} else {
+		condition.LastTransitionTime = report.Status.Conditions[0].LastTransitionTime
+	}
+	report.Status = v1alpha1.IPAMDriftReportStatus{
+		Conditions:    []v1alpha1.Condition{condition},
+		LastAuditTime: metav1.Now(),
+		Mode:          mode,
+		Drifts:        drifts,
+	}
+	return r.Client.Status().Update(ctx, report)
+}
@@ -0,0 +1,60 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ipaddressallocation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestIPAddressAllocationReconciler_auditDrift(t *testing.T) {
+	r, allocationClient := newFakeIPAddressAllocationReconciler(t)
+	ctx := context.Background()
+	poolPath := "/orgs/default/projects/p1/infra/ip-pools/ippool_pool-uid1"
+	allocationClient.allocate = true
+
+	// no drift
+	require.Nil(t, r.auditDrift(ctx))
+	report := &v1alpha1.IPAMDriftReport{}
+	require.Nil(t, r.Client.Get(ctx, types.NamespacedName{Name: IPAMDriftReportName}, report))
+	assert.Equal(t, config.IPAMDriftModeReport, report.Status.Mode)
+	assert.Empty(t, report.Status.Drifts)
+	assert.Equal(t, v1.ConditionFalse, report.Status.Conditions[0].Status)
+
+	// the allocation known to the store is removed from NSX
+	_, _, err := r.Service.CreateOrUpdateIPAddressAllocation(&v1alpha1.IPAddressAllocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "alloc1", Namespace: "ns1", UID: "uid1"},
+	}, &model.Vpc{Path: servicecommon.String("/orgs/default/projects/p1/vpcs/vpc1")}, poolPath)
+	require.Nil(t, err)
+	delete(allocationClient.allocations, "ipalloc_uid1_0")
+
+	require.Nil(t, r.auditDrift(ctx))
+	require.Nil(t, r.Client.Get(ctx, types.NamespacedName{Name: IPAMDriftReportName}, report))
+	assert.Equal(t, []v1alpha1.IPAMDrift{{
+		Type:     v1alpha1.IPAMDriftMissing,
+		Path:     poolPath + "/ip-allocations/ipalloc_uid1_0",
+		IP:       "172.16.0.1",
+		OwnerUID: "uid1",
+	}}, report.Status.Drifts)
+	assert.Equal(t, v1.ConditionTrue, report.Status.Conditions[0].Status)
+	assert.Equal(t, ReasonIPAMDriftDetected, report.Status.Conditions[0].Reason)
+
+	// the missing allocation is allocated again in the repair mode
+	r.Service.NSXConfig.IPAMDriftMode = config.IPAMDriftModeRepair
+	require.Nil(t, r.auditDrift(ctx))
+	require.Nil(t, r.Client.Get(ctx, types.NamespacedName{Name: IPAMDriftReportName}, report))
+	assert.True(t, report.Status.Drifts[0].Repaired)
+	assert.Equal(t, "172.16.0.1", *allocationClient.allocations["ipalloc_uid1_0"].AllocationIp)
+}
@@ -0,0 +1,362 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ipaddressallocation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipaddressallocation"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ippool"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	ResultRequeueAfter10sec          = common.ResultRequeueAfter10sec
+	MetricResType                    = common.MetricResTypeIPAllocation
+)
+
+// IPAddressAllocationReconciler allocates the IPs for an IPAddressAllocation CR from the NSX IP pool of an IPAddressAllocation CR,
+// or from the external IP blocks of the NSX VPC of its Namespace.
+type IPAddressAllocationReconciler struct {
+	Client        client.Client
+	Scheme        *apimachineryruntime.Scheme
+	Service       *ipaddressallocation.IPAddressAllocationService
+	IPPoolService *ippool.IPPoolService
+	Recorder      record.EventRecorder
+}
+
+func updateFail(r *IPAddressAllocationReconciler, c *context.Context, o *v1alpha1.IPAddressAllocation, e *error) {
+	r.setIPAddressAllocationReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while processing the IPAddressAllocation CR. Error: %v", *e), o.Status.IPAddresses,
+		common.ReportIPExhaustion(r.Recorder, r.Service.NSXConfig, o, *e)...)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+}
+
+func deleteFail(r *IPAddressAllocationReconciler, c *context.Context, o *v1alpha1.IPAddressAllocation, e *error) {
+	r.setIPAddressAllocationReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while deleting the IPAddressAllocation CR. Error: %v", *e), o.Status.IPAddresses)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+}
+
+func updateSuccess(r *IPAddressAllocationReconciler, c *context.Context, o *v1alpha1.IPAddressAllocation, ipAddresses []string) {
+	r.setIPAddressAllocationReadyStatusTrue(c, o, ipAddresses)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+}
+
+func deleteSuccess(r *IPAddressAllocationReconciler, _ *context.Context, _ *v1alpha1.IPAddressAllocation) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+}
+
+func (r *IPAddressAllocationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.IPAddressAllocation{}
+	log.Info("reconciling IPAddressAllocation CR", "ipaddressallocation", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch IPAddressAllocation CR", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "ipaddressallocation", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "ipaddressallocation", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.IPAllocationFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.IPAllocationFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "ipaddressallocation", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on IPAddressAllocation CR", "ipaddressallocation", req.NamespacedName)
+		}
+
+		vpcs := common.ServiceMediator.GetVPCsByNamespace(req.Namespace)
+		if len(vpcs) == 0 {
+			err := fmt.Errorf("no NSX VPC found in Namespace %s", req.Namespace)
+			log.Error(err, "failed to find VPC for IPAddressAllocation CR, would retry exponentially", "ipaddressallocation", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return ResultRequeue, err
+		}
+
+		ipPoolPath := ""
+		if obj.Spec.IPPoolName != "" {
+			ipPool := &v1alpha1.IPPool{}
+			if err := r.Client.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: obj.Spec.IPPoolName}, ipPool); err != nil {
+				log.Error(err, "failed to get IPPool, would retry exponentially", "ipaddressallocation", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			if ipPoolPath = r.IPPoolService.GetIPPoolPath(ipPool.UID); ipPoolPath == "" {
+				log.Info("NSX IPPool is not created yet, would check again", "ipaddressallocation", req.NamespacedName, "ippool", ipPool.Name)
+				r.setIPAddressAllocationReadyStatusFalse(&ctx, obj, common.ReasonNotRealized, fmt.Sprintf("NSX IPPool %s is not created yet", ipPool.Name), obj.Status.IPAddresses)
+				return ResultRequeueAfter10sec, nil
+			}
+		}
+
+		ipAddresses, allocated, err := r.Service.CreateOrUpdateIPAddressAllocation(obj, &vpcs[0], ipPoolPath)
+		if err != nil {
+			if errors.As(err, &nsxutil.RestrictionError{}) {
+				log.Error(err, err.Error(), "ipaddressallocation", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultNormal, nil
+			}
+			log.Error(err, "operate failed, would retry exponentially", "ipaddressallocation", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return common.ResultForError(err)
+		}
+		if !allocated {
+			log.Info("NSX IPs are not allocated yet, would check again", "ipaddressallocation", req.NamespacedName)
+			r.setIPAddressAllocationReadyStatusFalse(&ctx, obj, common.ReasonNotRealized, "NSX IPs are not allocated yet", ipAddresses)
+			return ResultRequeueAfter10sec, nil
+		}
+		updateSuccess(r, &ctx, obj, ipAddresses)
+	} else {
+		if controllerutil.ContainsFinalizer(obj, servicecommon.IPAllocationFinalizerName) {
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+			if err := r.Service.DeleteIPAddressAllocationByCRUID(obj.UID); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "ipaddressallocation", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return common.ResultForError(err)
+			}
+			controllerutil.RemoveFinalizer(obj, servicecommon.IPAllocationFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "ipaddressallocation", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("removed finalizer", "ipaddressallocation", req.NamespacedName)
+			deleteSuccess(r, &ctx, obj)
+		} else {
+			// only print a message because it's not a normal case
+			log.Info("finalizers cannot be recognized", "ipaddressallocation", req.NamespacedName)
+		}
+	}
+
+	return ResultNormal, nil
+}
+
+func (r *IPAddressAllocationReconciler) setIPAddressAllocationReadyStatusTrue(ctx *context.Context, obj *v1alpha1.IPAddressAllocation, ipAddresses []string) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionTrue,
+			Message: "NSX IPs have been successfully allocated",
+			Reason:  "NSX API returned 200 response code for PATCH",
+		},
+	}
+	newConditions = append(newConditions, common.ClearIPExhaustion(obj.Status.Conditions)...)
+	r.updateIPAddressAllocationStatus(ctx, obj, newConditions, ipAddresses)
+}
+
+func (r *IPAddressAllocationReconciler) setIPAddressAllocationReadyStatusFalse(ctx *context.Context, obj *v1alpha1.IPAddressAllocation, reason, message string, ipAddresses []string, conditions ...v1alpha1.Condition) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionFalse,
+			Message: message,
+			Reason:  reason,
+		},
+	}
+	newConditions = append(newConditions, conditions...)
+	r.updateIPAddressAllocationStatus(ctx, obj, newConditions, ipAddresses)
+}
+
+func (r *IPAddressAllocationReconciler) updateIPAddressAllocationStatus(ctx *context.Context, obj *v1alpha1.IPAddressAllocation, newConditions []v1alpha1.Condition, ipAddresses []string) {
+	updated := !reflect.DeepEqual(obj.Status.IPAddresses, ipAddresses)
+	obj.Status.IPAddresses = ipAddresses
+	for i := range newConditions {
+		if mergeIPAddressAllocationStatusCondition(obj, &newConditions[i]) {
+			updated = true
+		}
+	}
+	if updated {
+		r.Client.Status().Update(*ctx, obj)
+		log.V(1).Info("updated IPAddressAllocation", "Name", obj.Name, "Namespace", obj.Namespace,
+			"New Conditions", newConditions, "IPAddresses", ipAddresses)
+	}
+}
+
+func mergeIPAddressAllocationStatusCondition(obj *v1alpha1.IPAddressAllocation, newCondition *v1alpha1.Condition) bool {
+	var matchedCondition *v1alpha1.Condition
+	for i := range obj.Status.Conditions {
+		if obj.Status.Conditions[i].Type == newCondition.Type {
+			matchedCondition = &obj.Status.Conditions[i]
+			break
+		}
+	}
+
+	if reflect.DeepEqual(matchedCondition, newCondition) {
+		log.V(2).Info("conditions already match", "New Condition", newCondition, "Existing Condition", matchedCondition)
+		return false
+	}
+
+	if matchedCondition != nil {
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		matchedCondition.Status = newCondition.Status
+	} else {
+		obj.Status.Conditions = append(obj.Status.Conditions, *newCondition)
+	}
+	return true
+}
+
+func (r *IPAddressAllocationReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.IPAddressAllocation{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerIPAddressAllocation),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopeIPAllocationCRName, func() client.Object { return &v1alpha1.IPAddressAllocation{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.IPAddressAllocationList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.IPAddressAllocation{}, r)
+}
+
+// Start setup manager and launch GC and the IPAM drift auditor
+func (r *IPAddressAllocationReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(r.newGarbageCollector(servicecommon.GCInterval))
+	go common.ReportDrift(make(chan bool), servicecommon.DriftInterval, r.Service.NSXConfig, common.DriftSource{
+		ResourceType: MetricResType,
+		Client:       r.Client,
+		List:         &v1alpha1.IPAddressAllocationList{},
+		NSXUIDs:      r.Service.ListIPAddressAllocationCRUID,
+	})
+	if r.Service.NSXConfig.GetIPAMDriftMode() != config.IPAMDriftModeDisabled && config.DefaultFeatureGates.Enabled(config.FeatureIPAMDriftAudit) {
+		common.RunWhenElected(mgr.Elected(), func() { r.DriftAuditor(make(chan bool), servicecommon.IPAMDriftAuditInterval) })
+	}
+	return nil
+}
+
+// newGarbageCollector returns the garbage collector of the NSX IP allocations whose IPAddressAllocation CRs have been
+// removed. The NSX IP allocations tagged with the cluster are refreshed from NSX every IPReclamationInterval so that
+// the ones missing from the stores are reclaimed too.
+func (r *IPAddressAllocationReconciler) newGarbageCollector(interval time.Duration) *common.GarbageCollector {
+	// the stores are initialized from NSX when the service starts
+	lastRefresh := time.Now()
+	return common.NewGarbageCollector(MetricResType, interval, r.Service.NSXConfig, func(ctx context.Context, run *servicecommon.GCRun) error {
+		var nsxIPAllocationSet sets.String
+		if time.Since(lastRefresh) >= servicecommon.IPReclamationInterval {
+			var err error
+			if nsxIPAllocationSet, err = r.Service.ListNSXIPAddressAllocationCRUID(); err != nil {
+				log.Error(err, "failed to list NSX IP allocations")
+				return err
+			}
+			lastRefresh = time.Now()
+		} else {
+			nsxIPAllocationSet = r.Service.ListIPAddressAllocationCRUID()
+		}
+		run.Scanned(len(nsxIPAllocationSet))
+		if len(nsxIPAllocationSet) == 0 {
+			return nil
+		}
+		ipAllocationList := &v1alpha1.IPAddressAllocationList{}
+		if err := r.Client.List(ctx, ipAllocationList); err != nil {
+			log.Error(err, "failed to list IPAddressAllocation CR")
+			return err
+		}
+		CRIPAllocationSet := sets.NewString()
+		for _, obj := range ipAllocationList.Items {
+			CRIPAllocationSet.Insert(string(obj.UID))
+		}
+		run.Orphaned(servicecommon.TagScopeIPAllocationCRUID, nsxIPAllocationSet.Difference(CRIPAllocationSet))
+		if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxIPAllocationSet, CRIPAllocationSet); err != nil {
+			return err
+		}
+		gcSuccessCount, gcErrorCount := r.garbageCollector(nsxIPAllocationSet, ipAllocationList)
+		log.V(1).Info("gc collects IPAddressAllocation CR", "success", gcSuccessCount, "error", gcErrorCount)
+		run.Collected(gcSuccessCount, gcErrorCount)
+		return nil
+	})
+}
+
+// garbageCollector releases the NSX IP allocations of the UIDs in nsxIPAllocationSet which are not the UIDs of the
+// IPAddressAllocation CRs in ipAllocationList.
+func (r *IPAddressAllocationReconciler) garbageCollector(nsxIPAllocationSet sets.String, ipAllocationList *v1alpha1.IPAddressAllocationList) (gcSuccessCount, gcErrorCount uint32) {
+	CRIPAllocationSet := sets.NewString()
+	for _, obj := range ipAllocationList.Items {
+		CRIPAllocationSet.Insert(string(obj.UID))
+	}
+
+	for elem := range nsxIPAllocationSet {
+		if CRIPAllocationSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		log.V(1).Info("GC collected IPAddressAllocation CR", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.Service.DeleteIPAddressAllocationByCRUID(types.UID(elem)); err != nil {
+			log.Error(err, "failed to release NSX IP allocations", "UID", elem)
+			gcErrorCount++
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.IPReclamationFailTotal, MetricResType)
+		} else {
+			gcSuccessCount++
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.IPReclamationSuccessTotal, MetricResType)
+		}
+	}
+	return
+}
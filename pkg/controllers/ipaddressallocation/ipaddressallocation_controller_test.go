@@ -0,0 +1,259 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ipaddressallocation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects"
+	project_infra "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/ip_pools"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/realized_state"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipaddressallocation"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ippool"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+)
+
+type fakeQueryClient struct{}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	count := int64(0)
+	return model.SearchResponse{ResultCount: &count}, nil
+}
+
+type fakeVPCClient struct {
+	projects.VpcsClient
+}
+
+func (c *fakeVPCClient) Patch(_ string, _ string, _ string, _ model.Vpc) error {
+	return nil
+}
+
+type fakeSecurityPolicyClient struct {
+	vpcs.SecurityPoliciesClient
+}
+
+func (c *fakeSecurityPolicyClient) Patch(_ string, _ string, _ string, _ string, _ model.SecurityPolicy) error {
+	return nil
+}
+
+func (c *fakeSecurityPolicyClient) Delete(_ string, _ string, _ string, _ string) error {
+	return nil
+}
+
+type fakeIPPoolClient struct {
+	project_infra.IpPoolsClient
+}
+
+func (c *fakeIPPoolClient) Patch(_ string, _ string, _ string, _ model.IpAddressPool) error {
+	return nil
+}
+
+type fakeIPSubnetClient struct {
+	ip_pools.IpSubnetsClient
+}
+
+func (c *fakeIPSubnetClient) Patch(_ string, _ string, _ string, _ string, _ *data.StructValue) error {
+	return nil
+}
+
+type fakeRealizedEntitiesClient struct {
+	realized_state.RealizedEntitiesClient
+}
+
+func (c *fakeRealizedEntitiesClient) List(_ string, _ string, _ string, _ *string) (model.GenericPolicyRealizedResourceListResult, error) {
+	return model.GenericPolicyRealizedResourceListResult{}, nil
+}
+
+// fakeIPAllocationClient allocates the IPs in sequence once allocate is set.
+type fakeIPAllocationClient struct {
+	ip_pools.IpAllocationsClient
+	allocate    bool
+	allocations map[string]model.IpAddressAllocation
+	allocated   int
+	deleteErr   error
+}
+
+func (c *fakeIPAllocationClient) Patch(_ string, _ string, _ string, id string, allocation model.IpAddressAllocation) error {
+	c.allocations[id] = allocation
+	return nil
+}
+
+func (c *fakeIPAllocationClient) Get(_ string, _ string, _ string, id string) (model.IpAddressAllocation, error) {
+	allocation, ok := c.allocations[id]
+	if !ok {
+		return allocation, vapierrors.NotFound{}
+	}
+	if c.allocate && allocation.AllocationIp == nil {
+		c.allocated++
+		allocation.AllocationIp = servicecommon.String(fmt.Sprintf("172.16.0.%d", c.allocated))
+		c.allocations[id] = allocation
+	}
+	return allocation, nil
+}
+
+func (c *fakeIPAllocationClient) Delete(_ string, _ string, _ string, id string) error {
+	if c.deleteErr != nil {
+		return c.deleteErr
+	}
+	delete(c.allocations, id)
+	return nil
+}
+
+func newFakeIPAddressAllocationReconciler(t *testing.T, objs ...apimachineryruntime.Object) (*IPAddressAllocationReconciler, *fakeIPAllocationClient) {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{},
+	}
+	allocationClient := &fakeIPAllocationClient{allocations: map[string]model.IpAddressAllocation{}}
+	nsxClient := &nsx.Client{
+		NsxConfig:                 nsxConfig,
+		QueryClient:               &fakeQueryClient{},
+		VPCClient:                 &fakeVPCClient{},
+		VPCSecurityPolicyClient:   &fakeSecurityPolicyClient{},
+		ProjectIPPoolClient:       &fakeIPPoolClient{},
+		ProjectIPSubnetClient:     &fakeIPSubnetClient{},
+		ProjectIPAllocationClient: allocationClient,
+		RealizedEntitiesClient:    &fakeRealizedEntitiesClient{},
+	}
+	commonService := servicecommon.Service{NSXClient: nsxClient, NSXConfig: nsxConfig}
+	vpcService, err := vpc.InitializeVPC(commonService)
+	assert.Nil(t, err)
+	common.ServiceMediator.VPCService = vpcService
+	ipPoolService, err := ippool.InitializeIPPool(commonService)
+	assert.Nil(t, err)
+	service, err := ipaddressallocation.InitializeIPAddressAllocation(commonService)
+	assert.Nil(t, err)
+	return &IPAddressAllocationReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:        scheme,
+		Service:       service,
+		IPPoolService: ipPoolService,
+	}, allocationClient
+}
+
+func TestIPAddressAllocationReconciler_Reconcile(t *testing.T) {
+	ipPoolCR := &v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1", UID: "pool-uid1"},
+		Spec:       v1alpha1.IPPoolSpec{Subnets: []v1alpha1.SubnetRequest{{Name: "a", PrefixLength: 24}}},
+	}
+	allocationCR := &v1alpha1.IPAddressAllocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "alloc1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.IPAddressAllocationSpec{IPPoolName: "pool1", Count: 2},
+	}
+	r, allocationClient := newFakeIPAddressAllocationReconciler(t, ipPoolCR, allocationCR)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "alloc1"}}
+
+	nc := &v1alpha1.VPCNetworkConfiguration{Spec: v1alpha1.VPCNetworkConfigurationSpec{
+		NSXTProject:      "p1",
+		PrivateIPv4CIDRs: []string{"/orgs/default/projects/p1/infra/ip-blocks/private"},
+	}}
+	vpcCR := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "vpc-uid1"}}
+	nsxVPC, err := common.ServiceMediator.CreateOrUpdateVPC(context.TODO(), vpcCR, nc, &config.VPCConnectivityProfile{Name: vpc.ConnectivityProfilePublic})
+	assert.Nil(t, err)
+
+	// the allocation waits for the NSX IP pool
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultRequeueAfter10sec, result)
+	assert.Empty(t, allocationClient.allocations)
+	obj := &v1alpha1.IPAddressAllocation{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+
+	_, _, err = r.IPPoolService.CreateOrUpdateIPPool(ipPoolCR, nsxVPC)
+	assert.Nil(t, err)
+
+	// the status waits for the IPs to be allocated
+	result, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultRequeueAfter10sec, result)
+	assert.Equal(t, 2, len(allocationClient.allocations))
+
+	allocationClient.allocate = true
+	result, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.IPAllocationFinalizerName)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+	assert.Equal(t, []string{"172.16.0.1", "172.16.0.2"}, obj.Status.IPAddresses)
+
+	// the IPs are released with the IPAddressAllocation CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Empty(t, allocationClient.allocations)
+	err = r.Client.Get(ctx, req.NamespacedName, obj)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestIPAddressAllocationReconciler_GarbageCollector(t *testing.T) {
+	allocationCR := &v1alpha1.IPAddressAllocation{ObjectMeta: metav1.ObjectMeta{Name: "alloc1", Namespace: "ns1", UID: "uid1"}}
+	r, allocationClient := newFakeIPAddressAllocationReconciler(t, allocationCR)
+	nsxVPC := &model.Vpc{Path: servicecommon.String("/orgs/default/projects/p1/vpcs/vpc1")}
+	poolPath := "/orgs/default/projects/p1/infra/ip-pools/pool1"
+	_, _, err := r.Service.CreateOrUpdateIPAddressAllocation(allocationCR, nsxVPC, poolPath)
+	assert.Nil(t, err)
+	_, _, err = r.Service.CreateOrUpdateIPAddressAllocation(&v1alpha1.IPAddressAllocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "alloc2", Namespace: "ns1", UID: "uid2"},
+	}, nsxVPC, poolPath)
+	assert.Nil(t, err)
+
+	r.newGarbageCollector(servicecommon.GCInterval).RunOnce(context.TODO())
+	assert.Equal(t, []string{"uid1"}, r.Service.ListIPAddressAllocationCRUID().List())
+	_, ok := allocationClient.allocations["ipalloc_uid1_0"]
+	assert.True(t, ok)
+}
+
+func TestIPAddressAllocationReconciler_garbageCollector(t *testing.T) {
+	r, allocationClient := newFakeIPAddressAllocationReconciler(t)
+	nsxVPC := &model.Vpc{Path: servicecommon.String("/orgs/default/projects/p1/vpcs/vpc1")}
+	poolPath := "/orgs/default/projects/p1/infra/ip-pools/pool1"
+	for _, uid := range []types.UID{"uid1", "uid2"} {
+		_, _, err := r.Service.CreateOrUpdateIPAddressAllocation(&v1alpha1.IPAddressAllocation{
+			ObjectMeta: metav1.ObjectMeta{Name: string(uid), Namespace: "ns1", UID: uid},
+		}, nsxVPC, poolPath)
+		assert.Nil(t, err)
+	}
+	ipAllocationList := &v1alpha1.IPAddressAllocationList{Items: []v1alpha1.IPAddressAllocation{
+		{ObjectMeta: metav1.ObjectMeta{Name: "uid1", Namespace: "ns1", UID: "uid1"}},
+	}}
+
+	allocationClient.deleteErr = fmt.Errorf("connection refused")
+	gcSuccessCount, gcErrorCount := r.garbageCollector(r.Service.ListIPAddressAllocationCRUID(), ipAllocationList)
+	assert.Equal(t, uint32(0), gcSuccessCount)
+	assert.Equal(t, uint32(1), gcErrorCount)
+
+	allocationClient.deleteErr = nil
+	gcSuccessCount, gcErrorCount = r.garbageCollector(r.Service.ListIPAddressAllocationCRUID(), ipAllocationList)
+	assert.Equal(t, uint32(1), gcSuccessCount)
+	assert.Equal(t, uint32(0), gcErrorCount)
+	assert.Equal(t, []string{"uid1"}, r.Service.ListIPAddressAllocationCRUID().List())
+}
@@ -0,0 +1,310 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ippool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ippool"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	ResultRequeueAfter10sec          = common.ResultRequeueAfter10sec
+	MetricResType                    = common.MetricResTypeIPPool
+)
+
+// IPPoolReconciler creates the NSX IP pool for an IPPool CR in the Project of the NSX VPC of its Namespace.
+type IPPoolReconciler struct {
+	Client   client.Client
+	Scheme   *apimachineryruntime.Scheme
+	Service  *ippool.IPPoolService
+	Recorder record.EventRecorder
+}
+
+func updateFail(r *IPPoolReconciler, c *context.Context, o *v1alpha1.IPPool, e *error) {
+	r.setIPPoolReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while processing the IPPool CR. Error: %v", *e), o.Status.Subnets,
+		common.ReportIPExhaustion(r.Recorder, r.Service.NSXConfig, o, *e)...)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+}
+
+func deleteFail(r *IPPoolReconciler, c *context.Context, o *v1alpha1.IPPool, e *error) {
+	r.setIPPoolReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while deleting the IPPool CR. Error: %v", *e), o.Status.Subnets)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+}
+
+func updateSuccess(r *IPPoolReconciler, c *context.Context, o *v1alpha1.IPPool, subnets []v1alpha1.SubnetResult) {
+	r.setIPPoolReadyStatusTrue(c, o, subnets)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+}
+
+func deleteSuccess(r *IPPoolReconciler, _ *context.Context, _ *v1alpha1.IPPool) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+}
+
+func (r *IPPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.IPPool{}
+	log.Info("reconciling IPPool CR", "ippool", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch IPPool CR", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "ippool", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "ippool", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.IPPoolFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.IPPoolFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "ippool", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on IPPool CR", "ippool", req.NamespacedName)
+		}
+
+		vpcs := common.ServiceMediator.GetVPCsByNamespace(req.Namespace)
+		if len(vpcs) == 0 {
+			err := fmt.Errorf("no NSX VPC found in Namespace %s", req.Namespace)
+			log.Error(err, "failed to find VPC for IPPool CR, would retry exponentially", "ippool", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return ResultRequeue, err
+		}
+
+		subnets, allocated, err := r.Service.CreateOrUpdateIPPool(obj, &vpcs[0])
+		if err != nil {
+			if errors.As(err, &nsxutil.RestrictionError{}) {
+				log.Error(err, err.Error(), "ippool", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultNormal, nil
+			}
+			log.Error(err, "operate failed, would retry exponentially", "ippool", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return common.ResultForError(err)
+		}
+		if !allocated {
+			log.Info("NSX IPPool Subnets are not realized yet, would check again", "ippool", req.NamespacedName)
+			r.setIPPoolReadyStatusFalse(&ctx, obj, common.ReasonNotRealized, "NSX IPPool Subnets are not realized yet", subnets)
+			return ResultRequeueAfter10sec, nil
+		}
+		updateSuccess(r, &ctx, obj, subnets)
+	} else {
+		if controllerutil.ContainsFinalizer(obj, servicecommon.IPPoolFinalizerName) {
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+			if err := r.Service.DeleteIPPoolByCRUID(obj.UID); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "ippool", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return common.ResultForError(err)
+			}
+			controllerutil.RemoveFinalizer(obj, servicecommon.IPPoolFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "ippool", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("removed finalizer", "ippool", req.NamespacedName)
+			deleteSuccess(r, &ctx, obj)
+		} else {
+			// only print a message because it's not a normal case
+			log.Info("finalizers cannot be recognized", "ippool", req.NamespacedName)
+		}
+	}
+
+	return ResultNormal, nil
+}
+
+func (r *IPPoolReconciler) setIPPoolReadyStatusTrue(ctx *context.Context, obj *v1alpha1.IPPool, subnets []v1alpha1.SubnetResult) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionTrue,
+			Message: "NSX IPPool has been successfully created/updated",
+			Reason:  "NSX API returned 200 response code for PATCH",
+		},
+	}
+	newConditions = append(newConditions, common.ClearIPExhaustion(obj.Status.Conditions)...)
+	r.updateIPPoolStatus(ctx, obj, newConditions, subnets)
+}
+
+func (r *IPPoolReconciler) setIPPoolReadyStatusFalse(ctx *context.Context, obj *v1alpha1.IPPool, reason, message string, subnets []v1alpha1.SubnetResult, conditions ...v1alpha1.Condition) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionFalse,
+			Message: message,
+			Reason:  reason,
+		},
+	}
+	newConditions = append(newConditions, conditions...)
+	r.updateIPPoolStatus(ctx, obj, newConditions, subnets)
+}
+
+func (r *IPPoolReconciler) updateIPPoolStatus(ctx *context.Context, obj *v1alpha1.IPPool, newConditions []v1alpha1.Condition, subnets []v1alpha1.SubnetResult) {
+	updated := !reflect.DeepEqual(obj.Status.Subnets, subnets)
+	obj.Status.Subnets = subnets
+	for i := range newConditions {
+		if mergeIPPoolStatusCondition(obj, &newConditions[i]) {
+			updated = true
+		}
+	}
+	if updated {
+		r.Client.Status().Update(*ctx, obj)
+		log.V(1).Info("updated IPPool", "Name", obj.Name, "Namespace", obj.Namespace,
+			"New Conditions", newConditions, "Subnets", subnets)
+	}
+}
+
+func mergeIPPoolStatusCondition(obj *v1alpha1.IPPool, newCondition *v1alpha1.Condition) bool {
+	var matchedCondition *v1alpha1.Condition
+	for i := range obj.Status.Conditions {
+		if obj.Status.Conditions[i].Type == newCondition.Type {
+			matchedCondition = &obj.Status.Conditions[i]
+			break
+		}
+	}
+
+	if reflect.DeepEqual(matchedCondition, newCondition) {
+		log.V(2).Info("conditions already match", "New Condition", newCondition, "Existing Condition", matchedCondition)
+		return false
+	}
+
+	if matchedCondition != nil {
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		matchedCondition.Status = newCondition.Status
+	} else {
+		obj.Status.Conditions = append(obj.Status.Conditions, *newCondition)
+	}
+	return true
+}
+
+func (r *IPPoolReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.IPPool{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerIPPool),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopeIPPoolCRName, func() client.Object { return &v1alpha1.IPPool{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.IPPoolList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.IPPool{}, r)
+}
+
+// Start setup manager and launch GC
+func (r *IPPoolReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	go common.ReportDrift(make(chan bool), servicecommon.DriftInterval, r.Service.NSXConfig, common.DriftSource{
+		ResourceType: MetricResType,
+		Client:       r.Client,
+		List:         &v1alpha1.IPPoolList{},
+		NSXUIDs:      r.Service.ListIPPoolCRUID,
+	})
+	return nil
+}
+
+// collectGarbage deletes NSX IP pools whose IPPool CRs have been removed.
+func (r *IPPoolReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	nsxIPPoolSet := r.Service.ListIPPoolCRUID()
+	run.Scanned(len(nsxIPPoolSet))
+	if len(nsxIPPoolSet) == 0 {
+		return nil
+	}
+	ipPoolList := &v1alpha1.IPPoolList{}
+	if err := r.Client.List(ctx, ipPoolList); err != nil {
+		log.Error(err, "failed to list IPPool CR")
+		return err
+	}
+
+	CRIPPoolSet := sets.NewString()
+	for _, obj := range ipPoolList.Items {
+		CRIPPoolSet.Insert(string(obj.UID))
+	}
+
+	run.Orphaned(servicecommon.TagScopeIPPoolCRUID, nsxIPPoolSet.Difference(CRIPPoolSet))
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxIPPoolSet, CRIPPoolSet); err != nil {
+		return err
+	}
+
+	for elem := range nsxIPPoolSet {
+		if CRIPPoolSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		log.V(1).Info("GC collected IPPool CR", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.Service.DeleteIPPoolByCRUID(types.UID(elem)); err != nil {
+			run.Deleted(err)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			run.Deleted(nil)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+		}
+	}
+	return nil
+}
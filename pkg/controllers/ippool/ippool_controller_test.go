@@ -0,0 +1,234 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ippool
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects"
+	project_infra "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/ip_pools"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/realized_state"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ippool"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+)
+
+type fakeQueryClient struct{}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	count := int64(0)
+	return model.SearchResponse{ResultCount: &count}, nil
+}
+
+type fakeVPCClient struct {
+	projects.VpcsClient
+}
+
+func (c *fakeVPCClient) Patch(_ string, _ string, _ string, _ model.Vpc) error {
+	return nil
+}
+
+type fakeSecurityPolicyClient struct {
+	vpcs.SecurityPoliciesClient
+}
+
+func (c *fakeSecurityPolicyClient) Patch(_ string, _ string, _ string, _ string, _ model.SecurityPolicy) error {
+	return nil
+}
+
+func (c *fakeSecurityPolicyClient) Delete(_ string, _ string, _ string, _ string) error {
+	return nil
+}
+
+type fakeIPPoolClient struct {
+	project_infra.IpPoolsClient
+}
+
+func (c *fakeIPPoolClient) Patch(_ string, _ string, _ string, _ model.IpAddressPool) error {
+	return nil
+}
+
+func (c *fakeIPPoolClient) Delete(_ string, _ string, _ string) error {
+	return nil
+}
+
+type fakeIPSubnetClient struct {
+	ip_pools.IpSubnetsClient
+	subnets  map[string]bool
+	patchErr error
+}
+
+func (c *fakeIPSubnetClient) Patch(_ string, _ string, _ string, id string, _ *data.StructValue) error {
+	if c.patchErr != nil {
+		return c.patchErr
+	}
+	c.subnets[id] = true
+	return nil
+}
+
+func (c *fakeIPSubnetClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.subnets, id)
+	return nil
+}
+
+// fakeRealizedEntitiesClient realizes the Subnets once realized is set.
+type fakeRealizedEntitiesClient struct {
+	realized_state.RealizedEntitiesClient
+	realized bool
+}
+
+func (c *fakeRealizedEntitiesClient) List(_ string, _ string, path string, _ *string) (model.GenericPolicyRealizedResourceListResult, error) {
+	if !c.realized {
+		return model.GenericPolicyRealizedResourceListResult{}, nil
+	}
+	cidr := "172.16.0.0/24"
+	if strings.HasSuffix(path, "_b") {
+		cidr = "172.16.1.0/28"
+	}
+	return model.GenericPolicyRealizedResourceListResult{Results: []model.GenericPolicyRealizedResource{{
+		State:              servicecommon.String(model.GenericPolicyRealizedResource_STATE_REALIZED),
+		ExtendedAttributes: []model.AttributeVal{{Key: servicecommon.String("cidr"), Values: []string{cidr}}},
+	}}}, nil
+}
+
+func newFakeIPPoolReconciler(t *testing.T, objs ...apimachineryruntime.Object) (*IPPoolReconciler, *fakeIPSubnetClient, *fakeRealizedEntitiesClient) {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{},
+	}
+	ipSubnetClient := &fakeIPSubnetClient{subnets: map[string]bool{}}
+	realizedClient := &fakeRealizedEntitiesClient{}
+	nsxClient := &nsx.Client{
+		NsxConfig:               nsxConfig,
+		QueryClient:             &fakeQueryClient{},
+		VPCClient:               &fakeVPCClient{},
+		VPCSecurityPolicyClient: &fakeSecurityPolicyClient{},
+		ProjectIPPoolClient:     &fakeIPPoolClient{},
+		ProjectIPSubnetClient:   ipSubnetClient,
+		RealizedEntitiesClient:  realizedClient,
+	}
+	commonService := servicecommon.Service{NSXClient: nsxClient, NSXConfig: nsxConfig}
+	vpcService, err := vpc.InitializeVPC(commonService)
+	assert.Nil(t, err)
+	common.ServiceMediator.VPCService = vpcService
+	service, err := ippool.InitializeIPPool(commonService)
+	assert.Nil(t, err)
+	return &IPPoolReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:   scheme,
+		Service:  service,
+		Recorder: record.NewFakeRecorder(10),
+	}, ipSubnetClient, realizedClient
+}
+
+func TestIPPoolReconciler_Reconcile(t *testing.T) {
+	ipPoolCR := &v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.IPPoolSpec{Subnets: []v1alpha1.SubnetRequest{{Name: "a", PrefixLength: 24}, {Name: "b", PrefixLength: 28}}},
+	}
+	r, ipSubnetClient, realizedClient := newFakeIPPoolReconciler(t, ipPoolCR)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "pool1"}}
+
+	// the IPPool waits for the VPC of its Namespace
+	_, err := r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	obj := &v1alpha1.IPPool{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+
+	nc := &v1alpha1.VPCNetworkConfiguration{Spec: v1alpha1.VPCNetworkConfigurationSpec{
+		NSXTProject:      "p1",
+		PrivateIPv4CIDRs: []string{"/orgs/default/projects/p1/infra/ip-blocks/private"},
+	}}
+	vpcCR := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "vpc-uid1"}}
+	_, err = common.ServiceMediator.CreateOrUpdateVPC(context.TODO(), vpcCR, nc, &config.VPCConnectivityProfile{Name: vpc.ConnectivityProfilePublic})
+	assert.Nil(t, err)
+
+	// the IP block runs out of IPs
+	ipSubnetClient.patchErr = vapierrors.UnableToAllocateResource{}
+	_, err = r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1alpha1.IPExhausted, obj.Status.Conditions[1].Type)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[1].Status)
+	assert.Contains(t, <-r.Recorder.(*record.FakeRecorder).Events, "Warning IPExhausted no IP available in /orgs/default/projects/p1/infra/ip-blocks/private")
+	ipSubnetClient.patchErr = nil
+
+	// the status waits for the Subnets to be realized
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultRequeueAfter10sec, result)
+	assert.Equal(t, 2, len(ipSubnetClient.subnets))
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.IPPoolFinalizerName)
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+	assert.Empty(t, obj.Status.Subnets)
+
+	realizedClient.realized = true
+	result, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[1].Status)
+	assert.Equal(t, []v1alpha1.SubnetResult{{Name: "a", CIDR: "172.16.0.0/24"}, {Name: "b", CIDR: "172.16.1.0/28"}}, obj.Status.Subnets)
+
+	// the NSX IP pool is deleted with the IPPool CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ipSubnetClient.subnets))
+	assert.Empty(t, r.Service.ListIPPoolCRUID())
+	err = r.Client.Get(ctx, req.NamespacedName, obj)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestIPPoolReconciler_GarbageCollector(t *testing.T) {
+	ipPoolCR := &v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.IPPoolSpec{Subnets: []v1alpha1.SubnetRequest{{Name: "a", PrefixLength: 24}}},
+	}
+	r, ipSubnetClient, _ := newFakeIPPoolReconciler(t, ipPoolCR)
+	nsxVPC := &model.Vpc{
+		Path:              servicecommon.String("/orgs/default/projects/p1/vpcs/vpc1"),
+		PrivateIpv4Blocks: []string{"/orgs/default/projects/p1/infra/ip-blocks/private"},
+	}
+	_, _, err := r.Service.CreateOrUpdateIPPool(ipPoolCR, nsxVPC)
+	assert.Nil(t, err)
+	_, _, err = r.Service.CreateOrUpdateIPPool(&v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool2", Namespace: "ns1", UID: "uid2"},
+		Spec:       v1alpha1.IPPoolSpec{Subnets: []v1alpha1.SubnetRequest{{Name: "a", PrefixLength: 24}}},
+	}, nsxVPC)
+	assert.Nil(t, err)
+
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	assert.Equal(t, []string{"uid1"}, r.Service.ListIPPoolCRUID().List())
+	assert.True(t, ipSubnetClient.subnets["ipsubnet_uid1_a"])
+}
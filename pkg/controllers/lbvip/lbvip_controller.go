@@ -0,0 +1,273 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package lbvip
+
+import (
+	"context"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/lbvip"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	ResultRequeueAfter10sec          = common.ResultRequeueAfter10sec
+	MetricResType                    = common.MetricResTypeLBVIP
+)
+
+// LBVIPReconciler allocates the VIPs of the LoadBalancer Services from the LB VIP pools and publishes them in the
+// load balancer status of the Services.
+type LBVIPReconciler struct {
+	Client   client.Client
+	Scheme   *apimachineryruntime.Scheme
+	Service  *lbvip.LBVIPService
+	Recorder record.EventRecorder
+}
+
+func (r *LBVIPReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1.Service{}
+	log.Info("reconciling LoadBalancer Service", "service", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch Service", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "service", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "service", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() && lbvip.IsLBVIPService(obj) {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.LBVIPFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.LBVIPFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "service", req.NamespacedName)
+				metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on Service", "service", req.NamespacedName)
+		}
+
+		vip, err := r.Service.AllocateVIP(obj)
+		r.reportVIPUsage()
+		if err != nil {
+			log.Error(err, "failed to allocate VIP, would retry exponentially", "service", req.NamespacedName)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+			if conditions := common.ReportIPExhaustion(r.Recorder, r.Service.NSXConfig, obj, err); len(conditions) > 0 {
+				r.setIPExhaustedCondition(ctx, obj, &conditions[0])
+			}
+			return ResultRequeue, err
+		}
+		if vip == "" {
+			log.Info("VIP is not allocated yet, would check again", "service", req.NamespacedName)
+			return ResultRequeueAfter10sec, nil
+		}
+		if err := r.updateLoadBalancerStatus(ctx, obj, []v1.LoadBalancerIngress{{IP: vip}}); err != nil {
+			log.Error(err, "failed to update Service status, would retry exponentially", "service", req.NamespacedName)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+			return ResultRequeue, err
+		}
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+	} else if controllerutil.ContainsFinalizer(obj, servicecommon.LBVIPFinalizerName) {
+		// the Service is deleted or no longer a LoadBalancer Service
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		err := r.Service.ReleaseVIP(obj.UID)
+		r.reportVIPUsage()
+		if err != nil {
+			log.Error(err, "failed to release VIP, would retry exponentially", "service", req.NamespacedName)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+			return ResultRequeue, err
+		}
+		if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+			if err := r.updateLoadBalancerStatus(ctx, obj, nil); err != nil {
+				log.Error(err, "failed to update Service status, would retry exponentially", "service", req.NamespacedName)
+				metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+				return ResultRequeue, err
+			}
+		}
+		controllerutil.RemoveFinalizer(obj, servicecommon.LBVIPFinalizerName)
+		if err := r.Client.Update(ctx, obj); err != nil {
+			log.Error(err, "deletion failed, would retry exponentially", "service", req.NamespacedName)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+			return ResultRequeue, err
+		}
+		log.V(1).Info("removed finalizer", "service", req.NamespacedName)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+	}
+
+	return ResultNormal, nil
+}
+
+// updateLoadBalancerStatus publishes the VIP of the Service, the IPExhausted condition is removed once the VIP is
+// allocated or released.
+func (r *LBVIPReconciler) updateLoadBalancerStatus(ctx context.Context, obj *v1.Service, ingress []v1.LoadBalancerIngress) error {
+	exhausted := meta.FindStatusCondition(obj.Status.Conditions, string(v1alpha1.IPExhausted)) != nil
+	if reflect.DeepEqual(obj.Status.LoadBalancer.Ingress, ingress) && !exhausted {
+		return nil
+	}
+	obj.Status.LoadBalancer.Ingress = ingress
+	meta.RemoveStatusCondition(&obj.Status.Conditions, string(v1alpha1.IPExhausted))
+	if err := r.Client.Status().Update(ctx, obj); err != nil {
+		return err
+	}
+	log.V(1).Info("updated Service load balancer status", "Name", obj.Name, "Namespace", obj.Namespace, "Ingress", ingress)
+	return nil
+}
+
+// setIPExhaustedCondition reports that the LB VIP pools run out of IPs in the conditions of the Service.
+func (r *LBVIPReconciler) setIPExhaustedCondition(ctx context.Context, obj *v1.Service, condition *v1alpha1.Condition) {
+	meta.SetStatusCondition(&obj.Status.Conditions, metav1.Condition{
+		Type:               string(condition.Type),
+		Status:             metav1.ConditionStatus(condition.Status),
+		ObservedGeneration: obj.Generation,
+		Reason:             condition.Reason,
+		Message:            condition.Message,
+	})
+	if err := r.Client.Status().Update(ctx, obj); err != nil {
+		log.Error(err, "failed to update Service conditions", "Name", obj.Name, "Namespace", obj.Namespace)
+	}
+}
+
+// reportVIPUsage publishes the number of the VIPs allocated from each LB VIP pool as metrics.
+func (r *LBVIPReconciler) reportVIPUsage() {
+	for poolPath, count := range r.Service.CountVIPsByPool() {
+		metrics.GaugeSet(r.Service.NSXConfig, metrics.LBVIPAllocated, float64(count), poolPath)
+	}
+}
+
+// isLBVIPServiceOrFinalized filters the Services whose VIPs are allocated, or need to be released.
+func isLBVIPServiceOrFinalized(obj client.Object) bool {
+	svc, ok := obj.(*v1.Service)
+	if !ok {
+		return false
+	}
+	return lbvip.IsLBVIPService(svc) || controllerutil.ContainsFinalizer(svc, servicecommon.LBVIPFinalizerName)
+}
+
+func (r *LBVIPReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1.Service{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return isLBVIPServiceOrFinalized(e.Object)
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return isLBVIPServiceOrFinalized(e.ObjectNew)
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return isLBVIPServiceOrFinalized(e.Object)
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerLBVIP),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1.ServiceList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1.Service{}, r, predicate.NewPredicateFuncs(isLBVIPServiceOrFinalized))
+}
+
+// Start setup manager and launch GC
+func (r *LBVIPReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	return nil
+}
+
+// collectGarbage releases the VIPs whose LoadBalancer Services have been removed.
+func (r *LBVIPReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	nsxServiceSet := r.Service.ListServiceUID()
+	run.Scanned(len(nsxServiceSet))
+	if len(nsxServiceSet) == 0 {
+		return nil
+	}
+	serviceList := &v1.ServiceList{}
+	if err := r.Client.List(ctx, serviceList); err != nil {
+		log.Error(err, "failed to list Service")
+		return err
+	}
+
+	serviceSet := sets.NewString()
+	for i := range serviceList.Items {
+		if lbvip.IsLBVIPService(&serviceList.Items[i]) {
+			serviceSet.Insert(string(serviceList.Items[i].UID))
+		}
+	}
+
+	run.Orphaned(servicecommon.TagScopeServiceUID, nsxServiceSet.Difference(serviceSet))
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxServiceSet, serviceSet); err != nil {
+		return err
+	}
+
+	for elem := range nsxServiceSet {
+		if serviceSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		log.V(1).Info("GC collected LoadBalancer Service", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.Service.ReleaseVIP(types.UID(elem)); err != nil {
+			run.Deleted(err)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			run.Deleted(nil)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+		}
+	}
+	r.reportVIPUsage()
+	return nil
+}
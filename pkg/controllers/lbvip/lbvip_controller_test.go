@@ -0,0 +1,170 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package lbvip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/ip_pools"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/lbvip"
+)
+
+type fakeQueryClient struct{}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	count := int64(0)
+	return model.SearchResponse{ResultCount: &count}, nil
+}
+
+type fakeIPAllocationClient struct {
+	ip_pools.IpAllocationsClient
+	allocations map[string]model.IpAddressAllocation
+	exhausted   bool
+}
+
+func (c *fakeIPAllocationClient) Patch(_ string, _ string, _ string, id string, allocation model.IpAddressAllocation) error {
+	if c.exhausted {
+		return vapierrors.UnableToAllocateResource{}
+	}
+	allocation.AllocationIp = servicecommon.String("192.168.0.10")
+	c.allocations[id] = allocation
+	return nil
+}
+
+func (c *fakeIPAllocationClient) Get(_ string, _ string, _ string, id string) (model.IpAddressAllocation, error) {
+	return c.allocations[id], nil
+}
+
+func (c *fakeIPAllocationClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.allocations, id)
+	return nil
+}
+
+func newFakeLBVIPReconciler(t *testing.T, objs ...apimachineryruntime.Object) (*LBVIPReconciler, *fakeIPAllocationClient) {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one", LBVIPPools: []string{"/orgs/default/projects/p1/infra/ip-pools/vip"}},
+		NsxConfig: &config.NsxConfig{},
+	}
+	allocationClient := &fakeIPAllocationClient{allocations: map[string]model.IpAddressAllocation{}}
+	nsxClient := &nsx.Client{
+		NsxConfig:                 nsxConfig,
+		QueryClient:               &fakeQueryClient{},
+		ProjectIPAllocationClient: allocationClient,
+	}
+	service, err := lbvip.InitializeLBVIP(servicecommon.Service{NSXClient: nsxClient, NSXConfig: nsxConfig})
+	assert.Nil(t, err)
+	return &LBVIPReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:   scheme,
+		Service:  service,
+		Recorder: record.NewFakeRecorder(10),
+	}, allocationClient
+}
+
+func TestLBVIPReconciler_Reconcile(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+	r, allocationClient := newFakeLBVIPReconciler(t, svc)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "svc1"}}
+
+	_, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	obj := &v1.Service{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.LBVIPFinalizerName)
+	assert.Equal(t, []v1.LoadBalancerIngress{{IP: "192.168.0.10"}}, obj.Status.LoadBalancer.Ingress)
+
+	// the VIP is released when the Service is no longer a LoadBalancer Service
+	obj.Spec.Type = v1.ServiceTypeClusterIP
+	assert.Nil(t, r.Client.Update(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Empty(t, allocationClient.allocations)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.NotContains(t, obj.Finalizers, servicecommon.LBVIPFinalizerName)
+	assert.Empty(t, obj.Status.LoadBalancer.Ingress)
+
+	// the VIP is released with the Service
+	obj.Spec.Type = v1.ServiceTypeLoadBalancer
+	assert.Nil(t, r.Client.Update(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(allocationClient.allocations))
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Empty(t, allocationClient.allocations)
+	err = r.Client.Get(ctx, req.NamespacedName, obj)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestLBVIPReconciler_ReconcileExhausted(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+	r, allocationClient := newFakeLBVIPReconciler(t, svc)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "svc1"}}
+
+	// the Service is reported when the LB VIP pools run out of IPs
+	allocationClient.exhausted = true
+	_, err := r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	obj := &v1.Service{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.True(t, meta.IsStatusConditionTrue(obj.Status.Conditions, string(v1alpha1.IPExhausted)))
+	assert.Contains(t, <-r.Recorder.(*record.FakeRecorder).Events, "Warning IPExhausted no VIP available")
+
+	// the condition is removed once the VIP is allocated
+	allocationClient.exhausted = false
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Empty(t, obj.Status.Conditions)
+	assert.Equal(t, []v1.LoadBalancerIngress{{IP: "192.168.0.10"}}, obj.Status.LoadBalancer.Ingress)
+}
+
+func TestLBVIPReconciler_GarbageCollector(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+	r, allocationClient := newFakeLBVIPReconciler(t, svc)
+	_, err := r.Service.AllocateVIP(svc)
+	assert.Nil(t, err)
+	_, err = r.Service.AllocateVIP(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc2", Namespace: "ns1", UID: "uid2"}})
+	assert.Nil(t, err)
+
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	assert.Equal(t, []string{"uid1"}, r.Service.ListServiceUID().List())
+	_, ok := allocationClient.allocations["vip_uid1"]
+	assert.True(t, ok)
+}
@@ -0,0 +1,197 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package networkinfo
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+)
+
+var (
+	log           = logger.Log
+	ResultNormal  = common.ResultNormal
+	ResultRequeue = common.ResultRequeue
+	// NSX side changes of VPC are not notified, so NetworkInfo is resynced periodically.
+	ResultResync  = common.ResultRequeueAfter5mins
+	MetricResType = common.MetricResTypeNetworkInfo
+)
+
+// NetworkInfoReconciler maintains one NetworkInfo CR per Namespace, the CR is named after the Namespace
+// and reports the VPC path, default SNAT IP, gateways and DNS servers of the Namespace read from NSX.
+type NetworkInfoReconciler struct {
+	Client  client.Client
+	Scheme  *apimachineryruntime.Scheme
+	Service *vpc.VPCService
+}
+
+func (r *NetworkInfoReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Name != req.Namespace {
+		log.V(1).Info("skip networkinfo CR not named after its namespace", "networkinfo", req.NamespacedName)
+		return ResultNormal, nil
+	}
+	log.Info("reconciling networkinfo CR", "networkinfo", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	vpcList := &v1alpha1.VPCList{}
+	if err := r.Client.List(ctx, vpcList, client.InNamespace(req.Namespace)); err != nil {
+		log.Error(err, "failed to list VPC CR", "namespace", req.Namespace)
+		return ResultRequeue, err
+	}
+	if len(vpcList.Items) == 0 {
+		return r.deleteNetworkInfo(ctx, req)
+	}
+
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+	vpcStates := make([]v1alpha1.VPCState, 0, len(vpcList.Items))
+	for i := range vpcList.Items {
+		state, err := r.buildVPCState(ctx, &vpcList.Items[i])
+		if err != nil {
+			log.Error(err, "failed to read VPC from NSX", "VPC", vpcList.Items[i].Name, "namespace", req.Namespace)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+			return ResultRequeue, err
+		}
+		vpcStates = append(vpcStates, state)
+	}
+	sort.Slice(vpcStates, func(i, j int) bool {
+		return vpcStates[i].Name < vpcStates[j].Name
+	})
+
+	networkInfo := &v1alpha1.NetworkInfo{}
+	if err := r.Client.Get(ctx, req.NamespacedName, networkInfo); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to fetch networkinfo CR", "req", req.NamespacedName)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+			return ResultRequeue, err
+		}
+		networkInfo = &v1alpha1.NetworkInfo{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+			VPCs:       vpcStates,
+		}
+		if err := r.Client.Create(ctx, networkInfo); err != nil {
+			log.Error(err, "failed to create networkinfo CR", "req", req.NamespacedName)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+			return ResultRequeue, err
+		}
+		log.Info("created networkinfo CR", "req", req.NamespacedName, "vpcs", vpcStates)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+		return ResultResync, nil
+	}
+
+	if reflect.DeepEqual(networkInfo.VPCs, vpcStates) {
+		log.V(1).Info("networkinfo CR is up to date", "req", req.NamespacedName)
+		return ResultResync, nil
+	}
+	networkInfo.VPCs = vpcStates
+	if err := r.Client.Update(ctx, networkInfo); err != nil {
+		log.Error(err, "failed to update networkinfo CR", "req", req.NamespacedName)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+		return ResultRequeue, err
+	}
+	log.Info("updated networkinfo CR", "req", req.NamespacedName, "vpcs", vpcStates)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+	return ResultResync, nil
+}
+
+func (r *NetworkInfoReconciler) deleteNetworkInfo(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	networkInfo := &v1alpha1.NetworkInfo{
+		ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace},
+	}
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+	if err := r.Client.Delete(ctx, networkInfo); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ResultNormal, nil
+		}
+		log.Error(err, "failed to delete networkinfo CR", "req", req.NamespacedName)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		return ResultRequeue, err
+	}
+	log.Info("deleted networkinfo CR since no VPC in namespace", "req", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+	return ResultNormal, nil
+}
+
+// buildVPCState reads the network information of the VPC from NSX, the state only has the name
+// if the NSX VPC is not created yet.
+func (r *NetworkInfoReconciler) buildVPCState(ctx context.Context, vpcCR *v1alpha1.VPC) (v1alpha1.VPCState, error) {
+	state := v1alpha1.VPCState{Name: vpcCR.Name}
+	info, err := r.Service.GetVPCNetworkInfo(ctx, string(vpcCR.UID))
+	if err != nil {
+		return state, err
+	}
+	if info == nil {
+		log.V(1).Info("NSX VPC is not found in store", "VPC", vpcCR.Name, "namespace", vpcCR.Namespace)
+		return state, nil
+	}
+	state.VPCPath = info.Path
+	state.DefaultGatewayPath = info.DefaultGatewayPath
+	state.DefaultSNATIP = info.DefaultSNATIP
+	state.GatewayAddresses = info.GatewayAddresses
+	state.DNSServers = info.DNSServers
+	return state, nil
+}
+
+// requestForNamespace maps an object to the NetworkInfo of its Namespace.
+func requestForNamespace(obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{
+			Name:      obj.GetNamespace(),
+			Namespace: obj.GetNamespace(),
+		},
+	}}
+}
+
+// isNamedAfterNamespace filters out the NetworkInfo CRs not maintained by the controller.
+func isNamedAfterNamespace(obj client.Object) bool {
+	return obj.GetName() == obj.GetNamespace()
+}
+
+func (r *NetworkInfoReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.NetworkInfo{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client), predicate.NewPredicateFuncs(isNamedAfterNamespace))).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerNetworkInfo),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		Watches(
+			&source.Kind{Type: &v1alpha1.VPC{}},
+			handler.EnqueueRequestsFromMapFunc(requestForNamespace),
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.NetworkInfoList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.NetworkInfo{}, r, predicate.NewPredicateFuncs(isNamedAfterNamespace))
+}
+
+// Start setup manager
+func (r *NetworkInfoReconciler) Start(mgr ctrl.Manager) error {
+	return r.setupWithManager(mgr)
+}
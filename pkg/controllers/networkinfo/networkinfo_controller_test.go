@@ -0,0 +1,197 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package networkinfo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/nat"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+)
+
+type fakeQueryClient struct {
+	vpcs []model.Vpc
+}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	results := make([]*data.StructValue, 0, len(c.vpcs))
+	for _, v := range c.vpcs {
+		dataValue, _ := common.NewConverter().ConvertToVapi(v, model.VpcBindingType())
+		results = append(results, dataValue.(*data.StructValue))
+	}
+	count := int64(len(results))
+	return model.SearchResponse{Results: results, ResultCount: &count}, nil
+}
+
+type fakeVPCClient struct {
+	projects.VpcsClient
+	vpcs map[string]model.Vpc
+}
+
+func (c *fakeVPCClient) Get(_ string, _ string, vpcID string) (model.Vpc, error) {
+	return c.vpcs[vpcID], nil
+}
+
+type fakeSubnetClient struct {
+	vpcs.SubnetsClient
+	subnets map[string][]model.VpcSubnet
+}
+
+func (c *fakeSubnetClient) List(_ string, _ string, vpcID string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.VpcSubnetListResult, error) {
+	return model.VpcSubnetListResult{Results: c.subnets[vpcID]}, nil
+}
+
+type fakeNATRuleClient struct {
+	nat.NatRulesClient
+	rules map[string][]model.PolicyNatRule
+}
+
+func (c *fakeNATRuleClient) List(_ string, _ string, vpcID string, _ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.PolicyNatRuleListResult, error) {
+	return model.PolicyNatRuleListResult{Results: c.rules[vpcID]}, nil
+}
+
+func newNSXVPC(id, crUID string) model.Vpc {
+	return model.Vpc{
+		Id:   common.String(id),
+		Path: common.String("/orgs/default/projects/p1/vpcs/" + id),
+		Tags: []model.Tag{
+			{Scope: common.String(common.TagScopeCluster), Tag: common.String("k8scl-one")},
+			{Scope: common.String(common.TagScopeVPCCRUID), Tag: common.String(crUID)},
+		},
+	}
+}
+
+func newFakeNetworkInfoReconciler(t *testing.T, nsxVPCs []model.Vpc, vpcClient *fakeVPCClient, subnetClient *fakeSubnetClient,
+	natRuleClient *fakeNATRuleClient, objs ...apimachineryruntime.Object) *NetworkInfoReconciler {
+	scheme := apimachineryruntime.NewScheme()
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{},
+	}
+	service, err := vpc.InitializeVPC(common.Service{
+		NSXClient: &nsx.Client{
+			NsxConfig:       nsxConfig,
+			QueryClient:     &fakeQueryClient{vpcs: nsxVPCs},
+			VPCClient:       vpcClient,
+			VPCSubnetClient: subnetClient,
+			NATRuleClient:   natRuleClient,
+		},
+		NSXConfig: nsxConfig,
+	})
+	assert.Nil(t, err)
+	return &NetworkInfoReconciler{
+		Client:  fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:  scheme,
+		Service: service,
+	}
+}
+
+func TestNetworkInfoReconciler_Reconcile(t *testing.T) {
+	ns := "ns1"
+	vpcCR1 := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: ns, UID: "vpc1-uid"}}
+	vpcCR2 := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc2", Namespace: ns, UID: "vpc2-uid"}}
+	gatewayPath := "/infra/tier-0s/t0"
+	nsxVPC1 := newNSXVPC("vpc1-id", "vpc1-uid")
+	nsxVPC2 := newNSXVPC("vpc2-id", "vpc2-uid")
+	realizedVPC1 := newNSXVPC("vpc1-id", "vpc1-uid")
+	realizedVPC1.DefaultGatewayPath = &gatewayPath
+	realizedVPC1.DhcpConfig = &model.DhcpConfig{
+		DnsClientConfig: &model.DnsClientConfig{DnsServerIps: []string{"8.8.8.8"}},
+	}
+	vpcClient := &fakeVPCClient{vpcs: map[string]model.Vpc{"vpc1-id": realizedVPC1, "vpc2-id": nsxVPC2}}
+	subnetClient := &fakeSubnetClient{subnets: map[string][]model.VpcSubnet{
+		"vpc1-id": {{IpAddresses: []string{"172.16.0.16/28", "172.16.0.0/28"}}},
+		"vpc2-id": {{IpAddresses: []string{"172.16.1.0/28"}}},
+	}}
+	snat := model.PolicyNatRule_ACTION_SNAT
+	natRuleClient := &fakeNATRuleClient{rules: map[string][]model.PolicyNatRule{
+		"vpc1-id": {
+			{Action: common.String(model.PolicyNatRule_ACTION_DNAT), TranslatedNetwork: common.String("10.0.0.1")},
+			{Action: &snat, TranslatedNetwork: common.String("10.0.0.10")},
+		},
+	}}
+	r := newFakeNetworkInfoReconciler(t, []model.Vpc{nsxVPC1, nsxVPC2}, vpcClient, subnetClient, natRuleClient, vpcCR1, vpcCR2)
+
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: ns}}
+
+	// NetworkInfo is created, gateways are scoped to each VPC
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultResync, result)
+	networkInfo := &v1alpha1.NetworkInfo{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, networkInfo))
+	expected := []v1alpha1.VPCState{
+		{
+			Name:               "vpc1",
+			VPCPath:            "/orgs/default/projects/p1/vpcs/vpc1-id",
+			DefaultSNATIP:      "10.0.0.10",
+			DefaultGatewayPath: gatewayPath,
+			GatewayAddresses:   []string{"172.16.0.1", "172.16.0.17"},
+			DNSServers:         []string{"8.8.8.8"},
+		},
+		{
+			Name:             "vpc2",
+			VPCPath:          "/orgs/default/projects/p1/vpcs/vpc2-id",
+			GatewayAddresses: []string{"172.16.1.1"},
+		},
+	}
+	assert.Equal(t, expected, networkInfo.VPCs)
+
+	// NetworkInfo is updated after the SNAT IP is changed in NSX
+	natRuleClient.rules["vpc1-id"][1].TranslatedNetwork = common.String("10.0.0.11")
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, networkInfo))
+	assert.Equal(t, "10.0.0.11", networkInfo.VPCs[0].DefaultSNATIP)
+
+	// NetworkInfo not named after the namespace is ignored
+	other := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: "other"}}
+	result, err = r.Reconcile(ctx, other)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.True(t, apierrors.IsNotFound(r.Client.Get(ctx, other.NamespacedName, &v1alpha1.NetworkInfo{})))
+
+	// NetworkInfo is deleted after VPCs are deleted
+	assert.Nil(t, r.Client.Delete(ctx, vpcCR1))
+	assert.Nil(t, r.Client.Delete(ctx, vpcCR2))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	err = r.Client.Get(ctx, req.NamespacedName, networkInfo)
+	assert.True(t, apierrors.IsNotFound(err))
+
+	// nothing to delete
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+}
+
+func TestRequestForNamespace(t *testing.T) {
+	obj := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1"}}
+	requests := requestForNamespace(obj)
+	assert.Equal(t, 1, len(requests))
+	assert.Equal(t, types.NamespacedName{Namespace: "ns1", Name: "ns1"}, requests[0].NamespacedName)
+}
+
+func TestIsNamedAfterNamespace(t *testing.T) {
+	assert.True(t, isNamedAfterNamespace(&v1alpha1.NetworkInfo{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Namespace: "ns1"}}))
+	assert.False(t, isNamedAfterNamespace(&v1alpha1.NetworkInfo{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "ns1"}}))
+}
@@ -7,33 +7,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"runtime"
-	"time"
 
 	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	nsxvmwarecomv1alpha1 "github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
 	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
 	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
 	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
 	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/nsxserviceaccount"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
 )
 
 var (
-	log                     = logger.Log
-	ResultNormal            = common.ResultNormal
-	ResultRequeue           = common.ResultRequeue
-	ResultRequeueAfter5mins = common.ResultRequeueAfter5mins
-	MetricResType           = common.MetricResTypeNSXServiceAccount
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfter5mins          = common.ResultRequeueAfter5mins
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	MetricResType                    = common.MetricResTypeNSXServiceAccount
 )
 
 // NSXServiceAccountReconciler reconciles a NSXServiceAccount object
@@ -57,6 +61,7 @@ func (r *NSXServiceAccountReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		log.Error(err, "unable to fetch NSXServiceAccount CR", "req", req.NamespacedName)
 		return ResultNormal, client.IgnoreNotFound(err)
 	}
+	logger.CorrelateObject(ctx, obj)
 
 	// Since NSXServiceAccount service can only be activated from NSX 4.1.0 onwards,
 	// So need to check NSX version before starting NSXServiceAccount reconcile
@@ -67,6 +72,15 @@ func (r *NSXServiceAccountReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ResultRequeueAfter5mins, nil
 	}
 
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "nsxserviceaccount", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "nsxserviceaccount", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
 	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
 		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
 		if !controllerutil.ContainsFinalizer(obj, servicecommon.NSXServiceAccountFinalizerName) {
@@ -118,8 +132,8 @@ func (r *NSXServiceAccountReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 // setupWithManager sets up the controller with the Manager.
 func (r *NSXServiceAccountReconciler) setupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&nsxvmwarecomv1alpha1.NSXServiceAccount{}).
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&nsxvmwarecomv1alpha1.NSXServiceAccount{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
 		WithEventFilter(predicate.Funcs{
 			DeleteFunc: func(e event.DeleteEvent) bool {
 				// Suppress Delete events to avoid filtering them out in the Reconcile function
@@ -128,9 +142,24 @@ func (r *NSXServiceAccountReconciler) setupWithManager(mgr ctrl.Manager) error {
 		}).
 		WithOptions(
 			controller.Options{
-				MaxConcurrentReconciles: runtime.NumCPU(),
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerNSXServiceAccount),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
 			}).
-		Complete(r)
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopeNSXServiceAccountCRName, func() client.Object { return &nsxvmwarecomv1alpha1.NSXServiceAccount{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&nsxvmwarecomv1alpha1.NSXServiceAccountList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &nsxvmwarecomv1alpha1.NSXServiceAccount{}, r)
 }
 
 // Start setup manager and launch GC
@@ -140,34 +169,41 @@ func (r *NSXServiceAccountReconciler) Start(mgr ctrl.Manager) error {
 		return err
 	}
 
-	go r.GarbageCollector(make(chan bool), servicecommon.GCInterval)
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	go common.ReportDrift(make(chan bool), servicecommon.DriftInterval, r.Service.NSXConfig, common.DriftSource{
+		ResourceType: MetricResType,
+		Client:       r.Client,
+		List:         &nsxvmwarecomv1alpha1.NSXServiceAccountList{},
+		NSXUIDs:      r.Service.ListNSXServiceAccountRealization,
+	})
 	return nil
 }
 
-// GarbageCollector collect NSXServiceAccount which has been removed from crd.
-// cancel is used to break the loop during UT
-func (r *NSXServiceAccountReconciler) GarbageCollector(cancel chan bool, timeout time.Duration) {
-	ctx := context.Background()
-	log.Info("garbage collector started")
-	for {
-		select {
-		case <-cancel:
-			return
-		case <-time.After(timeout):
-		}
-		nsxServiceAccountUIDSet := r.Service.ListNSXServiceAccountRealization()
-		if len(nsxServiceAccountUIDSet) == 0 {
-			continue
-		}
-		nsxServiceAccountList := &nsxvmwarecomv1alpha1.NSXServiceAccountList{}
-		err := r.Client.List(ctx, nsxServiceAccountList)
-		if err != nil {
-			log.Error(err, "failed to list NSXServiceAccount CR")
-			continue
-		}
-		gcSuccessCount, gcErrorCount := r.garbageCollector(nsxServiceAccountUIDSet, nsxServiceAccountList)
-		log.V(1).Info("gc collects NSXServiceAccount CR", "success", gcSuccessCount, "error", gcErrorCount)
+// collectGarbage deletes the NSX resources of the NSXServiceAccounts which have been removed from crd.
+func (r *NSXServiceAccountReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	nsxServiceAccountUIDSet := r.Service.ListNSXServiceAccountRealization()
+	run.Scanned(len(nsxServiceAccountUIDSet))
+	if len(nsxServiceAccountUIDSet) == 0 {
+		return nil
 	}
+	nsxServiceAccountList := &nsxvmwarecomv1alpha1.NSXServiceAccountList{}
+	err := r.Client.List(ctx, nsxServiceAccountList)
+	if err != nil {
+		log.Error(err, "failed to list NSXServiceAccount CR")
+		return err
+	}
+	nsxServiceAccountCRUIDSet := sets.NewString()
+	for _, nsxServiceAccount := range nsxServiceAccountList.Items {
+		nsxServiceAccountCRUIDSet.Insert(string(nsxServiceAccount.UID))
+	}
+	run.Orphaned(servicecommon.TagScopeNSXServiceAccountCRUID, nsxServiceAccountUIDSet.Difference(nsxServiceAccountCRUIDSet))
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxServiceAccountUIDSet, nsxServiceAccountCRUIDSet); err != nil {
+		return err
+	}
+	gcSuccessCount, gcErrorCount := r.garbageCollector(nsxServiceAccountUIDSet, nsxServiceAccountList)
+	log.V(1).Info("gc collects NSXServiceAccount CR", "success", gcSuccessCount, "error", gcErrorCount)
+	run.Collected(gcSuccessCount, gcErrorCount)
+	return nil
 }
 
 func (r *NSXServiceAccountReconciler) garbageCollector(nsxServiceAccountUIDSet sets.String, nsxServiceAccountList *nsxvmwarecomv1alpha1.NSXServiceAccountList) (gcSuccessCount, gcErrorCount uint32) {
@@ -188,6 +224,9 @@ func (r *NSXServiceAccountReconciler) garbageCollector(nsxServiceAccountUIDSet s
 		if namespacedName.Namespace == "" || namespacedName.Name == "" {
 			continue
 		}
+		if common.GCDryRun(MetricResType, nsxServiceAccountUID) {
+			continue
+		}
 		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
 		err := r.Service.DeleteNSXServiceAccount(context.TODO(), namespacedName)
 		if err != nil {
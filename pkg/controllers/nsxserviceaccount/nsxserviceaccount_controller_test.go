@@ -24,6 +24,7 @@ import (
 
 	nsxvmwarecomv1alpha1 "github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
 	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
 	mock_client "github.com/vmware-tanzu/nsx-operator/pkg/mock/controller-runtime/client"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
 	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
@@ -465,17 +466,12 @@ func TestNSXServiceAccountReconciler_GarbageCollector(t *testing.T) {
 			}
 			r.Service.SetUpStore()
 			ctx := context.TODO()
-			cancel := make(chan bool)
 			if tt.prepareFunc != nil {
 				patches := tt.prepareFunc(t, r, ctx)
 				defer patches.Reset()
 			}
 
-			go func() {
-				time.Sleep(150 * time.Millisecond)
-				cancel <- true
-			}()
-			r.GarbageCollector(cancel, 100*time.Millisecond)
+			common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(ctx)
 		})
 	}
 }
@@ -483,7 +479,11 @@ func TestNSXServiceAccountReconciler_GarbageCollector(t *testing.T) {
 func TestNSXServiceAccountReconciler_Start(t *testing.T) {
 	mockCtl := gomock.NewController(t)
 	k8sClient := mock_client.NewMockClient(mockCtl)
-	service := &nsxserviceaccount.NSXServiceAccountService{}
+	service := &nsxserviceaccount.NSXServiceAccountService{
+		Service: servicecommon.Service{
+			NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{}},
+		},
+	}
 	r := &NSXServiceAccountReconciler{
 		Client:  k8sClient,
 		Scheme:  nil,
@@ -0,0 +1,166 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package pause
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+const (
+	// PauseConfigMapName is the name of the ConfigMap in the operator namespace which holds the pause switch.
+	PauseConfigMapName = "nsx-operator-pause"
+	// PauseKey set to "true" in the ConfigMap data requests to pause all the NSX mutations.
+	PauseKey = "paused"
+	// PauseReasonKey is an optional key in the ConfigMap data to record why the mutations are paused.
+	PauseReasonKey = "reason"
+	// AnnotationPauseConfirmedUntil confirms the pause request, the value is a RFC3339 timestamp.
+	// The pause is only effective before the timestamp, it is lifted automatically after that,
+	// so a forgotten pause won't block the NSX mutations forever.
+	AnnotationPauseConfirmedUntil = "nsx.vmware.com/pause-confirmed-until"
+	// MaxPauseDuration is the longest period a single confirmation could pause the mutations, a longer
+	// confirmation is capped and the annotation is rewritten to the capped timestamp.
+	MaxPauseDuration = 7 * 24 * time.Hour
+)
+
+var (
+	log          = logger.Log
+	ResultNormal = common.ResultNormal
+)
+
+// PauseReconciler watches the pause ConfigMap and turns on or off the global NSX mutation pause switch.
+type PauseReconciler struct {
+	Client    client.Client
+	Scheme    *apimachineryruntime.Scheme
+	NSXConfig *config.NSXOperatorConfig
+}
+
+func (r *PauseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.sync(ctx, r.Client, req.NamespacedName)
+}
+
+// LoadPauseState reads the pause ConfigMap with reader and applies it, it is used to load the pause switch
+// before the controllers mutating NSX are started, since the cache is not available before the manager starts.
+func (r *PauseReconciler) LoadPauseState(ctx context.Context, reader client.Reader) error {
+	_, err := r.sync(ctx, reader, types.NamespacedName{Namespace: r.NSXConfig.GetOperatorNamespace(), Name: PauseConfigMapName})
+	return err
+}
+
+func (r *PauseReconciler) sync(ctx context.Context, reader client.Reader, key types.NamespacedName) (ctrl.Result, error) {
+	cm := &v1.ConfigMap{}
+	if err := reader.Get(ctx, key, cm); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			log.Error(err, "unable to fetch pause ConfigMap", "req", key)
+			return ResultNormal, err
+		}
+		r.setPaused(false, "")
+		return ResultNormal, nil
+	}
+
+	now := time.Now()
+	paused, reason, until, err := evaluatePause(cm, now)
+	if err != nil {
+		log.Error(err, "ignore pause request", "req", key)
+	}
+	if confirmed, _ := time.Parse(time.RFC3339, cm.Annotations[AnnotationPauseConfirmedUntil]); paused && confirmed.After(until) {
+		// Record the capped confirmation, so that the cap is visible and the pause is not extended on every reconcile.
+		log.Info("pause confirmation exceeds the max pause duration, cap it", "req", key,
+			"confirmedUntil", cm.Annotations[AnnotationPauseConfirmedUntil], "cappedUntil", until.Format(time.RFC3339))
+		cm.Annotations[AnnotationPauseConfirmedUntil] = until.Format(time.RFC3339)
+		if err := r.Client.Update(ctx, cm); err != nil {
+			log.Error(err, "failed to cap pause confirmation", "req", key)
+			return ResultNormal, err
+		}
+	}
+	r.setPaused(paused, reason)
+	if paused {
+		// Reconcile again when the confirmation expires to resume the mutations.
+		return ctrl.Result{RequeueAfter: until.Sub(now)}, nil
+	}
+	return ResultNormal, nil
+}
+
+func (r *PauseReconciler) setPaused(paused bool, reason string) {
+	nsxutil.SetMutationsPaused(paused, reason)
+	if metrics.AreMetricsExposed(r.NSXConfig) {
+		value := 0.0
+		if paused {
+			value = 1
+		}
+		metrics.NSXOperatorMutationsPaused.Set(value)
+	}
+}
+
+// evaluatePause checks the pause request against the safety interlock. The mutations are paused only if the
+// request is confirmed by the annotation and the confirmation is not expired, it returns the time the pause
+// ends, which is capped to MaxPauseDuration from now.
+func evaluatePause(cm *v1.ConfigMap, now time.Time) (bool, string, time.Time, error) {
+	if cm.Data[PauseKey] != "true" {
+		return false, "", time.Time{}, nil
+	}
+	value, ok := cm.Annotations[AnnotationPauseConfirmedUntil]
+	if !ok {
+		return false, "", time.Time{}, fmt.Errorf("pause is not confirmed by annotation %s", AnnotationPauseConfirmedUntil)
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false, "", time.Time{}, fmt.Errorf("invalid annotation %s: %v", AnnotationPauseConfirmedUntil, err)
+	}
+	if !until.After(now) {
+		return false, "", time.Time{}, fmt.Errorf("pause confirmation expired at %s", value)
+	}
+	if until.Sub(now) > MaxPauseDuration {
+		until = now.Add(MaxPauseDuration).Truncate(time.Second)
+	}
+	reason := cm.Data[PauseReasonKey]
+	if reason == "" {
+		reason = "paused by " + PauseConfigMapName
+	}
+	return true, fmt.Sprintf("%s, until %s", reason, until.Format(time.RFC3339)), until, nil
+}
+
+// CacheSelectors restricts the ConfigMaps cached by the manager to the pause ConfigMap, the
+// controller doesn't need to list and watch all the ConfigMaps in the cluster.
+func CacheSelectors(cf *config.NSXOperatorConfig) cache.SelectorsByObject {
+	return cache.SelectorsByObject{
+		&v1.ConfigMap{}: {
+			Field: fields.SelectorFromSet(fields.Set{
+				"metadata.name":      PauseConfigMapName,
+				"metadata.namespace": cf.GetOperatorNamespace(),
+			}),
+		},
+	}
+}
+
+func (r *PauseReconciler) setupWithManager(mgr ctrl.Manager) error {
+	namespace := r.NSXConfig.GetOperatorNamespace()
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("pause").
+		For(&v1.ConfigMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetName() == PauseConfigMapName && obj.GetNamespace() == namespace
+		}))).
+		Complete(r)
+}
+
+// Start setup manager
+func (r *PauseReconciler) Start(mgr ctrl.Manager) error {
+	return r.setupWithManager(mgr)
+}
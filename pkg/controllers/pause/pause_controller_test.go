@@ -0,0 +1,131 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package pause
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func TestEvaluatePause(t *testing.T) {
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		data        map[string]string
+		annotations map[string]string
+		wantPaused  bool
+		wantUntil   time.Time
+		wantErr     bool
+	}{
+		{"not requested", map[string]string{PauseKey: "false"}, nil, false, time.Time{}, false},
+		{"not confirmed", map[string]string{PauseKey: "true"}, nil, false, time.Time{}, true},
+		{"invalid confirmation", map[string]string{PauseKey: "true"}, map[string]string{AnnotationPauseConfirmedUntil: "tomorrow"}, false, time.Time{}, true},
+		{"expired", map[string]string{PauseKey: "true"}, map[string]string{AnnotationPauseConfirmedUntil: "2022-09-30T00:00:00Z"}, false, time.Time{}, true},
+		{"capped", map[string]string{PauseKey: "true", PauseReasonKey: "change freeze"}, map[string]string{AnnotationPauseConfirmedUntil: "2022-12-01T00:00:00Z"}, true, now.Add(MaxPauseDuration), false},
+		{"paused", map[string]string{PauseKey: "true", PauseReasonKey: "change freeze"}, map[string]string{AnnotationPauseConfirmedUntil: "2022-10-01T02:00:00Z"}, true, now.Add(2 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Data:       tt.data,
+			}
+			paused, reason, until, err := evaluatePause(cm, now)
+			assert.Equal(t, tt.wantPaused, paused)
+			assert.True(t, tt.wantUntil.Equal(until))
+			assert.Equal(t, tt.wantErr, err != nil)
+			if paused {
+				assert.Contains(t, reason, "change freeze")
+			}
+		})
+	}
+}
+
+func TestPauseReconciler_Reconcile(t *testing.T) {
+	defer nsxutil.SetMutationsPaused(false, "")
+	cf := &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}, K8sConfig: &config.K8sConfig{}}
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        PauseConfigMapName,
+			Namespace:   cf.GetOperatorNamespace(),
+			Annotations: map[string]string{AnnotationPauseConfirmedUntil: time.Now().Add(time.Hour).Format(time.RFC3339)},
+		},
+		Data: map[string]string{PauseKey: "true"},
+	}
+	r := &PauseReconciler{
+		Client:    fake.NewClientBuilder().WithObjects(cm).Build(),
+		NSXConfig: cf,
+	}
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}}
+
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.True(t, result.RequeueAfter > 0)
+	paused, _ := nsxutil.MutationsPaused()
+	assert.True(t, paused)
+
+	// resume after the ConfigMap is deleted
+	assert.Nil(t, r.Client.Delete(ctx, cm))
+	result, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	paused, _ = nsxutil.MutationsPaused()
+	assert.False(t, paused)
+}
+
+func TestPauseReconciler_CapConfirmation(t *testing.T) {
+	defer nsxutil.SetMutationsPaused(false, "")
+	cf := &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}, K8sConfig: &config.K8sConfig{}}
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        PauseConfigMapName,
+			Namespace:   cf.GetOperatorNamespace(),
+			Annotations: map[string]string{AnnotationPauseConfirmedUntil: time.Now().Add(30 * 24 * time.Hour).Format(time.RFC3339)},
+		},
+		Data: map[string]string{PauseKey: "true"},
+	}
+	c := fake.NewClientBuilder().WithObjects(cm).Build()
+	r := &PauseReconciler{Client: c, NSXConfig: cf}
+	ctx := context.Background()
+
+	// the pause state is loaded with the reader before the manager starts
+	assert.Nil(t, r.LoadPauseState(ctx, c))
+	paused, _ := nsxutil.MutationsPaused()
+	assert.True(t, paused)
+
+	// the confirmation is capped in the ConfigMap, and the cap is not extended by later reconciles
+	updated := &v1.ConfigMap{}
+	assert.Nil(t, c.Get(ctx, types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}, updated))
+	until, err := time.Parse(time.RFC3339, updated.Annotations[AnnotationPauseConfirmedUntil])
+	assert.Nil(t, err)
+	assert.False(t, until.After(time.Now().Add(MaxPauseDuration)))
+	result, err := r.Reconcile(ctx, controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}})
+	assert.Nil(t, err)
+	assert.True(t, result.RequeueAfter <= MaxPauseDuration)
+	assert.Nil(t, c.Get(ctx, types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}, updated))
+	assert.Equal(t, until.Format(time.RFC3339), updated.Annotations[AnnotationPauseConfirmedUntil])
+}
+
+func TestCacheSelectors(t *testing.T) {
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{OperatorNamespace: "nsx"}}
+	selectors := CacheSelectors(cf)
+	assert.Equal(t, 1, len(selectors))
+	for _, selector := range selectors {
+		assert.True(t, selector.Field.Matches(fields.Set{"metadata.name": PauseConfigMapName, "metadata.namespace": "nsx"}))
+		assert.False(t, selector.Field.Matches(fields.Set{"metadata.name": PauseConfigMapName, "metadata.namespace": "default"}))
+	}
+}
@@ -0,0 +1,291 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package prefixlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/routemap"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	MetricResType                    = common.MetricResTypePrefixList
+)
+
+// PrefixListReconciler creates the NSX prefix lists of the PrefixList CRs on the Tier-0 gateways.
+type PrefixListReconciler struct {
+	Client  client.Client
+	Scheme  *apimachineryruntime.Scheme
+	Service *routemap.RouteMapService
+}
+
+func updateFail(r *PrefixListReconciler, c *context.Context, o *v1alpha1.PrefixList, e *error) {
+	r.setPrefixListReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while processing the PrefixList CR. Error: %v", *e))
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+}
+
+func deleteFail(r *PrefixListReconciler, c *context.Context, o *v1alpha1.PrefixList, e *error) {
+	r.setPrefixListReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while deleting the PrefixList CR. Error: %v", *e))
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+}
+
+func updateSuccess(r *PrefixListReconciler, c *context.Context, o *v1alpha1.PrefixList, path string) {
+	r.setPrefixListReadyStatusTrue(c, o, path)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+}
+
+func deleteSuccess(r *PrefixListReconciler, _ *context.Context, _ *v1alpha1.PrefixList) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+}
+
+func (r *PrefixListReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.PrefixList{}
+	log.Info("reconciling PrefixList CR", "prefixlist", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch PrefixList CR", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "prefixlist", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "prefixlist", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.PrefixListFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.PrefixListFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "prefixlist", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on PrefixList CR", "prefixlist", req.NamespacedName)
+		}
+
+		path, err := r.Service.CreateOrUpdatePrefixList(obj)
+		if err != nil {
+			if errors.As(err, &nsxutil.RestrictionError{}) {
+				log.Error(err, err.Error(), "prefixlist", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultNormal, nil
+			}
+			log.Error(err, "operate failed, would retry exponentially", "prefixlist", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return common.ResultForError(err)
+		}
+		updateSuccess(r, &ctx, obj, path)
+	} else {
+		if controllerutil.ContainsFinalizer(obj, servicecommon.PrefixListFinalizerName) {
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+			// NSX rejects the deletion until the RouteMaps matching the prefix list are updated
+			if err := r.Service.DeletePrefixListByCRUID(obj.UID); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "prefixlist", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return common.ResultForError(err)
+			}
+			controllerutil.RemoveFinalizer(obj, servicecommon.PrefixListFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "prefixlist", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("removed finalizer", "prefixlist", req.NamespacedName)
+			deleteSuccess(r, &ctx, obj)
+		} else {
+			// only print a message because it's not a normal case
+			log.Info("finalizers cannot be recognized", "prefixlist", req.NamespacedName)
+		}
+	}
+
+	return ResultNormal, nil
+}
+
+func (r *PrefixListReconciler) setPrefixListReadyStatusTrue(ctx *context.Context, obj *v1alpha1.PrefixList, path string) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionTrue,
+			Message: "NSX prefix list has been successfully created/updated",
+			Reason:  "NSX API returned 200 response code for PATCH",
+		},
+	}
+	r.updatePrefixListStatus(ctx, obj, newConditions, path)
+}
+
+func (r *PrefixListReconciler) setPrefixListReadyStatusFalse(ctx *context.Context, obj *v1alpha1.PrefixList, reason, message string) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionFalse,
+			Message: message,
+			Reason:  reason,
+		},
+	}
+	r.updatePrefixListStatus(ctx, obj, newConditions, obj.Status.Path)
+}
+
+func (r *PrefixListReconciler) updatePrefixListStatus(ctx *context.Context, obj *v1alpha1.PrefixList, newConditions []v1alpha1.Condition, path string) {
+	updated := obj.Status.Path != path
+	obj.Status.Path = path
+	for i := range newConditions {
+		if mergePrefixListStatusCondition(obj, &newConditions[i]) {
+			updated = true
+		}
+	}
+	if updated {
+		r.Client.Status().Update(*ctx, obj)
+		log.V(1).Info("updated PrefixList", "Name", obj.Name, "New Conditions", newConditions, "Path", path)
+	}
+}
+
+func mergePrefixListStatusCondition(obj *v1alpha1.PrefixList, newCondition *v1alpha1.Condition) bool {
+	var matchedCondition *v1alpha1.Condition
+	for i := range obj.Status.Conditions {
+		if obj.Status.Conditions[i].Type == newCondition.Type {
+			matchedCondition = &obj.Status.Conditions[i]
+			break
+		}
+	}
+
+	if reflect.DeepEqual(matchedCondition, newCondition) {
+		log.V(2).Info("conditions already match", "New Condition", newCondition, "Existing Condition", matchedCondition)
+		return false
+	}
+
+	if matchedCondition != nil {
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		matchedCondition.Status = newCondition.Status
+	} else {
+		obj.Status.Conditions = append(obj.Status.Conditions, *newCondition)
+	}
+	return true
+}
+
+func (r *PrefixListReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.PrefixList{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerPrefixList),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopePrefixListCRName, func() client.Object { return &v1alpha1.PrefixList{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.PrefixListList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.PrefixList{}, r)
+}
+
+// Start setup manager and launch GC
+func (r *PrefixListReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	go common.ReportDrift(make(chan bool), servicecommon.DriftInterval, r.Service.NSXConfig, common.DriftSource{
+		ResourceType: MetricResType,
+		Client:       r.Client,
+		List:         &v1alpha1.PrefixListList{},
+		NSXUIDs:      r.Service.ListPrefixListCRUID,
+	})
+	return nil
+}
+
+// collectGarbage deletes the NSX prefix lists whose PrefixList CRs have been removed.
+func (r *PrefixListReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	nsxPrefixListSet := r.Service.ListPrefixListCRUID()
+	run.Scanned(len(nsxPrefixListSet))
+	if len(nsxPrefixListSet) == 0 {
+		return nil
+	}
+	prefixListList := &v1alpha1.PrefixListList{}
+	if err := r.Client.List(ctx, prefixListList); err != nil {
+		log.Error(err, "failed to list PrefixList CR")
+		return err
+	}
+
+	CRPrefixListSet := sets.NewString()
+	for _, obj := range prefixListList.Items {
+		CRPrefixListSet.Insert(string(obj.UID))
+	}
+
+	run.Orphaned(servicecommon.TagScopePrefixListCRUID, nsxPrefixListSet.Difference(CRPrefixListSet))
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxPrefixListSet, CRPrefixListSet); err != nil {
+		return err
+	}
+
+	for elem := range nsxPrefixListSet {
+		if CRPrefixListSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		log.V(1).Info("GC collected PrefixList CR", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.Service.DeletePrefixListByCRUID(types.UID(elem)); err != nil {
+			run.Deleted(err)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			run.Deleted(nil)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+		}
+	}
+	return nil
+}
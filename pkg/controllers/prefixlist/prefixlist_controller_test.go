@@ -0,0 +1,136 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package prefixlist
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_0s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/routemap"
+)
+
+type fakeQueryClient struct{}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	count := int64(0)
+	return model.SearchResponse{ResultCount: &count}, nil
+}
+
+type fakePrefixListClient struct {
+	tier_0s.PrefixListsClient
+	prefixLists map[string]bool
+}
+
+func (c *fakePrefixListClient) Patch(_ string, id string, _ model.PrefixList) error {
+	c.prefixLists[id] = true
+	return nil
+}
+
+func (c *fakePrefixListClient) Delete(_ string, id string) error {
+	delete(c.prefixLists, id)
+	return nil
+}
+
+func newFakePrefixListReconciler(t *testing.T, tier0 string, objs ...apimachineryruntime.Object) (*PrefixListReconciler, *fakePrefixListClient) {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{Tier0Gateway: tier0},
+	}
+	prefixListClient := &fakePrefixListClient{prefixLists: map[string]bool{}}
+	nsxClient := &nsx.Client{
+		NsxConfig:             nsxConfig,
+		QueryClient:           &fakeQueryClient{},
+		Tier0PrefixListClient: prefixListClient,
+	}
+	service, err := routemap.InitializeRouteMap(servicecommon.Service{NSXClient: nsxClient, NSXConfig: nsxConfig})
+	assert.Nil(t, err)
+	return &PrefixListReconciler{
+		Client:  fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:  scheme,
+		Service: service,
+	}, prefixListClient
+}
+
+func newPrefixListCR(name string, uid types.UID) *v1alpha1.PrefixList {
+	return &v1alpha1.PrefixList{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: uid},
+		Spec: v1alpha1.PrefixListSpec{
+			Prefixes: []v1alpha1.PrefixListEntry{{Network: "10.244.0.0/16", LE: 24}},
+		},
+	}
+}
+
+func TestPrefixListReconciler_Reconcile(t *testing.T) {
+	r, prefixListClient := newFakePrefixListReconciler(t, "t0", newPrefixListCR("pl1", "uid1"))
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Name: "pl1"}}
+
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.True(t, prefixListClient.prefixLists["pl_uid1"])
+	obj := &v1alpha1.PrefixList{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.PrefixListFinalizerName)
+	assert.Equal(t, "/infra/tier-0s/t0/prefix-lists/pl_uid1", obj.Status.Path)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+
+	// the NSX prefix list is deleted with the PrefixList CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Empty(t, prefixListClient.prefixLists)
+	err = r.Client.Get(ctx, req.NamespacedName, obj)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestPrefixListReconciler_ReconcileInvalid(t *testing.T) {
+	prefixListCR := newPrefixListCR("pl1", "uid1")
+	prefixListCR.Spec.Prefixes[0].LE = 8
+	r, prefixListClient := newFakePrefixListReconciler(t, "t0", prefixListCR)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Name: "pl1"}}
+
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Empty(t, prefixListClient.prefixLists)
+	obj := &v1alpha1.PrefixList{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+	assert.Contains(t, obj.Status.Conditions[0].Message, "le 8 of network 10.244.0.0/16 is out of range")
+}
+
+func TestPrefixListReconciler_GarbageCollector(t *testing.T) {
+	prefixListCR := newPrefixListCR("pl1", "uid1")
+	r, prefixListClient := newFakePrefixListReconciler(t, "t0", prefixListCR)
+	_, err := r.Service.CreateOrUpdatePrefixList(prefixListCR)
+	assert.Nil(t, err)
+	_, err = r.Service.CreateOrUpdatePrefixList(newPrefixListCR("pl2", "uid2"))
+	assert.Nil(t, err)
+
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	assert.Equal(t, []string{"uid1"}, r.Service.ListPrefixListCRUID().List())
+	assert.True(t, prefixListClient.prefixLists["pl_uid1"])
+}
@@ -0,0 +1,363 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package routeadvertisement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/routeadvertisement"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	MetricResType                    = common.MetricResTypeRouteAdvertisement
+)
+
+// RouteAdvertisementReconciler configures the route advertisement of the NSX Tier-1 gateway of a RouteAdvertisement CR.
+type RouteAdvertisementReconciler struct {
+	Client  client.Client
+	Scheme  *apimachineryruntime.Scheme
+	Service *routeadvertisement.RouteAdvertisementService
+}
+
+func updateFail(r *RouteAdvertisementReconciler, c *context.Context, o *v1alpha1.RouteAdvertisement, e *error) {
+	r.setRouteAdvertisementReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while processing the RouteAdvertisement CR. Error: %v", *e))
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+}
+
+func deleteFail(r *RouteAdvertisementReconciler, c *context.Context, o *v1alpha1.RouteAdvertisement, e *error) {
+	r.setRouteAdvertisementReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while deleting the RouteAdvertisement CR. Error: %v", *e))
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+}
+
+func updateSuccess(r *RouteAdvertisementReconciler, c *context.Context, o *v1alpha1.RouteAdvertisement, gateway string, routeMapPath string) {
+	r.setRouteAdvertisementReadyStatusTrue(c, o, gateway, routeMapPath)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+}
+
+func deleteSuccess(r *RouteAdvertisementReconciler, _ *context.Context, _ *v1alpha1.RouteAdvertisement) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+}
+
+func (r *RouteAdvertisementReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.RouteAdvertisement{}
+	log.Info("reconciling RouteAdvertisement CR", "routeadvertisement", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch RouteAdvertisement CR", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "routeadvertisement", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "routeadvertisement", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.RouteAdvertisementFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.RouteAdvertisementFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "routeadvertisement", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on RouteAdvertisement CR", "routeadvertisement", req.NamespacedName)
+		}
+
+		gateway := r.Service.GetTier1Gateway(obj)
+		routeMapPath, err := r.apply(ctx, obj, gateway)
+		if err != nil {
+			if errors.As(err, &nsxutil.RestrictionError{}) {
+				log.Error(err, err.Error(), "routeadvertisement", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultNormal, nil
+			}
+			log.Error(err, "operate failed, would retry exponentially", "routeadvertisement", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return common.ResultForError(err)
+		}
+		updateSuccess(r, &ctx, obj, gateway, routeMapPath)
+	} else {
+		if controllerutil.ContainsFinalizer(obj, servicecommon.RouteAdvertisementFinalizerName) {
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+			gateway := obj.Status.Tier1Gateway
+			if gateway == "" {
+				gateway = r.Service.GetTier1Gateway(obj)
+			}
+			if gateway != "" {
+				if err := r.Service.RemoveRouteAdvertisement(obj.Name, gateway); err != nil {
+					log.Error(err, "deletion failed, would retry exponentially", "routeadvertisement", req.NamespacedName)
+					deleteFail(r, &ctx, obj, &err)
+					return common.ResultForError(err)
+				}
+			}
+			if obj.Status.RouteMapPath != "" {
+				if err := r.Service.RemoveRouteRedistribution(obj.Name, obj.Status.RouteMapPath); err != nil {
+					log.Error(err, "deletion failed, would retry exponentially", "routeadvertisement", req.NamespacedName)
+					deleteFail(r, &ctx, obj, &err)
+					return common.ResultForError(err)
+				}
+			}
+			controllerutil.RemoveFinalizer(obj, servicecommon.RouteAdvertisementFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "routeadvertisement", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("removed finalizer", "routeadvertisement", req.NamespacedName)
+			deleteSuccess(r, &ctx, obj)
+		} else {
+			// only print a message because it's not a normal case
+			log.Info("finalizers cannot be recognized", "routeadvertisement", req.NamespacedName)
+		}
+	}
+
+	return ResultNormal, nil
+}
+
+// apply configures the route advertisement of the Tier-1 gateway, and the route redistribution through the RouteMap on
+// its Tier-0 gateway if the RouteAdvertisement CR references one. It returns the path of the route map.
+func (r *RouteAdvertisementReconciler) apply(ctx context.Context, obj *v1alpha1.RouteAdvertisement, gateway string) (string, error) {
+	if err := r.checkGatewayOwner(ctx, obj, gateway); err != nil {
+		return "", err
+	}
+	if obj.Status.Tier1Gateway != "" && obj.Status.Tier1Gateway != gateway {
+		// the advertisement is moved to another gateway
+		if err := r.Service.RemoveRouteAdvertisement(obj.Name, obj.Status.Tier1Gateway); err != nil {
+			return "", err
+		}
+	}
+	if err := r.Service.ApplyRouteAdvertisement(obj); err != nil {
+		return "", err
+	}
+
+	routeMapPath := ""
+	if obj.Spec.RouteMap != "" {
+		// the RouteMap may be created after the RouteAdvertisement, so the errors are retried
+		routeMap := &v1alpha1.RouteMap{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: obj.Spec.RouteMap}, routeMap); err != nil {
+			return "", fmt.Errorf("failed to get RouteMap %s: %w", obj.Spec.RouteMap, err)
+		}
+		if routeMap.Status.Path == "" {
+			return "", fmt.Errorf("RouteMap %s is not created yet", obj.Spec.RouteMap)
+		}
+		routeMapPath = routeMap.Status.Path
+	}
+	if obj.Status.RouteMapPath != "" && obj.Status.RouteMapPath != routeMapPath {
+		if err := r.Service.RemoveRouteRedistribution(obj.Name, obj.Status.RouteMapPath); err != nil {
+			return "", err
+		}
+	}
+	if routeMapPath != "" {
+		if err := r.Service.ApplyRouteRedistribution(obj, routeMapPath); err != nil {
+			return "", err
+		}
+	}
+	return routeMapPath, nil
+}
+
+// checkGatewayOwner returns a RestrictionError if an older RouteAdvertisement CR configures the same gateway.
+func (r *RouteAdvertisementReconciler) checkGatewayOwner(ctx context.Context, obj *v1alpha1.RouteAdvertisement, gateway string) error {
+	list := &v1alpha1.RouteAdvertisementList{}
+	if err := r.Client.List(ctx, list); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		other := &list.Items[i]
+		if other.UID == obj.UID || r.Service.GetTier1Gateway(other) != gateway || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if other.CreationTimestamp.Before(&obj.CreationTimestamp) ||
+			(other.CreationTimestamp.Equal(&obj.CreationTimestamp) && other.Name < obj.Name) {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("Tier-1 gateway %s is already configured by RouteAdvertisement %s", gateway, other.Name)}
+		}
+	}
+	return nil
+}
+
+func (r *RouteAdvertisementReconciler) setRouteAdvertisementReadyStatusTrue(ctx *context.Context, obj *v1alpha1.RouteAdvertisement, gateway string, routeMapPath string) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionTrue,
+			Message: "NSX route advertisement has been successfully configured",
+			Reason:  "NSX API returned 200 response code for PATCH",
+		},
+	}
+	r.updateRouteAdvertisementStatus(ctx, obj, newConditions, gateway, routeMapPath)
+}
+
+func (r *RouteAdvertisementReconciler) setRouteAdvertisementReadyStatusFalse(ctx *context.Context, obj *v1alpha1.RouteAdvertisement, reason, message string) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionFalse,
+			Message: message,
+			Reason:  reason,
+		},
+	}
+	r.updateRouteAdvertisementStatus(ctx, obj, newConditions, obj.Status.Tier1Gateway, obj.Status.RouteMapPath)
+}
+
+func (r *RouteAdvertisementReconciler) updateRouteAdvertisementStatus(ctx *context.Context, obj *v1alpha1.RouteAdvertisement, newConditions []v1alpha1.Condition, gateway string, routeMapPath string) {
+	updated := obj.Status.Tier1Gateway != gateway || obj.Status.RouteMapPath != routeMapPath
+	obj.Status.Tier1Gateway = gateway
+	obj.Status.RouteMapPath = routeMapPath
+	for i := range newConditions {
+		if mergeRouteAdvertisementStatusCondition(obj, &newConditions[i]) {
+			updated = true
+		}
+	}
+	if updated {
+		r.Client.Status().Update(*ctx, obj)
+		log.V(1).Info("updated RouteAdvertisement", "Name", obj.Name, "New Conditions", newConditions, "Tier1Gateway", gateway, "RouteMapPath", routeMapPath)
+	}
+}
+
+func mergeRouteAdvertisementStatusCondition(obj *v1alpha1.RouteAdvertisement, newCondition *v1alpha1.Condition) bool {
+	var matchedCondition *v1alpha1.Condition
+	for i := range obj.Status.Conditions {
+		if obj.Status.Conditions[i].Type == newCondition.Type {
+			matchedCondition = &obj.Status.Conditions[i]
+			break
+		}
+	}
+
+	if reflect.DeepEqual(matchedCondition, newCondition) {
+		log.V(2).Info("conditions already match", "New Condition", newCondition, "Existing Condition", matchedCondition)
+		return false
+	}
+
+	if matchedCondition != nil {
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		matchedCondition.Status = newCondition.Status
+	} else {
+		obj.Status.Conditions = append(obj.Status.Conditions, *newCondition)
+	}
+	return true
+}
+
+func (r *RouteAdvertisementReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.RouteAdvertisement{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerRouteAdvertisement),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.RouteAdvertisementList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.RouteAdvertisement{}, r)
+}
+
+// Start setup manager and launch GC
+func (r *RouteAdvertisementReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	return nil
+}
+
+// collectGarbage deletes the route advertisement rules on the configured Tier-1 gateway whose RouteAdvertisement
+// CRs have been removed.
+func (r *RouteAdvertisementReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	gateway := r.Service.NSXConfig.Tier1Gateway
+	if gateway == "" {
+		return nil
+	}
+	nsxNameSet, err := r.Service.ListRouteAdvertisementNames(gateway)
+	if err != nil {
+		log.Error(err, "failed to list route advertisement rules", "Tier1", gateway)
+		return err
+	}
+	run.Scanned(len(nsxNameSet))
+	if len(nsxNameSet) == 0 {
+		return nil
+	}
+	list := &v1alpha1.RouteAdvertisementList{}
+	if err := r.Client.List(ctx, list); err != nil {
+		log.Error(err, "failed to list RouteAdvertisement CR")
+		return err
+	}
+
+	CRNameSet := sets.NewString()
+	for _, obj := range list.Items {
+		CRNameSet.Insert(obj.Name)
+	}
+
+	run.Orphaned(servicecommon.TagScopeRouteAdvertisementCRName, nsxNameSet.Difference(CRNameSet))
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxNameSet, CRNameSet); err != nil {
+		return err
+	}
+
+	for elem := range nsxNameSet {
+		if CRNameSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		log.V(1).Info("GC collected RouteAdvertisement CR", "Name", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.Service.RemoveRouteAdvertisement(elem, gateway); err != nil {
+			run.Deleted(err)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			run.Deleted(nil)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package routeadvertisement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_0s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/routeadvertisement"
+)
+
+type fakeTier1Client struct {
+	infra.Tier1sClient
+	tier1s map[string]model.Tier1
+}
+
+func (c *fakeTier1Client) Get(id string) (model.Tier1, error) {
+	tier1, ok := c.tier1s[id]
+	if !ok {
+		return model.Tier1{}, vapierrors.NotFound{}
+	}
+	return tier1, nil
+}
+
+func (c *fakeTier1Client) Patch(id string, tier1 model.Tier1) error {
+	c.tier1s[id] = tier1
+	return nil
+}
+
+type fakeLocaleServicesClient struct {
+	tier_0s.LocaleServicesClient
+	config *model.Tier0RouteRedistributionConfig
+}
+
+func (c *fakeLocaleServicesClient) List(_ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.LocaleServicesListResult, error) {
+	return model.LocaleServicesListResult{Results: []model.LocaleServices{{Id: servicecommon.String("default"), RouteRedistributionConfig: c.config}}}, nil
+}
+
+func (c *fakeLocaleServicesClient) Patch(_ string, _ string, localeServices model.LocaleServices) error {
+	c.config = localeServices.RouteRedistributionConfig
+	return nil
+}
+
+func newFakeRouteAdvertisementReconciler(t *testing.T, objs ...apimachineryruntime.Object) (*RouteAdvertisementReconciler, *fakeTier1Client) {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{Tier1Gateway: "t1"},
+	}
+	tier1Client := &fakeTier1Client{tier1s: map[string]model.Tier1{"t1": {}, "t1-other": {}}}
+	nsxClient := &nsx.Client{NsxConfig: nsxConfig, Tier1Client: tier1Client, Tier0LocaleServicesClient: &fakeLocaleServicesClient{}}
+	service, err := routeadvertisement.InitializeRouteAdvertisement(servicecommon.Service{NSXClient: nsxClient, NSXConfig: nsxConfig})
+	assert.Nil(t, err)
+	return &RouteAdvertisementReconciler{
+		Client:  fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:  scheme,
+		Service: service,
+	}, tier1Client
+}
+
+func newRouteAdvertisementCR(name string, uid types.UID, created time.Time) *v1alpha1.RouteAdvertisement {
+	return &v1alpha1.RouteAdvertisement{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: uid, CreationTimestamp: metav1.NewTime(created)},
+		Spec: v1alpha1.RouteAdvertisementSpec{
+			Advertise: []v1alpha1.RouteAdvertisementPrefixType{v1alpha1.RouteAdvertisementPodCIDRs},
+			Rules: []v1alpha1.RouteAdvertisementRule{{
+				Name:    "deny-pods",
+				Action:  v1alpha1.RouteAdvertisementDeny,
+				Subnets: []string{"10.0.0.0/16"},
+			}},
+		},
+	}
+}
+
+func TestRouteAdvertisementReconciler_Reconcile(t *testing.T) {
+	r, tier1Client := newFakeRouteAdvertisementReconciler(t, newRouteAdvertisementCR("ra", "uid1", time.Now()))
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Name: "ra"}}
+
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Equal(t, []string{model.Tier1_ROUTE_ADVERTISEMENT_TYPES_CONNECTED}, tier1Client.tier1s["t1"].RouteAdvertisementTypes)
+	assert.Len(t, tier1Client.tier1s["t1"].RouteAdvertisementRules, 1)
+	obj := &v1alpha1.RouteAdvertisement{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.RouteAdvertisementFinalizerName)
+	assert.Equal(t, "t1", obj.Status.Tier1Gateway)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+
+	// the rules are moved to the new gateway
+	obj.Spec.Tier1Gateway = "t1-other"
+	assert.Nil(t, r.Client.Update(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Empty(t, tier1Client.tier1s["t1"].RouteAdvertisementRules)
+	assert.Len(t, tier1Client.tier1s["t1-other"].RouteAdvertisementRules, 1)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, "t1-other", obj.Status.Tier1Gateway)
+
+	// the rules are removed with the RouteAdvertisement CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Empty(t, tier1Client.tier1s["t1-other"].RouteAdvertisementRules)
+	err = r.Client.Get(ctx, req.NamespacedName, obj)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestRouteAdvertisementReconciler_ReconcileConflict(t *testing.T) {
+	now := time.Now()
+	r, tier1Client := newFakeRouteAdvertisementReconciler(t,
+		newRouteAdvertisementCR("ra1", "uid1", now.Add(-time.Minute)),
+		newRouteAdvertisementCR("ra2", "uid2", now))
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Name: "ra2"}}
+
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Empty(t, tier1Client.tier1s["t1"].RouteAdvertisementRules)
+	obj := &v1alpha1.RouteAdvertisement{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+	assert.Contains(t, obj.Status.Conditions[0].Message, "Tier-1 gateway t1 is already configured by RouteAdvertisement ra1")
+
+	// the CRs on different gateways do not conflict
+	obj.Spec.Tier1Gateway = "t1-other"
+	assert.Nil(t, r.Client.Update(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Len(t, tier1Client.tier1s["t1-other"].RouteAdvertisementRules, 1)
+}
+
+func TestRouteAdvertisementReconciler_GarbageCollector(t *testing.T) {
+	raCR := newRouteAdvertisementCR("ra1", "uid1", time.Now())
+	r, _ := newFakeRouteAdvertisementReconciler(t, raCR)
+	assert.Nil(t, r.Service.ApplyRouteAdvertisement(raCR))
+	assert.Nil(t, r.Service.ApplyRouteAdvertisement(newRouteAdvertisementCR("ra2", "uid2", time.Now())))
+
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	names, err := r.Service.ListRouteAdvertisementNames("t1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ra1"}, names.List())
+}
+
+func TestRouteAdvertisementReconciler_ReconcileRouteMap(t *testing.T) {
+	raCR := newRouteAdvertisementCR("ra", "uid1", time.Now())
+	raCR.Spec.RouteMap = "rm1"
+	r, _ := newFakeRouteAdvertisementReconciler(t, raCR)
+	localeServicesClient := r.Service.NSXClient.Tier0LocaleServicesClient.(*fakeLocaleServicesClient)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Name: "ra"}}
+
+	// the RouteMap is not created yet
+	result, err := r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	assert.Equal(t, ResultRequeue, result)
+
+	routeMapPath := "/infra/tier-0s/t0/route-maps/rm_uid2"
+	routeMap := &v1alpha1.RouteMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "rm1"},
+		Status:     v1alpha1.RouteMapStatus{Path: routeMapPath},
+	}
+	assert.Nil(t, r.Client.Create(ctx, routeMap))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Len(t, localeServicesClient.config.RedistributionRules, 1)
+	assert.Equal(t, routeMapPath, *localeServicesClient.config.RedistributionRules[0].RouteMapPath)
+	obj := &v1alpha1.RouteAdvertisement{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, routeMapPath, obj.Status.RouteMapPath)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+
+	// the redistribution is removed with the RouteAdvertisement CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Empty(t, localeServicesClient.config.RedistributionRules)
+}
@@ -0,0 +1,291 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package routemap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/routemap"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	MetricResType                    = common.MetricResTypeRouteMap
+)
+
+// RouteMapReconciler creates the NSX route maps of the RouteMap CRs on the Tier-0 gateways, the route maps match the
+// NSX prefix lists of the PrefixList CRs.
+type RouteMapReconciler struct {
+	Client  client.Client
+	Scheme  *apimachineryruntime.Scheme
+	Service *routemap.RouteMapService
+}
+
+func updateFail(r *RouteMapReconciler, c *context.Context, o *v1alpha1.RouteMap, e *error) {
+	r.setRouteMapReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while processing the RouteMap CR. Error: %v", *e))
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+}
+
+func deleteFail(r *RouteMapReconciler, c *context.Context, o *v1alpha1.RouteMap, e *error) {
+	r.setRouteMapReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while deleting the RouteMap CR. Error: %v", *e))
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+}
+
+func updateSuccess(r *RouteMapReconciler, c *context.Context, o *v1alpha1.RouteMap, path string) {
+	r.setRouteMapReadyStatusTrue(c, o, path)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+}
+
+func deleteSuccess(r *RouteMapReconciler, _ *context.Context, _ *v1alpha1.RouteMap) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+}
+
+func (r *RouteMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.RouteMap{}
+	log.Info("reconciling RouteMap CR", "routemap", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch RouteMap CR", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "routemap", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "routemap", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.RouteMapFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.RouteMapFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "routemap", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on RouteMap CR", "routemap", req.NamespacedName)
+		}
+
+		path, err := r.Service.CreateOrUpdateRouteMap(obj)
+		if err != nil {
+			if errors.As(err, &nsxutil.RestrictionError{}) {
+				log.Error(err, err.Error(), "routemap", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultNormal, nil
+			}
+			log.Error(err, "operate failed, would retry exponentially", "routemap", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return common.ResultForError(err)
+		}
+		updateSuccess(r, &ctx, obj, path)
+	} else {
+		if controllerutil.ContainsFinalizer(obj, servicecommon.RouteMapFinalizerName) {
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+			if err := r.Service.DeleteRouteMapByCRUID(obj.UID); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "routemap", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return common.ResultForError(err)
+			}
+			controllerutil.RemoveFinalizer(obj, servicecommon.RouteMapFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "routemap", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("removed finalizer", "routemap", req.NamespacedName)
+			deleteSuccess(r, &ctx, obj)
+		} else {
+			// only print a message because it's not a normal case
+			log.Info("finalizers cannot be recognized", "routemap", req.NamespacedName)
+		}
+	}
+
+	return ResultNormal, nil
+}
+
+func (r *RouteMapReconciler) setRouteMapReadyStatusTrue(ctx *context.Context, obj *v1alpha1.RouteMap, path string) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionTrue,
+			Message: "NSX route map has been successfully created/updated",
+			Reason:  "NSX API returned 200 response code for PATCH",
+		},
+	}
+	r.updateRouteMapStatus(ctx, obj, newConditions, path)
+}
+
+func (r *RouteMapReconciler) setRouteMapReadyStatusFalse(ctx *context.Context, obj *v1alpha1.RouteMap, reason, message string) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionFalse,
+			Message: message,
+			Reason:  reason,
+		},
+	}
+	r.updateRouteMapStatus(ctx, obj, newConditions, obj.Status.Path)
+}
+
+func (r *RouteMapReconciler) updateRouteMapStatus(ctx *context.Context, obj *v1alpha1.RouteMap, newConditions []v1alpha1.Condition, path string) {
+	updated := obj.Status.Path != path
+	obj.Status.Path = path
+	for i := range newConditions {
+		if mergeRouteMapStatusCondition(obj, &newConditions[i]) {
+			updated = true
+		}
+	}
+	if updated {
+		r.Client.Status().Update(*ctx, obj)
+		log.V(1).Info("updated RouteMap", "Name", obj.Name, "New Conditions", newConditions, "Path", path)
+	}
+}
+
+func mergeRouteMapStatusCondition(obj *v1alpha1.RouteMap, newCondition *v1alpha1.Condition) bool {
+	var matchedCondition *v1alpha1.Condition
+	for i := range obj.Status.Conditions {
+		if obj.Status.Conditions[i].Type == newCondition.Type {
+			matchedCondition = &obj.Status.Conditions[i]
+			break
+		}
+	}
+
+	if reflect.DeepEqual(matchedCondition, newCondition) {
+		log.V(2).Info("conditions already match", "New Condition", newCondition, "Existing Condition", matchedCondition)
+		return false
+	}
+
+	if matchedCondition != nil {
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		matchedCondition.Status = newCondition.Status
+	} else {
+		obj.Status.Conditions = append(obj.Status.Conditions, *newCondition)
+	}
+	return true
+}
+
+func (r *RouteMapReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.RouteMap{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerRouteMap),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopeRouteMapCRName, func() client.Object { return &v1alpha1.RouteMap{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.RouteMapList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.RouteMap{}, r)
+}
+
+// Start setup manager and launch GC
+func (r *RouteMapReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	go common.ReportDrift(make(chan bool), servicecommon.DriftInterval, r.Service.NSXConfig, common.DriftSource{
+		ResourceType: MetricResType,
+		Client:       r.Client,
+		List:         &v1alpha1.RouteMapList{},
+		NSXUIDs:      r.Service.ListRouteMapCRUID,
+	})
+	return nil
+}
+
+// collectGarbage deletes the NSX route maps whose RouteMap CRs have been removed.
+func (r *RouteMapReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	nsxRouteMapSet := r.Service.ListRouteMapCRUID()
+	run.Scanned(len(nsxRouteMapSet))
+	if len(nsxRouteMapSet) == 0 {
+		return nil
+	}
+	routeMapList := &v1alpha1.RouteMapList{}
+	if err := r.Client.List(ctx, routeMapList); err != nil {
+		log.Error(err, "failed to list RouteMap CR")
+		return err
+	}
+
+	CRRouteMapSet := sets.NewString()
+	for _, obj := range routeMapList.Items {
+		CRRouteMapSet.Insert(string(obj.UID))
+	}
+
+	run.Orphaned(servicecommon.TagScopeRouteMapCRUID, nsxRouteMapSet.Difference(CRRouteMapSet))
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxRouteMapSet, CRRouteMapSet); err != nil {
+		return err
+	}
+
+	for elem := range nsxRouteMapSet {
+		if CRRouteMapSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		log.V(1).Info("GC collected RouteMap CR", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.Service.DeleteRouteMapByCRUID(types.UID(elem)); err != nil {
+			run.Deleted(err)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			run.Deleted(nil)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+		}
+	}
+	return nil
+}
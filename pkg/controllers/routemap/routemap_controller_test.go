@@ -0,0 +1,149 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package routemap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_0s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/routemap"
+)
+
+type fakeQueryClient struct{}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	count := int64(0)
+	return model.SearchResponse{ResultCount: &count}, nil
+}
+
+type fakePrefixListClient struct {
+	tier_0s.PrefixListsClient
+}
+
+func (c *fakePrefixListClient) Patch(_ string, _ string, _ model.PrefixList) error {
+	return nil
+}
+
+type fakeRouteMapClient struct {
+	tier_0s.RouteMapsClient
+	routeMaps map[string]model.Tier0RouteMap
+}
+
+func (c *fakeRouteMapClient) Patch(_ string, id string, routeMap model.Tier0RouteMap) error {
+	c.routeMaps[id] = routeMap
+	return nil
+}
+
+func (c *fakeRouteMapClient) Delete(_ string, id string) error {
+	delete(c.routeMaps, id)
+	return nil
+}
+
+func newFakeRouteMapReconciler(t *testing.T, objs ...apimachineryruntime.Object) (*RouteMapReconciler, *fakeRouteMapClient) {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{Tier0Gateway: "t0"},
+	}
+	routeMapClient := &fakeRouteMapClient{routeMaps: map[string]model.Tier0RouteMap{}}
+	nsxClient := &nsx.Client{
+		NsxConfig:             nsxConfig,
+		QueryClient:           &fakeQueryClient{},
+		Tier0PrefixListClient: &fakePrefixListClient{},
+		Tier0RouteMapClient:   routeMapClient,
+	}
+	service, err := routemap.InitializeRouteMap(servicecommon.Service{NSXClient: nsxClient, NSXConfig: nsxConfig})
+	assert.Nil(t, err)
+	return &RouteMapReconciler{
+		Client:  fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:  scheme,
+		Service: service,
+	}, routeMapClient
+}
+
+func newRouteMapCR(name string, uid types.UID) *v1alpha1.RouteMap {
+	return &v1alpha1.RouteMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: uid},
+		Spec: v1alpha1.RouteMapSpec{
+			Entries: []v1alpha1.RouteMapEntry{{PrefixLists: []string{"pl1"}}},
+		},
+	}
+}
+
+func newPrefixListCR() *v1alpha1.PrefixList {
+	return &v1alpha1.PrefixList{
+		ObjectMeta: metav1.ObjectMeta{Name: "pl1", UID: "uid1"},
+		Spec: v1alpha1.PrefixListSpec{
+			Prefixes: []v1alpha1.PrefixListEntry{{Network: "ANY"}},
+		},
+	}
+}
+
+func TestRouteMapReconciler_Reconcile(t *testing.T) {
+	r, routeMapClient := newFakeRouteMapReconciler(t, newRouteMapCR("rm1", "uid2"))
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Name: "rm1"}}
+
+	// the PrefixList is not created yet
+	result, err := r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	assert.Equal(t, ResultRequeue, result)
+	obj := &v1alpha1.RouteMap{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+	assert.Contains(t, obj.Status.Conditions[0].Message, "PrefixList pl1 not found on Tier-0 gateway t0")
+
+	_, err = r.Service.CreateOrUpdatePrefixList(newPrefixListCR())
+	assert.Nil(t, err)
+	result, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Equal(t, []string{"/infra/tier-0s/t0/prefix-lists/pl_uid1"}, routeMapClient.routeMaps["rm_uid2"].Entries[0].PrefixListMatches)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.RouteMapFinalizerName)
+	assert.Equal(t, "/infra/tier-0s/t0/route-maps/rm_uid2", obj.Status.Path)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+
+	// the NSX route map is deleted with the RouteMap CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Empty(t, routeMapClient.routeMaps)
+	err = r.Client.Get(ctx, req.NamespacedName, obj)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestRouteMapReconciler_GarbageCollector(t *testing.T) {
+	routeMapCR := newRouteMapCR("rm1", "uid2")
+	r, routeMapClient := newFakeRouteMapReconciler(t, routeMapCR)
+	_, err := r.Service.CreateOrUpdatePrefixList(newPrefixListCR())
+	assert.Nil(t, err)
+	_, err = r.Service.CreateOrUpdateRouteMap(routeMapCR)
+	assert.Nil(t, err)
+	_, err = r.Service.CreateOrUpdateRouteMap(newRouteMapCR("rm2", "uid3"))
+	assert.Nil(t, err)
+
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	assert.Equal(t, []string{"uid2"}, r.Service.ListRouteMapCRUID().List())
+	assert.Contains(t, routeMapClient.routeMaps, "rm_uid2")
+}
@@ -8,8 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"runtime"
-	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
@@ -23,11 +21,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
 	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
 	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
 	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
@@ -39,11 +39,13 @@ import (
 )
 
 var (
-	log                     = logger.Log
-	ResultNormal            = common.ResultNormal
-	ResultRequeue           = common.ResultRequeue
-	ResultRequeueAfter5mins = common.ResultRequeueAfter5mins
-	MetricResType           = common.MetricResTypeSecurityPolicy
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfter5mins          = common.ResultRequeueAfter5mins
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	MetricResType                    = common.MetricResTypeSecurityPolicy
 )
 
 // SecurityPolicyReconciler SecurityPolicyReconcile reconciles a SecurityPolicy object
@@ -89,6 +91,7 @@ func (r *SecurityPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		log.Error(err, "unable to fetch security policy CR", "req", req.NamespacedName)
 		return ResultNormal, client.IgnoreNotFound(err)
 	}
+	logger.CorrelateObject(ctx, obj)
 
 	// Since SecurityPolicy service can only be activated from NSX 3.2.0 onwards,
 	// So need to check NSX version before starting SecurityPolicy reconcile
@@ -99,6 +102,15 @@ func (r *SecurityPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ResultRequeueAfter5mins, nil
 	}
 
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "securitypolicy", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "securitypolicy", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
 	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
 		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
 		if !controllerutil.ContainsFinalizer(obj, servicecommon.FinalizerName) {
@@ -131,7 +143,7 @@ func (r *SecurityPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			}
 			log.Error(err, "operate failed, would retry exponentially", "securitypolicy", req.NamespacedName)
 			updateFail(r, &ctx, obj, &err)
-			return ResultRequeue, err
+			return common.ResultForError(err)
 		}
 		updateSuccess(r, &ctx, obj)
 	} else {
@@ -140,7 +152,7 @@ func (r *SecurityPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			if err := r.Service.DeleteSecurityPolicy(obj.UID); err != nil {
 				log.Error(err, "deletion failed, would retry exponentially", "securitypolicy", req.NamespacedName)
 				deleteFail(r, &ctx, obj, &err)
-				return ResultRequeue, err
+				return common.ResultForError(err)
 			}
 			controllerutil.RemoveFinalizer(obj, servicecommon.FinalizerName)
 			if err := r.Client.Update(ctx, obj); err != nil {
@@ -176,11 +188,8 @@ func (r *SecurityPolicyReconciler) setSecurityPolicyReadyStatusFalse(ctx *contex
 		{
 			Type:    v1alpha1.Ready,
 			Status:  v1.ConditionFalse,
-			Message: "NSX Security Policy could not be created/updated",
-			Reason: fmt.Sprintf(
-				"error occurred while processing the Security Policy CR. Error: %v",
-				*err,
-			),
+			Message: fmt.Sprintf("NSX Security Policy could not be created/updated, error occurred while processing the Security Policy CR. Error: %v", *err),
+			Reason:  common.ErrorReason(*err),
 		},
 	}
 	r.updateSecurityPolicyStatusConditions(ctx, sec_policy, newConditions)
@@ -228,8 +237,8 @@ func getExistingConditionOfType(conditionType v1alpha1.ConditionType, existingCo
 }
 
 func (r *SecurityPolicyReconciler) setupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.SecurityPolicy{}).
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.SecurityPolicy{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
 		WithEventFilter(predicate.Funcs{
 			DeleteFunc: func(e event.DeleteEvent) bool {
 				// Suppress Delete events to avoid filtering them out in the Reconcile function
@@ -238,7 +247,8 @@ func (r *SecurityPolicyReconciler) setupWithManager(mgr ctrl.Manager) error {
 		}).
 		WithOptions(
 			controller.Options{
-				MaxConcurrentReconciles: runtime.NumCPU(),
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerSecurityPolicy),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
 			}).
 		Watches(
 			&source.Kind{Type: &v1.Namespace{}},
@@ -250,7 +260,21 @@ func (r *SecurityPolicyReconciler) setupWithManager(mgr ctrl.Manager) error {
 			&EnqueueRequestForPod{Client: k8sClient(mgr)},
 			builder.WithPredicates(PredicateFuncsPod),
 		).
-		Complete(r)
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopeSecurityPolicyCRName, func() client.Object { return &v1alpha1.SecurityPolicy{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.SecurityPolicyList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.SecurityPolicy{}, r)
 }
 
 // Start setup manager and launch GC
@@ -260,51 +284,59 @@ func (r *SecurityPolicyReconciler) Start(mgr ctrl.Manager) error {
 		return err
 	}
 
-	go r.GarbageCollector(make(chan bool), servicecommon.GCInterval)
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	go common.ReportDrift(make(chan bool), servicecommon.DriftInterval, r.Service.NSXConfig, common.DriftSource{
+		ResourceType: MetricResType,
+		Client:       r.Client,
+		List:         &v1alpha1.SecurityPolicyList{},
+		NSXUIDs:      r.Service.ListSecurityPolicyID,
+	})
 	return nil
 }
 
-// GarbageCollector collect securitypolicy which has been removed from crd.
-// cancel is used to break the loop during UT
-func (r *SecurityPolicyReconciler) GarbageCollector(cancel chan bool, timeout time.Duration) {
-	ctx := context.Background()
-	log.Info("garbage collector started")
-	for {
-		select {
-		case <-cancel:
-			return
-		case <-time.After(timeout):
-		}
-		nsxPolicySet := r.Service.ListSecurityPolicyID()
-		if len(nsxPolicySet) == 0 {
+// collectGarbage deletes securitypolicy which has been removed from crd.
+func (r *SecurityPolicyReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	nsxPolicySet := r.Service.ListSecurityPolicyID()
+	run.Scanned(len(nsxPolicySet))
+	if len(nsxPolicySet) == 0 {
+		return nil
+	}
+	policyList := &v1alpha1.SecurityPolicyList{}
+	err := r.Client.List(ctx, policyList)
+	if err != nil {
+		log.Error(err, "failed to list security policy CR")
+		return err
+	}
+
+	CRPolicySet := sets.NewString()
+	for _, policy := range policyList.Items {
+		CRPolicySet.Insert(string(policy.UID))
+	}
+
+	run.Orphaned(servicecommon.TagScopeSecurityPolicyCRUID, nsxPolicySet.Difference(CRPolicySet))
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxPolicySet, CRPolicySet); err != nil {
+		return err
+	}
+
+	for elem := range nsxPolicySet {
+		if CRPolicySet.Has(elem) {
 			continue
 		}
-		policyList := &v1alpha1.SecurityPolicyList{}
-		err := r.Client.List(ctx, policyList)
-		if err != nil {
-			log.Error(err, "failed to list security policy CR")
+		if common.GCDryRun(MetricResType, elem) {
 			continue
 		}
-
-		CRPolicySet := sets.NewString()
-		for _, policy := range policyList.Items {
-			CRPolicySet.Insert(string(policy.UID))
-		}
-
-		for elem := range nsxPolicySet {
-			if CRPolicySet.Has(elem) {
-				continue
-			}
-			log.V(1).Info("GC collected SecurityPolicy CR", "UID", elem)
-			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
-			err = r.Service.DeleteSecurityPolicy(types.UID(elem))
-			if err != nil {
-				metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
-			} else {
-				metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
-			}
+		log.V(1).Info("GC collected SecurityPolicy CR", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		err = r.Service.DeleteSecurityPolicy(types.UID(elem))
+		if err != nil {
+			run.Deleted(err)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			run.Deleted(nil)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
 		}
 	}
+	return nil
 }
 
 // It is triggered by associated controller like pod, namespace, etc.
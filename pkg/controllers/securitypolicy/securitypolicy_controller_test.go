@@ -26,6 +26,7 @@ import (
 
 	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
 	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	commonctl "github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
 	mock_client "github.com/vmware-tanzu/nsx-operator/pkg/mock/controller-runtime/client"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
 	_ "github.com/vmware-tanzu/nsx-operator/pkg/nsx/ratelimiter"
@@ -227,7 +228,6 @@ func TestSecurityPolicyReconciler_GarbageCollector(t *testing.T) {
 	patch.ApplyMethod(reflect.TypeOf(service), "DeleteSecurityPolicy", func(_ *securitypolicy.SecurityPolicyService, UID interface{}) error {
 		return nil
 	})
-	cancel := make(chan bool)
 	defer patch.Reset()
 	mockCtl := gomock.NewController(t)
 	k8sClient := mock_client.NewMockClient(mockCtl)
@@ -246,11 +246,7 @@ func TestSecurityPolicyReconciler_GarbageCollector(t *testing.T) {
 		a.Items[0].UID = "1234"
 		return nil
 	})
-	go func() {
-		time.Sleep(1 * time.Second)
-		cancel <- true
-	}()
-	r.GarbageCollector(cancel, time.Second)
+	commonctl.NewGarbageCollector(MetricResType, common.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(ctx)
 
 	// local store has same item as k8s cache
 	patch.Reset()
@@ -270,11 +266,7 @@ func TestSecurityPolicyReconciler_GarbageCollector(t *testing.T) {
 		a.Items[0].UID = "1234"
 		return nil
 	})
-	go func() {
-		time.Sleep(1 * time.Second)
-		cancel <- true
-	}()
-	r.GarbageCollector(cancel, time.Second)
+	commonctl.NewGarbageCollector(MetricResType, common.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(ctx)
 
 	// local store has no item
 	patch.Reset()
@@ -287,17 +279,17 @@ func TestSecurityPolicyReconciler_GarbageCollector(t *testing.T) {
 		return nil
 	})
 	k8sClient.EXPECT().List(ctx, policyList).Return(nil).Times(0)
-	go func() {
-		time.Sleep(1 * time.Second)
-		cancel <- true
-	}()
-	r.GarbageCollector(cancel, time.Second)
+	commonctl.NewGarbageCollector(MetricResType, common.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(ctx)
 }
 
 func TestSecurityPolicyReconciler_Start(t *testing.T) {
 	mockCtl := gomock.NewController(t)
 	k8sClient := mock_client.NewMockClient(mockCtl)
-	service := &securitypolicy.SecurityPolicyService{}
+	service := &securitypolicy.SecurityPolicyService{
+		Service: common.Service{
+			NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{}},
+		},
+	}
 	var mgr controllerruntime.Manager
 	r := &SecurityPolicyReconciler{
 		Client:  k8sClient,
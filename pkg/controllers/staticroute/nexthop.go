@@ -0,0 +1,87 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package staticroute
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+)
+
+const (
+	ReasonNextHopUnreachable = "NextHopUnreachable"
+	ReasonNextHopsForwarding = "NextHopsForwarding"
+)
+
+// NextHopMonitor checks the next hops of the realized StaticRoutes periodically, so that the NextHopUnreachable
+// condition follows the forwarding state in NSX.
+// cancel is used to break the loop during UT
+func (r *StaticRouteReconciler) NextHopMonitor(cancel chan bool, interval time.Duration) {
+	ctx := context.Background()
+	log.Info("next hop monitor started")
+	for {
+		select {
+		case <-cancel:
+			return
+		case <-time.After(interval):
+		}
+		staticRouteList := &v1alpha1.StaticRouteList{}
+		if err := r.Client.List(ctx, staticRouteList); err != nil {
+			log.Error(err, "failed to list StaticRoute CR")
+			continue
+		}
+		for i := range staticRouteList.Items {
+			obj := &staticRouteList.Items[i]
+			if !obj.DeletionTimestamp.IsZero() || !isReady(obj) || !common.OwnsNamespace(obj.Namespace) {
+				continue
+			}
+			r.updateStaticRouteStatusConditions(&ctx, obj, r.checkNextHops(obj))
+		}
+	}
+}
+
+// checkNextHops returns the NextHopUnreachable condition of the realized StaticRoute, or nothing if NSX fails to
+// report the state of the next hops so that the current condition is kept.
+func (r *StaticRouteReconciler) checkNextHops(obj *v1alpha1.StaticRoute) []v1alpha1.StaticRouteCondition {
+	service, err := r.serviceFor(obj.Namespace)
+	if err != nil {
+		log.Error(err, "failed to select NSX manager", "staticroute", obj.Namespace+"/"+obj.Name)
+		return nil
+	}
+	unreachable, err := service.GetUnreachableNextHops(obj.UID)
+	if err != nil {
+		log.Error(err, "failed to check next hops", "staticroute", obj.Namespace+"/"+obj.Name)
+		return nil
+	}
+	if len(unreachable) > 0 {
+		log.Info("next hops of StaticRoute are not forwarding", "staticroute", obj.Namespace+"/"+obj.Name, "nextHops", unreachable)
+		return []v1alpha1.StaticRouteCondition{{
+			Type:    v1alpha1.NextHopUnreachable,
+			Status:  v1.ConditionTrue,
+			Reason:  ReasonNextHopUnreachable,
+			Message: fmt.Sprintf("next hops %s are not forwarding", strings.Join(unreachable, ", ")),
+		}}
+	}
+	return []v1alpha1.StaticRouteCondition{{
+		Type:    v1alpha1.NextHopUnreachable,
+		Status:  v1.ConditionFalse,
+		Reason:  ReasonNextHopsForwarding,
+		Message: "no next hop is reported unreachable by NSX",
+	}}
+}
+
+func isReady(obj *v1alpha1.StaticRoute) bool {
+	for _, condition := range obj.Status.Conditions {
+		if condition.Type == v1alpha1.Ready {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package staticroute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+)
+
+func TestStaticRouteReconciler_NextHopMonitor(t *testing.T) {
+	staticRouteCR := newStaticRouteCR("route1", "uid1")
+	staticRouteCR.Spec.NextHops = append(staticRouteCR.Spec.NextHops, v1alpha1.NextHop{IPAddress: "192.168.1.2"})
+	staticRouteCR.Status.Conditions = []v1alpha1.StaticRouteCondition{{Type: v1alpha1.Ready, Status: v1.ConditionTrue}}
+	notReadyCR := newStaticRouteCR("route2", "uid2")
+	r, _, _ := newFakeStaticRouteReconciler(t, "t1", staticRouteCR, notReadyCR)
+	_, err := r.Service.CreateOrUpdateStaticRoute(context.TODO(), staticRouteCR, nil)
+	assert.Nil(t, err)
+	_, err = r.Service.CreateOrUpdateStaticRoute(context.TODO(), notReadyCR, nil)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+	cancel := make(chan bool)
+	go r.NextHopMonitor(cancel, 10*time.Millisecond)
+	defer close(cancel)
+	obj := &v1alpha1.StaticRoute{}
+	assert.Eventually(t, func() bool {
+		assert.Nil(t, r.Client.Get(ctx, types.NamespacedName{Namespace: "ns1", Name: "route1"}, obj))
+		return len(obj.Status.Conditions) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, v1alpha1.NextHopUnreachable, obj.Status.Conditions[1].Type)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[1].Status)
+	assert.Equal(t, "next hops 192.168.1.2 are not forwarding", obj.Status.Conditions[1].Message)
+
+	assert.Nil(t, r.Client.Get(ctx, types.NamespacedName{Namespace: "ns1", Name: "route2"}, obj))
+	assert.Empty(t, obj.Status.Conditions)
+}
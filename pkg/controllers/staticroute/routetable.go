@@ -0,0 +1,82 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package staticroute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/tabwriter"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// RouteTablePath is the path the effective routing table of the gateway of a Namespace is served at on the metrics
+// server, e.g. /debug/routes?namespace=ns1, with output=json for the JSON format.
+const RouteTablePath = "/debug/routes"
+
+// ServeHTTP renders the effective routing table NSX reports for the gateway of the Namespace, so that the users can
+// troubleshoot the connectivity without access to NSX.
+func (r *StaticRouteReconciler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	namespace := req.URL.Query().Get("namespace")
+	if namespace == "" {
+		http.Error(w, "namespace is required", http.StatusBadRequest)
+		return
+	}
+	service, err := r.serviceFor(namespace)
+	if err != nil {
+		log.Error(err, "failed to select NSX manager", "namespace", namespace)
+		http.Error(w, fmt.Sprintf("failed to select NSX manager for Namespace %s: %v", namespace, err), http.StatusInternalServerError)
+		return
+	}
+	var nsxVPC *model.Vpc
+	if service == r.Service && r.Service.NSXConfig.EnableVPCNetwork {
+		if vpcs := common.ServiceMediator.GetVPCsByNamespace(namespace); len(vpcs) > 0 {
+			nsxVPC = &vpcs[0]
+		}
+	}
+	tables, err := service.GetRouteTables(nsxVPC)
+	if err != nil {
+		if errors.As(err, &nsxutil.RestrictionError{}) {
+			http.Error(w, fmt.Sprintf("no gateway found for Namespace %s", namespace), http.StatusNotFound)
+			return
+		}
+		log.Error(err, "failed to get routing table", "namespace", namespace)
+		http.Error(w, fmt.Sprintf("failed to get routing table from NSX: %v", err), http.StatusBadGateway)
+		return
+	}
+	if req.URL.Query().Get("output") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(tables); err != nil {
+			log.Error(err, "failed to write routing table", "namespace", namespace)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for i, table := range tables {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "Gateway: %s\n", table.Gateway)
+		if table.EdgeNode != "" {
+			fmt.Fprintf(tw, "Edge node: %s\n", table.EdgeNode)
+		}
+		if table.Error != "" {
+			fmt.Fprintf(tw, "Error: %s\n", table.Error)
+			continue
+		}
+		fmt.Fprintln(tw, "NETWORK\tNEXT HOP\tTYPE\tADMIN DISTANCE")
+		for _, route := range table.Routes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", route.Network, route.NextHop, route.RouteType, route.AdminDistance)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		log.Error(err, "failed to write routing table", "namespace", namespace)
+	}
+}
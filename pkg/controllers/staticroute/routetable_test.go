@@ -0,0 +1,40 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package staticroute
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/staticroute"
+)
+
+func TestStaticRouteReconciler_ServeHTTP(t *testing.T) {
+	r, _, _ := newFakeStaticRouteReconciler(t, "t1")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, RouteTablePath, nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, RouteTablePath+"?namespace=ns1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Gateway: /infra/tier-1s/t1\nNETWORK  NEXT HOP     TYPE  ADMIN DISTANCE\n         192.168.1.1        0\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, RouteTablePath+"?namespace=ns1&output=json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var tables []staticroute.RouteTable
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &tables))
+	assert.Equal(t, []staticroute.RouteTable{{Gateway: "/infra/tier-1s/t1", Routes: []staticroute.RouteEntry{{NextHop: "192.168.1.1"}}}}, tables)
+
+	r.Service.NSXConfig.Tier1Gateway = ""
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, RouteTablePath+"?namespace=ns1", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,400 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package staticroute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/staticroute"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	ResultRequeueAfter10sec          = common.ResultRequeueAfter10sec
+	MetricResType                    = common.MetricResTypeStaticRoute
+)
+
+// StaticRouteReconciler programs the NSX static route of a StaticRoute CR on the NSX VPC of its Namespace, or on the
+// configured Tier-1 gateway if VPC networking is not enabled. The routes of the Namespaces selected by an additional
+// NSX manager are programmed by the service of that NSX manager.
+type StaticRouteReconciler struct {
+	Client  client.Client
+	Scheme  *apimachineryruntime.Scheme
+	Service *staticroute.StaticRouteService
+	// NSXManagerServices are the services of the additional NSX managers, keyed by NSX manager name
+	NSXManagerServices map[string]*staticroute.StaticRouteService
+}
+
+// serviceFor returns the service of the NSX manager serving the Namespace.
+func (r *StaticRouteReconciler) serviceFor(namespace string) (*staticroute.StaticRouteService, error) {
+	name, err := r.Service.SelectNSXManager(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if service, ok := r.NSXManagerServices[name]; ok {
+		return service, nil
+	}
+	return r.Service, nil
+}
+
+// services returns the services of all the NSX managers, the default one first.
+func (r *StaticRouteReconciler) services() []*staticroute.StaticRouteService {
+	names := make([]string, 0, len(r.NSXManagerServices))
+	for name := range r.NSXManagerServices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	services := []*staticroute.StaticRouteService{r.Service}
+	for _, name := range names {
+		services = append(services, r.NSXManagerServices[name])
+	}
+	return services
+}
+
+// listStaticRouteCRUID returns the UIDs of the StaticRoute CRs which have NSX static routes on any NSX manager.
+func (r *StaticRouteReconciler) listStaticRouteCRUID() sets.String {
+	uids := sets.NewString()
+	for _, service := range r.services() {
+		uids = uids.Union(service.ListStaticRouteCRUID())
+	}
+	return uids
+}
+
+func updateFail(r *StaticRouteReconciler, c *context.Context, o *v1alpha1.StaticRoute, e *error) {
+	r.setStaticRouteReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while processing the StaticRoute CR. Error: %v", *e))
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+}
+
+func deleteFail(r *StaticRouteReconciler, c *context.Context, o *v1alpha1.StaticRoute, e *error) {
+	r.setStaticRouteReadyStatusFalse(c, o, common.ErrorReason(*e), fmt.Sprintf("error occurred while deleting the StaticRoute CR. Error: %v", *e))
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+}
+
+func updateSuccess(r *StaticRouteReconciler, c *context.Context, o *v1alpha1.StaticRoute) {
+	r.setStaticRouteReadyStatusTrue(c, o, r.checkNextHops(o)...)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+}
+
+func deleteSuccess(r *StaticRouteReconciler, _ *context.Context, _ *v1alpha1.StaticRoute) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+}
+
+func (r *StaticRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.StaticRoute{}
+	log.Info("reconciling StaticRoute CR", "staticroute", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch StaticRoute CR", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "staticroute", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "staticroute", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.StaticRouteFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.StaticRouteFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "staticroute", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on StaticRoute CR", "staticroute", req.NamespacedName)
+		}
+
+		service, err := r.serviceFor(req.Namespace)
+		if err != nil {
+			log.Error(err, "failed to select NSX manager for StaticRoute CR, would retry exponentially", "staticroute", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return ResultRequeue, err
+		}
+
+		var nsxVPC *model.Vpc
+		// the routes of the Tier1 and Tier0 scopes are not programmed on the VPC, and the VPCs are only managed on
+		// the default NSX manager
+		if service == r.Service && r.Service.NSXConfig.EnableVPCNetwork && (obj.Spec.Scope == "" || obj.Spec.Scope == v1alpha1.StaticRouteScopeVPC) {
+			vpcs := common.ServiceMediator.GetVPCsByNamespace(req.Namespace)
+			if len(vpcs) == 0 {
+				err := fmt.Errorf("no NSX VPC found in Namespace %s", req.Namespace)
+				log.Error(err, "failed to find VPC for StaticRoute CR, would retry exponentially", "staticroute", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			nsxVPC = &vpcs[0]
+		}
+
+		// the route moves to another NSX manager once the Namespace is selected by it
+		for _, other := range r.services() {
+			if other == service {
+				continue
+			}
+			if err := other.DeleteStaticRouteByCRUID(obj.UID); err != nil {
+				log.Error(err, "failed to delete NSX static route from previous NSX manager, would retry exponentially", "staticroute", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return common.ResultForError(err)
+			}
+		}
+
+		realized, err := service.CreateOrUpdateStaticRoute(ctx, obj, nsxVPC)
+		if err != nil {
+			if errors.As(err, &nsxutil.RestrictionError{}) {
+				log.Error(err, err.Error(), "staticroute", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultNormal, nil
+			}
+			log.Error(err, "operate failed, would retry exponentially", "staticroute", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return common.ResultForError(err)
+		}
+		if !realized {
+			log.Info("NSX StaticRoute is not realized yet, would check again", "staticroute", req.NamespacedName)
+			r.setStaticRouteReadyStatusFalse(&ctx, obj, common.ReasonNotRealized, "NSX StaticRoute is not realized yet")
+			return ResultRequeueAfter10sec, nil
+		}
+		updateSuccess(r, &ctx, obj)
+	} else {
+		if controllerutil.ContainsFinalizer(obj, servicecommon.StaticRouteFinalizerName) {
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+			for _, service := range r.services() {
+				if err := service.DeleteStaticRouteByCRUID(obj.UID); err != nil {
+					log.Error(err, "deletion failed, would retry exponentially", "staticroute", req.NamespacedName)
+					deleteFail(r, &ctx, obj, &err)
+					return common.ResultForError(err)
+				}
+			}
+			controllerutil.RemoveFinalizer(obj, servicecommon.StaticRouteFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "staticroute", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("removed finalizer", "staticroute", req.NamespacedName)
+			deleteSuccess(r, &ctx, obj)
+		} else {
+			// only print a message because it's not a normal case
+			log.Info("finalizers cannot be recognized", "staticroute", req.NamespacedName)
+		}
+	}
+
+	return ResultNormal, nil
+}
+
+func (r *StaticRouteReconciler) setStaticRouteReadyStatusTrue(ctx *context.Context, obj *v1alpha1.StaticRoute, conditions ...v1alpha1.StaticRouteCondition) {
+	newConditions := []v1alpha1.StaticRouteCondition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionTrue,
+			Message: "NSX StaticRoute has been successfully created/updated",
+			Reason:  "NSX API returned 200 response code for PATCH",
+		},
+	}
+	newConditions = append(newConditions, conditions...)
+	r.updateStaticRouteStatusConditions(ctx, obj, newConditions)
+}
+
+func (r *StaticRouteReconciler) setStaticRouteReadyStatusFalse(ctx *context.Context, obj *v1alpha1.StaticRoute, reason, message string) {
+	newConditions := []v1alpha1.StaticRouteCondition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionFalse,
+			Message: message,
+			Reason:  reason,
+		},
+	}
+	r.updateStaticRouteStatusConditions(ctx, obj, newConditions)
+}
+
+func (r *StaticRouteReconciler) updateStaticRouteStatusConditions(ctx *context.Context, obj *v1alpha1.StaticRoute, newConditions []v1alpha1.StaticRouteCondition) {
+	conditionsUpdated := false
+	for i := range newConditions {
+		if mergeStaticRouteStatusCondition(obj, &newConditions[i]) {
+			conditionsUpdated = true
+		}
+	}
+	if conditionsUpdated {
+		r.Client.Status().Update(*ctx, obj)
+		log.V(1).Info("updated StaticRoute", "Name", obj.Name, "Namespace", obj.Namespace, "New Conditions", newConditions)
+	}
+}
+
+func mergeStaticRouteStatusCondition(obj *v1alpha1.StaticRoute, newCondition *v1alpha1.StaticRouteCondition) bool {
+	var matchedCondition *v1alpha1.StaticRouteCondition
+	for i := range obj.Status.Conditions {
+		if obj.Status.Conditions[i].Type == newCondition.Type {
+			matchedCondition = &obj.Status.Conditions[i]
+			break
+		}
+	}
+
+	if reflect.DeepEqual(matchedCondition, newCondition) {
+		log.V(2).Info("conditions already match", "New Condition", newCondition, "Existing Condition", matchedCondition)
+		return false
+	}
+
+	if matchedCondition != nil {
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		matchedCondition.Status = newCondition.Status
+	} else {
+		obj.Status.Conditions = append(obj.Status.Conditions, *newCondition)
+	}
+	return true
+}
+
+func (r *StaticRouteReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.StaticRoute{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerStaticRoute),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopeStaticRouteCRName, func() client.Object { return &v1alpha1.StaticRoute{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.StaticRouteList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.StaticRoute{}, r)
+}
+
+// Start setup manager and launch GC
+func (r *StaticRouteReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	if err := mgr.AddMetricsExtraHandler(RouteTablePath, r); err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	go common.ReportDrift(make(chan bool), servicecommon.DriftInterval, r.Service.NSXConfig, common.DriftSource{
+		ResourceType: MetricResType,
+		Client:       r.Client,
+		List:         &v1alpha1.StaticRouteList{},
+		NSXUIDs:      r.listStaticRouteCRUID,
+	})
+	common.RunWhenElected(mgr.Elected(), func() { r.NextHopMonitor(make(chan bool), servicecommon.NextHopCheckInterval) })
+	return nil
+}
+
+// collectGarbage deletes the NSX static routes whose StaticRoute CRs have been removed on all the NSX managers.
+func (r *StaticRouteReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	staticRouteList := &v1alpha1.StaticRouteList{}
+	if err := r.Client.List(ctx, staticRouteList); err != nil {
+		log.Error(err, "failed to list StaticRoute CR")
+		return err
+	}
+	CRStaticRouteSet := sets.NewString()
+	for _, obj := range staticRouteList.Items {
+		CRStaticRouteSet.Insert(string(obj.UID))
+	}
+	// the garbage collection of the other NSX managers goes on if the static routes of one fail to be listed or too
+	// many of them would be deleted
+	var runErr error
+	for _, service := range r.services() {
+		nsxStaticRouteSet, err := service.ListNSXStaticRouteCRUID()
+		if err != nil {
+			log.Error(err, "failed to list NSX static routes")
+			runErr = err
+			continue
+		}
+		run.Scanned(len(nsxStaticRouteSet))
+		if len(nsxStaticRouteSet) == 0 {
+			continue
+		}
+		run.Orphaned(servicecommon.TagScopeStaticRouteCRUID, nsxStaticRouteSet.Difference(CRStaticRouteSet))
+		if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxStaticRouteSet, CRStaticRouteSet); err != nil {
+			runErr = err
+			continue
+		}
+		gcSuccessCount, gcErrorCount := r.garbageCollector(service, nsxStaticRouteSet, staticRouteList)
+		log.V(1).Info("gc collects StaticRoute CR", "success", gcSuccessCount, "error", gcErrorCount)
+		run.Collected(gcSuccessCount, gcErrorCount)
+	}
+	return runErr
+}
+
+// garbageCollector deletes the NSX static routes of the service of the UIDs in nsxStaticRouteSet which are not the
+// UIDs of the StaticRoute CRs in staticRouteList.
+func (r *StaticRouteReconciler) garbageCollector(service *staticroute.StaticRouteService, nsxStaticRouteSet sets.String, staticRouteList *v1alpha1.StaticRouteList) (gcSuccessCount, gcErrorCount uint32) {
+	CRStaticRouteSet := sets.NewString()
+	for _, obj := range staticRouteList.Items {
+		CRStaticRouteSet.Insert(string(obj.UID))
+	}
+
+	for elem := range nsxStaticRouteSet {
+		if CRStaticRouteSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		log.V(1).Info("GC collected StaticRoute CR", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := service.DeleteStaticRouteByCRUID(types.UID(elem)); err != nil {
+			log.Error(err, "failed to delete NSX static route", "UID", elem)
+			gcErrorCount++
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			gcSuccessCount++
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+		}
+	}
+	return
+}
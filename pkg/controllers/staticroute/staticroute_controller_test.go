@@ -0,0 +1,259 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package staticroute
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	infra_realized_state "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/realized_state"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_1s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/staticroute"
+)
+
+type fakeQueryClient struct{}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	count := int64(0)
+	return model.SearchResponse{ResultCount: &count}, nil
+}
+
+type fakeTier1StaticRouteClient struct {
+	tier_1s.StaticRoutesClient
+	routes    map[string]bool
+	deleteErr error
+}
+
+func (c *fakeTier1StaticRouteClient) Patch(_ string, id string, _ model.StaticRoutes) error {
+	c.routes[id] = true
+	return nil
+}
+
+func (c *fakeTier1StaticRouteClient) Delete(_ string, id string) error {
+	if c.deleteErr != nil {
+		return c.deleteErr
+	}
+	delete(c.routes, id)
+	return nil
+}
+
+// fakeForwardingTableClient has the routes through the next hops in forwarding.
+type fakeForwardingTableClient struct {
+	tier_1s.ForwardingTableClient
+	forwarding []string
+}
+
+func (c *fakeForwardingTableClient) List(_ string, _ *string, _ *string, _ *string, _ *string, _ *string, network *string, _ *int64, _ *string, _ *bool, _ *string) (model.RoutingTableListResult, error) {
+	table := model.RoutingTable{Status: servicecommon.String(model.RoutingTable_STATUS_SUCCESS)}
+	for _, nextHop := range c.forwarding {
+		table.RouteEntries = append(table.RouteEntries, model.RoutingEntry{Network: network, NextHop: servicecommon.String(nextHop)})
+	}
+	return model.RoutingTableListResult{Results: []model.RoutingTable{table}}, nil
+}
+
+// fakeRealizedEntitiesClient realizes the static routes once realized is set.
+type fakeRealizedEntitiesClient struct {
+	infra_realized_state.RealizedEntitiesClient
+	realized bool
+}
+
+func (c *fakeRealizedEntitiesClient) List(_ string, _ *string) (model.GenericPolicyRealizedResourceListResult, error) {
+	state := model.GenericPolicyRealizedResource_STATE_UNREALIZED
+	if c.realized {
+		state = model.GenericPolicyRealizedResource_STATE_REALIZED
+	}
+	return model.GenericPolicyRealizedResourceListResult{Results: []model.GenericPolicyRealizedResource{{
+		State: servicecommon.String(state),
+	}}}, nil
+}
+
+func newFakeStaticRouteReconciler(t *testing.T, tier1 string, objs ...apimachineryruntime.Object) (*StaticRouteReconciler, *fakeTier1StaticRouteClient, *fakeRealizedEntitiesClient) {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{Tier1Gateway: tier1},
+	}
+	tier1Client := &fakeTier1StaticRouteClient{routes: map[string]bool{}}
+	realizedClient := &fakeRealizedEntitiesClient{}
+	nsxClient := &nsx.Client{
+		NsxConfig:                   nsxConfig,
+		QueryClient:                 &fakeQueryClient{},
+		Tier1StaticRouteClient:      tier1Client,
+		Tier1ForwardingTableClient:  &fakeForwardingTableClient{forwarding: []string{"192.168.1.1"}},
+		InfraRealizedEntitiesClient: realizedClient,
+	}
+	service, err := staticroute.InitializeStaticRoute(servicecommon.Service{NSXClient: nsxClient, NSXConfig: nsxConfig})
+	assert.Nil(t, err)
+	return &StaticRouteReconciler{
+		Client:  fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:  scheme,
+		Service: service,
+	}, tier1Client, realizedClient
+}
+
+func newStaticRouteCR(name string, uid types.UID) *v1alpha1.StaticRoute {
+	return &v1alpha1.StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1", UID: uid},
+		Spec: v1alpha1.StaticRouteSpec{
+			Network:  "10.10.0.0/16",
+			NextHops: []v1alpha1.NextHop{{IPAddress: "192.168.1.1"}},
+		},
+	}
+}
+
+func TestStaticRouteReconciler_Reconcile(t *testing.T) {
+	r, tier1Client, realizedClient := newFakeStaticRouteReconciler(t, "t1", newStaticRouteCR("route1", "uid1"))
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "route1"}}
+
+	// the status waits for the static route to be realized
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultRequeueAfter10sec, result)
+	assert.True(t, tier1Client.routes["sr_uid1"])
+	obj := &v1alpha1.StaticRoute{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.StaticRouteFinalizerName)
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+
+	realizedClient.realized = true
+	result, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+	assert.Equal(t, v1alpha1.NextHopUnreachable, obj.Status.Conditions[1].Type)
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[1].Status)
+
+	// the NSX static route is deleted with the StaticRoute CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Empty(t, tier1Client.routes)
+	assert.Empty(t, r.Service.ListStaticRouteCRUID())
+	err = r.Client.Get(ctx, req.NamespacedName, obj)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestStaticRouteReconciler_ReconcileWithoutGateway(t *testing.T) {
+	r, tier1Client, _ := newFakeStaticRouteReconciler(t, "", newStaticRouteCR("route1", "uid1"))
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "route1"}}
+
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Empty(t, tier1Client.routes)
+	obj := &v1alpha1.StaticRoute{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+	assert.Contains(t, obj.Status.Conditions[0].Message, "no NSX VPC or Tier-1 gateway found")
+}
+
+func TestStaticRouteReconciler_ReconcileTier1Scope(t *testing.T) {
+	obj := newStaticRouteCR("route1", "uid1")
+	obj.Spec.Scope = v1alpha1.StaticRouteScopeTier1
+	obj.Spec.Gateway = "t1-shared"
+	r, tier1Client, realizedClient := newFakeStaticRouteReconciler(t, "", obj)
+	// the routes of the Tier1 scope do not need the VPC of the Namespace
+	r.Service.NSXConfig.EnableVPCNetwork = true
+	realizedClient.realized = true
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "route1"}}
+
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.True(t, tier1Client.routes["sr_uid1"])
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+}
+
+func TestStaticRouteReconciler_ReconcileNSXManagers(t *testing.T) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"site": "b"}}}
+	r, tier1Client, realizedClient := newFakeStaticRouteReconciler(t, "t1", ns, newStaticRouteCR("route1", "uid1"))
+	siteB, siteBTier1Client, siteBRealizedClient := newFakeStaticRouteReconciler(t, "t1-b")
+	r.Service.Client = r.Client
+	r.Service.NSXConfig.NSXManagers = map[string]*config.NSXManagerConfig{"site-b": {Name: "site-b", NamespaceSelector: "site=b"}}
+	r.NSXManagerServices = map[string]*staticroute.StaticRouteService{"site-b": siteB.Service}
+	realizedClient.realized = true
+	siteBRealizedClient.realized = true
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "route1"}}
+
+	// the route is programmed by the NSX manager selecting the Namespace
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.True(t, siteBTier1Client.routes["sr_uid1"])
+	assert.Empty(t, tier1Client.routes)
+
+	// the route moves to the default NSX manager once the Namespace is no longer selected
+	ns.Labels["site"] = "a"
+	assert.Nil(t, r.Client.Update(ctx, ns))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.True(t, tier1Client.routes["sr_uid1"])
+	assert.Empty(t, siteBTier1Client.routes)
+
+	obj := &v1alpha1.StaticRoute{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Empty(t, tier1Client.routes)
+	assert.Empty(t, siteB.Service.ListStaticRouteCRUID())
+}
+
+func TestStaticRouteReconciler_GarbageCollector(t *testing.T) {
+	staticRouteCR := newStaticRouteCR("route1", "uid1")
+	r, tier1Client, _ := newFakeStaticRouteReconciler(t, "t1", staticRouteCR)
+	_, err := r.Service.CreateOrUpdateStaticRoute(context.TODO(), staticRouteCR, nil)
+	assert.Nil(t, err)
+	_, err = r.Service.CreateOrUpdateStaticRoute(context.TODO(), newStaticRouteCR("route2", "uid2"), nil)
+	assert.Nil(t, err)
+
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	assert.Equal(t, []string{"uid1"}, r.Service.ListStaticRouteCRUID().List())
+	assert.True(t, tier1Client.routes["sr_uid1"])
+}
+
+func TestStaticRouteReconciler_garbageCollector(t *testing.T) {
+	r, tier1Client, _ := newFakeStaticRouteReconciler(t, "t1")
+	for _, uid := range []types.UID{"uid1", "uid2"} {
+		_, err := r.Service.CreateOrUpdateStaticRoute(context.TODO(), newStaticRouteCR(string(uid), uid), nil)
+		assert.Nil(t, err)
+	}
+	staticRouteList := &v1alpha1.StaticRouteList{Items: []v1alpha1.StaticRoute{*newStaticRouteCR("uid1", "uid1")}}
+
+	tier1Client.deleteErr = fmt.Errorf("connection refused")
+	gcSuccessCount, gcErrorCount := r.garbageCollector(r.Service, r.Service.ListStaticRouteCRUID(), staticRouteList)
+	assert.Equal(t, uint32(0), gcSuccessCount)
+	assert.Equal(t, uint32(1), gcErrorCount)
+
+	tier1Client.deleteErr = nil
+	gcSuccessCount, gcErrorCount = r.garbageCollector(r.Service, r.Service.ListStaticRouteCRUID(), staticRouteList)
+	assert.Equal(t, uint32(1), gcSuccessCount)
+	assert.Equal(t, uint32(0), gcErrorCount)
+	assert.Equal(t, []string{"uid1"}, r.Service.ListStaticRouteCRUID().List())
+}
@@ -0,0 +1,92 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package subnet
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// IPUsageReporter syncs the IP usage of the Subnets periodically.
+// cancel is used to break the loop during UT
+func (r *SubnetReconciler) IPUsageReporter(cancel chan bool, interval time.Duration) {
+	ctx := context.Background()
+	log.Info("Subnet IP usage reporter started")
+	reported := sets.NewString()
+	for {
+		select {
+		case <-cancel:
+			return
+		case <-time.After(interval):
+		}
+		var err error
+		if reported, err = r.reportIPUsage(ctx, reported); err != nil {
+			log.Error(err, "failed to report the IP usage of the Subnets")
+		}
+	}
+}
+
+// reportIPUsage publishes the IP usage of the Subnets as metrics and in the status of the Subnet CRs. The metrics of
+// the Subnets reported last time but gone are removed. It returns the Subnets reported this time.
+func (r *SubnetReconciler) reportIPUsage(ctx context.Context, lastReported sets.String) (sets.String, error) {
+	subnetList := &v1alpha1.SubnetList{}
+	if err := r.Client.List(ctx, subnetList); err != nil {
+		return lastReported, err
+	}
+	cf := r.Service.NSXConfig
+	reported := sets.NewString()
+	for i := range subnetList.Items {
+		obj := &subnetList.Items[i]
+		if !common.OwnsNamespace(obj.Namespace) {
+			continue
+		}
+		key := types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}
+		nsxSubnets := r.Service.GetSubnetsByIndex(servicecommon.TagScopeSubnetCRUID, string(obj.UID))
+		if len(nsxSubnets) == 0 {
+			continue
+		}
+		usage, err := r.Service.GetSubnetIPUsage(&nsxSubnets[0])
+		if err != nil {
+			// keep the metrics of last time
+			log.Error(err, "failed to get Subnet IP usage", "subnet", key)
+			if lastReported.Has(key.String()) {
+				reported.Insert(key.String())
+			}
+			continue
+		}
+		if usage == nil {
+			continue
+		}
+		reported.Insert(key.String())
+		metrics.GaugeSet(cf, metrics.SubnetTotalIPs, float64(usage.Total), obj.Namespace, obj.Name)
+		metrics.GaugeSet(cf, metrics.SubnetUsedIPs, float64(usage.Used), obj.Namespace, obj.Name)
+		metrics.GaugeSet(cf, metrics.SubnetAvailableIPs, float64(usage.Available), obj.Namespace, obj.Name)
+		if reflect.DeepEqual(obj.Status.IPUsage, usage) {
+			continue
+		}
+		obj.Status.IPUsage = usage
+		if err := r.Client.Status().Update(ctx, obj); err != nil {
+			log.Error(err, "failed to update Subnet IP usage", "subnet", key)
+			continue
+		}
+		log.V(1).Info("updated Subnet IP usage", "subnet", key, "usage", usage)
+	}
+	for _, key := range lastReported.Difference(reported).List() {
+		namespace, name, _ := cache.SplitMetaNamespaceKey(key)
+		metrics.GaugeDelete(cf, metrics.SubnetTotalIPs, namespace, name)
+		metrics.GaugeDelete(cf, metrics.SubnetUsedIPs, namespace, name)
+		metrics.GaugeDelete(cf, metrics.SubnetAvailableIPs, namespace, name)
+	}
+	return reported, nil
+}
@@ -0,0 +1,76 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package subnet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type fakeIPPoolClient struct {
+	subnets.IpPoolsClient
+	// available IPs of the Subnets
+	available map[string]int64
+}
+
+func (c *fakeIPPoolClient) List(_ string, _ string, _ string, subnetID string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.IpAddressPoolListResult, error) {
+	available, ok := c.available[subnetID]
+	if !ok {
+		return model.IpAddressPoolListResult{}, nil
+	}
+	return model.IpAddressPoolListResult{Results: []model.IpAddressPool{
+		{PoolUsage: &model.PolicyPoolUsage{TotalIps: servicecommon.Int64(64), AvailableIps: servicecommon.Int64(available)}},
+	}}, nil
+}
+
+func TestSubnetReconciler_ReportIPUsage(t *testing.T) {
+	subnetCR1 := &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1", UID: "uid1"}}
+	subnetCR2 := &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet2", Namespace: "ns1", UID: "uid2"}}
+	r, _ := newFakeSubnetReconciler(t, subnetCR1, subnetCR2)
+	r.Service.NSXConfig.EnforcementPoint = "vmc-enforcementpoint"
+	ipPoolClient := &fakeIPPoolClient{available: map[string]int64{}}
+	r.Service.NSXClient.IPPoolClient = ipPoolClient
+	vpcPath := "/orgs/default/projects/p1/vpcs/vpc1"
+	for _, obj := range []*v1alpha1.Subnet{subnetCR1, subnetCR2} {
+		_, err := r.Service.CreateOrUpdateSubnet(obj, vpcPath)
+		assert.Nil(t, err)
+	}
+	ctx := context.Background()
+
+	// the usage is not reported by NSX yet
+	reported, err := r.reportIPUsage(ctx, sets.NewString())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, reported.Len())
+
+	ipPoolClient.available["subnet_uid1"] = 16
+	ipPoolClient.available["subnet_uid2"] = 64
+	reported, err = r.reportIPUsage(ctx, reported)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ns1/subnet1", "ns1/subnet2"}, reported.List())
+	obj := &v1alpha1.Subnet{}
+	assert.Nil(t, r.Client.Get(ctx, types.NamespacedName{Namespace: "ns1", Name: "subnet1"}, obj))
+	assert.Equal(t, &v1alpha1.SubnetIPUsage{Total: 64, Used: 48, Available: 16, Utilization: 75}, obj.Status.IPUsage)
+	assert.Equal(t, float64(48), testutil.ToFloat64(metrics.SubnetUsedIPs.WithLabelValues("ns1", "subnet1")))
+	assert.Equal(t, float64(16), testutil.ToFloat64(metrics.SubnetAvailableIPs.WithLabelValues("ns1", "subnet1")))
+
+	// the metrics of the deleted Subnet are removed
+	assert.Nil(t, r.Client.Get(ctx, types.NamespacedName{Namespace: "ns1", Name: "subnet2"}, obj))
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	reported, err = r.reportIPUsage(ctx, reported)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ns1/subnet1"}, reported.List())
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.SubnetTotalIPs))
+}
@@ -0,0 +1,335 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package subnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/subnet"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	MetricResType                    = common.MetricResTypeSubnet
+)
+
+// SubnetReconciler creates the NSX Subnet for a Subnet CR in the NSX VPC of its Namespace.
+type SubnetReconciler struct {
+	Client   client.Client
+	Scheme   *apimachineryruntime.Scheme
+	Service  *subnet.SubnetService
+	Recorder record.EventRecorder
+}
+
+// subnetStatus is the realized state of the NSX Subnet published in the status of the Subnet CR.
+type subnetStatus struct {
+	path                string
+	ipAddresses         []string
+	gatewayAddresses    []string
+	dhcpServerAddresses []string
+}
+
+func updateFail(r *SubnetReconciler, c *context.Context, o *v1alpha1.Subnet, e *error) {
+	r.setSubnetReadyStatusFalse(c, o, e)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+}
+
+func deleteFail(r *SubnetReconciler, c *context.Context, o *v1alpha1.Subnet, e *error) {
+	r.setSubnetReadyStatusFalse(c, o, e)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+}
+
+func updateSuccess(r *SubnetReconciler, c *context.Context, o *v1alpha1.Subnet, status *subnetStatus) {
+	r.setSubnetReadyStatusTrue(c, o, status)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+}
+
+func deleteSuccess(r *SubnetReconciler, _ *context.Context, _ *v1alpha1.Subnet) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+}
+
+func (r *SubnetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.Subnet{}
+	log.Info("reconciling Subnet CR", "subnet", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch Subnet CR", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "subnet", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "subnet", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.SubnetFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.SubnetFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "subnet", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on Subnet CR", "subnet", req.NamespacedName)
+		}
+
+		vpcs := common.ServiceMediator.GetVPCsByNamespace(req.Namespace)
+		if len(vpcs) == 0 {
+			err := fmt.Errorf("no NSX VPC found in Namespace %s", req.Namespace)
+			log.Error(err, "failed to find VPC for Subnet CR, would retry exponentially", "subnet", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return ResultRequeue, err
+		}
+
+		nsxSubnet, err := r.Service.CreateOrUpdateSubnet(obj, *vpcs[0].Path)
+		if err != nil {
+			if errors.As(err, &nsxutil.RestrictionError{}) {
+				log.Error(err, err.Error(), "subnet", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultNormal, nil
+			}
+			log.Error(err, "operate failed, would retry exponentially", "subnet", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return common.ResultForError(err)
+		}
+		gatewayAddresses, dhcpServerAddresses, err := r.Service.GetSubnetStatus(nsxSubnet)
+		if err != nil {
+			log.Error(err, "failed to get Subnet status, would retry exponentially", "subnet", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return common.ResultForError(err)
+		}
+		updateSuccess(r, &ctx, obj, &subnetStatus{
+			path:                *nsxSubnet.Path,
+			ipAddresses:         nsxSubnet.IpAddresses,
+			gatewayAddresses:    gatewayAddresses,
+			dhcpServerAddresses: dhcpServerAddresses,
+		})
+	} else {
+		if controllerutil.ContainsFinalizer(obj, servicecommon.SubnetFinalizerName) {
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+			if err := r.Service.DeleteSubnetsByCRUID(obj.UID); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "subnet", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return common.ResultForError(err)
+			}
+			controllerutil.RemoveFinalizer(obj, servicecommon.SubnetFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "subnet", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("removed finalizer", "subnet", req.NamespacedName)
+			deleteSuccess(r, &ctx, obj)
+		} else {
+			// only print a message because it's not a normal case
+			log.Info("finalizers cannot be recognized", "subnet", req.NamespacedName)
+		}
+	}
+
+	return ResultNormal, nil
+}
+
+func (r *SubnetReconciler) setSubnetReadyStatusTrue(ctx *context.Context, obj *v1alpha1.Subnet, status *subnetStatus) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionTrue,
+			Message: "NSX Subnet has been successfully created/updated",
+			Reason:  "NSX API returned 200 response code for PATCH",
+		},
+	}
+	newConditions = append(newConditions, common.ClearIPExhaustion(obj.Status.Conditions)...)
+	r.updateSubnetStatus(ctx, obj, newConditions, status)
+}
+
+func (r *SubnetReconciler) setSubnetReadyStatusFalse(ctx *context.Context, obj *v1alpha1.Subnet, err *error) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionFalse,
+			Message: fmt.Sprintf("NSX Subnet could not be created/updated, error occurred while processing the Subnet CR. Error: %v", *err),
+			Reason:  common.ErrorReason(*err),
+		},
+	}
+	newConditions = append(newConditions, common.ReportIPExhaustion(r.Recorder, r.Service.NSXConfig, obj, *err)...)
+	// the realized state is kept until the NSX Subnet is updated successfully
+	r.updateSubnetStatus(ctx, obj, newConditions, &subnetStatus{
+		path:                obj.Status.NSXResourcePath,
+		ipAddresses:         obj.Status.IPAddresses,
+		gatewayAddresses:    obj.Status.GatewayAddresses,
+		dhcpServerAddresses: obj.Status.DHCPServerAddresses,
+	})
+}
+
+func (r *SubnetReconciler) updateSubnetStatus(ctx *context.Context, obj *v1alpha1.Subnet, newConditions []v1alpha1.Condition, status *subnetStatus) {
+	updated := obj.Status.NSXResourcePath != status.path ||
+		!reflect.DeepEqual(obj.Status.IPAddresses, status.ipAddresses) ||
+		!reflect.DeepEqual(obj.Status.GatewayAddresses, status.gatewayAddresses) ||
+		!reflect.DeepEqual(obj.Status.DHCPServerAddresses, status.dhcpServerAddresses)
+	obj.Status.NSXResourcePath = status.path
+	obj.Status.IPAddresses = status.ipAddresses
+	obj.Status.GatewayAddresses = status.gatewayAddresses
+	obj.Status.DHCPServerAddresses = status.dhcpServerAddresses
+	for i := range newConditions {
+		if mergeSubnetStatusCondition(obj, &newConditions[i]) {
+			updated = true
+		}
+	}
+	if updated {
+		r.Client.Status().Update(*ctx, obj)
+		log.V(1).Info("updated Subnet", "Name", obj.Name, "Namespace", obj.Namespace,
+			"New Conditions", newConditions, "NSXResourcePath", status.path)
+	}
+}
+
+func mergeSubnetStatusCondition(obj *v1alpha1.Subnet, newCondition *v1alpha1.Condition) bool {
+	var matchedCondition *v1alpha1.Condition
+	for i := range obj.Status.Conditions {
+		if obj.Status.Conditions[i].Type == newCondition.Type {
+			matchedCondition = &obj.Status.Conditions[i]
+			break
+		}
+	}
+
+	if reflect.DeepEqual(matchedCondition, newCondition) {
+		log.V(2).Info("conditions already match", "New Condition", newCondition, "Existing Condition", matchedCondition)
+		return false
+	}
+
+	if matchedCondition != nil {
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		matchedCondition.Status = newCondition.Status
+	} else {
+		obj.Status.Conditions = append(obj.Status.Conditions, *newCondition)
+	}
+	return true
+}
+
+func (r *SubnetReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.Subnet{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerSubnet),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopeSubnetCRName, func() client.Object { return &v1alpha1.Subnet{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.SubnetList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.Subnet{}, r)
+}
+
+// Start setup manager and launch GC
+func (r *SubnetReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	go common.ReportDrift(make(chan bool), servicecommon.DriftInterval, r.Service.NSXConfig, common.DriftSource{
+		ResourceType: MetricResType,
+		Client:       r.Client,
+		List:         &v1alpha1.SubnetList{},
+		NSXUIDs:      r.Service.ListSubnetCRUID,
+	})
+	common.RunWhenElected(mgr.Elected(), func() { r.IPUsageReporter(make(chan bool), servicecommon.SubnetIPUsageInterval) })
+	return nil
+}
+
+// collectGarbage deletes NSX Subnets whose Subnet CRs have been removed.
+func (r *SubnetReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	nsxSubnetSet := r.Service.ListSubnetCRUID()
+	run.Scanned(len(nsxSubnetSet))
+	if len(nsxSubnetSet) == 0 {
+		return nil
+	}
+	subnetList := &v1alpha1.SubnetList{}
+	if err := r.Client.List(ctx, subnetList); err != nil {
+		log.Error(err, "failed to list Subnet CR")
+		return err
+	}
+
+	CRSubnetSet := sets.NewString()
+	for _, obj := range subnetList.Items {
+		CRSubnetSet.Insert(string(obj.UID))
+	}
+
+	run.Orphaned(servicecommon.TagScopeSubnetCRUID, nsxSubnetSet.Difference(CRSubnetSet))
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxSubnetSet, CRSubnetSet); err != nil {
+		return err
+	}
+
+	for elem := range nsxSubnetSet {
+		if CRSubnetSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		log.V(1).Info("GC collected Subnet CR", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.Service.DeleteSubnetsByCRUID(types.UID(elem)); err != nil {
+			run.Deleted(err)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			run.Deleted(nil)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+		}
+	}
+	return nil
+}
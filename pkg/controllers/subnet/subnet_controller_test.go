@@ -0,0 +1,199 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package subnet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/subnet"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+)
+
+type fakeQueryClient struct{}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	count := int64(0)
+	return model.SearchResponse{ResultCount: &count}, nil
+}
+
+type fakeVPCClient struct {
+	projects.VpcsClient
+}
+
+func (c *fakeVPCClient) Patch(_ string, _ string, _ string, _ model.Vpc) error {
+	return nil
+}
+
+type fakeSecurityPolicyClient struct {
+	vpcs.SecurityPoliciesClient
+}
+
+func (c *fakeSecurityPolicyClient) Patch(_ string, _ string, _ string, _ string, _ model.SecurityPolicy) error {
+	return nil
+}
+
+func (c *fakeSecurityPolicyClient) Delete(_ string, _ string, _ string, _ string) error {
+	return nil
+}
+
+type fakeSubnetsClient struct {
+	vpcs.SubnetsClient
+	subnets map[string]model.VpcSubnet
+}
+
+func (c *fakeSubnetsClient) Patch(_ string, _ string, _ string, id string, subnet model.VpcSubnet) error {
+	subnet.IpAddresses = []string{"10.0.0.0/26"}
+	c.subnets[id] = subnet
+	return nil
+}
+
+func (c *fakeSubnetsClient) Get(_ string, _ string, _ string, id string) (model.VpcSubnet, error) {
+	return c.subnets[id], nil
+}
+
+func (c *fakeSubnetsClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.subnets, id)
+	return nil
+}
+
+type fakeSubnetStatusClient struct {
+	subnets.StatusClient
+	subnetsClient *fakeSubnetsClient
+}
+
+func (c *fakeSubnetStatusClient) List(_ string, _ string, _ string, id string) (model.VpcSubnetStatusListResult, error) {
+	status := model.VpcSubnetStatus{GatewayAddress: servicecommon.String("10.0.0.1/26")}
+	if dhcpConfig := c.subnetsClient.subnets[id].DhcpConfig; dhcpConfig != nil && *dhcpConfig.EnableDhcp {
+		status.DhcpServerAddress = servicecommon.String("10.0.0.2/26")
+	}
+	return model.VpcSubnetStatusListResult{Results: []model.VpcSubnetStatus{status}}, nil
+}
+
+func newFakeSubnetReconciler(t *testing.T, objs ...apimachineryruntime.Object) (*SubnetReconciler, *fakeSubnetsClient) {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{},
+	}
+	subnetsClient := &fakeSubnetsClient{subnets: map[string]model.VpcSubnet{}}
+	nsxClient := &nsx.Client{
+		NsxConfig:               nsxConfig,
+		QueryClient:             &fakeQueryClient{},
+		VPCClient:               &fakeVPCClient{},
+		VPCSecurityPolicyClient: &fakeSecurityPolicyClient{},
+		VPCSubnetClient:         subnetsClient,
+		SubnetStatusClient:      &fakeSubnetStatusClient{subnetsClient: subnetsClient},
+	}
+	commonService := servicecommon.Service{NSXClient: nsxClient, NSXConfig: nsxConfig}
+	vpcService, err := vpc.InitializeVPC(commonService)
+	assert.Nil(t, err)
+	common.ServiceMediator.VPCService = vpcService
+	service, err := subnet.InitializeSubnetService(commonService)
+	assert.Nil(t, err)
+	return &SubnetReconciler{
+		Client:  fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:  scheme,
+		Service: service,
+	}, subnetsClient
+}
+
+func TestSubnetReconciler_Reconcile(t *testing.T) {
+	subnetCR := &v1alpha1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.SubnetSpec{DHCPConfig: v1alpha1.DHCPConfig{Mode: v1alpha1.DHCPModeServer, DHCPV4PoolSize: 80}},
+	}
+	r, subnetsClient := newFakeSubnetReconciler(t, subnetCR)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "subnet1"}}
+
+	// the Subnet waits for the VPC of its Namespace
+	_, err := r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	obj := &v1alpha1.Subnet{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+
+	nc := &v1alpha1.VPCNetworkConfiguration{Spec: v1alpha1.VPCNetworkConfigurationSpec{NSXTProject: "p1"}}
+	vpcCR := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "vpc-uid1"}}
+	_, err = common.ServiceMediator.CreateOrUpdateVPC(context.TODO(), vpcCR, nc, &config.VPCConnectivityProfile{Name: vpc.ConnectivityProfilePublic})
+	assert.Nil(t, err)
+
+	// the Subnet is created with the DHCP server
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.True(t, *subnetsClient.subnets["subnet_uid1"].DhcpConfig.EnableDhcp)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.SubnetFinalizerName)
+	assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc_vpc-uid1/subnets/subnet_uid1", obj.Status.NSXResourcePath)
+	assert.Equal(t, []string{"10.0.0.0/26"}, obj.Status.IPAddresses)
+	assert.Equal(t, []string{"10.0.0.1/26"}, obj.Status.GatewayAddresses)
+	assert.Equal(t, []string{"10.0.0.2/26"}, obj.Status.DHCPServerAddresses)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+
+	// the relay mode requires the DHCP relay config
+	obj.Spec.DHCPConfig.Mode = v1alpha1.DHCPModeRelay
+	assert.Nil(t, r.Client.Update(ctx, obj))
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+	assert.Equal(t, []string{"10.0.0.1/26"}, obj.Status.GatewayAddresses)
+
+	// the Subnet is switched to static IP allocation
+	obj.Spec.DHCPConfig.Mode = v1alpha1.DHCPModeStatic
+	assert.Nil(t, r.Client.Update(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.False(t, *subnetsClient.subnets["subnet_uid1"].DhcpConfig.EnableDhcp)
+	assert.True(t, *subnetsClient.subnets["subnet_uid1"].AdvancedConfig.StaticIpAllocation.Enable)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Nil(t, obj.Status.DHCPServerAddresses)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+
+	// the NSX Subnet is deleted with the Subnet CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(subnetsClient.subnets))
+	err = r.Client.Get(ctx, req.NamespacedName, obj)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestSubnetReconciler_GarbageCollector(t *testing.T) {
+	subnetCR := &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1", UID: "uid1"}}
+	r, subnetsClient := newFakeSubnetReconciler(t, subnetCR)
+	vpcPath := "/orgs/default/projects/p1/vpcs/vpc1"
+	_, err := r.Service.CreateOrUpdateSubnet(subnetCR, vpcPath)
+	assert.Nil(t, err)
+	_, err = r.Service.CreateOrUpdateSubnet(&v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet2", Namespace: "ns1", UID: "uid2"}}, vpcPath)
+	assert.Nil(t, err)
+
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	assert.Equal(t, []string{"uid1"}, r.Service.ListSubnetCRUID().List())
+	_, ok := subnetsClient.subnets["subnet_uid1"]
+	assert.True(t, ok)
+}
@@ -0,0 +1,487 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package subnetport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/subnet"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/subnetport"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	ResultRequeueAfter10sec          = common.ResultRequeueAfter10sec
+	MetricResType                    = common.MetricResTypeSubnetPort
+)
+
+// SubnetPortReconciler creates the NSX port for a SubnetPort CR in the NSX Subnet of its Subnet or SubnetSet.
+type SubnetPortReconciler struct {
+	Client        client.Client
+	Scheme        *apimachineryruntime.Scheme
+	Service       *subnetport.SubnetPortService
+	SubnetService *subnet.SubnetService
+	Recorder      record.EventRecorder
+}
+
+func updateFail(r *SubnetPortReconciler, c *context.Context, o *v1alpha1.SubnetPort, e *error) {
+	r.setSubnetPortReadyStatusFalse(c, o, e)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+}
+
+func deleteFail(r *SubnetPortReconciler, c *context.Context, o *v1alpha1.SubnetPort, e *error) {
+	r.setSubnetPortReadyStatusFalse(c, o, e)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+}
+
+func updateSuccess(r *SubnetPortReconciler, c *context.Context, o *v1alpha1.SubnetPort, status *v1alpha1.SubnetPortStatus) {
+	r.setSubnetPortReadyStatusTrue(c, o, status)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+}
+
+func deleteSuccess(r *SubnetPortReconciler, _ *context.Context, _ *v1alpha1.SubnetPort) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+}
+
+func (r *SubnetPortReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.SubnetPort{}
+	log.Info("reconciling SubnetPort CR", "subnetport", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch SubnetPort CR", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "subnetport", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "subnetport", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.SubnetPortFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.SubnetPortFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "subnetport", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on SubnetPort CR", "subnetport", req.NamespacedName)
+		}
+
+		nsxSubnet, err := r.getSubnet(ctx, obj)
+		if err == nil {
+			err = r.reserveIP(obj, nsxSubnet)
+		}
+		var subnetSecurity *v1alpha1.PortSecurity
+		if err == nil {
+			subnetSecurity, err = r.getSubnetSecurity(ctx, obj)
+		}
+		if err == nil {
+			var nsxPort *model.SegmentPort
+			nsxPort, err = r.Service.CreateOrUpdateSubnetPort(obj, nsxSubnet, subnetSecurity)
+			if err == nil {
+				var status *v1alpha1.SubnetPortStatus
+				status, err = r.getSubnetPortStatus(nsxPort, nsxSubnet)
+				if err == nil {
+					if !attachmentRealized(status) {
+						log.Info("NSX port attachment is not realized yet, would check again", "subnetport", req.NamespacedName,
+							"attachmentState", status.AttachmentState)
+						r.setSubnetPortNotRealized(&ctx, obj, status)
+						return ResultRequeueAfter10sec, nil
+					}
+					updateSuccess(r, &ctx, obj, status)
+					return ResultNormal, nil
+				}
+			}
+		}
+		if errors.As(err, &nsxutil.RestrictionError{}) {
+			log.Error(err, err.Error(), "subnetport", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return ResultNormal, nil
+		}
+		log.Error(err, "operate failed, would retry exponentially", "subnetport", req.NamespacedName)
+		updateFail(r, &ctx, obj, &err)
+		return common.ResultForError(err)
+	}
+
+	if controllerutil.ContainsFinalizer(obj, servicecommon.SubnetPortFinalizerName) {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		var nsxSubnet *model.VpcSubnet
+		if obj.Spec.Subnet != "" {
+			// the Subnet may be deleted before the SubnetPort, the IPs reserved are still released by their tags
+			nsxSubnet, _ = r.getSubnet(ctx, obj)
+		}
+		if err := r.deleteSubnetPort(obj.UID, nsxSubnet); err != nil {
+			log.Error(err, "deletion failed, would retry exponentially", "subnetport", req.NamespacedName)
+			deleteFail(r, &ctx, obj, &err)
+			return common.ResultForError(err)
+		}
+		controllerutil.RemoveFinalizer(obj, servicecommon.SubnetPortFinalizerName)
+		if err := r.Client.Update(ctx, obj); err != nil {
+			log.Error(err, "deletion failed, would retry exponentially", "subnetport", req.NamespacedName)
+			deleteFail(r, &ctx, obj, &err)
+			return ResultRequeue, err
+		}
+		log.V(1).Info("removed finalizer", "subnetport", req.NamespacedName)
+		deleteSuccess(r, &ctx, obj)
+	} else {
+		// only print a message because it's not a normal case
+		log.Info("finalizers cannot be recognized", "subnetport", req.NamespacedName)
+	}
+	return ResultNormal, nil
+}
+
+// getSubnet returns the NSX Subnet the port is created in. A created port stays in its Subnet, a new port is created
+// in the Subnet of the Subnet CR, or in an available Subnet of the SubnetSet CR.
+func (r *SubnetPortReconciler) getSubnet(ctx context.Context, obj *v1alpha1.SubnetPort) (*model.VpcSubnet, error) {
+	if nsxPort := r.Service.GetSubnetPortByCRUID(obj.UID); nsxPort != nil && nsxPort.ParentPath != nil {
+		if nsxSubnet := r.SubnetService.GetSubnetByPath(*nsxPort.ParentPath); nsxSubnet != nil {
+			return nsxSubnet, nil
+		}
+	}
+	switch {
+	case obj.Spec.Subnet != "":
+		subnetCR := &v1alpha1.Subnet{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Spec.Subnet}, subnetCR); err != nil {
+			return nil, err
+		}
+		nsxSubnets := r.SubnetService.GetSubnetsByIndex(servicecommon.TagScopeSubnetCRUID, string(subnetCR.UID))
+		if len(nsxSubnets) == 0 {
+			return nil, fmt.Errorf("Subnet %s is not realized yet", obj.Spec.Subnet)
+		}
+		return &nsxSubnets[0], nil
+	case obj.Spec.SubnetSet != "":
+		subnetSetCR := &v1alpha1.SubnetSet{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Spec.SubnetSet}, subnetSetCR); err != nil {
+			return nil, err
+		}
+		vpcs := common.ServiceMediator.GetVPCsByNamespace(obj.Namespace)
+		if len(vpcs) == 0 {
+			return nil, fmt.Errorf("no NSX VPC found in Namespace %s", obj.Namespace)
+		}
+		return r.SubnetService.GetAvailableSubnet(subnetSetCR, *vpcs[0].Path)
+	default:
+		return nil, nsxutil.RestrictionError{Desc: "either subnet or subnetSet should be set in SubnetPort"}
+	}
+}
+
+// getSubnetSecurity returns the default security features of the Subnet CR the SubnetPort CR is created in, SubnetSets
+// have no defaults.
+func (r *SubnetPortReconciler) getSubnetSecurity(ctx context.Context, obj *v1alpha1.SubnetPort) (*v1alpha1.PortSecurity, error) {
+	if obj.Spec.Subnet == "" {
+		return nil, nil
+	}
+	subnetCR := &v1alpha1.Subnet{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Spec.Subnet}, subnetCR); err != nil {
+		return nil, err
+	}
+	return subnetCR.Spec.PortSecurity, nil
+}
+
+// reserveIP reserves the IP requested by the annotation in the IP pool of the Subnet, the IP reserved before is
+// released if it is no longer requested, also if no port was created with it.
+func (r *SubnetPortReconciler) reserveIP(obj *v1alpha1.SubnetPort, nsxSubnet *model.VpcSubnet) error {
+	if err := r.Service.ValidateSubnetPort(obj, nsxSubnet); err != nil {
+		return err
+	}
+	requested := obj.Annotations[servicecommon.AnnotationIPAddress]
+	reserved := r.SubnetService.GetReservedIPs(obj.UID)
+	if requested != "" && len(reserved) == 1 && subnet.ReservedIPInSubnet(&reserved[0], nsxSubnet, requested) {
+		return nil
+	}
+	if err := r.SubnetService.ReleaseReservedIPs(obj.UID); err != nil {
+		return err
+	}
+	// the IP reserved before the allocations were tagged with the UID of the CR is recorded on the port
+	if nsxPort := r.Service.GetSubnetPortByCRUID(obj.UID); nsxPort != nil && subnetport.ReservedIP(nsxPort) != "" {
+		if err := r.SubnetService.ReleaseIP(nsxSubnet, subnetport.IPAllocationID(obj.UID)); err != nil {
+			return err
+		}
+	}
+	if requested == "" {
+		return nil
+	}
+	return r.SubnetService.ReserveIP(nsxSubnet, subnetport.IPAllocationID(obj.UID), requested, obj.UID)
+}
+
+// deleteSubnetPort deletes the NSX port of the SubnetPort CR and releases the IPs reserved for it. nsxSubnet is the
+// NSX Subnet of the Subnet CR referenced by the SubnetPort CR, the IP reserved in it is released even if no port was
+// created, it is nil if unknown.
+func (r *SubnetPortReconciler) deleteSubnetPort(uid types.UID, nsxSubnet *model.VpcSubnet) error {
+	if err := r.SubnetService.ReleaseReservedIPs(uid); err != nil {
+		return err
+	}
+	if nsxPort := r.Service.GetSubnetPortByCRUID(uid); nsxPort != nil && nsxPort.ParentPath != nil {
+		if portSubnet := r.SubnetService.GetSubnetByPath(*nsxPort.ParentPath); portSubnet != nil {
+			nsxSubnet = portSubnet
+		}
+	}
+	if nsxSubnet != nil {
+		if err := r.SubnetService.ReleaseIP(nsxSubnet, subnetport.IPAllocationID(uid)); err != nil {
+			return err
+		}
+	}
+	return r.Service.DeleteSubnetPortsByCRUID(uid)
+}
+
+// getSubnetPortStatus gets the attachment state and the realized addresses of the port, the gateway and netmask of
+// an IP are taken from the gateway address of the Subnet CIDR containing the IP.
+func (r *SubnetPortReconciler) getSubnetPortStatus(nsxPort *model.SegmentPort, nsxSubnet *model.VpcSubnet) (*v1alpha1.SubnetPortStatus, error) {
+	state, err := r.Service.GetSubnetPortState(nsxPort)
+	if err != nil {
+		return nil, err
+	}
+	gatewayAddresses, _, err := r.SubnetService.GetSubnetStatus(nsxSubnet)
+	if err != nil {
+		return nil, err
+	}
+	status := &v1alpha1.SubnetPortStatus{}
+	if nsxPort.Attachment != nil && nsxPort.Attachment.Id != nil {
+		status.VIFID = *nsxPort.Attachment.Id
+	}
+	if state.Attachment != nil && state.Attachment.State != nil {
+		status.AttachmentState = *state.Attachment.State
+	}
+	for _, binding := range state.RealizedBindings {
+		if binding.Binding == nil {
+			continue
+		}
+		if binding.Binding.IpAddress != nil {
+			status.IPAddresses = append(status.IPAddresses, buildIPAddress(*binding.Binding.IpAddress, gatewayAddresses))
+		}
+		if binding.Binding.MacAddress != nil && status.MACAddress == "" {
+			status.MACAddress = *binding.Binding.MacAddress
+		}
+	}
+	return status, nil
+}
+
+// attachmentRealized tells if the VIF attachment of the NSX port is realized, the SubnetPort is checked again until it
+// is.
+func attachmentRealized(status *v1alpha1.SubnetPortStatus) bool {
+	return status.AttachmentState == model.SegmentPortAttachmentState_STATE_ATTACHED ||
+		status.AttachmentState == model.SegmentPortAttachmentState_STATE_ATTACHED_IN_MOTION
+}
+
+func buildIPAddress(ip string, gatewayAddresses []string) v1alpha1.SubnetPortIPAddress {
+	address := v1alpha1.SubnetPortIPAddress{IP: ip}
+	for _, gatewayAddress := range gatewayAddresses {
+		gateway, ipNet, err := net.ParseCIDR(gatewayAddress)
+		if err != nil || !ipNet.Contains(net.ParseIP(ip)) {
+			continue
+		}
+		address.Gateway = gateway.String()
+		address.Netmask = net.IP(ipNet.Mask).String()
+		break
+	}
+	return address
+}
+
+func (r *SubnetPortReconciler) setSubnetPortReadyStatusTrue(ctx *context.Context, obj *v1alpha1.SubnetPort, status *v1alpha1.SubnetPortStatus) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionTrue,
+			Message: "NSX SubnetPort has been successfully created/updated",
+			Reason:  "NSX API returned 200 response code for PATCH",
+		},
+	}
+	newConditions = append(newConditions, common.ClearIPExhaustion(obj.Status.Conditions)...)
+	r.updateSubnetPortStatus(ctx, obj, newConditions, status)
+}
+
+func (r *SubnetPortReconciler) setSubnetPortReadyStatusFalse(ctx *context.Context, obj *v1alpha1.SubnetPort, err *error) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionFalse,
+			Message: fmt.Sprintf("NSX SubnetPort could not be created/updated, error occurred while processing the SubnetPort CR. Error: %v", *err),
+			Reason:  common.ErrorReason(*err),
+		},
+	}
+	newConditions = append(newConditions, common.ReportIPExhaustion(r.Recorder, r.Service.NSXConfig, obj, *err)...)
+	// the realized state is kept until the NSX port is updated successfully
+	r.updateSubnetPortStatus(ctx, obj, newConditions, obj.Status.DeepCopy())
+}
+
+func (r *SubnetPortReconciler) setSubnetPortNotRealized(ctx *context.Context, obj *v1alpha1.SubnetPort, status *v1alpha1.SubnetPortStatus) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionFalse,
+			Message: fmt.Sprintf("NSX SubnetPort attachment is not realized yet, its state is %q", status.AttachmentState),
+			Reason:  common.ReasonNotRealized,
+		},
+	}
+	newConditions = append(newConditions, common.ClearIPExhaustion(obj.Status.Conditions)...)
+	r.updateSubnetPortStatus(ctx, obj, newConditions, status)
+}
+
+func (r *SubnetPortReconciler) updateSubnetPortStatus(ctx *context.Context, obj *v1alpha1.SubnetPort, newConditions []v1alpha1.Condition, status *v1alpha1.SubnetPortStatus) {
+	status.Conditions = obj.Status.Conditions
+	updated := !reflect.DeepEqual(obj.Status, *status)
+	obj.Status = *status
+	for i := range newConditions {
+		if mergeSubnetPortStatusCondition(obj, &newConditions[i]) {
+			updated = true
+		}
+	}
+	if updated {
+		r.Client.Status().Update(*ctx, obj)
+		log.V(1).Info("updated SubnetPort", "Name", obj.Name, "Namespace", obj.Namespace,
+			"New Conditions", newConditions, "VIFID", obj.Status.VIFID, "AttachmentState", obj.Status.AttachmentState)
+	}
+}
+
+func mergeSubnetPortStatusCondition(obj *v1alpha1.SubnetPort, newCondition *v1alpha1.Condition) bool {
+	var matchedCondition *v1alpha1.Condition
+	for i := range obj.Status.Conditions {
+		if obj.Status.Conditions[i].Type == newCondition.Type {
+			matchedCondition = &obj.Status.Conditions[i]
+			break
+		}
+	}
+
+	if reflect.DeepEqual(matchedCondition, newCondition) {
+		log.V(2).Info("conditions already match", "New Condition", newCondition, "Existing Condition", matchedCondition)
+		return false
+	}
+
+	if matchedCondition != nil {
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		matchedCondition.Status = newCondition.Status
+	} else {
+		obj.Status.Conditions = append(obj.Status.Conditions, *newCondition)
+	}
+	return true
+}
+
+func (r *SubnetPortReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.SubnetPort{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerSubnetPort),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopeSubnetPortCRName, func() client.Object { return &v1alpha1.SubnetPort{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.SubnetPortList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.SubnetPort{}, r)
+}
+
+// Start setup manager and launch GC
+func (r *SubnetPortReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	go common.ReportDrift(make(chan bool), servicecommon.DriftInterval, r.Service.NSXConfig, common.DriftSource{
+		ResourceType: MetricResType,
+		Client:       r.Client,
+		List:         &v1alpha1.SubnetPortList{},
+		NSXUIDs:      r.Service.ListSubnetPortCRUID,
+	})
+	return nil
+}
+
+// collectGarbage deletes NSX ports and releases the IPs reserved whose SubnetPort CRs have been removed.
+func (r *SubnetPortReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	nsxSubnetPortSet := r.Service.ListSubnetPortCRUID().Union(r.SubnetService.ListReservedIPOwnerUID())
+	run.Scanned(len(nsxSubnetPortSet))
+	if len(nsxSubnetPortSet) == 0 {
+		return nil
+	}
+	subnetPortList := &v1alpha1.SubnetPortList{}
+	if err := r.Client.List(ctx, subnetPortList); err != nil {
+		log.Error(err, "failed to list SubnetPort CR")
+		return err
+	}
+
+	CRSubnetPortSet := sets.NewString()
+	for _, obj := range subnetPortList.Items {
+		CRSubnetPortSet.Insert(string(obj.UID))
+	}
+
+	run.Orphaned(servicecommon.TagScopeSubnetPortCRUID, nsxSubnetPortSet.Difference(CRSubnetPortSet))
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxSubnetPortSet, CRSubnetPortSet); err != nil {
+		return err
+	}
+
+	for elem := range nsxSubnetPortSet {
+		if CRSubnetPortSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		log.V(1).Info("GC collected SubnetPort CR", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.deleteSubnetPort(types.UID(elem), nil); err != nil {
+			run.Deleted(err)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			run.Deleted(nil)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+		}
+	}
+	return nil
+}
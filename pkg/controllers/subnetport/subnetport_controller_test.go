@@ -0,0 +1,383 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package subnetport
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets/ip_pools"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets/ports"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/subnet"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/subnetport"
+)
+
+const vpcPath = "/orgs/default/projects/p1/vpcs/vpc1"
+
+type fakeSubnetsClient struct {
+	vpcs.SubnetsClient
+	subnets map[string]model.VpcSubnet
+}
+
+func (c *fakeSubnetsClient) Patch(_ string, _ string, _ string, id string, subnet model.VpcSubnet) error {
+	subnet.IpAddresses = []string{"10.0.0.0/26"}
+	c.subnets[id] = subnet
+	return nil
+}
+
+func (c *fakeSubnetsClient) Get(_ string, _ string, _ string, id string) (model.VpcSubnet, error) {
+	return c.subnets[id], nil
+}
+
+type fakeSubnetStatusClient struct {
+	subnets.StatusClient
+}
+
+func (c *fakeSubnetStatusClient) List(_ string, _ string, _ string, _ string) (model.VpcSubnetStatusListResult, error) {
+	return model.VpcSubnetStatusListResult{Results: []model.VpcSubnetStatus{{GatewayAddress: servicecommon.String("10.0.0.1/26")}}}, nil
+}
+
+type fakeIPPoolClient struct {
+	subnets.IpPoolsClient
+}
+
+func (c *fakeIPPoolClient) List(_ string, _ string, _ string, _ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.IpAddressPoolListResult, error) {
+	return model.IpAddressPoolListResult{Results: []model.IpAddressPool{{Id: servicecommon.String("static-ipv4-default")}}}, nil
+}
+
+type fakeIPAllocationClient struct {
+	ip_pools.IpAllocationsClient
+	allocations map[string]model.IpAddressAllocation
+}
+
+func (c *fakeIPAllocationClient) List(_ string, _ string, _ string, _ string, _ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.IpAddressAllocationListResult, error) {
+	result := model.IpAddressAllocationListResult{}
+	for _, allocation := range c.allocations {
+		result.Results = append(result.Results, allocation)
+	}
+	return result, nil
+}
+
+func (c *fakeIPAllocationClient) Patch(_ string, _ string, _ string, _ string, _ string, id string, allocation model.IpAddressAllocation) error {
+	c.allocations[id] = allocation
+	return nil
+}
+
+func (c *fakeIPAllocationClient) Delete(_ string, _ string, _ string, _ string, _ string, id string) error {
+	delete(c.allocations, id)
+	return nil
+}
+
+type fakePortsClient struct {
+	subnets.PortsClient
+	ports map[string]model.SegmentPort
+	// err fails the creations of the ports
+	err error
+}
+
+func (c *fakePortsClient) Patch(_ string, _ string, _ string, _ string, id string, port model.SegmentPort) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.ports[id] = port
+	return nil
+}
+
+func (c *fakePortsClient) Get(_ string, _ string, _ string, _ string, id string) (model.SegmentPort, error) {
+	return c.ports[id], nil
+}
+
+func (c *fakePortsClient) Delete(_ string, _ string, _ string, _ string, id string) error {
+	delete(c.ports, id)
+	return nil
+}
+
+type fakePortStateClient struct {
+	ports.StateClient
+	attachmentState string
+}
+
+func (c *fakePortStateClient) Get(_ string, _ string, _ string, _ string, _ string, _ *string, _ *string) (model.SegmentPortState, error) {
+	return model.SegmentPortState{
+		Attachment: &model.SegmentPortAttachmentState{State: servicecommon.String(c.attachmentState)},
+		RealizedBindings: []model.AddressBindingEntry{
+			{Binding: &model.PacketAddressClassifier{IpAddress: servicecommon.String("10.0.0.5"), MacAddress: servicecommon.String("00:50:56:00:00:01")}},
+		},
+	}, nil
+}
+
+func newFakeSubnetPortReconciler(t *testing.T, objs ...apimachineryruntime.Object) (*SubnetPortReconciler, *fakePortsClient, *fakeIPAllocationClient) {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{},
+	}
+	portsClient := &fakePortsClient{ports: map[string]model.SegmentPort{}}
+	allocationClient := &fakeIPAllocationClient{allocations: map[string]model.IpAddressAllocation{}}
+	nsxClient := &nsx.Client{
+		NsxConfig:                nsxConfig,
+		QueryClient:              &fakeQueryClient{},
+		VPCSubnetClient:          &fakeSubnetsClient{subnets: map[string]model.VpcSubnet{}},
+		SubnetStatusClient:       &fakeSubnetStatusClient{},
+		IPPoolClient:             &fakeIPPoolClient{},
+		SubnetIPAllocationClient: allocationClient,
+		PortClient:               portsClient,
+		PortStateClient:          &fakePortStateClient{attachmentState: model.SegmentPortAttachmentState_STATE_ATTACHED},
+	}
+	commonService := servicecommon.Service{NSXClient: nsxClient, NSXConfig: nsxConfig}
+	subnetService, err := subnet.InitializeSubnetService(commonService)
+	assert.Nil(t, err)
+	service, err := subnetport.InitializeSubnetPort(commonService)
+	assert.Nil(t, err)
+	return &SubnetPortReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:        scheme,
+		Service:       service,
+		SubnetService: subnetService,
+	}, portsClient, allocationClient
+}
+
+type fakeQueryClient struct{}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	count := int64(0)
+	return model.SearchResponse{ResultCount: &count}, nil
+}
+
+func TestSubnetPortReconciler_Reconcile(t *testing.T) {
+	subnetCR := &v1alpha1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1", UID: "subnet-uid1"},
+		Spec:       v1alpha1.SubnetSpec{DHCPConfig: v1alpha1.DHCPConfig{Mode: v1alpha1.DHCPModeStatic}},
+	}
+	subnetPortCR := &v1alpha1.SubnetPort{
+		ObjectMeta: metav1.ObjectMeta{Name: "port1", Namespace: "ns1", UID: "uid1"},
+		Spec: v1alpha1.SubnetPortSpec{
+			Subnet:          "subnet1",
+			AddressBindings: []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5", MACAddress: "00:50:56:00:00:01"}},
+		},
+	}
+	r, portsClient, _ := newFakeSubnetPortReconciler(t, subnetCR, subnetPortCR)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "port1"}}
+
+	// the SubnetPort waits for the NSX Subnet
+	_, err := r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	obj := &v1alpha1.SubnetPort{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+
+	_, err = r.SubnetService.CreateOrUpdateSubnet(subnetCR, vpcPath)
+	assert.Nil(t, err)
+
+	// the SubnetPort is checked again until the port attachment is realized
+	stateClient := r.Service.NSXClient.PortStateClient.(*fakePortStateClient)
+	stateClient.attachmentState = model.SegmentPortAttachmentState_STATE_DETACHED
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultRequeueAfter10sec, result)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, model.SegmentPortAttachmentState_STATE_DETACHED, obj.Status.AttachmentState)
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+	assert.Equal(t, common.ReasonNotRealized, obj.Status.Conditions[0].Reason)
+
+	// the port is created with the address binding and its realized state is reported
+	stateClient.attachmentState = model.SegmentPortAttachmentState_STATE_ATTACHED
+	result, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	nsxPort := portsClient.ports["port_uid1"]
+	assert.Equal(t, vpcPath+"/subnets/subnet_subnet-uid1", *nsxPort.ParentPath)
+	assert.Equal(t, model.PortAttachment_ALLOCATE_ADDRESSES_NONE, *nsxPort.Attachment.AllocateAddresses)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.SubnetPortFinalizerName)
+	assert.Equal(t, "uid1", obj.Status.VIFID)
+	assert.Equal(t, model.SegmentPortAttachmentState_STATE_ATTACHED, obj.Status.AttachmentState)
+	assert.Equal(t, []v1alpha1.SubnetPortIPAddress{{Gateway: "10.0.0.1", IP: "10.0.0.5", Netmask: "255.255.255.192"}}, obj.Status.IPAddresses)
+	assert.Equal(t, "00:50:56:00:00:01", obj.Status.MACAddress)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+
+	// the NSX port is deleted with the SubnetPort CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(portsClient.ports))
+	err = r.Client.Get(ctx, req.NamespacedName, obj)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestSubnetPortReconciler_ReconcileStaticIP(t *testing.T) {
+	subnetCR := &v1alpha1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1", UID: "subnet-uid1"},
+		Spec:       v1alpha1.SubnetSpec{DHCPConfig: v1alpha1.DHCPConfig{Mode: v1alpha1.DHCPModeStatic}},
+	}
+	newSubnetPort := func(name string, uid types.UID) *v1alpha1.SubnetPort {
+		return &v1alpha1.SubnetPort{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1", UID: uid, Annotations: map[string]string{servicecommon.AnnotationIPAddress: "10.0.0.5"}},
+			Spec:       v1alpha1.SubnetPortSpec{Subnet: "subnet1"},
+		}
+	}
+	r, portsClient, allocationClient := newFakeSubnetPortReconciler(t, subnetCR, newSubnetPort("port1", "uid1"), newSubnetPort("port2", "uid2"))
+	_, err := r.SubnetService.CreateOrUpdateSubnet(subnetCR, vpcPath)
+	assert.Nil(t, err)
+	ctx := context.Background()
+	req1 := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "port1"}}
+	req2 := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "port2"}}
+
+	// the requested IP is reserved and bound to the port
+	_, err = r.Reconcile(ctx, req1)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.5", *allocationClient.allocations["port_uid1"].AllocationIp)
+	assert.Equal(t, "10.0.0.5", *portsClient.ports["port_uid1"].AddressBindings[0].IpAddress)
+
+	// the same IP requested by another port is rejected
+	result, err := r.Reconcile(ctx, req2)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	_, ok := portsClient.ports["port_uid2"]
+	assert.False(t, ok)
+	obj := &v1alpha1.SubnetPort{}
+	assert.Nil(t, r.Client.Get(ctx, req2.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+	assert.Contains(t, obj.Status.Conditions[0].Message, "IP address 10.0.0.5 is already allocated")
+
+	// the IP reserved before is released when another IP is requested
+	assert.Nil(t, r.Client.Get(ctx, req1.NamespacedName, obj))
+	obj.Annotations[servicecommon.AnnotationIPAddress] = "10.0.0.6"
+	assert.Nil(t, r.Client.Update(ctx, obj))
+	_, err = r.Reconcile(ctx, req1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(allocationClient.allocations))
+	assert.Equal(t, "10.0.0.6", *allocationClient.allocations["port_uid1"].AllocationIp)
+	assert.Equal(t, "10.0.0.6", *portsClient.ports["port_uid1"].AddressBindings[0].IpAddress)
+
+	// the reserved IP is released with the SubnetPort CR
+	assert.Nil(t, r.Client.Get(ctx, req1.NamespacedName, obj))
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(allocationClient.allocations))
+	assert.Equal(t, 0, len(portsClient.ports))
+}
+
+func TestSubnetPortReconciler_ReleaseReservedIP(t *testing.T) {
+	subnetCR := &v1alpha1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1", UID: "subnet-uid1"},
+		Spec:       v1alpha1.SubnetSpec{DHCPConfig: v1alpha1.DHCPConfig{Mode: v1alpha1.DHCPModeStatic}},
+	}
+	subnetPortCR := &v1alpha1.SubnetPort{
+		ObjectMeta: metav1.ObjectMeta{Name: "port1", Namespace: "ns1", UID: "uid1", Annotations: map[string]string{servicecommon.AnnotationIPAddress: "10.0.0.5"}},
+		Spec:       v1alpha1.SubnetPortSpec{Subnet: "subnet1"},
+	}
+	r, portsClient, allocationClient := newFakeSubnetPortReconciler(t, subnetCR, subnetPortCR)
+	nsxSubnet, err := r.SubnetService.CreateOrUpdateSubnet(subnetCR, vpcPath)
+	assert.Nil(t, err)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "port1"}}
+
+	// the IP is reserved before the port fails to be created
+	portsClient.err = errors.New("port creation failed")
+	_, err = r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(portsClient.ports))
+	assert.Equal(t, "uid1", *allocationClient.allocations["port_uid1"].Tags[1].Tag)
+
+	// the IP is released once the annotation is removed
+	obj := &v1alpha1.SubnetPort{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	delete(obj.Annotations, servicecommon.AnnotationIPAddress)
+	assert.Nil(t, r.Client.Update(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(allocationClient.allocations))
+
+	// the IP is released with the SubnetPort CR
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	obj.Annotations = map[string]string{servicecommon.AnnotationIPAddress: "10.0.0.6"}
+	assert.Nil(t, r.Client.Update(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	assert.Equal(t, "10.0.0.6", *allocationClient.allocations["port_uid1"].AllocationIp)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(allocationClient.allocations))
+
+	// the IP reserved for a SubnetPort CR deleted is collected
+	assert.Nil(t, r.SubnetService.ReserveIP(nsxSubnet, "port_uid2", "10.0.0.7", "uid2"))
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	assert.Equal(t, 0, r.SubnetService.ListReservedIPOwnerUID().Len())
+}
+
+func TestSubnetPortReconciler_ReconcileWithoutSubnet(t *testing.T) {
+	subnetPortCR := &v1alpha1.SubnetPort{ObjectMeta: metav1.ObjectMeta{Name: "port1", Namespace: "ns1", UID: "uid1"}}
+	r, portsClient, _ := newFakeSubnetPortReconciler(t, subnetPortCR)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "port1"}}
+
+	// the SubnetPort without a Subnet or SubnetSet is not retried
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Equal(t, 0, len(portsClient.ports))
+	obj := &v1alpha1.SubnetPort{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+}
+
+func TestSubnetPortReconciler_getSubnetSecurity(t *testing.T) {
+	security := &v1alpha1.PortSecurity{SpoofGuard: servicecommon.Bool(true)}
+	subnetCR := &v1alpha1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1", UID: "subnet-uid1"},
+		Spec:       v1alpha1.SubnetSpec{PortSecurity: security},
+	}
+	r, _, _ := newFakeSubnetPortReconciler(t, subnetCR)
+	ctx := context.Background()
+
+	got, err := r.getSubnetSecurity(ctx, &v1alpha1.SubnetPort{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1"}, Spec: v1alpha1.SubnetPortSpec{Subnet: "subnet1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, security, got)
+
+	// SubnetSets have no defaults
+	got, err = r.getSubnetSecurity(ctx, &v1alpha1.SubnetPort{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1"}, Spec: v1alpha1.SubnetPortSpec{SubnetSet: "subnetset1"}})
+	assert.Nil(t, err)
+	assert.Nil(t, got)
+}
+
+func TestSubnetPortReconciler_GarbageCollector(t *testing.T) {
+	subnetPortCR := &v1alpha1.SubnetPort{ObjectMeta: metav1.ObjectMeta{Name: "port1", Namespace: "ns1", UID: "uid1"}}
+	r, portsClient, _ := newFakeSubnetPortReconciler(t, subnetPortCR)
+	nsxSubnet := &model.VpcSubnet{Path: servicecommon.String(vpcPath + "/subnets/subnet1")}
+	_, err := r.Service.CreateOrUpdateSubnetPort(subnetPortCR, nsxSubnet, nil)
+	assert.Nil(t, err)
+	_, err = r.Service.CreateOrUpdateSubnetPort(&v1alpha1.SubnetPort{ObjectMeta: metav1.ObjectMeta{Name: "port2", Namespace: "ns1", UID: "uid2"}}, nsxSubnet, nil)
+	assert.Nil(t, err)
+
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	assert.Equal(t, []string{"uid1"}, r.Service.ListSubnetPortCRUID().List())
+	_, ok := portsClient.ports["port_uid1"]
+	assert.True(t, ok)
+}
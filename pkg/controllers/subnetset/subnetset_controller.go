@@ -0,0 +1,243 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package subnetset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/subnet"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	// ResultRequeueAfterScale checks the utilization of the Subnets of the SubnetSet periodically.
+	ResultRequeueAfterScale = common.ResultRequeueAfter5mins
+	MetricResType           = common.MetricResTypeSubnetSet
+)
+
+// SubnetSetReconciler maintains the NSX Subnets of a SubnetSet CR in the NSX VPC of its Namespace, one more Subnet
+// is created when the utilization of all the Subnets reaches the scale threshold.
+type SubnetSetReconciler struct {
+	Client   client.Client
+	Scheme   *apimachineryruntime.Scheme
+	Service  *subnet.SubnetService
+	Recorder record.EventRecorder
+}
+
+func updateFail(r *SubnetSetReconciler) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+}
+
+func deleteFail(r *SubnetSetReconciler) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+}
+
+func updateSuccess(r *SubnetSetReconciler) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+}
+
+func deleteSuccess(r *SubnetSetReconciler) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+}
+
+func (r *SubnetSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.SubnetSet{}
+	log.Info("reconciling SubnetSet CR", "subnetset", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch SubnetSet CR", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "subnetset", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "subnetset", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.SubnetSetFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.SubnetSetFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "subnetset", req.NamespacedName)
+				updateFail(r)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on SubnetSet CR", "subnetset", req.NamespacedName)
+		}
+
+		vpcs := common.ServiceMediator.GetVPCsByNamespace(req.Namespace)
+		if len(vpcs) == 0 {
+			err := fmt.Errorf("no NSX VPC found in Namespace %s", req.Namespace)
+			log.Error(err, "failed to find VPC for SubnetSet CR, would retry exponentially", "subnetset", req.NamespacedName)
+			updateFail(r)
+			return ResultRequeue, err
+		}
+
+		nsxSubnet, err := r.Service.ScaleSubnetSet(obj, *vpcs[0].Path)
+		if err != nil {
+			if errors.As(err, &nsxutil.RestrictionError{}) {
+				log.Error(err, err.Error(), "subnetset", req.NamespacedName)
+				updateFail(r)
+				return ResultNormal, nil
+			}
+			log.Error(err, "operate failed, would retry exponentially", "subnetset", req.NamespacedName)
+			updateFail(r)
+			if conditions := common.ReportIPExhaustion(r.Recorder, r.Service.NSXConfig, obj, err); len(conditions) > 0 {
+				if err := r.Service.UpdateSubnetSetCondition(obj, &conditions[0]); err != nil {
+					log.Error(err, "failed to update SubnetSet status", "subnetset", req.NamespacedName)
+				}
+			}
+			return ResultRequeue, err
+		}
+		if nsxSubnet != nil {
+			log.Info("created Subnet for SubnetSet", "subnetset", req.NamespacedName, "Subnet", *nsxSubnet.Path)
+		}
+		if err := r.Service.UpdateSubnetSetStatus(obj); err != nil {
+			log.Error(err, "failed to update SubnetSet status, would retry exponentially", "subnetset", req.NamespacedName)
+			updateFail(r)
+			return ResultRequeue, err
+		}
+		updateSuccess(r)
+		return ResultRequeueAfterScale, nil
+	}
+
+	if controllerutil.ContainsFinalizer(obj, servicecommon.SubnetSetFinalizerName) {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.Service.DeleteSubnetSetSubnets(obj.UID); err != nil {
+			log.Error(err, "deletion failed, would retry exponentially", "subnetset", req.NamespacedName)
+			deleteFail(r)
+			return ResultRequeue, err
+		}
+		controllerutil.RemoveFinalizer(obj, servicecommon.SubnetSetFinalizerName)
+		if err := r.Client.Update(ctx, obj); err != nil {
+			log.Error(err, "deletion failed, would retry exponentially", "subnetset", req.NamespacedName)
+			deleteFail(r)
+			return ResultRequeue, err
+		}
+		log.V(1).Info("removed finalizer", "subnetset", req.NamespacedName)
+		deleteSuccess(r)
+	} else {
+		// only print a message because it's not a normal case
+		log.Info("finalizers cannot be recognized", "subnetset", req.NamespacedName)
+	}
+	return ResultNormal, nil
+}
+
+func (r *SubnetSetReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.SubnetSet{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerSubnetSet),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopeSubnetSetCRName, func() client.Object { return &v1alpha1.SubnetSet{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.SubnetSetList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.SubnetSet{}, r)
+}
+
+// Start setup manager and launch GC
+func (r *SubnetSetReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	return nil
+}
+
+// collectGarbage deletes NSX Subnets whose SubnetSet CRs have been removed.
+func (r *SubnetSetReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	nsxSubnetSetSet := r.Service.ListSubnetSetCRUID()
+	run.Scanned(len(nsxSubnetSetSet))
+	if len(nsxSubnetSetSet) == 0 {
+		return nil
+	}
+	subnetSetList := &v1alpha1.SubnetSetList{}
+	if err := r.Client.List(ctx, subnetSetList); err != nil {
+		log.Error(err, "failed to list SubnetSet CR")
+		return err
+	}
+
+	CRSubnetSetSet := sets.NewString()
+	for _, obj := range subnetSetList.Items {
+		CRSubnetSetSet.Insert(string(obj.UID))
+	}
+
+	run.Orphaned(servicecommon.TagScopeSubnetSetCRUID, nsxSubnetSetSet.Difference(CRSubnetSetSet))
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxSubnetSetSet, CRSubnetSetSet); err != nil {
+		return err
+	}
+
+	for elem := range nsxSubnetSetSet {
+		if CRSubnetSetSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		log.V(1).Info("GC collected SubnetSet CR", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.Service.DeleteSubnetSetSubnets(types.UID(elem)); err != nil {
+			run.Deleted(err)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			run.Deleted(nil)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+		}
+	}
+	return nil
+}
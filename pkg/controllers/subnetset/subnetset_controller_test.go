@@ -0,0 +1,188 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package subnetset
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/subnet"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+)
+
+type fakeQueryClient struct{}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	count := int64(0)
+	return model.SearchResponse{ResultCount: &count}, nil
+}
+
+type fakeVPCClient struct {
+	projects.VpcsClient
+}
+
+func (c *fakeVPCClient) Patch(_ string, _ string, _ string, _ model.Vpc) error {
+	return nil
+}
+
+type fakeSecurityPolicyClient struct {
+	vpcs.SecurityPoliciesClient
+}
+
+func (c *fakeSecurityPolicyClient) Delete(_ string, _ string, _ string, _ string) error {
+	return nil
+}
+
+type fakeSubnetsClient struct {
+	vpcs.SubnetsClient
+	subnets map[string]model.VpcSubnet
+}
+
+func (c *fakeSubnetsClient) Patch(_ string, _ string, _ string, id string, subnet model.VpcSubnet) error {
+	subnet.IpAddresses = []string{"10.0.0.0/26"}
+	c.subnets[id] = subnet
+	return nil
+}
+
+func (c *fakeSubnetsClient) Get(_ string, _ string, _ string, id string) (model.VpcSubnet, error) {
+	return c.subnets[id], nil
+}
+
+func (c *fakeSubnetsClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.subnets, id)
+	return nil
+}
+
+type fakeIPPoolClient struct {
+	subnets.IpPoolsClient
+	// available IPs of the Subnets
+	available map[string]int64
+}
+
+func (c *fakeIPPoolClient) List(_ string, _ string, _ string, subnetID string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.IpAddressPoolListResult, error) {
+	available, ok := c.available[subnetID]
+	if !ok {
+		return model.IpAddressPoolListResult{}, nil
+	}
+	return model.IpAddressPoolListResult{Results: []model.IpAddressPool{
+		{PoolUsage: &model.PolicyPoolUsage{TotalIps: servicecommon.Int64(64), AvailableIps: servicecommon.Int64(available)}},
+	}}, nil
+}
+
+func newFakeSubnetSetReconciler(t *testing.T, objs ...apimachineryruntime.Object) (*SubnetSetReconciler, *fakeSubnetsClient, *fakeIPPoolClient) {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one", SubnetExhaustionPolicy: config.SubnetExhaustionPolicyAutoCreate},
+		NsxConfig: &config.NsxConfig{},
+	}
+	subnetsClient := &fakeSubnetsClient{subnets: map[string]model.VpcSubnet{}}
+	ipPoolClient := &fakeIPPoolClient{available: map[string]int64{}}
+	nsxClient := &nsx.Client{
+		NsxConfig:               nsxConfig,
+		QueryClient:             &fakeQueryClient{},
+		VPCClient:               &fakeVPCClient{},
+		VPCSecurityPolicyClient: &fakeSecurityPolicyClient{},
+		VPCSubnetClient:         subnetsClient,
+		IPPoolClient:            ipPoolClient,
+	}
+	commonService := servicecommon.Service{NSXClient: nsxClient, NSXConfig: nsxConfig}
+	vpcService, err := vpc.InitializeVPC(commonService)
+	assert.Nil(t, err)
+	common.ServiceMediator.VPCService = vpcService
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+	commonService.Client = k8sClient
+	service, err := subnet.InitializeSubnetService(commonService)
+	assert.Nil(t, err)
+	return &SubnetSetReconciler{
+		Client:  k8sClient,
+		Scheme:  scheme,
+		Service: service,
+	}, subnetsClient, ipPoolClient
+}
+
+func TestSubnetSetReconciler_Reconcile(t *testing.T) {
+	subnetSetCR := &v1alpha1.SubnetSet{ObjectMeta: metav1.ObjectMeta{Name: "set1", Namespace: "ns1", UID: "uid1"}}
+	r, subnetsClient, ipPoolClient := newFakeSubnetSetReconciler(t, subnetSetCR)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "set1"}}
+
+	// the SubnetSet waits for the VPC of its Namespace
+	_, err := r.Reconcile(ctx, req)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(subnetsClient.subnets))
+
+	nc := &v1alpha1.VPCNetworkConfiguration{Spec: v1alpha1.VPCNetworkConfigurationSpec{NSXTProject: "p1"}}
+	vpcCR := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "vpc-uid1"}}
+	_, err = common.ServiceMediator.CreateOrUpdateVPC(context.TODO(), vpcCR, nc, &config.VPCConnectivityProfile{Name: vpc.ConnectivityProfilePublic})
+	assert.Nil(t, err)
+
+	// the first Subnet is created
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultRequeueAfterScale, result)
+	assert.Equal(t, 1, len(subnetsClient.subnets))
+	obj := &v1alpha1.SubnetSet{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.SubnetSetFinalizerName)
+	assert.Equal(t, 1, len(obj.Status.Subnets))
+	assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc_vpc-uid1/subnets/subnetset_uid1_0", obj.Status.Subnets[0].NSXResourcePath)
+
+	// one more Subnet is created after the utilization reaches the threshold
+	ipPoolClient.available["subnetset_uid1_0"] = 10
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(subnetsClient.subnets))
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, 2, len(obj.Status.Subnets))
+	assert.Equal(t, v1alpha1.SubnetInfo{
+		NSXResourcePath: "/orgs/default/projects/p1/vpcs/vpc_vpc-uid1/subnets/subnetset_uid1_0",
+		IPAddresses:     []string{"10.0.0.0/26"},
+		Total:           64,
+		Used:            54,
+		Utilization:     84,
+	}, obj.Status.Subnets[0])
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+
+	// the NSX Subnets are deleted with the SubnetSet CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(subnetsClient.subnets))
+	err = r.Client.Get(ctx, req.NamespacedName, obj)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestSubnetSetReconciler_GarbageCollector(t *testing.T) {
+	subnetSetCR := &v1alpha1.SubnetSet{ObjectMeta: metav1.ObjectMeta{Name: "set1", Namespace: "ns1", UID: "uid1"}}
+	r, _, _ := newFakeSubnetSetReconciler(t, subnetSetCR)
+	vpcPath := "/orgs/default/projects/p1/vpcs/vpc1"
+	_, err := r.Service.ScaleSubnetSet(subnetSetCR, vpcPath)
+	assert.Nil(t, err)
+	_, err = r.Service.ScaleSubnetSet(&v1alpha1.SubnetSet{ObjectMeta: metav1.ObjectMeta{Name: "set2", Namespace: "ns1", UID: "uid2"}}, vpcPath)
+	assert.Nil(t, err)
+
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	assert.Equal(t, []string{"uid1"}, r.Service.ListSubnetSetCRUID().List())
+}
@@ -0,0 +1,157 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package vpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+)
+
+const (
+	ReasonIPBlockUsageHigh   = "IPBlockUsageHigh"
+	ReasonIPBlockUsageNormal = "IPBlockUsageNormal"
+)
+
+var ipBlockUsageTypes = []string{vpc.IPBlockUsageSNAT, vpc.IPBlockUsageNAT, vpc.IPBlockUsageIPAllocation}
+
+// IPBlockUsageReporter syncs the usage of the external IP blocks of the VPCNetworkConfigurations periodically.
+// cancel is used to break the loop during UT
+func (r *VPCReconciler) IPBlockUsageReporter(cancel chan bool, interval time.Duration) {
+	ctx := context.Background()
+	log.Info("IP block usage reporter started")
+	for {
+		select {
+		case <-cancel:
+			return
+		case <-time.After(interval):
+		}
+		if !common.ShardCoordinator() {
+			continue
+		}
+		if err := r.reportExternalIPBlockUsage(ctx); err != nil {
+			log.Error(err, "failed to report the usage of the external IP blocks")
+		}
+	}
+}
+
+// reportExternalIPBlockUsage publishes the usage of the external IP blocks as metrics and in the status of the
+// VPCNetworkConfigurations, a Warning Event is emitted on the VPCNetworkConfigurations whose IP blocks cross the
+// threshold.
+func (r *VPCReconciler) reportExternalIPBlockUsage(ctx context.Context) error {
+	ncList := &v1alpha1.VPCNetworkConfigurationList{}
+	if err := r.Client.List(ctx, ncList); err != nil {
+		return err
+	}
+	paths := sets.NewString()
+	for _, nc := range ncList.Items {
+		paths.Insert(nc.Spec.ExternalIPv4Blocks...)
+	}
+	if paths.Len() == 0 {
+		return nil
+	}
+	usages, err := r.Service.SyncExternalIPBlockUsage(paths.List())
+	if err != nil {
+		return err
+	}
+
+	cf := r.Service.NSXConfig
+	threshold := cf.GetExternalIPBlockUsageThreshold()
+	usageByPath := make(map[string]*vpc.IPBlockUsage)
+	for _, usage := range usages {
+		usageByPath[usage.Path] = usage
+		metrics.GaugeSet(cf, metrics.ExternalIPBlockTotal, float64(usage.Total), usage.Path)
+		for _, usageType := range ipBlockUsageTypes {
+			metrics.GaugeSet(cf, metrics.ExternalIPBlockUsed, float64(usage.Used[usageType]), usage.Path, usageType)
+		}
+		metrics.GaugeSet(cf, metrics.ExternalIPBlockUtilization, float64(usage.Utilization()), usage.Path)
+		if usage.Utilization() >= threshold {
+			log.Info("external IP block usage crosses the threshold", "IPBlock", usage.Path, "used", usage.UsedIPs(),
+				"total", usage.Total, "threshold", threshold)
+		}
+	}
+
+	for i := range ncList.Items {
+		if err := r.updateIPBlockUsageStatus(ctx, &ncList.Items[i], usageByPath, threshold); err != nil {
+			log.Error(err, "failed to update VPCNetworkConfiguration status", "VPCNetworkConfiguration", ncList.Items[i].Name)
+		}
+	}
+	return nil
+}
+
+func (r *VPCReconciler) updateIPBlockUsageStatus(ctx context.Context, nc *v1alpha1.VPCNetworkConfiguration, usageByPath map[string]*vpc.IPBlockUsage, threshold int) error {
+	var blocksUsage []v1alpha1.IPBlockUsage
+	var highUsage []string
+	for _, path := range nc.Spec.ExternalIPv4Blocks {
+		usage, ok := usageByPath[path]
+		if !ok {
+			continue
+		}
+		blocksUsage = append(blocksUsage, v1alpha1.IPBlockUsage{
+			Path:        usage.Path,
+			CIDR:        usage.CIDR,
+			Total:       usage.Total,
+			Used:        usage.UsedIPs(),
+			Utilization: usage.Utilization(),
+		})
+		if usage.Utilization() >= threshold {
+			highUsage = append(highUsage, fmt.Sprintf("%s(%d%%)", usage.Path, usage.Utilization()))
+		}
+	}
+
+	condition := v1alpha1.Condition{
+		Type:    v1alpha1.ExternalIPBlockUsageHigh,
+		Status:  v1.ConditionFalse,
+		Reason:  ReasonIPBlockUsageNormal,
+		Message: fmt.Sprintf("utilization of the external IP blocks is below %d%%", threshold),
+	}
+	if len(highUsage) > 0 {
+		condition.Status = v1.ConditionTrue
+		condition.Reason = ReasonIPBlockUsageHigh
+		condition.Message = fmt.Sprintf("utilization of the external IP blocks %s reaches %d%%", strings.Join(highUsage, ", "), threshold)
+	}
+
+	updated := !reflect.DeepEqual(nc.Status.ExternalIPv4BlocksUsage, blocksUsage)
+	nc.Status.ExternalIPv4BlocksUsage = blocksUsage
+	if mergeNetworkConfigurationCondition(nc, &condition) {
+		updated = true
+		if condition.Status == v1.ConditionTrue {
+			r.Recorder.Event(nc, v1.EventTypeWarning, ReasonIPBlockUsageHigh, condition.Message)
+		}
+	}
+	if !updated {
+		return nil
+	}
+	log.V(1).Info("updated external IP blocks usage", "VPCNetworkConfiguration", nc.Name, "usage", blocksUsage)
+	return r.Client.Status().Update(ctx, nc)
+}
+
+func mergeNetworkConfigurationCondition(nc *v1alpha1.VPCNetworkConfiguration, newCondition *v1alpha1.Condition) bool {
+	for i := range nc.Status.Conditions {
+		matchedCondition := &nc.Status.Conditions[i]
+		if matchedCondition.Type != newCondition.Type {
+			continue
+		}
+		if matchedCondition.Status == newCondition.Status && matchedCondition.Reason == newCondition.Reason &&
+			matchedCondition.Message == newCondition.Message {
+			return false
+		}
+		matchedCondition.Status = newCondition.Status
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		return true
+	}
+	nc.Status.Conditions = append(nc.Status.Conditions, *newCondition)
+	return true
+}
@@ -0,0 +1,99 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package vpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/nat"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+)
+
+type fakeIPBlockClient struct {
+	infra.IpBlocksClient
+}
+
+func (c *fakeIPBlockClient) Get(id string) (model.IpAddressBlock, error) {
+	cidr := "10.0.0.0/29"
+	return model.IpAddressBlock{Id: &id, Cidr: &cidr}, nil
+}
+
+type fakeNATRuleClient struct {
+	nat.NatRulesClient
+	rules []model.PolicyNatRule
+}
+
+func (c *fakeNATRuleClient) List(_ string, _ string, _ string, _ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.PolicyNatRuleListResult, error) {
+	return model.PolicyNatRuleListResult{Results: c.rules}, nil
+}
+
+type fakeIPAllocationClient struct {
+	vpcs.IpAddressAllocationsClient
+}
+
+func (c *fakeIPAllocationClient) List(_ string, _ string, _ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.VpcIpAddressAllocationListResult, error) {
+	vip := "10.0.0.5"
+	return model.VpcIpAddressAllocationListResult{Results: []model.VpcIpAddressAllocation{{AllocationIp: &vip}}}, nil
+}
+
+func TestVPCReconciler_IPBlockUsageReporter(t *testing.T) {
+	nc := &v1alpha1.VPCNetworkConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "nc1"},
+		Spec: v1alpha1.VPCNetworkConfigurationSpec{
+			NSXTProject:        "p1",
+			ExternalIPv4Blocks: []string{"/infra/ip-blocks/b1"},
+		},
+	}
+	r := newFakeVPCReconciler(t, &fakeVPCClient{patched: map[string]model.Vpc{}}, nc)
+	_, err := r.Service.CreateOrUpdateVPC(context.TODO(), &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"}},
+		nc, &config.VPCConnectivityProfile{Name: vpc.ConnectivityProfilePublic})
+	assert.Nil(t, err)
+	snat, snatIP := model.PolicyNatRule_ACTION_SNAT, "10.0.0.1"
+	natClient := &fakeNATRuleClient{rules: []model.PolicyNatRule{{Action: &snat, TranslatedNetwork: &snatIP}}}
+	r.Service.NSXClient.IPBlockClient = &fakeIPBlockClient{}
+	r.Service.NSXClient.NATRuleClient = natClient
+	r.Service.NSXClient.VPCIPAllocationClient = &fakeIPAllocationClient{}
+	ctx := context.Background()
+
+	// 2 of the 8 IPs are used
+	assert.Nil(t, r.reportExternalIPBlockUsage(ctx))
+	obj := &v1alpha1.VPCNetworkConfiguration{}
+	assert.Nil(t, r.Client.Get(ctx, types.NamespacedName{Name: "nc1"}, obj))
+	assert.Equal(t, []v1alpha1.IPBlockUsage{
+		{Path: "/infra/ip-blocks/b1", CIDR: "10.0.0.0/29", Total: 8, Used: 2, Utilization: 25},
+	}, obj.Status.ExternalIPv4BlocksUsage)
+	assert.Equal(t, v1alpha1.ExternalIPBlockUsageHigh, obj.Status.Conditions[0].Type)
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+
+	// the condition is set when the utilization crosses the threshold
+	for _, ip := range []string{"10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.6", "10.0.0.7"} {
+		ip := ip
+		natClient.rules = append(natClient.rules, model.PolicyNatRule{Action: &snat, TranslatedNetwork: &ip})
+	}
+	cancel := make(chan bool)
+	go r.IPBlockUsageReporter(cancel, 10*time.Millisecond)
+	defer close(cancel)
+	assert.Eventually(t, func() bool {
+		assert.Nil(t, r.Client.Get(ctx, types.NamespacedName{Name: "nc1"}, obj))
+		return obj.Status.Conditions[0].Status == v1.ConditionTrue
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, ReasonIPBlockUsageHigh, obj.Status.Conditions[0].Reason)
+	assert.Equal(t, 87, obj.Status.ExternalIPv4BlocksUsage[0].Utilization)
+	assert.Equal(t, 1, len(obj.Status.Conditions))
+	assert.Equal(t, "Warning IPBlockUsageHigh utilization of the external IP blocks /infra/ip-blocks/b1(87%) reaches 80%",
+		<-r.Recorder.(*record.FakeRecorder).Events)
+}
@@ -0,0 +1,79 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package vpc
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+)
+
+// When the connectivity profile or the VPCNetworkConfiguration selected by a namespace is changed,
+// we should reconcile the VPCs in the namespace.
+
+type EnqueueRequestForNamespace struct {
+	Client client.Client
+}
+
+func (e *EnqueueRequestForNamespace) Create(_ event.CreateEvent, _ workqueue.RateLimitingInterface) {
+	log.V(1).Info("namespace create event, do nothing")
+}
+
+func (e *EnqueueRequestForNamespace) Delete(_ event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+	log.V(1).Info("namespace delete event, do nothing")
+}
+
+func (e *EnqueueRequestForNamespace) Generic(_ event.GenericEvent, _ workqueue.RateLimitingInterface) {
+	log.V(1).Info("namespace generic event, do nothing")
+}
+
+func (e *EnqueueRequestForNamespace) Update(updateEvent event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	obj := updateEvent.ObjectNew.(*v1.Namespace)
+	vpcList := &v1alpha1.VPCList{}
+	if err := e.Client.List(context.Background(), vpcList, client.InNamespace(obj.Name)); err != nil {
+		log.Error(err, "failed to list VPC in namespace", "namespace", obj.Name)
+		return
+	}
+	for _, item := range vpcList.Items {
+		log.Info("reconcile VPC because of namespace network selection change", "namespace", item.Namespace, "name", item.Name)
+		q.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.Name,
+				Namespace: item.Namespace,
+			},
+		})
+	}
+}
+
+// namespaceNetworkSelection returns the connectivity profile and the VPCNetworkConfiguration selected by the namespace.
+func namespaceNetworkSelection(ns *v1.Namespace) (string, string) {
+	return vpc.GetConnectivityProfileName(ns), vpc.GetVPCNetworkConfigName(ns)
+}
+
+var PredicateFuncsNs = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return false
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldProfile, oldConfig := namespaceNetworkSelection(e.ObjectOld.(*v1.Namespace))
+		newProfile, newConfig := namespaceNetworkSelection(e.ObjectNew.(*v1.Namespace))
+		if oldProfile == newProfile && oldConfig == newConfig {
+			log.V(2).Info("network selection of namespace is not changed, ignore it", "name", e.ObjectNew.GetName())
+			return false
+		}
+		return true
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return false
+	},
+}
@@ -0,0 +1,350 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package vpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log                              = logger.Log
+	ResultNormal                     = common.ResultNormal
+	ResultRequeue                    = common.ResultRequeue
+	ResultRequeueAfter5mins          = common.ResultRequeueAfter5mins
+	ResultRequeueAfterPaused         = common.ResultRequeueAfterPaused
+	ResultRequeueAfterNSXUnavailable = common.ResultRequeueAfterNSXUnavailable
+	MetricResType                    = common.MetricResTypeVPC
+)
+
+// VPCReconciler creates the NSX VPC for a VPC CR, the connectivity profile of the VPC is selected by the Namespace.
+type VPCReconciler struct {
+	Client   client.Client
+	Scheme   *apimachineryruntime.Scheme
+	Service  *vpc.VPCService
+	Recorder record.EventRecorder
+}
+
+func updateFail(r *VPCReconciler, c *context.Context, o *v1alpha1.VPC, e *error) {
+	r.setVPCReadyStatusFalse(c, o, e)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateFailTotal, MetricResType)
+}
+
+func deleteFail(r *VPCReconciler, c *context.Context, o *v1alpha1.VPC, e *error) {
+	r.setVPCReadyStatusFalse(c, o, e)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+}
+
+func updateSuccess(r *VPCReconciler, c *context.Context, o *v1alpha1.VPC, path string) {
+	r.setVPCReadyStatusTrue(c, o, path)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateSuccessTotal, MetricResType)
+}
+
+func deleteSuccess(r *VPCReconciler, _ *context.Context, _ *v1alpha1.VPC) {
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+}
+
+func (r *VPCReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := &v1alpha1.VPC{}
+	log.Info("reconciling VPC CR", "vpc", req.NamespacedName)
+	metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerSyncTotal, MetricResType)
+
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		log.Error(err, "unable to fetch VPC CR", "req", req.NamespacedName)
+		return ResultNormal, client.IgnoreNotFound(err)
+	}
+	logger.CorrelateObject(ctx, obj)
+
+	// VPCs can only be created from NSX 4.1.1 onwards with a valid license, so need to check NSX before creating them
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() && !r.Service.NSXClient.Capabilities().Supports(nsx.FeatureVPC) {
+		err := errors.New("NSX capability check failed, VPC feature is not supported")
+		updateFail(r, &ctx, obj, &err)
+		// it will be put back to reconcile queue and be reconciled after 5 minutes
+		return ResultRequeueAfter5mins, nil
+	}
+
+	if paused, reason := nsxutil.MutationsPaused(); paused {
+		log.Info("NSX mutations are paused, skip reconciling", "vpc", req.NamespacedName, "reason", reason)
+		return ResultRequeueAfterPaused, nil
+	}
+	if err := r.Service.NSXClient.CheckAvailable(); err != nil {
+		log.Info("NSX is unavailable, skip reconciling", "vpc", req.NamespacedName, "error", err)
+		return ResultRequeueAfterNSXUnavailable, nil
+	}
+
+	if obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerUpdateTotal, MetricResType)
+		if !controllerutil.ContainsFinalizer(obj, servicecommon.VPCFinalizerName) {
+			controllerutil.AddFinalizer(obj, servicecommon.VPCFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "add finalizer", "vpc", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("added finalizer on VPC CR", "vpc", req.NamespacedName)
+		}
+
+		ns := &v1.Namespace{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: req.Namespace}, ns); err != nil {
+			log.Error(err, "failed to fetch namespace of VPC CR", "vpc", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return ResultRequeue, err
+		}
+		profile, err := r.Service.GetConnectivityProfile(ns)
+		if err != nil {
+			log.Error(err, err.Error(), "vpc", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return ResultNormal, nil
+		}
+		nc := &v1alpha1.VPCNetworkConfiguration{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: vpc.GetVPCNetworkConfigName(ns)}, nc); err != nil {
+			log.Error(err, "failed to fetch VPCNetworkConfiguration of VPC CR", "vpc", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return ResultRequeue, err
+		}
+
+		nsxVPC, err := r.Service.CreateOrUpdateVPC(ctx, obj, nc, profile)
+		if err != nil {
+			if errors.As(err, &nsxutil.RestrictionError{}) {
+				log.Error(err, err.Error(), "vpc", req.NamespacedName)
+				updateFail(r, &ctx, obj, &err)
+				return ResultNormal, nil
+			}
+			log.Error(err, "operate failed, would retry exponentially", "vpc", req.NamespacedName)
+			updateFail(r, &ctx, obj, &err)
+			return common.ResultForError(err)
+		}
+		updateSuccess(r, &ctx, obj, *nsxVPC.Path)
+	} else {
+		if controllerutil.ContainsFinalizer(obj, servicecommon.VPCFinalizerName) {
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+			deleteVPC := r.Service.DeleteVPC
+			if obj.Spec.DeletionPolicy == v1alpha1.DeletionPolicyRetain {
+				log.Info("retaining NSX VPC", "vpc", req.NamespacedName)
+				deleteVPC = r.Service.RetainVPC
+			}
+			if err := deleteVPC(obj.UID); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "vpc", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return common.ResultForError(err)
+			}
+			controllerutil.RemoveFinalizer(obj, servicecommon.VPCFinalizerName)
+			if err := r.Client.Update(ctx, obj); err != nil {
+				log.Error(err, "deletion failed, would retry exponentially", "vpc", req.NamespacedName)
+				deleteFail(r, &ctx, obj, &err)
+				return ResultRequeue, err
+			}
+			log.V(1).Info("removed finalizer", "vpc", req.NamespacedName)
+			deleteSuccess(r, &ctx, obj)
+		} else {
+			// only print a message because it's not a normal case
+			log.Info("finalizers cannot be recognized", "vpc", req.NamespacedName)
+		}
+	}
+
+	return ResultNormal, nil
+}
+
+func (r *VPCReconciler) setVPCReadyStatusTrue(ctx *context.Context, obj *v1alpha1.VPC, path string) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionTrue,
+			Message: "NSX VPC has been successfully created/updated",
+			Reason:  "NSX API returned 200 response code for PATCH",
+		},
+	}
+	r.updateVPCStatus(ctx, obj, newConditions, path)
+}
+
+func (r *VPCReconciler) setVPCReadyStatusFalse(ctx *context.Context, obj *v1alpha1.VPC, err *error) {
+	newConditions := []v1alpha1.Condition{
+		{
+			Type:    v1alpha1.Ready,
+			Status:  v1.ConditionFalse,
+			Message: fmt.Sprintf("NSX VPC could not be created/updated, error occurred while processing the VPC CR. Error: %v", *err),
+			Reason:  common.ErrorReason(*err),
+		},
+	}
+	r.updateVPCStatus(ctx, obj, newConditions, obj.Status.NSXResourcePath)
+}
+
+func (r *VPCReconciler) updateVPCStatus(ctx *context.Context, obj *v1alpha1.VPC, newConditions []v1alpha1.Condition, path string) {
+	updated := obj.Status.NSXResourcePath != path
+	obj.Status.NSXResourcePath = path
+	for i := range newConditions {
+		if mergeVPCStatusCondition(obj, &newConditions[i]) {
+			updated = true
+		}
+	}
+	if updated {
+		r.Client.Status().Update(*ctx, obj)
+		log.V(1).Info("updated VPC", "Name", obj.Name, "Namespace", obj.Namespace,
+			"New Conditions", newConditions, "NSXResourcePath", path)
+	}
+}
+
+func mergeVPCStatusCondition(obj *v1alpha1.VPC, newCondition *v1alpha1.Condition) bool {
+	var matchedCondition *v1alpha1.Condition
+	for i := range obj.Status.Conditions {
+		if obj.Status.Conditions[i].Type == newCondition.Type {
+			matchedCondition = &obj.Status.Conditions[i]
+			break
+		}
+	}
+
+	if reflect.DeepEqual(matchedCondition, newCondition) {
+		log.V(2).Info("conditions already match", "New Condition", newCondition, "Existing Condition", matchedCondition)
+		return false
+	}
+
+	if matchedCondition != nil {
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		matchedCondition.Status = newCondition.Status
+	} else {
+		obj.Status.Conditions = append(obj.Status.Conditions, *newCondition)
+	}
+	return true
+}
+
+func (r *VPCReconciler) setupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.VPC{}, builder.WithPredicates(common.NotDeletingPredicate(r.Client))).
+		WithEventFilter(predicate.Funcs{
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Suppress Delete events to avoid filtering them out in the Reconcile function
+				return false
+			},
+		}).
+		WithOptions(
+			controller.Options{
+				MaxConcurrentReconciles: r.Service.NSXConfig.GetMaxConcurrentReconciles(config.ControllerVPC),
+				RateLimiter:             common.RateLimiter(r.Service.NSXConfig),
+			}).
+		Watches(
+			&source.Kind{Type: &v1.Namespace{}},
+			&EnqueueRequestForNamespace{Client: mgr.GetClient()},
+			builder.WithPredicates(PredicateFuncsNs),
+		).
+		// reconcile the CRs whose NSX resources are changed out of band
+		Watches(
+			common.StoreInvalidationSource(servicecommon.TagScopeVPCCRName, func() client.Object { return &v1alpha1.VPC{} }),
+			&handler.EnqueueRequestForObject{},
+		).
+		// reconcile the CRs of the namespaces the replica gains once the shard members change
+		Watches(
+			common.ShardRebalanceSource(&v1alpha1.VPCList{}),
+			&handler.EnqueueRequestForObject{},
+		).
+		Complete(common.Instrument(r.Service.NSXConfig, MetricResType, r)); err != nil {
+		return err
+	}
+	// reconcile the CRs being deleted ahead of the ones created or updated
+	return common.SetupDeletionController(mgr, r.Service.NSXConfig, MetricResType, &v1alpha1.VPC{}, r)
+}
+
+// Start setup manager and launch GC and the IP block usage reporter
+func (r *VPCReconciler) Start(mgr ctrl.Manager) error {
+	err := r.setupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+
+	common.RegisterGarbageCollector(common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage))
+	go common.ReportDrift(make(chan bool), servicecommon.DriftInterval, r.Service.NSXConfig, common.DriftSource{
+		ResourceType: MetricResType,
+		Client:       r.Client,
+		List:         &v1alpha1.VPCList{},
+		NSXUIDs:      r.Service.ListVPCCRUID,
+	})
+	common.RunWhenElected(mgr.Elected(), func() { r.IPBlockUsageReporter(make(chan bool), servicecommon.IPBlockUsageInterval) })
+	return nil
+}
+
+// collectGarbage deletes NSX VPCs whose VPC CRs have been removed, the ones tagged with the Retain deletion policy are
+// retained instead.
+func (r *VPCReconciler) collectGarbage(ctx context.Context, run *servicecommon.GCRun) error {
+	nsxVPCSet := r.Service.ListVPCCRUID()
+	run.Scanned(len(nsxVPCSet))
+	if len(nsxVPCSet) == 0 {
+		return nil
+	}
+	vpcList := &v1alpha1.VPCList{}
+	if err := r.Client.List(ctx, vpcList); err != nil {
+		log.Error(err, "failed to list VPC CR")
+		return err
+	}
+
+	CRVPCSet := sets.NewString()
+	for _, obj := range vpcList.Items {
+		CRVPCSet.Insert(string(obj.UID))
+	}
+
+	orphaned := nsxVPCSet.Difference(CRVPCSet)
+	run.Orphaned(servicecommon.TagScopeVPCCRUID, orphaned)
+	retainedSet := sets.NewString()
+	for elem := range orphaned {
+		if r.Service.VPCRetained(elem) {
+			retainedSet.Insert(elem)
+		}
+	}
+	// the retained VPCs are not deleted, they don't count against the deletion limits
+	if err := common.CheckGCDeletions(r.Service.NSXConfig, MetricResType, nsxVPCSet, CRVPCSet.Union(retainedSet)); err != nil {
+		return err
+	}
+
+	for elem := range nsxVPCSet {
+		if CRVPCSet.Has(elem) {
+			continue
+		}
+		if common.GCDryRun(MetricResType, elem) {
+			continue
+		}
+		if retainedSet.Has(elem) {
+			log.Info("GC retained VPC", "UID", elem)
+			if err := r.Service.RetainVPC(types.UID(elem)); err != nil {
+				log.Error(err, "failed to retain VPC", "UID", elem)
+			}
+			continue
+		}
+		log.V(1).Info("GC collected VPC CR", "UID", elem)
+		metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteTotal, MetricResType)
+		if err := r.Service.DeleteVPC(types.UID(elem)); err != nil {
+			run.Deleted(err)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteFailTotal, MetricResType)
+		} else {
+			run.Deleted(nil)
+			metrics.CounterInc(r.Service.NSXConfig, metrics.ControllerDeleteSuccessTotal, MetricResType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package vpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	servicecommon "github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+type fakeQueryClient struct{}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	count := int64(0)
+	return model.SearchResponse{ResultCount: &count}, nil
+}
+
+type fakeVPCClient struct {
+	projects.VpcsClient
+	patched map[string]model.Vpc
+	deleted []string
+}
+
+func (c *fakeVPCClient) Patch(_ string, _ string, vpcID string, vpc model.Vpc) error {
+	c.patched[vpcID] = vpc
+	return nil
+}
+
+func (c *fakeVPCClient) Delete(_ string, _ string, vpcID string) error {
+	c.deleted = append(c.deleted, vpcID)
+	return nil
+}
+
+type fakeSecurityPolicyClient struct {
+	vpcs.SecurityPoliciesClient
+	policies map[string]model.SecurityPolicy
+}
+
+func (c *fakeSecurityPolicyClient) Patch(_ string, _ string, vpcID string, _ string, policy model.SecurityPolicy) error {
+	c.policies[vpcID] = policy
+	return nil
+}
+
+func (c *fakeSecurityPolicyClient) Delete(_ string, _ string, vpcID string, _ string) error {
+	delete(c.policies, vpcID)
+	return nil
+}
+
+func newFakeVPCReconciler(t *testing.T, vpcClient *fakeVPCClient, objs ...apimachineryruntime.Object) *VPCReconciler {
+	scheme := apimachineryruntime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	nsxConfig := &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{},
+		VPCConnectivityProfiles: map[string]*config.VPCConnectivityProfile{
+			"restricted": {Name: "restricted", DefaultRuleAction: "DROP"},
+		},
+	}
+	service, err := vpc.InitializeVPC(servicecommon.Service{
+		NSXClient: &nsx.Client{
+			NsxConfig:               nsxConfig,
+			QueryClient:             &fakeQueryClient{},
+			VPCClient:               vpcClient,
+			VPCSecurityPolicyClient: &fakeSecurityPolicyClient{policies: map[string]model.SecurityPolicy{}},
+		},
+		NSXConfig: nsxConfig,
+	})
+	assert.Nil(t, err)
+	return &VPCReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme:   scheme,
+		Service:  service,
+		Recorder: record.NewFakeRecorder(10),
+	}
+}
+
+func TestVPCReconciler_Reconcile(t *testing.T) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "ns1",
+		Annotations: map[string]string{servicecommon.AnnotationVPCConnectivityProfile: vpc.ConnectivityProfileIsolated},
+	}}
+	nc := &v1alpha1.VPCNetworkConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: servicecommon.DefaultVPCNetworkConfigName},
+		Spec: v1alpha1.VPCNetworkConfigurationSpec{
+			NSXTProject:        "p1",
+			DefaultGatewayPath: "/infra/tier-0s/t0",
+		},
+	}
+	vpcCR := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"}}
+	vpcClient := &fakeVPCClient{patched: map[string]model.Vpc{}}
+	r := newFakeVPCReconciler(t, vpcClient, ns, nc, vpcCR)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "vpc1"}}
+
+	// isolated VPC is created
+	_, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.True(t, *vpcClient.patched["vpc_uid1"].ServiceGateway.Disable)
+	obj := &v1alpha1.VPC{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Contains(t, obj.Finalizers, servicecommon.VPCFinalizerName)
+	assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc_uid1", obj.Status.NSXResourcePath)
+	assert.Equal(t, v1.ConditionTrue, obj.Status.Conditions[0].Status)
+
+	// VPC is updated after the namespace selects the public profile
+	ns.Annotations[servicecommon.AnnotationVPCConnectivityProfile] = vpc.ConnectivityProfilePublic
+	assert.Nil(t, r.Client.Update(ctx, ns))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.False(t, *vpcClient.patched["vpc_uid1"].ServiceGateway.Disable)
+	assert.False(t, *vpcClient.patched["vpc_uid1"].ServiceGateway.AutoSnat)
+	assert.Equal(t, "/infra/tier-0s/t0", *vpcClient.patched["vpc_uid1"].DefaultGatewayPath)
+
+	// the profile defined in the config applies its default rule
+	policyClient := r.Service.NSXClient.VPCSecurityPolicyClient.(*fakeSecurityPolicyClient)
+	assert.Equal(t, 0, len(policyClient.policies))
+	ns.Annotations[servicecommon.AnnotationVPCConnectivityProfile] = "restricted"
+	assert.Nil(t, r.Client.Update(ctx, ns))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.True(t, *vpcClient.patched["vpc_uid1"].ServiceGateway.Disable)
+	assert.Equal(t, "DROP", *policyClient.policies["vpc_uid1"].Rules[0].Action)
+
+	// invalid profile is reported in the status without retry
+	ns.Annotations[servicecommon.AnnotationVPCConnectivityProfile] = "open"
+	assert.Nil(t, r.Client.Update(ctx, ns))
+	result, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, ResultNormal, result)
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Equal(t, v1.ConditionFalse, obj.Status.Conditions[0].Status)
+
+	// nothing is changed while the mutations are paused
+	nsxutil.SetMutationsPaused(true, "test")
+	result, err = r.Reconcile(ctx, req)
+	nsxutil.SetMutationsPaused(false, "")
+	assert.Nil(t, err)
+	assert.Equal(t, ResultRequeueAfterPaused, result)
+
+	// NSX VPC is deleted with the CR
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"vpc_uid1"}, vpcClient.deleted)
+	assert.Equal(t, 0, len(policyClient.policies))
+}
+
+func TestVPCReconciler_ReconcileRetain(t *testing.T) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "ns1",
+		Annotations: map[string]string{servicecommon.AnnotationVPCConnectivityProfile: vpc.ConnectivityProfileIsolated},
+	}}
+	nc := &v1alpha1.VPCNetworkConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: servicecommon.DefaultVPCNetworkConfigName},
+		Spec:       v1alpha1.VPCNetworkConfigurationSpec{NSXTProject: "p1"},
+	}
+	vpcCR := &v1alpha1.VPC{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.VPCSpec{DeletionPolicy: v1alpha1.DeletionPolicyRetain},
+	}
+	vpcClient := &fakeVPCClient{patched: map[string]model.Vpc{}}
+	r := newFakeVPCReconciler(t, vpcClient, ns, nc, vpcCR)
+	ctx := context.Background()
+	req := controllerruntime.Request{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "vpc1"}}
+
+	_, err := r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+	obj := &v1alpha1.VPC{}
+	assert.Nil(t, r.Client.Get(ctx, req.NamespacedName, obj))
+	assert.Nil(t, r.Client.Delete(ctx, obj))
+	_, err = r.Reconcile(ctx, req)
+	assert.Nil(t, err)
+
+	// NSX VPC is kept and tagged as retained
+	assert.Empty(t, vpcClient.deleted)
+	for _, tag := range vpcClient.patched["vpc_uid1"].Tags {
+		assert.NotEqual(t, servicecommon.TagScopeCluster, *tag.Scope)
+	}
+	assert.Equal(t, 0, r.Service.ListVPCCRUID().Len())
+	assert.True(t, apierrors.IsNotFound(r.Client.Get(ctx, req.NamespacedName, obj)))
+}
+
+func TestVPCReconciler_GarbageCollector(t *testing.T) {
+	nc := &v1alpha1.VPCNetworkConfiguration{Spec: v1alpha1.VPCNetworkConfigurationSpec{NSXTProject: "p1"}}
+	vpcClient := &fakeVPCClient{patched: map[string]model.Vpc{}}
+	r := newFakeVPCReconciler(t, vpcClient)
+	stale := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"}}
+	_, err := r.Service.CreateOrUpdateVPC(context.TODO(), stale, nc, &config.VPCConnectivityProfile{Name: vpc.ConnectivityProfileIsolated})
+	assert.Nil(t, err)
+
+	common.NewGarbageCollector(MetricResType, servicecommon.GCInterval, r.Service.NSXConfig, r.collectGarbage).RunOnce(context.TODO())
+	assert.Equal(t, 0, r.Service.ListVPCCRUID().Len())
+}
+
+func TestVPCReconciler_GarbageCollectorRetain(t *testing.T) {
+	nc := &v1alpha1.VPCNetworkConfiguration{Spec: v1alpha1.VPCNetworkConfigurationSpec{NSXTProject: "p1"}}
+	vpcClient := &fakeVPCClient{patched: map[string]model.Vpc{}}
+	r := newFakeVPCReconciler(t, vpcClient)
+	retained := &v1alpha1.VPC{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.VPCSpec{DeletionPolicy: v1alpha1.DeletionPolicyRetain},
+	}
+	stale := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc2", Namespace: "ns1", UID: "uid2"}}
+	for _, obj := range []*v1alpha1.VPC{retained, stale} {
+		_, err := r.Service.CreateOrUpdateVPC(context.TODO(), obj, nc, &config.VPCConnectivityProfile{Name: vpc.ConnectivityProfileIsolated})
+		assert.Nil(t, err)
+	}
+	assert.True(t, r.Service.VPCRetained("uid1"))
+	assert.False(t, r.Service.VPCRetained("uid2"))
+
+	// the retained VPC doesn't count against the deletion limits
+	common.SetGCDeletionLimits(1, 0)
+	defer common.SetGCDeletionLimits(0, 0)
+	run := servicecommon.StartGCRun(r.Service.NSXConfig, MetricResType)
+	assert.Nil(t, r.collectGarbage(context.TODO(), run))
+
+	// the NSX VPC of the Retain policy is kept and tagged as retained, the other one is deleted
+	assert.Equal(t, []string{"vpc_uid2"}, vpcClient.deleted)
+	for _, tag := range vpcClient.patched["vpc_uid1"].Tags {
+		assert.NotEqual(t, servicecommon.TagScopeCluster, *tag.Scope)
+	}
+	assert.Equal(t, 0, r.Service.ListVPCCRUID().Len())
+}
+
+func TestVPCReconciler_GarbageCollectorAborted(t *testing.T) {
+	nc := &v1alpha1.VPCNetworkConfiguration{Spec: v1alpha1.VPCNetworkConfigurationSpec{NSXTProject: "p1"}}
+	vpcClient := &fakeVPCClient{patched: map[string]model.Vpc{}}
+	r := newFakeVPCReconciler(t, vpcClient)
+	stale := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"}}
+	_, err := r.Service.CreateOrUpdateVPC(context.TODO(), stale, nc, &config.VPCConnectivityProfile{Name: vpc.ConnectivityProfileIsolated})
+	assert.Nil(t, err)
+
+	common.SetGCDeletionLimits(0, 50)
+	defer common.SetGCDeletionLimits(0, 0)
+	run := servicecommon.StartGCRun(r.Service.NSXConfig, MetricResType)
+	assert.EqualError(t, r.collectGarbage(context.TODO(), run), "garbage collection aborted, 1 of 1 NSX resources would be deleted, more than 50%")
+	assert.Equal(t, 1, r.Service.ListVPCCRUID().Len())
+}
+
+func TestPredicateFuncsNs(t *testing.T) {
+	oldNs := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"a": "b"}}}
+	newNs := oldNs.DeepCopy()
+	newNs.Labels["a"] = "c"
+	assert.False(t, PredicateFuncsNs.Update(event.UpdateEvent{ObjectOld: oldNs, ObjectNew: newNs}))
+	newNs.Labels[servicecommon.AnnotationVPCConnectivityProfile] = vpc.ConnectivityProfilePublic
+	assert.True(t, PredicateFuncsNs.Update(event.UpdateEvent{ObjectOld: oldNs, ObjectNew: newNs}))
+	newNs = oldNs.DeepCopy()
+	newNs.Annotations = map[string]string{servicecommon.AnnotationVPCNetworkConfig: "nc1"}
+	assert.True(t, PredicateFuncsNs.Update(event.UpdateEvent{ObjectOld: oldNs, ObjectNew: newNs}))
+}
@@ -0,0 +1,160 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	commonctl "github.com/vmware-tanzu/nsx-operator/pkg/controllers/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/audit"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// StoresPath is the path of the endpoint dumping the stores, the reconciles being run and the depths of the work queues.
+const StoresPath = "/debug/stores"
+
+// GCPath is the path of the endpoint triggering the garbage collectors of a resource type.
+const GCPath = "/debug/gc"
+
+const (
+	workQueueDepthMetric = "workqueue_depth"
+	shutdownTimeout      = 5 * time.Second
+)
+
+var log = logger.Log
+
+// Dump is the state of NSX Operator collected in the support bundles.
+type Dump struct {
+	// Stores are the NSX resources cached by the services, keyed by resource type.
+	Stores map[string][]interface{} `json:"stores"`
+	// Reconciles are the reconciles being run, the earliest first.
+	Reconciles []commonctl.Reconcile `json:"reconciles"`
+	// WorkQueues are the items waiting in the work queue of each controller, keyed by controller.
+	WorkQueues map[string]int `json:"workQueues"`
+}
+
+// Collect dumps the stores, the reconciles and the work queues.
+func Collect() Dump {
+	return Dump{
+		Stores:     common.DumpStores(),
+		Reconciles: commonctl.InflightReconciles(),
+		WorkQueues: workQueueDepths(),
+	}
+}
+
+// workQueueDepths reads the depths of the work queues from the workqueue metrics of controller-runtime.
+func workQueueDepths() map[string]int {
+	depths := map[string]int{}
+	families, err := ctrlmetrics.Registry.Gather()
+	if err != nil {
+		log.Error(err, "failed to gather the work queue metrics")
+	}
+	for _, family := range families {
+		if family.GetName() != workQueueDepthMetric {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "name" {
+					depths[label.GetValue()] = int(metric.GetGauge().GetValue())
+				}
+			}
+		}
+	}
+	return depths
+}
+
+// StoresHandler serves the dump as JSON.
+func StoresHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(Collect()); err != nil {
+		log.Error(err, "failed to dump the stores")
+	}
+}
+
+// GCHandler triggers the garbage collectors of the resource type of the resourceType parameter on POST, e.g.
+// ?resourceType=vpc, they run at once instead of waiting for their next run.
+func GCHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	resourceType := r.URL.Query().Get("resourceType")
+	if resourceType == "" {
+		http.Error(w, "resourceType is required", http.StatusBadRequest)
+		return
+	}
+	if err := commonctl.TriggerGarbageCollection(resourceType); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	log.Info("garbage collection triggered by request", "resourceType", resourceType)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Server serves the debug endpoints, the dumps, the audit trail of the NSX mutations and the garbage collection
+// trigger, on an address which should only be reachable from the pod, e.g. 127.0.0.1:8385, so that they are only used
+// by the users allowed to exec into the pod, e.g. with the dump-stores command.
+type Server struct {
+	Addr string
+}
+
+// Start serves the endpoints until ctx is done, it is run by the manager.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(StoresPath, StoresHandler)
+	mux.HandleFunc(audit.Path, audit.Handler)
+	mux.HandleFunc(GCPath, GCHandler)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: shutdownTimeout}
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to serve the debug endpoints on %s: %w", s.Addr, err)
+	}
+	log.Info("serving the debug endpoints", "address", s.Addr)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection returns false, all the replicas serve the debug endpoints.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Fetch writes the dump served on addr to out.
+func Fetch(addr string, out io.Writer) error {
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Get("http://" + addr + StoresPath)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to dump the stores: %s", resp.Status)
+	}
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
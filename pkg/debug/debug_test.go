@@ -0,0 +1,75 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestStoresHandler(t *testing.T) {
+	// the work queues of the controllers report their depths through the provider of controller-runtime
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "debug-test")
+	defer queue.ShutDown()
+	queue.Add("ns1/name1")
+	queue.Add("ns1/name2")
+
+	recorder := httptest.NewRecorder()
+	StoresHandler(recorder, httptest.NewRequest(http.MethodGet, StoresPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	dump := Dump{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &dump))
+	assert.Equal(t, 2, dump.WorkQueues["debug-test"])
+	assert.NotNil(t, dump.Stores)
+
+	recorder = httptest.NewRecorder()
+	StoresHandler(recorder, httptest.NewRequest(http.MethodPut, StoresPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- (&Server{Addr: addr}).Start(ctx) }()
+
+	var out bytes.Buffer
+	assert.Eventually(t, func() bool {
+		out.Reset()
+		return Fetch(addr, &out) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, out.String(), `"stores"`)
+
+	cancel()
+	assert.Nil(t, <-done)
+}
+
+func TestGCHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	GCHandler(recorder, httptest.NewRequest(http.MethodGet, GCPath+"?resourceType=vpc", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	GCHandler(recorder, httptest.NewRequest(http.MethodPost, GCPath, nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// no garbage collector is registered
+	recorder = httptest.NewRecorder()
+	GCHandler(recorder, httptest.NewRequest(http.MethodPost, GCPath+"?resourceType=vpc", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "no garbage collector of vpc")
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CorrelationEnabled returns whether the reconciles carry the correlation IDs, they are only logged in the JSON
+// format, as binding the NSX clients to the context of each reconcile costs an SDK client per call.
+func CorrelationEnabled() bool {
+	return logFormat == LogFormatJSON
+}
+
+// The keys of the correlation IDs in the logs.
+const (
+	CorrelationKeyController   = "controller"
+	CorrelationKeyReconcileID  = "reconcileID"
+	CorrelationKeyNamespace    = "namespace"
+	CorrelationKeyName         = "name"
+	CorrelationKeyUID          = "uid"
+	CorrelationKeyNSXRequestID = "nsxRequestID"
+)
+
+type correlationKey struct{}
+
+// correlation holds the key/values identifying a user action, e.g. the reconcile of a CR, so that the logs of the
+// NSX requests sent for it can be correlated with it.
+type correlation struct {
+	lock          sync.Mutex
+	keysAndValues []interface{}
+}
+
+// WithCorrelation returns ctx carrying the correlation key/values of a user action, e.g. the reconcile ID and the
+// namespace and name of the CR.
+func WithCorrelation(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	return context.WithValue(ctx, correlationKey{}, &correlation{keysAndValues: keysAndValues})
+}
+
+// AddCorrelation adds key/values to the correlation of ctx, they are logged by the loggers FromContext returns
+// afterwards. Nothing is added if ctx carries no correlation.
+func AddCorrelation(ctx context.Context, keysAndValues ...interface{}) {
+	if c, ok := ctx.Value(correlationKey{}).(*correlation); ok {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		c.keysAndValues = append(c.keysAndValues, keysAndValues...)
+	}
+}
+
+// CorrelateObject adds the UID of the CR to the correlation of ctx once it is read.
+func CorrelateObject(ctx context.Context, obj metav1.Object) {
+	AddCorrelation(ctx, CorrelationKeyUID, string(obj.GetUID()))
+}
+
+// HasCorrelation returns whether ctx carries a correlation, so that the callers can skip the work only needed to
+// correlate the logs.
+func HasCorrelation(ctx context.Context) bool {
+	_, ok := ctx.Value(correlationKey{}).(*correlation)
+	return ok
+}
+
+// CorrelationValue returns the value of the correlation key of ctx, "" if ctx carries no such key.
+func CorrelationValue(ctx context.Context, key string) string {
+	c, ok := ctx.Value(correlationKey{}).(*correlation)
+	if !ok {
+		return ""
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i := 0; i+1 < len(c.keysAndValues); i += 2 {
+		if c.keysAndValues[i] == key {
+			value, _ := c.keysAndValues[i+1].(string)
+			return value
+		}
+	}
+	return ""
+}
+
+// FromContext returns log logging the correlation key/values of ctx, or log itself if ctx carries no correlation.
+func FromContext(ctx context.Context, log logr.Logger) logr.Logger {
+	c, ok := ctx.Value(correlationKey{}).(*correlation)
+	if !ok {
+		return log
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return log.WithValues(c.keysAndValues...)
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCorrelation(t *testing.T) {
+	var lines []string
+	log := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})
+
+	ctx := context.TODO()
+	assert.False(t, HasCorrelation(ctx))
+	AddCorrelation(ctx, CorrelationKeyNSXRequestID, "ignored")
+	FromContext(ctx, log).Info("no correlation")
+
+	ctx = WithCorrelation(ctx, CorrelationKeyReconcileID, "r1", CorrelationKeyNamespace, "ns1", CorrelationKeyName, "vpc1")
+	assert.True(t, HasCorrelation(ctx))
+	CorrelateObject(ctx, &metav1.ObjectMeta{UID: "uid1"})
+	FromContext(ctx, log).Info("correlated")
+
+	assert.Equal(t, "uid1", CorrelationValue(ctx, CorrelationKeyUID))
+	assert.Equal(t, "", CorrelationValue(ctx, CorrelationKeyController))
+	assert.Equal(t, "", CorrelationValue(context.TODO(), CorrelationKeyUID))
+	assert.Equal(t, []string{
+		`"level"=0 "msg"="no correlation"`,
+		`"level"=0 "msg"="correlated" "reconcileID"="r1" "namespace"="ns1" "name"="vpc1" "uid"="uid1"`,
+	}, lines)
+}
+
+func TestLogFormatJSON(t *testing.T) {
+	defer func() { logFormat = LogFormatText }()
+	assert.False(t, CorrelationEnabled())
+	logFormat = LogFormatJSON
+	assert.True(t, CorrelationEnabled())
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// errorKey is the key of the error of the logs of logr.Logger.Error.
+const errorKey = "error"
+
+// dedupInterval is the interval the repeated identical errors are summarized over, 0 if they are all logged.
+var dedupInterval int64
+
+// SetDedupInterval sets the interval the repeated identical errors are summarized over, e.g. while NSX is down every
+// reconcile fails with the same error. The first error is logged, the ones repeated within the interval are counted
+// and logged once as a summary when the interval ends. The errors are all logged if interval is 0.
+func SetDedupInterval(interval time.Duration) {
+	atomic.StoreInt64(&dedupInterval, int64(interval))
+}
+
+// repeatedError is an error logged at the start of an interval and the count of its repetitions within the interval.
+type repeatedError struct {
+	// core, entry and fields are the ones of the last repetition, the summary is written with them.
+	core      zapcore.Core
+	entry     zapcore.Entry
+	fields    []zapcore.Field
+	start     time.Time
+	count     int
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// dedup holds the errors logged within their intervals, keyed by logger, message, error and the other fields, which are
+// shared by the cores created by With.
+type dedup struct {
+	errors map[string]*repeatedError
+	sync.Mutex
+}
+
+// dedupCore summarizes the repeated identical errors written to the core it wraps.
+type dedupCore struct {
+	zapcore.Core
+	dedup *dedup
+	// context are the fields added by With, they are a part of the keys of the errors like the fields of the logs.
+	context []zapcore.Field
+}
+
+func newDedupCore(core zapcore.Core) *dedupCore {
+	return &dedupCore{Core: core, dedup: &dedup{errors: map[string]*repeatedError{}}}
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), dedup: c.dedup, context: append(c.context[:len(c.context):len(c.context)], fields...)}
+}
+
+func (c *dedupCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *dedupCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	interval := time.Duration(atomic.LoadInt64(&dedupInterval))
+	if interval <= 0 || entry.Level < zapcore.ErrorLevel {
+		return c.Core.Write(entry, fields)
+	}
+	c.flush(entry.Time)
+	key := entry.LoggerName + "\x00" + entry.Message + "\x00" + errorOf(fields) + "\x00" + identityOf(c.context, fields)
+
+	c.dedup.Lock()
+	repeated, ok := c.dedup.errors[key]
+	if ok {
+		if repeated.count == 0 {
+			repeated.firstSeen = entry.Time
+		}
+		repeated.core, repeated.entry, repeated.fields = c.Core, entry, fields
+		repeated.count++
+		repeated.lastSeen = entry.Time
+		c.dedup.Unlock()
+		return nil
+	}
+	c.dedup.errors[key] = &repeatedError{start: entry.Time}
+	c.dedup.Unlock()
+	return c.Core.Write(entry, fields)
+}
+
+func (c *dedupCore) Sync() error {
+	c.flush(time.Now())
+	return c.Core.Sync()
+}
+
+// flush writes the summaries of the errors whose intervals ended before now, the next occurrence of these errors is
+// logged again.
+func (c *dedupCore) flush(now time.Time) {
+	interval := time.Duration(atomic.LoadInt64(&dedupInterval))
+	var summaries []*repeatedError
+	c.dedup.Lock()
+	for key, repeated := range c.dedup.errors {
+		if now.Sub(repeated.start) < interval {
+			continue
+		}
+		delete(c.dedup.errors, key)
+		if repeated.count > 0 {
+			summaries = append(summaries, repeated)
+		}
+	}
+	c.dedup.Unlock()
+
+	for _, repeated := range summaries {
+		entry := repeated.entry
+		entry.Time = now
+		fields := append(repeated.fields[:len(repeated.fields):len(repeated.fields)],
+			zap.Int("repeated", repeated.count), zap.Time("firstSeen", repeated.firstSeen), zap.Time("lastSeen", repeated.lastSeen))
+		repeated.core.Write(entry, fields)
+	}
+}
+
+// flushPeriodically writes the summaries of the errors which are not repeated after their intervals end.
+func (c *dedupCore) flushPeriodically(period time.Duration) {
+	for {
+		time.Sleep(period)
+		if atomic.LoadInt64(&dedupInterval) > 0 {
+			c.flush(time.Now())
+		}
+	}
+}
+
+// identityOf returns the fields of the log other than the error, e.g. the namespace and name of the object failed, so
+// that the same error of different objects is not summarized as one.
+func identityOf(fieldSets ...[]zapcore.Field) string {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, fields := range fieldSets {
+		for _, field := range fields {
+			if field.Key != errorKey {
+				field.AddTo(enc)
+			}
+		}
+	}
+	// the keys of the map are printed sorted
+	return fmt.Sprint(enc.Fields)
+}
+
+// errorOf returns the error of the log, empty if it has none.
+func errorOf(fields []zapcore.Field) string {
+	for _, field := range fields {
+		if field.Key != errorKey {
+			continue
+		}
+		if err, ok := field.Interface.(error); ok && err != nil {
+			return err.Error()
+		}
+		return field.String
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDedupCore(t *testing.T) {
+	defer SetDedupInterval(0)
+	observed, logs := observer.New(zapcore.InfoLevel)
+	core := newDedupCore(observed)
+	logger := zap.New(core).Named("nsx-operator")
+	down := errors.New("connection refused")
+
+	// the errors are all logged if the dedup is disabled
+	logger.Error("failed to reconcile", zap.NamedError(errorKey, down))
+	logger.Error("failed to reconcile", zap.NamedError(errorKey, down))
+	assert.Equal(t, 2, len(logs.TakeAll()))
+
+	SetDedupInterval(time.Hour)
+	for i := 0; i < 3; i++ {
+		logger.With(zap.String("name", "vpc1")).Error("failed to reconcile", zap.NamedError(errorKey, down))
+	}
+	// another error, the same error of other objects and the other levels are not deduplicated
+	logger.Error("failed to reconcile", zap.NamedError(errorKey, errors.New("forbidden")))
+	logger.With(zap.String("name", "vpc2")).Error("failed to reconcile", zap.NamedError(errorKey, down))
+	logger.Error("failed to reconcile", zap.String("name", "vpc3"), zap.NamedError(errorKey, down))
+	logger.Info("reconciled")
+	logger.Info("reconciled")
+	entries := logs.TakeAll()
+	assert.Equal(t, 6, len(entries))
+	assert.Equal(t, "connection refused", entries[0].ContextMap()[errorKey])
+	assert.Equal(t, "forbidden", entries[1].ContextMap()[errorKey])
+	assert.Equal(t, "vpc2", entries[2].ContextMap()["name"])
+	assert.Equal(t, "vpc3", entries[3].ContextMap()["name"])
+
+	// the repetitions are summarized once the interval ends
+	core.flush(time.Now())
+	assert.Equal(t, 0, logs.Len())
+	core.flush(time.Now().Add(time.Hour))
+	entries = logs.TakeAll()
+	assert.Equal(t, 1, len(entries))
+	summary := entries[0].ContextMap()
+	assert.Equal(t, "failed to reconcile", entries[0].Message)
+	assert.Equal(t, int64(2), summary["repeated"])
+	assert.Equal(t, "vpc1", summary["name"])
+	assert.Contains(t, summary, "firstSeen")
+	assert.Contains(t, summary, "lastSeen")
+
+	// the error is logged again after the interval
+	logger.With(zap.String("name", "vpc1")).Error("failed to reconcile", zap.NamedError(errorKey, down))
+	assert.Equal(t, 1, logs.Len())
+}
@@ -11,10 +11,28 @@ import (
 	zapcr "sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
-const logTmFmtWithMS = "2006-01-02 15:04:05.000"
+const (
+	logTmFmtWithMS = "2006-01-02 15:04:05.000"
+	// dedupFlushPeriod is the period to write the summaries of the repeated errors whose intervals ended.
+	dedupFlushPeriod = 10 * time.Second
+)
+
+// The formats of the logs set by --log-format.
+const (
+	LogFormatText = "text"
+	// LogFormatJSON logs an object per line, with the name of the logger and the key/values of the log as fields, for
+	// the log aggregation systems.
+	LogFormatJSON = "json"
+)
 
 var (
-	logLevel          int
+	logLevel  int
+	logFormat string
+	// level is the lowest level of the logs of all the modules, the logs of each module are filtered by its level.
+	level = zap.NewAtomicLevel()
+	// globalLevel is the level of the logs of the modules without a level, it is changed by SetLogLevel without
+	// recreating the logger.
+	globalLevel       = zap.NewAtomicLevel()
 	Log               logr.Logger
 	customTimeEncoder = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 		enc.AppendString(t.Format(logTmFmtWithMS))
@@ -26,6 +44,7 @@ var (
 
 func init() {
 	flag.IntVar(&logLevel, "log-level", 0, "Use zap-core log system.")
+	flag.StringVar(&logFormat, "log-format", LogFormatText, "Format of the logs, text or json.")
 	Log = logf.Log.WithName("nsx-operator")
 }
 
@@ -44,10 +63,20 @@ func ZapLogger() logr.Logger {
 		EncodeName:     zapcore.FullNameEncoder,
 	}
 
+	encoder := zapcore.NewConsoleEncoder(encoderConf)
+	if logFormat == LogFormatJSON {
+		encoderConf.NameKey = "logger"
+		encoderConf.LevelKey = "level"
+		encoderConf.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		encoderConf.EncodeLevel = zapcore.LowercaseLevelEncoder
+		encoderConf.EncodeDuration = zapcore.StringDurationEncoder
+		encoder = zapcore.NewJSONEncoder(encoderConf)
+	}
+
 	opts := zapcr.Options{
 		Development:     true,
 		Level:           zap.NewAtomicLevelAt(zap.InfoLevel),
-		Encoder:         zapcore.NewConsoleEncoder(encoderConf),
+		Encoder:         encoder,
 		StacktraceLevel: zap.FatalLevel,
 	}
 	opts.BindFlags(flag.CommandLine)
@@ -55,11 +84,26 @@ func ZapLogger() logr.Logger {
 	// In level.go of zapcore, higher levels are more important.
 	// However, in logr.go, a higher verbosity level means a log message is less important.
 	// So we need to reverse the order of the levels.
-	opts.Level = zapcore.Level(-1 * logLevel)
-	opts.ZapOpts = append(opts.ZapOpts, zap.AddCaller(), zap.AddCallerSkip(0))
+	globalLevel.SetLevel(zapcore.Level(-1 * logLevel))
+	updateLevel()
+	opts.Level = level
+	opts.ZapOpts = append(opts.ZapOpts, zap.AddCaller(), zap.AddCallerSkip(0), zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		dedup := newDedupCore(core)
+		go dedup.flushPeriodically(dedupFlushPeriod)
+		return &moduleCore{Core: dedup}
+	}))
 	if logLevel > 0 {
 		opts.StacktraceLevel = zap.ErrorLevel
 	}
 
 	return zapcr.New(zapcr.UseFlagOptions(&opts))
 }
+
+// SetLogLevel changes the verbosity of the logs, the level of the --log-level flag is restored if it is 0.
+func SetLogLevel(verbosity int) {
+	if verbosity <= 0 {
+		verbosity = logLevel
+	}
+	globalLevel.SetLevel(zapcore.Level(-1 * verbosity))
+	updateLevel()
+}
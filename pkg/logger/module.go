@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LogLevelPath is the path of the endpoint of the metrics server reading and changing the log levels, e.g.
+// PUT /debug/loglevel?module=nsx.wire&level=2.
+const LogLevelPath = "/debug/loglevel"
+
+// The modules whose verbosity can be set apart, a module is the name of the loggers, it covers the loggers named
+// after it too, e.g. nsx covers nsx.cluster.
+const (
+	// ModuleOperator logs the controllers and the services.
+	ModuleOperator = "nsx-operator"
+	// ModuleNSX logs the NSX client.
+	ModuleNSX = "nsx"
+	// ModuleNSXWire logs the requests sent to NSX and the responses with their payloads at verbosity 2.
+	ModuleNSXWire = "nsx.wire"
+)
+
+var (
+	// moduleLevels are the levels of the modules set apart from the global level, keyed by module.
+	moduleLevels = map[string]zapcore.Level{}
+	moduleLock   sync.RWMutex
+)
+
+// LogLevels are the verbosity of the logs and of the modules set apart.
+type LogLevels struct {
+	Level   int            `json:"level"`
+	Modules map[string]int `json:"modules"`
+}
+
+// moduleCore drops the entries below the level of the module of their logger, the core it wraps is enabled at the
+// lowest level of all the modules.
+type moduleCore struct {
+	zapcore.Core
+}
+
+func (c *moduleCore) With(fields []zapcore.Field) zapcore.Core {
+	return &moduleCore{Core: c.Core.With(fields)}
+}
+
+func (c *moduleCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !levelOf(entry.LoggerName).Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// levelOf returns the level of the most specific module of the logger, or the global level.
+func levelOf(loggerName string) zapcore.Level {
+	moduleLock.RLock()
+	defer moduleLock.RUnlock()
+	found, matched := globalLevel.Level(), ""
+	for module, level := range moduleLevels {
+		if (loggerName == module || strings.HasPrefix(loggerName, module+".")) && len(module) > len(matched) {
+			found, matched = level, module
+		}
+	}
+	return found
+}
+
+// updateLevel enables the logger at the lowest level of all the modules.
+func updateLevel() {
+	moduleLock.RLock()
+	defer moduleLock.RUnlock()
+	lowest := globalLevel.Level()
+	for _, level := range moduleLevels {
+		if level < lowest {
+			lowest = level
+		}
+	}
+	level.SetLevel(lowest)
+}
+
+// SetModuleLogLevel changes the verbosity of the logs of the module, the module logs at the global level again if
+// verbosity is negative.
+func SetModuleLogLevel(module string, verbosity int) {
+	moduleLock.Lock()
+	if verbosity < 0 {
+		delete(moduleLevels, module)
+	} else {
+		moduleLevels[module] = zapcore.Level(-1 * verbosity)
+	}
+	moduleLock.Unlock()
+	updateLevel()
+}
+
+// SetModuleLogLevels replaces the verbosity of the modules set apart, keyed by module.
+func SetModuleLogLevels(verbosities map[string]int) {
+	moduleLock.Lock()
+	moduleLevels = make(map[string]zapcore.Level, len(verbosities))
+	for module, verbosity := range verbosities {
+		if verbosity >= 0 {
+			moduleLevels[module] = zapcore.Level(-1 * verbosity)
+		}
+	}
+	moduleLock.Unlock()
+	updateLevel()
+}
+
+// GetLogLevels returns the global verbosity and the verbosity of the modules set apart.
+func GetLogLevels() LogLevels {
+	moduleLock.RLock()
+	defer moduleLock.RUnlock()
+	levels := LogLevels{Level: -1 * int(globalLevel.Level()), Modules: make(map[string]int, len(moduleLevels))}
+	for module, level := range moduleLevels {
+		levels.Modules[module] = -1 * int(level)
+	}
+	return levels
+}
+
+// LogLevelHandler serves the log levels on GET, and changes the global verbosity or the one of the module on PUT or
+// POST, e.g. ?level=1 or ?module=nsx.wire&level=2, level=-1 resets the module to the global verbosity.
+type LogLevelHandler struct{}
+
+func (h LogLevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		verbosity, err := strconv.Atoi(r.URL.Query().Get("level"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid level %q", r.URL.Query().Get("level")), http.StatusBadRequest)
+			return
+		}
+		module := r.URL.Query().Get("module")
+		if module == "" {
+			if verbosity < 0 {
+				http.Error(w, "the global level can't be negative", http.StatusBadRequest)
+				return
+			}
+			Log.Info("log level changed by request", "level", verbosity)
+			globalLevel.SetLevel(zapcore.Level(-1 * verbosity))
+			updateLevel()
+		} else {
+			Log.Info("module log level changed by request", "module", module, "level", verbosity)
+			SetModuleLogLevel(module, verbosity)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(GetLogLevels())
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func resetLogLevels() {
+	globalLevel.SetLevel(zapcore.InfoLevel)
+	SetModuleLogLevels(nil)
+}
+
+func TestModuleCore(t *testing.T) {
+	defer resetLogLevels()
+	observed, logs := observer.New(level)
+	logger := zap.New(&moduleCore{Core: observed})
+
+	SetModuleLogLevels(map[string]int{ModuleNSXWire: 2, "nsx-operator": -1})
+	assert.Equal(t, zapcore.Level(-2), level.Level())
+	logger.Named("nsx").Named("wire").Log(zapcore.Level(-2), "wire")
+	logger.Named("nsx").Named("cluster").Log(zapcore.Level(-2), "cluster")
+	logger.Named("nsx").Named("cluster").Info("cluster info")
+	logger.Named("nsx-operator").Log(zapcore.Level(-1), "operator")
+	var messages []string
+	for _, entry := range logs.All() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"wire", "cluster info"}, messages)
+
+	// the most specific module applies
+	SetModuleLogLevel(ModuleNSX, 0)
+	SetModuleLogLevel(ModuleNSXWire, 1)
+	assert.Equal(t, zapcore.Level(-1), levelOf("nsx.wire"))
+	assert.Equal(t, zapcore.InfoLevel, levelOf("nsx.cluster"))
+	assert.Equal(t, zapcore.InfoLevel, levelOf("nsx-operator"))
+	SetModuleLogLevel(ModuleNSXWire, -1)
+	assert.Equal(t, LogLevels{Level: 0, Modules: map[string]int{ModuleNSX: 0}}, GetLogLevels())
+	assert.Equal(t, zapcore.InfoLevel, level.Level())
+}
+
+func TestLogLevelHandler(t *testing.T) {
+	defer resetLogLevels()
+	serve := func(method, query string) (*httptest.ResponseRecorder, LogLevels) {
+		w := httptest.NewRecorder()
+		LogLevelHandler{}.ServeHTTP(w, httptest.NewRequest(method, LogLevelPath+query, nil))
+		levels := LogLevels{}
+		if w.Code == http.StatusOK {
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &levels))
+		}
+		return w, levels
+	}
+
+	w, levels := serve(http.MethodPut, "?module=nsx.wire&level=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, LogLevels{Level: 0, Modules: map[string]int{ModuleNSXWire: 2}}, levels)
+	w, levels = serve(http.MethodPost, "?level=1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, levels.Level)
+	_, levels = serve(http.MethodGet, "")
+	assert.Equal(t, LogLevels{Level: 1, Modules: map[string]int{ModuleNSXWire: 2}}, levels)
+
+	w, _ = serve(http.MethodPut, "?level=high")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = serve(http.MethodPut, "?level=-1")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = serve(http.MethodDelete, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+const (
+	ReconcileTotalKey    = "reconcile_total"
+	ReconcileDurationKey = "reconcile_duration_seconds"
+	ReconcileErrorsKey   = "reconcile_errors_total"
+	RequeueTotalKey      = "requeue_total"
+	StoreSizeKey         = "store_size"
+
+	// The results of the reconciles.
+	ReconcileResultSuccess = "success"
+	ReconcileResultError   = "error"
+	ReconcileResultRequeue = "requeue"
+)
+
+var (
+	ReconcileTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      ReconcileTotalKey,
+			Help:      "Total number of the reconciles of the controllers, by result: success, error or requeue",
+		},
+		[]string{"controller", "result"},
+	)
+	ReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      ReconcileDurationKey,
+			Help:      "Seconds the controllers take to reconcile a CR",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+		},
+		[]string{"controller"},
+	)
+	ReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      ReconcileErrorsKey,
+			Help:      "Total number of the reconciles failed, by the class of the error, e.g. NSXConflict",
+		},
+		[]string{"controller", "class"},
+	)
+	RequeueTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      RequeueTotalKey,
+			Help:      "Total number of the CRs requeued by the controllers, by the reason of the requeue",
+		},
+		[]string{"controller", "reason"},
+	)
+)
+
+// StoreSize reports the number of the NSX resources cached in the stores of the services, keyed by resource type.
+var StoreSize = &storeSizeCollector{
+	desc: prometheus.NewDesc(prometheus.BuildFQName(MetricNamespace, MetricSubsystem, StoreSizeKey),
+		"Number of the NSX resources cached in the stores of NSX Operator", []string{"resource_type"}, nil),
+	stores: map[string]func() int{},
+}
+
+// storeSizeCollector counts the resources of the stores when the metrics are scraped, so that the stores don't update
+// a gauge on every change.
+type storeSizeCollector struct {
+	desc   *prometheus.Desc
+	lock   sync.Mutex
+	stores map[string]func() int
+}
+
+func (c *storeSizeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *storeSizeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for resourceType, size := range c.stores {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(size()), resourceType)
+	}
+}
+
+// RegisterStore reports the size of the store of resourceType, size is called when the metrics are scraped.
+func RegisterStore(resourceType string, size func() int) {
+	StoreSize.lock.Lock()
+	defer StoreSize.lock.Unlock()
+	StoreSize.stores[resourceType] = size
+}
+
+// ObserveReconcile records a reconcile of the controller: its result and duration, the class of its error if it
+// failed, and the reason of the requeue if the CR is requeued.
+func ObserveReconcile(cf *config.NSXOperatorConfig, controller string, duration time.Duration, result, errorClass, requeueReason string) {
+	if !AreMetricsExposed(cf) {
+		return
+	}
+	ReconcileTotal.WithLabelValues(controller, result).Inc()
+	ReconcileDuration.WithLabelValues(controller).Observe(duration.Seconds())
+	if errorClass != "" {
+		ReconcileErrors.WithLabelValues(controller, errorClass).Inc()
+	}
+	if requeueReason != "" {
+		RequeueTotal.WithLabelValues(controller, requeueReason).Inc()
+	}
+}
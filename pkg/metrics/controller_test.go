@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestAreMetricsExposed(t *testing.T) {
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	assert.False(t, AreMetricsExposed(cf))
+	cf.EnablePromMetrics = true
+	assert.True(t, AreMetricsExposed(cf))
+	cf.EnablePromMetrics = false
+	cf.EnforcementPoint = "vmc-enforcementpoint"
+	assert.True(t, AreMetricsExposed(cf))
+}
+
+func TestRegisterStore(t *testing.T) {
+	size := 3
+	RegisterStore("Rule", func() int { return size })
+	expected := `
+# HELP nsx_operator_store_size Number of the NSX resources cached in the stores of NSX Operator
+# TYPE nsx_operator_store_size gauge
+nsx_operator_store_size{resource_type="Rule"} 3
+`
+	assert.Nil(t, testutil.CollectAndCompare(StoreSize, strings.NewReader(expected)))
+
+	// the size is counted when the metrics are scraped
+	size = 5
+	assert.Nil(t, testutil.CollectAndCompare(StoreSize, strings.NewReader(strings.Replace(expected, "} 3", "} 5", 1))))
+}
+
+func TestObserveReconcile(t *testing.T) {
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{EnablePromMetrics: true}, NsxConfig: &config.NsxConfig{}}
+	ObserveReconcile(cf, "vpc", time.Second, ReconcileResultRequeue, "", "throttled")
+	assert.Equal(t, float64(1), testutil.ToFloat64(ReconcileTotal.WithLabelValues("vpc", ReconcileResultRequeue)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(RequeueTotal.WithLabelValues("vpc", "throttled")))
+	assert.Equal(t, 0, testutil.CollectAndCount(ReconcileErrors))
+	assert.Equal(t, 1, testutil.CollectAndCount(ReconcileDuration))
+}
+
+func TestObserveGCRun(t *testing.T) {
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{EnablePromMetrics: true}, NsxConfig: &config.NsxConfig{}}
+	ObserveGCRun(cf, "gc-test", 5, 2, 1, time.Second, false)
+	assert.Equal(t, float64(5), testutil.ToFloat64(GCScannedTotal.WithLabelValues("gc-test")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(GCSuccessTotal.WithLabelValues("gc-test")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(GCFailTotal.WithLabelValues("gc-test")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(GCRunTotal.WithLabelValues("gc-test", GCResultError)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(GCLastSuccessTimestamp.WithLabelValues("gc-test")))
+
+	ObserveGCRun(cf, "gc-test", 3, 0, 0, time.Second, true)
+	assert.Equal(t, float64(1), testutil.ToFloat64(GCRunTotal.WithLabelValues("gc-test", GCResultSuccess)))
+	assert.Greater(t, testutil.ToFloat64(GCLastSuccessTimestamp.WithLabelValues("gc-test")), float64(0))
+}
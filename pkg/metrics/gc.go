@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+const (
+	GCRunTotalKey             = "gc_run_total"
+	GCScannedTotalKey         = "gc_scanned_total"
+	GCRunDurationKey          = "gc_run_duration_seconds"
+	GCLastSuccessTimestampKey = "gc_last_success_timestamp_seconds"
+	GCAbortedTotalKey         = "gc_aborted_total"
+
+	// The results of the runs of the garbage collectors.
+	GCResultSuccess = "success"
+	GCResultError   = "error"
+)
+
+var (
+	GCRunTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      GCRunTotalKey,
+			Help:      "Total number of the runs of the garbage collectors, by result: success or error",
+		},
+		[]string{"res_type", "result"},
+	)
+	GCScannedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      GCScannedTotalKey,
+			Help:      "Total number of the NSX resources scanned by the garbage collectors",
+		},
+		[]string{"res_type"},
+	)
+	GCRunDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      GCRunDurationKey,
+			Help:      "Seconds the garbage collectors take to run",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		},
+		[]string{"res_type"},
+	)
+	GCLastSuccessTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      GCLastSuccessTimestampKey,
+			Help:      "Unix time the garbage collectors last ran without errors",
+		},
+		[]string{"res_type"},
+	)
+	GCAbortedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      GCAbortedTotalKey,
+			Help:      "Total number of the runs of the garbage collectors aborted because they would delete too many NSX resources",
+		},
+		[]string{"res_type"},
+	)
+)
+
+// ObserveGCRun records a run of the garbage collector of resType: the NSX resources it scanned, deleted and failed to
+// delete, its duration, and the time it ended if it succeeded.
+func ObserveGCRun(cf *config.NSXOperatorConfig, resType string, scanned, deleted, failed int, duration time.Duration, succeeded bool) {
+	if !AreMetricsExposed(cf) {
+		return
+	}
+	GCScannedTotal.WithLabelValues(resType).Add(float64(scanned))
+	GCSuccessTotal.WithLabelValues(resType).Add(float64(deleted))
+	GCFailTotal.WithLabelValues(resType).Add(float64(failed))
+	GCRunDuration.WithLabelValues(resType).Observe(duration.Seconds())
+	if succeeded {
+		GCRunTotal.WithLabelValues(resType, GCResultSuccess).Inc()
+		GCLastSuccessTimestamp.WithLabelValues(resType).SetToCurrentTime()
+	} else {
+		GCRunTotal.WithLabelValues(resType, GCResultError).Inc()
+	}
+}
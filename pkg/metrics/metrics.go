@@ -14,6 +14,7 @@ const (
 	MetricNamespace                 = "nsx"
 	MetricSubsystem                 = "operator"
 	HealthKey                       = "health_status"
+	MutationsPausedKey              = "mutations_paused"
 	ControllerSyncTotalKey          = "controller_sync_total"
 	ControllerUpdateTotalKey        = "controller_update_total"
 	ControllerUpdateSuccessTotalKey = "controller_update_success_total"
@@ -21,6 +22,32 @@ const (
 	ControllerDeleteTotalKey        = "controller_delete_total"
 	ControllerDeleteSuccessTotalKey = "controller_delete_success_total"
 	ControllerDeleteFailTotalKey    = "controller_delete_fail_total"
+	ExternalIPBlockTotalKey         = "external_ip_block_total_ips"
+	ExternalIPBlockUsedKey          = "external_ip_block_used_ips"
+	ExternalIPBlockUtilizationKey   = "external_ip_block_utilization"
+	SubnetTotalIPsKey               = "subnet_total_ips"
+	SubnetUsedIPsKey                = "subnet_used_ips"
+	SubnetAvailableIPsKey           = "subnet_available_ips"
+	LBVIPAllocatedKey               = "lb_vip_allocated"
+	IPExhaustionTotalKey            = "ip_exhaustion_total"
+	IPReclamationSuccessTotalKey    = "ip_reclamation_success_total"
+	IPReclamationFailTotalKey       = "ip_reclamation_fail_total"
+	IPAMDriftAllocationsKey         = "ipam_drift_allocations"
+	IPAMDriftRepairedTotalKey       = "ipam_drift_repaired_total"
+	DriftOrphanedNSXResourcesKey    = "drift_orphaned_nsx_resources"
+	DriftUnrealizedCRsKey           = "drift_unrealized_crs"
+	GCSuccessTotalKey               = "gc_success_total"
+	GCFailTotalKey                  = "gc_fail_total"
+	NSXEndpointUpKey                = "nsx_endpoint_up"
+	NSXEndpointInUseKey             = "nsx_endpoint_in_use"
+	NSXEndpointFailoverTotalKey     = "nsx_endpoint_failover_total"
+	NSXEndpointBreakerStateKey      = "nsx_endpoint_breaker_state"
+	NSXAPIRequestTotalKey           = "nsx_api_request_total"
+	NSXAPIRequestDurationKey        = "nsx_api_request_duration_seconds"
+	NSXAPIRateLimitWaitKey          = "nsx_api_rate_limit_wait_seconds"
+	NSXWriteQueueDepthKey           = "nsx_write_queue_depth"
+	NSXWriteCoalescedTotalKey       = "nsx_write_coalesced_total"
+	FeatureEnabledKey               = "feature_enabled"
 	ScrapeTimeout                   = 30
 )
 
@@ -35,6 +62,14 @@ var (
 			Help:      "Last health status for NSX-Operator. 1 for 'status' label with current status.",
 		},
 	)
+	NSXOperatorMutationsPaused = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      MutationsPausedKey,
+			Help:      "Whether the NSX mutations are paused by the pause switch. 1 for paused.",
+		},
+	)
 	ControllerSyncTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: MetricNamespace,
@@ -98,6 +133,239 @@ var (
 		},
 		[]string{"res_type"},
 	)
+	ExternalIPBlockTotal = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      ExternalIPBlockTotalKey,
+			Help:      "Total number of IPs in the external IP block",
+		},
+		[]string{"ip_block"},
+	)
+	ExternalIPBlockUsed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      ExternalIPBlockUsedKey,
+			Help:      "Number of IPs of the external IP block used by SNAT IPs, NAT rules and IP allocations",
+		},
+		[]string{"ip_block", "type"},
+	)
+	ExternalIPBlockUtilization = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      ExternalIPBlockUtilizationKey,
+			Help:      "Percentage of the IPs in use of the external IP block",
+		},
+		[]string{"ip_block"},
+	)
+	SubnetTotalIPs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      SubnetTotalIPsKey,
+			Help:      "Total number of IPs in the Subnet",
+		},
+		[]string{"namespace", "subnet"},
+	)
+	SubnetUsedIPs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      SubnetUsedIPsKey,
+			Help:      "Number of IPs allocated in the Subnet",
+		},
+		[]string{"namespace", "subnet"},
+	)
+	SubnetAvailableIPs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      SubnetAvailableIPsKey,
+			Help:      "Number of IPs free to allocate in the Subnet",
+		},
+		[]string{"namespace", "subnet"},
+	)
+	LBVIPAllocated = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      LBVIPAllocatedKey,
+			Help:      "Number of LoadBalancer VIPs allocated from the IP pool",
+		},
+		[]string{"ip_pool"},
+	)
+	IPExhaustionTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      IPExhaustionTotalKey,
+			Help:      "Total number of the IP allocation requests failed as the pool runs out of IPs",
+		},
+		[]string{"pool"},
+	)
+	IPReclamationSuccessTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      IPReclamationSuccessTotalKey,
+			Help:      "Total number of the NSX IP allocations released as their CRs no longer exist",
+		},
+		[]string{"res_type"},
+	)
+	IPReclamationFailTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      IPReclamationFailTotalKey,
+			Help:      "Total number of the NSX IP allocations failed to be released as their CRs no longer exist",
+		},
+		[]string{"res_type"},
+	)
+	IPAMDriftAllocations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      IPAMDriftAllocationsKey,
+			Help:      "Number of the IP allocations drifted between NSX Operator and NSX found by the last audit",
+		},
+		[]string{"drift_type"},
+	)
+	IPAMDriftRepairedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      IPAMDriftRepairedTotalKey,
+			Help:      "Total number of the drifted IP allocations repaired by the audit",
+		},
+		[]string{"drift_type"},
+	)
+	DriftOrphanedNSXResources = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      DriftOrphanedNSXResourcesKey,
+			Help:      "Number of the CRs whose NSX resources tagged for the cluster remain in NSX though the CRs no longer exist",
+		},
+		[]string{"res_type"},
+	)
+	DriftUnrealizedCRs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      DriftUnrealizedCRsKey,
+			Help:      "Number of the CRs without NSX resources realized for them",
+		},
+		[]string{"res_type"},
+	)
+	GCSuccessTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      GCSuccessTotalKey,
+			Help:      "Total number of the NSX resources deleted by the garbage collectors as their CRs no longer exist",
+		},
+		[]string{"res_type"},
+	)
+	GCFailTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      GCFailTotalKey,
+			Help:      "Total number of the NSX resources failed to be deleted by the garbage collectors",
+		},
+		[]string{"res_type"},
+	)
+	NSXEndpointUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      NSXEndpointUpKey,
+			Help:      "Health of the NSX manager endpoints checked periodically. 1 for UP.",
+		},
+		[]string{"endpoint"},
+	)
+	NSXEndpointInUse = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      NSXEndpointInUseKey,
+			Help:      "The NSX manager endpoint serving the latest request. 1 for in use.",
+		},
+		[]string{"endpoint"},
+	)
+	NSXEndpointBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      NSXEndpointBreakerStateKey,
+			Help:      "State of the circuit breaker of the NSX manager endpoints. 0 for closed, 1 for half-open, 2 for open.",
+		},
+		[]string{"endpoint"},
+	)
+	NSXEndpointFailoverTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      NSXEndpointFailoverTotalKey,
+			Help:      "Total number of the NSX requests failed over to another NSX manager endpoint",
+		},
+	)
+	NSXAPIRequestTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      NSXAPIRequestTotalKey,
+			Help:      "Total number of the requests sent to NSX, the code is \"error\" if no response is received",
+		},
+		[]string{"method", "resource_type", "code", "endpoint"},
+	)
+	NSXAPIRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      NSXAPIRequestDurationKey,
+			Help:      "Seconds NSX takes to respond the requests, excluding the wait for the API rate limits",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+		},
+		[]string{"method", "resource_type", "code", "endpoint"},
+	)
+	NSXAPIRateLimitWait = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      NSXAPIRateLimitWaitKey,
+			Help:      "Seconds the requests wait for the API rate limits of NSX Operator before they are sent",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+		},
+		[]string{"method", "endpoint"},
+	)
+	NSXWriteQueueDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      NSXWriteQueueDepthKey,
+			Help:      "Number of the NSX intent paths with a write waiting in the write queue",
+		},
+	)
+	NSXWriteCoalescedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      NSXWriteCoalescedTotalKey,
+			Help:      "Total number of the NSX writes coalesced into a later write to the same intent path",
+		},
+	)
+	FeatureEnabled = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: MetricNamespace,
+			Subsystem: MetricSubsystem,
+			Name:      FeatureEnabledKey,
+			Help:      "State of the feature gates. 1 for enabled.",
+		},
+		[]string{"name", "stage"},
+	)
 )
 
 var registerMetrics sync.Once
@@ -114,6 +382,7 @@ func InitializePrometheusMetrics() {
 	log.Info("initializing prometheus metrics")
 	Register(
 		NSXOperatorHealthStats,
+		NSXOperatorMutationsPaused,
 		ControllerSyncTotal,
 		ControllerUpdateTotal,
 		ControllerUpdateSuccessTotal,
@@ -121,14 +390,63 @@ func InitializePrometheusMetrics() {
 		ControllerDeleteTotal,
 		ControllerDeleteSuccessTotal,
 		ControllerDeleteFailTotal,
+		ExternalIPBlockTotal,
+		ExternalIPBlockUsed,
+		ExternalIPBlockUtilization,
+		SubnetTotalIPs,
+		SubnetUsedIPs,
+		SubnetAvailableIPs,
+		LBVIPAllocated,
+		IPExhaustionTotal,
+		IPReclamationSuccessTotal,
+		IPReclamationFailTotal,
+		IPAMDriftAllocations,
+		IPAMDriftRepairedTotal,
+		DriftOrphanedNSXResources,
+		DriftUnrealizedCRs,
+		GCSuccessTotal,
+		GCFailTotal,
+		GCRunTotal,
+		GCScannedTotal,
+		GCRunDuration,
+		GCLastSuccessTimestamp,
+		GCAbortedTotal,
+		NSXEndpointUp,
+		NSXEndpointInUse,
+		NSXEndpointBreakerState,
+		NSXEndpointFailoverTotal,
+		NSXAPIRequestTotal,
+		NSXAPIRequestDuration,
+		NSXAPIRateLimitWait,
+		NSXWriteQueueDepth,
+		NSXWriteCoalescedTotal,
+		FeatureEnabled,
+		ReconcileTotal,
+		ReconcileDuration,
+		ReconcileErrors,
+		RequeueTotal,
+		StoreSize,
 	)
 }
 
+// UpdateFeatureGateMetrics records the state of each feature gate.
+func UpdateFeatureGateMetrics(gates *config.FeatureGates) {
+	for _, feature := range gates.KnownFeatures() {
+		enabled := 0
+		if gates.Enabled(feature) {
+			enabled = 1
+		}
+		FeatureEnabled.WithLabelValues(feature, gates.Spec(feature).PreRelease).Set(float64(enabled))
+	}
+}
+
+// AreMetricsExposed returns whether the metrics are served on the controller-runtime metrics endpoint, they are if
+// k8s.enable_prometheus_metrics is set, and always on VMC.
 func AreMetricsExposed(cf *config.NSXOperatorConfig) bool {
 	if cf.EnforcementPoint == "vmc-enforcementpoint" {
 		return true
 	}
-	return false
+	return cf.K8sConfig != nil && cf.EnablePromMetrics
 }
 
 func CounterInc(cf *config.NSXOperatorConfig, counter *prometheus.CounterVec, res_type string) {
@@ -136,3 +454,16 @@ func CounterInc(cf *config.NSXOperatorConfig, counter *prometheus.CounterVec, re
 		counter.WithLabelValues(res_type).Inc()
 	}
 }
+
+func GaugeSet(cf *config.NSXOperatorConfig, gauge *prometheus.GaugeVec, value float64, labels ...string) {
+	if AreMetricsExposed(cf) {
+		gauge.WithLabelValues(labels...).Set(value)
+	}
+}
+
+// GaugeDelete removes the gauge of the labels, e.g. when the resource it measures is deleted.
+func GaugeDelete(cf *config.NSXOperatorConfig, gauge *prometheus.GaugeVec, labels ...string) {
+	if AreMetricsExposed(cf) {
+		gauge.DeleteLabelValues(labels...)
+	}
+}
@@ -0,0 +1,77 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsxsimulator
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	// ErrorCodePageMax is the NSX error code of a search whose page size is larger than the release serves.
+	ErrorCodePageMax int64 = 60576
+	// ErrorCodeNotFound is the NSX error code of a missing object.
+	ErrorCodeNotFound int64 = 500012
+	// ErrorCodeInvalidRequest is the NSX error code of a request to an API the release doesn't have.
+	ErrorCodeInvalidRequest int64 = 600
+)
+
+// Profile is the API behavior of an NSX release the Simulator reproduces.
+type Profile struct {
+	Name string
+	// Version is the node version of the release, which the NSX capabilities are read from.
+	Version string
+	// MaxPageSize is the largest search page size served, a larger one fails with PageMaxStatus.
+	MaxPageSize int64
+	// PageMaxStatus is the HTTP status of a search whose page size is larger than MaxPageSize.
+	PageMaxStatus int
+	// MissingEndpointStatus and MissingEndpointErrorCode are returned for the APIs in MissingEndpoints.
+	MissingEndpointStatus    int
+	MissingEndpointErrorCode int64
+	// MissingEndpoints are the path prefixes of the APIs the release doesn't have.
+	MissingEndpoints []string
+}
+
+var (
+	// NSX320 has no VPC APIs.
+	NSX320 = Profile{
+		Name:                     "nsx-3.2",
+		Version:                  "3.2.0.0.0",
+		MaxPageSize:              800,
+		PageMaxStatus:            http.StatusServiceUnavailable,
+		MissingEndpointStatus:    http.StatusNotFound,
+		MissingEndpointErrorCode: ErrorCodeNotFound,
+		MissingEndpoints:         []string{"/policy/api/v1/orgs/"},
+	}
+	// NSX410 rejects the search pages too large as invalid requests.
+	NSX410 = Profile{
+		Name:          "nsx-4.1",
+		Version:       "4.1.1.0.0",
+		MaxPageSize:   900,
+		PageMaxStatus: http.StatusBadRequest,
+	}
+	// VMC serves smaller search pages and rejects the management plane trust APIs.
+	VMC = Profile{
+		Name:                     "vmc",
+		Version:                  "4.1.1.0.0",
+		MaxPageSize:              500,
+		PageMaxStatus:            http.StatusServiceUnavailable,
+		MissingEndpointStatus:    http.StatusForbidden,
+		MissingEndpointErrorCode: ErrorCodeInvalidRequest,
+		MissingEndpoints:         []string{"/api/v1/trust-management/"},
+	}
+
+	// Profiles are the profiles the service tests run against.
+	Profiles = []Profile{NSX320, NSX410, VMC}
+)
+
+// Supports tells if the release has the API at path.
+func (p Profile) Supports(path string) bool {
+	for _, prefix := range p.MissingEndpoints {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	return true
+}
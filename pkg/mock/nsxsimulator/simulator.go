@@ -0,0 +1,247 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+// Package nsxsimulator serves an in-memory NSX API, which behaves like the NSX release of its Profile,
+// so that the services can be tested against the quirks of the NSX releases.
+package nsxsimulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+)
+
+const (
+	policyAPIPrefix = "/policy/api/v1"
+	searchPath      = policyAPIPrefix + "/search/query"
+
+	// the APIs the NSX cluster of the client checks the managers with
+	sessionPath  = "/api/session/create"
+	healthPath   = "/api/v1/reverse-proxy/node/health"
+	versionPath  = "/api/v1/node/version"
+	licensesPath = "/api/v1/licenses"
+)
+
+var (
+	resourceTypeQuery = regexp.MustCompile(`resource_type:(\S+)`)
+	// projectSearchPath is the search API of an NSX Project, it only finds the objects in the Project.
+	projectSearchPath = regexp.MustCompile(`^` + policyAPIPrefix + `(/orgs/[^/]+/projects/[^/]+)/search/query$`)
+)
+
+// Simulator is an NSX API server keeping the objects in memory, keyed by their policy paths.
+type Simulator struct {
+	*httptest.Server
+	Profile Profile
+
+	sync.Mutex
+	objects map[string]map[string]interface{}
+	// pageSizes are the page sizes of the search pages served.
+	pageSizes []int64
+}
+
+// NewSimulator starts a Simulator of the profile, it should be closed once the test is done.
+func NewSimulator(profile Profile) *Simulator {
+	s := &Simulator{Profile: profile, objects: map[string]map[string]interface{}{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// NewNSXClient creates an NSX client talking to the Simulator through an NSX cluster, so that the requests go through
+// the transport with its retries and circuit breakers like they go to NSX. cf is copied with the Simulator as the
+// only NSX manager, the API rate is fixed since the AIMD rate limiter would start the tests at 1 request per second.
+func (s *Simulator) NewNSXClient(cf *config.NSXOperatorConfig) *nsx.Client {
+	simulated := *cf
+	nsxConfig := config.NsxConfig{}
+	if cf.NsxConfig != nil {
+		nsxConfig = *cf.NsxConfig
+	}
+	nsxConfig.NsxApiManagers = []string{s.URL}
+	nsxConfig.CaFile, nsxConfig.Thumbprint = nil, nil
+	nsxConfig.APIRateMode = config.APIRateModeFixRate
+	simulated.NsxConfig = &nsxConfig
+	if simulated.VCConfig == nil {
+		simulated.VCConfig = &config.VCConfig{}
+	}
+	return nsx.GetClient(&simulated)
+}
+
+// SearchPageSizes returns the page sizes of the search pages served, the pages rejected as too large are not counted.
+func (s *Simulator) SearchPageSizes() []int64 {
+	s.Lock()
+	defer s.Unlock()
+	return append([]int64(nil), s.pageSizes...)
+}
+
+// AddObject adds the object at the policy path, the object is returned by the searches of its resource_type.
+func (s *Simulator) AddObject(path string, object map[string]interface{}) {
+	s.Lock()
+	defer s.Unlock()
+	object["path"] = path
+	object["id"] = path[strings.LastIndex(path, "/")+1:]
+	s.objects[path] = object
+}
+
+// Object returns the object at the policy path, or nil if there is none.
+func (s *Simulator) Object(path string) map[string]interface{} {
+	s.Lock()
+	defer s.Unlock()
+	return s.objects[path]
+}
+
+func (s *Simulator) serve(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case sessionPath:
+		w.Header().Set("X-Xsrf-Token", "simulated")
+		w.WriteHeader(http.StatusOK)
+		return
+	case healthPath:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"healthy": true})
+		return
+	case versionPath:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"node_version": s.Profile.Version})
+		return
+	case licensesPath:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": []map[string]interface{}{{"license_key": "simulated", "is_expired": false}}})
+		return
+	}
+	if !s.Profile.Supports(r.URL.Path) {
+		writeError(w, s.Profile.MissingEndpointStatus, s.Profile.MissingEndpointErrorCode, fmt.Sprintf("%s is not supported by %s", r.URL.Path, s.Profile.Name))
+		return
+	}
+	if r.URL.Path == searchPath {
+		s.search(w, r, "")
+		return
+	}
+	if m := projectSearchPath.FindStringSubmatch(r.URL.Path); m != nil {
+		s.search(w, r, m[1]+"/")
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, policyAPIPrefix) {
+		writeError(w, http.StatusNotFound, ErrorCodeNotFound, fmt.Sprintf("%s is not simulated", r.URL.Path))
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, policyAPIPrefix)
+	switch r.Method {
+	case http.MethodGet:
+		s.get(w, path)
+	case http.MethodPatch, http.MethodPut:
+		object := map[string]interface{}{}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &object); err != nil {
+			writeError(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
+			return
+		}
+		s.AddObject(path, object)
+		writeJSON(w, http.StatusOK, object)
+	case http.MethodDelete:
+		s.Lock()
+		delete(s.objects, path)
+		s.Unlock()
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrorCodeInvalidRequest, r.Method+" is not simulated")
+	}
+}
+
+// get returns the object at path, or lists the objects right under path if it is a collection.
+func (s *Simulator) get(w http.ResponseWriter, path string) {
+	if !isCollection(path) {
+		object := s.Object(path)
+		if object == nil {
+			writeError(w, http.StatusNotFound, ErrorCodeNotFound, fmt.Sprintf("%s is not found", path))
+			return
+		}
+		writeJSON(w, http.StatusOK, object)
+		return
+	}
+	s.Lock()
+	results := []map[string]interface{}{}
+	for objectPath, object := range s.objects {
+		if strings.HasPrefix(objectPath, path+"/") && !strings.Contains(strings.TrimPrefix(objectPath, path+"/"), "/") {
+			results = append(results, object)
+		}
+	}
+	s.Unlock()
+	sortByPath(results)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"results": results, "result_count": len(results)})
+}
+
+// isCollection tells if the path is a collection like /orgs/default/projects or /infra/domains,
+// the segments of the paths under /orgs alternate between collection and id, /infra itself is an object.
+func isCollection(path string) bool {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	return (len(segments)%2 == 1) != (segments[0] == "infra")
+}
+
+// search filters the objects under the scope by the resource_type in the query, the cursor is the offset of the next page.
+func (s *Simulator) search(w http.ResponseWriter, r *http.Request, scope string) {
+	query := r.URL.Query()
+	pageSize := s.Profile.MaxPageSize
+	if v := query.Get("page_size"); v != "" {
+		pageSize, _ = strconv.ParseInt(v, 10, 64)
+	}
+	if pageSize > s.Profile.MaxPageSize {
+		writeError(w, s.Profile.PageMaxStatus, ErrorCodePageMax, fmt.Sprintf("page size %d exceeds the maximum %d", pageSize, s.Profile.MaxPageSize))
+		return
+	}
+	resourceType := ""
+	if m := resourceTypeQuery.FindStringSubmatch(query.Get("query")); m != nil {
+		resourceType = m[1]
+	}
+
+	s.Lock()
+	s.pageSizes = append(s.pageSizes, pageSize)
+	var matched []map[string]interface{}
+	for _, object := range s.objects {
+		if object["resource_type"] == resourceType && strings.HasPrefix(object["path"].(string), scope) {
+			matched = append(matched, object)
+		}
+	}
+	s.Unlock()
+	sortByPath(matched)
+
+	offset, _ := strconv.Atoi(query.Get("cursor"))
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	end := offset + int(pageSize)
+	if end > len(matched) {
+		end = len(matched)
+	}
+	response := map[string]interface{}{"results": matched[offset:end], "result_count": len(matched)}
+	if end < len(matched) {
+		response["cursor"] = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func sortByPath(objects []map[string]interface{}) {
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i]["path"].(string) < objects[j]["path"].(string)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, errorCode int64, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"httpStatus":    http.StatusText(status),
+		"error_code":    errorCode,
+		"module_name":   "simulator",
+		"error_message": message,
+	})
+}
@@ -0,0 +1,80 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsxsimulator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func TestIsCollection(t *testing.T) {
+	assert.True(t, isCollection("/orgs/default/projects"))
+	assert.False(t, isCollection("/orgs/default/projects/p1"))
+	assert.True(t, isCollection("/infra/domains"))
+	assert.False(t, isCollection("/infra/domains/default"))
+	assert.False(t, isCollection("/infra"))
+}
+
+func TestSimulator(t *testing.T) {
+	for _, profile := range Profiles {
+		t.Run(profile.Name, func(t *testing.T) {
+			s := NewSimulator(profile)
+			defer s.Close()
+			nsxClient := s.NewNSXClient(&config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}})
+			for i := 0; i < 3; i++ {
+				s.AddObject(fmt.Sprintf("/infra/domains/default/groups/group%d", i), map[string]interface{}{"resource_type": "Group"})
+			}
+
+			// search pages with the cursor
+			pageSize := int64(2)
+			response, err := nsxClient.QueryClient.List("resource_type:Group", nil, nil, &pageSize, nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, 2, len(response.Results))
+			assert.Equal(t, int64(3), *response.ResultCount)
+			response, err = nsxClient.QueryClient.List("resource_type:Group", response.Cursor, nil, &pageSize, nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(response.Results))
+			assert.Nil(t, response.Cursor)
+
+			pageSize = profile.MaxPageSize + 1
+			_, err = nsxClient.QueryClient.List("resource_type:Group", nil, nil, &pageSize, nil, nil)
+			assert.IsType(t, util.PageMaxError{}, nsx.TransSearchError(err))
+
+			// objects are patched, read and deleted at their paths
+			err = nsxClient.VPCClient.Patch("default", "p1", "vpc1", model.Vpc{})
+			if !profile.Supports("/policy/api/v1/orgs/") {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			got, err := nsxClient.VPCClient.Get("default", "p1", "vpc1")
+			assert.Nil(t, err)
+			assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc1", *got.Path)
+			subnets, err := nsxClient.VPCSubnetClient.List("default", "p1", "vpc1", nil, nil, nil, nil, nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, 0, len(subnets.Results))
+
+			// the search API of a Project only finds the objects in the Project
+			s.AddObject("/orgs/default/projects/p2/infra/domains/default/groups/group0", map[string]interface{}{"resource_type": "Group"})
+			response, err = nsxClient.VPCQueryClient.List("default", "p2", "resource_type:Group", nil, nil, nil, nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1), *response.ResultCount)
+			response, err = nsxClient.VPCQueryClient.List("default", "p1", "resource_type:Group", nil, nil, nil, nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), *response.ResultCount)
+			assert.Nil(t, nsxClient.VPCClient.Delete("default", "p1", "vpc1"))
+			assert.Nil(t, s.Object("/orgs/default/projects/p1/vpcs/vpc1"))
+			_, err = nsxClient.VPCClient.Get("default", "p1", "vpc1")
+			assert.IsType(t, vapierrors.NotFound{}, err)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package nsxsimulator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestTmp(t *testing.T) {
+	s := NewSimulator(NSX320)
+	defer s.Close()
+	c := s.NewNSXClient(&config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}})
+	ps := int64(2000)
+	for i := 0; i < 3; i++ {
+		start := time.Now()
+		_, err := c.QueryClient.List("resource_type:Group", nil, nil, &ps, nil, nil)
+		t.Log("list", time.Since(start), err)
+	}
+}
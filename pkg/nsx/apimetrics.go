@@ -0,0 +1,58 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+)
+
+// singletonPaths are the path segments not followed by an ID.
+var singletonPaths = map[string]bool{
+	"infra":            true,
+	"search":           true,
+	"trust-management": true,
+	"reverse-proxy":    true,
+	"node":             true,
+	"aaa":              true,
+}
+
+// resourceTypeOf returns the type of the resource the request path is about, which is the last collection in the
+// path, e.g. "rules" for /policy/api/v1/infra/domains/d1/security-policies/sp1/rules/r1. The IDs in the path are
+// never returned, so that the cardinality of the metrics stays bounded.
+func resourceTypeOf(path string) string {
+	if i := strings.Index(path, "/v1/"); i >= 0 {
+		path = path[i+len("/v1/"):]
+	}
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s != "" && !singletonPaths[s] {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) == 0 {
+		return "infra"
+	}
+	if len(segments)%2 == 0 {
+		return segments[len(segments)-2]
+	}
+	return segments[len(segments)-1]
+}
+
+// observeAPIRequest records the request sent to the endpoint with the response code, or "error" if no response is
+// received, and splits its latency into the wait for the API rate limits and the time NSX takes to respond.
+func observeAPIRequest(r *http.Request, endpoint string, resp *http.Response, waitTime, transTime time.Duration) {
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	resourceType := resourceTypeOf(r.URL.Path)
+	metrics.NSXAPIRequestTotal.WithLabelValues(r.Method, resourceType, code, endpoint).Inc()
+	metrics.NSXAPIRequestDuration.WithLabelValues(r.Method, resourceType, code, endpoint).Observe(transTime.Seconds())
+	metrics.NSXAPIRateLimitWait.WithLabelValues(r.Method, endpoint).Observe(waitTime.Seconds())
+}
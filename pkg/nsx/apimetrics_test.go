@@ -0,0 +1,45 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+)
+
+func Test_resourceTypeOf(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/policy/api/v1/infra", "infra"},
+		{"/policy/api/v1/infra/domains/k8scl-one:test/security-policies/sp1/rules/r1", "rules"},
+		{"/policy/api/v1/infra/domains/default/groups", "groups"},
+		{"/policy/api/v1/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1/ports/port1", "ports"},
+		{"/policy/api/v1/orgs/default/projects/p1/infra/domains/default/groups/g1", "groups"},
+		{"/policy/api/v1/search/query", "query"},
+		{"/api/v1/trust-management/principal-identities/with-certificate", "principal-identities"},
+		{"/api/v1/trust-management/certificates/cert1", "certificates"},
+		{"/api/v1/reverse-proxy/node/health", "health"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, resourceTypeOf(tt.path), tt.path)
+	}
+}
+
+func Test_observeAPIRequest(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodPatch, "https://10.0.0.1/policy/api/v1/orgs/default/projects/p1/vpcs/vpc1", nil)
+	observeAPIRequest(r, "10.0.0.1", &http.Response{StatusCode: http.StatusOK}, time.Millisecond, time.Second)
+	observeAPIRequest(r, "10.0.0.1", nil, time.Millisecond, time.Second)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.NSXAPIRequestTotal.WithLabelValues(http.MethodPatch, "vpcs", "200", "10.0.0.1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.NSXAPIRequestTotal.WithLabelValues(http.MethodPatch, "vpcs", "error", "10.0.0.1")))
+	assert.GreaterOrEqual(t, testutil.CollectAndCount(metrics.NSXAPIRequestDuration), 2)
+	assert.GreaterOrEqual(t, testutil.CollectAndCount(metrics.NSXAPIRateLimitWait), 1)
+}
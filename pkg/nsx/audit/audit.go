@@ -0,0 +1,272 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+// Path is the path of the endpoint serving the audit trail, e.g. GET /debug/audit?path=/infra/domains.
+const Path = "/debug/audit"
+
+// The outcomes of the mutations.
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	// OutcomeRejected is the outcome of the mutations rejected by NSX Operator itself, e.g. while they are paused.
+	OutcomeRejected = "rejected"
+)
+
+// The reasons of the Events recorded on the initiating CRs.
+const (
+	ReasonNSXMutated        = "NSXMutated"
+	ReasonNSXMutationFailed = "NSXMutationFailed"
+)
+
+// trailSize is the number of the latest mutations kept in the audit trail.
+const trailSize = 1000
+
+// kinds maps the kinds of the CRs as named in the tags of the NSX resources and in the metrics of the controllers,
+// without the underscores, to their API kinds.
+var kinds = map[string]struct{ apiVersion, kind string }{
+	"securitypolicy":      {"nsx.vmware.com/v1alpha1", "SecurityPolicy"},
+	"vpc":                 {"nsx.vmware.com/v1alpha1", "VPC"},
+	"subnet":              {"nsx.vmware.com/v1alpha1", "Subnet"},
+	"subnetset":           {"nsx.vmware.com/v1alpha1", "SubnetSet"},
+	"subnetport":          {"nsx.vmware.com/v1alpha1", "SubnetPort"},
+	"ippool":              {"nsx.vmware.com/v1alpha1", "IPPool"},
+	"ipaddressallocation": {"nsx.vmware.com/v1alpha1", "IPAddressAllocation"},
+	"staticroute":         {"nsx.vmware.com/v1alpha1", "StaticRoute"},
+	"routeadvertisement":  {"nsx.vmware.com/v1alpha1", "RouteAdvertisement"},
+	"prefixlist":          {"nsx.vmware.com/v1alpha1", "PrefixList"},
+	"routemap":            {"nsx.vmware.com/v1alpha1", "RouteMap"},
+	"nsxserviceaccount":   {"nsx.vmware.com/v1alpha1", "NSXServiceAccount"},
+	"service":             {"v1", "Service"},
+	"lbvip":               {"v1", "Service"},
+}
+
+// Initiator is the CR whose reconcile sent a mutation.
+type Initiator struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// NewInitiator returns the initiator of the kind, as named in the tags or by the controllers, e.g. subnet_port or
+// securitypolicy. The API kind is used if the kind is known.
+func NewInitiator(kind, namespace, name, uid string) *Initiator {
+	if known, ok := kinds[strings.ReplaceAll(strings.ToLower(kind), "_", "")]; ok {
+		kind = known.kind
+	}
+	return &Initiator{Kind: kind, Namespace: namespace, Name: name, UID: uid}
+}
+
+func (i *Initiator) String() string {
+	if i.Namespace == "" {
+		return i.Kind + " " + i.Name
+	}
+	return i.Kind + " " + i.Namespace + "/" + i.Name
+}
+
+// reference returns the reference of the initiator the Events are recorded on, or nil if its kind is unknown.
+func (i *Initiator) reference() *corev1.ObjectReference {
+	for _, known := range kinds {
+		if known.kind == i.Kind {
+			return &corev1.ObjectReference{
+				APIVersion: known.apiVersion,
+				Kind:       known.kind,
+				Namespace:  i.Namespace,
+				Name:       i.Name,
+				UID:        types.UID(i.UID),
+			}
+		}
+	}
+	return nil
+}
+
+// Record is a mutation sent to NSX.
+type Record struct {
+	Time       time.Time  `json:"time"`
+	Method     string     `json:"method"`
+	Path       string     `json:"path"`
+	Summary    string     `json:"summary,omitempty"`
+	Initiator  *Initiator `json:"initiator,omitempty"`
+	Endpoint   string     `json:"endpoint,omitempty"`
+	StatusCode int        `json:"statusCode,omitempty"`
+	Outcome    string     `json:"outcome"`
+	Error      string     `json:"error,omitempty"`
+	RequestID  string     `json:"nsxRequestID,omitempty"`
+}
+
+// trail is a ring buffer of the latest mutations.
+var trail = struct {
+	records  []Record
+	next     int
+	recorder record.EventRecorder
+	sync.RWMutex
+}{}
+
+// SetEventRecorder records the mutations as Events on their initiating CRs too, nil stops recording them.
+func SetEventRecorder(recorder record.EventRecorder) {
+	trail.Lock()
+	defer trail.Unlock()
+	trail.recorder = recorder
+}
+
+// Add adds the mutation to the audit trail, the earliest mutation is dropped once the trail is full.
+func Add(r Record) {
+	trail.Lock()
+	if len(trail.records) < trailSize {
+		trail.records = append(trail.records, r)
+	} else {
+		trail.records[trail.next] = r
+	}
+	trail.next = (trail.next + 1) % trailSize
+	recorder := trail.recorder
+	trail.Unlock()
+
+	if recorder == nil || r.Initiator == nil {
+		return
+	}
+	if ref := r.Initiator.reference(); ref != nil {
+		message := fmt.Sprintf("%s %s %s", r.Method, r.Path, r.Outcome)
+		if r.Summary != "" {
+			message += ": " + r.Summary
+		}
+		if r.Outcome == OutcomeSucceeded {
+			recorder.Event(ref, corev1.EventTypeNormal, ReasonNSXMutated, message)
+		} else {
+			recorder.Event(ref, corev1.EventTypeWarning, ReasonNSXMutationFailed, message+", "+r.Error)
+		}
+	}
+}
+
+// Records returns the mutations of the NSX paths starting with pathPrefix in the audit trail, the earliest first.
+func Records(pathPrefix string) []Record {
+	trail.RLock()
+	defer trail.RUnlock()
+	records := make([]Record, 0, len(trail.records))
+	start := 0
+	if len(trail.records) == trailSize {
+		start = trail.next
+	}
+	for i := 0; i < len(trail.records); i++ {
+		r := trail.records[(start+i)%len(trail.records)]
+		if strings.HasPrefix(r.Path, pathPrefix) {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// Handler serves the audit trail as JSON, filtered by the path query parameter.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(Records(r.URL.Query().Get("path")))
+}
+
+// resource is the part of an NSX resource, or of the H-API tree of resources, summarized in the audit trail.
+type resource struct {
+	ResourceType    string `json:"resource_type"`
+	ID              string `json:"id"`
+	MarkedForDelete bool   `json:"marked_for_delete"`
+	Tags            []struct {
+		Scope string `json:"scope"`
+		Tag   string `json:"tag"`
+	} `json:"tags"`
+	Children []map[string]json.RawMessage `json:"children"`
+}
+
+// Summarize returns the summary of the mutation sent with body, e.g. "upsert 2 Rule, delete 1 Group", and the
+// initiating CR found in the tags of the resources, nil if none is tagged with one.
+func Summarize(method string, body []byte) (string, *Initiator) {
+	if len(body) == 0 {
+		return strings.ToLower(method), nil
+	}
+	root := resource{}
+	if err := json.Unmarshal(body, &root); err != nil {
+		return fmt.Sprintf("%s %d bytes", strings.ToLower(method), len(body)), nil
+	}
+	counts := map[string]int{}
+	var initiator *Initiator
+	var walk func(r *resource, deleted bool, depth int)
+	walk = func(r *resource, deleted bool, depth int) {
+		// the root of an H-API tree, e.g. Infra, only contains the resources mutated
+		if depth > 0 || len(r.Children) == 0 {
+			op := "upsert"
+			if deleted || r.MarkedForDelete || method == http.MethodDelete {
+				op = "delete"
+			}
+			counts[op+" "+r.ResourceType]++
+		}
+		if initiator == nil {
+			initiator = initiatorOf(r)
+		}
+		// the children are wrapped, e.g. {"resource_type": "ChildRule", "marked_for_delete": true, "Rule": {...}}
+		for _, child := range r.Children {
+			var wrapperDeleted bool
+			if raw, ok := child["marked_for_delete"]; ok {
+				json.Unmarshal(raw, &wrapperDeleted)
+			}
+			for key, raw := range child {
+				if key == "resource_type" || key == "marked_for_delete" || key == "id" {
+					continue
+				}
+				c := resource{}
+				if json.Unmarshal(raw, &c) == nil && c.ResourceType != "" {
+					walk(&c, wrapperDeleted, depth+1)
+				}
+			}
+		}
+	}
+	walk(&root, false, 0)
+	summary := make([]string, 0, len(counts))
+	for op, count := range counts {
+		parts := strings.SplitN(op, " ", 2)
+		summary = append(summary, fmt.Sprintf("%s %d %s", parts[0], count, parts[1]))
+	}
+	sort.Strings(summary)
+	return strings.Join(summary, ", "), initiator
+}
+
+// initiatorOf reads the initiating CR from the tags of the resource, e.g. nsx-op/subnetport_cr_name and
+// nsx-op/subnetport_cr_uid.
+func initiatorOf(r *resource) *Initiator {
+	var kind, namespace, name, uid string
+	for _, tag := range r.Tags {
+		scope := strings.TrimPrefix(tag.Scope, "nsx-op/")
+		if scope == tag.Scope {
+			continue
+		}
+		switch {
+		case scope == "namespace":
+			namespace = tag.Tag
+		case strings.HasSuffix(scope, "_cr_uid") || scope == "service_uid" || scope == "nsx_service_account_uid":
+			uid = tag.Tag
+		case strings.HasSuffix(scope, "_cr_name") || scope == "service_name" || scope == "nsx_service_account_name":
+			kind, name = strings.TrimSuffix(strings.TrimSuffix(scope, "_name"), "_cr"), tag.Tag
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	return NewInitiator(kind, namespace, name, uid)
+}
@@ -0,0 +1,79 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+)
+
+func resetTrail() {
+	trail.Lock()
+	defer trail.Unlock()
+	trail.records, trail.next, trail.recorder = nil, 0, nil
+}
+
+func TestSummarize(t *testing.T) {
+	hapi := `{"resource_type": "Infra", "children": [{"resource_type": "ChildDomain", "Domain": {"resource_type": "Domain", "id": "default",
+		"children": [
+			{"resource_type": "ChildSecurityPolicy", "SecurityPolicy": {"resource_type": "SecurityPolicy", "id": "sp1",
+				"tags": [{"scope": "nsx-op/cluster", "tag": "cl1"}, {"scope": "nsx-op/namespace", "tag": "ns1"},
+					{"scope": "nsx-op/security_policy_cr_name", "tag": "sp1"}, {"scope": "nsx-op/security_policy_cr_uid", "tag": "uid1"}],
+				"children": [
+					{"resource_type": "ChildRule", "Rule": {"resource_type": "Rule", "id": "r1"}},
+					{"resource_type": "ChildRule", "Rule": {"resource_type": "Rule", "id": "r2"}}
+				]}},
+			{"resource_type": "ChildGroup", "marked_for_delete": true, "Group": {"resource_type": "Group", "id": "g1"}}
+		]}}]}`
+	summary, initiator := Summarize(http.MethodPatch, []byte(hapi))
+	assert.Equal(t, "delete 1 Group, upsert 1 Domain, upsert 1 SecurityPolicy, upsert 2 Rule", summary)
+	assert.Equal(t, &Initiator{Kind: "SecurityPolicy", Namespace: "ns1", Name: "sp1", UID: "uid1"}, initiator)
+
+	summary, initiator = Summarize(http.MethodPatch, []byte(`{"resource_type": "VpcSubnetPort", "tags": [
+		{"scope": "nsx-op/subnetport_cr_name", "tag": "port1"}, {"scope": "nsx-op/namespace", "tag": "ns1"}]}`))
+	assert.Equal(t, "upsert 1 VpcSubnetPort", summary)
+	assert.Equal(t, "SubnetPort ns1/port1", initiator.String())
+
+	summary, initiator = Summarize(http.MethodDelete, nil)
+	assert.Equal(t, "delete", summary)
+	assert.Nil(t, initiator)
+
+	summary, _ = Summarize(http.MethodPost, []byte("j_username=admin"))
+	assert.Equal(t, "post 16 bytes", summary)
+}
+
+func TestTrail(t *testing.T) {
+	defer resetTrail()
+	recorder := record.NewFakeRecorder(10)
+	SetEventRecorder(recorder)
+
+	for i := 0; i < trailSize+2; i++ {
+		Add(Record{Method: http.MethodPatch, Path: fmt.Sprintf("/policy/api/v1/infra/tier-1s/t%d", i), Outcome: OutcomeSucceeded})
+	}
+	records := Records("")
+	assert.Len(t, records, trailSize)
+	assert.Equal(t, "/policy/api/v1/infra/tier-1s/t2", records[0].Path)
+	assert.Equal(t, fmt.Sprintf("/policy/api/v1/infra/tier-1s/t%d", trailSize+1), records[trailSize-1].Path)
+	assert.Len(t, Records("/policy/api/v1/infra/tier-1s/t100"), 3)
+	assert.Empty(t, recorder.Events)
+
+	Add(Record{Method: http.MethodPatch, Path: "/policy/api/v1/infra", Summary: "upsert 1 Rule", Outcome: OutcomeFailed,
+		Error: "conflict", Initiator: NewInitiator("security_policy", "ns1", "sp1", "uid1")})
+	assert.Equal(t, "Warning NSXMutationFailed PATCH /policy/api/v1/infra failed: upsert 1 Rule, conflict", <-recorder.Events)
+	Add(Record{Method: http.MethodDelete, Path: "/policy/api/v1/infra/x", Outcome: OutcomeSucceeded, Initiator: NewInitiator("custom", "", "c1", "")})
+	assert.Empty(t, recorder.Events)
+
+	response := httptest.NewRecorder()
+	Handler(response, httptest.NewRequest(http.MethodGet, Path+"?path=/policy/api/v1/infra/x", nil))
+	var served []Record
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &served))
+	assert.Len(t, served, 1)
+	assert.Equal(t, "custom", served[0].Initiator.Kind)
+}
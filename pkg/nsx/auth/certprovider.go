@@ -3,6 +3,19 @@
 
 package auth
 
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
 // ClientCertProvider is implementation for client certificate provider
 // Responsible for preparing, providing and disposing client certificate
 // file. Basic implementation assumes the file exists in the file system
@@ -11,4 +24,110 @@ package auth
 type ClientCertProvider interface {
 	// FileName returns file name of certificate.
 	FileName() string
+	// GetClientCertificate returns the certificate presented in the TLS handshakes with NSX.
+	GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+}
+
+// CertSource loads the PEM encoded certificate and private key.
+type CertSource func() (cert []byte, key []byte, err error)
+
+// FileCertSource loads the certificate and the private key from the files.
+func FileCertSource(certFile, keyFile string) CertSource {
+	return func() ([]byte, []byte, error) {
+		cert, err := ioutil.ReadFile(certFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		key, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cert, key, nil
+	}
+}
+
+// SecretCertSource loads the certificate and the private key from the kubernetes.io/tls Secret.
+func SecretCertSource(c client.Reader, namespace, name string) CertSource {
+	return func() ([]byte, []byte, error) {
+		secret := &v1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+			return nil, nil, err
+		}
+		cert, key := secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey]
+		if len(cert) == 0 || len(key) == 0 {
+			return nil, nil, fmt.Errorf("no %s or %s in Secret %s/%s", v1.TLSCertKey, v1.TLSPrivateKeyKey, namespace, name)
+		}
+		return cert, key, nil
+	}
+}
+
+// PICertProvider provides the certificate of the Principal Identity NSX Operator authenticates to NSX with.
+// A rotated certificate loaded from the source is only presented once it is activated, so that it can be registered
+// to the Principal Identity with the certificate NSX still trusts.
+type PICertProvider struct {
+	name      string
+	source    CertSource
+	active    *tls.Certificate
+	activePEM []byte
+	sync.Mutex
+}
+
+// NewPICertProvider creates a PICertProvider and activates the certificate loaded from the source,
+// name describes the source in the logs.
+func NewPICertProvider(name string, source CertSource) (*PICertProvider, error) {
+	provider := &PICertProvider{name: name, source: source}
+	cert, key, err := source()
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.Activate(cert, key); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func (provider *PICertProvider) FileName() string {
+	return provider.name
+}
+
+func (provider *PICertProvider) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	provider.Lock()
+	defer provider.Unlock()
+	return provider.active, nil
+}
+
+// CertificatePEM returns the PEM encoded active certificate.
+func (provider *PICertProvider) CertificatePEM() []byte {
+	provider.Lock()
+	defer provider.Unlock()
+	return provider.activePEM
+}
+
+// Rotated loads the certificate from the source, it returns the certificate and the private key if the certificate
+// differs from the active one.
+func (provider *PICertProvider) Rotated() ([]byte, []byte, bool, error) {
+	cert, key, err := provider.source()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if bytes.Equal(bytes.TrimSpace(cert), bytes.TrimSpace(provider.CertificatePEM())) {
+		return nil, nil, false, nil
+	}
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		return nil, nil, false, err
+	}
+	return cert, key, true, nil
+}
+
+// Activate presents the certificate in the next TLS handshakes.
+func (provider *PICertProvider) Activate(cert, key []byte) error {
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return err
+	}
+	provider.Lock()
+	defer provider.Unlock()
+	provider.active = &pair
+	provider.activePEM = cert
+	return nil
 }
@@ -0,0 +1,105 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func generateCert(t *testing.T, cn string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestPICertProvider(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	cert1, key1 := generateCert(t, "nsx-operator-1")
+	assert.Nil(t, os.WriteFile(certFile, cert1, 0o600))
+	assert.Nil(t, os.WriteFile(keyFile, key1, 0o600))
+
+	provider, err := NewPICertProvider(certFile, FileCertSource(certFile, keyFile))
+	assert.Nil(t, err)
+	assert.Equal(t, certFile, provider.FileName())
+	assert.Equal(t, cert1, provider.CertificatePEM())
+	active, err := provider.GetClientCertificate(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(active.Certificate))
+
+	_, _, rotated, err := provider.Rotated()
+	assert.Nil(t, err)
+	assert.False(t, rotated)
+
+	// the rotated certificate is presented once it is activated
+	cert2, key2 := generateCert(t, "nsx-operator-2")
+	assert.Nil(t, os.WriteFile(certFile, cert2, 0o600))
+	assert.Nil(t, os.WriteFile(keyFile, key2, 0o600))
+	cert, key, rotated, err := provider.Rotated()
+	assert.Nil(t, err)
+	assert.True(t, rotated)
+	assert.Equal(t, cert1, provider.CertificatePEM())
+	assert.Nil(t, provider.Activate(cert, key))
+	assert.Equal(t, cert2, provider.CertificatePEM())
+
+	// a certificate not matching its key is not rotated
+	assert.Nil(t, os.WriteFile(keyFile, key1, 0o600))
+	assert.Nil(t, os.WriteFile(certFile, cert1, 0o600))
+	assert.Nil(t, os.WriteFile(keyFile, key2, 0o600))
+	_, _, rotated, err = provider.Rotated()
+	assert.NotNil(t, err)
+	assert.False(t, rotated)
+
+	_, err = NewPICertProvider("missing", FileCertSource(filepath.Join(dir, "missing.crt"), keyFile))
+	assert.NotNil(t, err)
+}
+
+func TestSecretCertSource(t *testing.T) {
+	cert, key := generateCert(t, "nsx-operator")
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "vmware-system-nsx", Name: "nsx-operator-cert"},
+		Type:       v1.SecretTypeTLS,
+		Data:       map[string][]byte{v1.TLSCertKey: cert, v1.TLSPrivateKeyKey: key},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	gotCert, gotKey, err := SecretCertSource(c, "vmware-system-nsx", "nsx-operator-cert")()
+	assert.Nil(t, err)
+	assert.Equal(t, cert, gotCert)
+	assert.Equal(t, key, gotKey)
+
+	_, _, err = SecretCertSource(c, "vmware-system-nsx", "missing")()
+	assert.NotNil(t, err)
+
+	secret.Data = map[string][]byte{v1.TLSCertKey: cert}
+	assert.Nil(t, c.Update(context.TODO(), secret))
+	_, _, err = SecretCertSource(c, "vmware-system-nsx", "nsx-operator-cert")()
+	assert.Equal(t, "no tls.crt or tls.key in Secret vmware-system-nsx/nsx-operator-cert", err.Error())
+}
@@ -0,0 +1,43 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CredentialProvider provides the username and password NSX Operator authenticates to NSX with, it is asked again
+// periodically so that the rotated credentials are picked up at runtime.
+type CredentialProvider interface {
+	// Credentials returns the current username and password.
+	Credentials() (username string, password string, err error)
+}
+
+// CredentialSource loads the username and password NSX Operator authenticates to NSX with.
+type CredentialSource func() (username string, password string, err error)
+
+// Credentials calls source, so that a CredentialSource is a CredentialProvider.
+func (source CredentialSource) Credentials() (string, string, error) {
+	return source()
+}
+
+// SecretCredentialSource loads the username and password from the kubernetes.io/basic-auth Secret.
+func SecretCredentialSource(c client.Reader, namespace, name string) CredentialSource {
+	return func() (string, string, error) {
+		secret := &v1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+			return "", "", err
+		}
+		username, password := secret.Data[v1.BasicAuthUsernameKey], secret.Data[v1.BasicAuthPasswordKey]
+		if len(username) == 0 || len(password) == 0 {
+			return "", "", fmt.Errorf("no %s or %s in Secret %s/%s", v1.BasicAuthUsernameKey, v1.BasicAuthPasswordKey, namespace, name)
+		}
+		return string(username), string(password), nil
+	}
+}
@@ -0,0 +1,34 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSecretCredentialSource(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "vmware-system-nsx", Name: "nsx-credentials"},
+		Data:       map[string][]byte{v1.BasicAuthUsernameKey: []byte("admin"), v1.BasicAuthPasswordKey: []byte("passw0rd")},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+	username, password, err := SecretCredentialSource(c, "vmware-system-nsx", "nsx-credentials")()
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", username)
+	assert.Equal(t, "passw0rd", password)
+
+	_, _, err = SecretCredentialSource(c, "vmware-system-nsx", "missing")()
+	assert.NotNil(t, err)
+
+	delete(secret.Data, v1.BasicAuthPasswordKey)
+	assert.Nil(t, c.Update(context.TODO(), secret))
+	_, _, err = SecretCredentialSource(c, "vmware-system-nsx", "nsx-credentials")()
+	assert.EqualError(t, err, "no username or password in Secret vmware-system-nsx/nsx-credentials")
+}
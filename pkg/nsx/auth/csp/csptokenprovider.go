@@ -0,0 +1,128 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package csp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth"
+)
+
+const (
+	// DefaultCSPURL is the VMware Cloud Services Platform issuing the access tokens of VMC NSX.
+	DefaultCSPURL = "https://console.cloud.vmware.com"
+	authorizePath = "/csp/gateway/am/api/auth/api-tokens/authorize"
+
+	// minFreshInterval is the min remaining lifetime of an access token before it is refreshed.
+	minFreshInterval = 5 * time.Minute
+	requestTimeout   = 30 * time.Second
+)
+
+var log = logf.Log.WithName("nsx").WithName("csp")
+
+// CSPTokenProvider exchanges a CSP refresh token for the access tokens used in the requests sent to VMC NSX.
+// The access token is refreshed before it expires, so that the requests never carry an expired token.
+type CSPTokenProvider struct {
+	cspURL string
+	// refreshTokenFile is read at each exchange, so that a rotated refresh token is picked up without restart.
+	refreshTokenFile string
+	client           *http.Client
+	token            string
+	expire           time.Time
+	mutex            sync.Mutex
+}
+
+type authorizeResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// NewTokenProvider creates a CSPTokenProvider exchanging the refresh token in the file with the CSP at the URL,
+// DefaultCSPURL is used if cspURL is empty.
+func NewTokenProvider(cspURL string, refreshTokenFile string) (auth.TokenProvider, error) {
+	if refreshTokenFile == "" {
+		return nil, errors.New("no CSP refresh token file")
+	}
+	if cspURL == "" {
+		cspURL = DefaultCSPURL
+	}
+	if _, err := url.Parse(cspURL); err != nil {
+		log.Error(err, "invalid CSP URL", "url", cspURL)
+		return nil, err
+	}
+	return &CSPTokenProvider{
+		cspURL:           strings.TrimSuffix(cspURL, "/"),
+		refreshTokenFile: refreshTokenFile,
+		client:           &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// GetToken returns the cached access token, a new one is exchanged if refreshToken is true or the cached one expires
+// within minFreshInterval.
+func (provider *CSPTokenProvider) GetToken(refreshToken bool) (string, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	if !refreshToken && provider.token != "" && time.Now().Add(minFreshInterval).Before(provider.expire) {
+		return provider.token, nil
+	}
+	token, expire, err := provider.exchange()
+	if err != nil {
+		log.Error(err, "failed to exchange CSP refresh token")
+		return "", err
+	}
+	provider.token = token
+	provider.expire = expire
+	log.V(1).Info("exchanged CSP access token", "expire", expire)
+	return token, nil
+}
+
+func (provider *CSPTokenProvider) HeaderValue(token string) string {
+	return "Bearer " + token
+}
+
+func (provider *CSPTokenProvider) exchange() (string, time.Time, error) {
+	refreshToken, err := ioutil.ReadFile(provider.refreshTokenFile)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	form := url.Values{}
+	form.Add("refresh_token", strings.TrimSpace(string(refreshToken)))
+	req, err := http.NewRequest(http.MethodPost, provider.cspURL+authorizePath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	start := time.Now()
+	resp, err := provider.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("CSP token exchange failed, unexpected status code %d", resp.StatusCode)
+	}
+	var res authorizeResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return "", time.Time{}, err
+	}
+	if res.AccessToken == "" {
+		return "", time.Time{}, errors.New("no access token in CSP response")
+	}
+	return res.AccessToken, start.Add(time.Duration(res.ExpiresIn) * time.Second), nil
+}
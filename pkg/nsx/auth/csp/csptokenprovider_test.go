@@ -0,0 +1,66 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package csp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSPTokenProvider_GetToken(t *testing.T) {
+	exchanged := 0
+	expiresIn := 1800
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, authorizePath, r.URL.Path)
+		assert.Nil(t, r.ParseForm())
+		if r.PostForm.Get("refresh_token") != "refresh-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		exchanged++
+		w.Write([]byte(fmt.Sprintf(`{"access_token":"access-%d","expires_in":%d,"token_type":"bearer"}`, exchanged, expiresIn)))
+	}))
+	defer ts.Close()
+	tokenFile := filepath.Join(t.TempDir(), "refresh_token")
+	assert.Nil(t, os.WriteFile(tokenFile, []byte("refresh-1\n"), 0o600))
+
+	provider, err := NewTokenProvider(ts.URL+"/", tokenFile)
+	assert.Nil(t, err)
+	token, err := provider.GetToken(false)
+	assert.Nil(t, err)
+	assert.Equal(t, "access-1", token)
+	assert.Equal(t, "Bearer access-1", provider.HeaderValue(token))
+
+	// the cached token is used until it is close to expire
+	token, _ = provider.GetToken(false)
+	assert.Equal(t, "access-1", token)
+	provider.(*CSPTokenProvider).expire = time.Now().Add(time.Minute)
+	token, _ = provider.GetToken(false)
+	assert.Equal(t, "access-2", token)
+	token, _ = provider.GetToken(true)
+	assert.Equal(t, "access-3", token)
+
+	// a rejected refresh token fails the exchange
+	assert.Nil(t, os.WriteFile(tokenFile, []byte("refresh-2"), 0o600))
+	_, err = provider.GetToken(true)
+	assert.Equal(t, "CSP token exchange failed, unexpected status code 400", err.Error())
+}
+
+func TestNewTokenProvider(t *testing.T) {
+	_, err := NewTokenProvider("", "")
+	assert.NotNil(t, err)
+
+	provider, err := NewTokenProvider("", "/etc/nsx-ujo/csp/refresh_token")
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultCSPURL, provider.(*CSPTokenProvider).cspURL)
+	_, err = provider.GetToken(false)
+	assert.NotNil(t, err)
+}
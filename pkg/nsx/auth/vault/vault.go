@@ -0,0 +1,240 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package vault
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultAuthPath is the path the Kubernetes auth method is enabled at by default.
+	DefaultAuthPath = "kubernetes"
+	requestTimeout  = 30 * time.Second
+)
+
+var (
+	log = logf.Log.WithName("nsx").WithName("vault")
+	// serviceAccountTokenFile is the token of the service account NSX Operator logs in to Vault with.
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// Config is where the NSX credentials are in Vault and how NSX Operator logs in to Vault.
+type Config struct {
+	// Addr is the address of Vault, e.g. https://vault:8200.
+	Addr string
+	// AuthPath is the path the Kubernetes auth method is enabled at, DefaultAuthPath is used if it is empty.
+	AuthPath string
+	// Role is the Vault role NSX Operator logs in with.
+	Role string
+	// SecretPath is the path of the secret holding the username and password fields, e.g. secret/data/nsx.
+	SecretPath string
+	// CAFile verifies the certificate of Vault, the system CAs are used if it is empty.
+	CAFile string
+}
+
+// CredentialProvider reads the NSX credentials from a Vault secret. It logs in to Vault with the service account token
+// and the role, and renews the Vault token before it expires, so that the credentials rotated in Vault are read at
+// runtime.
+type CredentialProvider struct {
+	addr       string
+	authPath   string
+	role       string
+	secretPath string
+	client     *http.Client
+	token      string
+	renewable  bool
+	// renewAt is when the token is renewed, expire is when it expires, they are zero if the token never expires.
+	renewAt time.Time
+	expire  time.Time
+	sync.Mutex
+}
+
+type authResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+type secretResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// NewCredentialProvider creates a CredentialProvider reading the credentials with the config.
+func NewCredentialProvider(config Config) (*CredentialProvider, error) {
+	if config.Role == "" || config.SecretPath == "" {
+		return nil, errors.New("no Vault role or secret path")
+	}
+	if _, err := url.Parse(config.Addr); err != nil {
+		log.Error(err, "invalid Vault address", "address", config.Addr)
+		return nil, err
+	}
+	authPath := config.AuthPath
+	if authPath == "" {
+		authPath = DefaultAuthPath
+	}
+	client := &http.Client{Timeout: requestTimeout}
+	if config.CAFile != "" {
+		caCert, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no CA certificate in %s", config.CAFile)
+		}
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+	}
+	return &CredentialProvider{
+		addr:       strings.TrimSuffix(config.Addr, "/"),
+		authPath:   strings.Trim(authPath, "/"),
+		role:       config.Role,
+		secretPath: strings.Trim(config.SecretPath, "/"),
+		client:     client,
+	}, nil
+}
+
+// Credentials reads the username and password from the Vault secret, it logs in to Vault again if the token is
+// rejected.
+func (provider *CredentialProvider) Credentials() (string, string, error) {
+	provider.Lock()
+	defer provider.Unlock()
+	if err := provider.ensureToken(); err != nil {
+		return "", "", err
+	}
+	username, password, status, err := provider.readSecret()
+	if status == http.StatusForbidden {
+		// the token may be revoked in Vault before it expires
+		log.Info("Vault token is rejected, logging in again")
+		if err := provider.login(); err != nil {
+			return "", "", err
+		}
+		username, password, _, err = provider.readSecret()
+	}
+	return username, password, err
+}
+
+// ensureToken logs in to Vault if there is no token or the token expires, and renews the token if it is due.
+func (provider *CredentialProvider) ensureToken() error {
+	now := time.Now()
+	if provider.token == "" || (!provider.expire.IsZero() && !now.Before(provider.expire)) {
+		return provider.login()
+	}
+	if provider.renewAt.IsZero() || now.Before(provider.renewAt) {
+		return nil
+	}
+	if provider.renewable {
+		err := provider.renew()
+		if err == nil {
+			return nil
+		}
+		log.Info("failed to renew Vault token, logging in again", "error", err.Error())
+	}
+	return provider.login()
+}
+
+// login logs in to Vault with the service account token through the Kubernetes auth method.
+func (provider *CredentialProvider) login() error {
+	jwt, err := ioutil.ReadFile(serviceAccountTokenFile)
+	if err != nil {
+		return err
+	}
+	body, _ := json.Marshal(map[string]string{"role": provider.role, "jwt": strings.TrimSpace(string(jwt))})
+	start := time.Now()
+	var res authResponse
+	if _, err := provider.do(http.MethodPost, "auth/"+provider.authPath+"/login", "", body, &res); err != nil {
+		log.Error(err, "failed to log in to Vault", "role", provider.role)
+		return err
+	}
+	if res.Auth.ClientToken == "" {
+		return errors.New("no client token in Vault login response")
+	}
+	provider.token = res.Auth.ClientToken
+	provider.setLease(start, res.Auth.LeaseDuration, res.Auth.Renewable)
+	log.Info("logged in to Vault", "role", provider.role, "expire", provider.expire)
+	return nil
+}
+
+// renew extends the lease of the token.
+func (provider *CredentialProvider) renew() error {
+	start := time.Now()
+	var res authResponse
+	if _, err := provider.do(http.MethodPost, "auth/token/renew-self", provider.token, []byte("{}"), &res); err != nil {
+		return err
+	}
+	provider.setLease(start, res.Auth.LeaseDuration, res.Auth.Renewable)
+	log.V(1).Info("renewed Vault token", "expire", provider.expire)
+	return nil
+}
+
+// setLease sets when the token is renewed and expires, the token is renewed after two thirds of its lease.
+func (provider *CredentialProvider) setLease(start time.Time, leaseDuration int64, renewable bool) {
+	provider.renewable = renewable
+	if leaseDuration <= 0 {
+		provider.renewAt, provider.expire = time.Time{}, time.Time{}
+		return
+	}
+	lease := time.Duration(leaseDuration) * time.Second
+	provider.renewAt, provider.expire = start.Add(lease*2/3), start.Add(lease)
+}
+
+// readSecret reads the username and password fields of the secret, the fields of a KV version 2 secret are nested in
+// its data.
+func (provider *CredentialProvider) readSecret() (string, string, int, error) {
+	var res secretResponse
+	status, err := provider.do(http.MethodGet, provider.secretPath, provider.token, nil, &res)
+	if err != nil {
+		return "", "", status, err
+	}
+	data := res.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	username, _ := data["username"].(string)
+	password, _ := data["password"].(string)
+	if username == "" || password == "" {
+		return "", "", status, fmt.Errorf("no username or password in Vault secret %s", provider.secretPath)
+	}
+	return username, password, status, nil
+}
+
+// do sends the request to the Vault API path and decodes the response into res, it returns the status code of the
+// response.
+func (provider *CredentialProvider) do(method, path, token string, body []byte, res interface{}) (int, error) {
+	req, err := http.NewRequest(method, provider.addr+"/v1/"+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Add("X-Vault-Token", token)
+	}
+	resp, err := provider.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("request %s %s to Vault failed, unexpected status code %d", method, path, resp.StatusCode)
+	}
+	return resp.StatusCode, json.Unmarshal(respBody, res)
+}
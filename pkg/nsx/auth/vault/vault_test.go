@@ -0,0 +1,161 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package vault
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVault serves the Kubernetes auth login, the token renewal and a KV version 2 secret.
+type fakeVault struct {
+	password  string
+	token     string
+	requests  []string
+	revoked   bool
+	renewable bool
+	sync.Mutex
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	vault := &fakeVault{password: "old", renewable: true}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vault.Lock()
+		defer vault.Unlock()
+		vault.requests = append(vault.requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			var login map[string]string
+			json.NewDecoder(r.Body).Decode(&login)
+			if login["role"] != "nsx-operator" || login["jwt"] != "sa-token" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			vault.token = "token-" + time.Now().String()
+			vault.revoked = false
+			w.Write([]byte(`{"auth": {"client_token": "` + vault.token + `", "lease_duration": 3600, "renewable": ` + strconv.FormatBool(vault.renewable) + `}}`))
+		case "/v1/auth/token/renew-self":
+			if r.Header.Get("X-Vault-Token") != vault.token {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"auth": {"client_token": "` + vault.token + `", "lease_duration": 3600, "renewable": true}}`))
+		case "/v1/secret/data/nsx":
+			if vault.revoked || r.Header.Get("X-Vault-Token") != vault.token {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"data": {"data": {"username": "admin", "password": "` + vault.password + `"}, "metadata": {"version": 1}}}`))
+		case "/v1/secret/nsx":
+			w.Write([]byte(`{"data": {"username": "admin"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.Nil(t, ioutil.WriteFile(tokenFile, []byte("sa-token\n"), 0600))
+	oldTokenFile := serviceAccountTokenFile
+	serviceAccountTokenFile = tokenFile
+	t.Cleanup(func() { serviceAccountTokenFile = oldTokenFile })
+	return vault, ts
+}
+
+func (vault *fakeVault) popRequests() []string {
+	vault.Lock()
+	defer vault.Unlock()
+	requests := vault.requests
+	vault.requests = nil
+	return requests
+}
+
+func TestNewCredentialProvider(t *testing.T) {
+	_, err := NewCredentialProvider(Config{Addr: "https://vault:8200", Role: "nsx-operator"})
+	assert.EqualError(t, err, "no Vault role or secret path")
+
+	provider, err := NewCredentialProvider(Config{Addr: "https://vault:8200/", Role: "nsx-operator", SecretPath: "/secret/data/nsx"})
+	assert.Nil(t, err)
+	assert.Equal(t, "https://vault:8200", provider.addr)
+	assert.Equal(t, DefaultAuthPath, provider.authPath)
+	assert.Equal(t, "secret/data/nsx", provider.secretPath)
+
+	_, err = NewCredentialProvider(Config{Addr: "https://vault:8200", Role: "nsx-operator", SecretPath: "secret/data/nsx", CAFile: "/no/such/file"})
+	assert.NotNil(t, err)
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	assert.Nil(t, ioutil.WriteFile(caFile, []byte("invalid"), 0600))
+	_, err = NewCredentialProvider(Config{Addr: "https://vault:8200", Role: "nsx-operator", SecretPath: "secret/data/nsx", CAFile: caFile})
+	assert.EqualError(t, err, "no CA certificate in "+caFile)
+}
+
+func TestCredentialProvider_Credentials(t *testing.T) {
+	vault, ts := newFakeVault(t)
+	provider, err := NewCredentialProvider(Config{Addr: ts.URL, Role: "nsx-operator", SecretPath: "secret/data/nsx"})
+	assert.Nil(t, err)
+
+	username, password, err := provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", username)
+	assert.Equal(t, "old", password)
+	assert.Equal(t, []string{"POST /v1/auth/kubernetes/login", "GET /v1/secret/data/nsx"}, vault.popRequests())
+
+	// the token is reused, and the rotated password is read
+	vault.password = "new"
+	_, password, err = provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "new", password)
+	assert.Equal(t, []string{"GET /v1/secret/data/nsx"}, vault.popRequests())
+
+	// the token is renewed after two thirds of its lease
+	provider.renewAt = time.Now().Add(-time.Second)
+	_, _, err = provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"POST /v1/auth/token/renew-self", "GET /v1/secret/data/nsx"}, vault.popRequests())
+	assert.True(t, provider.renewAt.After(time.Now()))
+
+	// it logs in again if the token is revoked
+	vault.revoked = true
+	_, password, err = provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "new", password)
+	assert.Equal(t, []string{"GET /v1/secret/data/nsx", "POST /v1/auth/kubernetes/login", "GET /v1/secret/data/nsx"}, vault.popRequests())
+
+	// it logs in again if the token can't be renewed or expires
+	vault.renewable = false
+	provider.renewable = false
+	provider.renewAt = time.Now().Add(-time.Second)
+	_, _, err = provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"POST /v1/auth/kubernetes/login", "GET /v1/secret/data/nsx"}, vault.popRequests())
+	provider.expire = time.Now().Add(-time.Second)
+	_, _, err = provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"POST /v1/auth/kubernetes/login", "GET /v1/secret/data/nsx"}, vault.popRequests())
+}
+
+func TestCredentialProvider_errors(t *testing.T) {
+	_, ts := newFakeVault(t)
+	provider, err := NewCredentialProvider(Config{Addr: ts.URL, Role: "nsx-operator", SecretPath: "secret/nsx"})
+	assert.Nil(t, err)
+	_, _, err = provider.Credentials()
+	assert.EqualError(t, err, "no username or password in Vault secret secret/nsx")
+
+	provider, err = NewCredentialProvider(Config{Addr: ts.URL, Role: "other", SecretPath: "secret/data/nsx"})
+	assert.Nil(t, err)
+	_, _, err = provider.Credentials()
+	assert.EqualError(t, err, "request POST auth/kubernetes/login to Vault failed, unexpected status code 400")
+
+	provider, err = NewCredentialProvider(Config{Addr: ts.URL, AuthPath: "k8s", Role: "nsx-operator", SecretPath: "secret/data/nsx"})
+	assert.Nil(t, err)
+	_, _, err = provider.Credentials()
+	assert.EqualError(t, err, "request POST auth/k8s/login to Vault failed, unexpected status code 404")
+}
@@ -0,0 +1,154 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of the circuit breaker of an endpoint.
+type BreakerState string
+
+const (
+	// BreakerClosed lets the requests through.
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen short-circuits the requests after consecutive failures.
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets one probe request through once the open period is over, its result decides whether the
+	// breaker closes or opens again.
+	BreakerHalfOpen BreakerState = "half-open"
+
+	// breakerFailureThreshold is the number of consecutive failures opening the breaker.
+	breakerFailureThreshold = 5
+	// breakerOpenPeriod is the period the breaker stays open before the requests are tried again.
+	breakerOpenPeriod = 30 * time.Second
+)
+
+// circuitBreaker stops sending requests to an endpoint failing consecutively, so that the callers get an error
+// immediately instead of waiting for the timeouts and retries of the requests.
+type circuitBreaker struct {
+	host     string
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	// probedAt is when the probe of the half-open breaker was let through, zero if none is in flight. A probe without
+	// a result for an open period is given up, so that another one can be sent.
+	probedAt   time.Time
+	threshold  int
+	openPeriod time.Duration
+	sync.Mutex
+}
+
+func newCircuitBreaker(host string, threshold int, openPeriod time.Duration) *circuitBreaker {
+	return &circuitBreaker{host: host, state: BreakerClosed, threshold: threshold, openPeriod: openPeriod}
+}
+
+// State returns the state of the breaker, an open breaker turns half-open once the open period is over.
+// A nil breaker is always closed.
+func (b *circuitBreaker) State() BreakerState {
+	if b == nil {
+		return BreakerClosed
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.refresh()
+	return b.state
+}
+
+// refresh turns the open breaker half-open once the open period is over, b must be locked.
+func (b *circuitBreaker) refresh() {
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.openPeriod {
+		b.state = BreakerHalfOpen
+		b.probedAt = time.Time{}
+	}
+}
+
+// probing returns whether the probe of the half-open breaker is in flight, b must be locked.
+func (b *circuitBreaker) probing() bool {
+	return b.state == BreakerHalfOpen && !b.probedAt.IsZero() && time.Since(b.probedAt) < b.openPeriod
+}
+
+// available returns whether a request could be sent through the breaker, without letting it through.
+func (b *circuitBreaker) available() bool {
+	if b == nil {
+		return true
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.refresh()
+	return b.state != BreakerOpen && !b.probing()
+}
+
+// allow returns whether a request can be sent through the breaker. Only one request is let through the half-open
+// breaker as the probe, the others are rejected until its result closes or opens the breaker.
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.refresh()
+	switch {
+	case b.state == BreakerOpen || b.probing():
+		return false
+	case b.state == BreakerHalfOpen:
+		b.probedAt = time.Now()
+	}
+	return true
+}
+
+// success closes the breaker.
+func (b *circuitBreaker) success() {
+	if b == nil {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.state != BreakerClosed {
+		log.Info("circuit breaker is closed", "endpoint", b.host)
+	}
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probedAt = time.Time{}
+}
+
+// failure opens the breaker if the failures reach the threshold, or if the breaker is half-open.
+func (b *circuitBreaker) failure() {
+	if b == nil {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.failures++
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.threshold) {
+		log.Info("circuit breaker is open", "endpoint", b.host, "failures", b.failures, "openPeriod", b.openPeriod)
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		b.probedAt = time.Time{}
+	}
+}
+
+// clientErrorCodes are the NSX error codes of the 5xx responses caused by the request rather than by an outage of the
+// endpoint, e.g. a search page exceeding the max size, which are not failures of the breaker.
+var clientErrorCodes = map[int64]bool{pageMaxErrorCode: true}
+
+// endpointFailure returns whether the response is a failure of the endpoint counted by the breaker, i.e. a 5xx
+// response which is neither an API not implemented by the NSX release nor caused by the request.
+func endpointFailure(statusCode int, body []byte) bool {
+	if statusCode < http.StatusInternalServerError || statusCode == http.StatusNotImplemented {
+		return false
+	}
+	return !clientError(body)
+}
+
+// clientError returns whether the response body is an NSX error with one of clientErrorCodes.
+func clientError(body []byte) bool {
+	var res struct {
+		ErrorCode int64 `json:"error_code"`
+	}
+	return json.Unmarshal(body, &res) == nil && clientErrorCodes[res.ErrorCode]
+}
@@ -0,0 +1,68 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := newCircuitBreaker("10.0.0.1", 3, 100*time.Millisecond)
+	assert.Equal(t, BreakerClosed, b.State())
+
+	// a success resets the consecutive failures
+	b.failure()
+	b.failure()
+	b.success()
+	b.failure()
+	b.failure()
+	assert.True(t, b.allow())
+	b.failure()
+	assert.Equal(t, BreakerOpen, b.State())
+	assert.False(t, b.allow())
+
+	// the breaker turns half-open after the open period and lets one probe through, a failure opens it again
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	assert.True(t, b.available())
+	assert.True(t, b.allow())
+	assert.False(t, b.available())
+	assert.False(t, b.allow())
+	b.failure()
+	assert.Equal(t, BreakerOpen, b.State())
+
+	// a success closes the half-open breaker
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, b.allow())
+	b.success()
+	assert.Equal(t, BreakerClosed, b.State())
+
+	// a probe without a result is given up after the open period
+	b.failure()
+	b.failure()
+	b.failure()
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, b.allow())
+	assert.False(t, b.allow())
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, b.allow())
+
+	var nilBreaker *circuitBreaker
+	nilBreaker.failure()
+	assert.True(t, nilBreaker.allow())
+}
+
+func TestEndpointFailure(t *testing.T) {
+	assert.False(t, endpointFailure(http.StatusOK, nil))
+	assert.False(t, endpointFailure(http.StatusBadRequest, []byte(`{"error_code":500012}`)))
+	assert.True(t, endpointFailure(http.StatusServiceUnavailable, nil))
+	assert.True(t, endpointFailure(http.StatusInternalServerError, []byte(`{"error_code":98}`)))
+	assert.False(t, endpointFailure(http.StatusNotImplemented, nil))
+	// the search page exceeding the max size is caused by the request
+	assert.False(t, endpointFailure(http.StatusServiceUnavailable, []byte(`{"error_code":60576}`)))
+}
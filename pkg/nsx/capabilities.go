@@ -0,0 +1,137 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"sync"
+	"time"
+)
+
+// featureRequirement is what NSX must meet for a feature to be supported.
+type featureRequirement struct {
+	// minVersion is the minimal major.minor.patch version of NSX.
+	minVersion [3]int64
+	// requiresLicense is whether NSX must have a valid license installed.
+	requiresLicense bool
+}
+
+// featureRequirements is the registry of the features gated by the version and licenses of NSX.
+var featureRequirements = map[string]featureRequirement{
+	FeatureSecurityPolicy:    {minVersion: nsx320Version},
+	FeatureNSXServiceAccount: {minVersion: nsx401Version},
+	FeatureVPC:               {minVersion: nsx411Version, requiresLicense: true},
+}
+
+// CapabilityRefreshInterval is how long the version and licenses of NSX are cached before they are read again, so
+// that the features are enabled after NSX is upgraded or licensed without restarting NSX Operator.
+var CapabilityRefreshInterval = 10 * time.Minute
+
+// Capabilities tells the features NSX supports by its version and licenses, which are read from NSX on the first
+// check and cached for CapabilityRefreshInterval.
+type Capabilities struct {
+	cluster  *Cluster
+	version  *NsxVersion
+	licensed bool
+	loadedAt time.Time
+	// gates are the features turned on or off by the feature gates regardless of NSX.
+	gates map[string]bool
+	sync.Mutex
+}
+
+func newCapabilities(cluster *Cluster) *Capabilities {
+	return &Capabilities{cluster: cluster}
+}
+
+// Supports returns whether NSX supports the feature. The feature gates override what NSX supports. The last
+// capabilities read are used if they can't be refreshed, and nothing is supported if they have never been read. All
+// the features are supported if there is no NSX cluster to check.
+func (c *Capabilities) Supports(feature string) bool {
+	if c == nil {
+		return true
+	}
+	c.Lock()
+	defer c.Unlock()
+	if enabled, ok := c.gates[feature]; ok {
+		log.V(1).Info("feature is set by the feature gate", "feature", feature, "enabled", enabled)
+		return enabled
+	}
+	if c.cluster == nil {
+		return true
+	}
+	requirement, ok := featureRequirements[feature]
+	if !ok {
+		return false
+	}
+	if c.version == nil || time.Since(c.loadedAt) >= CapabilityRefreshInterval {
+		if err := c.refresh(); err != nil {
+			if c.version == nil {
+				return false
+			}
+			// the last capabilities are kept until the next refresh
+			c.loadedAt = time.Now()
+		}
+	}
+	if !c.version.featureSupported(feature) {
+		log.V(1).Info("feature is not supported", "feature", feature, "current version", c.version.NodeVersion, "required version", requirement.minVersion)
+		return false
+	}
+	if requirement.requiresLicense && !c.licensed {
+		log.V(1).Info("feature is not supported", "feature", feature, "reason", "no valid NSX license")
+		return false
+	}
+	return true
+}
+
+// SetFeatureGates sets the features turned on or off regardless of NSX, keyed by feature.
+func (c *Capabilities) SetFeatureGates(gates map[string]bool) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.gates = gates
+}
+
+// Refresh reads the version and licenses of NSX again.
+func (c *Capabilities) Refresh() error {
+	if c == nil || c.cluster == nil {
+		return nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.refresh()
+}
+
+func (c *Capabilities) refresh() error {
+	version, err := c.cluster.GetVersion()
+	if err != nil {
+		log.Error(err, "get version error")
+		return err
+	}
+	if err := version.Validate(); err != nil {
+		log.Error(err, "validate version error")
+		return err
+	}
+	// the licenses may not be readable by the NSX user of NSX Operator, NSX is then taken as licensed and rejects the
+	// unlicensed requests by itself
+	licensed := true
+	if licenses, err := c.cluster.GetLicenses(); err != nil {
+		log.Info("failed to read the NSX licenses, assuming NSX is licensed", "error", err.Error())
+	} else {
+		licensed = licenses.valid()
+	}
+	c.version, c.licensed, c.loadedAt = version, licensed, time.Now()
+	log.Info("NSX capabilities refreshed", "version", version.NodeVersion, "licensed", licensed)
+	return nil
+}
+
+// valid returns whether any of the licenses is not expired.
+func (licenses *NsxLicenses) valid() bool {
+	for _, license := range licenses.Results {
+		if !license.IsExpired {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/ratelimiter"
+)
+
+// fakeNSXNode serves the version and licenses of an NSX manager.
+type fakeNSXNode struct {
+	version  atomic.Value
+	licenses atomic.Value
+	requests int32
+}
+
+func newFakeNSXNode(t *testing.T, version, licenses string) (*fakeNSXNode, *Cluster) {
+	node := &fakeNSXNode{}
+	node.version.Store(version)
+	node.licenses.Store(licenses)
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "reverse-proxy/node/health"):
+			w.Write([]byte(`{"healthy": true, "components_health": "MANAGER:UP, SEARCH:UP, UI:UP, NODE_MGMT:UP"}`))
+		case strings.HasSuffix(r.URL.Path, "/api/v1/node/version"):
+			atomic.AddInt32(&node.requests, 1)
+			w.Write([]byte(`{"node_version": "` + node.version.Load().(string) + `"}`))
+		case strings.HasSuffix(r.URL.Path, "/api/v1/licenses"):
+			if node.licenses.Load().(string) == "" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(node.licenses.Load().(string)))
+		}
+	}))
+	t.Cleanup(ts.Close)
+	thumbprint := []string{calcFingerprint(ts.Certificate().Raw)}
+	config := NewConfig(strings.TrimPrefix(ts.URL, "https://"), "admin", "passw0rd", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, thumbprint)
+	cluster, err := NewCluster(config)
+	assert.Nil(t, err)
+	return node, cluster
+}
+
+func TestCapabilities_Supports(t *testing.T) {
+	validLicenses := `{"results": [{"license_key": "expired", "is_expired": true}, {"license_key": "valid", "is_expired": false}]}`
+	expiredLicenses := `{"results": [{"license_key": "expired", "is_expired": true}]}`
+	tests := []struct {
+		name     string
+		version  string
+		licenses string
+		want     map[string]bool
+	}{
+		{"3.1.3", "3.1.3.3.0.18844962", validLicenses, map[string]bool{FeatureSecurityPolicy: false, FeatureNSXServiceAccount: false, FeatureVPC: false}},
+		{"3.2.0", "3.2.0", validLicenses, map[string]bool{FeatureSecurityPolicy: true, FeatureNSXServiceAccount: false, FeatureVPC: false}},
+		{"4.1.1 licensed", "4.1.1", validLicenses, map[string]bool{FeatureSecurityPolicy: true, FeatureNSXServiceAccount: true, FeatureVPC: true}},
+		{"4.1.1 expired", "4.1.1", expiredLicenses, map[string]bool{FeatureSecurityPolicy: true, FeatureNSXServiceAccount: true, FeatureVPC: false}},
+		{"4.1.1 licenses unreadable", "4.1.1", "", map[string]bool{FeatureVPC: true}},
+		{"invalid version", "12.3", validLicenses, map[string]bool{FeatureSecurityPolicy: false, FeatureVPC: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, cluster := newFakeNSXNode(t, tt.version, tt.licenses)
+			capabilities := newCapabilities(cluster)
+			for feature, want := range tt.want {
+				assert.Equal(t, want, capabilities.Supports(feature), feature)
+			}
+			assert.False(t, capabilities.Supports("notAFeature"))
+		})
+	}
+}
+
+func TestCapabilities_refresh(t *testing.T) {
+	node, cluster := newFakeNSXNode(t, "3.2.0", `{"results": [{"license_key": "valid"}]}`)
+	capabilities := newCapabilities(cluster)
+	assert.True(t, capabilities.Supports(FeatureSecurityPolicy))
+	assert.False(t, capabilities.Supports(FeatureNSXServiceAccount))
+	// the capabilities are cached
+	assert.Equal(t, int32(1), atomic.LoadInt32(&node.requests))
+
+	node.version.Store("4.1.1")
+	assert.False(t, capabilities.Supports(FeatureVPC))
+	assert.Nil(t, capabilities.Refresh())
+	assert.True(t, capabilities.Supports(FeatureVPC))
+
+	// the capabilities are read again after the refresh interval
+	node.version.Store("3.2.0")
+	capabilities.loadedAt = time.Now().Add(-CapabilityRefreshInterval)
+	assert.False(t, capabilities.Supports(FeatureVPC))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&node.requests))
+
+	// the last capabilities are kept if they can't be read
+	node.version.Store("invalid")
+	capabilities.loadedAt = time.Now().Add(-CapabilityRefreshInterval)
+	assert.True(t, capabilities.Supports(FeatureSecurityPolicy))
+	assert.NotNil(t, capabilities.Refresh())
+}
+
+func TestCapabilities_SetFeatureGates(t *testing.T) {
+	_, cluster := newFakeNSXNode(t, "3.2.0", `{"results": [{"license_key": "valid"}]}`)
+	capabilities := newCapabilities(cluster)
+	assert.True(t, capabilities.Supports(FeatureSecurityPolicy))
+	assert.False(t, capabilities.Supports(FeatureVPC))
+
+	capabilities.SetFeatureGates(map[string]bool{FeatureSecurityPolicy: false, FeatureVPC: true})
+	assert.False(t, capabilities.Supports(FeatureSecurityPolicy))
+	assert.True(t, capabilities.Supports(FeatureVPC))
+	assert.False(t, capabilities.Supports(FeatureNSXServiceAccount))
+
+	capabilities.SetFeatureGates(nil)
+	assert.True(t, capabilities.Supports(FeatureSecurityPolicy))
+	assert.False(t, capabilities.Supports(FeatureVPC))
+}
+
+func TestCapabilities_withoutCluster(t *testing.T) {
+	var capabilities *Capabilities
+	assert.True(t, capabilities.Supports(FeatureVPC))
+	assert.Nil(t, capabilities.Refresh())
+	client := &Client{}
+	assert.True(t, client.NSXCheckVersionForSecurityPolicy())
+	assert.True(t, client.Capabilities().Supports(FeatureNSXServiceAccount))
+}
@@ -0,0 +1,76 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+const policyAPIPrefix = "/policy/api/v1"
+
+// CheckNSXManagers sends an authenticated request to each NSX manager, it returns the error of each NSX manager which
+// can't be reached or rejects the credentials, keyed by its host.
+func (client *Client) CheckNSXManagers() map[string]error {
+	errs := map[string]error{}
+	if client.NSXChecker.cluster == nil {
+		return errs
+	}
+	for _, ep := range client.NSXChecker.cluster.Endpoints() {
+		if _, err := getPolicyObject(ep, "/infra"); err != nil {
+			errs[ep.Host()] = err
+		}
+	}
+	return errs
+}
+
+// PolicyObjectExists returns whether the NSX Policy object at the path exists, e.g. /infra/tier-0s/t0. The errors of
+// the NSX API are decoded by InitErrorFromResponse.
+func (client *Client) PolicyObjectExists(path string) (bool, error) {
+	if client.NSXChecker.cluster == nil {
+		return true, nil
+	}
+	endpoints := client.NSXChecker.cluster.Endpoints()
+	if len(endpoints) == 0 {
+		return false, fmt.Errorf("no NSX manager to read %s from", path)
+	}
+	return getPolicyObject(endpoints[0], path)
+}
+
+// getPolicyObject sends a GET request of the NSX Policy path to the endpoint, bypassing the balancer, it returns false
+// if NSX responds the object doesn't exist.
+func getPolicyObject(ep *Endpoint, path string) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s://%s%s%s", ep.Scheme(), ep.Host(), policyAPIPrefix, path), nil)
+	if err != nil {
+		return false, err
+	}
+	if err := ep.UpdateHttpRequestAuth(req); err != nil {
+		return false, err
+	}
+	resp, err := ep.noBalancerClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return true, nil
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if nsxErr := util.InitErrorFromResponse(ep.Host(), resp.StatusCode, body); nsxErr != nil {
+		if _, ok := nsxErr.(*util.ResourceNotFound); ok {
+			return false, nil
+		}
+		return false, nsxErr
+	}
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return false, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, util.CreateInvalidCredentials(fmt.Sprintf("%s responded %d", ep.Host(), resp.StatusCode))
+	}
+	return false, fmt.Errorf("request GET %s to %s failed, unexpected status code %d", path, ep.Host(), resp.StatusCode)
+}
@@ -0,0 +1,83 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/ratelimiter"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func newCheckedClient(t *testing.T, password string) *Client {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/session/create":
+			r.ParseForm()
+			if r.PostForm.Get("j_password") != "passw0rd" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Header().Set("X-Xsrf-Token", "token")
+		case "/policy/api/v1/infra":
+			if r.Header.Get("X-Xsrf-Token") != "token" {
+				w.WriteHeader(http.StatusForbidden)
+			}
+		case "/policy/api/v1/infra/tier-0s/t0":
+			w.Write([]byte(`{"id": "t0"}`))
+		case "/policy/api/v1/infra/tier-0s/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/policy/api/v1/infra/tier-0s/t0-missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code": 500012, "error_message": "The path=[/infra/tier-0s/t0-missing] is invalid"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	thumbprint := []string{calcFingerprint(ts.Certificate().Raw)}
+	config := NewConfig(strings.TrimPrefix(ts.URL, "https://"), "admin", password, "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, thumbprint)
+	cluster, err := NewCluster(config)
+	assert.Nil(t, err)
+	return &Client{NSXChecker: NSXHealthChecker{cluster: cluster}}
+}
+
+func TestClient_CheckNSXManagers(t *testing.T) {
+	client := newCheckedClient(t, "passw0rd")
+	assert.Empty(t, client.CheckNSXManagers())
+
+	client = newCheckedClient(t, "wrong")
+	errs := client.CheckNSXManagers()
+	assert.Equal(t, 1, len(errs))
+	for _, err := range errs {
+		assert.IsType(t, &util.InvalidCredentials{}, err)
+	}
+
+	assert.Empty(t, (&Client{}).CheckNSXManagers())
+}
+
+func TestClient_PolicyObjectExists(t *testing.T) {
+	client := newCheckedClient(t, "passw0rd")
+	exists, err := client.PolicyObjectExists("/infra/tier-0s/t0")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	for _, path := range []string{"/infra/tier-0s/t0-missing", "/infra/tier-1s/t1"} {
+		exists, err = client.PolicyObjectExists(path)
+		assert.Nil(t, err)
+		assert.False(t, exists, path)
+	}
+
+	_, err = client.PolicyObjectExists("/infra/tier-0s/error")
+	assert.Contains(t, err.Error(), "unexpected status code 500")
+
+	exists, err = (&Client{}).PolicyObjectExists("/infra/tier-0s/t0")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+}
@@ -6,6 +6,7 @@ package nsx
 import (
 	"errors"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -15,32 +16,90 @@ import (
 	mpsearch "github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/search"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/trust_management"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/trust_management/principal_identities"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/domains"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/domains/security_policies"
+	infra_ip_pools "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/ip_pools"
+	infra_realized_state "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/realized_state"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/sites/enforcement_points"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_0s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_1s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects"
+	project_infra "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra"
+	project_domains "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/domains"
+	project_ip_pools "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/ip_pools"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/realized_state"
 	vpc_search "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/search"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/nat"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets/ip_pools"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets/ports"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/search"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth/vault"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/ratelimiter"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
 )
 
 const (
 	FeatureSecurityPolicy    string = "SECURITY_POLICY"
 	FeatureNSXServiceAccount string = "NSX_SERVICE_ACCOUNT"
+	FeatureVPC               string = "VPC"
+)
+
+const (
+	// defaultConnIdleTimeout is the seconds an idle connection to the NSX managers is kept if it is not configured.
+	defaultConnIdleTimeout = 20
+	// defaultHTTPTimeout is the seconds a request to the NSX managers may take if it is not configured.
+	defaultHTTPTimeout = 20
 )
 
 type Client struct {
 	NsxConfig     *config.NSXOperatorConfig
 	RestConnector *client.RestConnector
 
-	QueryClient                search.QueryClient
-	VPCQueryClient             vpc_search.QueryClient
-	GroupClient                domains.GroupsClient
-	SecurityClient             domains.SecurityPoliciesClient
-	RuleClient                 security_policies.RulesClient
-	InfraClient                nsx_policy.InfraClient
-	ClusterControlPlanesClient enforcement_points.ClusterControlPlanesClient
+	QueryClient                  search.QueryClient
+	VPCQueryClient               vpc_search.QueryClient
+	GroupClient                  domains.GroupsClient
+	SecurityClient               domains.SecurityPoliciesClient
+	RuleClient                   security_policies.RulesClient
+	InfraClient                  nsx_policy.InfraClient
+	OrgRootClient                nsx_policy.OrgRootClient
+	ProjectGroupClient           project_domains.GroupsClient
+	ClusterControlPlanesClient   enforcement_points.ClusterControlPlanesClient
+	VPCClient                    projects.VpcsClient
+	VPCSubnetClient              vpcs.SubnetsClient
+	NATRuleClient                nat.NatRulesClient
+	IPPoolClient                 subnets.IpPoolsClient
+	SubnetStatusClient           subnets.StatusClient
+	SubnetIPAllocationClient     ip_pools.IpAllocationsClient
+	PortClient                   subnets.PortsClient
+	PortStateClient              ports.StateClient
+	VPCSecurityPolicyClient      vpcs.SecurityPoliciesClient
+	VPCIPAllocationClient        vpcs.IpAddressAllocationsClient
+	IPBlockClient                infra.IpBlocksClient
+	ProjectIPBlockClient         project_infra.IpBlocksClient
+	SegmentSecurityProfileClient project_infra.SegmentSecurityProfilesClient
+	SpoofGuardProfileClient      project_infra.SpoofguardProfilesClient
+	ProjectIPPoolClient          project_infra.IpPoolsClient
+	ProjectIPSubnetClient        project_ip_pools.IpSubnetsClient
+	ProjectIPAllocationClient    project_ip_pools.IpAllocationsClient
+	InfraIPAllocationClient      infra_ip_pools.IpAllocationsClient
+	RealizedEntitiesClient       realized_state.RealizedEntitiesClient
+	InfraRealizedEntitiesClient  infra_realized_state.RealizedEntitiesClient
+	VPCStaticRouteClient         vpcs.StaticRoutesClient
+	Tier1Client                  infra.Tier1sClient
+	Tier1StaticRouteClient       tier_1s.StaticRoutesClient
+	Tier1ForwardingTableClient   tier_1s.ForwardingTableClient
+	Tier0PrefixListClient        tier_0s.PrefixListsClient
+	Tier0RouteMapClient          tier_0s.RouteMapsClient
+	Tier0LocaleServicesClient    tier_0s.LocaleServicesClient
+	Tier0StaticRouteClient       tier_0s.StaticRoutesClient
+	Tier0ForwardingTableClient   tier_0s.ForwardingTableClient
 
 	MPQueryClient             mpsearch.QueryClient
 	CertificatesClient        trust_management.CertificatesClient
@@ -49,19 +108,22 @@ type Client struct {
 
 	NSXChecker    NSXHealthChecker
 	NSXVerChecker NSXVersionChecker
+
+	// connector creates the connectors of the SDK clients, it is used to create the clients bound to a context.
+	connector func() client.Connector
 }
 
 var nsx320Version = [3]int64{3, 2, 0}
 var nsx401Version = [3]int64{4, 0, 1}
+var nsx411Version = [3]int64{4, 1, 1}
 
 type NSXHealthChecker struct {
 	cluster *Cluster
 }
 
 type NSXVersionChecker struct {
-	cluster                    *Cluster
-	securityPolicySupported    bool
-	nsxServiceAccountSupported bool
+	cluster      *Cluster
+	capabilities *Capabilities
 }
 
 func (ck *NSXHealthChecker) CheckNSXHealth(req *http.Request) error {
@@ -74,119 +136,323 @@ func (ck *NSXHealthChecker) CheckNSXHealth(req *http.Request) error {
 	}
 }
 
+// CheckNSXSession fails if no endpoint has a valid session, i.e. no endpoint passed its latest authenticated health
+// check, or if the circuit breakers of the endpoints are open, so that the pod is not ready while it can't program NSX.
+func (ck *NSXHealthChecker) CheckNSXSession(req *http.Request) error {
+	if ck.cluster == nil || ck.cluster.Available() {
+		return nil
+	}
+	return errors.New("no NSX endpoint with a valid session is available")
+}
+
 func restConnector(c *Cluster) *client.RestConnector {
 	connector, _ := c.NewRestConnector()
 	return connector
 }
 
 func GetClient(cf *config.NSXOperatorConfig) *Client {
+	return GetClientWithOptions(cf, ClientOptions{})
+}
+
+// ClientOptions holds the settings of the client read from the cluster, such as the Secrets of the client certificate
+// and the proxy credentials.
+type ClientOptions struct {
+	// CertProvider provides the certificate the client authenticates to NSX with, the token or user configured is
+	// used if it is nil.
+	CertProvider auth.ClientCertProvider
+	// ProxyURL is the proxy NSX is reached through, the proxy URL configured without credentials is used if it is nil.
+	ProxyURL *url.URL
+	// CredentialProvider provides the username and password the client authenticates to NSX with, the user configured
+	// is used if it is nil.
+	CredentialProvider auth.CredentialProvider
+}
+
+// NewCredentialProvider creates the provider of the username and password NSX Operator authenticates with, the
+// credential Secret is read with reader. It returns nil if the credentials are neither in a Secret nor in Vault.
+func NewCredentialProvider(cf *config.NSXOperatorConfig, reader ctrlclient.Reader) (auth.CredentialProvider, error) {
+	if cf.NsxApiCredentialSecret != "" {
+		parts := strings.Split(cf.NsxApiCredentialSecret, "/")
+		return auth.SecretCredentialSource(reader, parts[0], parts[1]), nil
+	}
+	if cf.VaultAddr != "" {
+		provider, err := vault.NewCredentialProvider(vault.Config{
+			Addr:       cf.VaultAddr,
+			AuthPath:   cf.VaultAuthPath,
+			Role:       cf.VaultRole,
+			SecretPath: cf.VaultSecretPath,
+			CAFile:     cf.VaultCAFile,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+	return nil, nil
+}
+
+// NewClientCertProvider creates the provider of the Principal Identity certificate NSX Operator authenticates with,
+// the certificate Secret is read with reader. It returns nil if no certificate is configured.
+func NewClientCertProvider(cf *config.NSXOperatorConfig, reader ctrlclient.Reader) (auth.ClientCertProvider, error) {
+	if cf.NsxApiCertFile != "" {
+		return auth.NewPICertProvider(cf.NsxApiCertFile, auth.FileCertSource(cf.NsxApiCertFile, cf.NsxApiPrivateKeyFile))
+	}
+	if cf.NsxApiCertSecret != "" {
+		parts := strings.Split(cf.NsxApiCertSecret, "/")
+		return auth.NewPICertProvider(cf.NsxApiCertSecret, auth.SecretCertSource(reader, parts[0], parts[1]))
+	}
+	return nil, nil
+}
+
+// GetClientWithOptions creates the client with the settings configured in cf and the options read from the cluster.
+func GetClientWithOptions(cf *config.NSXOperatorConfig, opts ClientOptions) *Client {
 	// Set log level for vsphere-automation-sdk-go
 	logger := logrus.New()
 	vspherelog.SetLogger(logger)
-	c := NewConfig(strings.Join(cf.NsxApiManagers, ","), cf.NsxApiUser, cf.NsxApiPassword, "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, cf.GetTokenProvider(), nil, cf.Thumbprint)
+	rateMode := ratelimiter.AIMD
+	if cf.APIRateMode == config.APIRateModeFixRate {
+		rateMode = ratelimiter.FIXRATE
+	}
+	certProvider := opts.CertProvider
+	var tokenProvider auth.TokenProvider
+	if certProvider == nil {
+		tokenProvider = cf.GetTokenProvider()
+	}
+	connIdleTimeout := defaultConnIdleTimeout
+	if cf.ConnIdleTimeout > 0 {
+		connIdleTimeout = cf.ConnIdleTimeout
+	}
+	readTimeout, writeTimeout := defaultHTTPTimeout, defaultHTTPTimeout
+	if cf.ReadTimeout > 0 {
+		readTimeout = cf.ReadTimeout
+	}
+	if cf.WriteTimeout > 0 {
+		writeTimeout = cf.WriteTimeout
+	}
+	// the HTTP timeout bounds all the requests, the read and write requests are bounded by their own timeouts
+	httpTimeout := readTimeout
+	if writeTimeout > httpTimeout {
+		httpTimeout = writeTimeout
+	}
+	// the server certificates aren't verified if no way to verify them is configured
+	insecure := cf.Insecure || (len(cf.CaFile) == 0 && len(cf.Thumbprint) == 0)
+	username, password := cf.NsxApiUser, cf.NsxApiPassword
+	if opts.CredentialProvider != nil {
+		var err error
+		if username, password, err = opts.CredentialProvider.Credentials(); err != nil {
+			log.Error(err, "failed to load the NSX credentials")
+		}
+	}
+	c := NewConfig(strings.Join(cf.NsxApiManagers, ","), username, password, strings.Join(cf.CaFile, ","), cf.MaxConnsPerHost, 3, httpTimeout, connIdleTimeout, insecure, true, true, rateMode, tokenProvider, certProvider, cf.Thumbprint)
+	c.ReadTimeout = readTimeout
+	c.WriteTimeout = writeTimeout
+	c.MaxIdleConns = cf.MaxIdleConns
+	c.MaxIdleConnsPerHost = cf.MaxIdleConnsPerHost
+	c.TCPKeepAlive = cf.TCPKeepAlive
+	c.ProxyURL = opts.ProxyURL
+	if c.ProxyURL == nil {
+		proxyURL, err := NewProxyURL(cf, nil)
+		if err != nil {
+			log.Error(err, "invalid proxy URL", "ProxyURL", cf.ProxyURL)
+		}
+		c.ProxyURL = proxyURL
+	}
+	c.NoProxy = cf.NoProxy
+	c.CredentialProvider = opts.CredentialProvider
+	c.APIRateLimits = rateLimits(cf)
 	cluster, _ := NewCluster(c)
 
-	queryClient := search.NewQueryClient(restConnector(cluster))
-	groupClient := domains.NewGroupsClient(restConnector(cluster))
-	securityClient := domains.NewSecurityPoliciesClient(restConnector(cluster))
-	ruleClient := security_policies.NewRulesClient(restConnector(cluster))
-	infraClient := nsx_policy.NewInfraClient(restConnector(cluster))
-	vpcQueryClient := vpc_search.NewQueryClient(restConnector(cluster))
-	clusterControlPlanesClient := enforcement_points.NewClusterControlPlanesClient(restConnector(cluster))
-
-	mpQueryClient := mpsearch.NewQueryClient(restConnector(cluster))
-	certificatesClient := trust_management.NewCertificatesClient(restConnector(cluster))
-	principalIdentitiesClient := trust_management.NewPrincipalIdentitiesClient(restConnector(cluster))
-	withCertificateClient := principal_identities.NewWithCertificateClient(restConnector(cluster))
-
-	nsxChecker := &NSXHealthChecker{
+	nsxClient := newClient(cf, func() client.Connector { return restConnector(cluster) })
+	nsxClient.RestConnector = restConnector(cluster)
+	nsxClient.NSXChecker = NSXHealthChecker{
 		cluster: cluster,
 	}
-	nsxVersionChecker := &NSXVersionChecker{
-		cluster:                 cluster,
-		securityPolicySupported: false,
+	nsxClient.NSXVerChecker = NSXVersionChecker{
+		cluster:      cluster,
+		capabilities: newCapabilities(cluster),
+	}
+	if cf.CoeConfig != nil {
+		gates, _ := cf.GetFeatureGates()
+		nsxClient.Capabilities().SetFeatureGates(gates)
+	}
+	// the capabilities are read again when the controllers check them, so it's unnecessary to exit even if it fails
+	// the first time
+	if err := nsxClient.Capabilities().Refresh(); err != nil {
+		log.Error(err, "initial NSX capabilities check got error")
 	}
 
+	return nsxClient
+}
+
+// newClient creates the SDK clients, each on top of a new connector.
+func newClient(cf *config.NSXOperatorConfig, connector func() client.Connector) *Client {
+	queryClient := search.NewQueryClient(connector())
+	groupClient := domains.NewGroupsClient(connector())
+	securityClient := domains.NewSecurityPoliciesClient(connector())
+	ruleClient := security_policies.NewRulesClient(connector())
+	infraClient := nsx_policy.NewInfraClient(connector())
+	orgRootClient := nsx_policy.NewOrgRootClient(connector())
+	projectGroupClient := project_domains.NewGroupsClient(connector())
+	vpcQueryClient := vpc_search.NewQueryClient(connector())
+	clusterControlPlanesClient := enforcement_points.NewClusterControlPlanesClient(connector())
+	vpcClient := projects.NewVpcsClient(connector())
+	vpcSubnetClient := vpcs.NewSubnetsClient(connector())
+	natRuleClient := nat.NewNatRulesClient(connector())
+	ipPoolClient := subnets.NewIpPoolsClient(connector())
+	subnetStatusClient := subnets.NewStatusClient(connector())
+	portClient := subnets.NewPortsClient(connector())
+	portStateClient := ports.NewStateClient(connector())
+	subnetIPAllocationClient := ip_pools.NewIpAllocationsClient(connector())
+	vpcSecurityPolicyClient := vpcs.NewSecurityPoliciesClient(connector())
+	vpcIPAllocationClient := vpcs.NewIpAddressAllocationsClient(connector())
+	ipBlockClient := infra.NewIpBlocksClient(connector())
+	projectIPBlockClient := project_infra.NewIpBlocksClient(connector())
+	segmentSecurityProfileClient := project_infra.NewSegmentSecurityProfilesClient(connector())
+	spoofGuardProfileClient := project_infra.NewSpoofguardProfilesClient(connector())
+	projectIPPoolClient := project_infra.NewIpPoolsClient(connector())
+	projectIPSubnetClient := project_ip_pools.NewIpSubnetsClient(connector())
+	projectIPAllocationClient := project_ip_pools.NewIpAllocationsClient(connector())
+	infraIPAllocationClient := infra_ip_pools.NewIpAllocationsClient(connector())
+	realizedEntitiesClient := realized_state.NewRealizedEntitiesClient(connector())
+	infraRealizedEntitiesClient := infra_realized_state.NewRealizedEntitiesClient(connector())
+	vpcStaticRouteClient := vpcs.NewStaticRoutesClient(connector())
+	tier1Client := infra.NewTier1sClient(connector())
+	tier1StaticRouteClient := tier_1s.NewStaticRoutesClient(connector())
+	tier1ForwardingTableClient := tier_1s.NewForwardingTableClient(connector())
+	tier0PrefixListClient := tier_0s.NewPrefixListsClient(connector())
+	tier0RouteMapClient := tier_0s.NewRouteMapsClient(connector())
+	tier0LocaleServicesClient := tier_0s.NewLocaleServicesClient(connector())
+	tier0StaticRouteClient := tier_0s.NewStaticRoutesClient(connector())
+	tier0ForwardingTableClient := tier_0s.NewForwardingTableClient(connector())
+
+	mpQueryClient := mpsearch.NewQueryClient(connector())
+	certificatesClient := trust_management.NewCertificatesClient(connector())
+	principalIdentitiesClient := trust_management.NewPrincipalIdentitiesClient(connector())
+	withCertificateClient := principal_identities.NewWithCertificateClient(connector())
+
 	nsxClient := &Client{
 		NsxConfig:      cf,
-		RestConnector:  restConnector(cluster),
 		QueryClient:    queryClient,
 		GroupClient:    groupClient,
 		SecurityClient: securityClient,
 		RuleClient:     ruleClient,
 		InfraClient:    infraClient,
 
-		ClusterControlPlanesClient: clusterControlPlanesClient,
+		ClusterControlPlanesClient:   clusterControlPlanesClient,
+		OrgRootClient:                orgRootClient,
+		ProjectGroupClient:           projectGroupClient,
+		VPCClient:                    vpcClient,
+		VPCSubnetClient:              vpcSubnetClient,
+		NATRuleClient:                natRuleClient,
+		IPPoolClient:                 ipPoolClient,
+		SubnetStatusClient:           subnetStatusClient,
+		SubnetIPAllocationClient:     subnetIPAllocationClient,
+		PortClient:                   portClient,
+		PortStateClient:              portStateClient,
+		VPCSecurityPolicyClient:      vpcSecurityPolicyClient,
+		VPCIPAllocationClient:        vpcIPAllocationClient,
+		IPBlockClient:                ipBlockClient,
+		ProjectIPBlockClient:         projectIPBlockClient,
+		SegmentSecurityProfileClient: segmentSecurityProfileClient,
+		SpoofGuardProfileClient:      spoofGuardProfileClient,
+		ProjectIPPoolClient:          projectIPPoolClient,
+		ProjectIPSubnetClient:        projectIPSubnetClient,
+		ProjectIPAllocationClient:    projectIPAllocationClient,
+		InfraIPAllocationClient:      infraIPAllocationClient,
+		RealizedEntitiesClient:       realizedEntitiesClient,
+		InfraRealizedEntitiesClient:  infraRealizedEntitiesClient,
+		VPCStaticRouteClient:         vpcStaticRouteClient,
+		Tier1Client:                  tier1Client,
+		Tier1StaticRouteClient:       tier1StaticRouteClient,
+		Tier1ForwardingTableClient:   tier1ForwardingTableClient,
+		Tier0PrefixListClient:        tier0PrefixListClient,
+		Tier0RouteMapClient:          tier0RouteMapClient,
+		Tier0LocaleServicesClient:    tier0LocaleServicesClient,
+		Tier0StaticRouteClient:       tier0StaticRouteClient,
+		Tier0ForwardingTableClient:   tier0ForwardingTableClient,
 
 		MPQueryClient:             mpQueryClient,
 		CertificatesClient:        certificatesClient,
 		PrincipalIdentitiesClient: principalIdentitiesClient,
 		WithCertificateClient:     withCertificateClient,
 
-		NSXChecker:     *nsxChecker,
-		NSXVerChecker:  *nsxVersionChecker,
 		VPCQueryClient: vpcQueryClient,
+
+		connector: connector,
 	}
-	// NSX version check will be restarted during SecurityPolicy reconcile
-	// So, it's unnecessary to exit even if failed in the first time
-	if !nsxClient.NSXCheckVersionForSecurityPolicy() {
-		err := errors.New("SecurityPolicy feature support check failed")
-		log.Error(err, "initial NSX version check for SecurityPolicy got error")
+	return nsxClient
+}
+
+// UpdateNSXManagers applies a new NSX manager list to the running client. Connections to removed managers are
+// drained and new managers are probed, the SDK clients keep working on top of the same cluster.
+// The reloaded values are kept in the cluster, NsxConfig keeps the values the client was started with.
+func (client *Client) UpdateNSXManagers(managers []string, thumbprint []string) error {
+	return client.NSXChecker.cluster.UpdateEndpoints(managers, thumbprint)
+}
+
+// UpdateRateLimits applies the API rate limits of the config to the running client.
+func (client *Client) UpdateRateLimits(cf *config.NSXOperatorConfig) {
+	if client.NSXChecker.cluster == nil {
+		return
 	}
-	if !nsxClient.NSXCheckVersionForNSXServiceAccount() {
-		err := errors.New("NSXServiceAccount feature support check failed")
-		log.Error(err, "initial NSX version check for NSXServiceAccount got error")
+	client.NSXChecker.cluster.UpdateRateLimits(rateLimits(cf))
+}
+
+// rateLimits returns the API rate limits configured in cf.
+func rateLimits(cf *config.NSXOperatorConfig) ratelimiter.Limits {
+	return ratelimiter.Limits{
+		ratelimiter.PolicyRead:  cf.APIPolicyReadRateLimit,
+		ratelimiter.PolicyWrite: cf.APIPolicyWriteRateLimit,
+		ratelimiter.MPRead:      cf.APIMPReadRateLimit,
+		ratelimiter.MPWrite:     cf.APIMPWriteRateLimit,
 	}
+}
 
-	return nsxClient
+// NSXManagers returns the NSX managers and thumbprints currently used by the client.
+func (client *Client) NSXManagers() ([]string, []string) {
+	return client.NSXChecker.cluster.managers()
 }
 
-func (client *Client) NSXCheckVersionForSecurityPolicy() bool {
-	if client.NSXVerChecker.securityPolicySupported {
-		return true
+// NSXEndpointStats returns the state of the endpoints of the NSX managers and the number of the requests failed over
+// between them, it returns nothing if the client is not created on top of an NSX cluster.
+func (client *Client) NSXEndpointStats() ([]EndpointStats, uint64) {
+	if client.NSXChecker.cluster == nil {
+		return nil, 0
 	}
+	return client.NSXChecker.cluster.EndpointStats(), client.NSXChecker.cluster.Failovers()
+}
 
-	nsxVersion, err := client.NSXVerChecker.cluster.GetVersion()
-	if err != nil {
-		log.Error(err, "get version error")
-		return false
+// CheckAvailable returns an NSXUnavailableError if no NSX manager endpoint can serve the requests, the callers should
+// try again later instead of waiting for the requests to fail.
+func (client *Client) CheckAvailable() error {
+	if client == nil || client.NSXChecker.cluster == nil {
+		return nil
 	}
-	err = nsxVersion.Validate()
-	if err != nil {
-		log.Error(err, "validate version error")
-		return false
+	if !client.NSXChecker.cluster.Available() {
+		return util.NSXUnavailableError{Desc: "NSX managers are unavailable"}
 	}
+	return nil
+}
+
+// Capabilities returns the features supported by NSX, the controllers check it before they configure the features
+// requiring a newer or licensed NSX.
+func (client *Client) Capabilities() *Capabilities {
+	return client.NSXVerChecker.capabilities
+}
 
-	if !nsxVersion.featureSupported(FeatureSecurityPolicy) {
-		err = errors.New("NSX version check failed")
-		log.Error(err, "SecurityPolicy feature is not supported", "current version", nsxVersion.NodeVersion, "required version", nsx320Version)
+func (client *Client) NSXCheckVersionForSecurityPolicy() bool {
+	if !client.Capabilities().Supports(FeatureSecurityPolicy) {
+		err := errors.New("NSX version check failed")
+		log.Error(err, "SecurityPolicy feature is not supported", "required version", nsx320Version)
 		return false
 	}
-	client.NSXVerChecker.securityPolicySupported = true
 	return true
 }
 
 func (client *Client) NSXCheckVersionForNSXServiceAccount() bool {
-	if client.NSXVerChecker.nsxServiceAccountSupported {
-		return true
-	}
-
-	nsxVersion, err := client.NSXVerChecker.cluster.GetVersion()
-	if err != nil {
-		log.Error(err, "get version error")
-		return false
-	}
-	err = nsxVersion.Validate()
-	if err != nil {
-		log.Error(err, "validate version error")
-		return false
-	}
-
-	if !nsxVersion.featureSupported(FeatureNSXServiceAccount) {
-		err = errors.New("NSX version check failed")
-		log.Error(err, "NSXServiceAccount feature is not supported", "current version", nsxVersion.NodeVersion, "required version", nsx320Version)
+	if !client.Capabilities().Supports(FeatureNSXServiceAccount) {
+		err := errors.New("NSX version check failed")
+		log.Error(err, "NSXServiceAccount feature is not supported", "required version", nsx401Version)
 		return false
 	}
-	client.NSXVerChecker.nsxServiceAccountSupported = true
 	return true
 }
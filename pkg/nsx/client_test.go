@@ -59,6 +59,20 @@ func TestNSXHealthChecker_CheckNSXHealth(t *testing.T) {
 	}
 }
 
+func TestNSXHealthChecker_CheckNSXSession(t *testing.T) {
+	config := NewConfig("1.1.1.1", "1", "1", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, []string{})
+	cluster, _ := NewCluster(config)
+	ck := &NSXHealthChecker{cluster: cluster}
+
+	for _, ep := range cluster.Endpoints() {
+		ep.setStatus(DOWN)
+	}
+	assert.NotNil(t, ck.CheckNSXSession(nil))
+	cluster.Endpoints()[0].setStatus(UP)
+	assert.Nil(t, ck.CheckNSXSession(nil))
+	assert.Nil(t, (&NSXHealthChecker{}).CheckNSXSession(nil))
+}
+
 func TestGetClient(t *testing.T) {
 	cf := config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{NsxApiUser: "1", NsxApiPassword: "1"}}
 	cf.VCConfig = &config.VCConfig{}
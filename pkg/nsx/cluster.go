@@ -4,10 +4,15 @@
 package nsx
 
 import (
+	"context"
 	"crypto/sha1"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"regexp"
@@ -44,16 +49,31 @@ type Cluster struct {
 	transport        *Transport
 	client           http.Client
 	noBalancerClient http.Client
+	conns            *connTracker
 	sync.Mutex
 }
 type NsxVersion struct {
 	NodeVersion string `json:"node_version"`
 }
 
+// NsxLicenses is the list of the licenses installed on NSX.
+type NsxLicenses struct {
+	Results []NsxLicense `json:"results"`
+}
+
+type NsxLicense struct {
+	LicenseKey string `json:"license_key"`
+	IsExpired  bool   `json:"is_expired"`
+}
+
+const drainCheckInterval = time.Second
+
 var (
-	jarCache   = NewJar()
-	nsxVersion = &NsxVersion{}
-	log        = logf.Log.WithName("nsx").WithName("cluster")
+	jarCache = NewJar()
+	log      = logf.Log.WithName("nsx").WithName("cluster")
+	// wireLog logs the requests sent to NSX and the responses with their payloads at verbosity 2, its verbosity can be
+	// set apart with the nsx.wire module.
+	wireLog = logf.Log.WithName("nsx").WithName("wire")
 )
 
 // NewCluster creates a cluster based on nsx Config.
@@ -61,6 +81,7 @@ func NewCluster(config *Config) (*Cluster, error) {
 	log.Info("creating cluster")
 	cluster := &Cluster{}
 	cluster.config = config
+	cluster.conns = newConnTracker()
 	cluster.transport = cluster.createTransport(time.Duration(config.ConnIdleTimeout))
 	cluster.client = cluster.createHTTPClient(cluster.transport, time.Duration(config.HTTPTimeout))
 	cluster.noBalancerClient = cluster.createNoBalancerClient(time.Duration(config.HTTPTimeout), time.Duration(config.ConnIdleTimeout))
@@ -98,7 +119,10 @@ func (cluster *Cluster) NewRestConnector() (*policyclient.RestConnector, *Header
 	return connector, header
 }
 
-func (cluster *Cluster) getThumbprint(addr string) string {
+// getThumbprint returns the thumbprint of the manager at addr and the number of configured thumbprints.
+func (cluster *Cluster) getThumbprint(addr string) (string, int) {
+	cluster.Lock()
+	defer cluster.Unlock()
 	host := addr[:strings.Index(addr, ":")]
 	var thumbprint string
 	tpCount := len(cluster.config.Thumbprint)
@@ -107,54 +131,158 @@ func (cluster *Cluster) getThumbprint(addr string) string {
 	}
 	if tpCount > 1 {
 		for index, ep := range cluster.endpoints {
-			epHost := ep.Host()
-			if pos := strings.Index(ep.Host(), ":"); pos > 0 {
-				epHost = epHost[:pos]
-			}
-			if epHost == host {
+			if hostname(ep.Host()) == host {
 				thumbprint = cluster.config.Thumbprint[index]
 				break
 			}
 		}
 	}
-	return thumbprint
+	return thumbprint, tpCount
 }
 
 func (cluster *Cluster) createTransport(idle time.Duration) *Transport {
 	dial := func(network, addr string) (net.Conn, error) {
-		thumbprint := cluster.getThumbprint(addr)
-		tpCount := len(cluster.config.Thumbprint)
-		config := &tls.Config{
-			InsecureSkipVerify: true,
-			VerifyConnection: func(cs tls.ConnectionState) error {
-				// not check thumbprint if no thumbprint config
-				if tpCount > 0 {
-					fingerprint := calcFingerprint(cs.PeerCertificates[0].Raw)
-					if strings.Compare(fingerprint, thumbprint) == 0 {
-						return nil
-					} else {
-						err := errors.New("server certificate didn't match trusted fingerprint")
-						log.Error(err, "verify thumbprint", "address", addr, "server thumbprint", fingerprint, "local thumbprint", thumbprint)
-						return err
-					}
-				}
+		return cluster.dialTLS(context.Background(), network, addr)
+	}
+
+	tr := &http.Transport{
+		DialTLS:         dial,
+		IdleConnTimeout: idle * time.Second,
+	}
+	cluster.setConnectionPool(tr)
+	return &Transport{Base: tr}
+}
+
+// setConnectionPool bounds the connections of the transport with the config, 0 means no limit except for
+// MaxIdleConnsPerHost which defaults to http.DefaultMaxIdleConnsPerHost.
+func (cluster *Cluster) setConnectionPool(tr *http.Transport) {
+	if cluster.config == nil {
+		return
+	}
+	tr.MaxIdleConns = cluster.config.MaxIdleConns
+	tr.MaxIdleConnsPerHost = cluster.config.MaxIdleConnsPerHost
+	tr.MaxConnsPerHost = cluster.config.ConcurrentConnections
+}
+
+// dialer returns the dialer of the connections to the endpoints, the TCP keep-alive period defaults to 15s.
+func (cluster *Cluster) dialer() *net.Dialer {
+	if cluster.config == nil {
+		return &net.Dialer{}
+	}
+	return &net.Dialer{KeepAlive: time.Duration(cluster.config.TCPKeepAlive) * time.Second}
+}
+
+// dialTLS connects to the endpoint at addr, through the proxy if one is configured for it, and verifies its
+// certificate with the TLS config of the endpoint.
+func (cluster *Cluster) dialTLS(ctx context.Context, network, addr string) (net.Conn, error) {
+	config, err := cluster.tlsConfig(addr)
+	if err != nil {
+		log.Error(err, "failed to create TLS config", "addr", addr)
+		return nil, err
+	}
+	if config.ServerName == "" {
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			config.ServerName = host
+		}
+	}
+	rawConn, err := cluster.dialNet(ctx, network, addr)
+	if err != nil {
+		log.Error(err, "transport connect to", "addr", addr)
+		return nil, err
+	}
+	conn := tls.Client(rawConn, config)
+	if err := conn.HandshakeContext(ctx); err != nil {
+		rawConn.Close()
+		log.Error(err, "transport connect to", "addr", addr)
+		return nil, err
+	}
+	return cluster.conns.track(addr, conn), nil
+}
+
+// tlsConfig returns the TLS config of the connections to the endpoint at addr. The server certificate is pinned to
+// the thumbprint of the endpoint if thumbprints are configured, otherwise it is verified with the CA file, not
+// verified if the cluster is insecure, or verified with the system root CAs.
+func (cluster *Cluster) tlsConfig(addr string) (*tls.Config, error) {
+	config := &tls.Config{GetClientCertificate: cluster.getClientCertificate}
+	if cluster.config == nil {
+		config.InsecureSkipVerify = true
+		return config, nil
+	}
+	if thumbprint, tpCount := cluster.getThumbprint(addr); tpCount > 0 {
+		// the chain isn't verified as the certificate is trusted by its thumbprint
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) > 0 && matchThumbprint(rawCerts[0], thumbprint) {
 				return nil
-			},
+			}
+			err := errors.New("server certificate didn't match trusted fingerprint")
+			if len(rawCerts) > 0 {
+				log.Error(err, "verify thumbprint", "address", addr, "server thumbprint", calcFingerprint(rawCerts[0]), "local thumbprint", thumbprint)
+			}
+			return err
 		}
-		conn, err := tls.Dial(network, addr, config)
+		return config, nil
+	}
+	if cluster.config.CAFile != "" {
+		pool, err := loadCAPool(cluster.config.CAFile)
 		if err != nil {
-			log.Error(err, "transport connect to", "addr", addr)
 			return nil, err
+		}
+		config.RootCAs = pool
+		return config, nil
+	}
+	config.InsecureSkipVerify = cluster.config.Insecure
+	return config, nil
+}
 
+// loadCAPool loads the CA certificates in the comma separated CA files.
+func loadCAPool(caFile string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, f := range strings.Split(caFile, ",") {
+		pem, err := ioutil.ReadFile(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificate found in %s", f)
 		}
-		return conn, nil
 	}
+	return pool, nil
+}
 
-	tr := &http.Transport{
-		DialTLS:         dial,
-		IdleConnTimeout: idle * time.Second,
+// matchThumbprint returns whether the SHA-1 or SHA-256 thumbprint, with or without colons, is the one of the
+// DER encoded certificate.
+func matchThumbprint(der []byte, thumbprint string) bool {
+	thumbprint = strings.ReplaceAll(thumbprint, ":", "")
+	var sum []byte
+	switch len(thumbprint) {
+	case sha1.Size * 2:
+		s := sha1.Sum(der)
+		sum = s[:]
+	case sha256.Size * 2:
+		s := sha256.Sum256(der)
+		sum = s[:]
+	default:
+		return false
+	}
+	return strings.EqualFold(hex.EncodeToString(sum), thumbprint)
+}
+
+// getClientCertificate returns the certificate of the Principal Identity if the cluster authenticates with one,
+// otherwise no certificate is presented.
+func (cluster *Cluster) getClientCertificate(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if cluster.config == nil || cluster.config.ClientCertProvider == nil {
+		return &tls.Certificate{}, nil
+	}
+	return cluster.config.ClientCertProvider.GetClientCertificate(info)
+}
+
+// resetConnections closes the connections to all the endpoints, so that the next requests authenticate again.
+func (cluster *Cluster) resetConnections() {
+	for _, ep := range cluster.Endpoints() {
+		closed := cluster.conns.closeHost(ep.Host())
+		log.Info("connections reset", "endpoint", ep.Host(), "closedConnections", closed)
 	}
-	return &Transport{Base: tr}
 }
 
 func calcFingerprint(der []byte) string {
@@ -177,11 +305,18 @@ func (cluster *Cluster) createHTTPClient(tr *Transport, timeout time.Duration) h
 }
 
 func (cluster *Cluster) createNoBalancerClient(timeout, idle time.Duration) http.Client {
-	tlsConfig := tls.Config{InsecureSkipVerify: true}
 	transport := &http.Transport{
-		TLSClientConfig: &tlsConfig,
+		DialTLSContext:  cluster.dialTLS,
 		IdleConnTimeout: idle * time.Second,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := cluster.dialNet(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return cluster.conns.track(addr, conn), nil
+		},
 	}
+	cluster.setConnectionPool(transport)
 	noBClient := http.Client{
 		Transport: transport,
 		Timeout:   timeout * time.Second,
@@ -196,22 +331,189 @@ func (cluster *Cluster) createEndpoints(apiManagers []string, client *http.Clien
 		if err != nil {
 			return nil, err
 		}
+		cluster.setBuckets(ep)
 		eps[i] = ep
 	}
 	return eps, nil
 }
 
+// setBuckets gives the endpoint its own rate limiters per class of the APIs if the API rate limits are configured.
+func (cluster *Cluster) setBuckets(ep *Endpoint) {
+	if cluster.config == nil || cluster.config.APIRateLimits == nil {
+		return
+	}
+	ep.buckets = ratelimiter.NewBuckets(cluster.config.APIRateMode, cluster.config.APIRateLimits)
+}
+
 func (cluster *Cluster) createAuthSessions() {
 	for _, ep := range cluster.endpoints {
 		ep.createAuthSession(cluster.config.ClientCertProvider, cluster.config.TokenProvider, cluster.config.Username, cluster.config.Password, jarCache)
 	}
 }
 
+// UpdateEndpoints reconciles the cluster endpoints with the given NSX manager list without restarting the cluster.
+// Endpoints for added managers are created and probed, endpoints for removed managers are no longer selected for
+// new requests and are released once their in-flight requests are finished.
+func (cluster *Cluster) UpdateEndpoints(apiManagers []string, thumbprint []string) error {
+	cluster.Lock()
+	existing := make(map[string]*Endpoint, len(cluster.endpoints))
+	for _, ep := range cluster.endpoints {
+		existing[ep.Host()] = ep
+	}
+	var r ratelimiter.RateLimiter
+	if len(cluster.endpoints) > 0 {
+		r = cluster.endpoints[0].ratelimiter
+	} else {
+		r = ratelimiter.NewRateLimiter(cluster.config.APIRateMode)
+	}
+
+	eps := make([]*Endpoint, 0, len(apiManagers))
+	added := make([]*Endpoint, 0)
+	managers := make([]string, 0, len(apiManagers))
+	for _, manager := range apiManagers {
+		manager = strings.TrimSpace(manager)
+		host, _, err := parseURL(manager)
+		if err != nil {
+			cluster.Unlock()
+			return err
+		}
+		managers = append(managers, manager)
+		if ep, ok := existing[host]; ok {
+			eps = append(eps, ep)
+			delete(existing, host)
+			continue
+		}
+		ep, err := NewEndpoint(manager, &cluster.client, &cluster.noBalancerClient, r, cluster.config.TokenProvider)
+		if err != nil {
+			cluster.Unlock()
+			return err
+		}
+		cluster.setBuckets(ep)
+		eps = append(eps, ep)
+		added = append(added, ep)
+	}
+	if len(eps) == 0 {
+		cluster.Unlock()
+		err := errors.New("no NSX manager is configured")
+		log.Error(err, "failed to update cluster endpoints")
+		return err
+	}
+	cluster.config.APIManagers = managers
+	cluster.config.Thumbprint = thumbprint
+	cluster.endpoints = eps
+	cluster.transport.setEndpoints(eps)
+	cluster.Unlock()
+
+	// New endpoints stay DOWN and are skipped by the transport until they are set up.
+	for _, ep := range added {
+		log.Info("adding NSX manager endpoint", "endpoint", ep.Host())
+		ep.createAuthSession(cluster.config.ClientCertProvider, cluster.config.TokenProvider, cluster.config.Username, cluster.config.Password, jarCache)
+		ep.setUserPassword(cluster.config.Username, cluster.config.Password)
+		ep.setup()
+		go ep.KeepAlive()
+	}
+	for _, ep := range existing {
+		log.Info("removing NSX manager endpoint", "endpoint", ep.Host())
+		go cluster.drainEndpoint(ep)
+	}
+	return nil
+}
+
+// drainEndpoint stops the heart beat of a removed endpoint and waits for its in-flight requests to finish,
+// then closes the connections to the removed manager, connections to other managers are kept.
+func (cluster *Cluster) drainEndpoint(ep *Endpoint) {
+	close(ep.stop)
+	ep.setStatus(DOWN)
+	deadline := time.Now().Add(time.Duration(cluster.config.HTTPTimeout) * time.Second)
+	for ep.ConnNumber() > 0 && time.Now().Before(deadline) {
+		time.Sleep(drainCheckInterval)
+	}
+	if ep.ConnNumber() > 0 {
+		log.Info("endpoint still has in-flight requests after drain timeout", "endpoint", ep.Host(), "connections", ep.ConnNumber())
+	}
+	closed := cluster.conns.closeHost(ep.Host())
+	log.Info("endpoint drained", "endpoint", ep.Host(), "closedConnections", closed)
+}
+
+// UpdateRateLimits applies the API rate limits to the rate limiters of the endpoints without restarting the cluster.
+func (cluster *Cluster) UpdateRateLimits(limits ratelimiter.Limits) {
+	cluster.Lock()
+	defer cluster.Unlock()
+	cluster.config.APIRateLimits = limits
+	for _, ep := range cluster.endpoints {
+		if ep.buckets != nil {
+			ep.buckets.SetLimits(limits)
+		}
+	}
+}
+
+// managers returns the NSX managers and thumbprints currently used by the cluster.
+func (cluster *Cluster) managers() ([]string, []string) {
+	cluster.Lock()
+	defer cluster.Unlock()
+	managers := make([]string, len(cluster.config.APIManagers))
+	copy(managers, cluster.config.APIManagers)
+	thumbprint := make([]string, len(cluster.config.Thumbprint))
+	copy(thumbprint, cluster.config.Thumbprint)
+	return managers, thumbprint
+}
+
+// Endpoints returns the endpoints currently used by the cluster.
+func (cluster *Cluster) Endpoints() []*Endpoint {
+	cluster.Lock()
+	defer cluster.Unlock()
+	eps := make([]*Endpoint, len(cluster.endpoints))
+	copy(eps, cluster.endpoints)
+	return eps
+}
+
+// EndpointStats is the state of an endpoint of the cluster.
+type EndpointStats struct {
+	Host     string
+	Status   EndpointStatus
+	InUse    bool
+	Requests uint64
+	Breaker  BreakerState
+}
+
+// EndpointStats returns the state of the endpoints, the one serving the latest request is in use.
+func (cluster *Cluster) EndpointStats() []EndpointStats {
+	active := cluster.transport.activeEndpoint()
+	endpoints := cluster.Endpoints()
+	stats := make([]EndpointStats, 0, len(endpoints))
+	for _, ep := range endpoints {
+		stats = append(stats, EndpointStats{
+			Host:     ep.Host(),
+			Status:   ep.Status(),
+			InUse:    ep.Host() == active,
+			Requests: ep.RequestNumber(),
+			Breaker:  ep.BreakerState(),
+		})
+	}
+	return stats
+}
+
+// Failovers returns the number of the requests failed over to another endpoint.
+func (cluster *Cluster) Failovers() uint64 {
+	return cluster.transport.failoverNumber()
+}
+
+// Available returns whether an endpoint is UP and its circuit breaker lets the requests through.
+func (cluster *Cluster) Available() bool {
+	for _, ep := range cluster.Endpoints() {
+		if ep.Status() == UP && ep.breaker.available() {
+			return true
+		}
+	}
+	return false
+}
+
 // Health checks cluster health status.
 func (cluster *Cluster) Health() ClusterHealth {
 	down := 0
 	up := 0
-	for _, ep := range cluster.endpoints {
+	endpoints := cluster.Endpoints()
+	for _, ep := range endpoints {
 		if ep.Status() == UP {
 			up++
 		} else {
@@ -219,35 +521,55 @@ func (cluster *Cluster) Health() ClusterHealth {
 		}
 	}
 
-	if down == len(cluster.endpoints) {
+	if down == len(endpoints) {
 		return RED
 	}
-	if up == len(cluster.endpoints) {
+	if up == len(endpoints) {
 		return GREEN
 	}
 	return ORANGE
 }
 
 func (cluster *Cluster) GetVersion() (*NsxVersion, error) {
+	version := &NsxVersion{}
+	if err := cluster.getFromFirstEndpoint("/api/v1/node/version", version); err != nil {
+		log.Error(err, "failed to get nsx version")
+		return nil, err
+	}
+	return version, nil
+}
+
+// GetLicenses returns the licenses installed on NSX.
+func (cluster *Cluster) GetLicenses() (*NsxLicenses, error) {
+	licenses := &NsxLicenses{}
+	if err := cluster.getFromFirstEndpoint("/api/v1/licenses", licenses); err != nil {
+		log.Error(err, "failed to get nsx licenses")
+		return nil, err
+	}
+	return licenses, nil
+}
+
+// getFromFirstEndpoint sends a GET request of the path to the first endpoint, bypassing the balancer, and decodes the
+// response into result.
+func (cluster *Cluster) getFromFirstEndpoint(path string, result interface{}) error {
 	ep := cluster.endpoints[0]
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s://%s/api/v1/node/version", ep.Scheme(), ep.Host()), nil)
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s://%s%s", ep.Scheme(), ep.Host(), path), nil)
 	if err != nil {
 		log.Error(err, "failed to create http request")
-		return nil, err
+		return err
 	}
 	err = ep.UpdateHttpRequestAuth(req)
 	if err != nil {
 		log.Error(err, "keep alive update auth error")
-		return nil, err
+		return err
 	}
 
 	resp, err := ep.noBalancerClient.Do(req)
 	if err != nil {
-		log.Error(err, "failed to get nsx version")
-		return nil, err
+		return err
 	}
-	err, _ = util.HandleHTTPResponse(resp, nsxVersion, true)
-	return nsxVersion, err
+	err, _ = util.HandleHTTPResponse(resp, result, true)
+	return err
 }
 
 func (nsxVersion *NsxVersion) Validate() error {
@@ -263,40 +585,32 @@ func (nsxVersion *NsxVersion) Validate() error {
 }
 
 func (nsxVersion *NsxVersion) featureSupported(feature string) bool {
-	var minVersion [3]int64
-	validFeature := false
-	switch feature {
-	case FeatureSecurityPolicy:
-		minVersion = nsx320Version
-		validFeature = true
-	case FeatureNSXServiceAccount:
-		minVersion = nsx401Version
-		validFeature = true
-	}
-	if validFeature {
-		// only compared major.minor.patch
-		// NodeVersion should have at least three sections
-		// each section only have digital value
-		buff := strings.Split(nsxVersion.NodeVersion, ".")
-		sections := make([]int64, len(buff))
-		for i, str := range buff {
-			val, err := strconv.ParseInt(str, 10, 64)
-			if err != nil {
-				log.Error(err, "parse version error")
-				return false
-			}
-			sections[i] = val
+	requirement, validFeature := featureRequirements[feature]
+	if !validFeature {
+		return false
+	}
+	minVersion := requirement.minVersion
+	// only compared major.minor.patch
+	// NodeVersion should have at least three sections
+	// each section only have digital value
+	buff := strings.Split(nsxVersion.NodeVersion, ".")
+	sections := make([]int64, len(buff))
+	for i, str := range buff {
+		val, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			log.Error(err, "parse version error")
+			return false
 		}
+		sections[i] = val
+	}
 
-		for i := 0; i < 3; i++ {
-			if sections[i] > minVersion[i] {
-				return true
-			}
-			if sections[i] < minVersion[i] {
-				return false
-			}
+	for i := 0; i < 3; i++ {
+		if sections[i] > minVersion[i] {
+			return true
+		}
+		if sections[i] < minVersion[i] {
+			return false
 		}
-		return true
 	}
-	return false
+	return true
 }
@@ -4,18 +4,26 @@
 package nsx
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/ratelimiter"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
 )
 
 func TestNewCluster(t *testing.T) {
@@ -44,8 +52,9 @@ func TestCluster_getThumbprint(t *testing.T) {
 	cluster := &Cluster{}
 	cluster.config = &Config{}
 	cluster.config.Thumbprint = thumbprint
-	tb := cluster.getThumbprint(host)
+	tb, tpCount := cluster.getThumbprint(host)
 	assert.Equal(t, tb, "123")
+	assert.Equal(t, 1, tpCount)
 
 	// two api server share one thumbprint
 	ep1 := &Endpoint{}
@@ -56,23 +65,24 @@ func TestCluster_getThumbprint(t *testing.T) {
 	cluster.endpoints = []*Endpoint{ep1, ep2}
 	cluster.endpoints[0].provider = &address{host: "127.0.0.1:443"}
 	cluster.endpoints[1].provider = &address{host: "127.0.0.2:443"}
-	tb = cluster.getThumbprint(host)
+	tb, tpCount = cluster.getThumbprint(host)
 	assert.Equal(t, tb, "123")
 
 	// two api server, two thumbprint
 	thumbprint = []string{"123", "234"}
 	cluster.config.Thumbprint = thumbprint
-	tb = cluster.getThumbprint("127.0.0.1:443")
+	tb, tpCount = cluster.getThumbprint("127.0.0.1:443")
 	assert.Equal(t, tb, "123")
-	tb = cluster.getThumbprint("127.0.0.2:443")
+	tb, tpCount = cluster.getThumbprint("127.0.0.2:443")
 	assert.Equal(t, tb, "234")
+	assert.Equal(t, 2, tpCount)
 
 	// two api server no port, two thumbprint
 	cluster.endpoints[0].provider = &address{host: "127.0.0.1"}
 	cluster.endpoints[1].provider = &address{host: "127.0.0.2"}
-	tb = cluster.getThumbprint("127.0.0.1:443")
+	tb, tpCount = cluster.getThumbprint("127.0.0.1:443")
 	assert.Equal(t, tb, "123")
-	tb = cluster.getThumbprint("127.0.0.2:443")
+	tb, tpCount = cluster.getThumbprint("127.0.0.2:443")
 	assert.Equal(t, tb, "234")
 }
 
@@ -160,6 +170,119 @@ func TestCluster_Health(t *testing.T) {
 	assert.Equal(t, health, RED)
 }
 
+func TestCluster_setBuckets(t *testing.T) {
+	cluster := &Cluster{}
+	ep := &Endpoint{}
+	cluster.setBuckets(ep)
+	assert.Nil(t, ep.buckets)
+
+	cluster.config = &Config{APIRateMode: ratelimiter.FIXRATE, APIRateLimits: ratelimiter.Limits{ratelimiter.MPWrite: 5}}
+	cluster.setBuckets(ep)
+	assert.Equal(t, 5, ep.buckets.Rate(ratelimiter.MPWrite))
+
+	cluster.endpoints = []*Endpoint{ep}
+	cluster.UpdateRateLimits(ratelimiter.Limits{ratelimiter.MPWrite: 2})
+	assert.Equal(t, 2, ep.buckets.Rate(ratelimiter.MPWrite))
+	assert.Equal(t, 2, cluster.config.APIRateLimits[ratelimiter.MPWrite])
+}
+
+func TestCluster_EndpointStats(t *testing.T) {
+	cluster := &Cluster{transport: &Transport{}}
+	eps := []*Endpoint{{status: UP}, {status: DOWN}}
+	eps[0].provider = &address{host: "10.0.0.1", scheme: "https"}
+	eps[1].provider = &address{host: "10.0.0.2", scheme: "https"}
+	cluster.endpoints = eps
+	eps[0].increaseRequestNumber()
+	cluster.transport.active.Store("10.0.0.1")
+	cluster.transport.failovers = 2
+
+	eps[1].breaker = newCircuitBreaker("10.0.0.2", 1, time.Minute)
+	eps[1].breaker.failure()
+
+	stats := cluster.EndpointStats()
+	assert.Equal(t, []EndpointStats{
+		{Host: "10.0.0.1", Status: UP, InUse: true, Requests: 1, Breaker: BreakerClosed},
+		{Host: "10.0.0.2", Status: DOWN, Breaker: BreakerOpen},
+	}, stats)
+	assert.Equal(t, uint64(2), cluster.Failovers())
+}
+
+func TestCluster_Available(t *testing.T) {
+	cluster := &Cluster{}
+	eps := []*Endpoint{{status: UP}, {status: DOWN}}
+	eps[0].provider = &address{host: "10.0.0.1", scheme: "https"}
+	eps[1].provider = &address{host: "10.0.0.2", scheme: "https"}
+	eps[0].breaker = newCircuitBreaker("10.0.0.1", 1, time.Minute)
+	cluster.endpoints = eps
+	assert.True(t, cluster.Available())
+
+	eps[0].breaker.failure()
+	assert.False(t, cluster.Available())
+
+	client := &Client{NSXChecker: NSXHealthChecker{cluster: cluster}}
+	assert.Equal(t, util.NSXUnavailableError{Desc: "NSX managers are unavailable"}, client.CheckAvailable())
+	client = &Client{}
+	assert.Nil(t, client.CheckAvailable())
+}
+
+func TestCluster_UpdateEndpoints(t *testing.T) {
+	result := `{
+		"healthy" : true,
+		"components_health" : "POLICY:UP, SEARCH:UP, MANAGER:UP, NODE_MGMT:UP, UI:UP"
+	  }`
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(result))
+	})
+	ts1 := httptest.NewTLSServer(handler)
+	defer ts1.Close()
+	ts2 := httptest.NewTLSServer(handler)
+	defer ts2.Close()
+	a1 := ts1.URL[strings.Index(ts1.URL, "//")+2:]
+	a2 := ts2.URL[strings.Index(ts2.URL, "//")+2:]
+
+	config := NewConfig(a1, "admin", "passw0rd", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, []string{})
+	cluster, err := NewCluster(config)
+	assert.Nil(t, err)
+	oldEp := cluster.endpoints[0]
+
+	// add a manager, the existing endpoint is kept
+	err = cluster.UpdateEndpoints([]string{a1, a2}, []string{})
+	assert.Nil(t, err)
+	eps := cluster.Endpoints()
+	assert.Equal(t, 2, len(eps))
+	assert.Equal(t, oldEp, eps[0])
+	assert.Equal(t, a2, eps[1].Host())
+	assert.Equal(t, []string{a1, a2}, cluster.config.APIManagers)
+	assert.Equal(t, 2, len(cluster.transport.endpoints))
+
+	// remove the first manager, the removed endpoint is stopped
+	err = cluster.UpdateEndpoints([]string{a2}, []string{})
+	assert.Nil(t, err)
+	eps = cluster.Endpoints()
+	assert.Equal(t, 1, len(eps))
+	assert.Equal(t, a2, eps[0].Host())
+	assert.Equal(t, 1, len(cluster.transport.endpoints))
+	assert.Eventually(t, func() bool {
+		select {
+		case <-oldEp.stop:
+			return oldEp.Status() == DOWN
+		default:
+			return false
+		}
+	}, 5*time.Second, 100*time.Millisecond)
+	// only the connections to the removed manager are closed
+	assert.Eventually(t, func() bool {
+		return cluster.conns.count(a1) == 0
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.True(t, cluster.conns.count(a2) > 0)
+
+	// empty manager list is rejected
+	err = cluster.UpdateEndpoints([]string{}, []string{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, len(cluster.Endpoints()))
+}
+
 func TestCluster_enableFeature(t *testing.T) {
 	// Test case for enabling feature SecurityPolicy
 	nsxVersion := &NsxVersion{}
@@ -234,7 +357,8 @@ func TestCluster_getVersion(t *testing.T) {
 		}
 	}))
 	defer ts.Close()
-	thumbprint := []string{"123"}
+	// the NSX manager certificate is pinned to its thumbprint
+	thumbprint := []string{calcFingerprint(ts.Certificate().Raw)}
 	index := strings.Index(ts.URL, "//")
 	a := ts.URL[index+2:]
 	config := NewConfig(a, "admin", "passw0rd", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, thumbprint)
@@ -243,3 +367,71 @@ func TestCluster_getVersion(t *testing.T) {
 	assert.True(t, err == nil)
 	assert.Equal(t, nsxVersion.NodeVersion, "3.1.3.3.0.18844962")
 }
+
+func TestCluster_setConnectionPool(t *testing.T) {
+	config := NewConfig("127.0.0.1", "admin", "passw0rd", "", 20, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, nil)
+	config.MaxIdleConns = 200
+	config.MaxIdleConnsPerHost = 20
+	config.TCPKeepAlive = 30
+	cluster := &Cluster{config: config}
+
+	tr := cluster.createTransport(10).Base.(*http.Transport)
+	assert.Equal(t, 200, tr.MaxIdleConns)
+	assert.Equal(t, 20, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, 20, tr.MaxConnsPerHost)
+	assert.Equal(t, 10*time.Second, tr.IdleConnTimeout)
+	noBTr := cluster.createNoBalancerClient(20, 10).Transport.(*http.Transport)
+	assert.Equal(t, 200, noBTr.MaxIdleConns)
+	assert.Equal(t, 20, noBTr.MaxConnsPerHost)
+	assert.Equal(t, 30*time.Second, cluster.dialer().KeepAlive)
+
+	cluster = &Cluster{}
+	tr = cluster.createTransport(10).Base.(*http.Transport)
+	assert.Equal(t, 0, tr.MaxConnsPerHost)
+	assert.Equal(t, time.Duration(0), cluster.dialer().KeepAlive)
+}
+
+func TestCluster_dialTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	addr := ts.Listener.Addr().String()
+	der := ts.Certificate().Raw
+	sha256Sum := sha256.Sum256(der)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.Nil(t, ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr bool
+	}{
+		{"sha1 thumbprint", &Config{Thumbprint: []string{calcFingerprint(der)}}, false},
+		{"sha256 thumbprint", &Config{Thumbprint: []string{hex.EncodeToString(sha256Sum[:])}}, false},
+		{"thumbprint mismatch", &Config{Thumbprint: []string{"81:49:DD:B7:E8:79:55:5D:9E:75:A9:FA:A6:7D:CB:EA:A4:CA:12:C6"}, Insecure: true}, true},
+		{"ca file", &Config{CAFile: caFile}, false},
+		{"missing ca file", &Config{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, true},
+		{"insecure", &Config{Insecure: true}, false},
+		{"system root CAs", &Config{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &Cluster{config: tt.config, conns: newConnTracker()}
+			conn, err := cluster.dialTLS(context.TODO(), "tcp", addr)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			if conn != nil {
+				conn.Close()
+			}
+		})
+	}
+}
+
+func Test_matchThumbprint(t *testing.T) {
+	der := []byte("It is byte.")
+	sum := sha256.Sum256(der)
+	assert.True(t, matchThumbprint(der, "5C:1D:AE:31:3A:EA:74:74:FE:69:BA:9F:0B:1D:86:5E:39:97:43:4F"))
+	assert.True(t, matchThumbprint(der, "5c1dae313aea7474fe69ba9f0b1d865e3997434f"))
+	assert.True(t, matchThumbprint(der, hex.EncodeToString(sum[:])))
+	assert.False(t, matchThumbprint(der, "123"))
+}
@@ -4,6 +4,7 @@
 package nsx
 
 import (
+	"net/url"
 	"strings"
 
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth"
@@ -20,25 +21,38 @@ type Config struct {
 	Username string
 	// Password for the NSX manager.
 	Password string
-	// Specify a CA bundle file to use in verifying the NSX Manager server certificate. This option is ignored if
-	// "Insecure" is set to True. If "Insecure" is set to False and "CAFile" is unset, the "Thumbprint" will be used.
-	// If "Thumbprint" is unset, the system root CAs will be used to verify the server certificate.
+	// Specify comma separated CA bundle files to use in verifying the NSX Manager server certificate. This option is
+	// ignored if "Thumbprint" is set. If "CAFile" is unset, the server certificate isn't verified if "Insecure" is
+	// set to True, otherwise the system root CAs will be used to verify it.
 	CAFile string
-	// Specify a Thumbprint string to use in verifying the NSX Manager server certificate. This option is ignored
-	// if "Insecure" is set to True or "CAFile" is defined.
+	// Specify the SHA-1 or SHA-256 thumbprints the NSX Manager server certificates are pinned to, one shared by all
+	// the managers or one per manager.
 	Thumbprint []string
-	// Maximum concurrent connections to each NSX manager.
+	// Maximum concurrent connections to each NSX manager, 0 means no limit.
 	ConcurrentConnections int
+	// Maximum idle connections to all the NSX managers, 0 means no limit.
+	MaxIdleConns int
+	// Maximum idle connections to each NSX manager, http.DefaultMaxIdleConnsPerHost is used if it is 0.
+	MaxIdleConnsPerHost int
+	// The period in seconds between the TCP keep-alive probes of the connections, 15s is used if it is 0.
+	TCPKeepAlive int
+	// The HTTP, HTTPS or SOCKS5 proxy the NSX managers are reached through, with the proxy credentials if any. The
+	// managers are reached directly if it is nil.
+	ProxyURL *url.URL
+	// The hosts, domains and CIDRs of the NSX managers reached directly instead of through ProxyURL.
+	NoProxy []string
 	// If True, the client will retry requests failed on "Too many requests" error.
 	Retries int
 	// The time in seconds before aborting a HTTP connection to a NSX manager.
 	HTTPTimeout int
+	// The time in seconds before aborting the read and write requests to the NSX managers, no timeout other than
+	// HTTPTimeout is applied if they are 0.
+	ReadTimeout  int
+	WriteTimeout int
 	// The amount of time in seconds to wait before ensuring connectivity to the NSX manager if no manager connection
 	// has been used.
 	ConnIdleTimeout int
-	// If true, the NSX Manager server certificate is not verified. If false the CA bundle specified via "CAFile"
-	// will be used or if unset the "Thumbprint" will be used. If "Thumbprint" is unset, the default system root CAs
-	// will be used.
+	// If true, the NSX Manager server certificate is not verified unless "Thumbprint" or "CAFile" is set.
 	Insecure bool
 	// If True, a default header of X-Allow-Overwrite:true will be added to all the requests, to allow admin user to
 	// update/delete all entries.
@@ -50,9 +64,14 @@ type Config struct {
 	// sent, and will be decreased by half after 429/503 error for each period. The rate has hard max limit of
 	// min(100/s, param api_rate_limit_per_endpoint).
 	APIRateMode ratelimiter.Type
+	// Max API rates per second of the read and write requests of the Policy and MP APIs, each endpoint limits them
+	// with its own buckets. If not set, one rate limiter is shared by all the requests.
+	APIRateLimits ratelimiter.Limits
 	// None, or instance of implemented AbstractJWTProvider which will return the JSON Web Token used in the requests
 	// in NSX for authorization.
 	TokenProvider auth.TokenProvider
+	// CredentialProvider provides the Username and Password again when they are rotated, nil if they are not rotated.
+	CredentialProvider auth.CredentialProvider
 	// None, or ClientCertProvider object. If specified, client cert will be used instead of basic authentication.
 	ClientCertProvider auth.ClientCertProvider
 }
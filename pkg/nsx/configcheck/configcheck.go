@@ -0,0 +1,183 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+// Package configcheck checks the NSX Operator config, and that NSX is reachable with the credentials configured and
+// holds the NSX objects the config refers to, before NSX Operator is started with it.
+package configcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// The checks the findings are reported by.
+const (
+	CheckConfig       = "config"
+	CheckConnectivity = "connectivity"
+	CheckCredentials  = "credentials"
+	CheckReference    = "reference"
+)
+
+// The severities of the findings, the config is invalid if any finding is an error.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is a problem found by a check.
+type Finding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	// NSXManager is the additional NSX manager of the config the finding is about, empty for the nsx_v3 section.
+	NSXManager string `json:"nsxManager,omitempty"`
+	// Subject is what the finding is about, e.g. an NSX manager, or the config option or CR referring to an NSX object.
+	Subject string `json:"subject,omitempty"`
+	// Path is the NSX Policy path of the NSX object the finding is about.
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report is the findings of all the checks.
+type Report struct {
+	Valid    bool      `json:"valid"`
+	Findings []Finding `json:"findings"`
+}
+
+// NSXChecker checks NSX, it is implemented by nsx.Client.
+type NSXChecker interface {
+	// CheckNSXManagers returns the error of each NSX manager which can't be reached or rejects the credentials.
+	CheckNSXManagers() map[string]error
+	// PolicyObjectExists returns whether the NSX Policy object at the path exists.
+	PolicyObjectExists(path string) (bool, error)
+}
+
+// reference is an NSX object referred to by the config or a CR.
+type reference struct {
+	subject string
+	path    string
+}
+
+func (r *Report) add(finding Finding) {
+	r.Findings = append(r.Findings, finding)
+	if finding.Severity == SeverityError {
+		r.Valid = false
+	}
+}
+
+// Merge adds the findings of the other report about the additional NSX manager.
+func (r *Report) Merge(nsxManager string, other Report) {
+	for _, finding := range other.Findings {
+		finding.NSXManager = nsxManager
+		r.add(finding)
+	}
+}
+
+// ConfigError returns the report of the config which can't be loaded.
+func ConfigError(err error) Report {
+	return Report{Findings: []Finding{{Check: CheckConfig, Severity: SeverityError, Message: err.Error()}}}
+}
+
+// Check checks that NSX is reachable with the credentials configured and holds the NSX objects referred to by the
+// config. The NSX objects referred to by the VPCNetworkConfigurations are checked too if reader is not nil.
+func Check(ctx context.Context, cf *config.NSXOperatorConfig, checker NSXChecker, reader client.Reader) Report {
+	report := Report{Valid: true, Findings: []Finding{}}
+	managerErrs := checker.CheckNSXManagers()
+	managers := make([]string, 0, len(managerErrs))
+	for manager := range managerErrs {
+		managers = append(managers, manager)
+	}
+	sort.Strings(managers)
+	for _, manager := range managers {
+		err := managerErrs[manager]
+		check := CheckConnectivity
+		if errors.As(nsxutil.TransNSXError(err), &nsxutil.UnauthorizedError{}) {
+			check = CheckCredentials
+		}
+		report.add(Finding{Check: check, Severity: SeverityError, Subject: manager, Message: err.Error()})
+	}
+	// the NSX objects can't be read if none of the NSX managers can be reached
+	if len(managerErrs) > 0 && len(managerErrs) == len(cf.NsxApiManagers) {
+		return report
+	}
+
+	references := configReferences(cf)
+	if reader != nil && cf.EnableVPCNetwork {
+		ncReferences, err := vpcNetworkConfigurationReferences(ctx, cf, reader)
+		if err != nil {
+			report.add(Finding{Check: CheckReference, Severity: SeverityWarning, Subject: "VPCNetworkConfiguration",
+				Message: fmt.Sprintf("failed to list the VPCNetworkConfigurations: %v", err)})
+		}
+		references = append(references, ncReferences...)
+	}
+	for _, ref := range references {
+		exists, err := checker.PolicyObjectExists(ref.path)
+		if err != nil {
+			report.add(Finding{Check: CheckReference, Severity: SeverityWarning, Subject: ref.subject, Path: ref.path,
+				Message: fmt.Sprintf("failed to read the NSX object: %v", err)})
+		} else if !exists {
+			report.add(Finding{Check: CheckReference, Severity: SeverityError, Subject: ref.subject, Path: ref.path,
+				Message: "the NSX object doesn't exist"})
+		}
+	}
+	return report
+}
+
+// configReferences returns the NSX objects referred to by the config options.
+func configReferences(cf *config.NSXOperatorConfig) []reference {
+	var references []reference
+	if len(cf.EnforcementPoints) > 0 {
+		for _, ep := range cf.EnforcementPoints {
+			references = append(references, reference{"enforcement_points", common.EnforcementPointPath(cf, ep)})
+		}
+	} else if cf.EnforcementPoint != "" {
+		references = append(references, reference{"enforcement_point", common.EnforcementPointPath(cf, cf.EnforcementPoint)})
+	}
+	if cf.InProject() {
+		references = append(references, reference{"nsx_project", common.BuildProjectPath(cf.GetNsxOrg(), cf.NsxProject)})
+	}
+	if cf.Tier0Gateway != "" {
+		references = append(references, reference{"tier0_gateway", "/infra/tier-0s/" + cf.Tier0Gateway})
+	}
+	if cf.Tier1Gateway != "" {
+		references = append(references, reference{"tier1_gateway", "/infra/tier-1s/" + cf.Tier1Gateway})
+	}
+	for _, pool := range cf.LBVIPPools {
+		references = append(references, reference{"lb_vip_pools", pool})
+	}
+	return references
+}
+
+// vpcNetworkConfigurationReferences returns the gateways, edge clusters, Projects and external IP blocks referred to
+// by the VPCNetworkConfigurations.
+func vpcNetworkConfigurationReferences(ctx context.Context, cf *config.NSXOperatorConfig, reader client.Reader) ([]reference, error) {
+	ncList := &v1alpha1.VPCNetworkConfigurationList{}
+	if err := reader.List(ctx, ncList); err != nil {
+		return nil, err
+	}
+	var references []reference
+	for _, nc := range ncList.Items {
+		subject := "VPCNetworkConfiguration/" + nc.Name
+		if nc.Spec.DefaultGatewayPath != "" {
+			references = append(references, reference{subject, nc.Spec.DefaultGatewayPath})
+		}
+		if nc.Spec.EdgeClusterPath != "" {
+			references = append(references, reference{subject, nc.Spec.EdgeClusterPath})
+		}
+		if nc.Spec.NSXTProject != "" {
+			references = append(references, reference{subject, common.BuildProjectPath(cf.GetNsxOrg(), nc.Spec.NSXTProject)})
+		}
+		for _, block := range nc.Spec.ExternalIPv4Blocks {
+			references = append(references, reference{subject, block})
+		}
+	}
+	return references, nil
+}
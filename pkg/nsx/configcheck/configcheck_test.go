@@ -0,0 +1,148 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package configcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+type fakeChecker struct {
+	managerErrs map[string]error
+	objects     map[string]bool
+	readErr     error
+	read        []string
+}
+
+func (c *fakeChecker) CheckNSXManagers() map[string]error {
+	return c.managerErrs
+}
+
+func (c *fakeChecker) PolicyObjectExists(path string) (bool, error) {
+	c.read = append(c.read, path)
+	if c.readErr != nil {
+		return false, c.readErr
+	}
+	return c.objects[path], nil
+}
+
+func newConfig() *config.NSXOperatorConfig {
+	return &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{EnableVPCNetwork: true, LBVIPPools: []string{"/infra/ip-pools/vip"}},
+		NsxConfig: &config.NsxConfig{
+			NsxApiManagers:   []string{"10.0.0.1", "10.0.0.2"},
+			EnforcementPoint: "default",
+			NsxProject:       "p1",
+			Tier0Gateway:     "t0",
+			Tier1Gateway:     "t1",
+		},
+	}
+}
+
+func TestConfigError(t *testing.T) {
+	report := ConfigError(errors.New("invalid field NsxApiManagers"))
+	assert.False(t, report.Valid)
+	assert.Equal(t, []Finding{{Check: CheckConfig, Severity: SeverityError, Message: "invalid field NsxApiManagers"}}, report.Findings)
+
+	merged := Report{Valid: true, Findings: []Finding{}}
+	merged.Merge("", Report{Valid: true, Findings: []Finding{{Check: CheckReference, Severity: SeverityWarning}}})
+	assert.True(t, merged.Valid)
+	merged.Merge("site-b", report)
+	assert.False(t, merged.Valid)
+	assert.Equal(t, 2, len(merged.Findings))
+	assert.Equal(t, "site-b", merged.Findings[1].NSXManager)
+}
+
+func TestCheck(t *testing.T) {
+	scheme := runtime.NewScheme()
+	v1alpha1.AddToScheme(scheme)
+	nc := &v1alpha1.VPCNetworkConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.VPCNetworkConfigurationSpec{
+			DefaultGatewayPath: "/infra/tier-0s/t0",
+			EdgeClusterPath:    "/infra/sites/default/enforcement-points/default/edge-clusters/ec1",
+			ExternalIPv4Blocks: []string{"/infra/ip-blocks/external"},
+		},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(nc).Build()
+	checker := &fakeChecker{objects: map[string]bool{
+		"/orgs/default/projects/p1": true,
+		"/infra/tier-0s/t0":         true,
+		"/infra/tier-1s/t1":         true,
+		"/infra/ip-pools/vip":       true,
+		"/orgs/default/projects/p1/infra/sites/default/enforcement-points/default": true,
+		"/infra/sites/default/enforcement-points/default/edge-clusters/ec1":        true,
+	}}
+
+	report := Check(context.TODO(), newConfig(), checker, reader)
+	assert.False(t, report.Valid)
+	assert.Equal(t, []Finding{{Check: CheckReference, Severity: SeverityError, Subject: "VPCNetworkConfiguration/default",
+		Path: "/infra/ip-blocks/external", Message: "the NSX object doesn't exist"}}, report.Findings)
+	assert.Equal(t, 8, len(checker.read))
+
+	checker.objects["/infra/ip-blocks/external"] = true
+	report = Check(context.TODO(), newConfig(), checker, reader)
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Findings)
+
+	// the VPCNetworkConfigurations are not checked without a reader
+	checker.read = nil
+	report = Check(context.TODO(), newConfig(), checker, nil)
+	assert.True(t, report.Valid)
+	assert.Equal(t, 5, len(checker.read))
+
+	// the objects which can't be read are warned about
+	checker.readErr = errors.New("mock error")
+	report = Check(context.TODO(), newConfig(), checker, nil)
+	assert.True(t, report.Valid)
+	assert.Equal(t, 5, len(report.Findings))
+	assert.Equal(t, SeverityWarning, report.Findings[0].Severity)
+}
+
+func TestCheck_NSXManagers(t *testing.T) {
+	checker := &fakeChecker{managerErrs: map[string]error{
+		"10.0.0.2": nsxutil.CreateInvalidCredentials("10.0.0.2 responded 403"),
+		"10.0.0.1": errors.New("dial tcp 10.0.0.1:443: connect: connection refused"),
+	}}
+	report := Check(context.TODO(), newConfig(), checker, nil)
+	assert.False(t, report.Valid)
+	assert.Equal(t, []Finding{
+		{Check: CheckConnectivity, Severity: SeverityError, Subject: "10.0.0.1", Message: "dial tcp 10.0.0.1:443: connect: connection refused"},
+		{Check: CheckCredentials, Severity: SeverityError, Subject: "10.0.0.2", Message: "Failed to authenticate with NSX: 10.0.0.2 responded 403"},
+	}, report.Findings)
+	// the NSX objects are not read if no NSX manager can be reached
+	assert.Empty(t, checker.read)
+
+	delete(checker.managerErrs, "10.0.0.1")
+	report = Check(context.TODO(), newConfig(), checker, nil)
+	assert.False(t, report.Valid)
+	assert.Equal(t, 5, len(checker.read))
+}
+
+func TestCheck_EnforcementPoints(t *testing.T) {
+	cf := newConfig()
+	cf.EnforcementPoints = []string{"default", "site-b"}
+	checker := &fakeChecker{objects: map[string]bool{
+		"/orgs/default/projects/p1": true,
+		"/infra/tier-0s/t0":         true,
+		"/infra/tier-1s/t1":         true,
+		"/infra/ip-pools/vip":       true,
+		"/orgs/default/projects/p1/infra/sites/default/enforcement-points/default": true,
+	}}
+	report := Check(context.TODO(), cf, checker, nil)
+	assert.False(t, report.Valid)
+	assert.Equal(t, []Finding{{Check: CheckReference, Severity: SeverityError, Subject: "enforcement_points",
+		Path: "/orgs/default/projects/p1/infra/sites/default/enforcement-points/site-b", Message: "the NSX object doesn't exist"}}, report.Findings)
+	assert.Equal(t, 6, len(checker.read))
+}
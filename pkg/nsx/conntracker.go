@@ -0,0 +1,96 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"net"
+	"sync"
+)
+
+// connTracker records the connections opened to each NSX manager, so that the connections
+// to a removed manager can be closed without touching the ones to other managers.
+type connTracker struct {
+	conns map[string]map[*trackedConn]struct{}
+	sync.Mutex
+}
+
+type trackedConn struct {
+	net.Conn
+	host    string
+	tracker *connTracker
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{conns: make(map[string]map[*trackedConn]struct{})}
+}
+
+func (c *trackedConn) Close() error {
+	c.tracker.remove(c)
+	return c.Conn.Close()
+}
+
+// track wraps conn so that it is recorded until it is closed. It is a no-op on a nil tracker.
+func (t *connTracker) track(addr string, conn net.Conn) net.Conn {
+	if t == nil {
+		return conn
+	}
+	tc := &trackedConn{Conn: conn, host: hostPort(addr), tracker: t}
+	t.Lock()
+	defer t.Unlock()
+	if t.conns[tc.host] == nil {
+		t.conns[tc.host] = make(map[*trackedConn]struct{})
+	}
+	t.conns[tc.host][tc] = struct{}{}
+	return tc
+}
+
+func (t *connTracker) remove(c *trackedConn) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.conns[c.host], c)
+	if len(t.conns[c.host]) == 0 {
+		delete(t.conns, c.host)
+	}
+}
+
+// closeHost closes all the connections to host and returns the number of closed connections.
+func (t *connTracker) closeHost(host string) int {
+	if t == nil {
+		return 0
+	}
+	host = hostPort(host)
+	t.Lock()
+	conns := make([]*trackedConn, 0, len(t.conns[host]))
+	for c := range t.conns[host] {
+		conns = append(conns, c)
+	}
+	t.Unlock()
+	for _, c := range conns {
+		c.Close()
+	}
+	return len(conns)
+}
+
+// count returns the number of open connections to host.
+func (t *connTracker) count(host string) int {
+	t.Lock()
+	defer t.Unlock()
+	return len(t.conns[hostPort(host)])
+}
+
+// hostname strips the port from addr if there is one.
+func hostname(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// hostPort adds the default https port to addr if it has no port.
+func hostPort(addr string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	return net.JoinHostPort(addr, "443")
+}
@@ -0,0 +1,45 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+// RotateCredentials checks if the username and password NSX Operator authenticates with are rotated. The sessions
+// with NSX are re-established with the rotated credentials. It does nothing if the credentials aren't loaded from a
+// provider.
+func (client *Client) RotateCredentials() error {
+	cluster := client.NSXChecker.cluster
+	if cluster == nil || cluster.config.CredentialProvider == nil {
+		return nil
+	}
+	username, password, err := cluster.config.CredentialProvider.Credentials()
+	if err != nil {
+		return err
+	}
+	if !cluster.updateCredentials(username, password) {
+		return nil
+	}
+	log.Info("NSX credentials are rotated, re-establishing the sessions", "username", username)
+	for _, ep := range cluster.Endpoints() {
+		if err := ep.createAuthSession(cluster.config.ClientCertProvider, cluster.config.TokenProvider, username, password, jarCache); err != nil {
+			log.Error(err, "failed to re-establish the session", "endpoint", ep.Host())
+		}
+	}
+	// the open connections are authenticated with the previous credentials
+	cluster.resetConnections()
+	return nil
+}
+
+// updateCredentials sets the username and password the endpoints authenticate with, it returns whether they are
+// changed.
+func (cluster *Cluster) updateCredentials(username, password string) bool {
+	cluster.Lock()
+	defer cluster.Unlock()
+	if cluster.config.Username == username && cluster.config.Password == password {
+		return false
+	}
+	cluster.config.Username, cluster.config.Password = username, password
+	for _, ep := range cluster.endpoints {
+		ep.setUserPassword(username, password)
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth/vault"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/ratelimiter"
+)
+
+func TestClient_RotateCredentials(t *testing.T) {
+	var lock sync.Mutex
+	var sessions []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "reverse-proxy/node/health"):
+			w.Write([]byte(`{"healthy": true, "components_health": "MANAGER:UP, SEARCH:UP, UI:UP, NODE_MGMT:UP"}`))
+		case strings.HasSuffix(r.URL.Path, "/api/session/create"):
+			r.ParseForm()
+			lock.Lock()
+			sessions = append(sessions, r.PostForm.Get("j_username")+":"+r.PostForm.Get("j_password"))
+			lock.Unlock()
+			w.Header().Set("X-Xsrf-Token", "token")
+		}
+	}))
+	defer ts.Close()
+	thumbprint := []string{calcFingerprint(ts.Certificate().Raw)}
+	config := NewConfig(strings.TrimPrefix(ts.URL, "https://"), "admin", "old", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, thumbprint)
+	cluster, err := NewCluster(config)
+	assert.Nil(t, err)
+	client := &Client{NSXChecker: NSXHealthChecker{cluster: cluster}}
+	assert.Equal(t, []string{"admin:old"}, sessions)
+
+	// nothing is rotated without a credential provider
+	assert.Nil(t, client.RotateCredentials())
+
+	current := [2]string{"admin", "old"}
+	var sourceErr error
+	config.CredentialProvider = auth.CredentialSource(func() (string, string, error) { return current[0], current[1], sourceErr })
+	assert.Nil(t, client.RotateCredentials())
+	assert.Equal(t, 1, len(sessions))
+
+	current[1] = "new"
+	assert.Nil(t, client.RotateCredentials())
+	assert.Equal(t, []string{"admin:old", "admin:new"}, sessions)
+	ep := cluster.Endpoints()[0]
+	assert.Equal(t, "new", ep.password)
+	assert.Equal(t, "new", cluster.config.Password)
+
+	sourceErr = errors.New("mock error")
+	assert.EqualError(t, client.RotateCredentials(), "mock error")
+}
+
+func TestNewCredentialProvider(t *testing.T) {
+	cf := &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}}
+	provider, err := NewCredentialProvider(cf, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, provider)
+
+	cf.NsxApiCredentialSecret = "vmware-system-nsx/nsx-credentials"
+	provider, err = NewCredentialProvider(cf, nil)
+	assert.Nil(t, err)
+	assert.IsType(t, auth.CredentialSource(nil), provider)
+
+	cf.NsxApiCredentialSecret = ""
+	cf.VaultAddr, cf.VaultRole, cf.VaultSecretPath = "https://vault:8200", "nsx-operator", "secret/data/nsx"
+	provider, err = NewCredentialProvider(cf, nil)
+	assert.Nil(t, err)
+	assert.IsType(t, &vault.CredentialProvider{}, provider)
+}
@@ -38,10 +38,13 @@ type Endpoint struct {
 	client           *http.Client
 	noBalancerClient *http.Client
 	ratelimiter      ratelimiter.RateLimiter
+	buckets          *ratelimiter.Buckets
+	breaker          *circuitBreaker
 	lastAliveTime    time.Time
 	xXSRFToken       string
 	keepaliveperiod  int
 	connnumber       int32
+	requestnumber    uint64
 	stop             chan bool
 	// Used when JWT token is not avaiable, default value is 120s
 	lockWait      time.Duration
@@ -71,6 +74,9 @@ func (addr *address) Host() string {
 
 const (
 	healthURL = "%s://%s/api/v1/reverse-proxy/node/health"
+	// downKeepAlivePeriod is the period in seconds checking if a DOWN endpoint is alive again, it is shorter than
+	// the keep alive period of the UP endpoints so that the requests fail back to a recovered endpoint quickly.
+	downKeepAlivePeriod = 5
 )
 
 // NewEndpoint creates an endpoint.
@@ -84,6 +90,7 @@ func NewEndpoint(url string, client *http.Client, noBClient *http.Client, r rate
 	addr.scheme = scheme
 	ep := Endpoint{client: client, noBalancerClient: noBClient, keepaliveperiod: ratelimiter.KeepAlivePeriod, ratelimiter: r, status: DOWN, tokenProvider: tokenProvider}
 	ep.provider = addr
+	ep.breaker = newCircuitBreaker(host, breakerFailureThreshold, breakerOpenPeriod)
 	ep.stop = make(chan bool)
 	ep.lockWait = 120 * time.Second
 	return &ep, nil
@@ -121,7 +128,9 @@ func (ep *Endpoint) keepAlive() error {
 
 	resp, err := ep.noBalancerClient.Do(req)
 	if err != nil {
+		// the manager is unreachable, stop selecting it before a request stalls on it
 		log.Error(err, "failed to validate API cluster", "endpoint", ep.Host())
+		ep.setStatus(DOWN)
 		return err
 	}
 	var a epHealthy
@@ -148,15 +157,20 @@ func (ep *Endpoint) keepAlive() error {
 	return err
 }
 
+// nextInterval returns the seconds before the next health check, the check of an UP endpoint is delayed by the
+// requests succeeded on it since they prove it is alive.
 func (ep *Endpoint) nextInterval() int {
+	if ep.Status() == DOWN && ep.keepaliveperiod > downKeepAlivePeriod {
+		return downKeepAlivePeriod
+	}
 	t := time.Now()
 	ep.Lock()
-	i := t.Sub(ep.lastAliveTime)
+	i := int(t.Sub(ep.lastAliveTime) / time.Second)
 	ep.Unlock()
-	if int(i) > ep.keepaliveperiod {
+	if i > ep.keepaliveperiod {
 		return ep.keepaliveperiod
 	}
-	return ep.keepaliveperiod - int(i)
+	return ep.keepaliveperiod - i
 }
 
 // KeepAlive maintains a heart beat for each endpoint.
@@ -212,11 +226,21 @@ func (ep *Endpoint) Status() EndpointStatus {
 	return ep.status
 }
 
-func (ep *Endpoint) wait() {
+// wait blocks the request until it is allowed by the bucket of its class, or by the shared rate limiter if the
+// endpoint has no buckets.
+func (ep *Endpoint) wait(r *http.Request) {
+	if ep.buckets != nil {
+		ep.buckets.Wait(ratelimiter.ClassOf(r))
+		return
+	}
 	ep.ratelimiter.Wait()
 }
 
-func (ep *Endpoint) adjustRate(wait time.Duration, status int) {
+func (ep *Endpoint) adjustRate(r *http.Request, wait time.Duration, status int) {
+	if ep.buckets != nil {
+		ep.buckets.AdjustRate(ratelimiter.ClassOf(r), wait, status)
+		return
+	}
 	ep.ratelimiter.AdjustRate(wait, status)
 }
 
@@ -241,6 +265,20 @@ func (ep *Endpoint) decreaseConnNumber() {
 	atomic.AddInt32(&ep.connnumber, -1)
 }
 
+func (ep *Endpoint) increaseRequestNumber() {
+	atomic.AddUint64(&ep.requestnumber, 1)
+}
+
+// RequestNumber gets the number of the requests sent to the nsx-t manager.
+func (ep *Endpoint) RequestNumber() uint64 {
+	return atomic.LoadUint64(&ep.requestnumber)
+}
+
+// BreakerState gets the state of the circuit breaker of the endpoint.
+func (ep *Endpoint) BreakerState() BreakerState {
+	return ep.breaker.State()
+}
+
 // ConnNumber get the connection number of nsx-t.
 func (ep *Endpoint) ConnNumber() int {
 	return int(atomic.LoadInt32(&ep.connnumber))
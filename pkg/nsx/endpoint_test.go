@@ -4,6 +4,7 @@
 package nsx
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -77,6 +78,10 @@ type ncpCertProvider struct{}
 func (cert *ncpCertProvider) FileName() string {
 	return "certProvider"
 }
+
+func (cert *ncpCertProvider) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return &tls.Certificate{}, nil
+}
 func createNcpPovider() auth.ClientCertProvider {
 	return &ncpCertProvider{}
 }
@@ -218,3 +223,38 @@ func TestKeepAlive(t *testing.T) {
 	ep.KeepAlive()
 	assert.Equal(ep.Status(), DOWN)
 }
+
+func TestKeepAliveUnreachable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	host := ts.URL[len("http://"):]
+	ts.Close()
+	cluster := &Cluster{}
+	tr := cluster.createTransport(10)
+	client := cluster.createHTTPClient(tr, 30)
+	noBClient := cluster.createNoBalancerClient(90, 90)
+	rl := ratelimiter.NewFixRateLimiter(10)
+	ep, err := NewEndpoint(host, &client, &noBClient, rl, nil)
+	assert.Nil(t, err)
+	mockObj := new(mockObject)
+	ep.provider = mockObj
+	ep.client.Jar = NewJar()
+	ep.setXSRFToken("testtoken")
+	mockObj.On("Host").Return(host)
+	mockObj.On("Scheme").Return("http")
+	ep.setStatus(UP)
+
+	assert.NotNil(t, ep.keepAlive())
+	assert.Equal(t, DOWN, ep.Status())
+}
+
+func TestEndpoint_nextInterval(t *testing.T) {
+	ep := &Endpoint{keepaliveperiod: 33, status: UP, provider: &address{host: "10.0.0.1", scheme: "https"}}
+	ep.setAliveTime(time.Now().Add(-10 * time.Second))
+	assert.Equal(t, 23, ep.nextInterval())
+	ep.setAliveTime(time.Now().Add(-time.Minute))
+	assert.Equal(t, 33, ep.nextInterval())
+
+	// a DOWN endpoint is checked more often to fail back quickly
+	ep.setStatus(DOWN)
+	assert.Equal(t, downKeepAlivePeriod, ep.nextInterval())
+}
@@ -0,0 +1,85 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"fmt"
+
+	mpmodel "github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth"
+)
+
+// RotateClientCertificate checks if the certificate of the Principal Identity NSX Operator authenticates with is
+// rotated. The rotated certificate is registered to the Principal Identity with the certificate NSX still trusts,
+// and then presented in the next requests. It does nothing if the client doesn't authenticate with a certificate.
+func (client *Client) RotateClientCertificate() error {
+	cluster := client.NSXChecker.cluster
+	if cluster == nil {
+		return nil
+	}
+	provider, ok := cluster.config.ClientCertProvider.(*auth.PICertProvider)
+	if !ok {
+		return nil
+	}
+	cert, key, rotated, err := provider.Rotated()
+	if err != nil || !rotated {
+		return err
+	}
+	name := client.NsxConfig.PrincipalIdentity
+	if name == "" {
+		log.Info("client certificate is rotated, no Principal Identity is configured to register it to", "certificate", provider.FileName())
+	} else {
+		log.Info("client certificate is rotated, registering it to the Principal Identity", "certificate", provider.FileName(), "principalIdentity", name)
+		if err := client.updatePrincipalIdentityCertificate(name, string(cert)); err != nil {
+			return err
+		}
+	}
+	if err := provider.Activate(cert, key); err != nil {
+		return err
+	}
+	// the open connections are authenticated with the previous certificate
+	cluster.resetConnections()
+	return nil
+}
+
+// updatePrincipalIdentityCertificate imports the certificate and registers it to the Principal Identity, the
+// previous certificate of the Principal Identity is deleted.
+func (client *Client) updatePrincipalIdentityCertificate(name string, cert string) error {
+	pis, err := client.PrincipalIdentitiesClient.List()
+	if err != nil {
+		return err
+	}
+	var pi *mpmodel.PrincipalIdentity
+	for i := range pis.Results {
+		if pis.Results[i].Name != nil && *pis.Results[i].Name == name {
+			pi = &pis.Results[i]
+			break
+		}
+	}
+	if pi == nil {
+		return fmt.Errorf("Principal Identity %s not found", name)
+	}
+	certs, err := client.CertificatesClient.Importcertificate(mpmodel.TrustObjectData{PemEncoded: &cert, DisplayName: &name})
+	if err != nil {
+		return err
+	}
+	if len(certs.Results) == 0 || certs.Results[0].Id == nil {
+		return fmt.Errorf("no certificate imported for Principal Identity %s", name)
+	}
+	certID := certs.Results[0].Id
+	if _, err := client.PrincipalIdentitiesClient.Updatecertificate(mpmodel.UpdatePrincipalIdentityCertificateRequest{PrincipalIdentityId: pi.Id, CertificateId: certID}); err != nil {
+		if err := client.CertificatesClient.Delete(*certID); err != nil {
+			log.Error(err, "failed to delete the imported certificate", "certificate", *certID)
+		}
+		return err
+	}
+	log.Info("registered the rotated certificate to the Principal Identity", "principalIdentity", name, "certificate", *certID)
+	if pi.CertificateId != nil && *pi.CertificateId != *certID {
+		if err := client.CertificatesClient.Delete(*pi.CertificateId); err != nil {
+			log.Error(err, "failed to delete the previous certificate of the Principal Identity", "principalIdentity", name, "certificate", *pi.CertificateId)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/trust_management"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth"
+)
+
+type fakeCertificatesClient struct {
+	trust_management.CertificatesClient
+	imported []string
+	deleted  []string
+}
+
+func (c *fakeCertificatesClient) Importcertificate(data model.TrustObjectData) (model.CertificateList, error) {
+	c.imported = append(c.imported, *data.PemEncoded)
+	id := "cert-2"
+	return model.CertificateList{Results: []model.Certificate{{Id: &id}}}, nil
+}
+
+func (c *fakeCertificatesClient) Delete(id string) error {
+	c.deleted = append(c.deleted, id)
+	return nil
+}
+
+type fakePrincipalIdentitiesClient struct {
+	trust_management.PrincipalIdentitiesClient
+	updateErr error
+	updated   []model.UpdatePrincipalIdentityCertificateRequest
+}
+
+func (c *fakePrincipalIdentitiesClient) List() (model.PrincipalIdentityList, error) {
+	id, name, certID := "pi-1", "nsx-operator", "cert-1"
+	return model.PrincipalIdentityList{Results: []model.PrincipalIdentity{{Id: &id, Name: &name, CertificateId: &certID}}}, nil
+}
+
+func (c *fakePrincipalIdentitiesClient) Updatecertificate(req model.UpdatePrincipalIdentityCertificateRequest) (model.PrincipalIdentity, error) {
+	c.updated = append(c.updated, req)
+	return model.PrincipalIdentity{}, c.updateErr
+}
+
+func generateCert(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "nsx-operator"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestClient_RotateClientCertificate(t *testing.T) {
+	cert1, key1 := generateCert(t)
+	cert2, key2 := generateCert(t)
+	current := [2][]byte{cert1, key1}
+	provider, err := auth.NewPICertProvider("nsx-operator", func() ([]byte, []byte, error) { return current[0], current[1], nil })
+	assert.Nil(t, err)
+
+	certsClient := &fakeCertificatesClient{}
+	pisClient := &fakePrincipalIdentitiesClient{}
+	client := &Client{
+		NsxConfig:                 &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{PrincipalIdentity: "nsx-operator"}},
+		CertificatesClient:        certsClient,
+		PrincipalIdentitiesClient: pisClient,
+		NSXChecker:                NSXHealthChecker{cluster: &Cluster{config: &Config{ClientCertProvider: provider}}},
+	}
+
+	// not rotated
+	assert.Nil(t, client.RotateClientCertificate())
+	assert.Equal(t, 0, len(certsClient.imported))
+
+	// registering the rotated certificate fails, the previous one is kept
+	current = [2][]byte{cert2, key2}
+	pisClient.updateErr = errors.New("update failed")
+	assert.NotNil(t, client.RotateClientCertificate())
+	assert.Equal(t, []string{"cert-2"}, certsClient.deleted)
+	assert.Equal(t, cert1, provider.CertificatePEM())
+
+	// the rotated certificate is registered and activated, the previous one is deleted
+	pisClient.updateErr = nil
+	certsClient.deleted = nil
+	assert.Nil(t, client.RotateClientCertificate())
+	assert.Equal(t, string(cert2), certsClient.imported[1])
+	assert.Equal(t, "pi-1", *pisClient.updated[1].PrincipalIdentityId)
+	assert.Equal(t, "cert-2", *pisClient.updated[1].CertificateId)
+	assert.Equal(t, []string{"cert-1"}, certsClient.deleted)
+	assert.Equal(t, cert2, provider.CertificatePEM())
+
+	// no Principal Identity is configured
+	client.NsxConfig.PrincipalIdentity = ""
+	current = [2][]byte{cert1, key1}
+	assert.Nil(t, client.RotateClientCertificate())
+	assert.Equal(t, 2, len(certsClient.imported))
+	assert.Equal(t, cert1, provider.CertificatePEM())
+
+	// the client doesn't authenticate with a certificate
+	assert.Nil(t, (&Client{}).RotateClientCertificate())
+}
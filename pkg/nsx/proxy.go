@@ -0,0 +1,146 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+// NewProxyURL returns the URL of the proxy NSX is reached through, with the credentials of the proxy auth Secret read
+// with reader. It returns nil if no proxy is configured.
+func NewProxyURL(cf *config.NSXOperatorConfig, reader ctrlclient.Reader) (*url.URL, error) {
+	if cf.ProxyURL == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(cf.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if cf.ProxyAuthSecret == "" || reader == nil {
+		return proxyURL, nil
+	}
+	parts := strings.Split(cf.ProxyAuthSecret, "/")
+	secret := &v1.Secret{}
+	if err := reader.Get(context.TODO(), types.NamespacedName{Namespace: parts[0], Name: parts[1]}, secret); err != nil {
+		return nil, err
+	}
+	username, password := secret.Data[v1.BasicAuthUsernameKey], secret.Data[v1.BasicAuthPasswordKey]
+	if len(username) == 0 {
+		return nil, fmt.Errorf("no %s in Secret %s", v1.BasicAuthUsernameKey, cf.ProxyAuthSecret)
+	}
+	proxyURL.User = url.UserPassword(string(username), string(password))
+	return proxyURL, nil
+}
+
+// proxyFor returns the proxy the endpoint at addr is reached through, nil if the endpoint is reached directly.
+func (cluster *Cluster) proxyFor(addr string) (*url.URL, error) {
+	if cluster.config == nil || cluster.config.ProxyURL == nil {
+		return nil, nil
+	}
+	proxyConfig := httpproxy.Config{
+		HTTPProxy:  cluster.config.ProxyURL.String(),
+		HTTPSProxy: cluster.config.ProxyURL.String(),
+		NoProxy:    strings.Join(cluster.config.NoProxy, ","),
+	}
+	// the no-proxy list is matched by httpproxy, the proxy URL is returned as it is configured
+	proxyURL, err := proxyConfig.ProxyFunc()(&url.URL{Scheme: "https", Host: addr})
+	if err != nil || proxyURL == nil {
+		return nil, err
+	}
+	return cluster.config.ProxyURL, nil
+}
+
+// dialNet connects to the endpoint at addr, through the proxy if one is configured for it.
+func (cluster *Cluster) dialNet(ctx context.Context, network, addr string) (net.Conn, error) {
+	proxyURL, err := cluster.proxyFor(addr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		return cluster.dialer().DialContext(ctx, network, addr)
+	}
+	log.V(1).Info("connecting through proxy", "addr", addr, "proxy", proxyURL.Host)
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		dialer, err := proxy.FromURL(proxyURL, cluster.dialer())
+		if err != nil {
+			return nil, err
+		}
+		return dialer.(proxy.ContextDialer).DialContext(ctx, network, addr)
+	case "http", "https":
+		return cluster.dialHTTPProxy(ctx, proxyURL, addr)
+	}
+	return nil, fmt.Errorf("unsupported proxy scheme %s", proxyURL.Scheme)
+}
+
+// dialHTTPProxy opens a tunnel to addr with the CONNECT method of the HTTP proxy.
+func (cluster *Cluster) dialHTTPProxy(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	conn, err := cluster.dialer().DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// the proxy sends nothing after the response until the TLS handshake starts, so no data is lost in the buffer
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", proxyURL.Host, addr, resp.Status)
+	}
+	return conn, nil
+}
@@ -0,0 +1,173 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+// connectProxy is an HTTP proxy tunnelling the CONNECT requests carrying the expected credentials.
+type connectProxy struct {
+	listener      net.Listener
+	authorization string
+	requests      int32
+}
+
+func newConnectProxy(t *testing.T, username, password string) *connectProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	p := &connectProxy{listener: listener}
+	if username != "" {
+		p.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go p.serve(conn)
+		}
+	}()
+	return p
+}
+
+func (p *connectProxy) serve(conn net.Conn) {
+	defer conn.Close()
+	req, err := http.ReadRequest(bufio.NewReader(conn))
+	if err != nil {
+		return
+	}
+	atomic.AddInt32(&p.requests, 1)
+	if req.Method != http.MethodConnect {
+		io.WriteString(conn, "HTTP/1.1 405 Method Not Allowed\r\n\r\n")
+		return
+	}
+	if req.Header.Get("Proxy-Authorization") != p.authorization {
+		io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+		return
+	}
+	target, err := net.Dial("tcp", req.Host)
+	if err != nil {
+		io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+		return
+	}
+	defer target.Close()
+	io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+func (p *connectProxy) url(user *url.Userinfo) *url.URL {
+	return &url.URL{Scheme: "http", Host: p.listener.Addr().String(), User: user}
+}
+
+func TestNewProxyURL(t *testing.T) {
+	cf := &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}}
+	proxyURL, err := NewProxyURL(cf, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, proxyURL)
+
+	cf.ProxyURL = "http://proxy:3128"
+	proxyURL, err = NewProxyURL(cf, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://proxy:3128", proxyURL.String())
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "vmware-system-nsx", Name: "proxy-auth"},
+		Data:       map[string][]byte{v1.BasicAuthUsernameKey: []byte("admin"), v1.BasicAuthPasswordKey: []byte("p@ss")},
+	}
+	reader := fake.NewClientBuilder().WithObjects(secret).Build()
+	cf.ProxyAuthSecret = "vmware-system-nsx/proxy-auth"
+	proxyURL, err = NewProxyURL(cf, reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", proxyURL.User.Username())
+	password, _ := proxyURL.User.Password()
+	assert.Equal(t, "p@ss", password)
+
+	cf.ProxyAuthSecret = "vmware-system-nsx/missing"
+	_, err = NewProxyURL(cf, reader)
+	assert.NotNil(t, err)
+
+	secret.Name = "empty"
+	secret.ResourceVersion = ""
+	secret.Data = nil
+	assert.Nil(t, reader.Create(context.TODO(), secret))
+	cf.ProxyAuthSecret = "vmware-system-nsx/empty"
+	_, err = NewProxyURL(cf, reader)
+	assert.EqualError(t, err, "no username in Secret vmware-system-nsx/empty")
+}
+
+func TestCluster_proxyFor(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy:3128")
+	cluster := &Cluster{config: &Config{ProxyURL: proxyURL, NoProxy: []string{"nsx-1.example.com", ".internal", "10.0.0.0/8"}}}
+	tests := []struct {
+		addr string
+		want *url.URL
+	}{
+		{"nsx-0.example.com:443", proxyURL},
+		{"192.168.1.1:443", proxyURL},
+		{"nsx-1.example.com:443", nil},
+		{"nsx.internal:443", nil},
+		{"10.1.2.3:443", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got, err := cluster.proxyFor(tt.addr)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	cluster = &Cluster{config: &Config{}}
+	got, err := cluster.proxyFor("nsx-0.example.com:443")
+	assert.Nil(t, err)
+	assert.Nil(t, got)
+}
+
+func TestCluster_dialHTTPProxy(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	addr := ts.Listener.Addr().String()
+	proxy := newConnectProxy(t, "admin", "secret")
+	defer proxy.listener.Close()
+	cluster := &Cluster{config: &Config{}, conns: newConnTracker()}
+
+	conn, err := cluster.dialHTTPProxy(context.TODO(), proxy.url(url.UserPassword("admin", "secret")), addr)
+	assert.Nil(t, err)
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+	assert.Nil(t, tlsConn.HandshakeContext(context.TODO()))
+	tlsConn.Close()
+
+	_, err = cluster.dialHTTPProxy(context.TODO(), proxy.url(url.UserPassword("admin", "wrong")), addr)
+	assert.ErrorContains(t, err, "407")
+	_, err = cluster.dialHTTPProxy(context.TODO(), proxy.url(nil), addr)
+	assert.ErrorContains(t, err, "407")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&proxy.requests))
+}
+
+func TestCluster_dialNet(t *testing.T) {
+	cluster := &Cluster{config: &Config{ProxyURL: &url.URL{Scheme: "ftp", Host: "proxy:21"}}}
+	_, err := cluster.dialNet(context.TODO(), "tcp", "nsx-0.example.com:443")
+	assert.EqualError(t, err, "unsupported proxy scheme ftp")
+}
@@ -0,0 +1,153 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ratelimiter
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Class is a class of the NSX APIs limited by its own token bucket.
+type Class int
+
+const (
+	PolicyRead Class = iota
+	PolicyWrite
+	MPRead
+	MPWrite
+)
+
+const (
+	// policyAPIPrefix is the path prefix of the Policy APIs, the other APIs are MP APIs.
+	policyAPIPrefix = "/policy/api/"
+
+	// MinBackoff and MaxBackoff bound the pause of an endpoint after it responds 429/503.
+	MinBackoff = time.Second
+	MaxBackoff = RateLimiterTimeout * time.Second
+)
+
+// DefaultLimits are the max rates per second of the classes of the NSX APIs sent to one endpoint.
+var DefaultLimits = Limits{
+	PolicyRead:  MAXRATELIMIT,
+	PolicyWrite: 50,
+	MPRead:      50,
+	MPWrite:     20,
+}
+
+// Limits are the max rates per second of the classes of the NSX APIs, the default is used for a class absent or 0.
+type Limits map[Class]int
+
+func (c Class) String() string {
+	switch c {
+	case PolicyRead:
+		return "policy-read"
+	case PolicyWrite:
+		return "policy-write"
+	case MPRead:
+		return "mp-read"
+	default:
+		return "mp-write"
+	}
+}
+
+// ClassOf returns the class of the request.
+func ClassOf(r *http.Request) Class {
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions
+	if strings.HasPrefix(r.URL.Path, policyAPIPrefix) {
+		if read {
+			return PolicyRead
+		}
+		return PolicyWrite
+	}
+	if read {
+		return MPRead
+	}
+	return MPWrite
+}
+
+// Buckets limits the requests sent to one endpoint with a rate limiter per class of the APIs, and pauses all the
+// requests of the endpoint for a backoff doubled on each 429/503 response until a request succeeds.
+type Buckets struct {
+	rateLimiterType Type
+	limiters        map[Class]RateLimiter
+	backoff         time.Duration
+	backoffUntil    time.Time
+	sync.Mutex
+}
+
+// NewBuckets creates the rate limiters of the classes of the APIs with the limits.
+func NewBuckets(rateLimiterType Type, limits Limits) *Buckets {
+	b := &Buckets{rateLimiterType: rateLimiterType}
+	b.SetLimits(limits)
+	return b
+}
+
+// SetLimits replaces the rate limiters of the classes with the ones of the limits, the requests already waiting for a
+// token keep waiting on the replaced rate limiters.
+func (b *Buckets) SetLimits(limits Limits) {
+	limiters := map[Class]RateLimiter{}
+	for _, c := range []Class{PolicyRead, PolicyWrite, MPRead, MPWrite} {
+		max := limits[c]
+		if max == 0 {
+			max = DefaultLimits[c]
+		}
+		if b.rateLimiterType == FIXRATE {
+			limiters[c] = NewFixRateLimiter(max)
+		} else {
+			limiters[c] = NewAIMDRateLimiter(max, DEFAULTUPDATEPERIOD)
+		}
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.limiters = limiters
+}
+
+func (b *Buckets) limiter(c Class) RateLimiter {
+	b.Lock()
+	defer b.Unlock()
+	return b.limiters[c]
+}
+
+// Wait blocks the caller until the backoff is over and a token of the class is gained.
+func (b *Buckets) Wait(c Class) {
+	b.Lock()
+	pause := time.Until(b.backoffUntil)
+	b.Unlock()
+	if pause > 0 {
+		log.V(1).Info("waiting for the backoff of the endpoint", "class", c.String(), "pause", pause)
+		time.Sleep(pause)
+	}
+	b.limiter(c).Wait()
+}
+
+// AdjustRate adjusts the rate limiter of the class, and the backoff of the endpoint with the status code.
+func (b *Buckets) AdjustRate(c Class, waitTime time.Duration, statusCode int) {
+	b.limiter(c).AdjustRate(waitTime, statusCode)
+	b.Lock()
+	defer b.Unlock()
+	for _, v := range APIReduceRateCodes {
+		if v == statusCode {
+			b.backoff *= 2
+			if b.backoff < MinBackoff {
+				b.backoff = MinBackoff
+			}
+			if b.backoff > MaxBackoff {
+				b.backoff = MaxBackoff
+			}
+			b.backoffUntil = time.Now().Add(b.backoff)
+			log.Info("backing off the endpoint", "class", c.String(), "statusCode", statusCode, "backoff", b.backoff)
+			return
+		}
+	}
+	if statusCode < http.StatusBadRequest {
+		b.backoff = 0
+	}
+}
+
+// Rate returns the current rate of the class, 0 if it is not limited.
+func (b *Buckets) Rate(c Class) int {
+	return b.limiter(c).rate()
+}
@@ -0,0 +1,81 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ratelimiter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassOf(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		want   Class
+	}{
+		{http.MethodGet, "https://10.0.0.1/policy/api/v1/infra", PolicyRead},
+		{http.MethodPatch, "https://10.0.0.1/policy/api/v1/infra/tier-1s/t1", PolicyWrite},
+		{http.MethodDelete, "https://10.0.0.1/policy/api/v1/infra/tier-1s/t1", PolicyWrite},
+		{http.MethodGet, "https://10.0.0.1/api/v1/node/version", MPRead},
+		{http.MethodPost, "https://10.0.0.1/api/v1/trust-management/principal-identities", MPWrite},
+	}
+	for _, tt := range tests {
+		r, _ := http.NewRequest(tt.method, tt.url, nil)
+		assert.Equal(t, tt.want, ClassOf(r), tt.url)
+	}
+}
+
+func TestNewBuckets(t *testing.T) {
+	b := NewBuckets(FIXRATE, Limits{PolicyWrite: 10})
+	assert.Equal(t, MAXRATELIMIT, b.Rate(PolicyRead))
+	assert.Equal(t, 10, b.Rate(PolicyWrite))
+	assert.Equal(t, DefaultLimits[MPRead], b.Rate(MPRead))
+	assert.Equal(t, DefaultLimits[MPWrite], b.Rate(MPWrite))
+
+	// the AIMD rate limiters start from 1 and grow up to the limits
+	b = NewBuckets(AIMD, nil)
+	assert.Equal(t, 1, b.Rate(PolicyRead))
+}
+
+func TestBuckets_SetLimits(t *testing.T) {
+	b := NewBuckets(FIXRATE, Limits{PolicyWrite: 10})
+	b.SetLimits(Limits{PolicyWrite: 5, MPWrite: 2})
+	assert.Equal(t, 5, b.Rate(PolicyWrite))
+	assert.Equal(t, 2, b.Rate(MPWrite))
+	assert.Equal(t, MAXRATELIMIT, b.Rate(PolicyRead))
+}
+
+func TestBuckets_AdjustRate(t *testing.T) {
+	b := NewBuckets(FIXRATE, nil)
+	b.AdjustRate(PolicyWrite, 0, http.StatusTooManyRequests)
+	assert.Equal(t, MinBackoff, b.backoff)
+	b.AdjustRate(PolicyRead, 0, http.StatusServiceUnavailable)
+	assert.Equal(t, 2*MinBackoff, b.backoff)
+	for i := 0; i < 10; i++ {
+		b.AdjustRate(PolicyRead, 0, http.StatusServiceUnavailable)
+	}
+	assert.Equal(t, MaxBackoff, b.backoff)
+	assert.True(t, time.Until(b.backoffUntil) > MaxBackoff-time.Second)
+
+	// an error response keeps the backoff, a success resets it
+	b.AdjustRate(MPRead, 0, http.StatusNotFound)
+	assert.Equal(t, MaxBackoff, b.backoff)
+	b.AdjustRate(MPRead, 0, http.StatusOK)
+	assert.Equal(t, time.Duration(0), b.backoff)
+}
+
+func TestBuckets_Wait(t *testing.T) {
+	b := NewBuckets(FIXRATE, nil)
+	b.backoffUntil = time.Now().Add(200 * time.Millisecond)
+	start := time.Now()
+	b.Wait(PolicyRead)
+	assert.True(t, time.Since(start) >= 200*time.Millisecond)
+
+	start = time.Now()
+	b.Wait(PolicyRead)
+	assert.True(t, time.Since(start) < 100*time.Millisecond)
+}
@@ -0,0 +1,160 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"strconv"
+	"time"
+
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/bindings"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+	"github.com/vmware-tanzu/nsx-operator/pkg/third_party/retry"
+)
+
+// SearchAPI is the NSX search API a query is sent to.
+type SearchAPI int
+
+const (
+	// PolicySearch searches the Policy resources, in the NSX Project if NSX Operator is configured with one.
+	PolicySearch SearchAPI = iota
+	// MPSearch searches the MP resources.
+	MPSearch
+)
+
+const (
+	// SearchPageSize is the size of the pages requested, it is decreased by searchPageSizeStep down to
+	// minSearchPageSize if NSX rejects the page as too large.
+	SearchPageSize     int64 = 1000
+	searchPageSizeStep int64 = 100
+	minSearchPageSize  int64 = 10
+
+	// pageMaxErrorCode is the error code of NSX rejecting a page exceeding its max size.
+	pageMaxErrorCode = int64(60576)
+
+	// searchPageAttempts is the max attempts of a page failing with a transient error.
+	searchPageAttempts = 3
+	searchPageDelay    = time.Second
+)
+
+// SearchQuery is a query of the NSX search API.
+type SearchQuery struct {
+	API   SearchAPI
+	Query string
+}
+
+// searchPage is a page of the search results.
+type searchPage struct {
+	results     []*data.StructValue
+	cursor      *string
+	resultCount *int64
+}
+
+// SearchAll sends the query to the NSX search API and passes each result to into, following the cursors until all
+// the pages are read. A page rejected as too large is requested again with a smaller page size, and a page failing
+// with a transient error is retried with a backoff, so that the requests stay within the NSX API rate limits.
+// It returns the number of the results passed to into.
+func (client *Client) SearchAll(query SearchQuery, into func(*data.StructValue) error) (uint64, error) {
+	var cursor *string
+	count := uint64(0)
+	pageSize := SearchPageSize
+	for {
+		page, err := client.searchPageWithRetry(query, cursor, pageSize)
+		if _, ok := err.(util.PageMaxError); ok && pageSize > minSearchPageSize {
+			pageSize = decrementSearchPageSize(pageSize)
+			log.Info("search page is too large, retry with a smaller page size", "query", query.Query, "pageSize", pageSize)
+			continue
+		}
+		if err != nil {
+			log.Error(err, "failed to search", "query", query.Query, "count", count)
+			return count, err
+		}
+		for _, entity := range page.results {
+			if err := into(entity); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if page.cursor == nil || *page.cursor == "" || len(page.results) == 0 {
+			break
+		}
+		if c, err := strconv.ParseInt(*page.cursor, 10, 64); err == nil && page.resultCount != nil && c >= *page.resultCount {
+			break
+		}
+		cursor = page.cursor
+	}
+	return count, nil
+}
+
+func (client *Client) searchPageWithRetry(query SearchQuery, cursor *string, pageSize int64) (searchPage, error) {
+	var page searchPage
+	err := retry.Do(
+		func() error {
+			var err error
+			page, err = client.searchPage(query, cursor, pageSize)
+			return TransSearchError(err)
+		},
+		retry.RetryIf(func(err error) bool {
+			switch err.(type) {
+			case vapierrors.ServiceUnavailable, vapierrors.TimedOut:
+				log.Info("search page failed, retrying", "query", query.Query, "error", err.Error())
+				return true
+			}
+			return false
+		}),
+		retry.Attempts(searchPageAttempts), retry.Delay(searchPageDelay), retry.LastErrorOnly(true),
+	)
+	return page, err
+}
+
+func (client *Client) searchPage(query SearchQuery, cursor *string, pageSize int64) (searchPage, error) {
+	if query.API == MPSearch {
+		response, err := client.MPQueryClient.List(query.Query, cursor, nil, &pageSize, nil, nil)
+		return searchPage{results: response.Results, cursor: response.Cursor, resultCount: response.ResultCount}, err
+	}
+	if nsxConfig := client.NsxConfig; nsxConfig.InProject() {
+		// the resources in the NSX Project are only found by the search API of the Project
+		response, err := client.VPCQueryClient.List(nsxConfig.GetNsxOrg(), nsxConfig.NsxProject, query.Query, cursor, nil, &pageSize, nil, nil)
+		return searchPage{results: response.Results, cursor: response.Cursor, resultCount: response.ResultCount}, err
+	}
+	response, err := client.QueryClient.List(query.Query, cursor, nil, &pageSize, nil, nil)
+	return searchPage{results: response.Results, cursor: response.Cursor, resultCount: response.ResultCount}, err
+}
+
+func decrementSearchPageSize(pageSize int64) int64 {
+	pageSize -= searchPageSizeStep
+	if pageSize < minSearchPageSize {
+		pageSize = minSearchPageSize
+	}
+	return pageSize
+}
+
+// TransSearchError translates the error of NSX rejecting a page exceeding its max size to util.PageMaxError, the
+// other errors are returned as they are. The NSX releases reject the page with the same error code, as a
+// ServiceUnavailable or an InvalidRequest error.
+func TransSearchError(err error) error {
+	var errorData *data.StructValue
+	switch vApiError := err.(type) {
+	case vapierrors.ServiceUnavailable:
+		errorData = vApiError.Data
+	case vapierrors.InvalidRequest:
+		errorData = vApiError.Data
+	}
+	if errorData == nil {
+		return err
+	}
+	converter := bindings.NewTypeConverter()
+	converter.SetMode(bindings.REST)
+	dataError, errs := converter.ConvertToGolang(errorData, model.ApiErrorBindingType())
+	if len(errs) > 0 {
+		return err
+	}
+	if apiError, ok := dataError.(model.ApiError); ok && apiError.ErrorCode != nil && *apiError.ErrorCode == pageMaxErrorCode {
+		return util.PageMaxError{Desc: "page max overflow"}
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/bindings"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	mpmodel "github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/model"
+	mpsearch "github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/search"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/search"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func pageMaxError(t *testing.T) error {
+	code := pageMaxErrorCode
+	converter := bindings.NewTypeConverter()
+	converter.SetMode(bindings.REST)
+	dataValue, errs := converter.ConvertToVapi(model.ApiError{ErrorCode: &code}, model.ApiErrorBindingType())
+	assert.Nil(t, errs)
+	return vapierrors.ServiceUnavailable{Data: dataValue.(*data.StructValue)}
+}
+
+// fakeSearchClient serves total results in pages, the cursor is the offset of the next page. The errors are
+// returned by the first calls.
+type fakeSearchClient struct {
+	search.QueryClient
+	total     int
+	errs      []error
+	pageSizes []int64
+}
+
+func (c *fakeSearchClient) List(_ string, cursor *string, _ *string, pageSize *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	c.pageSizes = append(c.pageSizes, *pageSize)
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return model.SearchResponse{}, err
+	}
+	offset := 0
+	if cursor != nil {
+		offset, _ = strconv.Atoi(*cursor)
+	}
+	end := offset + int(*pageSize)
+	if end > c.total {
+		end = c.total
+	}
+	results := make([]*data.StructValue, 0)
+	for i := offset; i < end; i++ {
+		results = append(results, data.NewStructValue(strconv.Itoa(i), nil))
+	}
+	next, count := strconv.Itoa(end), int64(c.total)
+	return model.SearchResponse{Results: results, Cursor: &next, ResultCount: &count}, nil
+}
+
+type fakeMPSearchClient struct {
+	mpsearch.QueryClient
+}
+
+func (c *fakeMPSearchClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (mpmodel.SearchResponse, error) {
+	count := int64(1)
+	return mpmodel.SearchResponse{Results: []*data.StructValue{data.NewStructValue("mp", nil)}, ResultCount: &count}, nil
+}
+
+func TestClient_SearchAll(t *testing.T) {
+	queryClient := &fakeSearchClient{total: 2500, errs: []error{pageMaxError(t)}}
+	client := &Client{
+		NsxConfig:     &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}},
+		QueryClient:   queryClient,
+		MPQueryClient: &fakeMPSearchClient{},
+	}
+	names := map[string]bool{}
+	into := func(entity *data.StructValue) error {
+		names[entity.Name()] = true
+		return nil
+	}
+
+	// the page rejected as too large is requested again with a smaller page size
+	count, err := client.SearchAll(SearchQuery{Query: "resource_type:Rule"}, into)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2500), count)
+	assert.Equal(t, 2500, len(names))
+	assert.Equal(t, []int64{1000, 900, 900, 900}, queryClient.pageSizes)
+
+	// the page failing with a transient error is retried
+	queryClient.pageSizes = nil
+	queryClient.errs = []error{vapierrors.ServiceUnavailable{}}
+	count, err = client.SearchAll(SearchQuery{Query: "resource_type:Rule"}, into)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2500), count)
+	assert.Equal(t, []int64{1000, 1000, 1000, 1000}, queryClient.pageSizes)
+
+	// the other errors are returned
+	queryClient.errs = []error{vapierrors.InvalidRequest{}}
+	_, err = client.SearchAll(SearchQuery{Query: "resource_type:Rule"}, into)
+	assert.IsType(t, vapierrors.InvalidRequest{}, err)
+	count, err = client.SearchAll(SearchQuery{Query: "resource_type:Rule"}, func(*data.StructValue) error { return errors.New("invalid") })
+	assert.Equal(t, errors.New("invalid"), err)
+	assert.Equal(t, uint64(0), count)
+
+	count, err = client.SearchAll(SearchQuery{API: MPSearch, Query: "resource_type:IpBlock"}, into)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), count)
+	assert.True(t, names["mp"])
+}
+
+func TestTransSearchError(t *testing.T) {
+	assert.Equal(t, util.PageMaxError{Desc: "page max overflow"}, TransSearchError(pageMaxError(t)))
+	assert.Equal(t, util.PageMaxError{Desc: "page max overflow"}, TransSearchError(vapierrors.InvalidRequest{Data: pageMaxError(t).(vapierrors.ServiceUnavailable).Data}))
+	assert.Equal(t, vapierrors.ServiceUnavailable{}, TransSearchError(vapierrors.ServiceUnavailable{}))
+	assert.Equal(t, vapierrors.InvalidRequest{}, TransSearchError(vapierrors.InvalidRequest{}))
+	assert.Nil(t, TransSearchError(nil))
+}
+
+func Test_decrementSearchPageSize(t *testing.T) {
+	assert.Equal(t, int64(900), decrementSearchPageSize(1000))
+	assert.Equal(t, minSearchPageSize, decrementSearchPageSize(50))
+}
@@ -9,6 +9,8 @@ import (
 
 	mpmodel "github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/model"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
 )
 
 func QueryTagCondition(resourceType, cluster string) string {
@@ -35,3 +37,42 @@ func ConvertMPTagsToTags(mpTags []mpmodel.Tag) []model.Tag {
 	}
 	return tags
 }
+
+// ParseVPCPath gets the org, project and VPC ID from the path of a VPC or a resource under it,
+// e.g. /orgs/<org>/projects/<project>/vpcs/<vpc>/subnets/<subnet>.
+func ParseVPCPath(path string) (string, string, string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 6 || parts[0] != "orgs" || parts[2] != "projects" || parts[4] != "vpcs" {
+		return "", "", "", fmt.Errorf("invalid VPC path %s", path)
+	}
+	return parts[1], parts[3], parts[5], nil
+}
+
+// ParseIPPoolPath gets the org, project and IP pool ID from the path of a Project IP pool or a resource under it,
+// e.g. /orgs/<org>/projects/<project>/infra/ip-pools/<pool>/ip-subnets/<subnet>.
+func ParseIPPoolPath(path string) (string, string, string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 7 || parts[0] != "orgs" || parts[2] != "projects" || parts[4] != "infra" || parts[5] != "ip-pools" {
+		return "", "", "", fmt.Errorf("invalid IP pool path %s", path)
+	}
+	return parts[1], parts[3], parts[6], nil
+}
+
+// BuildProjectPath returns the path of the NSX Project, e.g. /orgs/<org>/projects/<project>.
+func BuildProjectPath(org, project string) string {
+	return fmt.Sprintf("/orgs/%s/projects/%s", org, project)
+}
+
+// BuildInfraPath returns the path the infra resources like groups and security policies are created under,
+// it is /orgs/<org>/projects/<project>/infra if NSX Operator runs in an NSX Project, otherwise /infra.
+func BuildInfraPath(cf *config.NSXOperatorConfig) string {
+	if !cf.InProject() {
+		return "/infra"
+	}
+	return BuildProjectPath(cf.GetNsxOrg(), cf.NsxProject) + "/infra"
+}
+
+// InfraPath returns the infra path of the NSX Project NSX Operator runs in, see BuildInfraPath.
+func (service *Service) InfraPath() string {
+	return BuildInfraPath(service.NSXConfig)
+}
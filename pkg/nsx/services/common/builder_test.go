@@ -9,6 +9,8 @@ import (
 
 	mpmodel "github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/model"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
 )
 
 func TestConvertMPTagsToTags(t *testing.T) {
@@ -118,3 +120,34 @@ func TestQueryTagCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestParseVPCPath(t *testing.T) {
+	org, project, vpc, err := ParseVPCPath("/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1")
+	if err != nil || org != "default" || project != "p1" || vpc != "vpc1" {
+		t.Errorf("ParseVPCPath() = %s, %s, %s, %v", org, project, vpc, err)
+	}
+	if _, _, _, err = ParseVPCPath("/infra/vpcs/vpc1"); err == nil {
+		t.Errorf("ParseVPCPath() should fail for non VPC path")
+	}
+}
+
+func TestParseIPPoolPath(t *testing.T) {
+	org, project, pool, err := ParseIPPoolPath("/orgs/default/projects/p1/infra/ip-pools/pool1/ip-subnets/subnet1")
+	if err != nil || org != "default" || project != "p1" || pool != "pool1" {
+		t.Errorf("ParseIPPoolPath() = %s, %s, %s, %v", org, project, pool, err)
+	}
+	if _, _, _, err = ParseIPPoolPath("/infra/ip-pools/pool1"); err == nil {
+		t.Errorf("ParseIPPoolPath() should fail for non Project IP pool path")
+	}
+}
+
+func TestService_InfraPath(t *testing.T) {
+	service := &Service{NSXConfig: &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}}}
+	if path := service.InfraPath(); path != "/infra" {
+		t.Errorf("InfraPath() = %s, want /infra", path)
+	}
+	service.NSXConfig.NsxProject = "tenant1"
+	if path := service.InfraPath(); path != "/orgs/default/projects/tenant1/infra" {
+		t.Errorf("InfraPath() = %s, want /orgs/default/projects/tenant1/infra", path)
+	}
+}
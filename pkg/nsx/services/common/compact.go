@@ -0,0 +1,139 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/bindings"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data/serializers/cleanjson"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CompactResource is the digest of an NSX resource a compact store holds instead of its SDK model, it only keeps what
+// the indexes, the resyncs and the diffs need, the full NSX resource is fetched from NSX when it is needed.
+type CompactResource struct {
+	Id       *string
+	Path     *string
+	Revision *int64
+	Tags     []model.Tag
+	// Hash is the hash of the compared fields of the NSX resource, see HashComparable.
+	Hash string
+}
+
+func (r CompactResource) Key() string {
+	return *r.Id
+}
+
+// Value returns nil, the CompactResources are compared by their hashes, see CompareResource.
+func (r CompactResource) Value() data.DataValue {
+	return nil
+}
+
+// Expand returns the SDK model of bindingType with the fields of the digest, e.g. to delete the NSX resource, or to
+// snapshot the store. The other fields are unset.
+func (r CompactResource) Expand(bindingType bindings.BindingType) interface{} {
+	structType, ok := bindingType.(bindings.StructType)
+	if !ok {
+		return r
+	}
+	obj := reflect.New(structType.BindingStruct()).Elem()
+	for name, value := range map[string]interface{}{"Id": r.Id, "Path": r.Path, "Revision": r.Revision, "Tags": r.Tags} {
+		field := obj.FieldByName(name)
+		if field.IsValid() && field.Type() == reflect.TypeOf(value) {
+			field.Set(reflect.ValueOf(value))
+		}
+	}
+	return obj.Interface()
+}
+
+// HashComparable returns the hash of the Value of c, two NSX resources with the same hash don't differ.
+func HashComparable(c Comparable) string {
+	s, _ := cleanjson.NewDataValueToJsonEncoder().Encode(c.Value())
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:16])
+}
+
+// compacter is implemented by the indexers of the compact stores.
+type compacter interface {
+	compact(obj interface{}) interface{}
+}
+
+// compactIndexer holds the CompactResources of the SDK models added to it.
+type compactIndexer struct {
+	cache.Indexer
+	toComparable func(obj interface{}) Comparable
+}
+
+// NewCompactIndexer returns the indexer of a compact store, it holds the CompactResources of the SDK models added to
+// it instead of the models, the stores of large inventories use much less memory. toComparable returns the Comparable
+// of an SDK model, whose Value is hashed. keyFunc and indexers must support both the SDK models and the
+// CompactResources, the NSX resources are also indexed as by NewIndexer.
+func NewCompactIndexer(keyFunc cache.KeyFunc, indexers cache.Indexers, toComparable func(obj interface{}) Comparable) cache.Indexer {
+	return &compactIndexer{Indexer: NewIndexer(keyFunc, indexers), toComparable: toComparable}
+}
+
+func (i *compactIndexer) compact(obj interface{}) interface{} {
+	if _, ok := obj.(CompactResource); ok {
+		return obj
+	}
+	r := CompactResource{
+		Id:   stringPtrField(reflect.ValueOf(obj), "Id"),
+		Path: stringPtrField(reflect.ValueOf(obj), "Path"),
+		Hash: HashComparable(i.toComparable(obj)),
+	}
+	if revision := revisionOf(obj); revision >= 0 {
+		r.Revision = &revision
+	}
+	forEachTag(obj, func(scope, tag string) {
+		r.Tags = append(r.Tags, model.Tag{Scope: &scope, Tag: &tag})
+	})
+	return r
+}
+
+func (i *compactIndexer) Add(obj interface{}) error {
+	return i.Indexer.Add(i.compact(obj))
+}
+
+func (i *compactIndexer) Update(obj interface{}) error {
+	return i.Indexer.Update(i.compact(obj))
+}
+
+func (i *compactIndexer) Replace(objs []interface{}, resourceVersion string) error {
+	compacted := make([]interface{}, 0, len(objs))
+	for _, obj := range objs {
+		compacted = append(compacted, i.compact(obj))
+	}
+	return i.Indexer.Replace(compacted, resourceVersion)
+}
+
+// ComparableByIndex returns the Comparables of the NSX resources of the index value to diff them with the expected
+// ones, the CompactResources of a compact store, or toComparable of the SDK models of the others.
+func (resourceStore *ResourceStore) ComparableByIndex(index string, value string, toComparable func(obj interface{}) Comparable) []Comparable {
+	objs := resourceStore.GetByIndex(index, value)
+	comparables := make([]Comparable, 0, len(objs))
+	for _, obj := range objs {
+		if r, ok := obj.(CompactResource); ok {
+			comparables = append(comparables, r)
+			continue
+		}
+		comparables = append(comparables, toComparable(obj))
+	}
+	return comparables
+}
+
+// Compact returns whether the store holds CompactResources, see NewCompactIndexer.
+func (resourceStore *ResourceStore) Compact() bool {
+	_, ok := resourceStore.Indexer.(compacter)
+	return ok
+}
+
+// expand returns the SDK model of obj, which the compact stores hold the CompactResource of.
+func (resourceStore *ResourceStore) expand(obj interface{}) interface{} {
+	if r, ok := obj.(CompactResource); ok {
+		return r.Expand(resourceStore.BindingType)
+	}
+	return obj
+}
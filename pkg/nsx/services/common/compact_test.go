@@ -0,0 +1,82 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+)
+
+type testRule model.Rule
+
+func (rule *testRule) Key() string {
+	return *rule.Id
+}
+
+func (rule *testRule) Value() data.DataValue {
+	r := model.Rule{Id: rule.Id, DisplayName: rule.DisplayName, Tags: rule.Tags}
+	dataValue, _ := r.GetDataValue__()
+	return dataValue
+}
+
+func testRuleToComparable(obj interface{}) Comparable {
+	rule := obj.(model.Rule)
+	return (*testRule)(&rule)
+}
+
+func compactKeyFunc(obj interface{}) (string, error) {
+	ids, _ := nsxIDIndexFunc(obj)
+	if len(ids) == 0 {
+		return "", nil
+	}
+	return ids[0], nil
+}
+
+func TestCompactIndexer(t *testing.T) {
+	store := &ResourceStore{Indexer: NewCompactIndexer(compactKeyFunc, cache.Indexers{}, testRuleToComparable),
+		BindingType: model.RuleBindingType()}
+	assert.True(t, store.Compact())
+	id, path, name, revision := "rule1", "/infra/domains/default/security-policies/sp1/rules/rule1", "rule1", int64(3)
+	scope, uid := TagScopeSecurityPolicyCRUID, "uid1"
+	tags := []model.Tag{{Scope: &scope, Tag: &uid}}
+	rule := model.Rule{Id: &id, Path: &path, DisplayName: &name, Revision: &revision, Tags: tags}
+	assert.Nil(t, store.Add(rule))
+
+	// only the digest of the rule is held
+	r, ok := store.GetByKey(id).(CompactResource)
+	assert.True(t, ok)
+	assert.Equal(t, path, *r.Path)
+	assert.Equal(t, revision, *r.Revision)
+	assert.Equal(t, tags, r.Tags)
+	assert.Len(t, store.ListByTag(TagScopeSecurityPolicyCRUID, uid), 1)
+	comparables := store.ComparableByIndex(IndexKeyNSXID, id, testRuleToComparable)
+	assert.Len(t, comparables, 1)
+	assert.False(t, CompareResource(comparables[0], testRuleToComparable(rule)))
+	renamed, changedName := rule, "rule2"
+	renamed.DisplayName = &changedName
+	assert.True(t, CompareResource(comparables[0], testRuleToComparable(renamed)))
+	expanded := r.Expand(model.RuleBindingType()).(model.Rule)
+	assert.Equal(t, model.Rule{Id: &id, Path: &path, Revision: &revision, Tags: tags}, expanded)
+
+	// the resyncs compare the digests
+	value, errs := NewConverter().ConvertToVapi(rule, model.RuleBindingType())
+	assert.Empty(t, errs)
+	changed, err := store.ResyncResource(value.(*data.StructValue))
+	assert.Nil(t, err)
+	assert.False(t, changed)
+	renamed.Revision = Int64(4)
+	value, _ = NewConverter().ConvertToVapi(renamed, model.RuleBindingType())
+	changed, err = store.ResyncResource(value.(*data.StructValue))
+	assert.Nil(t, err)
+	assert.True(t, changed)
+
+	// the digests are snapshotted
+	resources, err := store.Snapshot()
+	assert.Nil(t, err)
+	assert.Len(t, resources, 1)
+	assert.Contains(t, string(resources[0]), path)
+	assert.Nil(t, store.Delete(rule))
+	assert.Empty(t, store.List())
+}
@@ -14,7 +14,12 @@ type Comparable interface {
 	Value() data.DataValue
 }
 
+// CompareResource returns whether expected differs from existing, existing is compared by its hash if it is the
+// CompactResource of a compact store.
 func CompareResource(existing Comparable, expected Comparable) (isChanged bool) {
+	if r, ok := existing.(CompactResource); ok {
+		return r.Hash != HashComparable(expected)
+	}
 	var dataValueToJSONEncoder = cleanjson.NewDataValueToJsonEncoder()
 	s1, _ := dataValueToJSONEncoder.Encode(existing.Value())
 	s2, _ := dataValueToJSONEncoder.Encode(expected.Value())
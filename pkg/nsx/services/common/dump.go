@@ -0,0 +1,49 @@
+package common
+
+import (
+	"sync"
+)
+
+// lister lists the resources of a store, the ResourceStores implement it with their Indexer.
+type lister interface {
+	List() []interface{}
+}
+
+// storeDumps are the stores initialized by InitializeResourceStore, keyed by resource type, a resource type may be
+// cached by several services, e.g. the IP allocations of the IPAddressAllocations and of the LB VIPs, or by the
+// services of several NSX managers.
+var storeDumps = struct {
+	stores map[string][]lister
+	sync.RWMutex
+}{stores: map[string][]lister{}}
+
+func registerStoreDump(resourceType string, store Store) {
+	l, ok := store.(lister)
+	if !ok {
+		return
+	}
+	storeDumps.Lock()
+	defer storeDumps.Unlock()
+	for _, registered := range storeDumps.stores[resourceType] {
+		if registered == l {
+			return
+		}
+	}
+	storeDumps.stores[resourceType] = append(storeDumps.stores[resourceType], l)
+}
+
+// DumpStores returns the NSX resources cached in the stores of the services, keyed by resource type, for the support
+// bundles.
+func DumpStores() map[string][]interface{} {
+	storeDumps.RLock()
+	defer storeDumps.RUnlock()
+	dump := make(map[string][]interface{}, len(storeDumps.stores))
+	for resourceType, stores := range storeDumps.stores {
+		resources := []interface{}{}
+		for _, store := range stores {
+			resources = append(resources, store.List()...)
+		}
+		dump[resourceType] = resources
+	}
+	return dump
+}
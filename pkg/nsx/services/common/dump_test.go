@@ -0,0 +1,28 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDumpStores(t *testing.T) {
+	newStore := func(ids ...string) *ResourceStore {
+		store := &ResourceStore{Indexer: cache.NewIndexer(keyFunc, cache.Indexers{}), BindingType: model.RuleBindingType()}
+		for i := range ids {
+			assert.Nil(t, store.Add(model.Rule{Id: &ids[i]}))
+		}
+		return store
+	}
+	rules, others := newStore("rule1"), newStore("rule2", "rule3")
+	registerStoreDump("DumpTest", rules)
+	registerStoreDump("DumpTest", others)
+	registerStoreDump("DumpTest", others)
+
+	dump := DumpStores()
+	assert.Len(t, dump["DumpTest"], 3)
+	rule3 := "rule3"
+	assert.Contains(t, dump["DumpTest"], model.Rule{Id: &rule3})
+}
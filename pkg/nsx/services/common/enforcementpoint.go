@@ -0,0 +1,60 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// DefaultSite is the NSX site the enforcement points are in.
+const DefaultSite = "default"
+
+// EnforcementPointPath returns the NSX Policy path of the enforcement point of the default site, under the infra path
+// of the NSX Project if NSX Operator runs in one.
+func EnforcementPointPath(cf *config.NSXOperatorConfig, enforcementPoint string) string {
+	return fmt.Sprintf("%s/sites/%s/enforcement-points/%s", BuildInfraPath(cf), DefaultSite, enforcementPoint)
+}
+
+// ParseEnforcementPoint returns the enforcement point of the NSX Policy path of an object under an enforcement point,
+// or "" if the path is not under one.
+func ParseEnforcementPoint(path string) string {
+	parts := strings.Split(path, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == "enforcement-points" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// GetEnforcementPoint returns the enforcement point the resources of the Namespace are realized on, which is the one
+// selected by the annotation of the Namespace, or the default one of the config. The default one is used for the
+// cluster scoped resources and the Namespaces which no longer exist.
+func (service *Service) GetEnforcementPoint(namespace string) (string, error) {
+	defaultEnforcementPoint := service.NSXConfig.GetEnforcementPoint()
+	if namespace == "" || len(service.NSXConfig.EnforcementPoints) == 0 {
+		return defaultEnforcementPoint, nil
+	}
+	ns := &v1.Namespace{}
+	if err := service.Client.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return defaultEnforcementPoint, nil
+		}
+		return "", err
+	}
+	enforcementPoint, ok := ns.Annotations[AnnotationEnforcementPoint]
+	if !ok || enforcementPoint == "" {
+		return defaultEnforcementPoint, nil
+	}
+	if !service.NSXConfig.EnforcementPointAllowed(enforcementPoint) {
+		return "", nsxutil.RestrictionError{Desc: fmt.Sprintf("enforcement point %q of Namespace %s is not one of %v", enforcementPoint, namespace, service.NSXConfig.EnforcementPoints)}
+	}
+	return enforcementPoint, nil
+}
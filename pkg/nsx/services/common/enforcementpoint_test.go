@@ -0,0 +1,51 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func TestEnforcementPointPath(t *testing.T) {
+	cf := &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}}
+	assert.Equal(t, "/infra/sites/default/enforcement-points/ep1", EnforcementPointPath(cf, "ep1"))
+	assert.Equal(t, "ep1", ParseEnforcementPoint("/infra/sites/default/enforcement-points/ep1/cluster-control-planes/c1"))
+	assert.Equal(t, "ep1", ParseEnforcementPoint(EnforcementPointPath(cf, "ep1")))
+	cf.NsxProject = "p1"
+	assert.Equal(t, "/orgs/default/projects/p1/infra/sites/default/enforcement-points/ep1", EnforcementPointPath(cf, "ep1"))
+	assert.Equal(t, "ep1", ParseEnforcementPoint(EnforcementPointPath(cf, "ep1")))
+	assert.Equal(t, "", ParseEnforcementPoint("/infra/tier-0s/t0"))
+}
+
+func TestService_GetEnforcementPoint(t *testing.T) {
+	service := &Service{
+		Client: fake.NewClientBuilder().WithObjects(
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-a"}},
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-b", Annotations: map[string]string{AnnotationEnforcementPoint: "site-b"}}},
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-c", Annotations: map[string]string{AnnotationEnforcementPoint: "site-c"}}},
+		).Build(),
+		NSXConfig: &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}},
+	}
+
+	// the annotations are ignored without a list of enforcement points
+	ep, err := service.GetEnforcementPoint("ns-b")
+	assert.Nil(t, err)
+	assert.Equal(t, "default", ep)
+
+	service.NSXConfig.EnforcementPoint = "site-a"
+	service.NSXConfig.EnforcementPoints = []string{"site-a", "site-b"}
+	for namespace, want := range map[string]string{"": "site-a", "ns-a": "site-a", "ns-b": "site-b", "ns-deleted": "site-a"} {
+		ep, err = service.GetEnforcementPoint(namespace)
+		assert.Nil(t, err)
+		assert.Equal(t, want, ep, namespace)
+	}
+
+	_, err = service.GetEnforcementPoint("ns-c")
+	assert.IsType(t, nsxutil.RestrictionError{}, err)
+}
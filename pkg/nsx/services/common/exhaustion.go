@@ -0,0 +1,63 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	vapistd "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// ipExhaustedMessages are the fragments of the NSX error messages reporting that a pool runs out of IPs.
+var ipExhaustedMessages = []string{"exhausted", "no free", "not enough free", "insufficient"}
+
+// IsIPExhaustedMessage returns true if the NSX error message reports that a pool runs out of IPs.
+func IsIPExhaustedMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, fragment := range ipExhaustedMessages {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// TransIPExhaustedError translates the NSX error returned when the pool runs out of IPs to nsxutil.IPExhaustedError,
+// other errors are returned as they are.
+func TransIPExhaustedError(err error, pool string) error {
+	var msg string
+	switch e := err.(type) {
+	case vapierrors.UnableToAllocateResource:
+		msg = vapiErrorMessage(e.Messages, e.Data)
+	case vapierrors.InvalidRequest:
+		msg = vapiErrorMessage(e.Messages, e.Data)
+		if !IsIPExhaustedMessage(msg) {
+			return err
+		}
+	default:
+		return err
+	}
+	return nsxutil.IPExhaustedError{Pool: pool, Desc: fmt.Sprintf("no IP available in %s: %s", pool, msg)}
+}
+
+// vapiErrorMessage joins the messages of the vAPI error and the message of the NSX API error it carries.
+func vapiErrorMessage(messages []vapistd.LocalizableMessage, errorData *data.StructValue) string {
+	var msgs []string
+	for _, message := range messages {
+		if message.DefaultMessage != "" {
+			msgs = append(msgs, message.DefaultMessage)
+		}
+	}
+	if errorData != nil {
+		if dataError, errs := NewConverter().ConvertToGolang(errorData, model.ApiErrorBindingType()); len(errs) == 0 {
+			if apiError, ok := dataError.(model.ApiError); ok && apiError.ErrorMessage != nil {
+				msgs = append(msgs, *apiError.ErrorMessage)
+			}
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
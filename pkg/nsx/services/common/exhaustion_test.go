@@ -0,0 +1,42 @@
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	vapistd "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func TestIsIPExhaustedMessage(t *testing.T) {
+	assert.True(t, IsIPExhaustedMessage("IP Pool pool1 is Exhausted."))
+	assert.True(t, IsIPExhaustedMessage("No free IP address in the IP block."))
+	assert.False(t, IsIPExhaustedMessage("Invalid CIDR 10.0.0.0/33."))
+}
+
+func TestTransIPExhaustedError(t *testing.T) {
+	pool := "/orgs/default/projects/p1/infra/ip-pools/pool1"
+
+	err := TransIPExhaustedError(vapierrors.UnableToAllocateResource{
+		Messages: []vapistd.LocalizableMessage{{DefaultMessage: "cannot allocate IP"}},
+	}, pool)
+	exhausted := nsxutil.IPExhaustedError{}
+	assert.True(t, errors.As(err, &exhausted))
+	assert.Equal(t, pool, exhausted.Pool)
+	assert.Equal(t, "no IP available in "+pool+": cannot allocate IP", exhausted.Desc)
+
+	apiError, _ := NewConverter().ConvertToVapi(model.ApiError{ErrorMessage: String("IP block is exhausted")}, model.ApiErrorBindingType())
+	err = TransIPExhaustedError(vapierrors.InvalidRequest{Data: apiError.(*data.StructValue)}, pool)
+	assert.True(t, errors.As(err, &exhausted))
+	assert.Equal(t, "no IP available in "+pool+": IP block is exhausted", exhausted.Desc)
+
+	invalid := vapierrors.InvalidRequest{Messages: []vapistd.LocalizableMessage{{DefaultMessage: "invalid CIDR"}}}
+	assert.Equal(t, invalid, TransIPExhaustedError(invalid, pool))
+	other := errors.New("connection refused")
+	assert.Equal(t, other, TransIPExhaustedError(other, pool))
+}
@@ -0,0 +1,155 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+)
+
+// gcInterval is the interval of the garbage collectors configured by gc_interval, 0 if it is unset.
+var gcInterval int64
+
+// SetGCInterval sets the interval of the garbage collectors started with GCInterval, it takes effect on their next
+// run. GCInterval is restored if interval is 0.
+func SetGCInterval(interval time.Duration) {
+	atomic.StoreInt64(&gcInterval, int64(interval))
+}
+
+// GCWait returns how long a garbage collector started with interval waits before its next run. The collectors
+// started with GCInterval wait for the interval set by SetGCInterval if one is set.
+func GCWait(interval time.Duration) time.Duration {
+	if interval != GCInterval {
+		return interval
+	}
+	if configured := atomic.LoadInt64(&gcInterval); configured > 0 {
+		return time.Duration(configured)
+	}
+	return interval
+}
+
+// GCStatus is the outcome of the last run of the garbage collector of a resource type.
+type GCStatus struct {
+	ResourceType string
+	LastRunTime  time.Time
+	// LastSuccessTime is zero if the garbage collector never ran without errors.
+	LastSuccessTime time.Time
+	Scanned         int
+	Deleted         int
+	Errors          int
+	// LastError is empty if the last run succeeded.
+	LastError string
+}
+
+// gcStatuses are the statuses of the garbage collectors, keyed by resource type.
+var gcStatuses = struct {
+	statuses map[string]GCStatus
+	sync.Mutex
+}{statuses: map[string]GCStatus{}}
+
+// GCRun is a run of the garbage collector of a resource type, it counts the NSX resources scanned and deleted, and
+// reports them in the metrics and the GC statuses when it finishes.
+type GCRun struct {
+	cf           *config.NSXOperatorConfig
+	resourceType string
+	start        time.Time
+	scanned      int
+	deleted      int
+	failed       int
+	deleteErr    error
+	// orphans are the UIDs of the CRs which no longer exist whose NSX resources are orphaned, keyed by the scope of
+	// the tag of the UIDs, nil if the run didn't compare the NSX resources with the CRs.
+	orphans map[string]sets.String
+}
+
+// StartGCRun starts a run of the garbage collector of resourceType.
+func StartGCRun(cf *config.NSXOperatorConfig, resourceType string) *GCRun {
+	return &GCRun{cf: cf, resourceType: resourceType, start: time.Now()}
+}
+
+// Scanned counts n NSX resources scanned.
+func (r *GCRun) Scanned(n int) {
+	r.scanned += n
+}
+
+// Deleted counts an NSX resource deleted, or failed to be deleted if err is not nil.
+func (r *GCRun) Deleted(err error) {
+	if err != nil {
+		r.failed++
+		r.deleteErr = err
+		return
+	}
+	r.deleted++
+}
+
+// Collected counts the NSX resources deleted and failed to be deleted by a garbage collector counting them itself.
+func (r *GCRun) Collected(deleted, failed uint32) {
+	r.deleted += int(deleted)
+	r.failed += int(failed)
+}
+
+// Orphaned records the UIDs of the CRs which no longer exist, tagged with scope on their NSX resources. The NSX
+// resources tagged with them which are not deleted by the run, e.g. in dry run, are reported by OrphanedResources
+// once the run finishes.
+func (r *GCRun) Orphaned(scope string, uids sets.String) {
+	if r.orphans == nil {
+		r.orphans = map[string]sets.String{}
+	}
+	if r.orphans[scope] == nil {
+		r.orphans[scope] = sets.NewString()
+	}
+	r.orphans[scope].Insert(uids.UnsortedList()...)
+}
+
+// Finish ends the run, which failed if err is not nil, e.g. the CRs failed to be listed, or if some NSX resources failed
+// to be deleted.
+func (r *GCRun) Finish(err error) {
+	now := time.Now()
+	if err == nil && r.failed > 0 {
+		err = r.deleteErr
+		if err == nil {
+			err = fmt.Errorf("failed to delete %d NSX resources", r.failed)
+		}
+	}
+	metrics.ObserveGCRun(r.cf, r.resourceType, r.scanned, r.deleted, r.failed, now.Sub(r.start), err == nil)
+	if r.orphans != nil {
+		setOrphanedResources(r.resourceType, findOrphanedResources(r.resourceType, r.orphans))
+	} else if err == nil {
+		// the run found no NSX resources to compare with the CRs
+		setOrphanedResources(r.resourceType, nil)
+	}
+
+	gcStatuses.Lock()
+	defer gcStatuses.Unlock()
+	status := gcStatuses.statuses[r.resourceType]
+	status.ResourceType = r.resourceType
+	status.LastRunTime = now
+	status.Scanned, status.Deleted, status.Errors = r.scanned, r.deleted, r.failed
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	} else {
+		status.LastSuccessTime = now
+	}
+	gcStatuses.statuses[r.resourceType] = status
+}
+
+// GCStatuses returns the statuses of the garbage collectors which ran, sorted by resource type.
+func GCStatuses() []GCStatus {
+	gcStatuses.Lock()
+	defer gcStatuses.Unlock()
+	statuses := make([]GCStatus, 0, len(gcStatuses.statuses))
+	for _, status := range gcStatuses.statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ResourceType < statuses[j].ResourceType
+	})
+	return statuses
+}
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestGCWait(t *testing.T) {
+	defer SetGCInterval(0)
+	assert.Equal(t, GCInterval, GCWait(GCInterval))
+	SetGCInterval(5 * time.Minute)
+	assert.Equal(t, 5*time.Minute, GCWait(GCInterval))
+	// the collectors started with their own interval keep it
+	assert.Equal(t, 10*time.Millisecond, GCWait(10*time.Millisecond))
+	SetGCInterval(0)
+	assert.Equal(t, GCInterval, GCWait(GCInterval))
+}
+
+func TestGCRun(t *testing.T) {
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	defer func() { gcStatuses.statuses = map[string]GCStatus{} }()
+
+	run := StartGCRun(cf, "gc-b")
+	run.Scanned(3)
+	run.Deleted(nil)
+	run.Deleted(errors.New("conflict"))
+	run.Finish(nil)
+	run = StartGCRun(cf, "gc-a")
+	run.Scanned(2)
+	run.Collected(2, 0)
+	run.Finish(nil)
+
+	statuses := GCStatuses()
+	assert.Equal(t, 2, len(statuses))
+	assert.Equal(t, "gc-a", statuses[0].ResourceType)
+	assert.Equal(t, "", statuses[0].LastError)
+	assert.Equal(t, statuses[0].LastRunTime, statuses[0].LastSuccessTime)
+	assert.Equal(t, 2, statuses[0].Deleted)
+	failed := statuses[1]
+	assert.Equal(t, 3, failed.Scanned)
+	assert.Equal(t, 1, failed.Deleted)
+	assert.Equal(t, 1, failed.Errors)
+	assert.Equal(t, "conflict", failed.LastError)
+	assert.True(t, failed.LastSuccessTime.IsZero())
+
+	// the last success is kept when a later run fails
+	StartGCRun(cf, "gc-a").Finish(errors.New("failed to list"))
+	statuses = GCStatuses()
+	assert.Equal(t, "failed to list", statuses[0].LastError)
+	assert.False(t, statuses[0].LastSuccessTime.IsZero())
+	assert.False(t, statuses[0].LastSuccessTime.After(statuses[0].LastRunTime))
+}
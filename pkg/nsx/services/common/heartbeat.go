@@ -0,0 +1,140 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+const (
+	// heartbeatDomain is the domain of the NSX Group holding the heartbeat.
+	heartbeatDomain = "default"
+	// policyAPIPrefix is the prefix of the paths of the NSX Policy API requests.
+	policyAPIPrefix = "/policy/api/v1"
+)
+
+// heartbeatGroups are the NSX API requests of the Groups HeartbeatLock sends, they are served by domains.GroupsClient.
+type heartbeatGroups interface {
+	Get(domainIdParam string, groupIdParam string) (model.Group, error)
+	Update(domainIdParam string, groupIdParam string, groupParam model.Group) (model.Group, error)
+}
+
+// HeartbeatLock is the lock of the leader election of the NSX Operators of the management clusters sharing NSX, the
+// leader is the active one. The heartbeat, i.e. the record of the leader election, is held in the description of an
+// NSX Group instead of a Lease, which is only seen by one cluster. The Group is updated with the revision last read,
+// so the concurrent updates of the standby ones fail.
+type HeartbeatLock struct {
+	groupID  string
+	identity string
+	groups   heartbeatGroups
+	// group is the Group last read or updated.
+	group *model.Group
+	sync.Mutex
+}
+
+// NewHeartbeatLock returns the lock of identity, e.g. the cluster and the name of the pod, held in the Group of groupID.
+// The requests fail until the NSX client is set with SetNSXClient, it is created after the manager. The heartbeat is
+// exempted from the NSX mutation pause, the active NSX Operator keeps its leadership while the mutations are paused.
+func NewHeartbeatLock(groupID, identity string) *HeartbeatLock {
+	l := &HeartbeatLock{groupID: groupID, identity: identity}
+	nsxutil.ExemptFromPause(policyAPIPrefix + l.Describe())
+	return l
+}
+
+// SetNSXClient sets the NSX client the requests are sent with.
+func (l *HeartbeatLock) SetNSXClient(client *nsx.Client) {
+	l.Lock()
+	defer l.Unlock()
+	l.groups = client.GroupClient
+}
+
+// Get returns the heartbeat, or the NotFound error of the API server if the Group doesn't exist.
+func (l *HeartbeatLock) Get(ctx context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	l.Lock()
+	defer l.Unlock()
+	if l.groups == nil {
+		return nil, nil, errors.New("NSX client is not set")
+	}
+	group, err := l.groups.Get(heartbeatDomain, l.groupID)
+	if err != nil {
+		err = nsxutil.TransNSXError(err)
+		var notFound nsxutil.NotFoundError
+		if errors.As(err, &notFound) {
+			l.group = nil
+			return nil, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "groups"}, l.groupID)
+		}
+		return nil, nil, err
+	}
+	l.group = &group
+	record := &resourcelock.LeaderElectionRecord{}
+	if group.Description == nil || *group.Description == "" {
+		return record, nil, nil
+	}
+	raw := []byte(*group.Description)
+	if err := json.Unmarshal(raw, record); err != nil {
+		return nil, nil, fmt.Errorf("invalid heartbeat in NSX Group %s: %w", l.groupID, err)
+	}
+	return record, raw, nil
+}
+
+// Create creates the Group with the heartbeat.
+func (l *HeartbeatLock) Create(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	l.Lock()
+	defer l.Unlock()
+	return l.update(ler, &model.Group{Id: String(l.groupID), DisplayName: String(l.groupID)})
+}
+
+// Update updates the heartbeat of the Group last read.
+func (l *HeartbeatLock) Update(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	l.Lock()
+	defer l.Unlock()
+	if l.group == nil {
+		return errors.New("NSX Group of the heartbeat is not read yet")
+	}
+	group := *l.group
+	return l.update(ler, &group)
+}
+
+// update updates the heartbeat in group, and the holder in its tags to be seen in the NSX UI.
+func (l *HeartbeatLock) update(ler resourcelock.LeaderElectionRecord, group *model.Group) error {
+	if l.groups == nil {
+		return errors.New("NSX client is not set")
+	}
+	raw, err := json.Marshal(ler)
+	if err != nil {
+		return err
+	}
+	group.Description = String(string(raw))
+	group.Tags = []model.Tag{{Scope: String(TagScopeHeartbeatHolder), Tag: String(ler.HolderIdentity)}}
+	updated, err := l.groups.Update(heartbeatDomain, l.groupID, *group)
+	if err != nil {
+		return nsxutil.TransNSXError(err)
+	}
+	l.group = &updated
+	return nil
+}
+
+// RecordEvent logs the changes of the active NSX Operator, there is no object to record them on in all the clusters.
+func (l *HeartbeatLock) RecordEvent(s string) {
+	log.Info("NSX Operator heartbeat", "event", s, "identity", l.identity)
+}
+
+// Identity returns the identity of the NSX Operator.
+func (l *HeartbeatLock) Identity() string {
+	return l.identity
+}
+
+// Describe returns the path of the Group.
+func (l *HeartbeatLock) Describe() string {
+	return fmt.Sprintf("/infra/domains/%s/groups/%s", heartbeatDomain, l.groupID)
+}
@@ -0,0 +1,123 @@
+package common
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/domains"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/ratelimiter"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// fakeHeartbeatGroups rejects the updates of the Group with a stale revision like NSX.
+type fakeHeartbeatGroups struct {
+	group *model.Group
+}
+
+func (f *fakeHeartbeatGroups) Get(domainId string, groupId string) (model.Group, error) {
+	if f.group == nil {
+		return model.Group{}, vapierrors.NotFound{}
+	}
+	return *f.group, nil
+}
+
+func (f *fakeHeartbeatGroups) Update(domainId string, groupId string, group model.Group) (model.Group, error) {
+	if f.group != nil && (group.Revision == nil || *group.Revision != *f.group.Revision) {
+		return model.Group{}, vapierrors.ConcurrentChange{}
+	}
+	revision := int64(0)
+	if f.group != nil {
+		revision = *f.group.Revision + 1
+	}
+	group.Revision = &revision
+	f.group = &group
+	return group, nil
+}
+
+func TestHeartbeatLock(t *testing.T) {
+	groups := &fakeHeartbeatGroups{}
+	active, standby := NewHeartbeatLock("heartbeat", "cluster1/pod1"), NewHeartbeatLock("heartbeat", "cluster2/pod1")
+	ctx := context.TODO()
+	_, _, err := active.Get(ctx)
+	assert.EqualError(t, err, "NSX client is not set")
+	active.groups, standby.groups = groups, groups
+	assert.Equal(t, "cluster1/pod1", active.Identity())
+	assert.Equal(t, "/infra/domains/default/groups/heartbeat", active.Describe())
+
+	_, _, err = active.Get(ctx)
+	assert.True(t, apierrors.IsNotFound(err))
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	record := resourcelock.LeaderElectionRecord{HolderIdentity: "cluster1/pod1", LeaseDurationSeconds: 15, AcquireTime: now, RenewTime: now}
+	assert.Nil(t, active.Create(ctx, record))
+	assert.Equal(t, "cluster1/pod1", *groups.group.Tags[0].Tag)
+
+	got, raw, err := standby.Get(ctx)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, raw)
+	assert.Equal(t, record.HolderIdentity, got.HolderIdentity)
+	assert.True(t, record.RenewTime.Equal(&got.RenewTime))
+
+	// the active one renews the heartbeat, the standby one fails to update the heartbeat it read before
+	record.RenewTime = metav1.NewTime(now.Add(2 * time.Second))
+	assert.Nil(t, active.Update(ctx, record))
+	takeover := *got
+	takeover.HolderIdentity = "cluster2/pod1"
+	var conflict nsxutil.ConflictError
+	assert.ErrorAs(t, standby.Update(ctx, takeover), &conflict)
+
+	// the standby one takes over once it reads the heartbeat again
+	_, _, err = standby.Get(ctx)
+	assert.Nil(t, err)
+	assert.Nil(t, standby.Update(ctx, takeover))
+	got, _, err = active.Get(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "cluster2/pod1", got.HolderIdentity)
+}
+
+func TestHeartbeatLock_MutationsPaused(t *testing.T) {
+	var updates int
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/policy/api/v1/infra/domains/default/groups/heartbeat" && r.Method == http.MethodPut {
+			updates++
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+			return
+		}
+		w.Write([]byte(`{"healthy": true, "components_health": "POLICY:UP, SEARCH:UP, MANAGER:UP, NODE_MGMT:UP, UI:UP"}`))
+	}))
+	defer ts.Close()
+	cluster, err := nsx.NewCluster(nsx.NewConfig(strings.TrimPrefix(ts.URL, "https://"), "admin", "passw0rd", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, nil))
+	assert.Nil(t, err)
+	connector, _ := cluster.NewRestConnector()
+
+	nsxutil.SetMutationsPaused(true, "change freeze")
+	defer nsxutil.SetMutationsPaused(false, "")
+	lock := NewHeartbeatLock("heartbeat", "cluster1/pod1")
+	lock.groups = domains.NewGroupsClient(connector)
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	record := resourcelock.LeaderElectionRecord{HolderIdentity: "cluster1/pod1", LeaseDurationSeconds: 15, AcquireTime: now, RenewTime: now}
+	assert.Nil(t, lock.Create(context.TODO(), record))
+	record.RenewTime = metav1.NewTime(now.Add(2 * time.Second))
+	assert.Nil(t, lock.Update(context.TODO(), record))
+	assert.Equal(t, 2, updates)
+
+	// the other mutations are still paused
+	groups := domains.NewGroupsClient(connector)
+	_, err = groups.Update(heartbeatDomain, "other", model.Group{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, updates)
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"reflect"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// IndexKeyNSXID indexes the NSX resources by their IDs, e.g. the ones keyed by the UIDs of their CRs.
+	IndexKeyNSXID = "nsxID"
+	// IndexKeyNamespace indexes the NSX resources by the namespace they are tagged with.
+	IndexKeyNamespace = "namespace"
+	// IndexKeyTag indexes the NSX resources by all their tags, the index values are built by TagIndexValue.
+	IndexKeyTag = "tag"
+)
+
+// NewIndexer returns the indexer of a store, it indexes the NSX resources by indexers, usually the UID of the CR
+// controller reconciles, and by their IDs, namespaces and tags, so the stores look them up by any of these keys
+// without scanning all of them.
+func NewIndexer(keyFunc cache.KeyFunc, indexers cache.Indexers) cache.Indexer {
+	all := cache.Indexers{
+		IndexKeyNSXID:     nsxIDIndexFunc,
+		IndexKeyNamespace: namespaceIndexFunc,
+		IndexKeyTag:       tagIndexFunc,
+	}
+	for name, indexFunc := range indexers {
+		all[name] = indexFunc
+	}
+	return cache.NewIndexer(keyFunc, all)
+}
+
+// TagIndexValue returns the value of the tag of scope in the IndexKeyTag index.
+func TagIndexValue(scope, tag string) string {
+	return scope + "=" + tag
+}
+
+func nsxIDIndexFunc(obj interface{}) ([]string, error) {
+	field := structField(obj, "Id")
+	if !field.IsValid() || field.Kind() != reflect.Ptr || field.IsNil() || field.Elem().Kind() != reflect.String {
+		return nil, nil
+	}
+	return []string{field.Elem().String()}, nil
+}
+
+func namespaceIndexFunc(obj interface{}) ([]string, error) {
+	var namespaces []string
+	forEachTag(obj, func(scope, tag string) {
+		if scope == TagScopeNamespace {
+			namespaces = append(namespaces, tag)
+		}
+	})
+	return namespaces, nil
+}
+
+func tagIndexFunc(obj interface{}) ([]string, error) {
+	var values []string
+	forEachTag(obj, func(scope, tag string) {
+		values = append(values, TagIndexValue(scope, tag))
+	})
+	return values, nil
+}
+
+// forEachTag invokes f with the scope and the value of each tag of the NSX resource, the Policy and the MP resources
+// have different tag types.
+func forEachTag(obj interface{}, f func(scope, tag string)) {
+	tags := structField(obj, "Tags")
+	if !tags.IsValid() || tags.Kind() != reflect.Slice {
+		return
+	}
+	for i := 0; i < tags.Len(); i++ {
+		scope, tag := stringPtrField(tags.Index(i), "Scope"), stringPtrField(tags.Index(i), "Tag")
+		if scope != nil && tag != nil {
+			f(*scope, *tag)
+		}
+	}
+}
+
+func stringPtrField(value reflect.Value, name string) *string {
+	value = reflect.Indirect(value)
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	field := value.FieldByName(name)
+	if !field.IsValid() || field.Kind() != reflect.Ptr || field.IsNil() || field.Elem().Kind() != reflect.String {
+		return nil
+	}
+	s := field.Elem().String()
+	return &s
+}
+
+// GetByNSXID returns the NSX resource of id, or nil if it is not in the store.
+func (resourceStore *ResourceStore) GetByNSXID(id string) interface{} {
+	objs := resourceStore.GetByIndex(IndexKeyNSXID, id)
+	if len(objs) == 0 {
+		return nil
+	}
+	return objs[0]
+}
+
+// ListByNamespace returns the NSX resources tagged with namespace.
+func (resourceStore *ResourceStore) ListByNamespace(namespace string) []interface{} {
+	return resourceStore.GetByIndex(IndexKeyNamespace, namespace)
+}
+
+// ListByTag returns the NSX resources tagged with the tag of scope.
+func (resourceStore *ResourceStore) ListByTag(scope, tag string) []interface{} {
+	return resourceStore.GetByIndex(IndexKeyTag, TagIndexValue(scope, tag))
+}
@@ -0,0 +1,37 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	mpmodel "github.com/vmware/vsphere-automation-sdk-go/services/nsxt-mp/nsx/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNewIndexer(t *testing.T) {
+	store := &ResourceStore{Indexer: NewIndexer(keyFunc, cache.Indexers{TagScopeSecurityPolicyCRUID: indexFunc}),
+		BindingType: model.RuleBindingType()}
+	id1, id2 := "rule1", "rule2"
+	scopeNamespace, scopeUID := TagScopeNamespace, TagScopeSecurityPolicyCRUID
+	ns1, ns2, uid1, uid2 := "ns1", "ns2", "uid1", "uid2"
+	assert.Nil(t, store.Add(model.Rule{Id: &id1, Tags: []model.Tag{{Scope: &scopeNamespace, Tag: &ns1}, {Scope: &scopeUID, Tag: &uid1}}}))
+	assert.Nil(t, store.Add(model.Rule{Id: &id2, Tags: []model.Tag{{Scope: &scopeNamespace, Tag: &ns2}, {Scope: &scopeUID, Tag: &uid2}}}))
+
+	assert.Equal(t, id1, *store.GetByNSXID(id1).(model.Rule).Id)
+	assert.Nil(t, store.GetByNSXID("rule3"))
+	assert.Len(t, store.ListByNamespace(ns2), 1)
+	assert.Equal(t, id2, *store.ListByNamespace(ns2)[0].(model.Rule).Id)
+	assert.Empty(t, store.ListByNamespace("ns3"))
+	assert.Len(t, store.ListByTag(TagScopeSecurityPolicyCRUID, uid1), 1)
+	assert.Equal(t, id1, *store.ListByTag(TagScopeSecurityPolicyCRUID, uid1)[0].(model.Rule).Id)
+	// the indexers of the store are kept
+	assert.Len(t, store.GetByIndex(TagScopeSecurityPolicyCRUID, uid2), 1)
+
+	// the tags of the MP resources are indexed too
+	name := "pi1"
+	pi := mpmodel.PrincipalIdentity{Id: &name, Tags: []mpmodel.Tag{{Scope: &scopeNamespace, Tag: &ns1}}}
+	namespaces, err := namespaceIndexFunc(pi)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{ns1}, namespaces)
+}
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+)
+
+// StoreInvalidation is an NSX resource changed out of band, i.e. not by NSX Operator, which a resync applied to its
+// store.
+type StoreInvalidation struct {
+	ResourceType string
+	Tags         []model.Tag
+}
+
+// storeInvalidationHandlers are invoked with the NSX resources changed out of band.
+var storeInvalidationHandlers = struct {
+	handlers []func(StoreInvalidation)
+	sync.RWMutex
+}{}
+
+// OnStoreInvalidation registers handler to be invoked with the NSX resources changed out of band found by the resyncs
+// of the stores, e.g. to reconcile the CRs they are tagged with.
+func OnStoreInvalidation(handler func(StoreInvalidation)) {
+	storeInvalidationHandlers.Lock()
+	defer storeInvalidationHandlers.Unlock()
+	storeInvalidationHandlers.handlers = append(storeInvalidationHandlers.handlers, handler)
+}
+
+// invalidateStore invokes the handlers with the NSX resource applied to the store of resourceTypeValue by a resync,
+// unless it was last modified by NSX Operator itself.
+func (service *Service) invalidateStore(resourceTypeValue string, entity *data.StructValue) {
+	if user := stringField(entity, "_last_modified_user"); user != "" {
+		nsxConfig := service.NSXClient.NsxConfig.NsxConfig
+		if user == nsxConfig.NsxApiUser || user == nsxConfig.PrincipalIdentity {
+			return
+		}
+	}
+	invalidation := StoreInvalidation{ResourceType: resourceTypeValue, Tags: entityTags(entity)}
+	storeInvalidationHandlers.RLock()
+	defer storeInvalidationHandlers.RUnlock()
+	for _, handler := range storeInvalidationHandlers.handlers {
+		handler(invalidation)
+	}
+}
+
+// stringField returns the string field of the NSX resource, empty if it is unset.
+func stringField(entity *data.StructValue, name string) string {
+	field, err := entity.Field(name)
+	if err != nil {
+		return ""
+	}
+	if optional, ok := field.(*data.OptionalValue); ok {
+		field = optional.Value()
+	}
+	if value, ok := field.(*data.StringValue); ok {
+		return value.Value()
+	}
+	return ""
+}
+
+// entityTags returns the tags of the NSX resource.
+func entityTags(entity *data.StructValue) []model.Tag {
+	field, err := entity.Field("tags")
+	if err != nil {
+		return nil
+	}
+	if optional, ok := field.(*data.OptionalValue); ok {
+		field = optional.Value()
+	}
+	list, ok := field.(*data.ListValue)
+	if !ok {
+		return nil
+	}
+	var tags []model.Tag
+	for _, element := range list.List() {
+		tag, ok := element.(*data.StructValue)
+		if !ok {
+			continue
+		}
+		scope, value := stringField(tag, "scope"), stringField(tag, "tag")
+		tags = append(tags, model.Tag{Scope: &scope, Tag: &value})
+	}
+	return tags
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+)
+
+func TestInvalidateStore(t *testing.T) {
+	defer func() { storeInvalidationHandlers.handlers = nil }()
+	var invalidations []StoreInvalidation
+	OnStoreInvalidation(func(invalidation StoreInvalidation) {
+		invalidations = append(invalidations, invalidation)
+	})
+	cf := &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{NsxApiUser: "admin"}, CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}}
+	service := &Service{NSXClient: &nsx.Client{NsxConfig: cf}, NSXConfig: cf}
+
+	ruleWithUser := func(user string) *data.StructValue {
+		id, scope, tag := "rule1", TagScopeNamespace, "ns1"
+		value, errs := NewConverter().ConvertToVapi(model.Rule{Id: &id, LastModifiedUser: &user, Tags: []model.Tag{{Scope: &scope, Tag: &tag}}},
+			model.RuleBindingType())
+		assert.Nil(t, errs)
+		return value.(*data.StructValue)
+	}
+	service.invalidateStore(ResourceTypeRule, ruleWithUser("admin"))
+	assert.Empty(t, invalidations)
+
+	service.invalidateStore(ResourceTypeRule, ruleWithUser("someone"))
+	assert.Len(t, invalidations, 1)
+	assert.Equal(t, ResourceTypeRule, invalidations[0].ResourceType)
+	assert.Len(t, invalidations[0].Tags, 1)
+	assert.Equal(t, TagScopeNamespace, *invalidations[0].Tags[0].Scope)
+	assert.Equal(t, "ns1", *invalidations[0].Tags[0].Tag)
+}
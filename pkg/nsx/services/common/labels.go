@@ -0,0 +1,83 @@
+package common
+
+import (
+	"context"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+// AppendLabelTags appends the labels of the Namespace and the object with the keys configured to be propagated as
+// tags, the object labels take precedence over the Namespace labels.
+func (service *Service) AppendLabelTags(tags []model.Tag, namespace string, labels map[string]string) []model.Tag {
+	keys := service.NSXConfig.LabelTagKeys
+	if len(keys) == 0 {
+		return tags
+	}
+	merged := map[string]string{}
+	if service.Client != nil {
+		ns := &v1.Namespace{}
+		if err := service.Client.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+			log.Error(err, "failed to get Namespace labels", "Namespace", namespace)
+		}
+		for k, v := range ns.Labels {
+			merged[k] = v
+		}
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return appendLabelTags(tags, merged, keys, service.NSXConfig.GetLabelTagOverflowPolicy())
+}
+
+// labelTagScope returns the tag scope of the label key, the prefix of the key is dropped if it is too long.
+func labelTagScope(key string) string {
+	if len(key) <= MaxTagScopeLength {
+		return key
+	}
+	key = key[strings.LastIndex(key, "/")+1:]
+	if len(key) > MaxTagScopeLength {
+		key = key[:MaxTagScopeLength]
+	}
+	return key
+}
+
+// appendLabelTags appends the labels with the keys as tags in the order of the keys. NSX allows at most MaxTagsCount
+// tags, the labels beyond it are dropped, or merged into one tag with the merge policy.
+func appendLabelTags(tags []model.Tag, labels map[string]string, keys []string, policy string) []model.Tag {
+	var labelTags []model.Tag
+	for _, key := range keys {
+		if value, ok := labels[key]; ok {
+			labelTags = append(labelTags, model.Tag{Scope: String(labelTagScope(key)), Tag: String(value)})
+		}
+	}
+	free := MaxTagsCount - len(tags)
+	if len(labelTags) <= free {
+		return append(tags, labelTags...)
+	}
+	if free <= 0 {
+		log.Info("no tag left for the labels", "labels", len(labelTags))
+		return tags
+	}
+	if policy != config.LabelTagOverflowPolicyMerge {
+		log.Info("dropped the labels beyond the NSX tag limit", "dropped", len(labelTags)-free)
+		return append(tags, labelTags[:free]...)
+	}
+	tags = append(tags, labelTags[:free-1]...)
+	var overflow []string
+	length := -1
+	for _, tag := range labelTags[free-1:] {
+		label := *tag.Scope + "=" + *tag.Tag
+		if length+1+len(label) > MaxTagLength {
+			log.Info("dropped the labels beyond the overflow tag", "merged", len(overflow))
+			break
+		}
+		length += 1 + len(label)
+		overflow = append(overflow, label)
+	}
+	return append(tags, model.Tag{Scope: String(TagScopeOverflowLabels), Tag: String(strings.Join(overflow, ","))})
+}
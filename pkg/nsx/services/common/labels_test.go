@@ -0,0 +1,65 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestService_AppendLabelTags(t *testing.T) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"team": "net", "env": "prod", "owner": "alice"}}}
+	service := &Service{
+		Client:    fake.NewClientBuilder().WithObjects(ns).Build(),
+		NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{}},
+	}
+	tags := []model.Tag{{Scope: String(TagScopeCluster), Tag: String("k8scl-one")}}
+
+	// nothing is propagated without the keys configured
+	assert.Equal(t, tags, service.AppendLabelTags(tags, "ns1", map[string]string{"app": "web"}))
+
+	service.NSXConfig.LabelTagKeys = []string{"app", "env", "team"}
+	assert.Equal(t, []model.Tag{
+		{Scope: String(TagScopeCluster), Tag: String("k8scl-one")},
+		{Scope: String("app"), Tag: String("web")},
+		{Scope: String("env"), Tag: String("dev")},
+		{Scope: String("team"), Tag: String("net")},
+	}, service.AppendLabelTags(tags, "ns1", map[string]string{"app": "web", "env": "dev"}))
+}
+
+func TestAppendLabelTags(t *testing.T) {
+	var keys []string
+	labels := map[string]string{}
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("label%d", i)
+		keys = append(keys, key)
+		labels[key] = fmt.Sprintf("value%d", i)
+	}
+	tags := make([]model.Tag, MaxTagsCount-3)
+
+	dropped := appendLabelTags(tags, labels, keys, config.LabelTagOverflowPolicyDrop)
+	assert.Equal(t, MaxTagsCount, len(dropped))
+	assert.Equal(t, "label2", *dropped[MaxTagsCount-1].Scope)
+
+	merged := appendLabelTags(tags, labels, keys, config.LabelTagOverflowPolicyMerge)
+	assert.Equal(t, MaxTagsCount, len(merged))
+	assert.Equal(t, "label1", *merged[MaxTagsCount-2].Scope)
+	assert.Equal(t, TagScopeOverflowLabels, *merged[MaxTagsCount-1].Scope)
+	assert.Equal(t, "label2=value2,label3=value3,label4=value4", *merged[MaxTagsCount-1].Tag)
+
+	// no label is appended if the tags are full
+	full := make([]model.Tag, MaxTagsCount)
+	assert.Equal(t, full, appendLabelTags(full, labels, keys, config.LabelTagOverflowPolicyMerge))
+}
+
+func TestLabelTagScope(t *testing.T) {
+	assert.Equal(t, "app.kubernetes.io/name", labelTagScope("app.kubernetes.io/name"))
+	assert.Equal(t, "name", labelTagScope(strings.Repeat("a", MaxTagScopeLength)+"/name"))
+}
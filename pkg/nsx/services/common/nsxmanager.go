@@ -0,0 +1,39 @@
+package common
+
+import (
+	"context"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SelectNSXManager returns the name of the additional NSX manager whose Namespace selector selects the Namespace, or
+// "" if the Namespace is served by the default NSX manager. The NSX managers are matched in the order of their names
+// so that a Namespace selected by several of them is always served by the same one.
+func (service *Service) SelectNSXManager(namespace string) (string, error) {
+	if len(service.NSXConfig.NSXManagers) == 0 {
+		return "", nil
+	}
+	ns := &v1.Namespace{}
+	if err := service.Client.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(service.NSXConfig.NSXManagers))
+	for name := range service.NSXConfig.NSXManagers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// the selectors are validated when the config is loaded
+		selector, err := labels.Parse(service.NSXConfig.NSXManagers[name].NamespaceSelector)
+		if err != nil {
+			return "", err
+		}
+		if selector.Matches(labels.Set(ns.Labels)) {
+			return name, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestService_SelectNSXManager(t *testing.T) {
+	service := &Service{
+		Client: fake.NewClientBuilder().WithObjects(
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-a", Labels: map[string]string{"site": "a"}}},
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-b", Labels: map[string]string{"site": "b", "tier": "gold"}}},
+		).Build(),
+		NSXConfig: &config.NSXOperatorConfig{},
+	}
+
+	// all the Namespaces are served by the default NSX manager without additional ones
+	name, err := service.SelectNSXManager("ns-b")
+	assert.Nil(t, err)
+	assert.Equal(t, "", name)
+
+	service.NSXConfig.NSXManagers = map[string]*config.NSXManagerConfig{
+		"site-b": {Name: "site-b", NamespaceSelector: "site=b"},
+		"gold":   {Name: "gold", NamespaceSelector: "tier=gold"},
+	}
+	name, err = service.SelectNSXManager("ns-a")
+	assert.Nil(t, err)
+	assert.Equal(t, "", name)
+	name, err = service.SelectNSXManager("ns-b")
+	assert.Nil(t, err)
+	assert.Equal(t, "gold", name)
+
+	_, err = service.SelectNSXManager("ns-c")
+	assert.NotNil(t, err)
+}
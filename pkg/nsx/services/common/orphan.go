@@ -0,0 +1,101 @@
+package common
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// OrphanedResource is an NSX resource whose CR no longer exists, which the last run of a garbage collector didn't
+// delete, e.g. in dry run or because the run was aborted.
+type OrphanedResource struct {
+	// GarbageCollector is the resource type of the garbage collector which found it, e.g. subnet.
+	GarbageCollector string
+	// ResourceType is the NSX resource type, e.g. VpcSubnet.
+	ResourceType string
+	Path         string
+	OwnerUID     string
+	// CreateTime is zero if NSX didn't report it.
+	CreateTime time.Time
+	// Tags are in the form of scope=tag.
+	Tags []string
+}
+
+// gcOrphans are the NSX resources orphaned found by the last run of each garbage collector, keyed by resource type.
+var gcOrphans = struct {
+	orphans map[string][]OrphanedResource
+	sync.Mutex
+}{orphans: map[string][]OrphanedResource{}}
+
+func setOrphanedResources(gcResourceType string, orphans []OrphanedResource) {
+	gcOrphans.Lock()
+	defer gcOrphans.Unlock()
+	gcOrphans.orphans[gcResourceType] = orphans
+}
+
+// OrphanedResources returns the NSX resources orphaned found by the last runs of the garbage collectors, sorted by
+// garbage collector and path.
+func OrphanedResources() []OrphanedResource {
+	gcOrphans.Lock()
+	defer gcOrphans.Unlock()
+	orphans := []OrphanedResource{}
+	for _, resources := range gcOrphans.orphans {
+		orphans = append(orphans, resources...)
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].GarbageCollector != orphans[j].GarbageCollector {
+			return orphans[i].GarbageCollector < orphans[j].GarbageCollector
+		}
+		return orphans[i].Path < orphans[j].Path
+	})
+	return orphans
+}
+
+// byIndexer looks up the NSX resources of a store by index, the ResourceStores implement it with their Indexer.
+type byIndexer interface {
+	ByIndex(indexName, indexedValue string) ([]interface{}, error)
+}
+
+// findOrphanedResources returns the NSX resources in the stores tagged with the UIDs of the CRs which no longer exist,
+// keyed by the scope of the tag of the UIDs.
+func findOrphanedResources(gcResourceType string, orphans map[string]sets.String) []OrphanedResource {
+	storeDumps.RLock()
+	defer storeDumps.RUnlock()
+	var resources []OrphanedResource
+	for resourceType, stores := range storeDumps.stores {
+		for _, store := range stores {
+			indexer, ok := store.(byIndexer)
+			if !ok {
+				continue
+			}
+			for scope, uids := range orphans {
+				for uid := range uids {
+					objs, err := indexer.ByIndex(IndexKeyTag, TagIndexValue(scope, uid))
+					if err != nil {
+						// the store is not indexed by tag
+						break
+					}
+					for _, obj := range objs {
+						resources = append(resources, orphanedResource(gcResourceType, resourceType, uid, obj))
+					}
+				}
+			}
+		}
+	}
+	return resources
+}
+
+func orphanedResource(gcResourceType, resourceType, uid string, obj interface{}) OrphanedResource {
+	resource := OrphanedResource{GarbageCollector: gcResourceType, ResourceType: resourceType, Path: resourceID(obj), OwnerUID: uid}
+	if field := structField(obj, "CreateTime"); field.IsValid() && field.Kind() == reflect.Ptr && !field.IsNil() &&
+		field.Elem().Kind() == reflect.Int64 {
+		resource.CreateTime = time.UnixMilli(field.Elem().Int())
+	}
+	forEachTag(obj, func(scope, tag string) {
+		resource.Tags = append(resource.Tags, TagIndexValue(scope, tag))
+	})
+	return resource
+}
@@ -0,0 +1,50 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestOrphanedResources(t *testing.T) {
+	defer func() {
+		storeDumps.stores = map[string][]lister{}
+		gcOrphans.orphans = map[string][]OrphanedResource{}
+		gcStatuses.statuses = map[string]GCStatus{}
+	}()
+	cf := &config.NSXOperatorConfig{K8sConfig: &config.K8sConfig{}, NsxConfig: &config.NsxConfig{}}
+	store := &ResourceStore{Indexer: NewIndexer(keyFunc, cache.Indexers{}), BindingType: model.RuleBindingType()}
+	registerStoreDump(ResourceTypeRule, store)
+	id1, id2, path1 := "rule1", "rule2", "/infra/domains/default/security-policies/sp1/rules/rule1"
+	scope, uid1, uid2 := TagScopeSecurityPolicyCRUID, "uid-1", "uid-2"
+	createTime := int64(1700000000000)
+	assert.Nil(t, store.Add(model.Rule{Id: &id1, Path: &path1, CreateTime: &createTime, Tags: []model.Tag{{Scope: &scope, Tag: &uid1}}}))
+	assert.Nil(t, store.Add(model.Rule{Id: &id2, Tags: []model.Tag{{Scope: &scope, Tag: &uid2}}}))
+
+	run := StartGCRun(cf, "securitypolicy")
+	run.Orphaned(scope, sets.NewString(uid1))
+	run.Finish(nil)
+	orphans := OrphanedResources()
+	assert.Len(t, orphans, 1)
+	assert.Equal(t, OrphanedResource{
+		GarbageCollector: "securitypolicy",
+		ResourceType:     ResourceTypeRule,
+		Path:             path1,
+		OwnerUID:         uid1,
+		CreateTime:       orphans[0].CreateTime,
+		Tags:             []string{"nsx-op/security_policy_cr_uid=uid-1"},
+	}, orphans[0])
+	assert.Equal(t, createTime, orphans[0].CreateTime.UnixMilli())
+
+	// the orphans of a failed run are kept
+	StartGCRun(cf, "securitypolicy").Finish(assert.AnError)
+	assert.Len(t, OrphanedResources(), 1)
+	// no NSX resources are left
+	StartGCRun(cf, "securitypolicy").Finish(nil)
+	assert.Empty(t, OrphanedResources())
+}
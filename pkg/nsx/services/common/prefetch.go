@@ -0,0 +1,107 @@
+package common
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+)
+
+// DefaultPrefetchWorkers is the default number of the NSX resource types listed in parallel by PrefetchResources.
+const DefaultPrefetchWorkers = 8
+
+// prefetchKey identifies the listing of a query by an NSX client, the services of different NSX managers list the
+// same queries.
+type prefetchKey struct {
+	client *nsx.Client
+	query  nsx.SearchQuery
+}
+
+// prefetch is the listing of the NSX resources of a query started by PrefetchResources, done is closed once the
+// listing completes.
+type prefetch struct {
+	done     chan struct{}
+	entities []*data.StructValue
+	err      error
+}
+
+// prefetches are the listings started by PrefetchResources which are not taken by the stores yet.
+var prefetches = struct {
+	listings map[prefetchKey]*prefetch
+	sync.Mutex
+}{listings: map[prefetchKey]*prefetch{}}
+
+// PrefetchResources lists the Policy resources of resourceTypes tagged with the cluster in the background, at most
+// workers of them at a time. The services are initialized one after another, the stores of the later ones then take
+// the NSX resources listed in parallel instead of waiting for their own listings, see searchAll. The NSX resources of
+// the listings which are not taken are released by ReleasePrefetches.
+func (service *Service) PrefetchResources(workers int, resourceTypes ...string) {
+	if workers <= 0 {
+		workers = DefaultPrefetchWorkers
+	}
+	slots := make(chan struct{}, workers)
+	prefetches.Lock()
+	defer prefetches.Unlock()
+	for _, resourceType := range resourceTypes {
+		key := prefetchKey{client: service.NSXClient, query: service.resourceQuery(resourceType, true)}
+		if _, ok := prefetches.listings[key]; ok {
+			continue
+		}
+		p := &prefetch{done: make(chan struct{})}
+		prefetches.listings[key] = p
+		go func(resourceType string) {
+			defer close(p.done)
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			start := time.Now()
+			_, p.err = service.NSXClient.SearchAll(key.query, func(entity *data.StructValue) error {
+				p.entities = append(p.entities, entity)
+				return nil
+			})
+			if p.err != nil {
+				log.Error(p.err, "failed to prefetch NSX resources", "resourceType", resourceType)
+				return
+			}
+			log.Info("prefetched NSX resources", "resourceType", resourceType, "count", len(p.entities), "duration", time.Since(start))
+		}(resourceType)
+	}
+}
+
+// ReleasePrefetches releases the NSX resources of the listings started by PrefetchResources which are not taken by
+// the stores, e.g. of the disabled controllers, once all the services are initialized.
+func ReleasePrefetches() {
+	prefetches.Lock()
+	defer prefetches.Unlock()
+	prefetches.listings = map[prefetchKey]*prefetch{}
+}
+
+func takePrefetch(client *nsx.Client, query nsx.SearchQuery) *prefetch {
+	prefetches.Lock()
+	defer prefetches.Unlock()
+	key := prefetchKey{client: client, query: query}
+	p, ok := prefetches.listings[key]
+	if !ok {
+		return nil
+	}
+	delete(prefetches.listings, key)
+	return p
+}
+
+// searchAll is SearchAll of the NSX client of the service, the NSX resources listed by PrefetchResources for query
+// are passed to into instead once the listing completes. They are listed again if the listing failed.
+func (service *Service) searchAll(query nsx.SearchQuery, into func(*data.StructValue) error) (uint64, error) {
+	if p := takePrefetch(service.NSXClient, query); p != nil {
+		<-p.done
+		if p.err == nil {
+			for i, entity := range p.entities {
+				if err := into(entity); err != nil {
+					return uint64(i), err
+				}
+			}
+			return uint64(len(p.entities)), nil
+		}
+	}
+	return service.NSXClient.SearchAll(query, into)
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+)
+
+func TestPrefetchResources(t *testing.T) {
+	defer ReleasePrefetches()
+	queryClient := &fakeModifiedQueryClient{results: []*data.StructValue{ruleValue(t, "rule1", 0, false)}}
+	cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}, NsxConfig: &config.NsxConfig{}}
+	service := &Service{NSXClient: &nsx.Client{QueryClient: queryClient, NsxConfig: cf}, NSXConfig: cf}
+	// one worker, the fake query client is not safe for concurrent use
+	service.PrefetchResources(1, ResourceTypeRule, ResourceTypeGroup)
+	service.PrefetchResources(1, ResourceTypeRule)
+
+	// the store takes the prefetched NSX resources
+	store := &ResourceStore{Indexer: cache.NewIndexer(keyFunc, cache.Indexers{}), BindingType: model.RuleBindingType()}
+	count, err := service.SearchResource(ResourceTypeRule, store)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), count)
+	assert.NotNil(t, store.GetByKey("rule1"))
+	groups := takePrefetch(service.NSXClient, service.resourceQuery(ResourceTypeGroup, true))
+	<-groups.done
+	assert.Len(t, queryClient.queries, 2)
+
+	// the NSX resources are listed again once the prefetched ones are taken
+	_, err = service.SearchResource(ResourceTypeRule, store)
+	assert.Nil(t, err)
+	assert.Len(t, queryClient.queries, 3)
+
+	service.PrefetchResources(1, ResourceTypeSecurityPolicy)
+	ReleasePrefetches()
+	assert.Nil(t, takePrefetch(service.NSXClient, service.resourceQuery(ResourceTypeSecurityPolicy, true)))
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"context"
+	"time"
+)
+
+// RealizationCheckInterval is the interval the realization of a resource is checked while waiting for it.
+var RealizationCheckInterval = 2 * time.Second
+
+// WaitForRealization checks whether NSX realizes the resource until it is realized, the check fails, ctx is done or
+// the realization timeout of the config passes. The resource is checked once if no realization timeout is configured,
+// so that the caller checks it again later, e.g. by requeueing the CR.
+func (service *Service) WaitForRealization(ctx context.Context, isRealized func() (bool, error)) (bool, error) {
+	var timeout time.Duration
+	if nsxConfig := service.NSXConfig; nsxConfig != nil && nsxConfig.NsxConfig != nil {
+		timeout = time.Duration(nsxConfig.RealizationTimeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(RealizationCheckInterval)
+	defer ticker.Stop()
+	for {
+		realized, err := isRealized()
+		if realized || err != nil {
+			return realized, err
+		}
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestService_WaitForRealization(t *testing.T) {
+	RealizationCheckInterval = 10 * time.Millisecond
+	defer func() { RealizationCheckInterval = 2 * time.Second }()
+	service := &Service{NSXConfig: &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}}}
+	checks := 0
+	realizedAt := func(n int) func() (bool, error) {
+		checks = 0
+		return func() (bool, error) {
+			checks++
+			return checks >= n, nil
+		}
+	}
+
+	// checked once without realization timeout
+	realized, err := service.WaitForRealization(context.TODO(), realizedAt(3))
+	assert.Nil(t, err)
+	assert.False(t, realized)
+	assert.Equal(t, 1, checks)
+
+	service.NSXConfig.RealizationTimeout = 10
+	realized, err = service.WaitForRealization(context.TODO(), realizedAt(3))
+	assert.Nil(t, err)
+	assert.True(t, realized)
+	assert.Equal(t, 3, checks)
+
+	// the context of the caller bounds the wait
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	realized, err = service.WaitForRealization(ctx, realizedAt(1000))
+	assert.Nil(t, err)
+	assert.False(t, realized)
+	assert.Less(t, checks, 1000)
+
+	_, err = service.WaitForRealization(context.TODO(), func() (bool, error) { return false, errors.New("failed to realize") })
+	assert.Equal(t, errors.New("failed to realize"), err)
+}
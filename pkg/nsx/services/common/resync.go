@@ -0,0 +1,181 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+)
+
+const (
+	// StoreResyncInterval is the interval to resync the stores with the NSX resources modified since their last sync.
+	StoreResyncInterval = 5 * time.Minute
+	// storeResyncOverlap is searched again before the start of the last sync, the resources indexed late by the NSX
+	// search or modified while the clocks of NSX Operator and NSX are skewed are then not missed.
+	storeResyncOverlap = time.Minute
+)
+
+// resyncer is implemented by the stores which only apply the resources found by a resync if they are not older than
+// the ones they hold, the ResourceStores implement it.
+type resyncer interface {
+	ResyncResource(entity *data.StructValue) (bool, error)
+}
+
+// ResyncResource applies the resource found by a resync to the store, it is deleted if it is marked for delete. It is
+// skipped if the store holds a newer revision, e.g. written by NSX Operator after the search found it. It returns
+// whether the store changed.
+func (resourceStore *ResourceStore) ResyncResource(entity *data.StructValue) (bool, error) {
+	obj, errs := NewConverter().ConvertToGolang(entity, resourceStore.BindingType)
+	for _, err := range errs {
+		return false, err
+	}
+	existing, exists, err := resourceStore.Indexer.Get(obj)
+	if err != nil {
+		return false, err
+	}
+	if exists && revisionOf(existing) > revisionOf(obj) {
+		return false, nil
+	}
+	if markedForDelete(obj) {
+		if !exists {
+			return false, nil
+		}
+		return true, resourceStore.Delete(existing)
+	}
+	if c, ok := resourceStore.Indexer.(compacter); ok {
+		obj = c.compact(obj)
+	}
+	if exists && reflect.DeepEqual(existing, obj) {
+		return false, nil
+	}
+	return true, resourceStore.Add(obj)
+}
+
+// revisionOf returns the revision of the NSX resource, -1 if it has none.
+func revisionOf(obj interface{}) int64 {
+	field := structField(obj, "Revision")
+	if !field.IsValid() || field.Kind() != reflect.Ptr || field.IsNil() || field.Elem().Kind() != reflect.Int64 {
+		return -1
+	}
+	return field.Elem().Int()
+}
+
+// markedForDelete returns whether the NSX resource is being deleted.
+func markedForDelete(obj interface{}) bool {
+	field := structField(obj, "MarkedForDelete")
+	if !field.IsValid() || field.Kind() != reflect.Ptr || field.IsNil() || field.Elem().Kind() != reflect.Bool {
+		return false
+	}
+	return field.Elem().Bool()
+}
+
+func structField(obj interface{}, name string) reflect.Value {
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return value.FieldByName(name)
+}
+
+// storeResync is a store initialized by InitializeResourceStore and the time its last sync started.
+type storeResync struct {
+	service      *Service
+	resourceType string
+	store        Store
+	lastSync     time.Time
+}
+
+// storeResyncs are the stores resynced by ResyncStores and snapshotted by SaveStoreSnapshots.
+var storeResyncs = struct {
+	stores []*storeResync
+	sync.Mutex
+}{}
+
+func registerStoreResync(service *Service, resourceType string, store Store, lastSync time.Time) {
+	storeResyncs.Lock()
+	defer storeResyncs.Unlock()
+	for _, registered := range storeResyncs.stores {
+		if registered.store == store {
+			registered.lastSync = lastSync
+			return
+		}
+	}
+	storeResyncs.stores = append(storeResyncs.stores, &storeResync{service: service, resourceType: resourceType, store: store, lastSync: lastSync})
+}
+
+// SearchModifiedResource queries the resources of resourceTypeValue tagged with the cluster which were modified since
+// from nsx-t side and applies them to the store, it returns the number of the resources found and of the ones
+// changing the store. The handlers registered by OnStoreInvalidation are invoked with the ones changing the store
+// which were modified out of band.
+func (service *Service) SearchModifiedResource(resourceTypeValue string, store Store, since time.Time) (uint64, uint64, error) {
+	query := service.searchQuery(resourceTypeValue, store)
+	query.Query += fmt.Sprintf(" AND _last_modified_time:>=%d", since.UnixMilli())
+	changed := uint64(0)
+	count, err := service.NSXClient.SearchAll(query, func(entity *data.StructValue) error {
+		applied := true
+		var err error
+		if r, ok := store.(resyncer); ok {
+			applied, err = r.ResyncResource(entity)
+		} else {
+			err = store.TransResourceToStore(entity)
+		}
+		if err != nil || !applied {
+			return err
+		}
+		changed++
+		service.invalidateStore(resourceTypeValue, entity)
+		return nil
+	})
+	return count, changed, err
+}
+
+// ResyncStores applies the NSX resources modified since the last sync of each store to the stores, instead of listing
+// all the NSX resources again. The NSX resources deleted from NSX without being marked for delete first are not found
+// by the search, they are only removed from the stores by NSX Operator deleting them or restarting.
+func ResyncStores() {
+	storeResyncs.Lock()
+	stores := append([]*storeResync{}, storeResyncs.stores...)
+	storeResyncs.Unlock()
+
+	for _, s := range stores {
+		storeResyncs.Lock()
+		since := s.lastSync.Add(-storeResyncOverlap)
+		storeResyncs.Unlock()
+		start := time.Now()
+		count, changed, err := s.service.SearchModifiedResource(s.resourceType, s.store, since)
+		if err != nil {
+			log.Error(err, "failed to resync store", "resourceType", s.resourceType)
+			continue
+		}
+		storeResyncs.Lock()
+		s.lastSync = start
+		storeResyncs.Unlock()
+		log.V(1).Info("resynced store", "resourceType", s.resourceType, "modified", count, "changed", changed,
+			"duration", time.Since(start))
+	}
+}
+
+// StoreResyncer resyncs the stores every interval, it is added to the manager.
+type StoreResyncer struct {
+	Interval time.Duration
+}
+
+// Start resyncs the stores every interval until ctx is done.
+func (r StoreResyncer) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.Interval):
+			ResyncStores()
+		}
+	}
+}
+
+// NeedLeaderElection returns false, the stores of all the replicas are resynced.
+func (StoreResyncer) NeedLeaderElection() bool {
+	return false
+}
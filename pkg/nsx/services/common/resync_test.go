@@ -0,0 +1,86 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+)
+
+type fakeModifiedQueryClient struct {
+	queries []string
+	results []*data.StructValue
+}
+
+func (c *fakeModifiedQueryClient) List(query string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	c.queries = append(c.queries, query)
+	resultCount := int64(len(c.results))
+	return model.SearchResponse{Results: c.results, ResultCount: &resultCount}, nil
+}
+
+func ruleValue(t *testing.T, id string, revision int64, markedForDelete bool) *data.StructValue {
+	value, errs := NewConverter().ConvertToVapi(model.Rule{Id: &id, Revision: &revision, MarkedForDelete: &markedForDelete}, model.RuleBindingType())
+	assert.Nil(t, errs)
+	return value.(*data.StructValue)
+}
+
+func TestResyncResource(t *testing.T) {
+	store := &ResourceStore{Indexer: cache.NewIndexer(keyFunc, cache.Indexers{}), BindingType: model.RuleBindingType()}
+	id1, id2, revision := "rule1", "rule2", int64(3)
+	markedForDelete := false
+	assert.Nil(t, store.Add(model.Rule{Id: &id1, Revision: &revision, MarkedForDelete: &markedForDelete}))
+	assert.Nil(t, store.Add(model.Rule{Id: &id2, Revision: &revision, MarkedForDelete: &markedForDelete}))
+
+	for _, tc := range []struct {
+		name     string
+		id       string
+		entity   *data.StructValue
+		changed  bool
+		revision int64
+		exists   bool
+	}{
+		{"older revision", id1, ruleValue(t, id1, 2, false), false, 3, true},
+		{"same revision", id1, ruleValue(t, id1, 3, false), false, 3, true},
+		{"newer revision", id1, ruleValue(t, id1, 4, false), true, 4, true},
+		{"new resource", "rule3", ruleValue(t, "rule3", 0, false), true, 0, true},
+		{"marked for delete", id2, ruleValue(t, id2, 4, true), true, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			changed, err := store.ResyncResource(tc.entity)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.changed, changed)
+			obj := store.GetByKey(tc.id)
+			if !tc.exists {
+				assert.Nil(t, obj)
+				return
+			}
+			assert.Equal(t, tc.revision, *obj.(model.Rule).Revision)
+		})
+	}
+}
+
+func TestResyncStores(t *testing.T) {
+	defer func() { storeResyncs.stores = nil }()
+	queryClient := &fakeModifiedQueryClient{}
+	cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}}
+	service := &Service{NSXClient: &nsx.Client{QueryClient: queryClient, NsxConfig: cf}, NSXConfig: cf}
+	store := &ResourceStore{Indexer: cache.NewIndexer(keyFunc, cache.Indexers{}), BindingType: model.RuleBindingType()}
+	lastSync := time.UnixMilli(1700000000000)
+	registerStoreResync(service, ResourceTypeRule, store, lastSync)
+	registerStoreResync(service, ResourceTypeRule, store, lastSync)
+	assert.Len(t, storeResyncs.stores, 1)
+
+	queryClient.results = []*data.StructValue{ruleValue(t, "rule1", 0, false)}
+	ResyncStores()
+	assert.Equal(t, []string{"resource_type:Rule AND tags.scope:nsx-op\\/cluster AND tags.tag:k8scl-one AND _last_modified_time:>=1699999940000"},
+		queryClient.queries)
+	assert.NotNil(t, store.GetByKey("rule1"))
+	// the next resync searches the resources modified since this one started
+	assert.True(t, storeResyncs.stores[0].lastSync.After(lastSync))
+}
@@ -0,0 +1,274 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data/serializers/cleanjson"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// StoreSnapshotInterval is the interval to write the snapshots of the stores.
+	StoreSnapshotInterval = 5 * time.Minute
+	// storeSnapshotMaxAge is the age of the snapshots which are too old to be loaded, the stores are then listed from
+	// NSX before NSX Operator starts.
+	storeSnapshotMaxAge = 24 * time.Hour
+)
+
+// storeSnapshotDir is the directory the snapshots of the stores are written to and loaded from, empty if the stores
+// are not snapshotted.
+var storeSnapshotDir atomic.Value
+
+// SetStoreSnapshotDir sets the directory the snapshots of the stores are written to and loaded from, e.g. the mount
+// path of a PersistentVolumeClaim. The stores are not snapshotted if dir is empty.
+func SetStoreSnapshotDir(dir string) {
+	storeSnapshotDir.Store(dir)
+}
+
+func getStoreSnapshotDir() string {
+	dir, _ := storeSnapshotDir.Load().(string)
+	return dir
+}
+
+// storeSnapshot is the content of the snapshot file of a store.
+type storeSnapshot struct {
+	ResourceType string `json:"resourceType"`
+	// LastSync is the time the last sync of the store with NSX started, the NSX resources modified since are
+	// searched by the first resync after the snapshot is loaded.
+	LastSync  time.Time         `json:"lastSync"`
+	Resources []json.RawMessage `json:"resources"`
+}
+
+// snapshotter is implemented by the stores which can be snapshotted, the ResourceStores implement it.
+type snapshotter interface {
+	Snapshot() ([]json.RawMessage, error)
+	List() []interface{}
+	Get(obj interface{}) (interface{}, bool, error)
+	Delete(obj interface{}) error
+}
+
+// Snapshot returns the NSX resources of the store in the JSON format of the NSX API. The compact stores only snapshot
+// the fields of the CompactResources, their NSX resources are compared again once they are synced with NSX.
+func (resourceStore *ResourceStore) Snapshot() ([]json.RawMessage, error) {
+	encoder := cleanjson.NewDataValueToJsonEncoder()
+	objs := resourceStore.List()
+	resources := make([]json.RawMessage, 0, len(objs))
+	for _, obj := range objs {
+		obj = resourceStore.expand(obj)
+		value, errs := NewConverter().ConvertToVapi(reflect.Indirect(reflect.ValueOf(obj)).Interface(), resourceStore.BindingType)
+		for _, err := range errs {
+			return nil, err
+		}
+		resource, err := encoder.Encode(value)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, json.RawMessage(resource))
+	}
+	return resources, nil
+}
+
+// snapshotPath returns the path of the snapshot file of the store of resourceType, the stores of the same resource
+// type of the services of different NSX managers or clusters are written to different files.
+func (service *Service) snapshotPath(dir, resourceType string) string {
+	nsxConfig := service.NSXClient.NsxConfig
+	sum := sha256.Sum256([]byte(strings.Join(nsxConfig.NsxApiManagers, ",") + "/" + nsxConfig.NsxProject + "/" + nsxConfig.Cluster))
+	return filepath.Join(dir, fmt.Sprintf("%s-%x.json", resourceType, sum[:4]))
+}
+
+// loadStoreSnapshot loads the snapshot of the store of resourceTypeValue into the store if snapshots are enabled and
+// a recent one exists, it returns the NSX resources loaded and the time of the last sync of the snapshot.
+func (service *Service) loadStoreSnapshot(resourceTypeValue string, store Store) ([]interface{}, time.Time, bool) {
+	dir := getStoreSnapshotDir()
+	if dir == "" {
+		return nil, time.Time{}, false
+	}
+	indexer, ok := store.(snapshotter)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	path := service.snapshotPath(dir, resourceTypeValue)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Error(err, "failed to read store snapshot", "path", path)
+		}
+		return nil, time.Time{}, false
+	}
+	snapshot := storeSnapshot{}
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		log.Error(err, "failed to parse store snapshot", "path", path)
+		return nil, time.Time{}, false
+	}
+	if time.Since(snapshot.LastSync) > storeSnapshotMaxAge {
+		log.Info("ignore outdated store snapshot", "path", path, "lastSync", snapshot.LastSync)
+		return nil, time.Time{}, false
+	}
+	if err := loadResources(snapshot.Resources, store); err != nil {
+		log.Error(err, "failed to load store snapshot", "path", path)
+		// the store is listed from NSX instead
+		for _, obj := range indexer.List() {
+			indexer.Delete(obj)
+		}
+		return nil, time.Time{}, false
+	}
+	return indexer.List(), snapshot.LastSync, true
+}
+
+// loadResources decodes the NSX resources in the JSON format of the NSX API and adds them to the store.
+func loadResources(resources []json.RawMessage, store Store) error {
+	decoder := cleanjson.NewJsonToDataValueDecoder()
+	for _, resource := range resources {
+		jsonDecoder := json.NewDecoder(bytes.NewReader(resource))
+		jsonDecoder.UseNumber()
+		var raw interface{}
+		if err := jsonDecoder.Decode(&raw); err != nil {
+			return err
+		}
+		value, err := decoder.Decode(raw)
+		if err != nil {
+			return err
+		}
+		entity, ok := value.(*data.StructValue)
+		if !ok {
+			return fmt.Errorf("invalid NSX resource %s", resource)
+		}
+		if err := store.TransResourceToStore(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncStoreSnapshot lists the NSX resources of resourceTypeValue into the store loaded from its snapshot, the NSX
+// resources loaded which are not found anymore are removed unless they changed since they were loaded. The store is
+// resynced incrementally from the last sync of the snapshot if the NSX resources fail to be listed.
+func (service *Service) syncStoreSnapshot(resourceTypeValue string, store Store, loaded []interface{}) {
+	start := time.Now()
+	found := sets.NewString()
+	count, err := service.searchAll(service.searchQuery(resourceTypeValue, store), func(entity *data.StructValue) error {
+		found.Insert(entityID(entity))
+		if r, ok := store.(resyncer); ok {
+			_, err := r.ResyncResource(entity)
+			return err
+		}
+		return store.TransResourceToStore(entity)
+	})
+	if err != nil {
+		log.Error(err, "failed to sync store loaded from snapshot", "resourceType", resourceTypeValue)
+		return
+	}
+
+	indexer := store.(snapshotter)
+	removed := 0
+	for _, obj := range loaded {
+		if found.Has(resourceID(obj)) {
+			continue
+		}
+		if existing, exists, err := indexer.Get(obj); err == nil && exists && reflect.DeepEqual(existing, obj) {
+			if err := indexer.Delete(existing); err != nil {
+				log.Error(err, "failed to remove NSX resource not found from store", "resourceType", resourceTypeValue)
+				continue
+			}
+			removed++
+		}
+	}
+	registerStoreResync(service, resourceTypeValue, store, start)
+	log.Info("synced store loaded from snapshot", "resourceType", resourceTypeValue, "count", count, "removed", removed,
+		"duration", time.Since(start))
+}
+
+// entityID returns the path of the NSX resource, or its ID if it has no path, e.g. the MP resources.
+func entityID(entity *data.StructValue) string {
+	if path := stringField(entity, "path"); path != "" {
+		return path
+	}
+	return stringField(entity, "id")
+}
+
+// resourceID returns the path of the NSX resource, or its ID if it has no path, e.g. the MP resources.
+func resourceID(obj interface{}) string {
+	for _, name := range []string{"Path", "Id"} {
+		field := structField(obj, name)
+		if field.IsValid() && field.Kind() == reflect.Ptr && !field.IsNil() && field.Elem().Kind() == reflect.String {
+			return field.Elem().String()
+		}
+	}
+	return ""
+}
+
+// SaveStoreSnapshots writes the snapshots of the stores synced with NSX if snapshots are enabled, each one is written
+// to a temporary file renamed once it is complete.
+func SaveStoreSnapshots() {
+	dir := getStoreSnapshotDir()
+	if dir == "" {
+		return
+	}
+	storeResyncs.Lock()
+	stores := make([]storeResync, 0, len(storeResyncs.stores))
+	for _, s := range storeResyncs.stores {
+		stores = append(stores, *s)
+	}
+	storeResyncs.Unlock()
+
+	for _, s := range stores {
+		path := s.service.snapshotPath(dir, s.resourceType)
+		if err := saveStoreSnapshot(path, s); err != nil {
+			log.Error(err, "failed to write store snapshot", "path", path)
+		}
+	}
+}
+
+func saveStoreSnapshot(path string, s storeResync) error {
+	snapshotStore, ok := s.store.(snapshotter)
+	if !ok {
+		return nil
+	}
+	resources, err := snapshotStore.Snapshot()
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(storeSnapshot{ResourceType: s.resourceType, LastSync: s.lastSync, Resources: resources})
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// StoreSnapshotter writes the snapshots of the stores every interval, it is added to the manager. The last snapshots
+// are written once the NSX writes in flight are drained when the manager stops.
+type StoreSnapshotter struct {
+	Interval time.Duration
+}
+
+// Start writes the snapshots of the stores every interval until ctx is done.
+func (s StoreSnapshotter) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.Interval):
+			SaveStoreSnapshots()
+		}
+	}
+}
+
+// NeedLeaderElection returns false, the stores of all the replicas are snapshotted.
+func (StoreSnapshotter) NeedLeaderElection() bool {
+	return false
+}
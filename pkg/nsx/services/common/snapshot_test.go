@@ -0,0 +1,63 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+)
+
+func TestStoreSnapshot(t *testing.T) {
+	defer func() { storeResyncs.stores = nil }()
+	dir := t.TempDir()
+	SetStoreSnapshotDir(dir)
+	defer SetStoreSnapshotDir("")
+
+	queryClient := &fakeModifiedQueryClient{}
+	cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}, NsxConfig: &config.NsxConfig{NsxApiManagers: []string{"10.0.0.1"}}}
+	service := &Service{NSXClient: &nsx.Client{QueryClient: queryClient, NsxConfig: cf}, NSXConfig: cf}
+	newStore := func() *ResourceStore {
+		return &ResourceStore{Indexer: cache.NewIndexer(keyFunc, cache.Indexers{}), BindingType: model.RuleBindingType()}
+	}
+	store := newStore()
+	id1, id2, revision := "rule1", "rule2", int64(1)
+	assert.Nil(t, store.Add(model.Rule{Id: &id1, Revision: &revision}))
+	assert.Nil(t, store.Add(model.Rule{Id: &id2, Revision: &revision}))
+	lastSync := time.Now().Add(-time.Hour).Round(time.Second)
+	registerStoreResync(service, ResourceTypeRule, store, lastSync)
+	SaveStoreSnapshots()
+	files, _ := filepath.Glob(filepath.Join(dir, "Rule-*.json"))
+	assert.Len(t, files, 1)
+
+	// the store is loaded from the snapshot, then the NSX resources not found in NSX anymore are removed
+	storeResyncs.stores = nil
+	restarted := newStore()
+	queryClient.results = []*data.StructValue{ruleValue(t, id1, 1, false)}
+	loaded, snapshotLastSync, ok := service.loadStoreSnapshot(ResourceTypeRule, restarted)
+	assert.True(t, ok)
+	assert.Len(t, loaded, 2)
+	assert.True(t, lastSync.Equal(snapshotLastSync))
+	service.syncStoreSnapshot(ResourceTypeRule, restarted, loaded)
+	assert.NotNil(t, restarted.GetByKey(id1))
+	assert.Nil(t, restarted.GetByKey(id2))
+	assert.True(t, storeResyncs.stores[0].lastSync.After(lastSync))
+
+	// an invalid snapshot is ignored
+	assert.Nil(t, os.WriteFile(files[0], []byte(`{"lastSync":"`+time.Now().Format(time.RFC3339)+`","resources":[1]}`), 0o600))
+	invalid := newStore()
+	_, _, ok = service.loadStoreSnapshot(ResourceTypeRule, invalid)
+	assert.False(t, ok)
+	assert.Empty(t, invalid.List())
+	// an outdated snapshot is ignored
+	assert.Nil(t, os.WriteFile(files[0], []byte(`{"lastSync":"2020-01-01T00:00:00Z","resources":[]}`), 0o600))
+	_, _, ok = service.loadStoreSnapshot(ResourceTypeRule, newStore())
+	assert.False(t, ok)
+}
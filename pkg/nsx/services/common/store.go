@@ -2,19 +2,20 @@ package common
 
 import (
 	"fmt"
-	"strconv"
+	"net/http"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
-	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
 	"github.com/vmware/vsphere-automation-sdk-go/runtime/bindings"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
-	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
 
-	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
 )
 
 const (
@@ -99,79 +100,102 @@ func (resourceStore *ResourceStore) IsPolicyAPI() bool {
 	return true
 }
 
+// TransError translates the error of NSX rejecting a search page exceeding its max size to util.PageMaxError.
 func TransError(err error) error {
-	switch err.(type) {
-	case vapierrors.ServiceUnavailable:
-		vApiError, _ := err.(vapierrors.ServiceUnavailable)
-		if vApiError.Data == nil {
-			return err
-		}
-		dataError, errs := NewConverter().ConvertToGolang(vApiError.Data, model.ApiErrorBindingType())
-		if len(errs) > 0 {
-			return err
-		}
-		apiError := dataError.(model.ApiError)
-		if *apiError.ErrorCode == int64(60576) {
-			return nsxutil.PageMaxError{Desc: "page max overflow"}
-		}
-	default:
-		return err
+	return nsx.TransSearchError(err)
+}
+
+// storeSyncs counts the stores which are being initialized, the pod is not ready before they are synced with NSX.
+var storeSyncs = struct {
+	pending map[string]int
+	sync.Mutex
+}{pending: map[string]int{}}
+
+func startStoreSync(resourceType string) {
+	storeSyncs.Lock()
+	defer storeSyncs.Unlock()
+	storeSyncs.pending[resourceType]++
+}
+
+func finishStoreSync(resourceType string) {
+	storeSyncs.Lock()
+	defer storeSyncs.Unlock()
+	if storeSyncs.pending[resourceType]--; storeSyncs.pending[resourceType] <= 0 {
+		delete(storeSyncs.pending, resourceType)
 	}
-	return err
+}
+
+// CheckStoresSynced fails while the stores of some resource types are not initialized from NSX yet.
+func CheckStoresSynced(_ *http.Request) error {
+	storeSyncs.Lock()
+	defer storeSyncs.Unlock()
+	if len(storeSyncs.pending) == 0 {
+		return nil
+	}
+	pending := make([]string, 0, len(storeSyncs.pending))
+	for resourceType := range storeSyncs.pending {
+		pending = append(pending, resourceType)
+	}
+	sort.Strings(pending)
+	return fmt.Errorf("stores of %s are not synced with NSX", strings.Join(pending, ", "))
 }
 
 // InitializeResourceStore is the method to query all the various resources from nsx-t side and
 // save them to the store, we could use it to cache all the resources when process starts.
+// If snapshots are enabled, the store is loaded from its snapshot instead and synced with NSX in the background.
 func (service *Service) InitializeResourceStore(wg *sync.WaitGroup, fatalErrors chan error, resourceTypeValue string, store Store) {
+	if loaded, lastSync, ok := service.loadStoreSnapshot(resourceTypeValue, store); ok {
+		log.Info("initialized store from snapshot", "resourceType", resourceTypeValue, "count", len(loaded), "lastSync", lastSync)
+		service.registerStore(resourceTypeValue, store, lastSync)
+		wg.Done()
+		go service.syncStoreSnapshot(resourceTypeValue, store, loaded)
+		return
+	}
 	defer wg.Done()
 
+	startStoreSync(resourceTypeValue)
+	start := time.Now()
+	count, err := service.SearchResource(resourceTypeValue, store)
+	if err != nil {
+		fatalErrors <- err
+		return
+	}
+	finishStoreSync(resourceTypeValue)
+	log.Info("initialized store", "resourceType", resourceTypeValue, "count", count)
+	service.registerStore(resourceTypeValue, store, start)
+}
+
+// registerStore registers the store initialized from NSX or from its snapshot for the dumps, the resyncs, the snapshots
+// and the store size metrics, lastSync is the time its last sync with NSX started.
+func (service *Service) registerStore(resourceTypeValue string, store Store, lastSync time.Time) {
+	registerStoreDump(resourceTypeValue, store)
+	registerStoreResync(service, resourceTypeValue, store, lastSync)
+	if indexer, ok := store.(cache.KeyLister); ok {
+		metrics.RegisterStore(resourceTypeValue, func() int { return len(indexer.ListKeys()) })
+	}
+}
+
+// SearchResource queries the resources of resourceTypeValue tagged with the cluster from nsx-t side and saves them
+// to the store, it returns the number of the resources found.
+func (service *Service) SearchResource(resourceTypeValue string, store Store) (uint64, error) {
+	return service.searchAll(service.searchQuery(resourceTypeValue, store), store.TransResourceToStore)
+}
+
+// searchQuery returns the query of the resources of resourceTypeValue tagged with the cluster.
+func (service *Service) searchQuery(resourceTypeValue string, store Store) nsx.SearchQuery {
+	return service.resourceQuery(resourceTypeValue, store.IsPolicyAPI())
+}
+
+// resourceQuery returns the query of the Policy or the MP resources of resourceTypeValue tagged with the cluster.
+func (service *Service) resourceQuery(resourceTypeValue string, policyAPI bool) nsx.SearchQuery {
 	tagScopeClusterKey := strings.Replace(TagScopeCluster, "/", "\\/", -1)
 	tagScopeClusterValue := strings.Replace(service.NSXClient.NsxConfig.Cluster, ":", "\\:", -1)
 	tagParam := fmt.Sprintf("tags.scope:%s AND tags.tag:%s", tagScopeClusterKey, tagScopeClusterValue)
 	resourceParam := fmt.Sprintf("%s:%s", ResourceType, resourceTypeValue)
-	queryParam := resourceParam + " AND " + tagParam
-
-	var cursor *string = nil
-	count := uint64(0)
-	for {
-		var results []*data.StructValue
-		var resultCount *int64
-		var err error
-		if store.IsPolicyAPI() {
-			response, searchEerr := service.NSXClient.QueryClient.List(queryParam, cursor, nil, Int64(PageSize), nil, nil)
-			results = response.Results
-			cursor = response.Cursor
-			resultCount = response.ResultCount
-			err = searchEerr
-		} else {
-			response, searchEerr := service.NSXClient.MPQueryClient.List(queryParam, cursor, nil, Int64(PageSize), nil, nil)
-			results = response.Results
-			cursor = response.Cursor
-			resultCount = response.ResultCount
-			err = searchEerr
-		}
-		err = TransError(err)
-		if _, ok := err.(nsxutil.PageMaxError); ok == true {
-			DecrementPageSize(Int64(PageSize))
-			continue
-		}
-		if err != nil {
-			fatalErrors <- err
-		}
-		for _, entity := range results {
-			err = store.TransResourceToStore(entity)
-			if err != nil {
-				fatalErrors <- err
-			}
-			count++
-		}
-		if cursor == nil {
-			break
-		}
-		c, _ := strconv.Atoi(*cursor)
-		if int64(c) >= *resultCount {
-			break
-		}
+
+	query := nsx.SearchQuery{API: nsx.PolicySearch, Query: resourceParam + " AND " + tagParam}
+	if !policyAPI {
+		query.API = nsx.MPSearch
 	}
-	log.Info("initialized store", "resourceType", resourceTypeValue, "count", count)
+	return query
 }
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
 	"github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
 	"github.com/vmware/vsphere-automation-sdk-go/runtime/bindings"
 	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
@@ -13,6 +15,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/ratelimiter"
 )
@@ -178,4 +181,16 @@ func Test_InitializeResourceStore(t *testing.T) {
 	defer patches2.Reset()
 
 	service.InitializeResourceStore(&wg, fatalErrors, ResourceTypeRule, ruleStore)
+	assert.GreaterOrEqual(t, testutil.CollectAndCount(metrics.StoreSize), 1)
+}
+
+func TestCheckStoresSynced(t *testing.T) {
+	assert.Nil(t, CheckStoresSynced(nil))
+	startStoreSync(ResourceTypeRule)
+	startStoreSync(ResourceTypeGroup)
+	assert.EqualError(t, CheckStoresSynced(nil), "stores of Group, Rule are not synced with NSX")
+	finishStoreSync(ResourceTypeGroup)
+	assert.EqualError(t, CheckStoresSynced(nil), "stores of Rule are not synced with NSX")
+	finishStoreSync(ResourceTypeRule)
+	assert.Nil(t, CheckStoresSynced(nil))
 }
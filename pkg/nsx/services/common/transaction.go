@@ -0,0 +1,165 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+)
+
+var enforceRevisionCheckParam = false
+
+// HTarget is a parent resource in the hierarchy of the H-API, such as {"Vpc", vpcID}, the children are wrapped into
+// a ChildResourceReference of it.
+type HTarget struct {
+	Type string
+	ID   string
+}
+
+// hNode is a parent resource in the hierarchy with the children added to it.
+type hNode struct {
+	children []*data.StructValue
+	refs     map[HTarget]*hNode
+	// order keeps the parents in the order they are added, so that the hierarchy is stable.
+	order []HTarget
+}
+
+func newHNode() *hNode {
+	return &hNode{refs: map[HTarget]*hNode{}}
+}
+
+func (n *hNode) empty() bool {
+	return len(n.children) == 0 && len(n.order) == 0
+}
+
+func (n *hNode) add(targets []HTarget, children []*data.StructValue) {
+	node := n
+	for _, target := range targets {
+		next, ok := node.refs[target]
+		if !ok {
+			next = newHNode()
+			node.refs[target] = next
+			node.order = append(node.order, target)
+		}
+		node = next
+	}
+	node.children = append(node.children, children...)
+}
+
+// build wraps the parents into ChildResourceReferences with their children.
+func (n *hNode) build() ([]*data.StructValue, error) {
+	children := append([]*data.StructValue{}, n.children...)
+	for _, target := range n.order {
+		refChildren, err := n.refs[target].build()
+		if err != nil {
+			return nil, err
+		}
+		id, targetType := target.ID, target.Type
+		childReference := model.ChildResourceReference{
+			Id:           &id,
+			ResourceType: "ChildResourceReference",
+			TargetType:   &targetType,
+			Children:     refChildren,
+		}
+		dataValue, errs := NewConverter().ConvertToVapi(childReference, model.ChildResourceReferenceBindingType())
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		children = append(children, dataValue.(*data.StructValue))
+	}
+	return children, nil
+}
+
+// HTransaction collects the children of the H-API contributed by the services in one reconcile, and patches them
+// to NSX together, so that creating the resources of a Namespace, such as the VPC, Subnets and policies, doesn't
+// take an NSX write per resource.
+type HTransaction struct {
+	service *Service
+	// orgRoot holds the children under /orgs, infra the children under the infra of NSX Operator.
+	orgRoot *hNode
+	infra   *hNode
+	sync.Mutex
+}
+
+// NewHTransaction creates an empty HTransaction committed with the NSX client of the service.
+func (service *Service) NewHTransaction() *HTransaction {
+	return &HTransaction{service: service, orgRoot: newHNode(), infra: newHNode()}
+}
+
+// Add adds the children, such as ChildVpc or ChildResourceReference values, under the parents from OrgRoot, e.g.
+// {"Org", org}, {"Project", project}, {"Vpc", vpcID}.
+func (tx *HTransaction) Add(targets []HTarget, children ...*data.StructValue) {
+	tx.Lock()
+	defer tx.Unlock()
+	tx.orgRoot.add(targets, children)
+}
+
+// AddToInfra adds the children under the parents from the infra NSX Operator creates the resources in, e.g.
+// {"Domain", domain}. The infra is the one of the NSX Project if NSX Operator is configured with one.
+func (tx *HTransaction) AddToInfra(targets []HTarget, children ...*data.StructValue) {
+	tx.Lock()
+	defer tx.Unlock()
+	tx.infra.add(targets, children)
+}
+
+// Empty returns whether no children are added.
+func (tx *HTransaction) Empty() bool {
+	tx.Lock()
+	defer tx.Unlock()
+	return tx.orgRoot.empty() && tx.infra.empty()
+}
+
+// Commit patches the children added to NSX and empties the transaction, nothing is patched if no children are added.
+// The children of the infra are wrapped into the OrgRoot if NSX Operator is configured with an NSX Project, so that
+// one H-API call is sent.
+func (tx *HTransaction) Commit() error {
+	tx.Lock()
+	infra, orgRoot, err := tx.build()
+	tx.orgRoot, tx.infra = newHNode(), newHNode()
+	tx.Unlock()
+	if err != nil {
+		return err
+	}
+	if infra != nil {
+		if err := tx.service.NSXClient.InfraClient.Patch(*infra, &enforceRevisionCheckParam); err != nil {
+			return err
+		}
+		log.V(1).Info("patched hierarchical infra", "children", len(infra.Children))
+	}
+	if orgRoot != nil {
+		if err := tx.service.NSXClient.OrgRootClient.Patch(*orgRoot, &enforceRevisionCheckParam); err != nil {
+			return err
+		}
+		log.V(1).Info("patched hierarchical OrgRoot", "children", len(orgRoot.Children))
+	}
+	return nil
+}
+
+// build returns the Infra and OrgRoot to patch, nil if there is nothing to patch with them.
+func (tx *HTransaction) build() (*model.Infra, *model.OrgRoot, error) {
+	var infra *model.Infra
+	if !tx.infra.empty() {
+		children, err := tx.infra.build()
+		if err != nil {
+			return nil, nil, err
+		}
+		infra = &model.Infra{Children: children, ResourceType: String("Infra")}
+	}
+	if nsxConfig := tx.service.NSXConfig; infra != nil && nsxConfig.InProject() {
+		childInfra := model.ChildInfra{ResourceType: "ChildInfra", Infra: infra}
+		dataValue, errs := NewConverter().ConvertToVapi(childInfra, model.ChildInfraBindingType())
+		if len(errs) > 0 {
+			return nil, nil, errs[0]
+		}
+		tx.orgRoot.add([]HTarget{{"Org", nsxConfig.GetNsxOrg()}, {"Project", nsxConfig.NsxProject}}, []*data.StructValue{dataValue.(*data.StructValue)})
+		infra = nil
+	}
+	if tx.orgRoot.empty() {
+		return infra, nil, nil
+	}
+	children, err := tx.orgRoot.build()
+	if err != nil {
+		return nil, nil, err
+	}
+	return infra, &model.OrgRoot{Children: children, ResourceType: String("OrgRoot")}, nil
+}
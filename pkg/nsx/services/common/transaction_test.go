@@ -0,0 +1,108 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	nsx_policy "github.com/vmware/vsphere-automation-sdk-go/services/nsxt"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+)
+
+type fakeInfraClient struct {
+	nsx_policy.InfraClient
+	infras []model.Infra
+}
+
+func (c *fakeInfraClient) Patch(infra model.Infra, _ *bool) error {
+	c.infras = append(c.infras, infra)
+	return nil
+}
+
+type fakeOrgRootClient struct {
+	nsx_policy.OrgRootClient
+	orgRoots []model.OrgRoot
+}
+
+func (c *fakeOrgRootClient) Patch(orgRoot model.OrgRoot, _ *bool) error {
+	c.orgRoots = append(c.orgRoots, orgRoot)
+	return nil
+}
+
+func childGroup(t *testing.T, id string) *data.StructValue {
+	dataValue, errs := NewConverter().ConvertToVapi(model.ChildGroup{ResourceType: "ChildGroup", Id: String(id), Group: &model.Group{Id: String(id)}}, model.ChildGroupBindingType())
+	assert.Nil(t, errs)
+	return dataValue.(*data.StructValue)
+}
+
+// references returns the target types and IDs of the ChildResourceReferences in the children.
+func references(t *testing.T, children []*data.StructValue) ([]HTarget, []model.ChildResourceReference) {
+	var targets []HTarget
+	var refs []model.ChildResourceReference
+	for _, child := range children {
+		resourceType, err := child.String("resource_type")
+		assert.Nil(t, err)
+		if resourceType != "ChildResourceReference" {
+			continue
+		}
+		r, errs := NewConverter().ConvertToGolang(child, model.ChildResourceReferenceBindingType())
+		assert.Nil(t, errs)
+		ref := r.(model.ChildResourceReference)
+		targets = append(targets, HTarget{*ref.TargetType, *ref.Id})
+		refs = append(refs, ref)
+	}
+	return targets, refs
+}
+
+func TestHTransaction_Commit(t *testing.T) {
+	infraClient, orgRootClient := &fakeInfraClient{}, &fakeOrgRootClient{}
+	service := &Service{
+		NSXClient: &nsx.Client{InfraClient: infraClient, OrgRootClient: orgRootClient},
+		NSXConfig: &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}},
+	}
+	tx := service.NewHTransaction()
+	assert.True(t, tx.Empty())
+	assert.Nil(t, tx.Commit())
+	assert.Equal(t, 0, len(infraClient.infras)+len(orgRootClient.orgRoots))
+
+	// the children of the same parents are merged
+	vpc := []HTarget{{"Org", "default"}, {"Project", "p1"}, {"Vpc", "vpc1"}}
+	tx.Add(vpc, childGroup(t, "g1"))
+	tx.Add([]HTarget{{"Org", "default"}, {"Project", "p1"}, {"Vpc", "vpc2"}}, childGroup(t, "g2"))
+	tx.Add(vpc, childGroup(t, "g3"))
+	tx.AddToInfra([]HTarget{{"Domain", "default"}}, childGroup(t, "g4"))
+	assert.False(t, tx.Empty())
+	assert.Nil(t, tx.Commit())
+	assert.True(t, tx.Empty())
+
+	assert.Equal(t, 1, len(infraClient.infras))
+	targets, _ := references(t, infraClient.infras[0].Children)
+	assert.Equal(t, []HTarget{{"Domain", "default"}}, targets)
+	assert.Equal(t, 1, len(orgRootClient.orgRoots))
+	assert.Equal(t, "OrgRoot", *orgRootClient.orgRoots[0].ResourceType)
+	_, refs := references(t, orgRootClient.orgRoots[0].Children)
+	_, refs = references(t, refs[0].Children)
+	targets, refs = references(t, refs[0].Children)
+	assert.Equal(t, []HTarget{{"Vpc", "vpc1"}, {"Vpc", "vpc2"}}, targets)
+	assert.Equal(t, 2, len(refs[0].Children))
+	assert.Equal(t, 1, len(refs[1].Children))
+
+	// the infra is wrapped into the OrgRoot in the NSX Project
+	service.NSXConfig.NsxProject = "tenant1"
+	tx.AddToInfra([]HTarget{{"Domain", "default"}}, childGroup(t, "g4"))
+	tx.Add(vpc, childGroup(t, "g1"))
+	assert.Nil(t, tx.Commit())
+	assert.Equal(t, 1, len(infraClient.infras))
+	assert.Equal(t, 2, len(orgRootClient.orgRoots))
+	targets, refs = references(t, orgRootClient.orgRoots[1].Children)
+	assert.Equal(t, []HTarget{{"Org", "default"}}, targets)
+	targets, refs = references(t, refs[0].Children)
+	assert.Equal(t, []HTarget{{"Project", "p1"}, {"Project", "tenant1"}}, targets)
+	i, errs := NewConverter().ConvertToGolang(refs[1].Children[0], model.ChildInfraBindingType())
+	assert.Nil(t, errs)
+	targets, _ = references(t, i.(model.ChildInfra).Infra.Children)
+	assert.Equal(t, []HTarget{{"Domain", "default"}}, targets)
+}
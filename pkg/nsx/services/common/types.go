@@ -17,6 +17,8 @@ import (
 const (
 	HashLength                      int    = 8
 	MaxTagLength                    int    = 256
+	MaxTagScopeLength               int    = 128
+	MaxTagsCount                    int    = 30
 	MaxIdLength                     int    = 255
 	TagScopeCluster                 string = "nsx-op/cluster"
 	TagScopeNamespace               string = "nsx-op/namespace"
@@ -34,11 +36,79 @@ const (
 	TagScopeNCPVNETInterface        string = "ncp/vnet_interface"
 	TagScopeVPCCRName               string = "nsx-op/vpc_cr_name"
 	TagScopeVPCCRUID                string = "nsx-op/vpc_cr_uid"
+	TagScopeVPCConnectivityProfile  string = "nsx-op/vpc_connectivity_profile"
+	TagScopeVPCDefaultRuleAction    string = "nsx-op/vpc_default_rule_action"
+	TagScopeVPCDeletionPolicy       string = "nsx-op/vpc_deletion_policy"
+	TagScopeRetainedCluster         string = "nsx-op/retained_cluster"
+	TagScopeHeartbeatHolder         string = "nsx-op/heartbeat_holder"
+	TagScopeSubnetCRName            string = "nsx-op/subnet_cr_name"
+	TagScopeSubnetCRUID             string = "nsx-op/subnet_cr_uid"
+	TagScopeSubnetSetCRName         string = "nsx-op/subnetset_cr_name"
+	TagScopeSubnetSetCRUID          string = "nsx-op/subnetset_cr_uid"
+	TagScopeSubnetPortCRName        string = "nsx-op/subnetport_cr_name"
+	TagScopeSubnetPortCRUID         string = "nsx-op/subnetport_cr_uid"
+	TagScopeSubnetPortIPAddress     string = "nsx-op/subnetport_ip_address"
+	TagScopeSubnetPortMACAddress    string = "nsx-op/subnetport_mac_address"
+	TagScopeSubnetPortSecurity      string = "nsx-op/subnetport_security"
+	TagScopeOverflowLabels          string = "nsx-op/overflow_labels"
+	TagScopeIPPoolCRName            string = "nsx-op/ippool_cr_name"
+	TagScopeIPPoolCRUID             string = "nsx-op/ippool_cr_uid"
+	TagScopeIPSubnetName            string = "nsx-op/ipsubnet_name"
+	TagScopeIPAllocationCRName      string = "nsx-op/ipaddressallocation_cr_name"
+	TagScopeIPAllocationCRUID       string = "nsx-op/ipaddressallocation_cr_uid"
+	TagScopeServiceName             string = "nsx-op/service_name"
+	TagScopeServiceUID              string = "nsx-op/service_uid"
+	TagScopeStaticRouteCRName       string = "nsx-op/staticroute_cr_name"
+	TagScopeStaticRouteCRUID        string = "nsx-op/staticroute_cr_uid"
+	TagScopePrefixListCRName        string = "nsx-op/prefixlist_cr_name"
+	TagScopePrefixListCRUID         string = "nsx-op/prefixlist_cr_uid"
+	TagScopeRouteMapCRName          string = "nsx-op/routemap_cr_name"
+	TagScopeRouteMapCRUID           string = "nsx-op/routemap_cr_uid"
+	// TagScopeRouteAdvertisementCRName is the scope the route advertisement rules are recorded with by the garbage
+	// collector, the rules have no tags but are named after their RouteAdvertisement CRs.
+	TagScopeRouteAdvertisementCRName string = "nsx-op/routeadvertisement_cr_name"
 
 	GCInterval    = 60 * time.Second
 	FinalizerName = "securitypolicy.nsx.vmware.com/finalizer"
+	// IPBlockUsageInterval is the interval to sync the usage of the external IP blocks.
+	IPBlockUsageInterval = 5 * time.Minute
+	// IPReclamationInterval is the interval to refresh the NSX IP allocations from NSX before collecting the ones
+	// whose CRs no longer exist.
+	IPReclamationInterval = 10 * time.Minute
+	// IPAMDriftAuditInterval is the interval to audit the IP allocations known to NSX Operator against NSX.
+	IPAMDriftAuditInterval = 15 * time.Minute
+	// NextHopCheckInterval is the interval to check whether the next hops of the StaticRoutes are forwarding.
+	NextHopCheckInterval = 60 * time.Second
+	// SubnetIPUsageInterval is the interval to sync the IP usage of the Subnets.
+	SubnetIPUsageInterval = 5 * time.Minute
+	// DriftInterval is the interval to compare the NSX resources tagged for the cluster with the CRs.
+	DriftInterval = 5 * time.Minute
 
-	NSXServiceAccountFinalizerName = "nsxserviceaccount.nsx.vmware.com/finalizer"
+	NSXServiceAccountFinalizerName  = "nsxserviceaccount.nsx.vmware.com/finalizer"
+	VPCFinalizerName                = "vpc.nsx.vmware.com/finalizer"
+	SubnetFinalizerName             = "subnet.nsx.vmware.com/finalizer"
+	SubnetSetFinalizerName          = "subnetset.nsx.vmware.com/finalizer"
+	SubnetPortFinalizerName         = "subnetport.nsx.vmware.com/finalizer"
+	IPPoolFinalizerName             = "ippool.nsx.vmware.com/finalizer"
+	IPAllocationFinalizerName       = "ipaddressallocation.nsx.vmware.com/finalizer"
+	LBVIPFinalizerName              = "lbvip.nsx.vmware.com/finalizer"
+	StaticRouteFinalizerName        = "staticroute.nsx.vmware.com/finalizer"
+	RouteAdvertisementFinalizerName = "routeadvertisement.nsx.vmware.com/finalizer"
+	PrefixListFinalizerName         = "prefixlist.nsx.vmware.com/finalizer"
+	RouteMapFinalizerName           = "routemap.nsx.vmware.com/finalizer"
+
+	// AnnotationVPCNetworkConfig selects the VPCNetworkConfiguration of a Namespace,
+	// the one named DefaultVPCNetworkConfigName is used if it is absent.
+	AnnotationVPCNetworkConfig  = "nsx.vmware.com/vpc_network_config"
+	DefaultVPCNetworkConfigName = "default"
+	// AnnotationVPCConnectivityProfile selects the connectivity profile of the VPCs in a Namespace,
+	// it can be set as either a label or an annotation of the Namespace.
+	AnnotationVPCConnectivityProfile = "nsx.vmware.com/vpc-connectivity-profile"
+	// AnnotationEnforcementPoint selects the NSX enforcement point of the resources of a Namespace, it must be one of
+	// the enforcement points of the config, the default enforcement point is used if it is absent.
+	AnnotationEnforcementPoint = "nsx.vmware.com/enforcement-point"
+	// AnnotationIPAddress requests a static IP for a SubnetPort, the IP is reserved in the IP pool of the Subnet.
+	AnnotationIPAddress = "nsx.vmware.com/ip-address"
 )
 
 var (
@@ -47,6 +117,16 @@ var (
 	ResourceTypeGroup          = "Group"
 	ResourceTypeRule           = "Rule"
 	ResourceTypeVPC            = "VPC"
+	ResourceTypeSubnet         = "VpcSubnet"
+	ResourceTypeSubnetPort     = "VpcSubnetPort"
+	ResourceTypeIPPool         = "IpAddressPool"
+	ResourceTypeIPPoolSubnet   = "IpAddressPoolBlockSubnet"
+	ResourceTypeIPAllocation   = "IpAddressAllocation"
+	// ResourceTypeVPCIPAllocation is the allocation from the external IP blocks of a VPC.
+	ResourceTypeVPCIPAllocation = "VpcIpAddressAllocation"
+	ResourceTypeStaticRoute     = "StaticRoutes"
+	ResourceTypePrefixList      = "PrefixList"
+	ResourceTypeRouteMap        = "Tier0RouteMap"
 	// ResourceTypeClusterControlPlane is used by NSXServiceAccountController
 	ResourceTypeClusterControlPlane = "clustercontrolplane"
 	// ResourceTypePrincipalIdentity is used by NSXServiceAccountController, and it is MP resource type.
@@ -57,6 +137,8 @@ type Service struct {
 	Client    client.Client
 	NSXClient *nsx.Client
 	NSXConfig *config.NSXOperatorConfig
+	// WriteQueue coalesces the writes to the same NSX intent path, the writes are sent right away if it is nil.
+	WriteQueue *WriteQueue
 }
 
 func NewConverter() *bindings.TypeConverter {
@@ -68,4 +150,5 @@ func NewConverter() *bindings.TypeConverter {
 var (
 	String = pointy.String // address of string
 	Int64  = pointy.Int64  // address of int64
+	Bool   = pointy.Bool   // address of bool
 )
@@ -0,0 +1,172 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/metrics"
+)
+
+// pendingWrite is the latest write to an NSX intent path waiting in the WriteQueue, with the callers waiting for it.
+type pendingWrite struct {
+	write   func() error
+	waiters []chan error
+}
+
+// WriteQueue holds the writes to each NSX intent path for a delay, so that the writes to the same path in the delay,
+// e.g. the updates of a port on the label churn of its Pod, are coalesced into one PATCH of the latest intent. The
+// writes to a path are sent one at a time in the order they are queued, the writes to different paths are sent
+// concurrently.
+type WriteQueue struct {
+	delay time.Duration
+	cf    *config.NSXOperatorConfig
+	// pending holds the write of each path which is not sent yet.
+	pending map[string]*pendingWrite
+	// busy holds the paths with a write waiting for the delay or being sent.
+	busy map[string]bool
+	// waiting holds the timers of the paths with a write waiting for the delay.
+	waiting map[string]*time.Timer
+	// draining is set once the queue is drained, the writes are then sent without waiting for the delay.
+	draining bool
+	sync.Mutex
+}
+
+// writeQueues are the WriteQueues created by NewWriteQueue, they are drained when NSX Operator stops.
+var writeQueues = struct {
+	queues []*WriteQueue
+	sync.Mutex
+}{}
+
+// NewWriteQueue creates the WriteQueue with the coalesce delay of the config, nil if the delay is unset.
+func NewWriteQueue(cf *config.NSXOperatorConfig) *WriteQueue {
+	if cf.NsxConfig == nil || cf.WriteCoalesceDelay <= 0 {
+		return nil
+	}
+	q := newWriteQueue(cf, time.Duration(cf.WriteCoalesceDelay)*time.Millisecond)
+	writeQueues.Lock()
+	defer writeQueues.Unlock()
+	writeQueues.queues = append(writeQueues.queues, q)
+	return q
+}
+
+func newWriteQueue(cf *config.NSXOperatorConfig, delay time.Duration) *WriteQueue {
+	return &WriteQueue{delay: delay, cf: cf, pending: map[string]*pendingWrite{}, busy: map[string]bool{},
+		waiting: map[string]*time.Timer{}}
+}
+
+// Enqueue queues the write to the path, it replaces the write to the path which is not sent yet. The channel
+// returned receives the error of the write sent for the path, which is the latest write queued before it is sent.
+func (q *WriteQueue) Enqueue(path string, write func() error) <-chan error {
+	done := make(chan error, 1)
+	q.Lock()
+	defer q.Unlock()
+	if p, ok := q.pending[path]; ok {
+		p.write = write
+		p.waiters = append(p.waiters, done)
+		if metrics.AreMetricsExposed(q.cf) {
+			metrics.NSXWriteCoalescedTotal.Inc()
+		}
+		log.V(1).Info("coalesced NSX write", "path", path, "writes", len(p.waiters))
+		return done
+	}
+	q.pending[path] = &pendingWrite{write: write, waiters: []chan error{done}}
+	q.updateDepth()
+	// the write is scheduled after the one being sent for the path, if there is one
+	if !q.busy[path] {
+		q.schedule(path)
+	}
+	return done
+}
+
+// Write sends the write to the path through the queue and waits for it, the write is sent right away if the queue
+// is nil.
+func (q *WriteQueue) Write(path string, write func() error) error {
+	if q == nil {
+		return write()
+	}
+	return <-q.Enqueue(path, write)
+}
+
+func (q *WriteQueue) schedule(path string) {
+	q.busy[path] = true
+	if q.draining {
+		go q.flush(path)
+		return
+	}
+	q.waiting[path] = time.AfterFunc(q.delay, func() { q.flush(path) })
+}
+
+// flush sends the pending write of the path, and schedules the one queued while it is sent.
+func (q *WriteQueue) flush(path string) {
+	q.Lock()
+	delete(q.waiting, path)
+	p := q.pending[path]
+	delete(q.pending, path)
+	q.updateDepth()
+	q.Unlock()
+
+	err := p.write()
+	for _, waiter := range p.waiters {
+		waiter <- err
+	}
+
+	q.Lock()
+	defer q.Unlock()
+	delete(q.busy, path)
+	if _, ok := q.pending[path]; ok {
+		q.schedule(path)
+	}
+}
+
+// Drain sends the writes waiting for the delay at once, as well as the ones queued afterwards, and waits until all the
+// writes are sent or ctx is done.
+func (q *WriteQueue) Drain(ctx context.Context) error {
+	q.Lock()
+	q.draining = true
+	for path, timer := range q.waiting {
+		// the write is being sent if the timer already fired
+		if timer.Stop() {
+			delete(q.waiting, path)
+			go q.flush(path)
+		}
+	}
+	q.Unlock()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		q.Lock()
+		busy := len(q.busy)
+		q.Unlock()
+		if busy == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d NSX writes are not sent: %w", busy, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// DrainWriteQueues drains all the WriteQueues, see WriteQueue.Drain.
+func DrainWriteQueues(ctx context.Context) error {
+	writeQueues.Lock()
+	queues := append([]*WriteQueue{}, writeQueues.queues...)
+	writeQueues.Unlock()
+	for _, q := range queues {
+		if err := q.Drain(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *WriteQueue) updateDepth() {
+	if metrics.AreMetricsExposed(q.cf) {
+		metrics.NSXWriteQueueDepth.Set(float64(len(q.pending)))
+	}
+}
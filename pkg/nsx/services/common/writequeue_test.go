@@ -0,0 +1,114 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestNewWriteQueue(t *testing.T) {
+	cf := &config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}}
+	assert.Nil(t, NewWriteQueue(cf))
+	cf.WriteCoalesceDelay = 100
+	q := NewWriteQueue(cf)
+	assert.Equal(t, 100*time.Millisecond, q.delay)
+
+	// the writes are sent right away without a queue
+	var nilQueue *WriteQueue
+	assert.EqualError(t, nilQueue.Write("/a", func() error { return errors.New("mock error") }), "mock error")
+}
+
+func TestWriteQueue_coalesce(t *testing.T) {
+	q := newWriteQueue(&config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}}, 50*time.Millisecond)
+	var lock sync.Mutex
+	var sent []string
+	write := func(path, intent string) func() error {
+		return func() error {
+			lock.Lock()
+			defer lock.Unlock()
+			sent = append(sent, path+"="+intent)
+			return nil
+		}
+	}
+
+	// the writes to the same path in the delay are coalesced into the latest one
+	var results []<-chan error
+	for _, intent := range []string{"1", "2", "3"} {
+		results = append(results, q.Enqueue("/port1", write("/port1", intent)))
+	}
+	results = append(results, q.Enqueue("/port2", write("/port2", "1")))
+	for _, result := range results {
+		assert.Nil(t, <-result)
+	}
+	assert.ElementsMatch(t, []string{"/port1=3", "/port2=1"}, sent)
+	// the paths are released once the flushes return after notifying the callers
+	assert.Nil(t, q.Drain(context.TODO()))
+	q.Lock()
+	defer q.Unlock()
+	assert.Empty(t, q.pending)
+	assert.Empty(t, q.busy)
+}
+
+func TestWriteQueue_order(t *testing.T) {
+	q := newWriteQueue(&config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}}, 10*time.Millisecond)
+	started, release := make(chan struct{}), make(chan struct{})
+	var sent []string
+	first := q.Enqueue("/port1", func() error {
+		close(started)
+		<-release
+		sent = append(sent, "1")
+		return errors.New("mock error")
+	})
+	<-started
+	// the writes queued while a write to the path is sent are sent after it
+	second := q.Enqueue("/port1", func() error {
+		sent = append(sent, "2")
+		return nil
+	})
+	third := q.Enqueue("/port1", func() error {
+		sent = append(sent, "3")
+		return nil
+	})
+	close(release)
+	assert.EqualError(t, <-first, "mock error")
+	assert.Nil(t, <-second)
+	assert.Nil(t, <-third)
+	assert.Equal(t, []string{"1", "3"}, sent)
+}
+
+func TestWriteQueue_Drain(t *testing.T) {
+	q := newWriteQueue(&config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{}}, time.Hour)
+	// the writes waiting for the delay are sent at once
+	result := q.Enqueue("/port1", func() error { return nil })
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, q.Drain(ctx))
+	assert.Nil(t, <-result)
+	assert.Empty(t, q.busy)
+	assert.Empty(t, q.waiting)
+
+	// the writes queued once the queue is drained don't wait for the delay
+	select {
+	case err := <-q.Enqueue("/port2", func() error { return nil }):
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("write not sent")
+	}
+
+	// the writes which are not sent in time fail the drain
+	release := make(chan struct{})
+	defer close(release)
+	q.Enqueue("/port3", func() error {
+		<-release
+		return nil
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, q.Drain(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,60 @@
+package ipaddressallocation
+
+import (
+	"fmt"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+const defaultCount = 1
+
+var String = common.String
+
+func allocationCount(obj *v1alpha1.IPAddressAllocation) int {
+	if obj.Spec.Count == 0 {
+		return defaultCount
+	}
+	return obj.Spec.Count
+}
+
+// allocationID returns the ID of the index-th IP allocation of the IPAddressAllocation CR.
+func allocationID(obj *v1alpha1.IPAddressAllocation, index int) string {
+	return fmt.Sprintf("ipalloc_%s_%d", obj.UID, index)
+}
+
+func (s *IPAddressAllocationService) buildBasicTags(obj *v1alpha1.IPAddressAllocation) []model.Tag {
+	return []model.Tag{
+		{Scope: String(common.TagScopeCluster), Tag: String(s.NSXConfig.Cluster)},
+		{Scope: String(common.TagScopeNamespace), Tag: String(obj.Namespace)},
+		{Scope: String(common.TagScopeIPAllocationCRName), Tag: String(obj.Name)},
+		{Scope: String(common.TagScopeIPAllocationCRUID), Tag: String(string(obj.UID))},
+	}
+}
+
+// buildPoolAllocation builds the index-th IP allocation in the NSX IP pool, NSX picks a free IP of the pool.
+func (s *IPAddressAllocationService) buildPoolAllocation(obj *v1alpha1.IPAddressAllocation, ipPoolPath string, index int) *model.IpAddressAllocation {
+	id := allocationID(obj, index)
+	return &model.IpAddressAllocation{
+		Id:          String(id),
+		DisplayName: String(fmt.Sprintf("%s-%s-%d", obj.Namespace, obj.Name, index)),
+		Path:        String(fmt.Sprintf("%s/ip-allocations/%s", ipPoolPath, id)),
+		ParentPath:  String(ipPoolPath),
+		Tags:        s.buildBasicTags(obj),
+	}
+}
+
+// buildVPCAllocation builds the index-th IP allocation from the external IP blocks of the NSX VPC.
+func (s *IPAddressAllocationService) buildVPCAllocation(obj *v1alpha1.IPAddressAllocation, nsxVPC *model.Vpc, index int) *model.VpcIpAddressAllocation {
+	id := allocationID(obj, index)
+	return &model.VpcIpAddressAllocation{
+		Id:                       String(id),
+		DisplayName:              String(fmt.Sprintf("%s-%s-%d", obj.Namespace, obj.Name, index)),
+		Path:                     String(fmt.Sprintf("%s/ip-address-allocations/%s", *nsxVPC.Path, id)),
+		ParentPath:               nsxVPC.Path,
+		IpAddressBlockVisibility: String(model.VpcIpAddressAllocation_IP_ADDRESS_BLOCK_VISIBILITY_PUBLIC),
+		Tags:                     s.buildBasicTags(obj),
+	}
+}
@@ -0,0 +1,32 @@
+package ipaddressallocation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestBuildAllocations(t *testing.T) {
+	s := &IPAddressAllocationService{Service: common.Service{
+		NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}},
+	}}
+	obj := &v1alpha1.IPAddressAllocation{ObjectMeta: metav1.ObjectMeta{Name: "alloc1", Namespace: "ns1", UID: "uid1"}}
+	assert.Equal(t, 1, allocationCount(obj))
+
+	poolAllocation := s.buildPoolAllocation(obj, "/orgs/default/projects/p1/infra/ip-pools/pool1", 1)
+	assert.Equal(t, "ipalloc_uid1_1", *poolAllocation.Id)
+	assert.Equal(t, "ns1-alloc1-1", *poolAllocation.DisplayName)
+	assert.Equal(t, "/orgs/default/projects/p1/infra/ip-pools/pool1/ip-allocations/ipalloc_uid1_1", *poolAllocation.Path)
+	assert.Equal(t, []string{"uid1"}, filterTag(poolAllocation.Tags, common.TagScopeIPAllocationCRUID))
+
+	nsxVPC := &model.Vpc{Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	vpcAllocation := s.buildVPCAllocation(obj, nsxVPC, 0)
+	assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc1/ip-address-allocations/ipalloc_uid1_0", *vpcAllocation.Path)
+	assert.Equal(t, model.VpcIpAddressAllocation_IP_ADDRESS_BLOCK_VISIBILITY_PUBLIC, *vpcAllocation.IpAddressBlockVisibility)
+}
@@ -0,0 +1,58 @@
+package ipaddressallocation
+
+import (
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type (
+	PoolAllocation model.IpAddressAllocation
+	VPCAllocation  model.VpcIpAddressAllocation
+)
+
+type Comparable = common.Comparable
+
+func (allocation *PoolAllocation) Key() string {
+	return *allocation.Path
+}
+
+// Value leaves out the IP which NSX fills in when it is allocated.
+func (allocation *PoolAllocation) Value() data.DataValue {
+	a := &PoolAllocation{Id: allocation.Id, DisplayName: allocation.DisplayName, Tags: allocation.Tags}
+	dataValue, _ := ComparableToPoolAllocation(a).GetDataValue__()
+	return dataValue
+}
+
+func (allocation *VPCAllocation) Key() string {
+	return *allocation.Path
+}
+
+// Value leaves out the IP which NSX fills in when it is allocated.
+func (allocation *VPCAllocation) Value() data.DataValue {
+	a := &VPCAllocation{
+		Id:                       allocation.Id,
+		DisplayName:              allocation.DisplayName,
+		Tags:                     allocation.Tags,
+		IpAddressBlockVisibility: allocation.IpAddressBlockVisibility,
+	}
+	dataValue, _ := ComparableToVPCAllocation(a).GetDataValue__()
+	return dataValue
+}
+
+func PoolAllocationToComparable(allocation *model.IpAddressAllocation) Comparable {
+	return (*PoolAllocation)(allocation)
+}
+
+func ComparableToPoolAllocation(allocation Comparable) *model.IpAddressAllocation {
+	return (*model.IpAddressAllocation)(allocation.(*PoolAllocation))
+}
+
+func VPCAllocationToComparable(allocation *model.VpcIpAddressAllocation) Comparable {
+	return (*VPCAllocation)(allocation)
+}
+
+func ComparableToVPCAllocation(allocation Comparable) *model.VpcIpAddressAllocation {
+	return (*model.VpcIpAddressAllocation)(allocation.(*VPCAllocation))
+}
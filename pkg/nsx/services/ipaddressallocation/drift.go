@@ -0,0 +1,138 @@
+package ipaddressallocation
+
+import (
+	"path"
+
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipam"
+)
+
+// AuditAllocations compares the IP allocations of the IPAddressAllocation CRs in the stores with the NSX IP allocations
+// tagged with the cluster, and returns the ones found only in NSX (Unknown) or only in the stores (Missing). Each drift
+// is confirmed with NSX directly as the search results may lag behind. With repair, the Unknown allocations are added
+// to the stores so that they are released by the garbage collector or reused by the reconciler, and the Missing ones
+// are allocated again with the same IPs.
+func (s *IPAddressAllocationService) AuditAllocations(repair bool) ([]v1alpha1.IPAMDrift, error) {
+	nsxPoolAllocations := &PoolAllocationStore{ResourceStore: common.ResourceStore{
+		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{}),
+		BindingType: model.IpAddressAllocationBindingType(),
+	}}
+	nsxVPCAllocations := &VPCAllocationStore{ResourceStore: common.ResourceStore{
+		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{}),
+		BindingType: model.VpcIpAddressAllocationBindingType(),
+	}}
+	if _, err := s.SearchResource(common.ResourceTypeIPAllocation, nsxPoolAllocations); err != nil {
+		return nil, err
+	}
+	if _, err := s.SearchResource(common.ResourceTypeVPCIPAllocation, nsxVPCAllocations); err != nil {
+		return nil, err
+	}
+
+	var drifts []v1alpha1.IPAMDrift
+	for _, obj := range nsxPoolAllocations.List() {
+		allocation := obj.(model.IpAddressAllocation)
+		if !isCRAllocation(allocation.Tags) || s.poolAllocationStore.GetByKey(*allocation.Path) != nil {
+			continue
+		}
+		drift, err := s.confirmDrift(v1alpha1.IPAMDriftUnknown, *allocation.ParentPath, *allocation.Id, *allocation.Path, allocation.AllocationIp, allocation.Tags)
+		if err != nil || drift == nil {
+			continue
+		}
+		if repair {
+			drift.Repaired = s.poolAllocationStore.Operate(&allocation) == nil
+		}
+		drifts = append(drifts, *drift)
+	}
+	for _, obj := range nsxVPCAllocations.List() {
+		allocation := obj.(model.VpcIpAddressAllocation)
+		if !isCRAllocation(allocation.Tags) || s.vpcAllocationStore.GetByKey(*allocation.Path) != nil {
+			continue
+		}
+		drift, err := s.confirmDrift(v1alpha1.IPAMDriftUnknown, *allocation.ParentPath, *allocation.Id, *allocation.Path, allocation.AllocationIp, allocation.Tags)
+		if err != nil || drift == nil {
+			continue
+		}
+		if repair {
+			drift.Repaired = s.vpcAllocationStore.Operate(&allocation) == nil
+		}
+		drifts = append(drifts, *drift)
+	}
+
+	for _, obj := range s.poolAllocationStore.List() {
+		allocation := obj.(model.IpAddressAllocation)
+		if !isCRAllocation(allocation.Tags) || nsxPoolAllocations.GetByKey(*allocation.Path) != nil {
+			continue
+		}
+		drift, err := s.confirmDrift(v1alpha1.IPAMDriftMissing, *allocation.ParentPath, *allocation.Id, *allocation.Path, allocation.AllocationIp, allocation.Tags)
+		if err != nil || drift == nil {
+			continue
+		}
+		if repair {
+			drift.Repaired = s.reallocate(drift, *allocation.DisplayName, allocation.Tags) == nil
+		}
+		drifts = append(drifts, *drift)
+	}
+	for _, obj := range s.vpcAllocationStore.List() {
+		allocation := obj.(model.VpcIpAddressAllocation)
+		if !isCRAllocation(allocation.Tags) || nsxVPCAllocations.GetByKey(*allocation.Path) != nil {
+			continue
+		}
+		drift, err := s.confirmDrift(v1alpha1.IPAMDriftMissing, *allocation.ParentPath, *allocation.Id, *allocation.Path, allocation.AllocationIp, allocation.Tags)
+		if err != nil || drift == nil {
+			continue
+		}
+		if repair {
+			drift.Repaired = s.reallocate(drift, *allocation.DisplayName, allocation.Tags) == nil
+		}
+		drifts = append(drifts, *drift)
+	}
+	return drifts, nil
+}
+
+// confirmDrift reads the allocation from NSX, and returns the drift if the allocation is still found only in NSX for
+// Unknown, or still not found in NSX for Missing, or else nil.
+func (s *IPAddressAllocationService) confirmDrift(driftType v1alpha1.IPAMDriftType, pool, id, allocationPath string, ip *string, tags []model.Tag) (*v1alpha1.IPAMDrift, error) {
+	_, err := s.allocator.GetIP(pool, id)
+	_, notFound := err.(vapierrors.NotFound)
+	if err != nil && !notFound {
+		log.Error(err, "failed to confirm IP allocation drift", "allocation", allocationPath)
+		return nil, err
+	}
+	if notFound != (driftType == v1alpha1.IPAMDriftMissing) {
+		return nil, nil
+	}
+	drift := &v1alpha1.IPAMDrift{Type: driftType, Path: allocationPath}
+	if ip != nil {
+		drift.IP = *ip
+	}
+	if uids := filterTag(tags, common.TagScopeIPAllocationCRUID); len(uids) > 0 {
+		drift.OwnerUID = uids[0]
+	}
+	log.Info("found IP allocation drift", "type", driftType, "allocation", allocationPath, "IP", drift.IP)
+	return drift, nil
+}
+
+// reallocate creates the Missing allocation in NSX again, the IP previously allocated is requested.
+func (s *IPAddressAllocationService) reallocate(drift *v1alpha1.IPAMDrift, displayName string, tags []model.Tag) error {
+	req := &ipam.Request{ID: path.Base(drift.Path), DisplayName: displayName, Tags: tags}
+	if drift.IP != "" {
+		req.IPs = []string{drift.IP}
+	}
+	if _, err := s.allocator.AllocateIP(ipam.IPFamilyIPv4, path.Dir(path.Dir(drift.Path)), req); err != nil {
+		log.Error(err, "failed to repair missing IP allocation", "allocation", drift.Path)
+		return err
+	}
+	log.Info("repaired missing IP allocation", "allocation", drift.Path, "IP", drift.IP)
+	return nil
+}
+
+// isCRAllocation returns whether the allocation is created for an IPAddressAllocation CR, the LB VIP allocations in the
+// same NSX IP pools are not.
+func isCRAllocation(tags []model.Tag) bool {
+	return len(filterTag(tags, common.TagScopeIPAllocationCRUID)) > 0
+}
@@ -0,0 +1,68 @@
+package ipaddressallocation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestIPAddressAllocationService_AuditAllocations(t *testing.T) {
+	s, poolClient, vpcClient := newFakeIPAddressAllocationService()
+	nsxVPC := &model.Vpc{Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	poolPath := "/orgs/default/projects/p1/infra/ip-pools/ippool_pool-uid1"
+	poolClient.allocate = true
+	_, _, err := s.CreateOrUpdateIPAddressAllocation(&v1alpha1.IPAddressAllocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "alloc1", Namespace: "ns1", UID: "uid1"},
+	}, nsxVPC, poolPath)
+	require.Nil(t, err)
+	_, _, err = s.CreateOrUpdateIPAddressAllocation(&v1alpha1.IPAddressAllocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "alloc2", Namespace: "ns1", UID: "uid2"},
+	}, nsxVPC, "")
+	require.Nil(t, err)
+
+	// the VPC allocation of uid2 is missing from NSX, the pool allocation of uid3 is unknown to the stores, the LB VIP
+	// allocation and the allocation not found by NSX search yet are ignored
+	delete(vpcClient.allocations, "ipalloc_uid2_0")
+	unknown := model.IpAddressAllocation{
+		Id:           String("ipalloc_uid3_0"),
+		Path:         String(poolPath + "/ip-allocations/ipalloc_uid3_0"),
+		ParentPath:   String(poolPath),
+		AllocationIp: String("172.16.0.9"),
+		Tags:         []model.Tag{{Scope: String(common.TagScopeIPAllocationCRUID), Tag: String("uid3")}},
+	}
+	poolClient.allocations[*unknown.Id] = unknown
+	vip := model.IpAddressAllocation{
+		Id:         String("vip_svc1"),
+		Path:       String(poolPath + "/ip-allocations/vip_svc1"),
+		ParentPath: String(poolPath),
+		Tags:       []model.Tag{{Scope: String(common.TagScopeServiceUID), Tag: String("svc1")}},
+	}
+	poolClient.allocations[*vip.Id] = vip
+	known := s.poolAllocationStore.GetByKey(poolPath + "/ip-allocations/ipalloc_uid1_0").(model.IpAddressAllocation)
+	s.NSXClient.QueryClient = &fakeQueryClient{poolAllocations: []model.IpAddressAllocation{known, unknown, vip}}
+	s.NSXClient.NsxConfig = s.NSXConfig
+
+	drifts, err := s.AuditAllocations(false)
+	require.Nil(t, err)
+	assert.Equal(t, []v1alpha1.IPAMDrift{
+		{Type: v1alpha1.IPAMDriftUnknown, Path: *unknown.Path, IP: "172.16.0.9", OwnerUID: "uid3"},
+		{Type: v1alpha1.IPAMDriftMissing, Path: "/orgs/default/projects/p1/vpcs/vpc1/ip-address-allocations/ipalloc_uid2_0", IP: "10.10.0.1", OwnerUID: "uid2"},
+	}, drifts)
+	assert.NotContains(t, s.ListIPAddressAllocationCRUID().List(), "uid3")
+
+	// the unknown allocation is added to the stores and the missing one is allocated again
+	drifts, err = s.AuditAllocations(true)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(drifts))
+	assert.True(t, drifts[0].Repaired)
+	assert.True(t, drifts[1].Repaired)
+	assert.Contains(t, s.ListIPAddressAllocationCRUID().List(), "uid3")
+	_, ok := vpcClient.allocations["ipalloc_uid2_0"]
+	assert.True(t, ok)
+}
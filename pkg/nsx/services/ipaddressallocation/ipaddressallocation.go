@@ -0,0 +1,230 @@
+package ipaddressallocation
+
+import (
+	"path"
+	"sync"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipam"
+)
+
+var (
+	log             = logger.Log
+	MarkedForDelete = true
+)
+
+// IPAddressAllocationService manages the NSX IP allocations of the IPAddressAllocation CRs, the IPs are allocated
+// either from the NSX IP pool of an IPPool CR, or from the external IP blocks of the VPC.
+type IPAddressAllocationService struct {
+	common.Service
+	poolAllocationStore *PoolAllocationStore
+	vpcAllocationStore  *VPCAllocationStore
+	allocator           ipam.Allocator
+}
+
+// InitializeIPAddressAllocation sync NSX resources
+func InitializeIPAddressAllocation(service common.Service) (*IPAddressAllocationService, error) {
+	wg := sync.WaitGroup{}
+	wgDone := make(chan bool)
+	fatalErrors := make(chan error)
+
+	wg.Add(2)
+
+	ipAllocationService := &IPAddressAllocationService{Service: service, allocator: ipam.NewIPAMService(service)}
+	ipAllocationService.poolAllocationStore = &PoolAllocationStore{ResourceStore: common.ResourceStore{
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeIPAllocationCRUID: ipAllocationIndexFunc}),
+		BindingType: model.IpAddressAllocationBindingType(),
+	}}
+	ipAllocationService.vpcAllocationStore = &VPCAllocationStore{ResourceStore: common.ResourceStore{
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeIPAllocationCRUID: ipAllocationIndexFunc}),
+		BindingType: model.VpcIpAddressAllocationBindingType(),
+	}}
+
+	go ipAllocationService.InitializeResourceStore(&wg, fatalErrors, common.ResourceTypeIPAllocation, ipAllocationService.poolAllocationStore)
+	go ipAllocationService.InitializeResourceStore(&wg, fatalErrors, common.ResourceTypeVPCIPAllocation, ipAllocationService.vpcAllocationStore)
+
+	go func() {
+		wg.Wait()
+		close(wgDone)
+	}()
+
+	select {
+	case <-wgDone:
+		break
+	case err := <-fatalErrors:
+		close(fatalErrors)
+		return ipAllocationService, err
+	}
+
+	return ipAllocationService, nil
+}
+
+// CreateOrUpdateIPAddressAllocation allocates the IPs of the IPAddressAllocation CR from the NSX IP pool of ipPoolPath,
+// or from the external IP blocks of the NSX VPC if ipPoolPath is empty. The allocations no longer requested are
+// released first so that their IPs can be reused. It returns the IPs allocated so far, and whether all the requested
+// IPs are allocated.
+func (s *IPAddressAllocationService) CreateOrUpdateIPAddressAllocation(obj *v1alpha1.IPAddressAllocation, nsxVPC *model.Vpc, ipPoolPath string) ([]string, bool, error) {
+	var poolAllocations []*model.IpAddressAllocation
+	var vpcAllocations []*model.VpcIpAddressAllocation
+	requested := sets.NewString()
+	for i := 0; i < allocationCount(obj); i++ {
+		if ipPoolPath != "" {
+			allocation := s.buildPoolAllocation(obj, ipPoolPath, i)
+			poolAllocations = append(poolAllocations, allocation)
+			requested.Insert(*allocation.Path)
+		} else {
+			allocation := s.buildVPCAllocation(obj, nsxVPC, i)
+			vpcAllocations = append(vpcAllocations, allocation)
+			requested.Insert(*allocation.Path)
+		}
+	}
+	if err := s.deleteAllocations(obj.UID, requested); err != nil {
+		return nil, false, err
+	}
+
+	var ips []string
+	allocated := true
+	for _, allocation := range poolAllocations {
+		ip, err := s.createOrUpdatePoolAllocation(allocation)
+		if err != nil {
+			return nil, false, err
+		}
+		if ip == "" {
+			allocated = false
+			continue
+		}
+		ips = append(ips, ip)
+	}
+	for _, allocation := range vpcAllocations {
+		ip, err := s.createOrUpdateVPCAllocation(allocation)
+		if err != nil {
+			return nil, false, err
+		}
+		if ip == "" {
+			allocated = false
+			continue
+		}
+		ips = append(ips, ip)
+	}
+	return ips, allocated, nil
+}
+
+// createOrUpdatePoolAllocation returns the IP of the allocation in the NSX IP pool, or "" if it is not allocated yet.
+func (s *IPAddressAllocationService) createOrUpdatePoolAllocation(allocation *model.IpAddressAllocation) (string, error) {
+	changed := true
+	if existing := s.poolAllocationStore.GetByKey(*allocation.Path); existing != nil {
+		existingAllocation := existing.(model.IpAddressAllocation)
+		changed = common.CompareResource(PoolAllocationToComparable(&existingAllocation), PoolAllocationToComparable(allocation))
+		if !changed && existingAllocation.AllocationIp != nil {
+			return *existingAllocation.AllocationIp, nil
+		}
+	}
+	ip, err := s.allocateIP(changed, *allocation.ParentPath, *allocation.Id, *allocation.DisplayName, allocation.Tags)
+	if err != nil {
+		return "", err
+	}
+	allocation.AllocationIp = nil
+	if ip != "" {
+		allocation.AllocationIp = String(ip)
+	}
+	if err := s.poolAllocationStore.Operate(allocation); err != nil {
+		return "", err
+	}
+	return ip, nil
+}
+
+// createOrUpdateVPCAllocation returns the IP of the allocation in the NSX VPC, or "" if it is not allocated yet.
+func (s *IPAddressAllocationService) createOrUpdateVPCAllocation(allocation *model.VpcIpAddressAllocation) (string, error) {
+	changed := true
+	if existing := s.vpcAllocationStore.GetByKey(*allocation.Path); existing != nil {
+		existingAllocation := existing.(model.VpcIpAddressAllocation)
+		changed = common.CompareResource(VPCAllocationToComparable(&existingAllocation), VPCAllocationToComparable(allocation))
+		if !changed && existingAllocation.AllocationIp != nil {
+			return *existingAllocation.AllocationIp, nil
+		}
+	}
+	// the IPs are allocated from the external IP blocks of the VPC
+	ip, err := s.allocateIP(changed, *allocation.ParentPath, *allocation.Id, *allocation.DisplayName, allocation.Tags)
+	if err != nil {
+		return "", err
+	}
+	allocation.AllocationIp = nil
+	if ip != "" {
+		allocation.AllocationIp = String(ip)
+	}
+	if err := s.vpcAllocationStore.Operate(allocation); err != nil {
+		return "", err
+	}
+	return ip, nil
+}
+
+// allocateIP creates or updates the allocation in the pool if it is changed, and returns the IP NSX has allocated.
+func (s *IPAddressAllocationService) allocateIP(changed bool, pool, id, displayName string, tags []model.Tag) (string, error) {
+	if !changed {
+		return s.allocator.GetIP(pool, id)
+	}
+	allocations, err := s.allocator.AllocateIP(ipam.IPFamilyIPv4, pool, &ipam.Request{ID: id, DisplayName: displayName, Tags: tags})
+	if err != nil {
+		return "", err
+	}
+	return allocations[0].IP, nil
+}
+
+// deleteAllocations releases the IP allocations of the IPAddressAllocation CR except the ones in retained.
+func (s *IPAddressAllocationService) deleteAllocations(uid types.UID, retained sets.String) error {
+	for _, allocation := range s.poolAllocationStore.GetByIndex(common.TagScopeIPAllocationCRUID, string(uid)) {
+		if retained.Has(*allocation.Path) {
+			continue
+		}
+		if err := s.allocator.ReleaseIP(path.Dir(path.Dir(*allocation.Path)), *allocation.Id); err != nil {
+			return err
+		}
+		allocation.MarkedForDelete = &MarkedForDelete
+		if err := s.poolAllocationStore.Operate(&allocation); err != nil {
+			return err
+		}
+	}
+	for _, allocation := range s.vpcAllocationStore.GetByIndex(common.TagScopeIPAllocationCRUID, string(uid)) {
+		if retained.Has(*allocation.Path) {
+			continue
+		}
+		if err := s.allocator.ReleaseIP(path.Dir(path.Dir(*allocation.Path)), *allocation.Id); err != nil {
+			return err
+		}
+		allocation.MarkedForDelete = &MarkedForDelete
+		if err := s.vpcAllocationStore.Operate(&allocation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteIPAddressAllocationByCRUID releases all the IPs of the IPAddressAllocation CR.
+func (s *IPAddressAllocationService) DeleteIPAddressAllocationByCRUID(uid types.UID) error {
+	return s.deleteAllocations(uid, sets.NewString())
+}
+
+// ListIPAddressAllocationCRUID returns the UIDs of the IPAddressAllocation CRs which have NSX IP allocations.
+func (s *IPAddressAllocationService) ListIPAddressAllocationCRUID() sets.String {
+	return s.poolAllocationStore.ListIndexFuncValues(common.TagScopeIPAllocationCRUID).Union(s.vpcAllocationStore.ListIndexFuncValues(common.TagScopeIPAllocationCRUID))
+}
+
+// ListNSXIPAddressAllocationCRUID refreshes the stores with the NSX IP allocations tagged with the cluster, so that the
+// allocations missing from the stores, e.g. the ones whose creation was interrupted, can be released too, and returns
+// the UIDs of the IPAddressAllocation CRs which have NSX IP allocations.
+func (s *IPAddressAllocationService) ListNSXIPAddressAllocationCRUID() (sets.String, error) {
+	if _, err := s.SearchResource(common.ResourceTypeIPAllocation, s.poolAllocationStore); err != nil {
+		return nil, err
+	}
+	if _, err := s.SearchResource(common.ResourceTypeVPCIPAllocation, s.vpcAllocationStore); err != nil {
+		return nil, err
+	}
+	return s.ListIPAddressAllocationCRUID(), nil
+}
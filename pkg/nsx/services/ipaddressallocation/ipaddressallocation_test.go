@@ -0,0 +1,253 @@
+package ipaddressallocation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/ip_pools"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipam"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// fakePoolAllocationClient allocates the IPs in sequence once allocate is set.
+type fakePoolAllocationClient struct {
+	ip_pools.IpAllocationsClient
+	allocate    bool
+	allocations map[string]model.IpAddressAllocation
+	allocated   int
+	patched     int
+	patchErr    error
+}
+
+func (c *fakePoolAllocationClient) Patch(_ string, _ string, _ string, id string, allocation model.IpAddressAllocation) error {
+	if c.patchErr != nil {
+		return c.patchErr
+	}
+	c.patched++
+	c.allocations[id] = allocation
+	return nil
+}
+
+func (c *fakePoolAllocationClient) Get(_ string, _ string, _ string, id string) (model.IpAddressAllocation, error) {
+	allocation, ok := c.allocations[id]
+	if !ok {
+		return allocation, vapierrors.NotFound{}
+	}
+	if c.allocate && allocation.AllocationIp == nil {
+		c.allocated++
+		allocation.AllocationIp = String(fmt.Sprintf("172.16.0.%d", c.allocated))
+		c.allocations[id] = allocation
+	}
+	return allocation, nil
+}
+
+func (c *fakePoolAllocationClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.allocations, id)
+	return nil
+}
+
+type fakeVPCAllocationClient struct {
+	vpcs.IpAddressAllocationsClient
+	allocations map[string]model.VpcIpAddressAllocation
+	patchErr    error
+}
+
+func (c *fakeVPCAllocationClient) Patch(_ string, _ string, _ string, id string, allocation model.VpcIpAddressAllocation) error {
+	if c.patchErr != nil {
+		return c.patchErr
+	}
+	allocation.AllocationIp = String(fmt.Sprintf("10.10.0.%d", len(c.allocations)+1))
+	c.allocations[id] = allocation
+	return nil
+}
+
+func (c *fakeVPCAllocationClient) Get(_ string, _ string, _ string, id string) (model.VpcIpAddressAllocation, error) {
+	allocation, ok := c.allocations[id]
+	if !ok {
+		return allocation, vapierrors.NotFound{}
+	}
+	return allocation, nil
+}
+
+func (c *fakeVPCAllocationClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.allocations, id)
+	return nil
+}
+
+// fakeQueryClient finds the NSX IP allocations tagged with the cluster.
+type fakeQueryClient struct {
+	poolAllocations []model.IpAddressAllocation
+	vpcAllocations  []model.VpcIpAddressAllocation
+}
+
+func (c *fakeQueryClient) List(query string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	var results []*data.StructValue
+	if strings.HasPrefix(query, common.ResourceType+":"+common.ResourceTypeIPAllocation+" ") {
+		for _, allocation := range c.poolAllocations {
+			dataValue, _ := common.NewConverter().ConvertToVapi(allocation, model.IpAddressAllocationBindingType())
+			results = append(results, dataValue.(*data.StructValue))
+		}
+	} else {
+		for _, allocation := range c.vpcAllocations {
+			dataValue, _ := common.NewConverter().ConvertToVapi(allocation, model.VpcIpAddressAllocationBindingType())
+			results = append(results, dataValue.(*data.StructValue))
+		}
+	}
+	count := int64(len(results))
+	return model.SearchResponse{Results: results, ResultCount: &count}, nil
+}
+
+func newFakeIPAddressAllocationService() (*IPAddressAllocationService, *fakePoolAllocationClient, *fakeVPCAllocationClient) {
+	poolClient := &fakePoolAllocationClient{allocations: map[string]model.IpAddressAllocation{}}
+	vpcClient := &fakeVPCAllocationClient{allocations: map[string]model.VpcIpAddressAllocation{}}
+	s := &IPAddressAllocationService{
+		Service: common.Service{
+			NSXClient: &nsx.Client{
+				ProjectIPAllocationClient: poolClient,
+				VPCIPAllocationClient:     vpcClient,
+			},
+			NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}},
+		},
+		poolAllocationStore: &PoolAllocationStore{ResourceStore: common.ResourceStore{
+			Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeIPAllocationCRUID: ipAllocationIndexFunc}),
+			BindingType: model.IpAddressAllocationBindingType(),
+		}},
+		vpcAllocationStore: &VPCAllocationStore{ResourceStore: common.ResourceStore{
+			Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeIPAllocationCRUID: ipAllocationIndexFunc}),
+			BindingType: model.VpcIpAddressAllocationBindingType(),
+		}},
+	}
+	s.allocator = ipam.NewIPAMService(s.Service)
+	return s, poolClient, vpcClient
+}
+
+func TestIPAddressAllocationService_PoolAllocation(t *testing.T) {
+	s, poolClient, _ := newFakeIPAddressAllocationService()
+	nsxVPC := &model.Vpc{Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	poolPath := "/orgs/default/projects/p1/infra/ip-pools/ippool_pool-uid1"
+	obj := &v1alpha1.IPAddressAllocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "alloc1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.IPAddressAllocationSpec{IPPoolName: "pool1", Count: 2},
+	}
+
+	// the IPs are not reported until NSX allocates them
+	ips, allocated, err := s.CreateOrUpdateIPAddressAllocation(obj, nsxVPC, poolPath)
+	require.Nil(t, err)
+	assert.False(t, allocated)
+	assert.Empty(t, ips)
+	assert.Equal(t, 2, len(poolClient.allocations))
+
+	poolClient.allocate = true
+	ips, allocated, err = s.CreateOrUpdateIPAddressAllocation(obj, nsxVPC, poolPath)
+	require.Nil(t, err)
+	assert.True(t, allocated)
+	assert.Equal(t, []string{"172.16.0.1", "172.16.0.2"}, ips)
+
+	// the allocated IPs are kept without patching NSX again
+	patched := poolClient.patched
+	again, _, err := s.CreateOrUpdateIPAddressAllocation(obj, nsxVPC, poolPath)
+	require.Nil(t, err)
+	assert.Equal(t, ips, again)
+	assert.Equal(t, patched, poolClient.patched)
+
+	// the extra IP is released when the count is decreased
+	obj.Spec.Count = 1
+	ips, _, err = s.CreateOrUpdateIPAddressAllocation(obj, nsxVPC, poolPath)
+	require.Nil(t, err)
+	assert.Equal(t, again[:1], ips)
+	assert.Equal(t, 1, len(poolClient.allocations))
+	assert.Equal(t, []string{"uid1"}, s.ListIPAddressAllocationCRUID().List())
+
+	assert.Nil(t, s.DeleteIPAddressAllocationByCRUID("uid1"))
+	assert.Empty(t, poolClient.allocations)
+	assert.Empty(t, s.ListIPAddressAllocationCRUID())
+}
+
+func TestIPAddressAllocationService_VPCAllocation(t *testing.T) {
+	s, poolClient, vpcClient := newFakeIPAddressAllocationService()
+	nsxVPC := &model.Vpc{Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	poolPath := "/orgs/default/projects/p1/infra/ip-pools/ippool_pool-uid1"
+	obj := &v1alpha1.IPAddressAllocation{ObjectMeta: metav1.ObjectMeta{Name: "alloc1", Namespace: "ns1", UID: "uid1"}}
+
+	ips, allocated, err := s.CreateOrUpdateIPAddressAllocation(obj, nsxVPC, "")
+	require.Nil(t, err)
+	assert.True(t, allocated)
+	assert.Equal(t, []string{"10.10.0.1"}, ips)
+	assert.Equal(t, model.VpcIpAddressAllocation_IP_ADDRESS_BLOCK_VISIBILITY_PUBLIC, *vpcClient.allocations["ipalloc_uid1_0"].IpAddressBlockVisibility)
+
+	// the external IP is released when the IPs are switched to an IPPool
+	poolClient.allocate = true
+	ips, _, err = s.CreateOrUpdateIPAddressAllocation(obj, nsxVPC, poolPath)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"172.16.0.1"}, ips)
+	assert.Empty(t, vpcClient.allocations)
+	assert.Equal(t, 1, len(poolClient.allocations))
+}
+
+func TestIPAddressAllocationService_Exhausted(t *testing.T) {
+	s, poolClient, vpcClient := newFakeIPAddressAllocationService()
+	nsxVPC := &model.Vpc{Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	poolPath := "/orgs/default/projects/p1/infra/ip-pools/ippool_pool-uid1"
+	obj := &v1alpha1.IPAddressAllocation{ObjectMeta: metav1.ObjectMeta{Name: "alloc1", Namespace: "ns1", UID: "uid1"}}
+	exhausted := nsxutil.IPExhaustedError{}
+
+	vpcClient.patchErr = vapierrors.UnableToAllocateResource{}
+	_, _, err := s.CreateOrUpdateIPAddressAllocation(obj, nsxVPC, "")
+	require.True(t, errors.As(err, &exhausted))
+	assert.Equal(t, *nsxVPC.Path, exhausted.Pool)
+
+	poolClient.patchErr = vapierrors.UnableToAllocateResource{}
+	_, _, err = s.CreateOrUpdateIPAddressAllocation(obj, nsxVPC, poolPath)
+	require.True(t, errors.As(err, &exhausted))
+	assert.Equal(t, poolPath, exhausted.Pool)
+}
+
+func TestIPAddressAllocationService_ListNSXIPAddressAllocationCRUID(t *testing.T) {
+	s, poolClient, _ := newFakeIPAddressAllocationService()
+	nsxVPC := &model.Vpc{Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	poolPath := "/orgs/default/projects/p1/infra/ip-pools/ippool_pool-uid1"
+	_, _, err := s.CreateOrUpdateIPAddressAllocation(&v1alpha1.IPAddressAllocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "alloc1", Namespace: "ns1", UID: "uid1"},
+	}, nsxVPC, poolPath)
+	require.Nil(t, err)
+
+	// the allocations missing from the stores are found in NSX
+	leaked := model.IpAddressAllocation{
+		Id:   String("ipalloc_uid2_0"),
+		Path: String(poolPath + "/ip-allocations/ipalloc_uid2_0"),
+		Tags: []model.Tag{{Scope: String(common.TagScopeIPAllocationCRUID), Tag: String("uid2")}},
+	}
+	poolClient.allocations[*leaked.Id] = leaked
+	queryClient := &fakeQueryClient{
+		poolAllocations: []model.IpAddressAllocation{leaked},
+		vpcAllocations: []model.VpcIpAddressAllocation{{
+			Id:   String("ipalloc_uid3_0"),
+			Path: String("/orgs/default/projects/p1/vpcs/vpc1/ip-address-allocations/ipalloc_uid3_0"),
+			Tags: []model.Tag{{Scope: String(common.TagScopeIPAllocationCRUID), Tag: String("uid3")}},
+		}},
+	}
+	s.NSXClient.QueryClient = queryClient
+	s.NSXClient.NsxConfig = s.NSXConfig
+	uids, err := s.ListNSXIPAddressAllocationCRUID()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"uid1", "uid2", "uid3"}, uids.List())
+
+	assert.Nil(t, s.DeleteIPAddressAllocationByCRUID("uid2"))
+	_, ok := poolClient.allocations[*leaked.Id]
+	assert.False(t, ok)
+}
@@ -0,0 +1,108 @@
+package ipaddressallocation
+
+import (
+	"errors"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// keyFunc is used to get the key of a resource, which is the path of the allocation as the allocations in different
+// IP pools may share the same ID.
+func keyFunc(obj interface{}) (string, error) {
+	switch v := obj.(type) {
+	case model.IpAddressAllocation:
+		return *v.Path, nil
+	case model.VpcIpAddressAllocation:
+		return *v.Path, nil
+	default:
+		return "", errors.New("keyFunc doesn't support unknown type")
+	}
+}
+
+// ipAllocationIndexFunc indexes the NSX IP allocations by the UID of the IPAddressAllocation CR they are created for.
+func ipAllocationIndexFunc(obj interface{}) ([]string, error) {
+	switch o := obj.(type) {
+	case model.IpAddressAllocation:
+		return filterTag(o.Tags, common.TagScopeIPAllocationCRUID), nil
+	case model.VpcIpAddressAllocation:
+		return filterTag(o.Tags, common.TagScopeIPAllocationCRUID), nil
+	default:
+		return nil, errors.New("ipAllocationIndexFunc doesn't support unknown type")
+	}
+}
+
+func filterTag(tags []model.Tag, tagScope string) []string {
+	res := make([]string, 0, 5)
+	for _, tag := range tags {
+		if *tag.Scope == tagScope {
+			res = append(res, *tag.Tag)
+		}
+	}
+	return res
+}
+
+// PoolAllocationStore is a store for the IP allocations in NSX IP pools
+type PoolAllocationStore struct {
+	common.ResourceStore
+}
+
+func (poolAllocationStore *PoolAllocationStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	allocation := i.(*model.IpAddressAllocation)
+	if allocation.MarkedForDelete != nil && *allocation.MarkedForDelete {
+		if err := poolAllocationStore.Delete(*allocation); err != nil {
+			return err
+		}
+		log.V(1).Info("delete IPAllocation from store", "IPAllocation", allocation)
+	} else {
+		if err := poolAllocationStore.Add(*allocation); err != nil {
+			return err
+		}
+		log.V(1).Info("add IPAllocation to store", "IPAllocation", allocation)
+	}
+	return nil
+}
+
+func (poolAllocationStore *PoolAllocationStore) GetByIndex(key string, value string) []model.IpAddressAllocation {
+	allocations := make([]model.IpAddressAllocation, 0)
+	for _, allocation := range poolAllocationStore.ResourceStore.GetByIndex(key, value) {
+		allocations = append(allocations, allocation.(model.IpAddressAllocation))
+	}
+	return allocations
+}
+
+// VPCAllocationStore is a store for the IP allocations from the external IP blocks of NSX VPCs
+type VPCAllocationStore struct {
+	common.ResourceStore
+}
+
+func (vpcAllocationStore *VPCAllocationStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	allocation := i.(*model.VpcIpAddressAllocation)
+	if allocation.MarkedForDelete != nil && *allocation.MarkedForDelete {
+		if err := vpcAllocationStore.Delete(*allocation); err != nil {
+			return err
+		}
+		log.V(1).Info("delete VPC IPAllocation from store", "IPAllocation", allocation)
+	} else {
+		if err := vpcAllocationStore.Add(*allocation); err != nil {
+			return err
+		}
+		log.V(1).Info("add VPC IPAllocation to store", "IPAllocation", allocation)
+	}
+	return nil
+}
+
+func (vpcAllocationStore *VPCAllocationStore) GetByIndex(key string, value string) []model.VpcIpAddressAllocation {
+	allocations := make([]model.VpcIpAddressAllocation, 0)
+	for _, allocation := range vpcAllocationStore.ResourceStore.GetByIndex(key, value) {
+		allocations = append(allocations, allocation.(model.VpcIpAddressAllocation))
+	}
+	return allocations
+}
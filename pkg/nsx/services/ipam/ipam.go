@@ -0,0 +1,205 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+var (
+	log    = logger.Log
+	String = common.String
+)
+
+// IPFamily is the family of the IPs requested from the IPAM.
+type IPFamily string
+
+const (
+	IPFamilyIPv4      IPFamily = "IPv4"
+	IPFamilyIPv6      IPFamily = "IPv6"
+	IPFamilyDualStack IPFamily = "DualStack"
+)
+
+// Families returns the single families of the IPs requested for the family.
+func (f IPFamily) Families() []IPFamily {
+	if f == IPFamilyDualStack {
+		return []IPFamily{IPFamilyIPv4, IPFamilyIPv6}
+	}
+	return []IPFamily{f}
+}
+
+// FamilyOf returns the family of the IP or CIDR, "" if it is invalid.
+func FamilyOf(ip string) IPFamily {
+	if _, ipNet, err := net.ParseCIDR(ip); err == nil {
+		ip = ipNet.IP.String()
+	}
+	addr := net.ParseIP(ip)
+	switch {
+	case addr == nil:
+		return ""
+	case addr.To4() != nil:
+		return IPFamilyIPv4
+	default:
+		return IPFamilyIPv6
+	}
+}
+
+// Request describes the NSX IP allocations requested from a pool.
+type Request struct {
+	// ID of the NSX allocation, the IPv6 allocation of a dual-stack request gets the ID suffixed with _ipv6.
+	ID          string
+	DisplayName string
+	Tags        []model.Tag
+	// IPs requested, at most one of each family, NSX picks a free IP of the pool for the other families.
+	IPs []string
+}
+
+// Allocation is an NSX IP allocation of a Request.
+type Allocation struct {
+	ID     string
+	Path   string
+	Family IPFamily
+	// IP is "" until NSX allocates it.
+	IP string
+}
+
+// Allocator allocates the IPs of the NSX Operator resources. The pool is the path of an NSX IP pool under /infra or in
+// an NSX Project, of an NSX VPC whose external IP blocks the IPs are allocated from, or of an NSX VPC Subnet.
+type Allocator interface {
+	// AllocateIP creates or updates the NSX allocations of the family requested by req in the pool, and returns them
+	// with the IPs NSX has allocated so far.
+	AllocateIP(family IPFamily, pool string, req *Request) ([]Allocation, error)
+	// GetIP returns the IP of the NSX allocation in the pool, "" if NSX has not allocated it yet.
+	GetIP(pool string, id string) (string, error)
+	// ReleaseIP deletes the NSX allocation from the pool.
+	ReleaseIP(pool string, id string) error
+}
+
+// IPAMService allocates the IPs with the NSX IP allocation APIs of the pools.
+type IPAMService struct {
+	common.Service
+}
+
+func NewIPAMService(service common.Service) *IPAMService {
+	return &IPAMService{Service: service}
+}
+
+// poolKind is the kind of the pool the IPs are allocated from.
+type poolKind int
+
+const (
+	poolKindInfraIPPool poolKind = iota
+	poolKindProjectIPPool
+	poolKindVPC
+	poolKindSubnet
+)
+
+// pool is the parsed path of a pool.
+type pool struct {
+	kind     poolKind
+	path     string
+	org      string
+	project  string
+	vpcID    string
+	id       string
+	subnetID string
+}
+
+func parsePool(path string) (*pool, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "infra" && parts[1] == "ip-pools":
+		return &pool{kind: poolKindInfraIPPool, path: path, id: parts[2]}, nil
+	case len(parts) == 7 && parts[0] == "orgs" && parts[2] == "projects" && parts[4] == "infra" && parts[5] == "ip-pools":
+		return &pool{kind: poolKindProjectIPPool, path: path, org: parts[1], project: parts[3], id: parts[6]}, nil
+	case len(parts) == 6 && parts[0] == "orgs" && parts[2] == "projects" && parts[4] == "vpcs":
+		return &pool{kind: poolKindVPC, path: path, org: parts[1], project: parts[3], vpcID: parts[5]}, nil
+	case len(parts) == 8 && parts[0] == "orgs" && parts[2] == "projects" && parts[4] == "vpcs" && parts[6] == "subnets":
+		return &pool{kind: poolKindSubnet, path: path, org: parts[1], project: parts[3], vpcID: parts[5], subnetID: parts[7]}, nil
+	}
+	return nil, fmt.Errorf("invalid IP allocation pool %s", path)
+}
+
+// AllocationID returns the ID of the NSX allocation of the family for a request of requestedFamily.
+func AllocationID(id string, requestedFamily, family IPFamily) string {
+	if requestedFamily == IPFamilyDualStack && family == IPFamilyIPv6 {
+		return id + "_ipv6"
+	}
+	return id
+}
+
+// requestedIP returns the IP of the family requested by req, "" if NSX picks one.
+func requestedIP(req *Request, family IPFamily) string {
+	for _, ip := range req.IPs {
+		if FamilyOf(ip) == family {
+			return ip
+		}
+	}
+	return ""
+}
+
+func (s *IPAMService) AllocateIP(family IPFamily, poolPath string, req *Request) ([]Allocation, error) {
+	p, err := parsePool(poolPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range req.IPs {
+		f := FamilyOf(ip)
+		if f == "" {
+			return nil, fmt.Errorf("invalid IP %s requested", ip)
+		}
+		if family != IPFamilyDualStack && f != family {
+			return nil, fmt.Errorf("%s IP %s requested for %s allocation %s", f, ip, family, req.ID)
+		}
+	}
+	var allocations []Allocation
+	for _, f := range family.Families() {
+		allocation := Allocation{ID: AllocationID(req.ID, family, f), Family: f, IP: requestedIP(req, f)}
+		if err := s.patchAllocation(p, &allocation, req); err != nil {
+			return nil, common.TransIPExhaustedError(err, poolPath)
+		}
+		if allocation.IP == "" {
+			if allocation.IP, err = s.getIP(p, allocation.ID); err != nil {
+				return nil, err
+			}
+		}
+		if allocation.IP != "" && FamilyOf(allocation.IP) != f {
+			// NSX picks the IPs of an IP pool from its subnets regardless of the family
+			if err := s.deleteAllocation(p, allocation.ID); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("no %s IP available in %s, got %s", f, poolPath, allocation.IP)
+		}
+		log.Info("successfully allocated IP", "pool", poolPath, "allocation", allocation.ID, "family", f, "IP", allocation.IP)
+		allocations = append(allocations, allocation)
+	}
+	return allocations, nil
+}
+
+func (s *IPAMService) GetIP(poolPath string, id string) (string, error) {
+	p, err := parsePool(poolPath)
+	if err != nil {
+		return "", err
+	}
+	return s.getIP(p, id)
+}
+
+func (s *IPAMService) ReleaseIP(poolPath string, id string) error {
+	p, err := parsePool(poolPath)
+	if err != nil {
+		return err
+	}
+	if err := s.deleteAllocation(p, id); err != nil {
+		return err
+	}
+	log.Info("successfully released IP", "pool", poolPath, "allocation", id)
+	return nil
+}
@@ -0,0 +1,167 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	infra_ip_pools "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/ip_pools"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	project_ip_pools "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/ip_pools"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// fakeInfraIPAllocationClient fails to allocate as if the pool is exhausted.
+type fakeInfraIPAllocationClient struct {
+	infra_ip_pools.IpAllocationsClient
+}
+
+func (c *fakeInfraIPAllocationClient) Patch(_ string, _ string, _ model.IpAddressAllocation) error {
+	return vapierrors.UnableToAllocateResource{}
+}
+
+// fakeProjectIPAllocationClient allocates the IPs from ip in sequence.
+type fakeProjectIPAllocationClient struct {
+	project_ip_pools.IpAllocationsClient
+	ip          string
+	allocations map[string]model.IpAddressAllocation
+}
+
+func (c *fakeProjectIPAllocationClient) Patch(_ string, _ string, _ string, id string, allocation model.IpAddressAllocation) error {
+	c.allocations[id] = allocation
+	return nil
+}
+
+func (c *fakeProjectIPAllocationClient) Get(_ string, _ string, _ string, id string) (model.IpAddressAllocation, error) {
+	allocation := c.allocations[id]
+	if allocation.AllocationIp == nil {
+		allocation.AllocationIp = String(fmt.Sprintf("%s%d", c.ip, len(c.allocations)))
+		c.allocations[id] = allocation
+	}
+	return allocation, nil
+}
+
+func (c *fakeProjectIPAllocationClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.allocations, id)
+	return nil
+}
+
+// fakeVPCIPAllocationClient allocates the IPs of the requested types.
+type fakeVPCIPAllocationClient struct {
+	vpcs.IpAddressAllocationsClient
+	allocations map[string]model.VpcIpAddressAllocation
+}
+
+func (c *fakeVPCIPAllocationClient) Patch(_ string, _ string, _ string, id string, allocation model.VpcIpAddressAllocation) error {
+	if allocation.AllocationIp == nil {
+		if *allocation.IpAddressType == model.VpcIpAddressAllocation_IP_ADDRESS_TYPE_IPV6 {
+			allocation.AllocationIp = String("2001:db8::10")
+		} else {
+			allocation.AllocationIp = String("100.64.0.10")
+		}
+	}
+	c.allocations[id] = allocation
+	return nil
+}
+
+func (c *fakeVPCIPAllocationClient) Get(_ string, _ string, _ string, id string) (model.VpcIpAddressAllocation, error) {
+	return c.allocations[id], nil
+}
+
+func (c *fakeVPCIPAllocationClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.allocations, id)
+	return nil
+}
+
+func newFakeIPAMService() (*IPAMService, *fakeProjectIPAllocationClient, *fakeVPCIPAllocationClient) {
+	projectClient := &fakeProjectIPAllocationClient{ip: "192.168.0.", allocations: map[string]model.IpAddressAllocation{}}
+	vpcClient := &fakeVPCIPAllocationClient{allocations: map[string]model.VpcIpAddressAllocation{}}
+	return NewIPAMService(common.Service{
+		NSXClient: &nsx.Client{
+			InfraIPAllocationClient:   &fakeInfraIPAllocationClient{},
+			ProjectIPAllocationClient: projectClient,
+			VPCIPAllocationClient:     vpcClient,
+		},
+		NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}},
+	}), projectClient, vpcClient
+}
+
+func TestIPFamily(t *testing.T) {
+	assert.Equal(t, []IPFamily{IPFamilyIPv4, IPFamilyIPv6}, IPFamilyDualStack.Families())
+	assert.Equal(t, []IPFamily{IPFamilyIPv6}, IPFamilyIPv6.Families())
+	assert.Equal(t, IPFamilyIPv4, FamilyOf("10.0.0.1"))
+	assert.Equal(t, IPFamilyIPv4, FamilyOf("10.0.0.0/24"))
+	assert.Equal(t, IPFamilyIPv6, FamilyOf("fd00::1"))
+	assert.Equal(t, IPFamily(""), FamilyOf("10.0.0"))
+	assert.Equal(t, "alloc1", AllocationID("alloc1", IPFamilyIPv6, IPFamilyIPv6))
+	assert.Equal(t, "alloc1_ipv6", AllocationID("alloc1", IPFamilyDualStack, IPFamilyIPv6))
+}
+
+func TestParsePool(t *testing.T) {
+	p, err := parsePool("/infra/ip-pools/pool1")
+	require.Nil(t, err)
+	assert.Equal(t, &pool{kind: poolKindInfraIPPool, path: "/infra/ip-pools/pool1", id: "pool1"}, p)
+	p, err = parsePool("/orgs/default/projects/p1/infra/ip-pools/pool1")
+	require.Nil(t, err)
+	assert.Equal(t, poolKindProjectIPPool, p.kind)
+	p, err = parsePool("/orgs/default/projects/p1/vpcs/vpc1")
+	require.Nil(t, err)
+	assert.Equal(t, poolKindVPC, p.kind)
+	p, err = parsePool("/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1")
+	require.Nil(t, err)
+	assert.Equal(t, &pool{kind: poolKindSubnet, path: "/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1",
+		org: "default", project: "p1", vpcID: "vpc1", subnetID: "subnet1"}, p)
+	_, err = parsePool("/infra/ip-blocks/block1")
+	assert.NotNil(t, err)
+}
+
+func TestIPAMService_AllocateIP(t *testing.T) {
+	s, projectClient, vpcClient := newFakeIPAMService()
+	vpcPath := "/orgs/default/projects/p1/vpcs/vpc1"
+	req := &Request{ID: "alloc1", DisplayName: "alloc1"}
+
+	// both the families are allocated from the external IP blocks of the VPC
+	allocations, err := s.AllocateIP(IPFamilyDualStack, vpcPath, req)
+	require.Nil(t, err)
+	assert.Equal(t, []Allocation{
+		{ID: "alloc1", Path: vpcPath + "/ip-address-allocations/alloc1", Family: IPFamilyIPv4, IP: "100.64.0.10"},
+		{ID: "alloc1_ipv6", Path: vpcPath + "/ip-address-allocations/alloc1_ipv6", Family: IPFamilyIPv6, IP: "2001:db8::10"},
+	}, allocations)
+	ip, err := s.GetIP(vpcPath, "alloc1_ipv6")
+	require.Nil(t, err)
+	assert.Equal(t, "2001:db8::10", ip)
+	assert.Nil(t, s.ReleaseIP(vpcPath, "alloc1_ipv6"))
+	assert.Equal(t, 1, len(vpcClient.allocations))
+
+	// the requested IP must be of the requested family
+	_, err = s.AllocateIP(IPFamilyIPv4, vpcPath, &Request{ID: "alloc2", IPs: []string{"2001:db8::20"}})
+	assert.NotNil(t, err)
+	_, err = s.AllocateIP(IPFamilyIPv4, vpcPath, &Request{ID: "alloc2", IPs: []string{"100.64.0"}})
+	assert.NotNil(t, err)
+
+	projectPool := "/orgs/default/projects/p1/infra/ip-pools/pool1"
+	allocations, err = s.AllocateIP(IPFamilyIPv4, projectPool, &Request{ID: "alloc3", IPs: []string{"192.168.0.20"}})
+	require.Nil(t, err)
+	assert.Equal(t, []Allocation{{ID: "alloc3", Path: projectPool + "/ip-allocations/alloc3", Family: IPFamilyIPv4, IP: "192.168.0.20"}}, allocations)
+
+	// the IP NSX picks of another family is released
+	_, err = s.AllocateIP(IPFamilyIPv6, projectPool, &Request{ID: "alloc4"})
+	assert.Contains(t, err.Error(), "no IPv6 IP available")
+	assert.Equal(t, 1, len(projectClient.allocations))
+
+	_, err = s.AllocateIP(IPFamilyIPv4, "/infra/ip-pools/pool2", req)
+	assert.True(t, errors.As(err, &nsxutil.IPExhaustedError{}))
+	_, err = s.AllocateIP(IPFamilyIPv4, "/infra/ip-blocks/block1", req)
+	assert.NotNil(t, err)
+}
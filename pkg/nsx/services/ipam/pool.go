@@ -0,0 +1,215 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// vpcIPAddressTypes are the types of the VPC allocations of the families.
+var vpcIPAddressTypes = map[IPFamily]string{
+	IPFamilyIPv4: model.VpcIpAddressAllocation_IP_ADDRESS_TYPE_IPV4,
+	IPFamilyIPv6: model.VpcIpAddressAllocation_IP_ADDRESS_TYPE_IPV6,
+}
+
+// patchAllocation creates or updates the NSX allocation in the pool, and fills in its path.
+func (s *IPAMService) patchAllocation(p *pool, allocation *Allocation, req *Request) error {
+	var ip *string
+	if allocation.IP != "" {
+		ip = String(allocation.IP)
+	}
+	switch p.kind {
+	case poolKindInfraIPPool, poolKindProjectIPPool:
+		allocation.Path = fmt.Sprintf("%s/ip-allocations/%s", p.path, allocation.ID)
+		nsxAllocation := model.IpAddressAllocation{
+			Id:           String(allocation.ID),
+			DisplayName:  String(req.DisplayName),
+			Tags:         req.Tags,
+			AllocationIp: ip,
+		}
+		if p.kind == poolKindInfraIPPool {
+			return s.NSXClient.InfraIPAllocationClient.Patch(p.id, allocation.ID, nsxAllocation)
+		}
+		return s.NSXClient.ProjectIPAllocationClient.Patch(p.org, p.project, p.id, allocation.ID, nsxAllocation)
+	case poolKindVPC:
+		allocation.Path = fmt.Sprintf("%s/ip-address-allocations/%s", p.path, allocation.ID)
+		return s.NSXClient.VPCIPAllocationClient.Patch(p.org, p.project, p.vpcID, allocation.ID, model.VpcIpAddressAllocation{
+			Id:                       String(allocation.ID),
+			DisplayName:              String(req.DisplayName),
+			Tags:                     req.Tags,
+			AllocationIp:             ip,
+			IpAddressBlockVisibility: String(model.VpcIpAddressAllocation_IP_ADDRESS_BLOCK_VISIBILITY_PUBLIC),
+			IpAddressType:            String(vpcIPAddressTypes[allocation.Family]),
+		})
+	default:
+		return s.patchSubnetAllocation(p, allocation, req)
+	}
+}
+
+// patchSubnetAllocation allocates the IP from the IP pool of the family in the NSX Subnet. The IP already allocated
+// with another ID is rejected.
+func (s *IPAMService) patchSubnetAllocation(p *pool, allocation *Allocation, req *Request) error {
+	pools, err := s.listSubnetIPPools(p)
+	if err != nil {
+		return err
+	}
+	if len(pools) == 0 {
+		return fmt.Errorf("no IP pool found in Subnet %s", p.subnetID)
+	}
+	for _, ipPool := range pools {
+		allocations, err := s.listSubnetIPAllocations(p, *ipPool.Id)
+		if err != nil {
+			return err
+		}
+		for _, existing := range allocations {
+			if *existing.Id == allocation.ID && (allocation.IP == "" || existing.AllocationIp != nil && sameIP(*existing.AllocationIp, allocation.IP)) {
+				allocation.Path = subnetAllocationPath(p, *ipPool.Id, allocation.ID)
+				return nil
+			}
+			if allocation.IP != "" && existing.AllocationIp != nil && sameIP(*existing.AllocationIp, allocation.IP) {
+				return nsxutil.RestrictionError{Desc: fmt.Sprintf("IP address %s is already allocated in Subnet %s", allocation.IP, p.subnetID)}
+			}
+		}
+	}
+	ipPool := pools[0]
+	for _, candidate := range pools {
+		// the Subnet IP pools are named after their families, e.g. static-ipv4-default
+		if strings.Contains(strings.ToLower(*candidate.Id), strings.ToLower(string(allocation.Family))) {
+			ipPool = candidate
+			break
+		}
+	}
+	allocation.Path = subnetAllocationPath(p, *ipPool.Id, allocation.ID)
+	var ip *string
+	if allocation.IP != "" {
+		ip = String(allocation.IP)
+	}
+	return s.NSXClient.SubnetIPAllocationClient.Patch(p.org, p.project, p.vpcID, p.subnetID, *ipPool.Id, allocation.ID, model.IpAddressAllocation{
+		Id:           String(allocation.ID),
+		DisplayName:  String(req.DisplayName),
+		AllocationIp: ip,
+		Tags:         req.Tags,
+	})
+}
+
+func (s *IPAMService) getIP(p *pool, id string) (string, error) {
+	var ip *string
+	switch p.kind {
+	case poolKindInfraIPPool:
+		nsxAllocation, err := s.NSXClient.InfraIPAllocationClient.Get(p.id, id)
+		if err != nil {
+			return "", err
+		}
+		ip = nsxAllocation.AllocationIp
+	case poolKindProjectIPPool:
+		nsxAllocation, err := s.NSXClient.ProjectIPAllocationClient.Get(p.org, p.project, p.id, id)
+		if err != nil {
+			return "", err
+		}
+		ip = nsxAllocation.AllocationIp
+	case poolKindVPC:
+		nsxAllocation, err := s.NSXClient.VPCIPAllocationClient.Get(p.org, p.project, p.vpcID, id)
+		if err != nil {
+			return "", err
+		}
+		ip = nsxAllocation.AllocationIp
+	default:
+		pools, err := s.listSubnetIPPools(p)
+		if err != nil {
+			return "", err
+		}
+		for _, ipPool := range pools {
+			allocations, err := s.listSubnetIPAllocations(p, *ipPool.Id)
+			if err != nil {
+				return "", err
+			}
+			for _, allocation := range allocations {
+				if *allocation.Id == id {
+					ip = allocation.AllocationIp
+				}
+			}
+		}
+	}
+	if ip == nil {
+		log.V(1).Info("IP is not allocated yet", "pool", p.path, "allocation", id)
+		return "", nil
+	}
+	return *ip, nil
+}
+
+func (s *IPAMService) deleteAllocation(p *pool, id string) error {
+	switch p.kind {
+	case poolKindInfraIPPool:
+		return s.NSXClient.InfraIPAllocationClient.Delete(p.id, id)
+	case poolKindProjectIPPool:
+		return s.NSXClient.ProjectIPAllocationClient.Delete(p.org, p.project, p.id, id)
+	case poolKindVPC:
+		return s.NSXClient.VPCIPAllocationClient.Delete(p.org, p.project, p.vpcID, id)
+	}
+	pools, err := s.listSubnetIPPools(p)
+	if err != nil {
+		return err
+	}
+	for _, ipPool := range pools {
+		allocations, err := s.listSubnetIPAllocations(p, *ipPool.Id)
+		if err != nil {
+			return err
+		}
+		for _, allocation := range allocations {
+			if *allocation.Id != id {
+				continue
+			}
+			if err := s.NSXClient.SubnetIPAllocationClient.Delete(p.org, p.project, p.vpcID, p.subnetID, *ipPool.Id, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *IPAMService) listSubnetIPPools(p *pool) ([]model.IpAddressPool, error) {
+	var pools []model.IpAddressPool
+	var cursor *string
+	for {
+		result, err := s.NSXClient.IPPoolClient.List(p.org, p.project, p.vpcID, p.subnetID, cursor, nil, nil, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		pools = append(pools, result.Results...)
+		if result.Cursor == nil || *result.Cursor == "" {
+			return pools, nil
+		}
+		cursor = result.Cursor
+	}
+}
+
+func (s *IPAMService) listSubnetIPAllocations(p *pool, poolID string) ([]model.IpAddressAllocation, error) {
+	var allocations []model.IpAddressAllocation
+	var cursor *string
+	for {
+		result, err := s.NSXClient.SubnetIPAllocationClient.List(p.org, p.project, p.vpcID, p.subnetID, poolID, cursor, nil, nil, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, result.Results...)
+		if result.Cursor == nil || *result.Cursor == "" {
+			return allocations, nil
+		}
+		cursor = result.Cursor
+	}
+}
+
+func subnetAllocationPath(p *pool, poolID, id string) string {
+	return fmt.Sprintf("%s/ip-pools/%s/ip-allocations/%s", p.path, poolID, id)
+}
+
+func sameIP(a, b string) bool {
+	return net.ParseIP(a).Equal(net.ParseIP(b))
+}
@@ -0,0 +1,91 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package ipam
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets/ip_pools"
+
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+type fakeSubnetIPPoolClient struct {
+	subnets.IpPoolsClient
+	pools []model.IpAddressPool
+}
+
+func (c *fakeSubnetIPPoolClient) List(_ string, _ string, _ string, _ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.IpAddressPoolListResult, error) {
+	return model.IpAddressPoolListResult{Results: c.pools}, nil
+}
+
+// fakeSubnetIPAllocationClient keeps the allocations keyed by pool and ID.
+type fakeSubnetIPAllocationClient struct {
+	ip_pools.IpAllocationsClient
+	allocations map[string]map[string]model.IpAddressAllocation
+}
+
+func (c *fakeSubnetIPAllocationClient) List(_ string, _ string, _ string, _ string, poolID string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.IpAddressAllocationListResult, error) {
+	result := model.IpAddressAllocationListResult{}
+	for _, allocation := range c.allocations[poolID] {
+		result.Results = append(result.Results, allocation)
+	}
+	return result, nil
+}
+
+func (c *fakeSubnetIPAllocationClient) Patch(_ string, _ string, _ string, _ string, poolID string, id string, allocation model.IpAddressAllocation) error {
+	if c.allocations[poolID] == nil {
+		c.allocations[poolID] = map[string]model.IpAddressAllocation{}
+	}
+	if allocation.AllocationIp == nil {
+		allocation.AllocationIp = String("fd00::5")
+	}
+	c.allocations[poolID][id] = allocation
+	return nil
+}
+
+func (c *fakeSubnetIPAllocationClient) Delete(_ string, _ string, _ string, _ string, poolID string, id string) error {
+	delete(c.allocations[poolID], id)
+	return nil
+}
+
+func TestIPAMService_SubnetAllocation(t *testing.T) {
+	s, _, _ := newFakeIPAMService()
+	poolClient := &fakeSubnetIPPoolClient{}
+	allocationClient := &fakeSubnetIPAllocationClient{allocations: map[string]map[string]model.IpAddressAllocation{}}
+	s.NSXClient.IPPoolClient = poolClient
+	s.NSXClient.SubnetIPAllocationClient = allocationClient
+	subnetPath := "/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1"
+
+	// the Subnet has no IP pool yet
+	_, err := s.AllocateIP(IPFamilyIPv4, subnetPath, &Request{ID: "port1", IPs: []string{"10.0.0.5"}})
+	assert.NotNil(t, err)
+
+	poolClient.pools = []model.IpAddressPool{{Id: String("static-ipv4-default")}, {Id: String("static-ipv6-default")}}
+	allocations, err := s.AllocateIP(IPFamilyIPv4, subnetPath, &Request{ID: "port1", IPs: []string{"10.0.0.5"}})
+	require.Nil(t, err)
+	assert.Equal(t, subnetPath+"/ip-pools/static-ipv4-default/ip-allocations/port1", allocations[0].Path)
+	// allocating the same IP again is a no-op
+	_, err = s.AllocateIP(IPFamilyIPv4, subnetPath, &Request{ID: "port1", IPs: []string{"10.0.0.5"}})
+	require.Nil(t, err)
+
+	// the IPv6 IP is allocated from the IPv6 pool
+	allocations, err = s.AllocateIP(IPFamilyIPv6, subnetPath, &Request{ID: "port2"})
+	require.Nil(t, err)
+	assert.Equal(t, []Allocation{{ID: "port2", Path: subnetPath + "/ip-pools/static-ipv6-default/ip-allocations/port2", Family: IPFamilyIPv6, IP: "fd00::5"}}, allocations)
+
+	// the IP allocated with another ID is rejected
+	_, err = s.AllocateIP(IPFamilyIPv4, subnetPath, &Request{ID: "port3", IPs: []string{"10.0.0.5"}})
+	assert.True(t, errors.As(err, &nsxutil.RestrictionError{}))
+
+	assert.Nil(t, s.ReleaseIP(subnetPath, "port1"))
+	assert.Nil(t, s.ReleaseIP(subnetPath, "port2"))
+	assert.Empty(t, allocationClient.allocations["static-ipv4-default"])
+	assert.Empty(t, allocationClient.allocations["static-ipv6-default"])
+}
@@ -0,0 +1,125 @@
+package ippool
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+	"github.com/vmware-tanzu/nsx-operator/pkg/util"
+)
+
+const defaultPrefixLength = 24
+
+var (
+	String = common.String
+	Int64  = common.Int64
+)
+
+func buildIPPoolPath(org, project, id string) string {
+	return fmt.Sprintf("%s/infra/ip-pools/%s", common.BuildProjectPath(org, project), id)
+}
+
+// ipBlockPath returns the IP block the Subnets of the IPPool are allocated from, the IP block referenced by the IPPool
+// or else the IP block of the VPC.
+func ipBlockPath(obj *v1alpha1.IPPool, nsxVPC *model.Vpc) (string, error) {
+	if obj.Spec.IPBlockPath != "" {
+		return obj.Spec.IPBlockPath, nil
+	}
+	blocks := nsxVPC.PrivateIpv4Blocks
+	if obj.Spec.Type == v1alpha1.IPPoolTypePublic {
+		blocks = nsxVPC.PublicIpv4Blocks
+	}
+	if len(blocks) == 0 {
+		return "", nsxutil.RestrictionError{Desc: fmt.Sprintf("no %s IP block found in VPC %s", ipPoolType(obj), *nsxVPC.Id)}
+	}
+	return blocks[0], nil
+}
+
+// parseIPBlockPath gets the org, project and ID of the IP block from its path, the org and project are "" for the IP
+// blocks under /infra.
+func parseIPBlockPath(path string) (string, string, string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "infra" && parts[1] == "ip-blocks":
+		return "", "", parts[2], nil
+	case len(parts) == 7 && parts[0] == "orgs" && parts[2] == "projects" && parts[4] == "infra" && parts[5] == "ip-blocks":
+		return parts[1], parts[3], parts[6], nil
+	}
+	return "", "", "", nsxutil.RestrictionError{Desc: fmt.Sprintf("invalid IP block path %s", path)}
+}
+
+func ipPoolType(obj *v1alpha1.IPPool) v1alpha1.IPPoolType {
+	if obj.Spec.Type == "" {
+		return v1alpha1.IPPoolTypePrivate
+	}
+	return obj.Spec.Type
+}
+
+func prefixLength(req v1alpha1.SubnetRequest) int {
+	if req.PrefixLength == 0 {
+		return defaultPrefixLength
+	}
+	return req.PrefixLength
+}
+
+func subnetSize(req v1alpha1.SubnetRequest) int64 {
+	return int64(1) << (32 - prefixLength(req))
+}
+
+func ipSubnetID(obj *v1alpha1.IPPool, req v1alpha1.SubnetRequest) string {
+	return util.NormalizeId(fmt.Sprintf("ipsubnet_%s_%s", obj.UID, req.Name))
+}
+
+// validateSubnetRequests rejects the duplicate names and the prefix lengths out of the IPv4 range.
+func validateSubnetRequests(obj *v1alpha1.IPPool) error {
+	names := map[string]bool{}
+	for _, req := range obj.Spec.Subnets {
+		if names[req.Name] {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("duplicate Subnet %s in IPPool %s", req.Name, obj.Name)}
+		}
+		names[req.Name] = true
+		if length := prefixLength(req); length < 1 || length > 32 {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("invalid prefix length %d of Subnet %s", length, req.Name)}
+		}
+	}
+	return nil
+}
+
+func (s *IPPoolService) buildBasicTags(obj *v1alpha1.IPPool) []model.Tag {
+	return []model.Tag{
+		{Scope: String(common.TagScopeCluster), Tag: String(s.NSXConfig.Cluster)},
+		{Scope: String(common.TagScopeNamespace), Tag: String(obj.Namespace)},
+		{Scope: String(common.TagScopeIPPoolCRName), Tag: String(obj.Name)},
+		{Scope: String(common.TagScopeIPPoolCRUID), Tag: String(string(obj.UID))},
+	}
+}
+
+func (s *IPPoolService) buildIPPool(obj *v1alpha1.IPPool, org, project string) *model.IpAddressPool {
+	id := fmt.Sprintf("ippool_%s", obj.UID)
+	return &model.IpAddressPool{
+		Id:          String(id),
+		DisplayName: String(fmt.Sprintf("%s-%s", obj.Namespace, obj.Name)),
+		Path:        String(buildIPPoolPath(org, project, id)),
+		Tags:        s.buildBasicTags(obj),
+	}
+}
+
+// buildIPSubnet builds the block Subnet of the request in the IP pool, NSX allocates its CIDR from the IP block.
+func (s *IPPoolService) buildIPSubnet(obj *v1alpha1.IPPool, ipPool *model.IpAddressPool, req v1alpha1.SubnetRequest, blockPath string) *model.IpAddressPoolBlockSubnet {
+	id := ipSubnetID(obj, req)
+	tags := append(s.buildBasicTags(obj), model.Tag{Scope: String(common.TagScopeIPSubnetName), Tag: String(req.Name)})
+	return &model.IpAddressPoolBlockSubnet{
+		Id:           String(id),
+		DisplayName:  String(req.Name),
+		Path:         String(fmt.Sprintf("%s/ip-subnets/%s", *ipPool.Path, id)),
+		ParentPath:   ipPool.Path,
+		ResourceType: common.ResourceTypeIPPoolSubnet,
+		IpBlockPath:  String(blockPath),
+		Size:         Int64(subnetSize(req)),
+		Tags:         tags,
+	}
+}
@@ -0,0 +1,73 @@
+package ippool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestBuildIPPoolAndSubnet(t *testing.T) {
+	s := &IPPoolService{Service: common.Service{
+		NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}},
+	}}
+	obj := &v1alpha1.IPPool{ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1", UID: "uid1"}}
+
+	ipPool := s.buildIPPool(obj, "default", "p1")
+	assert.Equal(t, "ippool_uid1", *ipPool.Id)
+	assert.Equal(t, "ns1-pool1", *ipPool.DisplayName)
+	assert.Equal(t, "/orgs/default/projects/p1/infra/ip-pools/ippool_uid1", *ipPool.Path)
+	assert.Equal(t, []string{"uid1"}, filterTag(ipPool.Tags, common.TagScopeIPPoolCRUID))
+
+	ipSubnet := s.buildIPSubnet(obj, ipPool, v1alpha1.SubnetRequest{Name: "a"}, "/infra/ip-blocks/b1")
+	assert.Equal(t, "ipsubnet_uid1_a", *ipSubnet.Id)
+	assert.Equal(t, "/orgs/default/projects/p1/infra/ip-pools/ippool_uid1/ip-subnets/ipsubnet_uid1_a", *ipSubnet.Path)
+	assert.Equal(t, int64(256), *ipSubnet.Size)
+	assert.Equal(t, []string{"a"}, filterTag(ipSubnet.Tags, common.TagScopeIPSubnetName))
+}
+
+func TestValidateSubnetRequests(t *testing.T) {
+	obj := &v1alpha1.IPPool{Spec: v1alpha1.IPPoolSpec{Subnets: []v1alpha1.SubnetRequest{{Name: "a"}, {Name: "b", PrefixLength: 32}}}}
+	assert.Nil(t, validateSubnetRequests(obj))
+	obj.Spec.Subnets[1].PrefixLength = 33
+	assert.NotNil(t, validateSubnetRequests(obj))
+	obj.Spec.Subnets[1] = v1alpha1.SubnetRequest{Name: "a"}
+	assert.NotNil(t, validateSubnetRequests(obj))
+}
+
+func TestIPBlockPath(t *testing.T) {
+	nsxVPC := &model.Vpc{
+		Id:                String("vpc1"),
+		PrivateIpv4Blocks: []string{"/orgs/default/projects/p1/infra/ip-blocks/private"},
+	}
+	obj := &v1alpha1.IPPool{Spec: v1alpha1.IPPoolSpec{Type: v1alpha1.IPPoolTypePublic}}
+	_, err := ipBlockPath(obj, nsxVPC)
+	assert.NotNil(t, err)
+
+	// the referenced IP block takes precedence over the IP blocks of the VPC
+	obj.Spec.IPBlockPath = "/infra/ip-blocks/shared"
+	path, err := ipBlockPath(obj, nsxVPC)
+	assert.Nil(t, err)
+	assert.Equal(t, "/infra/ip-blocks/shared", path)
+
+	obj.Spec = v1alpha1.IPPoolSpec{}
+	path, err = ipBlockPath(obj, nsxVPC)
+	assert.Nil(t, err)
+	assert.Equal(t, "/orgs/default/projects/p1/infra/ip-blocks/private", path)
+}
+
+func TestParseIPBlockPath(t *testing.T) {
+	org, project, id, err := parseIPBlockPath("/orgs/default/projects/p1/infra/ip-blocks/b1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"default", "p1", "b1"}, []string{org, project, id})
+	org, project, id, err = parseIPBlockPath("/infra/ip-blocks/b1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"", "", "b1"}, []string{org, project, id})
+	_, _, _, err = parseIPBlockPath("/infra/ip-pools/p1")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,59 @@
+package ippool
+
+import (
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type (
+	IPPool   model.IpAddressPool
+	IPSubnet model.IpAddressPoolBlockSubnet
+)
+
+type Comparable = common.Comparable
+
+func (ipPool *IPPool) Key() string {
+	return *ipPool.Id
+}
+
+func (ipPool *IPPool) Value() data.DataValue {
+	p := &IPPool{Id: ipPool.Id, DisplayName: ipPool.DisplayName, Tags: ipPool.Tags}
+	dataValue, _ := ComparableToIPPool(p).GetDataValue__()
+	return dataValue
+}
+
+func (ipSubnet *IPSubnet) Key() string {
+	return *ipSubnet.Id
+}
+
+// Value leaves out the addresses which NSX fills in when the Subnet is allocated.
+func (ipSubnet *IPSubnet) Value() data.DataValue {
+	s := &IPSubnet{
+		Id:           ipSubnet.Id,
+		DisplayName:  ipSubnet.DisplayName,
+		Tags:         ipSubnet.Tags,
+		IpBlockPath:  ipSubnet.IpBlockPath,
+		Size:         ipSubnet.Size,
+		ResourceType: ipSubnet.ResourceType,
+	}
+	dataValue, _ := ComparableToIPSubnet(s).GetDataValue__()
+	return dataValue
+}
+
+func IPPoolToComparable(ipPool *model.IpAddressPool) Comparable {
+	return (*IPPool)(ipPool)
+}
+
+func ComparableToIPPool(ipPool Comparable) *model.IpAddressPool {
+	return (*model.IpAddressPool)(ipPool.(*IPPool))
+}
+
+func IPSubnetToComparable(ipSubnet *model.IpAddressPoolBlockSubnet) Comparable {
+	return (*IPSubnet)(ipSubnet)
+}
+
+func ComparableToIPSubnet(ipSubnet Comparable) *model.IpAddressPoolBlockSubnet {
+	return (*model.IpAddressPoolBlockSubnet)(ipSubnet.(*IPSubnet))
+}
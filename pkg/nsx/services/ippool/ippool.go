@@ -0,0 +1,299 @@
+package ippool
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log             = logger.Log
+	MarkedForDelete = true
+)
+
+// IPPoolService manages the NSX IP pools of the IPPool CRs, each Subnet request of an IPPool CR is a block Subnet
+// of the IP pool allocated from an IP block of the VPC.
+type IPPoolService struct {
+	common.Service
+	ipPoolStore   *IPPoolStore
+	ipSubnetStore *IPSubnetStore
+}
+
+// InitializeIPPool sync NSX resources
+func InitializeIPPool(service common.Service) (*IPPoolService, error) {
+	wg := sync.WaitGroup{}
+	wgDone := make(chan bool)
+	fatalErrors := make(chan error)
+
+	wg.Add(2)
+
+	ipPoolService := &IPPoolService{Service: service}
+	ipPoolService.ipPoolStore = &IPPoolStore{ResourceStore: common.ResourceStore{
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeIPPoolCRUID: ipPoolIndexFunc}),
+		BindingType: model.IpAddressPoolBindingType(),
+	}}
+	ipPoolService.ipSubnetStore = &IPSubnetStore{ResourceStore: common.ResourceStore{
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeIPPoolCRUID: ipPoolIndexFunc}),
+		BindingType: model.IpAddressPoolBlockSubnetBindingType(),
+	}}
+
+	go ipPoolService.InitializeResourceStore(&wg, fatalErrors, common.ResourceTypeIPPool, ipPoolService.ipPoolStore)
+	go ipPoolService.InitializeResourceStore(&wg, fatalErrors, common.ResourceTypeIPPoolSubnet, ipPoolService.ipSubnetStore)
+
+	go func() {
+		wg.Wait()
+		close(wgDone)
+	}()
+
+	select {
+	case <-wgDone:
+		break
+	case err := <-fatalErrors:
+		close(fatalErrors)
+		return ipPoolService, err
+	}
+
+	return ipPoolService, nil
+}
+
+// CreateOrUpdateIPPool creates or updates the NSX IP pool of the IPPool CR in the Project of the NSX VPC, the Subnets
+// no longer requested are deleted. It returns the Subnets allocated so far in the order of the requests, and whether
+// all the requested Subnets are allocated.
+func (s *IPPoolService) CreateOrUpdateIPPool(obj *v1alpha1.IPPool, nsxVPC *model.Vpc) ([]v1alpha1.SubnetResult, bool, error) {
+	if err := validateSubnetRequests(obj); err != nil {
+		return nil, false, err
+	}
+	blockPath, err := ipBlockPath(obj, nsxVPC)
+	if err != nil {
+		return nil, false, err
+	}
+	if obj.Spec.IPBlockPath != "" {
+		if err := s.validateIPBlock(obj, blockPath); err != nil {
+			return nil, false, err
+		}
+	}
+	org, project, _, err := common.ParseVPCPath(*nsxVPC.Path)
+	if err != nil {
+		return nil, false, err
+	}
+	ipPool := s.buildIPPool(obj, org, project)
+	changed := true
+	if existing := s.ipPoolStore.GetByKey(*ipPool.Id); existing != nil {
+		existingPool := existing.(model.IpAddressPool)
+		changed = common.CompareResource(IPPoolToComparable(&existingPool), IPPoolToComparable(ipPool))
+	}
+	if changed {
+		if err := s.NSXClient.ProjectIPPoolClient.Patch(org, project, *ipPool.Id, *ipPool); err != nil {
+			return nil, false, err
+		}
+		if err := s.ipPoolStore.Operate(ipPool); err != nil {
+			return nil, false, err
+		}
+		log.Info("successfully created or updated IPPool", "IPPool", ipPool)
+	}
+
+	requested := sets.NewString()
+	var results []v1alpha1.SubnetResult
+	allocated := true
+	for _, req := range obj.Spec.Subnets {
+		ipSubnet := s.buildIPSubnet(obj, ipPool, req, blockPath)
+		requested.Insert(*ipSubnet.Id)
+		if err := s.createOrUpdateIPSubnet(org, project, ipSubnet); err != nil {
+			return nil, false, err
+		}
+		cidr, err := s.getIPSubnetCIDR(org, project, ipSubnet)
+		if err != nil {
+			return nil, false, err
+		}
+		if cidr == "" {
+			allocated = false
+			continue
+		}
+		results = append(results, v1alpha1.SubnetResult{Name: req.Name, CIDR: cidr})
+	}
+	for _, ipSubnet := range s.ipSubnetStore.GetByIndex(common.TagScopeIPPoolCRUID, string(obj.UID)) {
+		if requested.Has(*ipSubnet.Id) {
+			continue
+		}
+		if err := s.deleteIPSubnet(ipSubnet); err != nil {
+			return nil, false, err
+		}
+	}
+	return results, allocated, nil
+}
+
+// validateIPBlock checks that the IP block referenced by the IPPool CR exists and has enough IPs left for the Subnets
+// not allocated from it yet.
+func (s *IPPoolService) validateIPBlock(obj *v1alpha1.IPPool, blockPath string) error {
+	org, project, id, err := parseIPBlockPath(blockPath)
+	if err != nil {
+		return err
+	}
+	var block model.IpAddressBlock
+	if org == "" {
+		block, err = s.NSXClient.IPBlockClient.Get(id)
+	} else {
+		block, err = s.NSXClient.ProjectIPBlockClient.Get(org, project, id)
+	}
+	if err != nil {
+		if _, ok := err.(vapierrors.NotFound); ok {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("IP block %s not found", blockPath)}
+		}
+		return err
+	}
+	if block.IpAddressType != nil && *block.IpAddressType != model.IpAddressBlock_IP_ADDRESS_TYPE_IPV4 {
+		return nsxutil.RestrictionError{Desc: fmt.Sprintf("IP block %s is not an IPv4 block", blockPath)}
+	}
+	if block.AvailableAllocationSize == nil {
+		return nil
+	}
+	available, err := strconv.ParseInt(*block.AvailableAllocationSize, 10, 64)
+	if err != nil {
+		log.V(1).Info("unknown available size of IP block", "IPBlock", blockPath, "size", *block.AvailableAllocationSize)
+		return nil
+	}
+	var requested int64
+	for _, req := range obj.Spec.Subnets {
+		if existing := s.ipSubnetStore.GetByKey(ipSubnetID(obj, req)); existing != nil {
+			existingSubnet := existing.(model.IpAddressPoolBlockSubnet)
+			if existingSubnet.IpBlockPath != nil && *existingSubnet.IpBlockPath == blockPath {
+				continue
+			}
+		}
+		requested += subnetSize(req)
+	}
+	if requested > available {
+		return nsxutil.IPExhaustedError{
+			Pool: blockPath,
+			Desc: fmt.Sprintf("no IP available in %s: %d IPs requested, %d IPs left", blockPath, requested, available),
+		}
+	}
+	return nil
+}
+
+func (s *IPPoolService) createOrUpdateIPSubnet(org, project string, ipSubnet *model.IpAddressPoolBlockSubnet) error {
+	if existing := s.ipSubnetStore.GetByKey(*ipSubnet.Id); existing != nil {
+		existingSubnet := existing.(model.IpAddressPoolBlockSubnet)
+		if existingSubnet.Size != nil && *existingSubnet.Size != *ipSubnet.Size {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("prefix length of Subnet %s cannot be changed", *ipSubnet.DisplayName)}
+		}
+		if existingSubnet.IpBlockPath != nil && *existingSubnet.IpBlockPath != *ipSubnet.IpBlockPath {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("IP block of Subnet %s cannot be changed", *ipSubnet.DisplayName)}
+		}
+		if !common.CompareResource(IPSubnetToComparable(&existingSubnet), IPSubnetToComparable(ipSubnet)) {
+			return nil
+		}
+	}
+	dataValue, errs := common.NewConverter().ConvertToVapi(ipSubnet, model.IpAddressPoolBlockSubnetBindingType())
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	_, _, poolID, err := common.ParseIPPoolPath(*ipSubnet.Path)
+	if err != nil {
+		return err
+	}
+	if err := s.NSXClient.ProjectIPSubnetClient.Patch(org, project, poolID, *ipSubnet.Id, dataValue.(*data.StructValue)); err != nil {
+		return common.TransIPExhaustedError(err, *ipSubnet.IpBlockPath)
+	}
+	if err := s.ipSubnetStore.Operate(ipSubnet); err != nil {
+		return err
+	}
+	log.Info("successfully created or updated IPSubnet", "IPSubnet", ipSubnet)
+	return nil
+}
+
+// getIPSubnetCIDR returns the CIDR NSX allocates to the block Subnet, or "" if it is not realized yet. It returns
+// nsxutil.IPExhaustedError if NSX fails to realize it as the IP block runs out of IPs.
+func (s *IPPoolService) getIPSubnetCIDR(org, project string, ipSubnet *model.IpAddressPoolBlockSubnet) (string, error) {
+	result, err := s.NSXClient.RealizedEntitiesClient.List(org, project, *ipSubnet.Path, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, entity := range result.Results {
+		if entity.State != nil && *entity.State == model.GenericPolicyRealizedResource_STATE_ERROR {
+			for _, alarm := range entity.Alarms {
+				if alarm.Message != nil && common.IsIPExhaustedMessage(*alarm.Message) {
+					return "", nsxutil.IPExhaustedError{
+						Pool: *ipSubnet.IpBlockPath,
+						Desc: fmt.Sprintf("no IP available in %s: %s", *ipSubnet.IpBlockPath, *alarm.Message),
+					}
+				}
+			}
+		}
+		if entity.State == nil || *entity.State != model.GenericPolicyRealizedResource_STATE_REALIZED {
+			continue
+		}
+		for _, attr := range entity.ExtendedAttributes {
+			if attr.Key != nil && *attr.Key == "cidr" && len(attr.Values) > 0 {
+				return attr.Values[0], nil
+			}
+		}
+	}
+	log.V(1).Info("IPSubnet is not realized yet", "IPSubnet", *ipSubnet.Id)
+	return "", nil
+}
+
+func (s *IPPoolService) deleteIPSubnet(ipSubnet model.IpAddressPoolBlockSubnet) error {
+	org, project, poolID, err := common.ParseIPPoolPath(*ipSubnet.Path)
+	if err != nil {
+		return err
+	}
+	if err := s.NSXClient.ProjectIPSubnetClient.Delete(org, project, poolID, *ipSubnet.Id); err != nil {
+		return err
+	}
+	ipSubnet.MarkedForDelete = &MarkedForDelete
+	if err := s.ipSubnetStore.Operate(&ipSubnet); err != nil {
+		return err
+	}
+	log.Info("successfully deleted IPSubnet", "IPSubnet", ipSubnet)
+	return nil
+}
+
+// DeleteIPPoolByCRUID deletes the NSX IP pool of the IPPool CR after its Subnets.
+func (s *IPPoolService) DeleteIPPoolByCRUID(uid types.UID) error {
+	for _, ipSubnet := range s.ipSubnetStore.GetByIndex(common.TagScopeIPPoolCRUID, string(uid)) {
+		if err := s.deleteIPSubnet(ipSubnet); err != nil {
+			return err
+		}
+	}
+	for _, ipPool := range s.ipPoolStore.GetByIndex(common.TagScopeIPPoolCRUID, string(uid)) {
+		org, project, poolID, err := common.ParseIPPoolPath(*ipPool.Path)
+		if err != nil {
+			return err
+		}
+		if err := s.NSXClient.ProjectIPPoolClient.Delete(org, project, poolID); err != nil {
+			return err
+		}
+		ipPool.MarkedForDelete = &MarkedForDelete
+		if err := s.ipPoolStore.Operate(&ipPool); err != nil {
+			return err
+		}
+		log.Info("successfully deleted IPPool", "IPPool", ipPool)
+	}
+	return nil
+}
+
+// ListIPPoolCRUID returns the UIDs of the IPPool CRs which have NSX IP pools or Subnets.
+func (s *IPPoolService) ListIPPoolCRUID() sets.String {
+	return s.ipPoolStore.ListIndexFuncValues(common.TagScopeIPPoolCRUID).Union(s.ipSubnetStore.ListIndexFuncValues(common.TagScopeIPPoolCRUID))
+}
+
+// GetIPPoolPath returns the path of the NSX IP pool of the IPPool CR, or "" if it is not created yet.
+func (s *IPPoolService) GetIPPoolPath(uid types.UID) string {
+	for _, ipPool := range s.ipPoolStore.GetByIndex(common.TagScopeIPPoolCRUID, string(uid)) {
+		return *ipPool.Path
+	}
+	return ""
+}
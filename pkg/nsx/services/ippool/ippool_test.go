@@ -0,0 +1,268 @@
+package ippool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	project_infra "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/ip_pools"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/realized_state"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+type fakeIPPoolClient struct {
+	project_infra.IpPoolsClient
+	pools map[string]model.IpAddressPool
+}
+
+func (c *fakeIPPoolClient) Patch(_ string, _ string, id string, pool model.IpAddressPool) error {
+	c.pools[id] = pool
+	return nil
+}
+
+func (c *fakeIPPoolClient) Delete(_ string, _ string, id string) error {
+	delete(c.pools, id)
+	return nil
+}
+
+type fakeIPSubnetClient struct {
+	ip_pools.IpSubnetsClient
+	subnets map[string]model.IpAddressPoolBlockSubnet
+	patched int
+}
+
+func (c *fakeIPSubnetClient) Patch(_ string, _ string, _ string, id string, value *data.StructValue) error {
+	obj, errs := common.NewConverter().ConvertToGolang(value, model.IpAddressPoolBlockSubnetBindingType())
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	c.patched++
+	c.subnets[id] = obj.(model.IpAddressPoolBlockSubnet)
+	return nil
+}
+
+func (c *fakeIPSubnetClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.subnets, id)
+	return nil
+}
+
+// fakeRealizedEntitiesClient reports the CIDRs of the realized Subnets and the alarms of the failed ones by path.
+type fakeRealizedEntitiesClient struct {
+	realized_state.RealizedEntitiesClient
+	cidrs  map[string]string
+	alarms map[string]string
+}
+
+func (c *fakeRealizedEntitiesClient) List(_ string, _ string, path string, _ *string) (model.GenericPolicyRealizedResourceListResult, error) {
+	if alarm, ok := c.alarms[path]; ok {
+		return model.GenericPolicyRealizedResourceListResult{Results: []model.GenericPolicyRealizedResource{{
+			State:  String(model.GenericPolicyRealizedResource_STATE_ERROR),
+			Alarms: []model.PolicyAlarmResource{{Message: String(alarm)}},
+		}}}, nil
+	}
+	cidr, ok := c.cidrs[path]
+	if !ok {
+		return model.GenericPolicyRealizedResourceListResult{Results: []model.GenericPolicyRealizedResource{{State: String(model.GenericPolicyRealizedResource_STATE_UNREALIZED)}}}, nil
+	}
+	return model.GenericPolicyRealizedResourceListResult{Results: []model.GenericPolicyRealizedResource{{
+		State:              String(model.GenericPolicyRealizedResource_STATE_REALIZED),
+		ExtendedAttributes: []model.AttributeVal{{Key: String("cidr"), Values: []string{cidr}}},
+	}}}, nil
+}
+
+type fakeIPBlockClient struct {
+	infra.IpBlocksClient
+	blocks map[string]model.IpAddressBlock
+}
+
+func (c *fakeIPBlockClient) Get(id string) (model.IpAddressBlock, error) {
+	block, ok := c.blocks[id]
+	if !ok {
+		return model.IpAddressBlock{}, vapierrors.NotFound{}
+	}
+	return block, nil
+}
+
+type fakeProjectIPBlockClient struct {
+	project_infra.IpBlocksClient
+	blocks map[string]model.IpAddressBlock
+}
+
+func (c *fakeProjectIPBlockClient) Get(_ string, _ string, id string) (model.IpAddressBlock, error) {
+	block, ok := c.blocks[id]
+	if !ok {
+		return model.IpAddressBlock{}, vapierrors.NotFound{}
+	}
+	return block, nil
+}
+
+func newFakeIPPoolService() (*IPPoolService, *fakeIPSubnetClient, *fakeRealizedEntitiesClient) {
+	subnetClient := &fakeIPSubnetClient{subnets: map[string]model.IpAddressPoolBlockSubnet{}}
+	realizedClient := &fakeRealizedEntitiesClient{cidrs: map[string]string{}, alarms: map[string]string{}}
+	return &IPPoolService{
+		Service: common.Service{
+			NSXClient: &nsx.Client{
+				ProjectIPPoolClient:    &fakeIPPoolClient{pools: map[string]model.IpAddressPool{}},
+				ProjectIPSubnetClient:  subnetClient,
+				RealizedEntitiesClient: realizedClient,
+			},
+			NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}},
+		},
+		ipPoolStore: &IPPoolStore{ResourceStore: common.ResourceStore{
+			Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeIPPoolCRUID: ipPoolIndexFunc}),
+			BindingType: model.IpAddressPoolBindingType(),
+		}},
+		ipSubnetStore: &IPSubnetStore{ResourceStore: common.ResourceStore{
+			Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeIPPoolCRUID: ipPoolIndexFunc}),
+			BindingType: model.IpAddressPoolBlockSubnetBindingType(),
+		}},
+	}, subnetClient, realizedClient
+}
+
+func TestIPPoolService_CreateOrUpdateAndDeleteIPPool(t *testing.T) {
+	s, subnetClient, realizedClient := newFakeIPPoolService()
+	nsxVPC := &model.Vpc{
+		Id:                String("vpc1"),
+		Path:              String("/orgs/default/projects/p1/vpcs/vpc1"),
+		PrivateIpv4Blocks: []string{"/orgs/default/projects/p1/infra/ip-blocks/private"},
+	}
+	obj := &v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.IPPoolSpec{Subnets: []v1alpha1.SubnetRequest{{Name: "a", PrefixLength: 28}, {Name: "b"}}},
+	}
+	poolPath := "/orgs/default/projects/p1/infra/ip-pools/ippool_uid1"
+
+	// the Subnets are not allocated until realized
+	results, allocated, err := s.CreateOrUpdateIPPool(obj, nsxVPC)
+	require.Nil(t, err)
+	assert.False(t, allocated)
+	assert.Empty(t, results)
+	assert.Equal(t, 2, len(subnetClient.subnets))
+	assert.Equal(t, int64(16), *subnetClient.subnets["ipsubnet_uid1_a"].Size)
+	assert.Equal(t, int64(256), *subnetClient.subnets["ipsubnet_uid1_b"].Size)
+	assert.Equal(t, "/orgs/default/projects/p1/infra/ip-blocks/private", *subnetClient.subnets["ipsubnet_uid1_a"].IpBlockPath)
+
+	realizedClient.cidrs[poolPath+"/ip-subnets/ipsubnet_uid1_a"] = "172.16.0.0/28"
+	realizedClient.cidrs[poolPath+"/ip-subnets/ipsubnet_uid1_b"] = "172.16.1.0/24"
+	results, allocated, err = s.CreateOrUpdateIPPool(obj, nsxVPC)
+	require.Nil(t, err)
+	assert.True(t, allocated)
+	assert.Equal(t, []v1alpha1.SubnetResult{{Name: "a", CIDR: "172.16.0.0/28"}, {Name: "b", CIDR: "172.16.1.0/24"}}, results)
+	// the unchanged Subnets are not patched again
+	assert.Equal(t, 2, subnetClient.patched)
+	assert.Equal(t, []string{"uid1"}, s.ListIPPoolCRUID().List())
+
+	// the Subnet no longer requested is deleted
+	obj.Spec.Subnets = obj.Spec.Subnets[1:]
+	results, _, err = s.CreateOrUpdateIPPool(obj, nsxVPC)
+	require.Nil(t, err)
+	assert.Equal(t, []v1alpha1.SubnetResult{{Name: "b", CIDR: "172.16.1.0/24"}}, results)
+	assert.Equal(t, 1, len(subnetClient.subnets))
+
+	// the prefix length cannot be changed
+	obj.Spec.Subnets[0].PrefixLength = 26
+	_, _, err = s.CreateOrUpdateIPPool(obj, nsxVPC)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+
+	assert.Nil(t, s.DeleteIPPoolByCRUID("uid1"))
+	assert.Equal(t, 0, len(subnetClient.subnets))
+	assert.Empty(t, s.ListIPPoolCRUID())
+}
+
+func TestIPPoolService_CreateOrUpdateIPPoolRestrictions(t *testing.T) {
+	s, _, _ := newFakeIPPoolService()
+	nsxVPC := &model.Vpc{Id: String("vpc1"), Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+
+	// no public IP block in the VPC
+	obj := &v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.IPPoolSpec{Type: v1alpha1.IPPoolTypePublic, Subnets: []v1alpha1.SubnetRequest{{Name: "a"}}},
+	}
+	_, _, err := s.CreateOrUpdateIPPool(obj, nsxVPC)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+
+	// duplicate Subnet names
+	nsxVPC.PublicIpv4Blocks = []string{"/orgs/default/projects/p1/infra/ip-blocks/public"}
+	obj.Spec.Subnets = append(obj.Spec.Subnets, v1alpha1.SubnetRequest{Name: "a"})
+	_, _, err = s.CreateOrUpdateIPPool(obj, nsxVPC)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+}
+
+func TestIPPoolService_CreateOrUpdateIPPoolExhausted(t *testing.T) {
+	s, _, realizedClient := newFakeIPPoolService()
+	blockPath := "/orgs/default/projects/p1/infra/ip-blocks/private"
+	nsxVPC := &model.Vpc{Id: String("vpc1"), Path: String("/orgs/default/projects/p1/vpcs/vpc1"), PrivateIpv4Blocks: []string{blockPath}}
+	obj := &v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.IPPoolSpec{Subnets: []v1alpha1.SubnetRequest{{Name: "a"}}},
+	}
+	realizedClient.alarms["/orgs/default/projects/p1/infra/ip-pools/ippool_uid1/ip-subnets/ipsubnet_uid1_a"] = "IP block is exhausted"
+
+	_, _, err := s.CreateOrUpdateIPPool(obj, nsxVPC)
+	assert.Equal(t, nsxutil.IPExhaustedError{Pool: blockPath, Desc: "no IP available in " + blockPath + ": IP block is exhausted"}, err)
+}
+
+func TestIPPoolService_CreateOrUpdateIPPoolWithIPBlock(t *testing.T) {
+	s, subnetClient, _ := newFakeIPPoolService()
+	s.NSXClient.IPBlockClient = &fakeIPBlockClient{blocks: map[string]model.IpAddressBlock{
+		"shared": {Cidr: String("10.0.0.0/24"), AvailableAllocationSize: String("256")},
+		"ipv6":   {Cidr: String("fd00::/64"), IpAddressType: String(model.IpAddressBlock_IP_ADDRESS_TYPE_IPV6)},
+	}}
+	s.NSXClient.ProjectIPBlockClient = &fakeProjectIPBlockClient{blocks: map[string]model.IpAddressBlock{
+		"small": {Cidr: String("10.1.0.0/28"), AvailableAllocationSize: String("16")},
+	}}
+	nsxVPC := &model.Vpc{Id: String("vpc1"), Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	obj := &v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1", UID: "uid1"},
+		Spec: v1alpha1.IPPoolSpec{
+			IPBlockPath: "/infra/ip-blocks/missing",
+			Subnets:     []v1alpha1.SubnetRequest{{Name: "a"}},
+		},
+	}
+
+	// the IP block does not exist
+	_, _, err := s.CreateOrUpdateIPPool(obj, nsxVPC)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+
+	// the IP block is not an IPv4 block
+	obj.Spec.IPBlockPath = "/infra/ip-blocks/ipv6"
+	_, _, err = s.CreateOrUpdateIPPool(obj, nsxVPC)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+
+	// not enough IPs left in the IP block
+	obj.Spec.IPBlockPath = "/orgs/default/projects/p1/infra/ip-blocks/small"
+	_, _, err = s.CreateOrUpdateIPPool(obj, nsxVPC)
+	assert.Equal(t, nsxutil.IPExhaustedError{
+		Pool: obj.Spec.IPBlockPath,
+		Desc: "no IP available in /orgs/default/projects/p1/infra/ip-blocks/small: 256 IPs requested, 16 IPs left",
+	}, err)
+	assert.Empty(t, subnetClient.subnets)
+
+	obj.Spec.IPBlockPath = "/infra/ip-blocks/shared"
+	_, _, err = s.CreateOrUpdateIPPool(obj, nsxVPC)
+	require.Nil(t, err)
+	assert.Equal(t, "/infra/ip-blocks/shared", *subnetClient.subnets["ipsubnet_uid1_a"].IpBlockPath)
+
+	// the Subnets already allocated from the IP block are not counted again
+	s.NSXClient.IPBlockClient.(*fakeIPBlockClient).blocks["shared"] = model.IpAddressBlock{Cidr: String("10.0.0.0/24"), AvailableAllocationSize: String("0")}
+	_, _, err = s.CreateOrUpdateIPPool(obj, nsxVPC)
+	require.Nil(t, err)
+
+	// the IP block of an allocated Subnet cannot be changed
+	nsxVPC.PrivateIpv4Blocks = []string{"/orgs/default/projects/p1/infra/ip-blocks/private"}
+	obj.Spec.IPBlockPath = ""
+	_, _, err = s.CreateOrUpdateIPPool(obj, nsxVPC)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+}
@@ -0,0 +1,107 @@
+package ippool
+
+import (
+	"errors"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// keyFunc is used to get the key of a resource, usually, which is the ID of the resource
+func keyFunc(obj interface{}) (string, error) {
+	switch v := obj.(type) {
+	case model.IpAddressPool:
+		return *v.Id, nil
+	case model.IpAddressPoolBlockSubnet:
+		return *v.Id, nil
+	default:
+		return "", errors.New("keyFunc doesn't support unknown type")
+	}
+}
+
+// ipPoolIndexFunc indexes the NSX IP pools and their Subnets by the UID of the IPPool CR they are created for.
+func ipPoolIndexFunc(obj interface{}) ([]string, error) {
+	switch o := obj.(type) {
+	case model.IpAddressPool:
+		return filterTag(o.Tags, common.TagScopeIPPoolCRUID), nil
+	case model.IpAddressPoolBlockSubnet:
+		return filterTag(o.Tags, common.TagScopeIPPoolCRUID), nil
+	default:
+		return nil, errors.New("ipPoolIndexFunc doesn't support unknown type")
+	}
+}
+
+func filterTag(tags []model.Tag, tagScope string) []string {
+	res := make([]string, 0, 5)
+	for _, tag := range tags {
+		if *tag.Scope == tagScope {
+			res = append(res, *tag.Tag)
+		}
+	}
+	return res
+}
+
+// IPPoolStore is a store for NSX IP pools
+type IPPoolStore struct {
+	common.ResourceStore
+}
+
+func (ipPoolStore *IPPoolStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	ipPool := i.(*model.IpAddressPool)
+	if ipPool.MarkedForDelete != nil && *ipPool.MarkedForDelete {
+		if err := ipPoolStore.Delete(*ipPool); err != nil {
+			return err
+		}
+		log.V(1).Info("delete IPPool from store", "IPPool", ipPool)
+	} else {
+		if err := ipPoolStore.Add(*ipPool); err != nil {
+			return err
+		}
+		log.V(1).Info("add IPPool to store", "IPPool", ipPool)
+	}
+	return nil
+}
+
+func (ipPoolStore *IPPoolStore) GetByIndex(key string, value string) []model.IpAddressPool {
+	ipPools := make([]model.IpAddressPool, 0)
+	for _, ipPool := range ipPoolStore.ResourceStore.GetByIndex(key, value) {
+		ipPools = append(ipPools, ipPool.(model.IpAddressPool))
+	}
+	return ipPools
+}
+
+// IPSubnetStore is a store for the block Subnets of NSX IP pools
+type IPSubnetStore struct {
+	common.ResourceStore
+}
+
+func (ipSubnetStore *IPSubnetStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	ipSubnet := i.(*model.IpAddressPoolBlockSubnet)
+	if ipSubnet.MarkedForDelete != nil && *ipSubnet.MarkedForDelete {
+		if err := ipSubnetStore.Delete(*ipSubnet); err != nil {
+			return err
+		}
+		log.V(1).Info("delete IPSubnet from store", "IPSubnet", ipSubnet)
+	} else {
+		if err := ipSubnetStore.Add(*ipSubnet); err != nil {
+			return err
+		}
+		log.V(1).Info("add IPSubnet to store", "IPSubnet", ipSubnet)
+	}
+	return nil
+}
+
+func (ipSubnetStore *IPSubnetStore) GetByIndex(key string, value string) []model.IpAddressPoolBlockSubnet {
+	ipSubnets := make([]model.IpAddressPoolBlockSubnet, 0)
+	for _, ipSubnet := range ipSubnetStore.ResourceStore.GetByIndex(key, value) {
+		ipSubnets = append(ipSubnets, ipSubnet.(model.IpAddressPoolBlockSubnet))
+	}
+	return ipSubnets
+}
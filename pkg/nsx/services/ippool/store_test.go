@@ -0,0 +1,31 @@
+package ippool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestIPSubnetStore_Operate(t *testing.T) {
+	store := &IPSubnetStore{ResourceStore: common.ResourceStore{
+		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeIPPoolCRUID: ipPoolIndexFunc}),
+		BindingType: model.IpAddressPoolBlockSubnetBindingType(),
+	}}
+	ipSubnet := &model.IpAddressPoolBlockSubnet{
+		Id:   String("ipsubnet_uid1_a"),
+		Tags: []model.Tag{{Scope: String(common.TagScopeIPPoolCRUID), Tag: String("uid1")}},
+	}
+	assert.Nil(t, store.Operate(ipSubnet))
+	assert.Equal(t, []model.IpAddressPoolBlockSubnet{*ipSubnet}, store.GetByIndex(common.TagScopeIPPoolCRUID, "uid1"))
+
+	ipSubnet.MarkedForDelete = &MarkedForDelete
+	assert.Nil(t, store.Operate(ipSubnet))
+	assert.Empty(t, store.GetByIndex(common.TagScopeIPPoolCRUID, "uid1"))
+
+	_, err := keyFunc(model.Vpc{})
+	assert.NotNil(t, err)
+}
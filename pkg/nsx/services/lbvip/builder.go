@@ -0,0 +1,65 @@
+package lbvip
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipam"
+)
+
+var String = common.String
+
+// IsLBVIPService tells if the VIP of the Service is allocated by NSX Operator, i.e. a LoadBalancer Service which
+// doesn't ask for another load balancer implementation.
+func IsLBVIPService(svc *v1.Service) bool {
+	return svc.Spec.Type == v1.ServiceTypeLoadBalancer && svc.Spec.LoadBalancerClass == nil
+}
+
+// poolPathOf returns the path of the IP pool the allocation is in.
+func poolPathOf(allocation *model.IpAddressAllocation) string {
+	return path.Dir(path.Dir(*allocation.Path))
+}
+
+func (s *LBVIPService) buildVIPAllocation(svc *v1.Service, poolPath string) *model.IpAddressAllocation {
+	id := fmt.Sprintf("vip_%s", svc.UID)
+	allocation := &model.IpAddressAllocation{
+		Id:          String(id),
+		DisplayName: String(fmt.Sprintf("%s-%s", svc.Namespace, svc.Name)),
+		Path:        String(fmt.Sprintf("%s/ip-allocations/%s", poolPath, id)),
+		ParentPath:  String(poolPath),
+		Tags: []model.Tag{
+			{Scope: String(common.TagScopeCluster), Tag: String(s.NSXConfig.Cluster)},
+			{Scope: String(common.TagScopeNamespace), Tag: String(svc.Namespace)},
+			{Scope: String(common.TagScopeServiceName), Tag: String(svc.Name)},
+			{Scope: String(common.TagScopeServiceUID), Tag: String(string(svc.UID))},
+		},
+	}
+	if svc.Spec.LoadBalancerIP != "" {
+		allocation.AllocationIp = String(svc.Spec.LoadBalancerIP)
+	}
+	return allocation
+}
+
+// vipFamily returns the family of the VIP, the family of the requested IP or the primary family of the Service.
+func vipFamily(svc *v1.Service) ipam.IPFamily {
+	if svc.Spec.LoadBalancerIP != "" {
+		return ipam.FamilyOf(svc.Spec.LoadBalancerIP)
+	}
+	if len(svc.Spec.IPFamilies) > 0 && svc.Spec.IPFamilies[0] == v1.IPv6Protocol {
+		return ipam.IPFamilyIPv6
+	}
+	return ipam.IPFamilyIPv4
+}
+
+// vipRequest returns the request of the VIP allocation to the IPAM.
+func vipRequest(allocation *model.IpAddressAllocation) *ipam.Request {
+	req := &ipam.Request{ID: *allocation.Id, DisplayName: *allocation.DisplayName, Tags: allocation.Tags}
+	if allocation.AllocationIp != nil {
+		req.IPs = []string{*allocation.AllocationIp}
+	}
+	return req
+}
@@ -0,0 +1,38 @@
+package lbvip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestIsLBVIPService(t *testing.T) {
+	svc := &v1.Service{Spec: v1.ServiceSpec{Type: v1.ServiceTypeClusterIP}}
+	assert.False(t, IsLBVIPService(svc))
+	svc.Spec.Type = v1.ServiceTypeLoadBalancer
+	assert.True(t, IsLBVIPService(svc))
+	svc.Spec.LoadBalancerClass = String("example.com/lb")
+	assert.False(t, IsLBVIPService(svc))
+}
+
+func TestBuildVIPAllocation(t *testing.T) {
+	s := &LBVIPService{Service: common.Service{
+		NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}},
+	}}
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "ns1", UID: "uid1"}}
+
+	allocation := s.buildVIPAllocation(svc, "/infra/ip-pools/vip")
+	assert.Equal(t, "vip_uid1", *allocation.Id)
+	assert.Equal(t, "/infra/ip-pools/vip/ip-allocations/vip_uid1", *allocation.Path)
+	assert.Equal(t, "/infra/ip-pools/vip", poolPathOf(allocation))
+	assert.Nil(t, allocation.AllocationIp)
+	assert.Equal(t, []string{"uid1"}, filterTag(allocation.Tags, common.TagScopeServiceUID))
+
+	svc.Spec.LoadBalancerIP = "10.0.0.5"
+	assert.Equal(t, "10.0.0.5", *s.buildVIPAllocation(svc, "/infra/ip-pools/vip").AllocationIp)
+}
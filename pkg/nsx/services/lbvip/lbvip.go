@@ -0,0 +1,167 @@
+package lbvip
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipam"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log             = logger.Log
+	MarkedForDelete = true
+)
+
+// LBVIPService allocates the VIPs of the LoadBalancer Services from the NSX IP pools configured as LB VIP pools.
+type LBVIPService struct {
+	common.Service
+	vipAllocationStore *VIPAllocationStore
+	allocator          ipam.Allocator
+}
+
+// InitializeLBVIP sync NSX resources
+func InitializeLBVIP(service common.Service) (*LBVIPService, error) {
+	wg := sync.WaitGroup{}
+	wgDone := make(chan bool)
+	fatalErrors := make(chan error)
+
+	wg.Add(1)
+
+	lbVIPService := &LBVIPService{Service: service, allocator: ipam.NewIPAMService(service)}
+	lbVIPService.vipAllocationStore = &VIPAllocationStore{ResourceStore: common.ResourceStore{
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeServiceUID: serviceIndexFunc}),
+		BindingType: model.IpAddressAllocationBindingType(),
+	}}
+
+	go lbVIPService.InitializeResourceStore(&wg, fatalErrors, common.ResourceTypeIPAllocation, lbVIPService.vipAllocationStore)
+
+	go func() {
+		wg.Wait()
+		close(wgDone)
+	}()
+
+	select {
+	case <-wgDone:
+		break
+	case err := <-fatalErrors:
+		close(fatalErrors)
+		return lbVIPService, err
+	}
+
+	return lbVIPService, nil
+}
+
+// AllocateVIP returns the VIP of the LoadBalancer Service, or "" if NSX has not allocated it yet. The VIP is allocated
+// from the first LB VIP pool with a free IP, or the IP requested by spec.loadBalancerIP is allocated.
+func (s *LBVIPService) AllocateVIP(svc *v1.Service) (string, error) {
+	for _, allocation := range s.vipAllocationStore.GetByIndex(common.TagScopeServiceUID, string(svc.UID)) {
+		requested := svc.Spec.LoadBalancerIP
+		if allocation.AllocationIp != nil && (requested == "" || requested == *allocation.AllocationIp) {
+			return *allocation.AllocationIp, nil
+		}
+		if allocation.AllocationIp == nil && requested == "" {
+			return s.getVIP(&allocation)
+		}
+		// the VIP is released to allocate the newly requested one
+		if err := s.deleteAllocation(allocation); err != nil {
+			return "", err
+		}
+	}
+	var lastErr error
+	exhausted := len(s.NSXConfig.LBVIPPools) > 0
+	for _, poolPath := range s.NSXConfig.LBVIPPools {
+		allocation := s.buildVIPAllocation(svc, poolPath)
+		allocations, err := s.allocator.AllocateIP(vipFamily(svc), poolPath, vipRequest(allocation))
+		if err != nil {
+			log.Error(err, "failed to allocate VIP from IP pool, try the next one", "Service", svc.Name, "IPPool", poolPath)
+			lastErr = err
+			if !errors.As(lastErr, &nsxutil.IPExhaustedError{}) {
+				exhausted = false
+			}
+			continue
+		}
+		log.Info("successfully allocated VIP", "Service", svc.Name, "IPPool", poolPath)
+		allocation.AllocationIp = nil
+		if allocations[0].IP != "" {
+			allocation.AllocationIp = String(allocations[0].IP)
+		}
+		if err := s.vipAllocationStore.Operate(allocation); err != nil {
+			return "", err
+		}
+		return allocations[0].IP, nil
+	}
+	desc := fmt.Sprintf("no VIP available in the LB VIP pools %v: %v", s.NSXConfig.LBVIPPools, lastErr)
+	if exhausted {
+		// all the pools run out of IPs
+		return "", nsxutil.IPExhaustedError{Pool: strings.Join(s.NSXConfig.LBVIPPools, ","), Desc: desc}
+	}
+	return "", errors.New(desc)
+}
+
+// getVIP reads the IP NSX allocates and caches the allocation in the store.
+func (s *LBVIPService) getVIP(allocation *model.IpAddressAllocation) (string, error) {
+	ip, err := s.allocator.GetIP(poolPathOf(allocation), *allocation.Id)
+	if err != nil {
+		return "", err
+	}
+	if ip == "" {
+		return "", nil
+	}
+	allocation.AllocationIp = String(ip)
+	if err := s.vipAllocationStore.Operate(allocation); err != nil {
+		return "", err
+	}
+	return ip, nil
+}
+
+func (s *LBVIPService) deleteAllocation(allocation model.IpAddressAllocation) error {
+	if err := s.allocator.ReleaseIP(poolPathOf(&allocation), *allocation.Id); err != nil {
+		return err
+	}
+	allocation.MarkedForDelete = &MarkedForDelete
+	if err := s.vipAllocationStore.Operate(&allocation); err != nil {
+		return err
+	}
+	log.Info("successfully released VIP", "allocation", *allocation.Path, "VIP", allocation.AllocationIp)
+	return nil
+}
+
+// ReleaseVIP releases the VIP of the LoadBalancer Service.
+func (s *LBVIPService) ReleaseVIP(uid types.UID) error {
+	for _, allocation := range s.vipAllocationStore.GetByIndex(common.TagScopeServiceUID, string(uid)) {
+		if err := s.deleteAllocation(allocation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListServiceUID returns the UIDs of the Services which have VIPs allocated.
+func (s *LBVIPService) ListServiceUID() sets.String {
+	return s.vipAllocationStore.ListIndexFuncValues(common.TagScopeServiceUID)
+}
+
+// CountVIPsByPool returns the number of the VIPs allocated from each LB VIP pool.
+func (s *LBVIPService) CountVIPsByPool() map[string]int {
+	counts := make(map[string]int)
+	for _, poolPath := range s.NSXConfig.LBVIPPools {
+		counts[poolPath] = 0
+	}
+	for uid := range s.ListServiceUID() {
+		for _, allocation := range s.vipAllocationStore.GetByIndex(common.TagScopeServiceUID, uid) {
+			counts[poolPathOf(&allocation)]++
+		}
+	}
+	return counts
+}
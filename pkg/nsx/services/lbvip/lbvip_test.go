@@ -0,0 +1,104 @@
+package lbvip
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/ip_pools"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	project_ip_pools "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/ip_pools"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipam"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// fakeInfraIPAllocationClient fails to allocate as if the pool is exhausted.
+type fakeInfraIPAllocationClient struct {
+	ip_pools.IpAllocationsClient
+}
+
+func (c *fakeInfraIPAllocationClient) Patch(_ string, _ string, _ model.IpAddressAllocation) error {
+	return vapierrors.UnableToAllocateResource{}
+}
+
+type fakeProjectIPAllocationClient struct {
+	project_ip_pools.IpAllocationsClient
+	allocations map[string]model.IpAddressAllocation
+}
+
+func (c *fakeProjectIPAllocationClient) Patch(_ string, _ string, _ string, id string, allocation model.IpAddressAllocation) error {
+	if allocation.AllocationIp == nil {
+		allocation.AllocationIp = String("192.168.0.10")
+	}
+	c.allocations[id] = allocation
+	return nil
+}
+
+func (c *fakeProjectIPAllocationClient) Get(_ string, _ string, _ string, id string) (model.IpAddressAllocation, error) {
+	return c.allocations[id], nil
+}
+
+func (c *fakeProjectIPAllocationClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.allocations, id)
+	return nil
+}
+
+func TestLBVIPService_AllocateVIP(t *testing.T) {
+	projectClient := &fakeProjectIPAllocationClient{allocations: map[string]model.IpAddressAllocation{}}
+	infraPool, projectPool := "/infra/ip-pools/vip", "/orgs/default/projects/p1/infra/ip-pools/vip"
+	s := &LBVIPService{
+		Service: common.Service{
+			NSXClient: &nsx.Client{
+				InfraIPAllocationClient:   &fakeInfraIPAllocationClient{},
+				ProjectIPAllocationClient: projectClient,
+			},
+			NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{
+				Cluster:    "k8scl-one",
+				LBVIPPools: []string{infraPool, projectPool},
+			}},
+		},
+		vipAllocationStore: &VIPAllocationStore{ResourceStore: common.ResourceStore{
+			Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeServiceUID: serviceIndexFunc}),
+			BindingType: model.IpAddressAllocationBindingType(),
+		}},
+	}
+	s.allocator = ipam.NewIPAMService(s.Service)
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+
+	// the VIP is allocated from the next pool if the first one is exhausted
+	vip, err := s.AllocateVIP(svc)
+	require.Nil(t, err)
+	assert.Equal(t, "192.168.0.10", vip)
+	assert.Equal(t, map[string]int{infraPool: 0, projectPool: 1}, s.CountVIPsByPool())
+	assert.Equal(t, []string{"uid1"}, s.ListServiceUID().List())
+
+	// the VIP is reallocated when another IP is requested
+	svc.Spec.LoadBalancerIP = "192.168.0.20"
+	vip, err = s.AllocateVIP(svc)
+	require.Nil(t, err)
+	assert.Equal(t, "192.168.0.20", vip)
+	assert.Equal(t, 1, len(projectClient.allocations))
+
+	assert.Nil(t, s.ReleaseVIP("uid1"))
+	assert.Empty(t, projectClient.allocations)
+	assert.Equal(t, map[string]int{infraPool: 0, projectPool: 0}, s.CountVIPsByPool())
+
+	// no VIP is allocated if all the pools are exhausted
+	s.NSXConfig.LBVIPPools = []string{infraPool}
+	_, err = s.AllocateVIP(svc)
+	exhausted := nsxutil.IPExhaustedError{}
+	assert.True(t, errors.As(err, &exhausted))
+	assert.Equal(t, infraPool, exhausted.Pool)
+}
@@ -0,0 +1,72 @@
+package lbvip
+
+import (
+	"errors"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// keyFunc is used to get the key of a resource, which is the path of the allocation as the allocations in different
+// IP pools may share the same ID.
+func keyFunc(obj interface{}) (string, error) {
+	switch v := obj.(type) {
+	case model.IpAddressAllocation:
+		return *v.Path, nil
+	default:
+		return "", errors.New("keyFunc doesn't support unknown type")
+	}
+}
+
+// serviceIndexFunc indexes the VIP allocations by the UID of the LoadBalancer Service they are allocated for.
+func serviceIndexFunc(obj interface{}) ([]string, error) {
+	switch o := obj.(type) {
+	case model.IpAddressAllocation:
+		return filterTag(o.Tags, common.TagScopeServiceUID), nil
+	default:
+		return nil, errors.New("serviceIndexFunc doesn't support unknown type")
+	}
+}
+
+func filterTag(tags []model.Tag, tagScope string) []string {
+	res := make([]string, 0, 5)
+	for _, tag := range tags {
+		if *tag.Scope == tagScope {
+			res = append(res, *tag.Tag)
+		}
+	}
+	return res
+}
+
+// VIPAllocationStore is a store for the VIP allocations in the LB VIP pools
+type VIPAllocationStore struct {
+	common.ResourceStore
+}
+
+func (vipAllocationStore *VIPAllocationStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	allocation := i.(*model.IpAddressAllocation)
+	if allocation.MarkedForDelete != nil && *allocation.MarkedForDelete {
+		if err := vipAllocationStore.Delete(*allocation); err != nil {
+			return err
+		}
+		log.V(1).Info("delete VIP allocation from store", "allocation", allocation)
+	} else {
+		if err := vipAllocationStore.Add(*allocation); err != nil {
+			return err
+		}
+		log.V(1).Info("add VIP allocation to store", "allocation", allocation)
+	}
+	return nil
+}
+
+func (vipAllocationStore *VIPAllocationStore) GetByIndex(key string, value string) []model.IpAddressAllocation {
+	allocations := make([]model.IpAddressAllocation, 0)
+	for _, allocation := range vipAllocationStore.ResourceStore.GetByIndex(key, value) {
+		allocations = append(allocations, allocation.(model.IpAddressAllocation))
+	}
+	return allocations
+}
@@ -24,8 +24,6 @@ import (
 )
 
 const (
-	siteId             = "default"
-	enforcementpointId = "default"
 	PortRestAPI        = "rest-api"
 	PortNSXRPCFwdProxy = "nsx-rpc-fwd-proxy"
 	SecretSuffix       = "-nsx-cert"
@@ -81,11 +79,11 @@ func InitializeNSXServiceAccount(service common.Service) (*NSXServiceAccountServ
 
 func (s *NSXServiceAccountService) SetUpStore() {
 	s.PrincipalIdentityStore = &PrincipalIdentityStore{ResourceStore: common.ResourceStore{
-		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeNSXServiceAccountCRUID: indexFunc}),
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeNSXServiceAccountCRUID: indexFunc}),
 		BindingType: mpmodel.PrincipalIdentityBindingType(),
 	}}
 	s.ClusterControlPlaneStore = &ClusterControlPlaneStore{ResourceStore: common.ResourceStore{
-		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeNSXServiceAccountCRUID: indexFunc}),
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeNSXServiceAccountCRUID: indexFunc}),
 		BindingType: model.ClusterControlPlaneBindingType(),
 	}}
 }
@@ -96,7 +94,7 @@ func (s *NSXServiceAccountService) CreateOrUpdateNSXServiceAccount(ctx context.C
 	// TODO: Use WCPConfig.NSXTProject as project when WCPConfig.EnableWCPVPCNetwork is true
 	project := s.NSXConfig.CoeConfig.Cluster
 	vpcName := obj.Namespace + "-default-vpc"
-	vpcPath := fmt.Sprintf("/orgs/default/projects/%s/vpcs/%s", util.NormalizeId(project), vpcName)
+	vpcPath := fmt.Sprintf("%s/vpcs/%s", common.BuildProjectPath(s.NSXConfig.GetNsxOrg(), util.NormalizeId(project)), vpcName)
 
 	// generate certificate
 	subject := util.DefaultSubject
@@ -133,10 +131,14 @@ func (s *NSXServiceAccountService) CreateOrUpdateNSXServiceAccount(ctx context.C
 		s.PrincipalIdentityStore.Add(pi)
 	}
 
-	// create ClusterControlPlane
+	// create ClusterControlPlane on the enforcement point of the Namespace
 	clusterId := ""
 	if ccpObj := s.ClusterControlPlaneStore.GetByKey(normalizedClusterName); ccpObj == nil {
-		ccp, err := s.NSXClient.ClusterControlPlanesClient.Update(siteId, enforcementpointId, normalizedClusterName, model.ClusterControlPlane{
+		enforcementPoint, err := s.GetEnforcementPoint(obj.Namespace)
+		if err != nil {
+			return err
+		}
+		ccp, err := s.NSXClient.ClusterControlPlanesClient.Update(common.DefaultSite, enforcementPoint, normalizedClusterName, model.ClusterControlPlane{
 			Revision:     &revision1,
 			ResourceType: &antreaClusterResourceType,
 			Certificate:  &cert,
@@ -204,9 +206,21 @@ func (s *NSXServiceAccountService) DeleteNSXServiceAccount(ctx context.Context,
 		return err
 	}
 
-	// delete ClusterControlPlane
+	// delete ClusterControlPlane from the enforcement point it is created on
+	enforcementPoint := ""
+	if ccpObj := s.ClusterControlPlaneStore.GetByKey(normalizedClusterName); ccpObj != nil {
+		if ccp := ccpObj.(model.ClusterControlPlane); ccp.Path != nil {
+			enforcementPoint = common.ParseEnforcementPoint(*ccp.Path)
+		}
+	}
+	if enforcementPoint == "" {
+		var err error
+		if enforcementPoint, err = s.GetEnforcementPoint(namespacedName.Namespace); err != nil {
+			return err
+		}
+	}
 	cascade := true
-	if err := s.NSXClient.ClusterControlPlanesClient.Delete(siteId, enforcementpointId, normalizedClusterName, &cascade); err != nil {
+	if err := s.NSXClient.ClusterControlPlanesClient.Delete(common.DefaultSite, enforcementPoint, normalizedClusterName, &cascade); err != nil {
 		log.Error(err, "failed to delete", "ClusterControlPlane", normalizedClusterName)
 		return err
 	}
@@ -0,0 +1,120 @@
+package routeadvertisement
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var String = common.String
+
+// advertisementTypes maps the prefixes created by NSX Operator to the route advertisement types of the Tier-1 gateway,
+// the other types of the gateway are left as they are.
+var advertisementTypes = map[v1alpha1.RouteAdvertisementPrefixType]string{
+	v1alpha1.RouteAdvertisementPodCIDRs:     model.Tier1_ROUTE_ADVERTISEMENT_TYPES_CONNECTED,
+	v1alpha1.RouteAdvertisementLBVIPs:       model.Tier1_ROUTE_ADVERTISEMENT_TYPES_LB_VIP,
+	v1alpha1.RouteAdvertisementNATIPs:       model.Tier1_ROUTE_ADVERTISEMENT_TYPES_NAT,
+	v1alpha1.RouteAdvertisementStaticRoutes: model.Tier1_ROUTE_ADVERTISEMENT_TYPES_STATIC_ROUTES,
+}
+
+// rulePrefix returns the prefix of the names of the rules of the RouteAdvertisement CR, or of all the rules of the
+// cluster if name is "".
+func (s *RouteAdvertisementService) rulePrefix(name string) string {
+	if name == "" {
+		return fmt.Sprintf("nsx-op/%s/", s.NSXConfig.Cluster)
+	}
+	return fmt.Sprintf("nsx-op/%s/%s/", s.NSXConfig.Cluster, name)
+}
+
+// buildAdvertisementTypes returns the route advertisement types of the gateway with the types of the prefixes created
+// by NSX Operator replaced by the ones advertised by the RouteAdvertisement CR.
+func buildAdvertisementTypes(obj *v1alpha1.RouteAdvertisement, existing []string) []string {
+	managed := map[string]bool{}
+	for _, t := range advertisementTypes {
+		managed[t] = true
+	}
+	types := make([]string, 0, len(existing)+len(obj.Spec.Advertise))
+	for _, t := range existing {
+		if !managed[t] {
+			types = append(types, t)
+		}
+	}
+	for _, t := range obj.Spec.Advertise {
+		if nsxType := advertisementTypes[t]; !contains(types, nsxType) {
+			types = append(types, nsxType)
+		}
+	}
+	return types
+}
+
+// buildAdvertisementRules returns the route advertisement rules of the gateway with the rules of the RouteAdvertisement
+// CR replaced by the ones in its spec, the rules created in NSX directly are kept before them.
+func (s *RouteAdvertisementService) buildAdvertisementRules(obj *v1alpha1.RouteAdvertisement, existing []model.RouteAdvertisementRule) []model.RouteAdvertisementRule {
+	rules := s.removeAdvertisementRules(obj.Name, existing)
+	for _, rule := range obj.Spec.Rules {
+		action := model.RouteAdvertisementRule_ACTION_PERMIT
+		if rule.Action == v1alpha1.RouteAdvertisementDeny {
+			action = model.RouteAdvertisementRule_ACTION_DENY
+		}
+		operator := model.RouteAdvertisementRule_PREFIX_OPERATOR_GE
+		if rule.PrefixOperator == v1alpha1.RouteAdvertisementPrefixEQ {
+			operator = model.RouteAdvertisementRule_PREFIX_OPERATOR_EQ
+		}
+		var types []string
+		for _, t := range rule.Types {
+			types = append(types, advertisementTypes[t])
+		}
+		rules = append(rules, model.RouteAdvertisementRule{
+			Name:                    String(s.rulePrefix(obj.Name) + rule.Name),
+			Action:                  String(action),
+			PrefixOperator:          String(operator),
+			Subnets:                 rule.Subnets,
+			RouteAdvertisementTypes: types,
+		})
+	}
+	return rules
+}
+
+// removeAdvertisementRules returns the rules except the ones of the RouteAdvertisement CR.
+func (s *RouteAdvertisementService) removeAdvertisementRules(name string, existing []model.RouteAdvertisementRule) []model.RouteAdvertisementRule {
+	rules := make([]model.RouteAdvertisementRule, 0, len(existing))
+	for _, rule := range existing {
+		if rule.Name != nil && strings.HasPrefix(*rule.Name, s.rulePrefix(name)) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// validateRules rejects the duplicate rule names and the invalid subnets.
+func validateRules(obj *v1alpha1.RouteAdvertisement) error {
+	names := map[string]bool{}
+	for _, rule := range obj.Spec.Rules {
+		if names[rule.Name] {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("duplicate rule %s in RouteAdvertisement %s", rule.Name, obj.Name)}
+		}
+		names[rule.Name] = true
+		for _, subnet := range rule.Subnets {
+			if _, _, err := net.ParseCIDR(subnet); err != nil {
+				return nsxutil.RestrictionError{Desc: fmt.Sprintf("invalid subnet %s of rule %s", subnet, rule.Name)}
+			}
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package routeadvertisement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func TestBuildAdvertisementTypes(t *testing.T) {
+	obj := newRouteAdvertisement("ra")
+	existing := []string{model.Tier1_ROUTE_ADVERTISEMENT_TYPES_STATIC_ROUTES, model.Tier1_ROUTE_ADVERTISEMENT_TYPES_IPSEC_LOCAL_ENDPOINT}
+	types := buildAdvertisementTypes(obj, existing)
+	assert.Equal(t, []string{
+		model.Tier1_ROUTE_ADVERTISEMENT_TYPES_IPSEC_LOCAL_ENDPOINT,
+		model.Tier1_ROUTE_ADVERTISEMENT_TYPES_CONNECTED,
+		model.Tier1_ROUTE_ADVERTISEMENT_TYPES_LB_VIP,
+	}, types)
+
+	obj.Spec.Advertise = nil
+	assert.Equal(t, []string{model.Tier1_ROUTE_ADVERTISEMENT_TYPES_IPSEC_LOCAL_ENDPOINT}, buildAdvertisementTypes(obj, existing))
+}
+
+func TestBuildAdvertisementRules(t *testing.T) {
+	s, _ := newFakeRouteAdvertisementService(nil)
+	obj := newRouteAdvertisement("ra")
+	obj.Spec.Rules = append(obj.Spec.Rules, v1alpha1.RouteAdvertisementRule{
+		Name:           "permit-vips",
+		Action:         v1alpha1.RouteAdvertisementPermit,
+		Subnets:        []string{"172.16.0.0/24"},
+		PrefixOperator: v1alpha1.RouteAdvertisementPrefixEQ,
+		Types:          []v1alpha1.RouteAdvertisementPrefixType{v1alpha1.RouteAdvertisementLBVIPs, v1alpha1.RouteAdvertisementNATIPs},
+	})
+	existing := []model.RouteAdvertisementRule{
+		{Name: String("manual")},
+		{Name: String("nsx-op/k8scl-one/ra/stale")},
+		{Name: String("nsx-op/k8scl-one/ra-other/rule")},
+	}
+
+	rules := s.buildAdvertisementRules(obj, existing)
+	assert.Len(t, rules, 4)
+	assert.Equal(t, "manual", *rules[0].Name)
+	assert.Equal(t, "nsx-op/k8scl-one/ra-other/rule", *rules[1].Name)
+	assert.Equal(t, model.RouteAdvertisementRule{
+		Name:                    String("nsx-op/k8scl-one/ra/deny-pods"),
+		Action:                  String(model.RouteAdvertisementRule_ACTION_DENY),
+		PrefixOperator:          String(model.RouteAdvertisementRule_PREFIX_OPERATOR_GE),
+		Subnets:                 []string{"10.0.0.0/16"},
+		RouteAdvertisementTypes: []string{model.RouteAdvertisementRule_ROUTE_ADVERTISEMENT_TYPES_CONNECTED},
+	}, rules[2])
+	assert.Equal(t, model.RouteAdvertisementRule_ACTION_PERMIT, *rules[3].Action)
+	assert.Equal(t, model.RouteAdvertisementRule_PREFIX_OPERATOR_EQ, *rules[3].PrefixOperator)
+	assert.Equal(t, []string{model.Tier1_ROUTE_ADVERTISEMENT_TYPES_LB_VIP, model.Tier1_ROUTE_ADVERTISEMENT_TYPES_NAT}, rules[3].RouteAdvertisementTypes)
+}
+
+func TestValidateRules(t *testing.T) {
+	obj := newRouteAdvertisement("ra")
+	assert.NoError(t, validateRules(obj))
+
+	obj.Spec.Rules = append(obj.Spec.Rules, obj.Spec.Rules[0])
+	assert.ErrorAs(t, validateRules(obj), &nsxutil.RestrictionError{})
+
+	obj.Spec.Rules = obj.Spec.Rules[:1]
+	obj.Spec.Rules[0].Subnets = []string{"10.0.0.0"}
+	assert.ErrorAs(t, validateRules(obj), &nsxutil.RestrictionError{})
+}
@@ -0,0 +1,98 @@
+package routeadvertisement
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// redistributionTypes maps the prefixes created by NSX Operator to the route redistribution types of the Tier-0
+// gateway.
+var redistributionTypes = map[v1alpha1.RouteAdvertisementPrefixType]string{
+	v1alpha1.RouteAdvertisementPodCIDRs:     model.Tier0RouteRedistributionRule_ROUTE_REDISTRIBUTION_TYPES_TIER1_CONNECTED,
+	v1alpha1.RouteAdvertisementLBVIPs:       model.Tier0RouteRedistributionRule_ROUTE_REDISTRIBUTION_TYPES_TIER1_LB_VIP,
+	v1alpha1.RouteAdvertisementNATIPs:       model.Tier0RouteRedistributionRule_ROUTE_REDISTRIBUTION_TYPES_TIER1_NAT,
+	v1alpha1.RouteAdvertisementStaticRoutes: model.Tier0RouteRedistributionRule_ROUTE_REDISTRIBUTION_TYPES_TIER1_STATIC,
+}
+
+// redistributionRuleName returns the name of the route redistribution rule of the RouteAdvertisement CR.
+func (s *RouteAdvertisementService) redistributionRuleName(name string) string {
+	return strings.TrimSuffix(s.rulePrefix(name), "/")
+}
+
+// parseTier0 returns the Tier-0 gateway of the route map path /infra/tier-0s/<tier0>/route-maps/<id>.
+func parseTier0(routeMapPath string) (string, error) {
+	parts := strings.Split(routeMapPath, "/")
+	if len(parts) != 6 || parts[1] != "infra" || parts[2] != "tier-0s" || parts[4] != "route-maps" {
+		return "", fmt.Errorf("invalid route map path %s", routeMapPath)
+	}
+	return parts[3], nil
+}
+
+// ApplyRouteRedistribution configures the rule redistributing the prefixes advertised by the RouteAdvertisement CR
+// through the route map on the Tier-0 gateway of the route map.
+func (s *RouteAdvertisementService) ApplyRouteRedistribution(obj *v1alpha1.RouteAdvertisement, routeMapPath string) error {
+	var types []string
+	for _, t := range obj.Spec.Advertise {
+		types = append(types, redistributionTypes[t])
+	}
+	rule := model.Tier0RouteRedistributionRule{
+		Name:                     String(s.redistributionRuleName(obj.Name)),
+		RouteMapPath:             String(routeMapPath),
+		RouteRedistributionTypes: types,
+		Destinations:             []string{model.Tier0RouteRedistributionRule_DESTINATIONS_BGP},
+	}
+	return s.updateRedistributionRules(obj.Name, routeMapPath, &rule)
+}
+
+// RemoveRouteRedistribution removes the route redistribution rule of the RouteAdvertisement CR from the Tier-0
+// gateway of the route map.
+func (s *RouteAdvertisementService) RemoveRouteRedistribution(name string, routeMapPath string) error {
+	return s.updateRedistributionRules(name, routeMapPath, nil)
+}
+
+// updateRedistributionRules replaces the rule of the RouteAdvertisement CR with rule in all the locale services of
+// the Tier-0 gateway, the rule is removed if it is nil.
+func (s *RouteAdvertisementService) updateRedistributionRules(name string, routeMapPath string, rule *model.Tier0RouteRedistributionRule) error {
+	tier0, err := parseTier0(routeMapPath)
+	if err != nil {
+		return nsxutil.RestrictionError{Desc: err.Error()}
+	}
+	localeServices, err := s.NSXClient.Tier0LocaleServicesClient.List(tier0, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	if rule != nil && len(localeServices.Results) == 0 {
+		return nsxutil.RestrictionError{Desc: fmt.Sprintf("no locale services found on Tier-0 gateway %s", tier0)}
+	}
+	ruleName := s.redistributionRuleName(name)
+	for _, localeService := range localeServices.Results {
+		config := model.Tier0RouteRedistributionConfig{}
+		if localeService.RouteRedistributionConfig != nil {
+			config = *localeService.RouteRedistributionConfig
+		}
+		rules := make([]model.Tier0RouteRedistributionRule, 0, len(config.RedistributionRules)+1)
+		for _, r := range config.RedistributionRules {
+			if r.Name == nil || *r.Name != ruleName {
+				rules = append(rules, r)
+			}
+		}
+		if rule != nil {
+			rules = append(rules, *rule)
+		}
+		if reflect.DeepEqual(rules, append([]model.Tier0RouteRedistributionRule{}, config.RedistributionRules...)) {
+			continue
+		}
+		config.RedistributionRules = rules
+		if err := s.NSXClient.Tier0LocaleServicesClient.Patch(tier0, *localeService.Id, model.LocaleServices{RouteRedistributionConfig: &config}); err != nil {
+			return err
+		}
+		log.Info("successfully updated route redistribution", "RouteAdvertisement", name, "Tier0", tier0, "LocaleServices", *localeService.Id)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package routeadvertisement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_0s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// fakeLocaleServicesClient stores the locale services of the Tier-0 gateways by gateway.
+type fakeLocaleServicesClient struct {
+	tier_0s.LocaleServicesClient
+	localeServices map[string][]model.LocaleServices
+	patched        int
+}
+
+func (c *fakeLocaleServicesClient) List(tier0 string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.LocaleServicesListResult, error) {
+	return model.LocaleServicesListResult{Results: c.localeServices[tier0]}, nil
+}
+
+func (c *fakeLocaleServicesClient) Patch(tier0 string, id string, localeServices model.LocaleServices) error {
+	c.patched++
+	for i := range c.localeServices[tier0] {
+		if *c.localeServices[tier0][i].Id == id {
+			c.localeServices[tier0][i].RouteRedistributionConfig = localeServices.RouteRedistributionConfig
+		}
+	}
+	return nil
+}
+
+func TestParseTier0(t *testing.T) {
+	tier0, err := parseTier0("/infra/tier-0s/t0/route-maps/rm_uid1")
+	assert.Nil(t, err)
+	assert.Equal(t, "t0", tier0)
+	_, err = parseTier0("/infra/tier-1s/t1/route-maps/rm_uid1")
+	assert.NotNil(t, err)
+}
+
+func TestRouteAdvertisementService_RouteRedistribution(t *testing.T) {
+	s, _ := newFakeRouteAdvertisementService(nil)
+	manual := model.Tier0RouteRedistributionRule{Name: String("manual")}
+	localeServicesClient := &fakeLocaleServicesClient{localeServices: map[string][]model.LocaleServices{
+		"t0": {{
+			Id:                        String("default"),
+			RouteRedistributionConfig: &model.Tier0RouteRedistributionConfig{BgpEnabled: common.Bool(true), RedistributionRules: []model.Tier0RouteRedistributionRule{manual}},
+		}},
+	}}
+	s.NSXClient.Tier0LocaleServicesClient = localeServicesClient
+	obj := newRouteAdvertisement("ra")
+	routeMapPath := "/infra/tier-0s/t0/route-maps/rm_uid1"
+
+	require.Nil(t, s.ApplyRouteRedistribution(obj, routeMapPath))
+	config := localeServicesClient.localeServices["t0"][0].RouteRedistributionConfig
+	assert.True(t, *config.BgpEnabled)
+	require.Len(t, config.RedistributionRules, 2)
+	assert.Equal(t, manual, config.RedistributionRules[0])
+	assert.Equal(t, model.Tier0RouteRedistributionRule{
+		Name:         String("nsx-op/k8scl-one/ra"),
+		RouteMapPath: String(routeMapPath),
+		RouteRedistributionTypes: []string{
+			model.Tier0RouteRedistributionRule_ROUTE_REDISTRIBUTION_TYPES_TIER1_CONNECTED,
+			model.Tier0RouteRedistributionRule_ROUTE_REDISTRIBUTION_TYPES_TIER1_LB_VIP,
+		},
+		Destinations: []string{model.Tier0RouteRedistributionRule_DESTINATIONS_BGP},
+	}, config.RedistributionRules[1])
+
+	// unchanged
+	require.Nil(t, s.ApplyRouteRedistribution(obj, routeMapPath))
+	assert.Equal(t, 1, localeServicesClient.patched)
+
+	require.Nil(t, s.RemoveRouteRedistribution("ra", routeMapPath))
+	config = localeServicesClient.localeServices["t0"][0].RouteRedistributionConfig
+	assert.Equal(t, []model.Tier0RouteRedistributionRule{manual}, config.RedistributionRules)
+
+	// the Tier-0 gateway has no locale services
+	err := s.ApplyRouteRedistribution(obj, "/infra/tier-0s/t0-other/route-maps/rm_uid1")
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+	assert.Nil(t, s.RemoveRouteRedistribution("ra", "/infra/tier-0s/t0-other/route-maps/rm_uid1"))
+}
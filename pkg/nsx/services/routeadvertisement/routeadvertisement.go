@@ -0,0 +1,127 @@
+package routeadvertisement
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var log = logger.Log
+
+// RouteAdvertisementService configures the route advertisement of the NSX Tier-1 gateways for the RouteAdvertisement
+// CRs. The gateways are not created by NSX Operator, so only the advertisement types of the prefixes created by NSX
+// Operator and the rules named after the CRs are changed.
+type RouteAdvertisementService struct {
+	common.Service
+}
+
+// InitializeRouteAdvertisement sync NSX resources
+func InitializeRouteAdvertisement(service common.Service) (*RouteAdvertisementService, error) {
+	return &RouteAdvertisementService{Service: service}, nil
+}
+
+// GetTier1Gateway returns the Tier-1 gateway of the RouteAdvertisement CR.
+func (s *RouteAdvertisementService) GetTier1Gateway(obj *v1alpha1.RouteAdvertisement) string {
+	if obj.Spec.Tier1Gateway != "" {
+		return obj.Spec.Tier1Gateway
+	}
+	return s.NSXConfig.Tier1Gateway
+}
+
+// ApplyRouteAdvertisement configures the route advertisement types and rules of the RouteAdvertisement CR on its
+// Tier-1 gateway.
+func (s *RouteAdvertisementService) ApplyRouteAdvertisement(obj *v1alpha1.RouteAdvertisement) error {
+	gateway := s.GetTier1Gateway(obj)
+	if gateway == "" {
+		return nsxutil.RestrictionError{Desc: fmt.Sprintf("no Tier-1 gateway found for RouteAdvertisement %s", obj.Name)}
+	}
+	if err := validateRules(obj); err != nil {
+		return err
+	}
+	tier1, err := s.NSXClient.Tier1Client.Get(gateway)
+	if err != nil {
+		if _, ok := err.(vapierrors.NotFound); ok {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("Tier-1 gateway %s not found", gateway)}
+		}
+		return err
+	}
+	types := buildAdvertisementTypes(obj, tier1.RouteAdvertisementTypes)
+	rules := s.buildAdvertisementRules(obj, tier1.RouteAdvertisementRules)
+	if equalTypes(types, tier1.RouteAdvertisementTypes) && reflect.DeepEqual(rules, nonNilRules(tier1.RouteAdvertisementRules)) {
+		return nil
+	}
+	if err := s.patchTier1(gateway, types, rules); err != nil {
+		return err
+	}
+	log.Info("successfully configured route advertisement", "RouteAdvertisement", obj.Name, "Tier1", gateway, "types", types)
+	return nil
+}
+
+// RemoveRouteAdvertisement removes the rules of the RouteAdvertisement CR from the Tier-1 gateway, the advertisement
+// types are left as they are.
+func (s *RouteAdvertisementService) RemoveRouteAdvertisement(name string, gateway string) error {
+	tier1, err := s.NSXClient.Tier1Client.Get(gateway)
+	if err != nil {
+		if _, ok := err.(vapierrors.NotFound); ok {
+			return nil
+		}
+		return err
+	}
+	rules := s.removeAdvertisementRules(name, tier1.RouteAdvertisementRules)
+	if len(rules) == len(tier1.RouteAdvertisementRules) {
+		return nil
+	}
+	if err := s.patchTier1(gateway, tier1.RouteAdvertisementTypes, rules); err != nil {
+		return err
+	}
+	log.Info("successfully removed route advertisement rules", "RouteAdvertisement", name, "Tier1", gateway)
+	return nil
+}
+
+// ListRouteAdvertisementNames returns the names of the RouteAdvertisement CRs which have rules on the Tier-1 gateway.
+func (s *RouteAdvertisementService) ListRouteAdvertisementNames(gateway string) (sets.String, error) {
+	names := sets.NewString()
+	tier1, err := s.NSXClient.Tier1Client.Get(gateway)
+	if err != nil {
+		if _, ok := err.(vapierrors.NotFound); ok {
+			return names, nil
+		}
+		return nil, err
+	}
+	prefix := s.rulePrefix("")
+	for _, rule := range tier1.RouteAdvertisementRules {
+		if rule.Name == nil || !strings.HasPrefix(*rule.Name, prefix) {
+			continue
+		}
+		if parts := strings.SplitN(strings.TrimPrefix(*rule.Name, prefix), "/", 2); len(parts) == 2 {
+			names.Insert(parts[0])
+		}
+	}
+	return names, nil
+}
+
+// patchTier1 patches only the route advertisement of the gateway, the empty lists are sent to clear the existing ones.
+func (s *RouteAdvertisementService) patchTier1(gateway string, types []string, rules []model.RouteAdvertisementRule) error {
+	tier1 := model.Tier1{
+		RouteAdvertisementTypes: append([]string{}, types...),
+		RouteAdvertisementRules: nonNilRules(rules),
+	}
+	return s.NSXClient.Tier1Client.Patch(gateway, tier1)
+}
+
+func nonNilRules(rules []model.RouteAdvertisementRule) []model.RouteAdvertisementRule {
+	return append([]model.RouteAdvertisementRule{}, rules...)
+}
+
+func equalTypes(a, b []string) bool {
+	return sets.NewString(a...).Equal(sets.NewString(b...)) && len(a) == len(b)
+}
@@ -0,0 +1,145 @@
+package routeadvertisement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// fakeTier1Client patches the route advertisement of the gateways by id.
+type fakeTier1Client struct {
+	infra.Tier1sClient
+	tier1s  map[string]model.Tier1
+	patched int
+}
+
+func (c *fakeTier1Client) Get(id string) (model.Tier1, error) {
+	tier1, ok := c.tier1s[id]
+	if !ok {
+		return model.Tier1{}, vapierrors.NotFound{}
+	}
+	return tier1, nil
+}
+
+func (c *fakeTier1Client) Patch(id string, tier1 model.Tier1) error {
+	c.patched++
+	existing := c.tier1s[id]
+	existing.RouteAdvertisementTypes = tier1.RouteAdvertisementTypes
+	existing.RouteAdvertisementRules = tier1.RouteAdvertisementRules
+	c.tier1s[id] = existing
+	return nil
+}
+
+func newFakeRouteAdvertisementService(tier1s map[string]model.Tier1) (*RouteAdvertisementService, *fakeTier1Client) {
+	tier1Client := &fakeTier1Client{tier1s: tier1s}
+	service := &RouteAdvertisementService{
+		Service: common.Service{
+			NSXClient: &nsx.Client{Tier1Client: tier1Client},
+			NSXConfig: &config.NSXOperatorConfig{
+				CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+				NsxConfig: &config.NsxConfig{Tier1Gateway: "t1"},
+			},
+		},
+	}
+	return service, tier1Client
+}
+
+func newRouteAdvertisement(name string) *v1alpha1.RouteAdvertisement {
+	return &v1alpha1.RouteAdvertisement{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.RouteAdvertisementSpec{
+			Advertise: []v1alpha1.RouteAdvertisementPrefixType{v1alpha1.RouteAdvertisementPodCIDRs, v1alpha1.RouteAdvertisementLBVIPs},
+			Rules: []v1alpha1.RouteAdvertisementRule{{
+				Name:    "deny-pods",
+				Action:  v1alpha1.RouteAdvertisementDeny,
+				Subnets: []string{"10.0.0.0/16"},
+				Types:   []v1alpha1.RouteAdvertisementPrefixType{v1alpha1.RouteAdvertisementPodCIDRs},
+			}},
+		},
+	}
+}
+
+func TestRouteAdvertisementService_GetTier1Gateway(t *testing.T) {
+	s, _ := newFakeRouteAdvertisementService(nil)
+	obj := newRouteAdvertisement("ra")
+	assert.Equal(t, "t1", s.GetTier1Gateway(obj))
+	obj.Spec.Tier1Gateway = "t1-other"
+	assert.Equal(t, "t1-other", s.GetTier1Gateway(obj))
+}
+
+func TestRouteAdvertisementService_ApplyRouteAdvertisement(t *testing.T) {
+	manual := model.RouteAdvertisementRule{Name: String("manual"), Subnets: []string{"192.168.0.0/24"}}
+	s, tier1Client := newFakeRouteAdvertisementService(map[string]model.Tier1{
+		"t1": {
+			RouteAdvertisementTypes: []string{model.Tier1_ROUTE_ADVERTISEMENT_TYPES_DNS_FORWARDER_IP, model.Tier1_ROUTE_ADVERTISEMENT_TYPES_NAT},
+			RouteAdvertisementRules: []model.RouteAdvertisementRule{manual},
+		},
+	})
+	obj := newRouteAdvertisement("ra")
+
+	require.NoError(t, s.ApplyRouteAdvertisement(obj))
+	tier1 := tier1Client.tier1s["t1"]
+	assert.ElementsMatch(t, []string{
+		model.Tier1_ROUTE_ADVERTISEMENT_TYPES_DNS_FORWARDER_IP,
+		model.Tier1_ROUTE_ADVERTISEMENT_TYPES_CONNECTED,
+		model.Tier1_ROUTE_ADVERTISEMENT_TYPES_LB_VIP,
+	}, tier1.RouteAdvertisementTypes)
+	require.Len(t, tier1.RouteAdvertisementRules, 2)
+	assert.Equal(t, manual, tier1.RouteAdvertisementRules[0])
+	assert.Equal(t, "nsx-op/k8scl-one/ra/deny-pods", *tier1.RouteAdvertisementRules[1].Name)
+	assert.Equal(t, 1, tier1Client.patched)
+
+	// unchanged
+	require.NoError(t, s.ApplyRouteAdvertisement(obj))
+	assert.Equal(t, 1, tier1Client.patched)
+
+	// unknown gateway
+	obj.Spec.Tier1Gateway = "t1-missing"
+	err := s.ApplyRouteAdvertisement(obj)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+
+	// no gateway
+	s.NSXConfig.Tier1Gateway = ""
+	obj.Spec.Tier1Gateway = ""
+	err = s.ApplyRouteAdvertisement(obj)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+}
+
+func TestRouteAdvertisementService_RemoveRouteAdvertisement(t *testing.T) {
+	s, tier1Client := newFakeRouteAdvertisementService(map[string]model.Tier1{"t1": {}})
+	require.NoError(t, s.ApplyRouteAdvertisement(newRouteAdvertisement("ra")))
+	other := newRouteAdvertisement("ra-other")
+	require.NoError(t, s.ApplyRouteAdvertisement(other))
+
+	names, err := s.ListRouteAdvertisementNames("t1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ra", "ra-other"}, names.List())
+
+	require.NoError(t, s.RemoveRouteAdvertisement("ra", "t1"))
+	tier1 := tier1Client.tier1s["t1"]
+	require.Len(t, tier1.RouteAdvertisementRules, 1)
+	assert.Equal(t, "nsx-op/k8scl-one/ra-other/deny-pods", *tier1.RouteAdvertisementRules[0].Name)
+	assert.Len(t, tier1.RouteAdvertisementTypes, 2)
+
+	// nothing to remove
+	patched := tier1Client.patched
+	require.NoError(t, s.RemoveRouteAdvertisement("ra", "t1"))
+	assert.Equal(t, patched, tier1Client.patched)
+
+	// the gateway has been removed
+	require.NoError(t, s.RemoveRouteAdvertisement("ra", "t1-missing"))
+	names, err = s.ListRouteAdvertisementNames("t1-missing")
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
@@ -0,0 +1,115 @@
+package routemap
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// networkAny matches all the prefixes in a prefix list.
+const networkAny = "ANY"
+
+var (
+	String = common.String
+	Int64  = common.Int64
+)
+
+// buildPrefixList builds the prefix list of the PrefixList CR on the Tier-0 gateway.
+func (s *RouteMapService) buildPrefixList(obj *v1alpha1.PrefixList, tier0 string) *model.PrefixList {
+	id := fmt.Sprintf("pl_%s", obj.UID)
+	parentPath := buildTier0Path(tier0)
+	prefixes := make([]model.PrefixEntry, 0, len(obj.Spec.Prefixes))
+	for _, prefix := range obj.Spec.Prefixes {
+		entry := model.PrefixEntry{
+			Network: String(prefix.Network),
+			Action:  String(buildAction(prefix.Action)),
+		}
+		if prefix.GE != 0 {
+			entry.Ge = Int64(int64(prefix.GE))
+		}
+		if prefix.LE != 0 {
+			entry.Le = Int64(int64(prefix.LE))
+		}
+		prefixes = append(prefixes, entry)
+	}
+	return &model.PrefixList{
+		Id:           String(id),
+		DisplayName:  String(obj.Name),
+		Path:         String(fmt.Sprintf("%s/prefix-lists/%s", parentPath, id)),
+		ParentPath:   String(parentPath),
+		ResourceType: String(common.ResourceTypePrefixList),
+		Prefixes:     prefixes,
+		Tags: []model.Tag{
+			{Scope: String(common.TagScopeCluster), Tag: String(s.NSXConfig.Cluster)},
+			{Scope: String(common.TagScopePrefixListCRName), Tag: String(obj.Name)},
+			{Scope: String(common.TagScopePrefixListCRUID), Tag: String(string(obj.UID))},
+		},
+	}
+}
+
+// buildRouteMap builds the route map of the RouteMap CR on the Tier-0 gateway, prefixListPaths are the paths of the
+// prefix lists matched by each entry.
+func (s *RouteMapService) buildRouteMap(obj *v1alpha1.RouteMap, tier0 string, prefixListPaths [][]string) *model.Tier0RouteMap {
+	id := fmt.Sprintf("rm_%s", obj.UID)
+	parentPath := buildTier0Path(tier0)
+	entries := make([]model.RouteMapEntry, 0, len(obj.Spec.Entries))
+	for i, entry := range obj.Spec.Entries {
+		entries = append(entries, model.RouteMapEntry{
+			Action:            String(buildAction(entry.Action)),
+			PrefixListMatches: prefixListPaths[i],
+		})
+	}
+	return &model.Tier0RouteMap{
+		Id:           String(id),
+		DisplayName:  String(obj.Name),
+		Path:         String(fmt.Sprintf("%s/route-maps/%s", parentPath, id)),
+		ParentPath:   String(parentPath),
+		ResourceType: String(common.ResourceTypeRouteMap),
+		Entries:      entries,
+		Tags: []model.Tag{
+			{Scope: String(common.TagScopeCluster), Tag: String(s.NSXConfig.Cluster)},
+			{Scope: String(common.TagScopeRouteMapCRName), Tag: String(obj.Name)},
+			{Scope: String(common.TagScopeRouteMapCRUID), Tag: String(string(obj.UID))},
+		},
+	}
+}
+
+func buildAction(action v1alpha1.RouteAdvertisementAction) string {
+	if action == v1alpha1.RouteAdvertisementDeny {
+		return model.PrefixEntry_ACTION_DENY
+	}
+	return model.PrefixEntry_ACTION_PERMIT
+}
+
+func buildTier0Path(tier0 string) string {
+	return fmt.Sprintf("/infra/tier-0s/%s", tier0)
+}
+
+// validatePrefixes rejects the invalid networks and the prefix lengths out of the range of the networks.
+func validatePrefixes(obj *v1alpha1.PrefixList) error {
+	for _, prefix := range obj.Spec.Prefixes {
+		if prefix.Network == networkAny {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(prefix.Network)
+		if err != nil {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("invalid network %s of PrefixList %s", prefix.Network, obj.Name)}
+		}
+		ones, bits := ipNet.Mask.Size()
+		if prefix.GE != 0 && (prefix.GE < ones || prefix.GE > bits) {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("ge %d of network %s is out of range [%d, %d]", prefix.GE, prefix.Network, ones, bits)}
+		}
+		if prefix.GE > ones {
+			ones = prefix.GE
+		}
+		if prefix.LE != 0 && (prefix.LE < ones || prefix.LE > bits) {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("le %d of network %s is out of range [%d, %d]", prefix.LE, prefix.Network, ones, bits)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package routemap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func TestBuildPrefixList(t *testing.T) {
+	s, _, _ := newFakeRouteMapService()
+	obj := newPrefixList("pl1", "uid1")
+	prefixList := s.buildPrefixList(obj, "t0")
+	assert.Equal(t, "pl_uid1", *prefixList.Id)
+	assert.Equal(t, "/infra/tier-0s/t0", *prefixList.ParentPath)
+	assert.Equal(t, "/infra/tier-0s/t0/prefix-lists/pl_uid1", *prefixList.Path)
+	assert.Equal(t, []model.PrefixEntry{
+		{Network: String("10.244.0.0/16"), Action: String(model.PrefixEntry_ACTION_PERMIT), Le: Int64(24)},
+		{Network: String("ANY"), Action: String(model.PrefixEntry_ACTION_DENY)},
+	}, prefixList.Prefixes)
+	assert.Len(t, prefixList.Tags, 3)
+}
+
+func TestBuildRouteMap(t *testing.T) {
+	s, _, _ := newFakeRouteMapService()
+	obj := newRouteMap("rm1", "uid2", "pl1")
+	routeMap := s.buildRouteMap(obj, "t0", [][]string{{"/infra/tier-0s/t0/prefix-lists/pl_uid1"}})
+	assert.Equal(t, "rm_uid2", *routeMap.Id)
+	assert.Equal(t, "/infra/tier-0s/t0/route-maps/rm_uid2", *routeMap.Path)
+	assert.Equal(t, []model.RouteMapEntry{{
+		Action:            String(model.RouteMapEntry_ACTION_PERMIT),
+		PrefixListMatches: []string{"/infra/tier-0s/t0/prefix-lists/pl_uid1"},
+	}}, routeMap.Entries)
+}
+
+func TestValidatePrefixes(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry v1alpha1.PrefixListEntry
+		valid bool
+	}{
+		{name: "any", entry: v1alpha1.PrefixListEntry{Network: "ANY"}, valid: true},
+		{name: "range", entry: v1alpha1.PrefixListEntry{Network: "10.0.0.0/8", GE: 16, LE: 24}, valid: true},
+		{name: "ipv6", entry: v1alpha1.PrefixListEntry{Network: "2001:db8::/32", LE: 64}, valid: true},
+		{name: "invalid network", entry: v1alpha1.PrefixListEntry{Network: "10.0.0.0"}},
+		{name: "ge shorter than network", entry: v1alpha1.PrefixListEntry{Network: "10.0.0.0/16", GE: 8}},
+		{name: "le longer than address", entry: v1alpha1.PrefixListEntry{Network: "10.0.0.0/16", LE: 33}},
+		{name: "le shorter than ge", entry: v1alpha1.PrefixListEntry{Network: "10.0.0.0/16", GE: 24, LE: 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &v1alpha1.PrefixList{
+				ObjectMeta: metav1.ObjectMeta{Name: "pl1"},
+				Spec:       v1alpha1.PrefixListSpec{Prefixes: []v1alpha1.PrefixListEntry{tt.entry}},
+			}
+			err := validatePrefixes(obj)
+			if tt.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+			}
+		})
+	}
+}
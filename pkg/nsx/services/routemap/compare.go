@@ -0,0 +1,61 @@
+package routemap
+
+import (
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type (
+	PrefixList model.PrefixList
+	RouteMap   model.Tier0RouteMap
+)
+
+type Comparable = common.Comparable
+
+func (prefixList *PrefixList) Key() string {
+	return *prefixList.Id
+}
+
+func (routeMap *RouteMap) Key() string {
+	return *routeMap.Id
+}
+
+func (prefixList *PrefixList) Value() data.DataValue {
+	p := &PrefixList{
+		Id:          prefixList.Id,
+		DisplayName: prefixList.DisplayName,
+		Tags:        prefixList.Tags,
+		Prefixes:    prefixList.Prefixes,
+	}
+	dataValue, _ := ComparableToPrefixList(p).GetDataValue__()
+	return dataValue
+}
+
+func (routeMap *RouteMap) Value() data.DataValue {
+	r := &RouteMap{
+		Id:          routeMap.Id,
+		DisplayName: routeMap.DisplayName,
+		Tags:        routeMap.Tags,
+		Entries:     routeMap.Entries,
+	}
+	dataValue, _ := ComparableToRouteMap(r).GetDataValue__()
+	return dataValue
+}
+
+func PrefixListToComparable(prefixList *model.PrefixList) Comparable {
+	return (*PrefixList)(prefixList)
+}
+
+func RouteMapToComparable(routeMap *model.Tier0RouteMap) Comparable {
+	return (*RouteMap)(routeMap)
+}
+
+func ComparableToPrefixList(prefixList Comparable) *model.PrefixList {
+	return (*model.PrefixList)(prefixList.(*PrefixList))
+}
+
+func ComparableToRouteMap(routeMap Comparable) *model.Tier0RouteMap {
+	return (*model.Tier0RouteMap)(routeMap.(*RouteMap))
+}
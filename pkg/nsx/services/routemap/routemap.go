@@ -0,0 +1,222 @@
+package routemap
+
+import (
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log             = logger.Log
+	MarkedForDelete = true
+)
+
+// RouteMapService manages the NSX prefix lists and route maps of the PrefixList and RouteMap CRs on the Tier-0
+// gateways.
+type RouteMapService struct {
+	common.Service
+	prefixListStore *PrefixListStore
+	routeMapStore   *RouteMapStore
+}
+
+// InitializeRouteMap sync NSX resources
+func InitializeRouteMap(service common.Service) (*RouteMapService, error) {
+	wg := sync.WaitGroup{}
+	wgDone := make(chan bool)
+	fatalErrors := make(chan error)
+
+	wg.Add(2)
+
+	routeMapService := &RouteMapService{Service: service}
+	routeMapService.prefixListStore = &PrefixListStore{ResourceStore: common.ResourceStore{
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopePrefixListCRName: prefixListNameIndexFunc}),
+		BindingType: model.PrefixListBindingType(),
+	}}
+	routeMapService.routeMapStore = &RouteMapStore{ResourceStore: common.ResourceStore{
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{}),
+		BindingType: model.Tier0RouteMapBindingType(),
+	}}
+
+	go routeMapService.InitializeResourceStore(&wg, fatalErrors, common.ResourceTypePrefixList, routeMapService.prefixListStore)
+	go routeMapService.InitializeResourceStore(&wg, fatalErrors, common.ResourceTypeRouteMap, routeMapService.routeMapStore)
+
+	go func() {
+		wg.Wait()
+		close(wgDone)
+	}()
+
+	select {
+	case <-wgDone:
+		break
+	case err := <-fatalErrors:
+		close(fatalErrors)
+		return routeMapService, err
+	}
+
+	return routeMapService, nil
+}
+
+// GetTier0Gateway returns the Tier-0 gateway of a PrefixList or RouteMap CR, the configured one is used if the CR
+// doesn't set it.
+func (s *RouteMapService) GetTier0Gateway(tier0 string) string {
+	if tier0 != "" {
+		return tier0
+	}
+	return s.NSXConfig.Tier0Gateway
+}
+
+// CreateOrUpdatePrefixList creates or updates the NSX prefix list of the PrefixList CR, it returns the path of the
+// prefix list.
+func (s *RouteMapService) CreateOrUpdatePrefixList(obj *v1alpha1.PrefixList) (string, error) {
+	tier0 := s.GetTier0Gateway(obj.Spec.Tier0Gateway)
+	if tier0 == "" {
+		return "", nsxutil.RestrictionError{Desc: fmt.Sprintf("no Tier-0 gateway found for PrefixList %s", obj.Name)}
+	}
+	if err := validatePrefixes(obj); err != nil {
+		return "", err
+	}
+	prefixList := s.buildPrefixList(obj, tier0)
+	changed := true
+	if existing := s.prefixListStore.GetByCRUID(string(obj.UID)); existing != nil {
+		if *existing.ParentPath != *prefixList.ParentPath {
+			// the PrefixList is moved to another gateway
+			if err := s.deletePrefixList(existing); err != nil {
+				return "", err
+			}
+		} else {
+			changed = common.CompareResource(PrefixListToComparable(existing), PrefixListToComparable(prefixList))
+		}
+	}
+	if changed {
+		if err := s.NSXClient.Tier0PrefixListClient.Patch(tier0, *prefixList.Id, *prefixList); err != nil {
+			return "", err
+		}
+		if err := s.prefixListStore.Operate(prefixList); err != nil {
+			return "", err
+		}
+		log.Info("successfully created or updated PrefixList", "PrefixList", prefixList)
+	}
+	return *prefixList.Path, nil
+}
+
+func (s *RouteMapService) deletePrefixList(prefixList *model.PrefixList) error {
+	if err := s.NSXClient.Tier0PrefixListClient.Delete(path.Base(*prefixList.ParentPath), *prefixList.Id); err != nil {
+		return err
+	}
+	prefixList.MarkedForDelete = &MarkedForDelete
+	if err := s.prefixListStore.Operate(prefixList); err != nil {
+		return err
+	}
+	log.Info("successfully deleted PrefixList", "PrefixList", prefixList)
+	return nil
+}
+
+// DeletePrefixListByCRUID deletes the NSX prefix list of the PrefixList CR, NSX rejects it while a route map matches
+// the prefix list.
+func (s *RouteMapService) DeletePrefixListByCRUID(uid types.UID) error {
+	prefixList := s.prefixListStore.GetByCRUID(string(uid))
+	if prefixList == nil {
+		return nil
+	}
+	return s.deletePrefixList(prefixList)
+}
+
+// ListPrefixListCRUID returns the UIDs of the PrefixList CRs which have NSX prefix lists.
+func (s *RouteMapService) ListPrefixListCRUID() sets.String {
+	return sets.NewString(s.prefixListStore.ListKeys()...)
+}
+
+// CreateOrUpdateRouteMap creates or updates the NSX route map of the RouteMap CR, it returns the path of the route
+// map. The PrefixLists matched by the route map must have been created on the same Tier-0 gateway.
+func (s *RouteMapService) CreateOrUpdateRouteMap(obj *v1alpha1.RouteMap) (string, error) {
+	tier0 := s.GetTier0Gateway(obj.Spec.Tier0Gateway)
+	if tier0 == "" {
+		return "", nsxutil.RestrictionError{Desc: fmt.Sprintf("no Tier-0 gateway found for RouteMap %s", obj.Name)}
+	}
+	prefixListPaths, err := s.getPrefixListPaths(obj, tier0)
+	if err != nil {
+		return "", err
+	}
+	routeMap := s.buildRouteMap(obj, tier0, prefixListPaths)
+	changed := true
+	if existing := s.routeMapStore.GetByCRUID(string(obj.UID)); existing != nil {
+		if *existing.ParentPath != *routeMap.ParentPath {
+			// the RouteMap is moved to another gateway
+			if err := s.deleteRouteMap(existing); err != nil {
+				return "", err
+			}
+		} else {
+			changed = common.CompareResource(RouteMapToComparable(existing), RouteMapToComparable(routeMap))
+		}
+	}
+	if changed {
+		if err := s.NSXClient.Tier0RouteMapClient.Patch(tier0, *routeMap.Id, *routeMap); err != nil {
+			return "", err
+		}
+		if err := s.routeMapStore.Operate(routeMap); err != nil {
+			return "", err
+		}
+		log.Info("successfully created or updated RouteMap", "RouteMap", routeMap)
+	}
+	return *routeMap.Path, nil
+}
+
+// getPrefixListPaths returns the paths of the prefix lists matched by each entry of the RouteMap CR, the error is
+// retried as the PrefixLists may be created after the RouteMap.
+func (s *RouteMapService) getPrefixListPaths(obj *v1alpha1.RouteMap, tier0 string) ([][]string, error) {
+	parentPath := buildTier0Path(tier0)
+	prefixListPaths := make([][]string, 0, len(obj.Spec.Entries))
+	for _, entry := range obj.Spec.Entries {
+		paths := make([]string, 0, len(entry.PrefixLists))
+		for _, name := range entry.PrefixLists {
+			var prefixListPath string
+			for _, prefixList := range s.prefixListStore.GetByCRName(name) {
+				if *prefixList.ParentPath == parentPath {
+					prefixListPath = *prefixList.Path
+				}
+			}
+			if prefixListPath == "" {
+				return nil, fmt.Errorf("PrefixList %s not found on Tier-0 gateway %s", name, tier0)
+			}
+			paths = append(paths, prefixListPath)
+		}
+		prefixListPaths = append(prefixListPaths, paths)
+	}
+	return prefixListPaths, nil
+}
+
+func (s *RouteMapService) deleteRouteMap(routeMap *model.Tier0RouteMap) error {
+	if err := s.NSXClient.Tier0RouteMapClient.Delete(path.Base(*routeMap.ParentPath), *routeMap.Id); err != nil {
+		return err
+	}
+	routeMap.MarkedForDelete = &MarkedForDelete
+	if err := s.routeMapStore.Operate(routeMap); err != nil {
+		return err
+	}
+	log.Info("successfully deleted RouteMap", "RouteMap", routeMap)
+	return nil
+}
+
+// DeleteRouteMapByCRUID deletes the NSX route map of the RouteMap CR.
+func (s *RouteMapService) DeleteRouteMapByCRUID(uid types.UID) error {
+	routeMap := s.routeMapStore.GetByCRUID(string(uid))
+	if routeMap == nil {
+		return nil
+	}
+	return s.deleteRouteMap(routeMap)
+}
+
+// ListRouteMapCRUID returns the UIDs of the RouteMap CRs which have NSX route maps.
+func (s *RouteMapService) ListRouteMapCRUID() sets.String {
+	return sets.NewString(s.routeMapStore.ListKeys()...)
+}
@@ -0,0 +1,165 @@
+package routemap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_0s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// fakePrefixListClient stores the prefix lists by gateway and id.
+type fakePrefixListClient struct {
+	tier_0s.PrefixListsClient
+	prefixLists map[string]model.PrefixList
+	patched     int
+}
+
+func (c *fakePrefixListClient) Patch(tier0 string, id string, prefixList model.PrefixList) error {
+	c.patched++
+	c.prefixLists[tier0+"/"+id] = prefixList
+	return nil
+}
+
+func (c *fakePrefixListClient) Delete(tier0 string, id string) error {
+	delete(c.prefixLists, tier0+"/"+id)
+	return nil
+}
+
+// fakeRouteMapClient stores the route maps by gateway and id.
+type fakeRouteMapClient struct {
+	tier_0s.RouteMapsClient
+	routeMaps map[string]model.Tier0RouteMap
+}
+
+func (c *fakeRouteMapClient) Patch(tier0 string, id string, routeMap model.Tier0RouteMap) error {
+	c.routeMaps[tier0+"/"+id] = routeMap
+	return nil
+}
+
+func (c *fakeRouteMapClient) Delete(tier0 string, id string) error {
+	delete(c.routeMaps, tier0+"/"+id)
+	return nil
+}
+
+func newFakeRouteMapService() (*RouteMapService, *fakePrefixListClient, *fakeRouteMapClient) {
+	prefixListClient := &fakePrefixListClient{prefixLists: map[string]model.PrefixList{}}
+	routeMapClient := &fakeRouteMapClient{routeMaps: map[string]model.Tier0RouteMap{}}
+	return &RouteMapService{
+		Service: common.Service{
+			NSXClient: &nsx.Client{
+				Tier0PrefixListClient: prefixListClient,
+				Tier0RouteMapClient:   routeMapClient,
+			},
+			NSXConfig: &config.NSXOperatorConfig{
+				CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+				NsxConfig: &config.NsxConfig{Tier0Gateway: "t0"},
+			},
+		},
+		prefixListStore: &PrefixListStore{ResourceStore: common.ResourceStore{
+			Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopePrefixListCRName: prefixListNameIndexFunc}),
+			BindingType: model.PrefixListBindingType(),
+		}},
+		routeMapStore: &RouteMapStore{ResourceStore: common.ResourceStore{
+			Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{}),
+			BindingType: model.Tier0RouteMapBindingType(),
+		}},
+	}, prefixListClient, routeMapClient
+}
+
+func newPrefixList(name string, uid types.UID) *v1alpha1.PrefixList {
+	return &v1alpha1.PrefixList{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: uid},
+		Spec: v1alpha1.PrefixListSpec{
+			Prefixes: []v1alpha1.PrefixListEntry{
+				{Network: "10.244.0.0/16", LE: 24},
+				{Network: "ANY", Action: v1alpha1.RouteAdvertisementDeny},
+			},
+		},
+	}
+}
+
+func newRouteMap(name string, uid types.UID, prefixLists ...string) *v1alpha1.RouteMap {
+	return &v1alpha1.RouteMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: uid},
+		Spec: v1alpha1.RouteMapSpec{
+			Entries: []v1alpha1.RouteMapEntry{{PrefixLists: prefixLists}},
+		},
+	}
+}
+
+func TestRouteMapService_PrefixList(t *testing.T) {
+	s, prefixListClient, _ := newFakeRouteMapService()
+	obj := newPrefixList("pl1", "uid1")
+
+	path, err := s.CreateOrUpdatePrefixList(obj)
+	require.Nil(t, err)
+	assert.Equal(t, "/infra/tier-0s/t0/prefix-lists/pl_uid1", path)
+	assert.Contains(t, prefixListClient.prefixLists, "t0/pl_uid1")
+	assert.Equal(t, []string{"uid1"}, s.ListPrefixListCRUID().List())
+
+	// unchanged prefix list is not patched again
+	_, err = s.CreateOrUpdatePrefixList(obj)
+	require.Nil(t, err)
+	assert.Equal(t, 1, prefixListClient.patched)
+
+	// the prefix list is moved to another gateway
+	obj.Spec.Tier0Gateway = "t0-other"
+	path, err = s.CreateOrUpdatePrefixList(obj)
+	require.Nil(t, err)
+	assert.Equal(t, "/infra/tier-0s/t0-other/prefix-lists/pl_uid1", path)
+	assert.NotContains(t, prefixListClient.prefixLists, "t0/pl_uid1")
+	assert.Contains(t, prefixListClient.prefixLists, "t0-other/pl_uid1")
+
+	require.Nil(t, s.DeletePrefixListByCRUID("uid1"))
+	assert.Empty(t, prefixListClient.prefixLists)
+	assert.Empty(t, s.ListPrefixListCRUID())
+	require.Nil(t, s.DeletePrefixListByCRUID("uid1"))
+
+	// no gateway
+	s.NSXConfig.Tier0Gateway = ""
+	_, err = s.CreateOrUpdatePrefixList(newPrefixList("pl2", "uid2"))
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+}
+
+func TestRouteMapService_RouteMap(t *testing.T) {
+	s, _, routeMapClient := newFakeRouteMapService()
+	obj := newRouteMap("rm1", "uid2", "pl1")
+
+	// the PrefixList is not created yet
+	_, err := s.CreateOrUpdateRouteMap(obj)
+	require.NotNil(t, err)
+	assert.False(t, errors.As(err, &nsxutil.RestrictionError{}))
+	assert.Contains(t, err.Error(), "PrefixList pl1 not found on Tier-0 gateway t0")
+
+	// the PrefixList on another gateway is not matched
+	other := newPrefixList("pl1", "uid3")
+	other.Spec.Tier0Gateway = "t0-other"
+	_, err = s.CreateOrUpdatePrefixList(other)
+	require.Nil(t, err)
+	_, err = s.CreateOrUpdateRouteMap(obj)
+	require.NotNil(t, err)
+
+	_, err = s.CreateOrUpdatePrefixList(newPrefixList("pl1", "uid1"))
+	require.Nil(t, err)
+	path, err := s.CreateOrUpdateRouteMap(obj)
+	require.Nil(t, err)
+	assert.Equal(t, "/infra/tier-0s/t0/route-maps/rm_uid2", path)
+	assert.Equal(t, []string{"/infra/tier-0s/t0/prefix-lists/pl_uid1"}, routeMapClient.routeMaps["t0/rm_uid2"].Entries[0].PrefixListMatches)
+	assert.Equal(t, []string{"uid2"}, s.ListRouteMapCRUID().List())
+
+	require.Nil(t, s.DeleteRouteMapByCRUID("uid2"))
+	assert.Empty(t, routeMapClient.routeMaps)
+	assert.Empty(t, s.ListRouteMapCRUID())
+}
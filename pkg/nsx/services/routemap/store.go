@@ -0,0 +1,126 @@
+package routemap
+
+import (
+	"errors"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// keyFunc is used to get the key of a prefix list or route map, which is the UID of the CR it is created for.
+func keyFunc(obj interface{}) (string, error) {
+	switch v := obj.(type) {
+	case model.PrefixList:
+		return crUID(v.Id, v.Tags, common.TagScopePrefixListCRUID), nil
+	case model.Tier0RouteMap:
+		return crUID(v.Id, v.Tags, common.TagScopeRouteMapCRUID), nil
+	default:
+		return "", errors.New("keyFunc doesn't support unknown type")
+	}
+}
+
+func crUID(id *string, tags []model.Tag, tagScope string) string {
+	uids := filterTag(tags, tagScope)
+	if len(uids) == 0 {
+		return *id
+	}
+	return uids[0]
+}
+
+// prefixListNameIndexFunc indexes the prefix lists by the names of their PrefixList CRs.
+func prefixListNameIndexFunc(obj interface{}) ([]string, error) {
+	switch v := obj.(type) {
+	case model.PrefixList:
+		return filterTag(v.Tags, common.TagScopePrefixListCRName), nil
+	default:
+		return nil, errors.New("prefixListNameIndexFunc doesn't support unknown type")
+	}
+}
+
+func filterTag(tags []model.Tag, tagScope string) []string {
+	res := make([]string, 0, 5)
+	for _, tag := range tags {
+		if *tag.Scope == tagScope {
+			res = append(res, *tag.Tag)
+		}
+	}
+	return res
+}
+
+// PrefixListStore is a store for NSX prefix lists
+type PrefixListStore struct {
+	common.ResourceStore
+}
+
+func (prefixListStore *PrefixListStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	prefixList := i.(*model.PrefixList)
+	if prefixList.MarkedForDelete != nil && *prefixList.MarkedForDelete {
+		if err := prefixListStore.Delete(*prefixList); err != nil {
+			return err
+		}
+		log.V(1).Info("delete PrefixList from store", "PrefixList", prefixList)
+	} else {
+		if err := prefixListStore.Add(*prefixList); err != nil {
+			return err
+		}
+		log.V(1).Info("add PrefixList to store", "PrefixList", prefixList)
+	}
+	return nil
+}
+
+// GetByCRUID returns the prefix list of the PrefixList CR, or nil if it is not created yet.
+func (prefixListStore *PrefixListStore) GetByCRUID(uid string) *model.PrefixList {
+	obj := prefixListStore.GetByKey(uid)
+	if obj == nil {
+		return nil
+	}
+	prefixList := obj.(model.PrefixList)
+	return &prefixList
+}
+
+// GetByCRName returns the prefix lists of the PrefixList CR with the name.
+func (prefixListStore *PrefixListStore) GetByCRName(name string) []model.PrefixList {
+	var prefixLists []model.PrefixList
+	for _, obj := range prefixListStore.GetByIndex(common.TagScopePrefixListCRName, name) {
+		prefixLists = append(prefixLists, obj.(model.PrefixList))
+	}
+	return prefixLists
+}
+
+// RouteMapStore is a store for NSX route maps
+type RouteMapStore struct {
+	common.ResourceStore
+}
+
+func (routeMapStore *RouteMapStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	routeMap := i.(*model.Tier0RouteMap)
+	if routeMap.MarkedForDelete != nil && *routeMap.MarkedForDelete {
+		if err := routeMapStore.Delete(*routeMap); err != nil {
+			return err
+		}
+		log.V(1).Info("delete RouteMap from store", "RouteMap", routeMap)
+	} else {
+		if err := routeMapStore.Add(*routeMap); err != nil {
+			return err
+		}
+		log.V(1).Info("add RouteMap to store", "RouteMap", routeMap)
+	}
+	return nil
+}
+
+// GetByCRUID returns the route map of the RouteMap CR, or nil if it is not created yet.
+func (routeMapStore *RouteMapStore) GetByCRUID(uid string) *model.Tier0RouteMap {
+	obj := routeMapStore.GetByKey(uid)
+	if obj == nil {
+		return nil
+	}
+	routeMap := obj.(model.Tier0RouteMap)
+	return &routeMap
+}
@@ -0,0 +1,55 @@
+package routemap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestPrefixListStore_Operate(t *testing.T) {
+	store := &PrefixListStore{ResourceStore: common.ResourceStore{
+		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopePrefixListCRName: prefixListNameIndexFunc}),
+		BindingType: model.PrefixListBindingType(),
+	}}
+	prefixList := &model.PrefixList{
+		Id: String("pl_uid1"),
+		Tags: []model.Tag{
+			{Scope: String(common.TagScopePrefixListCRName), Tag: String("pl1")},
+			{Scope: String(common.TagScopePrefixListCRUID), Tag: String("uid1")},
+		},
+	}
+	assert.Nil(t, store.Operate(prefixList))
+	assert.Equal(t, prefixList, store.GetByCRUID("uid1"))
+	assert.Equal(t, []model.PrefixList{*prefixList}, store.GetByCRName("pl1"))
+	assert.Empty(t, store.GetByCRName("pl2"))
+
+	prefixList.MarkedForDelete = &MarkedForDelete
+	assert.Nil(t, store.Operate(prefixList))
+	assert.Nil(t, store.GetByCRUID("uid1"))
+	assert.Empty(t, store.GetByCRName("pl1"))
+
+	_, err := keyFunc(model.Vpc{})
+	assert.NotNil(t, err)
+}
+
+func TestRouteMapStore_Operate(t *testing.T) {
+	store := &RouteMapStore{ResourceStore: common.ResourceStore{
+		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{}),
+		BindingType: model.Tier0RouteMapBindingType(),
+	}}
+	routeMap := &model.Tier0RouteMap{
+		Id:   String("rm_uid1"),
+		Tags: []model.Tag{{Scope: String(common.TagScopeRouteMapCRUID), Tag: String("uid1")}},
+	}
+	assert.Nil(t, store.Operate(routeMap))
+	assert.Equal(t, routeMap, store.GetByCRUID("uid1"))
+	assert.Nil(t, store.GetByCRUID("rm_uid1"))
+
+	routeMap.MarkedForDelete = &MarkedForDelete
+	assert.Nil(t, store.Operate(routeMap))
+	assert.Nil(t, store.GetByCRUID("uid1"))
+}
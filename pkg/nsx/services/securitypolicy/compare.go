@@ -88,6 +88,18 @@ func GroupsToComparable(groups []model.Group) []Comparable {
 	return res
 }
 
+// ruleToComparable returns the Comparable of a rule of the rule store.
+func ruleToComparable(obj interface{}) Comparable {
+	rule := obj.(model.Rule)
+	return (*Rule)(&rule)
+}
+
+// groupToComparable returns the Comparable of a group of the group store.
+func groupToComparable(obj interface{}) Comparable {
+	group := obj.(model.Group)
+	return (*Group)(&group)
+}
+
 func ComparableToSecurityPolicy(sp Comparable) *model.SecurityPolicy {
 	return (*model.SecurityPolicy)(sp.(*SecurityPolicy))
 }
@@ -95,6 +107,11 @@ func ComparableToSecurityPolicy(sp Comparable) *model.SecurityPolicy {
 func ComparableToRules(rules []Comparable) []model.Rule {
 	res := make([]model.Rule, 0, len(rules))
 	for _, rule := range rules {
+		// the stale rules of a compact store only need their IDs to be deleted
+		if r, ok := rule.(common.CompactResource); ok {
+			res = append(res, r.Expand(model.RuleBindingType()).(model.Rule))
+			continue
+		}
 		res = append(res, (model.Rule)(*(rule.(*Rule))))
 	}
 	return res
@@ -107,6 +124,10 @@ func ComparableToRule(rule Comparable) *model.Rule {
 func ComparableToGroups(groups []Comparable) []model.Group {
 	res := make([]model.Group, 0, len(groups))
 	for _, group := range groups {
+		if g, ok := group.(common.CompactResource); ok {
+			res = append(res, g.Expand(model.GroupBindingType()).(model.Group))
+			continue
+		}
 		res = append(res, (model.Group)(*(group.(*Group))))
 	}
 	return res
@@ -64,11 +64,13 @@ func (service *SecurityPolicyService) expandRuleByPort(obj *v1alpha1.SecurityPol
 			// Update the stale ip set group if stale ips exist
 			if errors.As(err, &nsxutil.NoEffectiveOption{}) {
 				groups := service.groupStore.GetByIndex(common.TagScopeRuleID, service.buildRuleID(obj, ruleIdx))
-				var ipSetGroup model.Group
 				for _, group := range groups {
-					ipSetGroup = group
+					ipSetGroup, err3 := service.fetchGroup(group)
+					if err3 != nil {
+						return nil, nil, err3
+					}
 					ipSetGroup.Expression = nil // clear the stale ips
-					err3 := service.createOrUpdateGroups([]model.Group{ipSetGroup})
+					err3 = service.createOrUpdateGroups([]model.Group{ipSetGroup})
 					if err3 != nil {
 						return nil, nil, err3
 					}
@@ -106,11 +108,7 @@ func (service *SecurityPolicyService) expandRuleByService(obj *v1alpha1.Security
 	// If portAddress contains a list of IPs, we should build an ip set group for the rule.
 	if len(portAddress.IPs) > 0 {
 		ruleIPSetGroup := service.buildRuleIPSetGroup(obj, rule, nsxRule, portAddress.IPs, ruleIdx)
-		groupPath := fmt.Sprintf(
-			"/infra/domains/%s/groups/%s",
-			getDomain(service),
-			*ruleIPSetGroup.Id,
-		)
+		groupPath := service.buildGroupPath(*ruleIPSetGroup.Id)
 		nsxRule.DestinationGroups = []string{groupPath}
 		log.V(2).Info("built ruleIPSetGroup", "ruleIPSetGroup", ruleIPSetGroup)
 		nsxGroups = append(nsxGroups, ruleIPSetGroup)
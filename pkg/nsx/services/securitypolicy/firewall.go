@@ -41,15 +41,22 @@ func InitializeSecurityPolicy(service common.Service) (*SecurityPolicyService, e
 	securityPolicyService := &SecurityPolicyService{Service: service}
 
 	securityPolicyService.securityPolicyStore = &SecurityPolicyStore{ResourceStore: common.ResourceStore{
-		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc}),
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc}),
 		BindingType: model.SecurityPolicyBindingType(),
 	}}
+	// the rules and the groups outnumber the other NSX resources by far in large clusters
+	groupIndexer := common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc})
+	ruleIndexer := common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc})
+	if service.NSXConfig.CompactStores {
+		groupIndexer = common.NewCompactIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc}, groupToComparable)
+		ruleIndexer = common.NewCompactIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc}, ruleToComparable)
+	}
 	securityPolicyService.groupStore = &GroupStore{ResourceStore: common.ResourceStore{
-		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc}),
+		Indexer:     groupIndexer,
 		BindingType: model.GroupBindingType(),
 	}}
 	securityPolicyService.ruleStore = &RuleStore{ResourceStore: common.ResourceStore{
-		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc}),
+		Indexer:     ruleIndexer,
 		BindingType: model.RuleBindingType(),
 	}}
 
@@ -85,13 +92,13 @@ func (service *SecurityPolicyService) CreateOrUpdateSecurityPolicy(obj *v1alpha1
 	}
 
 	existingSecurityPolicy := service.securityPolicyStore.GetByKey(*nsxSecurityPolicy.Id)
-	existingRules := service.ruleStore.GetByIndex(common.TagScopeSecurityPolicyCRUID, string(obj.UID))
-	existingGroups := service.groupStore.GetByIndex(common.TagScopeSecurityPolicyCRUID, string(obj.UID))
+	existingRules := service.ruleStore.ComparableByIndex(common.TagScopeSecurityPolicyCRUID, string(obj.UID), ruleToComparable)
+	existingGroups := service.groupStore.ComparableByIndex(common.TagScopeSecurityPolicyCRUID, string(obj.UID), groupToComparable)
 
 	isChanged := common.CompareResource(SecurityPolicyToComparable(existingSecurityPolicy), SecurityPolicyToComparable(nsxSecurityPolicy))
-	changed, stale := common.CompareResources(RulesToComparable(existingRules), RulesToComparable(nsxSecurityPolicy.Rules))
+	changed, stale := common.CompareResources(existingRules, RulesToComparable(nsxSecurityPolicy.Rules))
 	changedRules, staleRules := ComparableToRules(changed), ComparableToRules(stale)
-	changed, stale = common.CompareResources(GroupsToComparable(existingGroups), GroupsToComparable(*nsxGroups))
+	changed, stale = common.CompareResources(existingGroups, GroupsToComparable(*nsxGroups))
 	changedGroups, staleGroups := ComparableToGroups(changed), ComparableToGroups(stale)
 
 	if !isChanged && len(changedRules) == 0 && len(staleRules) == 0 && len(changedGroups) == 0 && len(staleGroups) == 0 {
@@ -128,7 +135,7 @@ func (service *SecurityPolicyService) CreateOrUpdateSecurityPolicy(obj *v1alpha1
 	if err != nil {
 		return err
 	}
-	err = service.NSXClient.InfraClient.Patch(*infraSecurityPolicy, &EnforceRevisionCheckParam)
+	err = service.patchInfra(infraSecurityPolicy)
 	if err != nil {
 		return err
 	}
@@ -201,7 +208,7 @@ func (service *SecurityPolicyService) DeleteSecurityPolicy(obj interface{}) erro
 	if err != nil {
 		return err
 	}
-	err = service.NSXClient.InfraClient.Patch(*infraSecurityPolicy, &EnforceRevisionCheckParam)
+	err = service.patchInfra(infraSecurityPolicy)
 	if err != nil {
 		return err
 	}
@@ -224,7 +231,12 @@ func (service *SecurityPolicyService) DeleteSecurityPolicy(obj interface{}) erro
 func (service *SecurityPolicyService) createOrUpdateGroups(nsxGroups []model.Group) error {
 	for _, group := range nsxGroups {
 		group.MarkedForDelete = nil
-		err := service.NSXClient.GroupClient.Patch(getDomain(service), *group.Id, group)
+		var err error
+		if service.NSXConfig.InProject() {
+			err = service.NSXClient.ProjectGroupClient.Patch(service.NSXConfig.GetNsxOrg(), service.NSXConfig.NsxProject, getDomain(service), *group.Id, group)
+		} else {
+			err = service.NSXClient.GroupClient.Patch(getDomain(service), *group.Id, group)
+		}
 		if err != nil {
 			return err
 		}
@@ -238,6 +250,18 @@ func (service *SecurityPolicyService) createOrUpdateGroups(nsxGroups []model.Gro
 	return nil
 }
 
+// fetchGroup returns the whole group of the group store, it is fetched from NSX if the store only holds its
+// CompactResource.
+func (service *SecurityPolicyService) fetchGroup(group model.Group) (model.Group, error) {
+	if !service.groupStore.Compact() {
+		return group, nil
+	}
+	if service.NSXConfig.InProject() {
+		return service.NSXClient.ProjectGroupClient.Get(service.NSXConfig.GetNsxOrg(), service.NSXConfig.NsxProject, getDomain(service), *group.Id)
+	}
+	return service.NSXClient.GroupClient.Get(getDomain(service), *group.Id)
+}
+
 func (service *SecurityPolicyService) ListSecurityPolicyID() sets.String {
 	groupSet := service.groupStore.ListIndexFuncValues(common.TagScopeSecurityPolicyCRUID)
 	policySet := service.securityPolicyStore.ListIndexFuncValues(common.TagScopeSecurityPolicyCRUID)
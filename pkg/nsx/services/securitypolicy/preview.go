@@ -0,0 +1,48 @@
+package securitypolicy
+
+import (
+	"fmt"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// RealizationPreview is what realizing a SecurityPolicy would create on NSX, and what it would change compared to
+// what is realized of it.
+type RealizationPreview struct {
+	// Path of the NSX security policy.
+	Path string `json:"path"`
+	// Paths of the NSX groups of the scope, the sources and the destinations of the policy and its rules.
+	Groups []string `json:"groups"`
+	// Number of the NSX rules, a rule with named ports expands to a rule per port number.
+	RuleCount int `json:"ruleCount"`
+	// Number of the NSX rules and groups which would be created or updated, and deleted.
+	ChangedRules  int `json:"changedRules"`
+	StaleRules    int `json:"staleRules"`
+	ChangedGroups int `json:"changedGroups"`
+	StaleGroups   int `json:"staleGroups"`
+}
+
+// PreviewSecurityPolicy builds the NSX security policy, rules and groups of obj as CreateOrUpdateSecurityPolicy does
+// and compares them with the stores, without calling NSX.
+func (service *SecurityPolicyService) PreviewSecurityPolicy(obj *v1alpha1.SecurityPolicy) (*RealizationPreview, error) {
+	nsxSecurityPolicy, nsxGroups, err := service.buildSecurityPolicy(obj)
+	if err != nil {
+		return nil, err
+	}
+	preview := &RealizationPreview{
+		Path:      fmt.Sprintf("%s/domains/%s/security-policies/%s", service.InfraPath(), getDomain(service), *nsxSecurityPolicy.Id),
+		Groups:    make([]string, 0, len(*nsxGroups)),
+		RuleCount: len(nsxSecurityPolicy.Rules),
+	}
+	for _, group := range *nsxGroups {
+		preview.Groups = append(preview.Groups, service.buildGroupPath(*group.Id))
+	}
+	existingRules := service.ruleStore.ComparableByIndex(common.TagScopeSecurityPolicyCRUID, string(obj.UID), ruleToComparable)
+	existingGroups := service.groupStore.ComparableByIndex(common.TagScopeSecurityPolicyCRUID, string(obj.UID), groupToComparable)
+	changed, stale := common.CompareResources(existingRules, RulesToComparable(nsxSecurityPolicy.Rules))
+	preview.ChangedRules, preview.StaleRules = len(changed), len(stale)
+	changed, stale = common.CompareResources(existingGroups, GroupsToComparable(*nsxGroups))
+	preview.ChangedGroups, preview.StaleGroups = len(changed), len(stale)
+	return preview, nil
+}
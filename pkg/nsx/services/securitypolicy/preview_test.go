@@ -0,0 +1,46 @@
+package securitypolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestPreviewSecurityPolicy(t *testing.T) {
+	previewService := &SecurityPolicyService{Service: service.Service}
+	previewService.groupStore = &GroupStore{ResourceStore: common.ResourceStore{
+		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc}),
+		BindingType: model.GroupBindingType(),
+	}}
+	previewService.ruleStore = &RuleStore{ResourceStore: common.ResourceStore{
+		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc}),
+		BindingType: model.RuleBindingType(),
+	}}
+
+	preview, err := previewService.PreviewSecurityPolicy(&spWithPodSelector)
+	assert.Nil(t, err)
+	assert.Equal(t, &RealizationPreview{
+		Path: "/infra/domains/k8scl-one/security-policies/sp_uidA",
+		Groups: []string{
+			"/infra/domains/k8scl-one/groups/sp_uidA_scope",
+			"/infra/domains/k8scl-one/groups/sp_uidA_0_src",
+			"/infra/domains/k8scl-one/groups/sp_uidA_0_scope",
+			"/infra/domains/k8scl-one/groups/sp_uidA_1_src",
+		},
+		RuleCount:     2,
+		ChangedRules:  2,
+		ChangedGroups: 4,
+	}, preview)
+
+	// the realized rules are not changed again
+	nsxSecurityPolicy, _, err := previewService.buildSecurityPolicy(&spWithPodSelector)
+	assert.Nil(t, err)
+	assert.Nil(t, previewService.ruleStore.Operate(nsxSecurityPolicy))
+	preview, err = previewService.PreviewSecurityPolicy(&spWithPodSelector)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 0}, []int{preview.ChangedRules, preview.StaleRules})
+}
@@ -17,6 +17,8 @@ func keyFunc(obj interface{}) (string, error) {
 		return *v.Id, nil
 	case model.Rule:
 		return *v.Id, nil
+	case common.CompactResource:
+		return *v.Id, nil
 	default:
 		return "", errors.New("keyFunc doesn't support unknown type")
 	}
@@ -33,6 +35,8 @@ func indexFunc(obj interface{}) ([]string, error) {
 		return filterTag(o.Tags), nil
 	case model.Rule:
 		return filterTag(o.Tags), nil
+	case common.CompactResource:
+		return filterTag(o.Tags), nil
 	default:
 		return res, errors.New("indexFunc doesn't support unknown type")
 	}
@@ -53,12 +57,14 @@ type SecurityPolicyStore struct {
 	common.ResourceStore
 }
 
-// RuleStore is a store for rules of security policy
+// RuleStore is a store for rules of security policy, it holds the CompactResources of the rules if compact_stores is
+// set, GetByIndex then returns the rules with their IDs, paths, revisions and tags only.
 type RuleStore struct {
 	common.ResourceStore
 }
 
-// GroupStore is a store for groups referenced by security policy or rule
+// GroupStore is a store for groups referenced by security policy or rule, it holds the CompactResources of the groups
+// if compact_stores is set like RuleStore.
 type GroupStore struct {
 	common.ResourceStore
 }
@@ -126,6 +132,9 @@ func (ruleStore *RuleStore) GetByIndex(key string, value string) []model.Rule {
 	rules := make([]model.Rule, 0)
 	objs := ruleStore.ResourceStore.GetByIndex(key, value)
 	for _, rule := range objs {
+		if r, ok := rule.(common.CompactResource); ok {
+			rule = r.Expand(ruleStore.BindingType)
+		}
 		rules = append(rules, rule.(model.Rule))
 	}
 	return rules
@@ -155,6 +164,9 @@ func (groupStore *GroupStore) GetByIndex(key string, value string) []model.Group
 	groups := make([]model.Group, 0)
 	objs := groupStore.ResourceStore.GetByIndex(key, value)
 	for _, group := range objs {
+		if r, ok := group.(common.CompactResource); ok {
+			group = r.Expand(groupStore.BindingType)
+		}
 		groups = append(groups, group.(model.Group))
 	}
 	return groups
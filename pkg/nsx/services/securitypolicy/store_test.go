@@ -357,3 +357,53 @@ func TestGroupStore_Operator(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleStore_Compact(t *testing.T) {
+	ruleStore := &RuleStore{ResourceStore: common.ResourceStore{
+		Indexer:     common.NewCompactIndexer(keyFunc, cache.Indexers{common.TagScopeSecurityPolicyCRUID: indexFunc}, ruleToComparable),
+		BindingType: model.RuleBindingType(),
+	}}
+	rules := []model.Rule{
+		{
+			DisplayName:       &ruleNameWithPodSelector00,
+			Id:                &ruleIDPort000,
+			DestinationGroups: []string{"ANY"},
+			Direction:         &nsxDirectionIn,
+			Services:          []string{"ANY"},
+			Action:            &nsxActionAllow,
+			Tags:              basicTags,
+		},
+		{
+			DisplayName:       &ruleNameWithNsSelector00,
+			Id:                &ruleIDPort100,
+			DestinationGroups: []string{"ANY"},
+			Direction:         &nsxDirectionIn,
+			Services:          []string{"ANY"},
+			Action:            &nsxActionAllow,
+			Tags:              basicTags,
+		},
+	}
+	assert.Nil(t, ruleStore.Operate(&model.SecurityPolicy{Rules: rules}))
+
+	// the rules only have their IDs and tags
+	existing := ruleStore.GetByIndex(common.TagScopeSecurityPolicyCRUID, tagValuePolicyCRUID)
+	assert.Len(t, existing, 2)
+	for _, rule := range existing {
+		assert.Nil(t, rule.DisplayName)
+		assert.Equal(t, basicTags, rule.Tags)
+	}
+
+	// they are diffed by the hashes of their compared fields
+	comparables := ruleStore.ComparableByIndex(common.TagScopeSecurityPolicyCRUID, tagValuePolicyCRUID, ruleToComparable)
+	changed, stale := common.CompareResources(comparables, RulesToComparable(rules))
+	assert.Empty(t, changed)
+	assert.Empty(t, stale)
+	action := "DROP"
+	expected := []model.Rule{rules[0]}
+	expected[0].Action = &action
+	changed, stale = common.CompareResources(comparables, RulesToComparable(expected))
+	assert.Equal(t, []model.Rule{expected[0]}, ComparableToRules(changed))
+	staleRules := ComparableToRules(stale)
+	assert.Len(t, staleRules, 1)
+	assert.Equal(t, ruleIDPort100, *staleRules[0].Id)
+}
@@ -46,6 +46,14 @@ func (service *SecurityPolicyService) WrapHierarchySecurityPolicy(sp *model.Secu
 	return infra, nil
 }
 
+// patchInfra patches the hierarchical infra, it is wrapped into the NSX Project with OrgRoot API
+// if NSX Operator runs in an NSX Project.
+func (service *SecurityPolicyService) patchInfra(infra *model.Infra) error {
+	tx := service.NewHTransaction()
+	tx.AddToInfra(nil, infra.Children...)
+	return tx.Commit()
+}
+
 func (service *SecurityPolicyService) wrapInfra(children []*data.StructValue) (*model.Infra, error) {
 	// This is the outermost layer of the hierarchy security policy.
 	// It doesn't need ID field.
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/vmware/vsphere-automation-sdk-go/runtime/bindings"
 	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	nsx_policy "github.com/vmware/vsphere-automation-sdk-go/services/nsxt"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
 
 	"github.com/vmware-tanzu/nsx-operator/pkg/config"
@@ -202,3 +203,45 @@ func TestSecurityPolicyService_wrapResourceReference(t *testing.T) {
 		})
 	}
 }
+
+type fakeOrgRootClient struct {
+	nsx_policy.OrgRootClient
+	orgRoots []model.OrgRoot
+}
+
+func (c *fakeOrgRootClient) Patch(orgRoot model.OrgRoot, _ *bool) error {
+	c.orgRoots = append(c.orgRoots, orgRoot)
+	return nil
+}
+
+func TestSecurityPolicyService_patchInfraInProject(t *testing.T) {
+	Converter := bindings.NewTypeConverter()
+	Converter.SetMode(bindings.REST)
+	orgRootClient := &fakeOrgRootClient{}
+	service := &SecurityPolicyService{Service: common.Service{
+		NSXClient: &nsx.Client{OrgRootClient: orgRootClient},
+		NSXConfig: &config.NSXOperatorConfig{
+			CoeConfig: &config.CoeConfig{Cluster: "k8scl-one:test"},
+			NsxConfig: &config.NsxConfig{NsxProject: "tenant1"},
+		}}}
+	assert.Equal(t, "/orgs/default/projects/tenant1/infra/domains/k8scl-one:test/groups/g1", service.buildGroupPath("g1"))
+
+	infra, err := service.WrapHierarchySecurityPolicy(&model.SecurityPolicy{Id: String("sp1")}, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, service.patchInfra(infra))
+	assert.Equal(t, 1, len(orgRootClient.orgRoots))
+	orgRoot := orgRootClient.orgRoots[0]
+	assert.Equal(t, "OrgRoot", *orgRoot.ResourceType)
+	var ids []string
+	children := orgRoot.Children
+	for _, targetType := range []string{"Org", "Project"} {
+		r, _ := Converter.ConvertToGolang(children[0], model.ChildResourceReferenceBindingType())
+		rc := r.(model.ChildResourceReference)
+		assert.Equal(t, targetType, *rc.TargetType)
+		ids = append(ids, *rc.Id)
+		children = rc.Children
+	}
+	assert.Equal(t, []string{"default", "tenant1"}, ids)
+	i, _ := Converter.ConvertToGolang(children[0], model.ChildInfraBindingType())
+	assert.NotNil(t, i.(model.ChildInfra).Infra)
+}
@@ -0,0 +1,92 @@
+package staticroute
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	String = common.String
+	Int64  = common.Int64
+)
+
+// buildStaticRoute builds the static route of the StaticRoute CR under the VPC or gateway of parentPath.
+func (s *StaticRouteService) buildStaticRoute(obj *v1alpha1.StaticRoute, parentPath string) *model.StaticRoutes {
+	id := fmt.Sprintf("sr_%s", obj.UID)
+	nextHops := make([]model.RouterNexthop, 0, len(obj.Spec.NextHops))
+	for _, nextHop := range obj.Spec.NextHops {
+		nextHops = append(nextHops, model.RouterNexthop{
+			IpAddress:     String(nextHop.IPAddress),
+			AdminDistance: Int64(int64(adminDistance(nextHop))),
+		})
+	}
+	return &model.StaticRoutes{
+		Id:           String(id),
+		DisplayName:  String(fmt.Sprintf("%s-%s", obj.Namespace, obj.Name)),
+		Path:         String(fmt.Sprintf("%s/static-routes/%s", parentPath, id)),
+		ParentPath:   String(parentPath),
+		ResourceType: String(common.ResourceTypeStaticRoute),
+		Network:      String(obj.Spec.Network),
+		NextHops:     nextHops,
+		Tags:         s.buildBasicTags(obj),
+	}
+}
+
+func (s *StaticRouteService) buildBasicTags(obj *v1alpha1.StaticRoute) []model.Tag {
+	return []model.Tag{
+		{Scope: String(common.TagScopeCluster), Tag: String(s.NSXConfig.Cluster)},
+		{Scope: String(common.TagScopeNamespace), Tag: String(obj.Namespace)},
+		{Scope: String(common.TagScopeStaticRouteCRName), Tag: String(obj.Name)},
+		{Scope: String(common.TagScopeStaticRouteCRUID), Tag: String(string(obj.UID))},
+	}
+}
+
+func adminDistance(nextHop v1alpha1.NextHop) int {
+	if nextHop.AdminDistance == 0 {
+		return v1alpha1.DefaultAdminDistance
+	}
+	return nextHop.AdminDistance
+}
+
+// validateNextHops rejects the duplicate next hops, NSX programs one ECMP path per next hop IP.
+func validateNextHops(obj *v1alpha1.StaticRoute) error {
+	ips := map[string]bool{}
+	for _, nextHop := range obj.Spec.NextHops {
+		ip := net.ParseIP(nextHop.IPAddress)
+		if ip == nil {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("invalid next hop %s of StaticRoute %s", nextHop.IPAddress, obj.Name)}
+		}
+		if ips[ip.String()] {
+			return nsxutil.RestrictionError{Desc: fmt.Sprintf("duplicate next hop %s of StaticRoute %s", nextHop.IPAddress, obj.Name)}
+		}
+		ips[ip.String()] = true
+	}
+	return nil
+}
+
+// gatewayOrDefault returns the gateway of the StaticRoute CR, or defaultGateway if it is not set.
+func gatewayOrDefault(obj *v1alpha1.StaticRoute, defaultGateway string) string {
+	if obj.Spec.Gateway != "" {
+		return obj.Spec.Gateway
+	}
+	return defaultGateway
+}
+
+func buildTier1Path(tier1 string) string {
+	return fmt.Sprintf("/infra/tier-1s/%s", tier1)
+}
+
+func buildTier0Path(tier0 string) string {
+	return fmt.Sprintf("/infra/tier-0s/%s", tier0)
+}
+
+func isTier0Path(parentPath string) bool {
+	return strings.HasPrefix(parentPath, "/infra/tier-0s/")
+}
@@ -0,0 +1,49 @@
+package staticroute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestBuildStaticRoute(t *testing.T) {
+	s := &StaticRouteService{Service: common.Service{
+		NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}},
+	}}
+	obj := &v1alpha1.StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "ns1", UID: "uid1"},
+		Spec: v1alpha1.StaticRouteSpec{
+			Network:  "10.10.0.0/16",
+			NextHops: []v1alpha1.NextHop{{IPAddress: "192.168.1.1"}, {IPAddress: "192.168.1.2", AdminDistance: 10}},
+		},
+	}
+
+	staticRoute := s.buildStaticRoute(obj, "/orgs/default/projects/p1/vpcs/vpc1")
+	assert.Equal(t, "sr_uid1", *staticRoute.Id)
+	assert.Equal(t, "ns1-route1", *staticRoute.DisplayName)
+	assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc1/static-routes/sr_uid1", *staticRoute.Path)
+	assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc1", *staticRoute.ParentPath)
+	assert.Equal(t, "10.10.0.0/16", *staticRoute.Network)
+	assert.Len(t, staticRoute.NextHops, 2)
+	assert.Equal(t, "192.168.1.2", *staticRoute.NextHops[1].IpAddress)
+	assert.Equal(t, int64(1), *staticRoute.NextHops[0].AdminDistance)
+	assert.Equal(t, int64(10), *staticRoute.NextHops[1].AdminDistance)
+	assert.Equal(t, []string{"uid1"}, filterTag(staticRoute.Tags, common.TagScopeStaticRouteCRUID))
+	assert.Equal(t, []string{"route1"}, filterTag(staticRoute.Tags, common.TagScopeStaticRouteCRName))
+
+	assert.Equal(t, "/infra/tier-1s/t1", buildTier1Path("t1"))
+}
+
+func TestValidateNextHops(t *testing.T) {
+	obj := &v1alpha1.StaticRoute{Spec: v1alpha1.StaticRouteSpec{NextHops: []v1alpha1.NextHop{{IPAddress: "192.168.1.1"}, {IPAddress: "192.168.1.2"}}}}
+	assert.Nil(t, validateNextHops(obj))
+	obj.Spec.NextHops[1].IPAddress = "192.168.1.1"
+	assert.ErrorContains(t, validateNextHops(obj), "duplicate next hop 192.168.1.1")
+	obj.Spec.NextHops[1].IPAddress = "192.168.1"
+	assert.ErrorContains(t, validateNextHops(obj), "invalid next hop 192.168.1")
+}
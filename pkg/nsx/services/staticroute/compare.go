@@ -0,0 +1,36 @@
+package staticroute
+
+import (
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type StaticRoute model.StaticRoutes
+
+type Comparable = common.Comparable
+
+func (staticRoute *StaticRoute) Key() string {
+	return *staticRoute.Id
+}
+
+func (staticRoute *StaticRoute) Value() data.DataValue {
+	r := &StaticRoute{
+		Id:          staticRoute.Id,
+		DisplayName: staticRoute.DisplayName,
+		Tags:        staticRoute.Tags,
+		Network:     staticRoute.Network,
+		NextHops:    staticRoute.NextHops,
+	}
+	dataValue, _ := ComparableToStaticRoute(r).GetDataValue__()
+	return dataValue
+}
+
+func StaticRouteToComparable(staticRoute *model.StaticRoutes) Comparable {
+	return (*StaticRoute)(staticRoute)
+}
+
+func ComparableToStaticRoute(staticRoute Comparable) *model.StaticRoutes {
+	return (*model.StaticRoutes)(staticRoute.(*StaticRoute))
+}
@@ -0,0 +1,90 @@
+package staticroute
+
+import (
+	"path"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// runtimeStatusDown is the runtime status NSX reports for a realized static route which is not forwarding.
+const runtimeStatusDown = "DOWN"
+
+// GetUnreachableNextHops returns the next hops of the static route of the StaticRoute CR which are not forwarding,
+// or nil if they are all forwarding or NSX does not report their status yet. On the Tier-1 and Tier-0 gateways a next
+// hop is forwarding once the forwarding table of an edge has the route through it, NSX only reports the runtime status
+// of the whole route on the VPCs, so all the next hops are unreachable when it is down.
+func (s *StaticRouteService) GetUnreachableNextHops(uid types.UID) ([]string, error) {
+	staticRoute := s.staticRouteStore.GetByCRUID(string(uid))
+	if staticRoute == nil {
+		return nil, nil
+	}
+	if _, _, _, err := common.ParseVPCPath(*staticRoute.ParentPath); err == nil {
+		return s.getVPCUnreachableNextHops(staticRoute)
+	}
+	return s.getGatewayUnreachableNextHops(staticRoute)
+}
+
+func (s *StaticRouteService) getVPCUnreachableNextHops(staticRoute *model.StaticRoutes) ([]string, error) {
+	result, err := s.listRealizedEntities(staticRoute)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range result.Results {
+		if entity.RuntimeStatus != nil && *entity.RuntimeStatus == runtimeStatusDown {
+			return nextHopIPs(staticRoute), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *StaticRouteService) getGatewayUnreachableNextHops(staticRoute *model.StaticRoutes) ([]string, error) {
+	var result model.RoutingTableListResult
+	var err error
+	if isTier0Path(*staticRoute.ParentPath) {
+		result, err = s.NSXClient.Tier0ForwardingTableClient.List(path.Base(*staticRoute.ParentPath), nil, nil, nil, nil, nil,
+			staticRoute.Network, nil, nil, nil, nil)
+	} else {
+		result, err = s.NSXClient.Tier1ForwardingTableClient.List(path.Base(*staticRoute.ParentPath), nil, nil, nil, nil, nil,
+			staticRoute.Network, nil, nil, nil, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	forwarding := map[string]bool{}
+	reported := false
+	for _, table := range result.Results {
+		if table.Status != nil && *table.Status != model.RoutingTable_STATUS_SUCCESS {
+			continue
+		}
+		reported = true
+		for _, entry := range table.RouteEntries {
+			if entry.NextHop != nil {
+				forwarding[*entry.NextHop] = true
+			}
+		}
+	}
+	if !reported {
+		log.V(1).Info("forwarding table of gateway is not available", "StaticRoute", *staticRoute.Id)
+		return nil, nil
+	}
+	var unreachable []string
+	for _, ip := range nextHopIPs(staticRoute) {
+		if !forwarding[ip] {
+			unreachable = append(unreachable, ip)
+		}
+	}
+	return unreachable, nil
+}
+
+func nextHopIPs(staticRoute *model.StaticRoutes) []string {
+	ips := make([]string, 0, len(staticRoute.NextHops))
+	for _, nextHop := range staticRoute.NextHops {
+		if nextHop.IpAddress != nil {
+			ips = append(ips, *nextHop.IpAddress)
+		}
+	}
+	return ips
+}
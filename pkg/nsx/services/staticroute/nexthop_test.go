@@ -0,0 +1,106 @@
+package staticroute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_0s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_1s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+)
+
+// fakeForwardingTableClient reports the forwarding tables of the edges, nil means no edge reports its table.
+type fakeForwardingTableClient struct {
+	tier_1s.ForwardingTableClient
+	tables []model.RoutingTable
+}
+
+func (c *fakeForwardingTableClient) List(_ string, _ *string, _ *string, _ *string, _ *string, _ *string, _ *string, _ *int64, _ *string, _ *bool, _ *string) (model.RoutingTableListResult, error) {
+	return model.RoutingTableListResult{Results: c.tables}, nil
+}
+
+type fakeTier0ForwardingTableClient struct {
+	tier_0s.ForwardingTableClient
+	tables []model.RoutingTable
+}
+
+func (c *fakeTier0ForwardingTableClient) List(_ string, _ *string, _ *string, _ *string, _ *string, _ *string, _ *string, _ *int64, _ *string, _ *bool, _ *string) (model.RoutingTableListResult, error) {
+	return model.RoutingTableListResult{Results: c.tables}, nil
+}
+
+func TestStaticRouteService_GetUnreachableNextHops(t *testing.T) {
+	s, _, _, realizedClient := newFakeStaticRouteService()
+	forwardingClient := &fakeForwardingTableClient{}
+	s.NSXClient.Tier1ForwardingTableClient = forwardingClient
+	s.NSXConfig.Tier1Gateway = "t1"
+	obj := &v1alpha1.StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "ns1", UID: "uid1"},
+		Spec: v1alpha1.StaticRouteSpec{
+			Network:  "10.10.0.0/16",
+			NextHops: []v1alpha1.NextHop{{IPAddress: "192.168.1.1"}, {IPAddress: "192.168.1.2"}},
+		},
+	}
+
+	unreachable, err := s.GetUnreachableNextHops(obj.UID)
+	require.Nil(t, err)
+	assert.Nil(t, unreachable)
+
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nil)
+	require.Nil(t, err)
+	unreachable, err = s.GetUnreachableNextHops(obj.UID)
+	require.Nil(t, err)
+	assert.Nil(t, unreachable)
+
+	forwardingClient.tables = []model.RoutingTable{
+		{Status: String(model.RoutingTable_STATUS_FAILURE)},
+		{Status: String(model.RoutingTable_STATUS_SUCCESS), RouteEntries: []model.RoutingEntry{
+			{Network: String("10.10.0.0/16"), NextHop: String("192.168.1.2")},
+		}},
+	}
+	unreachable, err = s.GetUnreachableNextHops(obj.UID)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"192.168.1.1"}, unreachable)
+
+	nsxVPC := &model.Vpc{Id: String("vpc1"), Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	require.Nil(t, err)
+	unreachable, err = s.GetUnreachableNextHops(obj.UID)
+	require.Nil(t, err)
+	assert.Nil(t, unreachable)
+
+	realizedClient.runtimeStatuses = map[string]string{"/orgs/default/projects/p1/vpcs/vpc1/static-routes/sr_uid1": runtimeStatusDown}
+	unreachable, err = s.GetUnreachableNextHops(obj.UID)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"192.168.1.1", "192.168.1.2"}, unreachable)
+}
+
+func TestStaticRouteService_GetUnreachableNextHopsOnTier0(t *testing.T) {
+	s, _, _, _ := newFakeStaticRouteService()
+	forwardingClient := &fakeTier0ForwardingTableClient{}
+	s.NSXClient.Tier0ForwardingTableClient = forwardingClient
+	s.NSXConfig.Tier0Gateway = "t0"
+	obj := &v1alpha1.StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "ns1", UID: "uid1"},
+		Spec: v1alpha1.StaticRouteSpec{
+			Scope:    v1alpha1.StaticRouteScopeTier0,
+			Network:  "10.10.0.0/16",
+			NextHops: []v1alpha1.NextHop{{IPAddress: "192.168.1.1"}, {IPAddress: "192.168.1.2"}},
+		},
+	}
+	_, err := s.CreateOrUpdateStaticRoute(context.TODO(), obj, nil)
+	require.Nil(t, err)
+
+	forwardingClient.tables = []model.RoutingTable{
+		{Status: String(model.RoutingTable_STATUS_SUCCESS), RouteEntries: []model.RoutingEntry{
+			{Network: String("10.10.0.0/16"), NextHop: String("192.168.1.1")},
+		}},
+	}
+	unreachable, err := s.GetUnreachableNextHops(obj.UID)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"192.168.1.2"}, unreachable)
+}
@@ -0,0 +1,110 @@
+package staticroute
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// RouteTable is the effective routing table of a gateway, reported per edge node for the Tier-1 gateways.
+type RouteTable struct {
+	Gateway  string       `json:"gateway"`
+	EdgeNode string       `json:"edgeNode,omitempty"`
+	Error    string       `json:"error,omitempty"`
+	Routes   []RouteEntry `json:"routes"`
+}
+
+// RouteEntry is a route of a RouteTable.
+type RouteEntry struct {
+	Network       string `json:"network"`
+	NextHop       string `json:"nextHop,omitempty"`
+	RouteType     string `json:"routeType,omitempty"`
+	AdminDistance int64  `json:"adminDistance,omitempty"`
+}
+
+// GetRouteTables returns the effective routing tables of the gateway of a Namespace, the NSX VPC or the configured
+// Tier-1 gateway if nsxVPC is nil. NSX does not report the forwarding table of the VPCs, so the table of a VPC only
+// has its static routes.
+func (s *StaticRouteService) GetRouteTables(nsxVPC *model.Vpc) ([]RouteTable, error) {
+	switch {
+	case nsxVPC != nil:
+		return s.getVPCRouteTables(*nsxVPC.Path)
+	case s.NSXConfig.Tier1Gateway != "":
+		return s.getTier1RouteTables(buildTier1Path(s.NSXConfig.Tier1Gateway))
+	}
+	return nil, nsxutil.RestrictionError{Desc: "no NSX VPC or Tier-1 gateway found"}
+}
+
+func (s *StaticRouteService) getVPCRouteTables(vpcPath string) ([]RouteTable, error) {
+	org, project, vpc, err := common.ParseVPCPath(vpcPath)
+	if err != nil {
+		return nil, err
+	}
+	table := RouteTable{Gateway: vpcPath, Routes: []RouteEntry{}}
+	var cursor *string
+	for {
+		result, err := s.NSXClient.VPCStaticRouteClient.List(org, project, vpc, cursor, nil, nil, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, staticRoute := range result.Results {
+			for _, nextHop := range staticRoute.NextHops {
+				entry := RouteEntry{Network: *staticRoute.Network, RouteType: "static"}
+				if nextHop.IpAddress != nil {
+					entry.NextHop = *nextHop.IpAddress
+				}
+				if nextHop.AdminDistance != nil {
+					entry.AdminDistance = *nextHop.AdminDistance
+				}
+				table.Routes = append(table.Routes, entry)
+			}
+		}
+		if result.Cursor == nil || *result.Cursor == "" || len(result.Results) == 0 {
+			break
+		}
+		cursor = result.Cursor
+	}
+	return []RouteTable{table}, nil
+}
+
+func (s *StaticRouteService) getTier1RouteTables(tier1Path string) ([]RouteTable, error) {
+	result, err := s.NSXClient.Tier1ForwardingTableClient.List(path.Base(tier1Path), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]RouteTable, 0, len(result.Results))
+	for _, routingTable := range result.Results {
+		table := RouteTable{Gateway: tier1Path, Routes: []RouteEntry{}}
+		if routingTable.EdgeNode != nil {
+			table.EdgeNode = *routingTable.EdgeNode
+		}
+		if routingTable.Status != nil && *routingTable.Status != model.RoutingTable_STATUS_SUCCESS {
+			table.Error = fmt.Sprintf("forwarding table is not available: %s", *routingTable.Status)
+			if routingTable.ErrorMessage != nil {
+				table.Error = fmt.Sprintf("%s, %s", table.Error, *routingTable.ErrorMessage)
+			}
+		}
+		for _, routingEntry := range routingTable.RouteEntries {
+			entry := RouteEntry{}
+			if routingEntry.Network != nil {
+				entry.Network = *routingEntry.Network
+			}
+			if routingEntry.NextHop != nil {
+				entry.NextHop = *routingEntry.NextHop
+			}
+			if routingEntry.RouteType != nil {
+				entry.RouteType = *routingEntry.RouteType
+			}
+			if routingEntry.AdminDistance != nil {
+				entry.AdminDistance = *routingEntry.AdminDistance
+			}
+			table.Routes = append(table.Routes, entry)
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
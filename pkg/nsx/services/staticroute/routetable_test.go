@@ -0,0 +1,61 @@
+package staticroute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func (c *fakeVPCStaticRouteClient) List(_ string, _ string, _ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.StaticRoutesListResult, error) {
+	result := model.StaticRoutesListResult{}
+	for _, route := range c.routes {
+		result.Results = append(result.Results, route)
+	}
+	return result, nil
+}
+
+func TestStaticRouteService_GetRouteTables(t *testing.T) {
+	s, vpcClient, _, _ := newFakeStaticRouteService()
+	forwardingClient := &fakeForwardingTableClient{}
+	s.NSXClient.Tier1ForwardingTableClient = forwardingClient
+
+	_, err := s.GetRouteTables(nil)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+
+	s.NSXConfig.Tier1Gateway = "t1"
+	forwardingClient.tables = []model.RoutingTable{
+		{EdgeNode: String("edge1"), Status: String(model.RoutingTable_STATUS_SUCCESS), RouteEntries: []model.RoutingEntry{
+			{Network: String("10.10.0.0/16"), NextHop: String("192.168.1.1"), RouteType: String("t1s"), AdminDistance: Int64(1)},
+			{Network: String("0.0.0.0/0"), NextHop: String("100.64.0.0"), RouteType: String("t0c")},
+		}},
+		{EdgeNode: String("edge2"), Status: String(model.RoutingTable_STATUS_FAILURE), ErrorMessage: String("edge is down")},
+	}
+	tables, err := s.GetRouteTables(nil)
+	require.Nil(t, err)
+	assert.Equal(t, []RouteTable{
+		{Gateway: "/infra/tier-1s/t1", EdgeNode: "edge1", Routes: []RouteEntry{
+			{Network: "10.10.0.0/16", NextHop: "192.168.1.1", RouteType: "t1s", AdminDistance: 1},
+			{Network: "0.0.0.0/0", NextHop: "100.64.0.0", RouteType: "t0c"},
+		}},
+		{Gateway: "/infra/tier-1s/t1", EdgeNode: "edge2", Error: "forwarding table is not available: FAILURE, edge is down", Routes: []RouteEntry{}},
+	}, tables)
+
+	vpcClient.routes["route1"] = model.StaticRoutes{
+		Network: String("10.20.0.0/16"),
+		NextHops: []model.RouterNexthop{
+			{IpAddress: String("192.168.2.1"), AdminDistance: Int64(1)},
+			{IpAddress: String("192.168.2.2"), AdminDistance: Int64(10)},
+		},
+	}
+	nsxVPC := &model.Vpc{Id: String("vpc1"), Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	tables, err = s.GetRouteTables(nsxVPC)
+	require.Nil(t, err)
+	assert.Equal(t, []RouteTable{{Gateway: "/orgs/default/projects/p1/vpcs/vpc1", Routes: []RouteEntry{
+		{Network: "10.20.0.0/16", NextHop: "192.168.2.1", RouteType: "static", AdminDistance: 1},
+		{Network: "10.20.0.0/16", NextHop: "192.168.2.2", RouteType: "static", AdminDistance: 10},
+	}}}, tables)
+}
@@ -0,0 +1,211 @@
+package staticroute
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	log             = logger.Log
+	MarkedForDelete = true
+)
+
+// StaticRouteService manages the NSX static routes of the StaticRoute CRs, they are programmed on the NSX VPC of the
+// Namespace, or on the configured Tier-1 gateway for the Namespaces without VPCs, unless the CR selects a Tier-1 or
+// Tier-0 gateway with its scope.
+type StaticRouteService struct {
+	common.Service
+	staticRouteStore *StaticRouteStore
+}
+
+// InitializeStaticRoute sync NSX resources
+func InitializeStaticRoute(service common.Service) (*StaticRouteService, error) {
+	wg := sync.WaitGroup{}
+	wgDone := make(chan bool)
+	fatalErrors := make(chan error)
+
+	wg.Add(1)
+
+	staticRouteService := &StaticRouteService{Service: service}
+	staticRouteService.staticRouteStore = &StaticRouteStore{ResourceStore: common.ResourceStore{
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{}),
+		BindingType: model.StaticRoutesBindingType(),
+	}}
+
+	go staticRouteService.InitializeResourceStore(&wg, fatalErrors, common.ResourceTypeStaticRoute, staticRouteService.staticRouteStore)
+
+	go func() {
+		wg.Wait()
+		close(wgDone)
+	}()
+
+	select {
+	case <-wgDone:
+		break
+	case err := <-fatalErrors:
+		close(fatalErrors)
+		return staticRouteService, err
+	}
+
+	return staticRouteService, nil
+}
+
+// CreateOrUpdateStaticRoute creates or updates the NSX static route of the StaticRoute CR on the gateway of its scope.
+// The routes of the VPC scope are programmed on the NSX VPC, or on the Tier-1 gateway if nsxVPC is nil. It returns
+// whether the static route is realized within the realization timeout.
+func (s *StaticRouteService) CreateOrUpdateStaticRoute(ctx context.Context, obj *v1alpha1.StaticRoute, nsxVPC *model.Vpc) (bool, error) {
+	if err := validateNextHops(obj); err != nil {
+		return false, err
+	}
+	parentPath, err := s.getParentPath(obj, nsxVPC)
+	if err != nil {
+		return false, err
+	}
+	staticRoute := s.buildStaticRoute(obj, parentPath)
+
+	changed := true
+	if existing := s.staticRouteStore.GetByCRUID(string(obj.UID)); existing != nil {
+		if *existing.ParentPath != parentPath {
+			// the Namespace is moved to another VPC or gateway
+			if err := s.deleteStaticRoute(existing); err != nil {
+				return false, err
+			}
+		} else {
+			changed = common.CompareResource(StaticRouteToComparable(existing), StaticRouteToComparable(staticRoute))
+		}
+	}
+	if changed {
+		if err := s.patchStaticRoute(staticRoute); err != nil {
+			return false, err
+		}
+		if err := s.staticRouteStore.Operate(staticRoute); err != nil {
+			return false, err
+		}
+		log.Info("successfully created or updated StaticRoute", "StaticRoute", staticRoute)
+	}
+	return s.WaitForRealization(ctx, func() (bool, error) { return s.isRealized(staticRoute) })
+}
+
+// getParentPath returns the path of the gateway the static route of the StaticRoute CR is programmed on.
+func (s *StaticRouteService) getParentPath(obj *v1alpha1.StaticRoute, nsxVPC *model.Vpc) (string, error) {
+	switch obj.Spec.Scope {
+	case v1alpha1.StaticRouteScopeTier1:
+		if gateway := gatewayOrDefault(obj, s.NSXConfig.Tier1Gateway); gateway != "" {
+			return buildTier1Path(gateway), nil
+		}
+		return "", nsxutil.RestrictionError{Desc: fmt.Sprintf("no Tier-1 gateway found for StaticRoute %s", obj.Name)}
+	case v1alpha1.StaticRouteScopeTier0:
+		if gateway := gatewayOrDefault(obj, s.NSXConfig.Tier0Gateway); gateway != "" {
+			return buildTier0Path(gateway), nil
+		}
+		return "", nsxutil.RestrictionError{Desc: fmt.Sprintf("no Tier-0 gateway found for StaticRoute %s", obj.Name)}
+	}
+	switch {
+	case nsxVPC != nil:
+		return *nsxVPC.Path, nil
+	case s.NSXConfig.Tier1Gateway != "":
+		return buildTier1Path(s.NSXConfig.Tier1Gateway), nil
+	}
+	return "", nsxutil.RestrictionError{Desc: fmt.Sprintf("no NSX VPC or Tier-1 gateway found for StaticRoute %s", obj.Name)}
+}
+
+func (s *StaticRouteService) patchStaticRoute(staticRoute *model.StaticRoutes) error {
+	if org, project, vpc, err := common.ParseVPCPath(*staticRoute.ParentPath); err == nil {
+		return s.NSXClient.VPCStaticRouteClient.Patch(org, project, vpc, *staticRoute.Id, *staticRoute)
+	}
+	if isTier0Path(*staticRoute.ParentPath) {
+		return s.NSXClient.Tier0StaticRouteClient.Patch(path.Base(*staticRoute.ParentPath), *staticRoute.Id, *staticRoute)
+	}
+	return s.NSXClient.Tier1StaticRouteClient.Patch(path.Base(*staticRoute.ParentPath), *staticRoute.Id, *staticRoute)
+}
+
+// isRealized returns whether NSX has realized the static route, or an error if NSX fails to realize it.
+func (s *StaticRouteService) isRealized(staticRoute *model.StaticRoutes) (bool, error) {
+	result, err := s.listRealizedEntities(staticRoute)
+	if err != nil {
+		return false, err
+	}
+	realized := len(result.Results) > 0
+	for _, entity := range result.Results {
+		if entity.State != nil && *entity.State == model.GenericPolicyRealizedResource_STATE_ERROR {
+			var messages []string
+			for _, alarm := range entity.Alarms {
+				if alarm.Message != nil {
+					messages = append(messages, *alarm.Message)
+				}
+			}
+			return false, fmt.Errorf("failed to realize StaticRoute %s: %s", *staticRoute.Id, strings.Join(messages, "; "))
+		}
+		if entity.State == nil || *entity.State != model.GenericPolicyRealizedResource_STATE_REALIZED {
+			realized = false
+		}
+	}
+	if !realized {
+		log.V(1).Info("StaticRoute is not realized yet", "StaticRoute", *staticRoute.Id)
+	}
+	return realized, nil
+}
+
+func (s *StaticRouteService) listRealizedEntities(staticRoute *model.StaticRoutes) (model.GenericPolicyRealizedResourceListResult, error) {
+	if org, project, _, err := common.ParseVPCPath(*staticRoute.Path); err == nil {
+		return s.NSXClient.RealizedEntitiesClient.List(org, project, *staticRoute.Path, nil)
+	}
+	return s.NSXClient.InfraRealizedEntitiesClient.List(*staticRoute.Path, nil)
+}
+
+func (s *StaticRouteService) deleteStaticRoute(staticRoute *model.StaticRoutes) error {
+	var err error
+	if org, project, vpc, perr := common.ParseVPCPath(*staticRoute.ParentPath); perr == nil {
+		err = s.NSXClient.VPCStaticRouteClient.Delete(org, project, vpc, *staticRoute.Id)
+	} else if isTier0Path(*staticRoute.ParentPath) {
+		err = s.NSXClient.Tier0StaticRouteClient.Delete(path.Base(*staticRoute.ParentPath), *staticRoute.Id)
+	} else {
+		err = s.NSXClient.Tier1StaticRouteClient.Delete(path.Base(*staticRoute.ParentPath), *staticRoute.Id)
+	}
+	if err != nil {
+		return err
+	}
+	staticRoute.MarkedForDelete = &MarkedForDelete
+	if err := s.staticRouteStore.Operate(staticRoute); err != nil {
+		return err
+	}
+	log.Info("successfully deleted StaticRoute", "StaticRoute", staticRoute)
+	return nil
+}
+
+// DeleteStaticRouteByCRUID deletes the NSX static route of the StaticRoute CR.
+func (s *StaticRouteService) DeleteStaticRouteByCRUID(uid types.UID) error {
+	staticRoute := s.staticRouteStore.GetByCRUID(string(uid))
+	if staticRoute == nil {
+		return nil
+	}
+	return s.deleteStaticRoute(staticRoute)
+}
+
+// ListStaticRouteCRUID returns the UIDs of the StaticRoute CRs which have NSX static routes.
+func (s *StaticRouteService) ListStaticRouteCRUID() sets.String {
+	return sets.NewString(s.staticRouteStore.ListKeys()...)
+}
+
+// ListNSXStaticRouteCRUID refreshes the store with the NSX static routes tagged with the cluster, so that the static
+// routes missing from the store, e.g. the ones created by another NSX Operator instance, can be collected too, and
+// returns the UIDs of the StaticRoute CRs which have NSX static routes.
+func (s *StaticRouteService) ListNSXStaticRouteCRUID() (sets.String, error) {
+	if _, err := s.SearchResource(common.ResourceTypeStaticRoute, s.staticRouteStore); err != nil {
+		return nil, err
+	}
+	return s.ListStaticRouteCRUID(), nil
+}
@@ -0,0 +1,315 @@
+package staticroute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	infra_realized_state "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/realized_state"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_0s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/tier_1s"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra/realized_state"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+type fakeVPCStaticRouteClient struct {
+	vpcs.StaticRoutesClient
+	routes  map[string]model.StaticRoutes
+	patched int
+}
+
+func (c *fakeVPCStaticRouteClient) Patch(_ string, _ string, _ string, id string, route model.StaticRoutes) error {
+	c.patched++
+	c.routes[id] = route
+	return nil
+}
+
+func (c *fakeVPCStaticRouteClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.routes, id)
+	return nil
+}
+
+type fakeTier1StaticRouteClient struct {
+	tier_1s.StaticRoutesClient
+	routes map[string]model.StaticRoutes
+}
+
+func (c *fakeTier1StaticRouteClient) Patch(_ string, id string, route model.StaticRoutes) error {
+	c.routes[id] = route
+	return nil
+}
+
+func (c *fakeTier1StaticRouteClient) Delete(_ string, id string) error {
+	delete(c.routes, id)
+	return nil
+}
+
+// fakeTier0StaticRouteClient keeps the static routes by Tier-0 gateway.
+type fakeTier0StaticRouteClient struct {
+	tier_0s.StaticRoutesClient
+	routes map[string]map[string]model.StaticRoutes
+}
+
+func (c *fakeTier0StaticRouteClient) Patch(tier0 string, id string, route model.StaticRoutes) error {
+	if c.routes[tier0] == nil {
+		c.routes[tier0] = map[string]model.StaticRoutes{}
+	}
+	c.routes[tier0][id] = route
+	return nil
+}
+
+func (c *fakeTier0StaticRouteClient) Delete(tier0 string, id string) error {
+	delete(c.routes[tier0], id)
+	return nil
+}
+
+// fakeRealizedEntitiesClient reports the realized state and the alarms of the static routes by path.
+type fakeRealizedEntitiesClient struct {
+	realized_state.RealizedEntitiesClient
+	states          map[string]string
+	alarms          map[string]string
+	runtimeStatuses map[string]string
+}
+
+func (c *fakeRealizedEntitiesClient) List(_ string, _ string, path string, _ *string) (model.GenericPolicyRealizedResourceListResult, error) {
+	return c.list(path), nil
+}
+
+func (c *fakeRealizedEntitiesClient) list(path string) model.GenericPolicyRealizedResourceListResult {
+	if alarm, ok := c.alarms[path]; ok {
+		return model.GenericPolicyRealizedResourceListResult{Results: []model.GenericPolicyRealizedResource{{
+			State:  String(model.GenericPolicyRealizedResource_STATE_ERROR),
+			Alarms: []model.PolicyAlarmResource{{Message: String(alarm)}},
+		}}}
+	}
+	state, ok := c.states[path]
+	if !ok {
+		state = model.GenericPolicyRealizedResource_STATE_UNREALIZED
+	}
+	entity := model.GenericPolicyRealizedResource{State: String(state)}
+	if status, ok := c.runtimeStatuses[path]; ok {
+		entity.RuntimeStatus = String(status)
+	}
+	return model.GenericPolicyRealizedResourceListResult{Results: []model.GenericPolicyRealizedResource{entity}}
+}
+
+type fakeInfraRealizedEntitiesClient struct {
+	infra_realized_state.RealizedEntitiesClient
+	*fakeRealizedEntitiesClient
+}
+
+func (c *fakeInfraRealizedEntitiesClient) List(path string, _ *string) (model.GenericPolicyRealizedResourceListResult, error) {
+	return c.list(path), nil
+}
+
+// fakeQueryClient finds the NSX static routes tagged with the cluster.
+type fakeQueryClient struct {
+	routes []model.StaticRoutes
+}
+
+func (c *fakeQueryClient) List(_ string, _ *string, _ *string, _ *int64, _ *bool, _ *string) (model.SearchResponse, error) {
+	var results []*data.StructValue
+	for _, route := range c.routes {
+		dataValue, _ := common.NewConverter().ConvertToVapi(route, model.StaticRoutesBindingType())
+		results = append(results, dataValue.(*data.StructValue))
+	}
+	count := int64(len(results))
+	return model.SearchResponse{Results: results, ResultCount: &count}, nil
+}
+
+func newFakeStaticRouteService() (*StaticRouteService, *fakeVPCStaticRouteClient, *fakeTier1StaticRouteClient, *fakeRealizedEntitiesClient) {
+	vpcClient := &fakeVPCStaticRouteClient{routes: map[string]model.StaticRoutes{}}
+	tier1Client := &fakeTier1StaticRouteClient{routes: map[string]model.StaticRoutes{}}
+	realizedClient := &fakeRealizedEntitiesClient{states: map[string]string{}, alarms: map[string]string{}}
+	return &StaticRouteService{
+		Service: common.Service{
+			NSXClient: &nsx.Client{
+				VPCStaticRouteClient:        vpcClient,
+				Tier1StaticRouteClient:      tier1Client,
+				Tier0StaticRouteClient:      &fakeTier0StaticRouteClient{routes: map[string]map[string]model.StaticRoutes{}},
+				RealizedEntitiesClient:      realizedClient,
+				InfraRealizedEntitiesClient: &fakeInfraRealizedEntitiesClient{fakeRealizedEntitiesClient: realizedClient},
+			},
+			NSXConfig: &config.NSXOperatorConfig{
+				CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+				NsxConfig: &config.NsxConfig{},
+			},
+		},
+		staticRouteStore: &StaticRouteStore{ResourceStore: common.ResourceStore{
+			Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{}),
+			BindingType: model.StaticRoutesBindingType(),
+		}},
+	}, vpcClient, tier1Client, realizedClient
+}
+
+func TestStaticRouteService_CreateOrUpdateAndDeleteStaticRoute(t *testing.T) {
+	s, vpcClient, _, realizedClient := newFakeStaticRouteService()
+	nsxVPC := &model.Vpc{Id: String("vpc1"), Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	obj := &v1alpha1.StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "ns1", UID: "uid1"},
+		Spec: v1alpha1.StaticRouteSpec{
+			Network:  "10.10.0.0/16",
+			NextHops: []v1alpha1.NextHop{{IPAddress: "192.168.1.1"}},
+		},
+	}
+	vpcRoutePath := "/orgs/default/projects/p1/vpcs/vpc1/static-routes/sr_uid1"
+
+	realized, err := s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	require.Nil(t, err)
+	assert.False(t, realized)
+	assert.Contains(t, vpcClient.routes, "sr_uid1")
+	assert.Equal(t, "sr_uid1", *s.staticRouteStore.GetByCRUID("uid1").Id)
+
+	// unchanged route is not patched again
+	realizedClient.states[vpcRoutePath] = model.GenericPolicyRealizedResource_STATE_REALIZED
+	realized, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	require.Nil(t, err)
+	assert.True(t, realized)
+	assert.Equal(t, 1, vpcClient.patched)
+
+	obj.Spec.NextHops = append(obj.Spec.NextHops, v1alpha1.NextHop{IPAddress: "192.168.1.2"})
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	require.Nil(t, err)
+	assert.Equal(t, 2, vpcClient.patched)
+	assert.Len(t, vpcClient.routes["sr_uid1"].NextHops, 2)
+
+	realizedClient.alarms[vpcRoutePath] = "invalid next hop"
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	assert.ErrorContains(t, err, "invalid next hop")
+	delete(realizedClient.alarms, vpcRoutePath)
+
+	assert.Equal(t, []string{"uid1"}, s.ListStaticRouteCRUID().List())
+	require.Nil(t, s.DeleteStaticRouteByCRUID(obj.UID))
+	assert.Empty(t, vpcClient.routes)
+	assert.Empty(t, s.ListStaticRouteCRUID())
+	assert.Nil(t, s.DeleteStaticRouteByCRUID(obj.UID))
+}
+
+func TestStaticRouteService_CreateOrUpdateStaticRouteOnTier1(t *testing.T) {
+	s, vpcClient, tier1Client, realizedClient := newFakeStaticRouteService()
+	obj := &v1alpha1.StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "ns1", UID: "uid1"},
+		Spec: v1alpha1.StaticRouteSpec{
+			Network:  "10.10.0.0/16",
+			NextHops: []v1alpha1.NextHop{{IPAddress: "192.168.1.1"}},
+		},
+	}
+
+	_, err := s.CreateOrUpdateStaticRoute(context.TODO(), obj, nil)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+
+	s.NSXConfig.Tier1Gateway = "t1"
+	realizedClient.states["/infra/tier-1s/t1/static-routes/sr_uid1"] = model.GenericPolicyRealizedResource_STATE_REALIZED
+	realized, err := s.CreateOrUpdateStaticRoute(context.TODO(), obj, nil)
+	require.Nil(t, err)
+	assert.True(t, realized)
+	assert.Contains(t, tier1Client.routes, "sr_uid1")
+
+	// the route moves to the VPC once the Namespace has one
+	nsxVPC := &model.Vpc{Id: String("vpc1"), Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	require.Nil(t, err)
+	assert.Empty(t, tier1Client.routes)
+	assert.Contains(t, vpcClient.routes, "sr_uid1")
+
+	require.Nil(t, s.DeleteStaticRouteByCRUID(obj.UID))
+	assert.Empty(t, vpcClient.routes)
+}
+
+func TestStaticRouteService_CreateOrUpdateStaticRouteWithScope(t *testing.T) {
+	s, vpcClient, tier1Client, _ := newFakeStaticRouteService()
+	tier0Client := s.NSXClient.Tier0StaticRouteClient.(*fakeTier0StaticRouteClient)
+	nsxVPC := &model.Vpc{Id: String("vpc1"), Path: String("/orgs/default/projects/p1/vpcs/vpc1")}
+	obj := &v1alpha1.StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "ns1", UID: "uid1"},
+		Spec: v1alpha1.StaticRouteSpec{
+			Scope:    v1alpha1.StaticRouteScopeTier0,
+			Network:  "10.10.0.0/16",
+			NextHops: []v1alpha1.NextHop{{IPAddress: "192.168.1.1"}},
+		},
+	}
+
+	_, err := s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+	assert.Empty(t, vpcClient.routes)
+
+	// the route is programmed on the configured Tier-0 gateway regardless of the VPC
+	s.NSXConfig.Tier0Gateway = "t0"
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	require.Nil(t, err)
+	assert.Contains(t, tier0Client.routes["t0"], "sr_uid1")
+	assert.Equal(t, "/infra/tier-0s/t0/static-routes/sr_uid1", *s.staticRouteStore.GetByCRUID("uid1").Path)
+	assert.Empty(t, vpcClient.routes)
+
+	obj.Spec.Gateway = "t0-other"
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	require.Nil(t, err)
+	assert.Empty(t, tier0Client.routes["t0"])
+	assert.Contains(t, tier0Client.routes["t0-other"], "sr_uid1")
+
+	obj.Spec.Scope = v1alpha1.StaticRouteScopeTier1
+	obj.Spec.Gateway = ""
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+
+	obj.Spec.Gateway = "t1"
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	require.Nil(t, err)
+	assert.Empty(t, tier0Client.routes["t0-other"])
+	assert.Contains(t, tier1Client.routes, "sr_uid1")
+
+	obj.Spec.Scope = v1alpha1.StaticRouteScopeVPC
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	require.Nil(t, err)
+	assert.Empty(t, tier1Client.routes)
+	assert.Contains(t, vpcClient.routes, "sr_uid1")
+
+	obj.Spec.Scope = v1alpha1.StaticRouteScopeTier0
+	_, err = s.CreateOrUpdateStaticRoute(context.TODO(), obj, nsxVPC)
+	require.Nil(t, err)
+	require.Nil(t, s.DeleteStaticRouteByCRUID(obj.UID))
+	assert.Empty(t, tier0Client.routes["t1"])
+	assert.Empty(t, vpcClient.routes)
+}
+
+func TestStaticRouteService_ListNSXStaticRouteCRUID(t *testing.T) {
+	s, _, tier1Client, _ := newFakeStaticRouteService()
+	s.NSXConfig.Tier1Gateway = "t1"
+	obj := &v1alpha1.StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "ns1", UID: "uid1"},
+		Spec: v1alpha1.StaticRouteSpec{
+			Network:  "10.10.0.0/16",
+			NextHops: []v1alpha1.NextHop{{IPAddress: "192.168.1.1"}},
+		},
+	}
+	_, err := s.CreateOrUpdateStaticRoute(context.TODO(), obj, nil)
+	require.Nil(t, err)
+
+	// the static routes missing from the store are found in NSX
+	leaked := model.StaticRoutes{
+		Id:         String("sr_uid2"),
+		Path:       String("/infra/tier-1s/t1/static-routes/sr_uid2"),
+		ParentPath: String("/infra/tier-1s/t1"),
+		Tags:       []model.Tag{{Scope: String(common.TagScopeStaticRouteCRUID), Tag: String("uid2")}},
+	}
+	tier1Client.routes[*leaked.Id] = leaked
+	s.NSXClient.QueryClient = &fakeQueryClient{routes: []model.StaticRoutes{leaked}}
+	s.NSXClient.NsxConfig = s.NSXConfig
+	uids, err := s.ListNSXStaticRouteCRUID()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"uid1", "uid2"}, uids.List())
+
+	require.Nil(t, s.DeleteStaticRouteByCRUID("uid2"))
+	assert.NotContains(t, tier1Client.routes, "sr_uid2")
+}
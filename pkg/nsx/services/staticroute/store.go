@@ -0,0 +1,67 @@
+package staticroute
+
+import (
+	"errors"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// keyFunc is used to get the key of a static route, which is the UID of the StaticRoute CR it is created for.
+func keyFunc(obj interface{}) (string, error) {
+	switch v := obj.(type) {
+	case model.StaticRoutes:
+		uids := filterTag(v.Tags, common.TagScopeStaticRouteCRUID)
+		if len(uids) == 0 {
+			return *v.Id, nil
+		}
+		return uids[0], nil
+	default:
+		return "", errors.New("keyFunc doesn't support unknown type")
+	}
+}
+
+func filterTag(tags []model.Tag, tagScope string) []string {
+	res := make([]string, 0, 5)
+	for _, tag := range tags {
+		if *tag.Scope == tagScope {
+			res = append(res, *tag.Tag)
+		}
+	}
+	return res
+}
+
+// StaticRouteStore is a store for NSX static routes
+type StaticRouteStore struct {
+	common.ResourceStore
+}
+
+func (staticRouteStore *StaticRouteStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	staticRoute := i.(*model.StaticRoutes)
+	if staticRoute.MarkedForDelete != nil && *staticRoute.MarkedForDelete {
+		if err := staticRouteStore.Delete(*staticRoute); err != nil {
+			return err
+		}
+		log.V(1).Info("delete StaticRoute from store", "StaticRoute", staticRoute)
+	} else {
+		if err := staticRouteStore.Add(*staticRoute); err != nil {
+			return err
+		}
+		log.V(1).Info("add StaticRoute to store", "StaticRoute", staticRoute)
+	}
+	return nil
+}
+
+// GetByCRUID returns the static route of the StaticRoute CR, or nil if it is not created yet.
+func (staticRouteStore *StaticRouteStore) GetByCRUID(uid string) *model.StaticRoutes {
+	obj := staticRouteStore.GetByKey(uid)
+	if obj == nil {
+		return nil
+	}
+	staticRoute := obj.(model.StaticRoutes)
+	return &staticRoute
+}
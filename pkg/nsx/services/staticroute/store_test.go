@@ -0,0 +1,32 @@
+package staticroute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestStaticRouteStore_Operate(t *testing.T) {
+	store := &StaticRouteStore{ResourceStore: common.ResourceStore{
+		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{}),
+		BindingType: model.StaticRoutesBindingType(),
+	}}
+	staticRoute := &model.StaticRoutes{
+		Id:   String("sr_uid1"),
+		Tags: []model.Tag{{Scope: String(common.TagScopeStaticRouteCRUID), Tag: String("uid1")}},
+	}
+	assert.Nil(t, store.Operate(staticRoute))
+	assert.Equal(t, staticRoute, store.GetByCRUID("uid1"))
+	assert.Nil(t, store.GetByCRUID("sr_uid1"))
+
+	staticRoute.MarkedForDelete = &MarkedForDelete
+	assert.Nil(t, store.Operate(staticRoute))
+	assert.Nil(t, store.GetByCRUID("uid1"))
+
+	_, err := keyFunc(model.Vpc{})
+	assert.NotNil(t, err)
+}
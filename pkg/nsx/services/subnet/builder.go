@@ -0,0 +1,123 @@
+package subnet
+
+import (
+	"fmt"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	String = common.String
+	Int64  = common.Int64
+	Bool   = common.Bool
+)
+
+func buildSubnetPath(vpcPath, id string) string {
+	return fmt.Sprintf("%s/subnets/%s", vpcPath, id)
+}
+
+// ipv4SubnetSize falls back to the default Subnet size of the config if the CR doesn't specify one.
+func (s *SubnetService) ipv4SubnetSize(size int) *int64 {
+	if size == 0 {
+		size = s.NSXConfig.DefaultSubnetSize()
+	}
+	return Int64(int64(size))
+}
+
+func accessMode(mode v1alpha1.AccessMode) *string {
+	if mode == "public" {
+		return String(model.VpcSubnet_ACCESS_MODE_PUBLIC)
+	}
+	return String(model.VpcSubnet_ACCESS_MODE_PRIVATE)
+}
+
+// buildDHCPConfig builds the DHCP config and the advanced config of the Subnet. The DHCP server of the Subnet is only
+// enabled in server mode, and the Subnet ports get static IPs in static mode.
+func buildDHCPConfig(cfg v1alpha1.DHCPConfig, advanced v1alpha1.AdvancedConfig) (*model.DhcpConfig, *model.SubnetAdvancedConfig, error) {
+	dhcpConfig := &model.DhcpConfig{EnableDhcp: Bool(false)}
+	staticIPAllocation := advanced.StaticIPAllocation.Enable
+	switch mode := v1alpha1.DefaultDHCPMode(cfg); mode {
+	case v1alpha1.DHCPModeServer:
+		dhcpConfig.EnableDhcp = Bool(true)
+		dhcpConfig.DhcpV4PoolSize = Int64(int64(cfg.DHCPV4PoolSize))
+		dhcpConfig.DhcpV6PoolSize = Int64(int64(cfg.DHCPV6PoolSize))
+		if len(cfg.DNSClientConfig.DNSServersIPs) > 0 {
+			dhcpConfig.DnsClientConfig = &model.DnsClientConfig{DnsServerIps: cfg.DNSClientConfig.DNSServersIPs}
+		}
+	case v1alpha1.DHCPModeRelay:
+		if cfg.DHCPRelayConfigPath == "" {
+			return nil, nil, nsxutil.RestrictionError{Desc: "dhcpRelayConfigPath is required in DHCP relay mode"}
+		}
+		dhcpConfig.DhcpRelayConfigPath = String(cfg.DHCPRelayConfigPath)
+	case v1alpha1.DHCPModeStatic:
+		staticIPAllocation = true
+	default:
+		return nil, nil, nsxutil.RestrictionError{Desc: fmt.Sprintf("unsupported DHCP mode %q", mode)}
+	}
+	advancedConfig := &model.SubnetAdvancedConfig{StaticIpAllocation: &model.StaticIpAllocation{Enable: Bool(staticIPAllocation)}}
+	return dhcpConfig, advancedConfig, nil
+}
+
+func (s *SubnetService) buildSubnet(obj *v1alpha1.Subnet, vpcPath string) (*model.VpcSubnet, error) {
+	dhcpConfig, advancedConfig, err := buildDHCPConfig(obj.Spec.DHCPConfig, obj.Spec.AdvancedConfig)
+	if err != nil {
+		return nil, err
+	}
+	id := fmt.Sprintf("subnet_%s", obj.UID)
+	return &model.VpcSubnet{
+		Id:             String(id),
+		DisplayName:    String(fmt.Sprintf("%s-%s", obj.Namespace, obj.Name)),
+		Path:           String(buildSubnetPath(vpcPath, id)),
+		AccessMode:     accessMode(obj.Spec.AccessMode),
+		Ipv4SubnetSize: s.ipv4SubnetSize(obj.Spec.IPv4SubnetSize),
+		IpAddresses:    obj.Spec.IPAddresses,
+		DhcpConfig:     dhcpConfig,
+		AdvancedConfig: advancedConfig,
+		Tags:           s.AppendLabelTags(s.buildBasicTags(obj.Namespace, common.TagScopeSubnetCRName, obj.Name, common.TagScopeSubnetCRUID, obj.UID), obj.Namespace, nil),
+	}, nil
+}
+
+// buildSubnetSetSubnet builds the index-th Subnet of the SubnetSet.
+func (s *SubnetService) buildSubnetSetSubnet(obj *v1alpha1.SubnetSet, vpcPath string, index int) (*model.VpcSubnet, error) {
+	dhcpConfig, advancedConfig, err := buildDHCPConfig(obj.Spec.DHCPConfig, obj.Spec.AdvancedConfig)
+	if err != nil {
+		return nil, err
+	}
+	id := fmt.Sprintf("subnetset_%s_%d", obj.UID, index)
+	return &model.VpcSubnet{
+		Id:             String(id),
+		DisplayName:    String(fmt.Sprintf("%s-%s-%d", obj.Namespace, obj.Name, index)),
+		Path:           String(buildSubnetPath(vpcPath, id)),
+		AccessMode:     accessMode(obj.Spec.AccessMode),
+		Ipv4SubnetSize: s.ipv4SubnetSize(obj.Spec.IPv4SubnetSize),
+		DhcpConfig:     dhcpConfig,
+		AdvancedConfig: advancedConfig,
+		Tags:           s.AppendLabelTags(s.buildBasicTags(obj.Namespace, common.TagScopeSubnetSetCRName, obj.Name, common.TagScopeSubnetSetCRUID, obj.UID), obj.Namespace, nil),
+	}, nil
+}
+
+func (s *SubnetService) buildBasicTags(namespace, nameScope, name, uidScope string, uid types.UID) []model.Tag {
+	return []model.Tag{
+		{
+			Scope: String(common.TagScopeCluster),
+			Tag:   String(s.NSXConfig.Cluster),
+		},
+		{
+			Scope: String(common.TagScopeNamespace),
+			Tag:   String(namespace),
+		},
+		{
+			Scope: String(nameScope),
+			Tag:   String(name),
+		},
+		{
+			Scope: String(uidScope),
+			Tag:   String(string(uid)),
+		},
+	}
+}
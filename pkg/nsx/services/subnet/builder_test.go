@@ -0,0 +1,123 @@
+package subnet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+const vpcPath = "/orgs/default/projects/p1/vpcs/vpc1"
+
+func TestBuildSubnet(t *testing.T) {
+	s := &SubnetService{Service: common.Service{
+		NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}},
+	}}
+	obj := &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1", UID: "uid1"}}
+
+	nsxSubnet, err := s.buildSubnet(obj, vpcPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "subnet_uid1", *nsxSubnet.Id)
+	assert.Equal(t, "ns1-subnet1", *nsxSubnet.DisplayName)
+	assert.Equal(t, vpcPath+"/subnets/subnet_uid1", *nsxSubnet.Path)
+	assert.Equal(t, model.VpcSubnet_ACCESS_MODE_PRIVATE, *nsxSubnet.AccessMode)
+	// default size comes from the config
+	assert.Equal(t, int64(64), *nsxSubnet.Ipv4SubnetSize)
+	assert.Equal(t, []string{"uid1"}, filterTag(nsxSubnet.Tags, common.TagScopeSubnetCRUID))
+
+	s.NSXConfig.DefaultSubnetPrefixLength = 24
+	nsxSubnet, _ = s.buildSubnet(obj, vpcPath)
+	assert.Equal(t, int64(256), *nsxSubnet.Ipv4SubnetSize)
+	obj.Spec.IPv4SubnetSize = 32
+	obj.Spec.AccessMode = "public"
+	nsxSubnet, _ = s.buildSubnet(obj, vpcPath)
+	assert.Equal(t, int64(32), *nsxSubnet.Ipv4SubnetSize)
+	assert.Equal(t, model.VpcSubnet_ACCESS_MODE_PUBLIC, *nsxSubnet.AccessMode)
+}
+
+func TestBuildSubnetSetSubnet(t *testing.T) {
+	s := &SubnetService{Service: common.Service{
+		NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one", DefaultSubnetPrefixLength: 28}},
+	}}
+	obj := &v1alpha1.SubnetSet{ObjectMeta: metav1.ObjectMeta{Name: "set1", Namespace: "ns1", UID: "uid2"}}
+
+	nsxSubnet, err := s.buildSubnetSetSubnet(obj, vpcPath, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "subnetset_uid2_1", *nsxSubnet.Id)
+	assert.Equal(t, "ns1-set1-1", *nsxSubnet.DisplayName)
+	assert.Equal(t, int64(16), *nsxSubnet.Ipv4SubnetSize)
+	assert.Equal(t, []string{"uid2"}, filterTag(nsxSubnet.Tags, common.TagScopeSubnetSetCRUID))
+	assert.Equal(t, []string{"set1"}, filterTag(nsxSubnet.Tags, common.TagScopeSubnetSetCRName))
+
+	// the Namespace labels configured to be propagated are tagged
+	s.Client = fake.NewClientBuilder().WithObjects(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"team": "net"}}}).Build()
+	s.NSXConfig.LabelTagKeys = []string{"team"}
+	nsxSubnet, err = s.buildSubnetSetSubnet(obj, vpcPath, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"net"}, filterTag(nsxSubnet.Tags, "team"))
+}
+
+func TestBuildDHCPConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        v1alpha1.DHCPConfig
+		advanced   v1alpha1.AdvancedConfig
+		wantDHCP   *model.DhcpConfig
+		wantStatic bool
+		wantErr    bool
+	}{
+		{
+			name:       "static by default",
+			wantDHCP:   &model.DhcpConfig{EnableDhcp: Bool(false)},
+			wantStatic: true,
+		},
+		{
+			name: "server",
+			cfg:  v1alpha1.DHCPConfig{EnableDHCP: true, DHCPV4PoolSize: 80, DNSClientConfig: v1alpha1.DNSClientConfig{DNSServersIPs: []string{"1.1.1.1"}}},
+			wantDHCP: &model.DhcpConfig{EnableDhcp: Bool(true), DhcpV4PoolSize: Int64(80), DhcpV6PoolSize: Int64(0),
+				DnsClientConfig: &model.DnsClientConfig{DnsServerIps: []string{"1.1.1.1"}}},
+		},
+		{
+			name:       "server with static IP allocation",
+			cfg:        v1alpha1.DHCPConfig{Mode: v1alpha1.DHCPModeServer, DHCPV4PoolSize: 50},
+			advanced:   v1alpha1.AdvancedConfig{StaticIPAllocation: v1alpha1.StaticIPAllocation{Enable: true}},
+			wantDHCP:   &model.DhcpConfig{EnableDhcp: Bool(true), DhcpV4PoolSize: Int64(50), DhcpV6PoolSize: Int64(0)},
+			wantStatic: true,
+		},
+		{
+			name:     "relay",
+			cfg:      v1alpha1.DHCPConfig{DHCPRelayConfigPath: "/infra/dhcp-relay-configs/r1"},
+			wantDHCP: &model.DhcpConfig{EnableDhcp: Bool(false), DhcpRelayConfigPath: String("/infra/dhcp-relay-configs/r1")},
+		},
+		{
+			name:    "relay without path",
+			cfg:     v1alpha1.DHCPConfig{Mode: v1alpha1.DHCPModeRelay},
+			wantErr: true,
+		},
+		{
+			name:       "static overrides enableDHCP",
+			cfg:        v1alpha1.DHCPConfig{Mode: v1alpha1.DHCPModeStatic, EnableDHCP: true},
+			wantDHCP:   &model.DhcpConfig{EnableDhcp: Bool(false)},
+			wantStatic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dhcpConfig, advancedConfig, err := buildDHCPConfig(tt.cfg, tt.advanced)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantDHCP, dhcpConfig)
+			assert.Equal(t, tt.wantStatic, *advancedConfig.StaticIpAllocation.Enable)
+		})
+	}
+}
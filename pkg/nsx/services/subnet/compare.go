@@ -0,0 +1,39 @@
+package subnet
+
+import (
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type Subnet model.VpcSubnet
+
+type Comparable = common.Comparable
+
+func (subnet *Subnet) Key() string {
+	return *subnet.Id
+}
+
+// Value leaves out IpAddresses because NSX allocates them when they are not specified.
+func (subnet *Subnet) Value() data.DataValue {
+	s := &Subnet{
+		Id:             subnet.Id,
+		DisplayName:    subnet.DisplayName,
+		Tags:           subnet.Tags,
+		AccessMode:     subnet.AccessMode,
+		Ipv4SubnetSize: subnet.Ipv4SubnetSize,
+		DhcpConfig:     subnet.DhcpConfig,
+		AdvancedConfig: subnet.AdvancedConfig,
+	}
+	dataValue, _ := ComparableToSubnet(s).GetDataValue__()
+	return dataValue
+}
+
+func SubnetToComparable(subnet *model.VpcSubnet) Comparable {
+	return (*Subnet)(subnet)
+}
+
+func ComparableToSubnet(subnet Comparable) *model.VpcSubnet {
+	return (*model.VpcSubnet)(subnet.(*Subnet))
+}
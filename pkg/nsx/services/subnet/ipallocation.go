@@ -0,0 +1,116 @@
+package subnet
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipam"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+// ReserveIP allocates the IP from the IP pool of the NSX Subnet with the allocation ID for the SubnetPort CR of
+// ownerUID, so that it is not assigned to other ports. The allocation is tagged with ownerUID, so that it is released
+// with the CR even if no port is created. The IP out of the Subnet CIDRs or already allocated with another ID is
+// rejected.
+func (s *SubnetService) ReserveIP(nsxSubnet *model.VpcSubnet, allocationID, ip string, ownerUID types.UID) error {
+	if !subnetContainsIP(nsxSubnet, ip) {
+		return nsxutil.RestrictionError{Desc: fmt.Sprintf("IP address %s is not in Subnet %s %v", ip, *nsxSubnet.Id, nsxSubnet.IpAddresses)}
+	}
+	tags := []model.Tag{
+		{Scope: String(common.TagScopeCluster), Tag: String(s.NSXConfig.Cluster)},
+		{Scope: String(common.TagScopeSubnetPortCRUID), Tag: String(string(ownerUID))},
+	}
+	allocations, err := s.allocator.AllocateIP(ipam.FamilyOf(ip), *nsxSubnet.Path, &ipam.Request{
+		ID:          allocationID,
+		DisplayName: allocationID,
+		Tags:        tags,
+		IPs:         []string{ip},
+	})
+	if err != nil {
+		return err
+	}
+	for _, allocation := range allocations {
+		if err := s.reservedIPStore.Operate(&model.IpAddressAllocation{
+			Id:           String(allocation.ID),
+			Path:         String(allocation.Path),
+			AllocationIp: String(allocation.IP),
+			Tags:         tags,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseIP deletes the IP allocation of the ID from the IP pools of the NSX Subnet.
+func (s *SubnetService) ReleaseIP(nsxSubnet *model.VpcSubnet, allocationID string) error {
+	if err := s.allocator.ReleaseIP(*nsxSubnet.Path, allocationID); err != nil {
+		return err
+	}
+	for _, obj := range s.reservedIPStore.List() {
+		allocation := obj.(model.IpAddressAllocation)
+		if *allocation.Id == allocationID && reservedIPSubnetPath(&allocation) == *nsxSubnet.Path {
+			allocation.MarkedForDelete = Bool(true)
+			if err := s.reservedIPStore.Operate(&allocation); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetReservedIPs returns the IP allocations reserving the IPs of the SubnetPort CR of ownerUID.
+func (s *SubnetService) GetReservedIPs(ownerUID types.UID) []model.IpAddressAllocation {
+	return s.reservedIPStore.GetByIndex(common.TagScopeSubnetPortCRUID, string(ownerUID))
+}
+
+// ReleaseReservedIPs releases the IPs reserved for the SubnetPort CR of ownerUID from all the NSX Subnets.
+func (s *SubnetService) ReleaseReservedIPs(ownerUID types.UID) error {
+	for _, allocation := range s.GetReservedIPs(ownerUID) {
+		if err := s.allocator.ReleaseIP(reservedIPSubnetPath(&allocation), *allocation.Id); err != nil {
+			return err
+		}
+		allocation.MarkedForDelete = Bool(true)
+		if err := s.reservedIPStore.Operate(&allocation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListReservedIPOwnerUID returns the UIDs of the SubnetPort CRs with IPs reserved, for the garbage collector.
+func (s *SubnetService) ListReservedIPOwnerUID() sets.String {
+	return s.reservedIPStore.ListIndexFuncValues(common.TagScopeSubnetPortCRUID)
+}
+
+// ReservedIPInSubnet returns whether the IP allocation reserves the IP in the NSX Subnet.
+func ReservedIPInSubnet(allocation *model.IpAddressAllocation, nsxSubnet *model.VpcSubnet, ip string) bool {
+	return reservedIPSubnetPath(allocation) == *nsxSubnet.Path && allocation.AllocationIp != nil && *allocation.AllocationIp == ip
+}
+
+// reservedIPSubnetPath returns the path of the NSX Subnet of the IP allocation, which is in an IP pool of the Subnet.
+func reservedIPSubnetPath(allocation *model.IpAddressAllocation) string {
+	if i := strings.Index(*allocation.Path, "/ip-pools/"); i >= 0 {
+		return (*allocation.Path)[:i]
+	}
+	return ""
+}
+
+func subnetContainsIP(nsxSubnet *model.VpcSubnet, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, cidr := range nsxSubnet.IpAddresses {
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil && ipNet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
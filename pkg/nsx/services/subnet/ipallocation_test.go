@@ -0,0 +1,79 @@
+package subnet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets/ip_pools"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+type fakeIPAllocationClient struct {
+	ip_pools.IpAllocationsClient
+	allocations map[string]model.IpAddressAllocation
+}
+
+func (c *fakeIPAllocationClient) List(_ string, _ string, _ string, _ string, _ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.IpAddressAllocationListResult, error) {
+	result := model.IpAddressAllocationListResult{}
+	for _, allocation := range c.allocations {
+		result.Results = append(result.Results, allocation)
+	}
+	return result, nil
+}
+
+func (c *fakeIPAllocationClient) Patch(_ string, _ string, _ string, _ string, _ string, id string, allocation model.IpAddressAllocation) error {
+	c.allocations[id] = allocation
+	return nil
+}
+
+func (c *fakeIPAllocationClient) Delete(_ string, _ string, _ string, _ string, _ string, id string) error {
+	delete(c.allocations, id)
+	return nil
+}
+
+func TestSubnetService_ReserveAndReleaseIP(t *testing.T) {
+	s, _, ipPoolClient := newFakeSubnetService(config.SubnetExhaustionPolicyFail)
+	allocationClient := &fakeIPAllocationClient{allocations: map[string]model.IpAddressAllocation{}}
+	s.NSXClient.SubnetIPAllocationClient = allocationClient
+	nsxSubnet := &model.VpcSubnet{Id: String("subnet1"), Path: String(vpcPath + "/subnets/subnet1"), IpAddresses: []string{"10.0.0.0/26"}}
+
+	// the Subnet has no IP pool yet
+	assert.NotNil(t, s.ReserveIP(nsxSubnet, "port_uid1", "10.0.0.5", "uid1"))
+
+	ipPoolClient.available["subnet1"] = 60
+	assert.Nil(t, s.ReserveIP(nsxSubnet, "port_uid1", "10.0.0.5", "uid1"))
+	assert.Equal(t, "10.0.0.5", *allocationClient.allocations["port_uid1"].AllocationIp)
+	// the allocation is tagged with the UID of the SubnetPort CR
+	assert.Equal(t, "uid1", *allocationClient.allocations["port_uid1"].Tags[1].Tag)
+	reserved := s.GetReservedIPs("uid1")
+	assert.Equal(t, 1, len(reserved))
+	assert.True(t, ReservedIPInSubnet(&reserved[0], nsxSubnet, "10.0.0.5"))
+	assert.False(t, ReservedIPInSubnet(&reserved[0], nsxSubnet, "10.0.0.6"))
+	assert.Equal(t, []string{"uid1"}, s.ListReservedIPOwnerUID().List())
+	// reserving the same IP again is a no-op
+	assert.Nil(t, s.ReserveIP(nsxSubnet, "port_uid1", "10.0.0.5", "uid1"))
+
+	// the IP allocated to another port or out of the Subnet is rejected
+	err := s.ReserveIP(nsxSubnet, "port_uid2", "10.0.0.5", "uid2")
+	assert.True(t, errors.As(err, &nsxutil.RestrictionError{}))
+	assert.Contains(t, err.Error(), "already allocated")
+	err = s.ReserveIP(nsxSubnet, "port_uid2", "10.0.1.5", "uid2")
+	assert.True(t, errors.As(err, &nsxutil.RestrictionError{}))
+	assert.Equal(t, 1, len(allocationClient.allocations))
+
+	assert.Nil(t, s.ReleaseIP(nsxSubnet, "port_uid2"))
+	assert.Equal(t, 1, len(allocationClient.allocations))
+	assert.Nil(t, s.ReleaseIP(nsxSubnet, "port_uid1"))
+	assert.Equal(t, 0, len(allocationClient.allocations))
+	assert.Equal(t, 0, len(s.GetReservedIPs("uid1")))
+
+	// the IPs reserved for a SubnetPort CR are released by its UID
+	assert.Nil(t, s.ReserveIP(nsxSubnet, "port_uid1", "10.0.0.5", "uid1"))
+	assert.Nil(t, s.ReleaseReservedIPs("uid1"))
+	assert.Equal(t, 0, len(allocationClient.allocations))
+	assert.Equal(t, 0, s.ListReservedIPOwnerUID().Len())
+}
@@ -0,0 +1,134 @@
+package subnet
+
+import (
+	"errors"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// keyFunc is used to get the key of a resource, usually, which is the ID of the resource
+func keyFunc(obj interface{}) (string, error) {
+	switch v := obj.(type) {
+	case model.VpcSubnet:
+		return *v.Id, nil
+	default:
+		return "", errors.New("keyFunc doesn't support unknown type")
+	}
+}
+
+// subnetIndexFunc indexes the NSX Subnets by the UID of the Subnet CR they are created for.
+func subnetIndexFunc(obj interface{}) ([]string, error) {
+	switch o := obj.(type) {
+	case model.VpcSubnet:
+		return filterTag(o.Tags, common.TagScopeSubnetCRUID), nil
+	default:
+		return nil, errors.New("subnetIndexFunc doesn't support unknown type")
+	}
+}
+
+// subnetSetIndexFunc indexes the NSX Subnets by the UID of the SubnetSet CR they are created for.
+func subnetSetIndexFunc(obj interface{}) ([]string, error) {
+	switch o := obj.(type) {
+	case model.VpcSubnet:
+		return filterTag(o.Tags, common.TagScopeSubnetSetCRUID), nil
+	default:
+		return nil, errors.New("subnetSetIndexFunc doesn't support unknown type")
+	}
+}
+
+// reservedIPKeyFunc is used to get the key of an IP allocation, which is its path as the allocations in different
+// Subnets share the ID.
+func reservedIPKeyFunc(obj interface{}) (string, error) {
+	switch v := obj.(type) {
+	case model.IpAddressAllocation:
+		return *v.Path, nil
+	default:
+		return "", errors.New("reservedIPKeyFunc doesn't support unknown type")
+	}
+}
+
+// reservedIPIndexFunc indexes the IP allocations by the UID of the SubnetPort CR they reserve the IP for.
+func reservedIPIndexFunc(obj interface{}) ([]string, error) {
+	switch o := obj.(type) {
+	case model.IpAddressAllocation:
+		return filterTag(o.Tags, common.TagScopeSubnetPortCRUID), nil
+	default:
+		return nil, errors.New("reservedIPIndexFunc doesn't support unknown type")
+	}
+}
+
+func filterTag(tags []model.Tag, tagScope string) []string {
+	res := make([]string, 0, 5)
+	for _, tag := range tags {
+		if *tag.Scope == tagScope {
+			res = append(res, *tag.Tag)
+		}
+	}
+	return res
+}
+
+// SubnetStore is a store for NSX VPC Subnets
+type SubnetStore struct {
+	common.ResourceStore
+}
+
+func (subnetStore *SubnetStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	subnet := i.(*model.VpcSubnet)
+	if subnet.MarkedForDelete != nil && *subnet.MarkedForDelete {
+		if err := subnetStore.Delete(*subnet); err != nil {
+			return err
+		}
+		log.V(1).Info("delete Subnet from store", "Subnet", subnet)
+	} else {
+		if err := subnetStore.Add(*subnet); err != nil {
+			return err
+		}
+		log.V(1).Info("add Subnet to store", "Subnet", subnet)
+	}
+	return nil
+}
+
+func (subnetStore *SubnetStore) GetByIndex(key string, value string) []model.VpcSubnet {
+	subnets := make([]model.VpcSubnet, 0)
+	for _, subnet := range subnetStore.ResourceStore.GetByIndex(key, value) {
+		subnets = append(subnets, subnet.(model.VpcSubnet))
+	}
+	return subnets
+}
+
+// ReservedIPStore is a store for the IP allocations reserving the IPs of the SubnetPorts in the NSX Subnets.
+type ReservedIPStore struct {
+	common.ResourceStore
+}
+
+func (reservedIPStore *ReservedIPStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	allocation := i.(*model.IpAddressAllocation)
+	if allocation.MarkedForDelete != nil && *allocation.MarkedForDelete {
+		if err := reservedIPStore.Delete(*allocation); err != nil {
+			return err
+		}
+		log.V(1).Info("delete reserved IP from store", "allocation", allocation)
+	} else {
+		if err := reservedIPStore.Add(*allocation); err != nil {
+			return err
+		}
+		log.V(1).Info("add reserved IP to store", "allocation", allocation)
+	}
+	return nil
+}
+
+func (reservedIPStore *ReservedIPStore) GetByIndex(key string, value string) []model.IpAddressAllocation {
+	allocations := make([]model.IpAddressAllocation, 0)
+	for _, allocation := range reservedIPStore.ResourceStore.GetByIndex(key, value) {
+		allocations = append(allocations, allocation.(model.IpAddressAllocation))
+	}
+	return allocations
+}
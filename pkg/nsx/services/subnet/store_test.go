@@ -0,0 +1,55 @@
+package subnet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func newSubnetStore() *SubnetStore {
+	return &SubnetStore{ResourceStore: common.ResourceStore{
+		Indexer: cache.NewIndexer(keyFunc, cache.Indexers{
+			common.TagScopeSubnetCRUID:    subnetIndexFunc,
+			common.TagScopeSubnetSetCRUID: subnetSetIndexFunc,
+		}),
+		BindingType: model.VpcSubnetBindingType(),
+	}}
+}
+
+func newReservedIPStore() *ReservedIPStore {
+	return &ReservedIPStore{ResourceStore: common.ResourceStore{
+		Indexer:     cache.NewIndexer(reservedIPKeyFunc, cache.Indexers{common.TagScopeSubnetPortCRUID: reservedIPIndexFunc}),
+		BindingType: model.IpAddressAllocationBindingType(),
+	}}
+}
+
+func TestSubnetStore_Operate(t *testing.T) {
+	subnetStore := newSubnetStore()
+	subnet1 := model.VpcSubnet{
+		Id:   String("subnet_uid1"),
+		Tags: []model.Tag{{Scope: String(common.TagScopeSubnetCRUID), Tag: String("uid1")}},
+	}
+	subnet2 := model.VpcSubnet{
+		Id:   String("subnetset_uid2_0"),
+		Tags: []model.Tag{{Scope: String(common.TagScopeSubnetSetCRUID), Tag: String("uid2")}},
+	}
+	assert.Nil(t, subnetStore.Operate(&subnet1))
+	assert.Nil(t, subnetStore.Operate(&subnet2))
+	assert.Nil(t, subnetStore.Operate(nil))
+
+	assert.Equal(t, []model.VpcSubnet{subnet1}, subnetStore.GetByIndex(common.TagScopeSubnetCRUID, "uid1"))
+	assert.Equal(t, []model.VpcSubnet{subnet2}, subnetStore.GetByIndex(common.TagScopeSubnetSetCRUID, "uid2"))
+	assert.Equal(t, 0, len(subnetStore.GetByIndex(common.TagScopeSubnetSetCRUID, "uid1")))
+
+	subnet1.MarkedForDelete = &MarkedForDelete
+	assert.Nil(t, subnetStore.Operate(&subnet1))
+	assert.Equal(t, 0, len(subnetStore.GetByIndex(common.TagScopeSubnetCRUID, "uid1")))
+	assert.Equal(t, 1, len(subnetStore.List()))
+
+	_, err := keyFunc(model.Vpc{})
+	assert.NotNil(t, err)
+}
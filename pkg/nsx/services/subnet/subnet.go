@@ -0,0 +1,430 @@
+package subnet
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipam"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+const (
+	ReasonSubnetSetExhausted = "SubnetSetExhausted"
+)
+
+var (
+	log                = logger.Log
+	ResourceTypeSubnet = common.ResourceTypeSubnet
+	MarkedForDelete    = true
+)
+
+type SubnetService struct {
+	common.Service
+	subnetStore     *SubnetStore
+	reservedIPStore *ReservedIPStore
+	allocator       ipam.Allocator
+}
+
+// InitializeSubnetService sync NSX resources
+func InitializeSubnetService(service common.Service) (*SubnetService, error) {
+	wg := sync.WaitGroup{}
+	wgDone := make(chan bool)
+	fatalErrors := make(chan error)
+
+	wg.Add(2)
+
+	subnetService := &SubnetService{Service: service, allocator: ipam.NewIPAMService(service)}
+
+	subnetService.subnetStore = &SubnetStore{ResourceStore: common.ResourceStore{
+		Indexer: common.NewIndexer(keyFunc, cache.Indexers{
+			common.TagScopeSubnetCRUID:    subnetIndexFunc,
+			common.TagScopeSubnetSetCRUID: subnetSetIndexFunc,
+		}),
+		BindingType: model.VpcSubnetBindingType(),
+	}}
+	subnetService.reservedIPStore = &ReservedIPStore{ResourceStore: common.ResourceStore{
+		Indexer:     common.NewIndexer(reservedIPKeyFunc, cache.Indexers{common.TagScopeSubnetPortCRUID: reservedIPIndexFunc}),
+		BindingType: model.IpAddressAllocationBindingType(),
+	}}
+
+	go subnetService.InitializeResourceStore(&wg, fatalErrors, ResourceTypeSubnet, subnetService.subnetStore)
+	go subnetService.InitializeResourceStore(&wg, fatalErrors, common.ResourceTypeIPAllocation, subnetService.reservedIPStore)
+
+	go func() {
+		wg.Wait()
+		close(wgDone)
+	}()
+
+	select {
+	case <-wgDone:
+		break
+	case err := <-fatalErrors:
+		close(fatalErrors)
+		return subnetService, err
+	}
+
+	return subnetService, nil
+}
+
+// CreateOrUpdateSubnet creates or updates the NSX Subnet of the Subnet CR in the VPC.
+func (s *SubnetService) CreateOrUpdateSubnet(obj *v1alpha1.Subnet, vpcPath string) (*model.VpcSubnet, error) {
+	nsxSubnet, err := s.buildSubnet(obj, vpcPath)
+	if err != nil {
+		return nil, err
+	}
+	if existing := s.subnetStore.GetByKey(*nsxSubnet.Id); existing != nil {
+		existingSubnet := existing.(model.VpcSubnet)
+		if !common.CompareResource(SubnetToComparable(&existingSubnet), SubnetToComparable(nsxSubnet)) {
+			log.Info("Subnet is not changed, skip updating it", "Subnet.Id", *nsxSubnet.Id)
+			return &existingSubnet, nil
+		}
+	}
+	return s.patchSubnet(nsxSubnet)
+}
+
+// createSubnetSetSubnet creates one more NSX Subnet for the SubnetSet with the first unused index.
+func (s *SubnetService) createSubnetSetSubnet(obj *v1alpha1.SubnetSet, vpcPath string) (*model.VpcSubnet, error) {
+	for index := 0; ; index++ {
+		nsxSubnet, err := s.buildSubnetSetSubnet(obj, vpcPath, index)
+		if err != nil {
+			return nil, err
+		}
+		if s.subnetStore.GetByKey(*nsxSubnet.Id) == nil {
+			return s.patchSubnet(nsxSubnet)
+		}
+	}
+}
+
+// patchSubnet patches the Subnet to NSX and stores the realized one, whose IpAddresses are allocated by NSX if not specified.
+func (s *SubnetService) patchSubnet(nsxSubnet *model.VpcSubnet) (*model.VpcSubnet, error) {
+	org, project, vpcID, err := common.ParseVPCPath(*nsxSubnet.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.NSXClient.VPCSubnetClient.Patch(org, project, vpcID, *nsxSubnet.Id, *nsxSubnet); err != nil {
+		// the IPs of the Subnet are allocated from the IP blocks of the VPC
+		return nil, common.TransIPExhaustedError(err, path.Dir(path.Dir(*nsxSubnet.Path)))
+	}
+	realized, err := s.NSXClient.VPCSubnetClient.Get(org, project, vpcID, *nsxSubnet.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.subnetStore.Operate(&realized); err != nil {
+		return nil, err
+	}
+	log.Info("successfully created or updated Subnet", "Subnet", realized)
+	return &realized, nil
+}
+
+// DeleteSubnet deletes the NSX Subnet and removes it from the store.
+func (s *SubnetService) DeleteSubnet(nsxSubnet model.VpcSubnet) error {
+	org, project, vpcID, err := common.ParseVPCPath(*nsxSubnet.Path)
+	if err != nil {
+		return err
+	}
+	if err := s.NSXClient.VPCSubnetClient.Delete(org, project, vpcID, *nsxSubnet.Id); err != nil {
+		return err
+	}
+	nsxSubnet.MarkedForDelete = &MarkedForDelete
+	if err := s.subnetStore.Operate(&nsxSubnet); err != nil {
+		return err
+	}
+	log.Info("successfully deleted Subnet", "Subnet", nsxSubnet)
+	return nil
+}
+
+func (s *SubnetService) GetSubnetsByIndex(key, value string) []model.VpcSubnet {
+	return s.subnetStore.GetByIndex(key, value)
+}
+
+// GetSubnetByPath returns the NSX Subnet of the path from the store, or nil if it doesn't exist.
+func (s *SubnetService) GetSubnetByPath(path string) *model.VpcSubnet {
+	obj := s.subnetStore.GetByKey(path[strings.LastIndex(path, "/")+1:])
+	if obj == nil {
+		return nil
+	}
+	nsxSubnet := obj.(model.VpcSubnet)
+	if nsxSubnet.Path == nil || *nsxSubnet.Path != path {
+		return nil
+	}
+	return &nsxSubnet
+}
+
+// ListSubnetCRUID returns the UIDs of the Subnet CRs which have NSX Subnets.
+func (s *SubnetService) ListSubnetCRUID() sets.String {
+	return s.subnetStore.ListIndexFuncValues(common.TagScopeSubnetCRUID)
+}
+
+// ListSubnetSetCRUID returns the UIDs of the SubnetSet CRs which have NSX Subnets.
+func (s *SubnetService) ListSubnetSetCRUID() sets.String {
+	return s.subnetStore.ListIndexFuncValues(common.TagScopeSubnetSetCRUID)
+}
+
+// DeleteSubnetsByCRUID deletes the NSX Subnets created for the Subnet CR.
+func (s *SubnetService) DeleteSubnetsByCRUID(uid types.UID) error {
+	return s.deleteSubnetsByIndex(common.TagScopeSubnetCRUID, string(uid))
+}
+
+// DeleteSubnetSetSubnets deletes all the NSX Subnets created for the SubnetSet CR.
+func (s *SubnetService) DeleteSubnetSetSubnets(uid types.UID) error {
+	return s.deleteSubnetsByIndex(common.TagScopeSubnetSetCRUID, string(uid))
+}
+
+func (s *SubnetService) deleteSubnetsByIndex(key, value string) error {
+	for _, nsxSubnet := range s.GetSubnetsByIndex(key, value) {
+		if err := s.DeleteSubnet(nsxSubnet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSubnetStatus reads the gateway and DHCP server addresses of the NSX Subnet realized on NSX.
+func (s *SubnetService) GetSubnetStatus(nsxSubnet *model.VpcSubnet) ([]string, []string, error) {
+	org, project, vpcID, err := common.ParseVPCPath(*nsxSubnet.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	statusList, err := s.NSXClient.SubnetStatusClient.List(org, project, vpcID, *nsxSubnet.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+	var gatewayAddresses, dhcpServerAddresses []string
+	for _, status := range statusList.Results {
+		if status.GatewayAddress != nil {
+			gatewayAddresses = append(gatewayAddresses, *status.GatewayAddress)
+		}
+		if status.DhcpServerAddress != nil {
+			dhcpServerAddresses = append(dhcpServerAddresses, *status.DhcpServerAddress)
+		}
+	}
+	return gatewayAddresses, dhcpServerAddresses, nil
+}
+
+// GetIPPoolUsage sums up the usage of the IP pools of the NSX Subnet.
+func (s *SubnetService) GetIPPoolUsage(nsxSubnet *model.VpcSubnet) (*model.PolicyPoolUsage, error) {
+	org, project, vpcID, err := common.ParseVPCPath(*nsxSubnet.Path)
+	if err != nil {
+		return nil, err
+	}
+	usage := &model.PolicyPoolUsage{}
+	var cursor *string
+	for {
+		pools, err := s.NSXClient.IPPoolClient.List(org, project, vpcID, *nsxSubnet.Id, cursor, nil, nil, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, pool := range pools.Results {
+			if pool.PoolUsage == nil {
+				continue
+			}
+			usage.TotalIps = addInt64(usage.TotalIps, pool.PoolUsage.TotalIps)
+			usage.AvailableIps = addInt64(usage.AvailableIps, pool.PoolUsage.AvailableIps)
+			usage.AllocatedIpAllocations = addInt64(usage.AllocatedIpAllocations, pool.PoolUsage.AllocatedIpAllocations)
+			usage.RequestedIpAllocations = addInt64(usage.RequestedIpAllocations, pool.PoolUsage.RequestedIpAllocations)
+		}
+		if pools.Cursor == nil || *pools.Cursor == "" {
+			return usage, nil
+		}
+		cursor = pools.Cursor
+	}
+}
+
+func addInt64(sum, v *int64) *int64 {
+	if v == nil {
+		return sum
+	}
+	if sum == nil {
+		return Int64(*v)
+	}
+	return Int64(*sum + *v)
+}
+
+// subnetUsage is the IP usage of an NSX Subnet, it is not reported by NSX for a new Subnet.
+type subnetUsage struct {
+	reported  bool
+	total     int64
+	available int64
+}
+
+func (u *subnetUsage) used() int64 {
+	if u.total < u.available {
+		return 0
+	}
+	return u.total - u.available
+}
+
+func (u *subnetUsage) utilization() int {
+	if u.total == 0 {
+		return 0
+	}
+	return int(u.used() * 100 / u.total)
+}
+
+// hasAvailableIP treats the Subnet as available if NSX doesn't report its usage yet.
+func (u *subnetUsage) hasAvailableIP() bool {
+	return !u.reported || u.available > 0
+}
+
+func (s *SubnetService) getSubnetUsage(nsxSubnet *model.VpcSubnet) (*subnetUsage, error) {
+	poolUsage, err := s.GetIPPoolUsage(nsxSubnet)
+	if err != nil {
+		return nil, err
+	}
+	usage := &subnetUsage{}
+	if poolUsage.AvailableIps != nil {
+		usage.reported = true
+		usage.available = *poolUsage.AvailableIps
+	}
+	if poolUsage.TotalIps != nil {
+		usage.total = *poolUsage.TotalIps
+	}
+	return usage, nil
+}
+
+// GetSubnetIPUsage returns the IP usage of the NSX Subnet, or nil if NSX doesn't report it yet.
+func (s *SubnetService) GetSubnetIPUsage(nsxSubnet *model.VpcSubnet) (*v1alpha1.SubnetIPUsage, error) {
+	usage, err := s.getSubnetUsage(nsxSubnet)
+	if err != nil || !usage.reported {
+		return nil, err
+	}
+	return &v1alpha1.SubnetIPUsage{
+		Total:       usage.total,
+		Used:        usage.used(),
+		Available:   usage.available,
+		Utilization: usage.utilization(),
+	}, nil
+}
+
+// GetAvailableSubnet returns a Subnet of the SubnetSet which still has free IPs. When all the Subnets run out of IPs,
+// it creates one more Subnet if the exhaustion policy is auto_create, or returns nsxutil.IPExhaustedError otherwise.
+func (s *SubnetService) GetAvailableSubnet(obj *v1alpha1.SubnetSet, vpcPath string) (*model.VpcSubnet, error) {
+	subnets := s.GetSubnetsByIndex(common.TagScopeSubnetSetCRUID, string(obj.UID))
+	for i := range subnets {
+		usage, err := s.getSubnetUsage(&subnets[i])
+		if err != nil {
+			return nil, err
+		}
+		if usage.hasAvailableIP() {
+			return &subnets[i], nil
+		}
+	}
+	if len(subnets) > 0 && s.NSXConfig.GetSubnetExhaustionPolicy() == config.SubnetExhaustionPolicyFail {
+		log.Info("all the Subnets of SubnetSet run out of IPs", "SubnetSet", obj.Name, "Namespace", obj.Namespace, "Subnets", len(subnets))
+		return nil, nsxutil.IPExhaustedError{
+			Pool: subnetSetPool(obj),
+			Desc: fmt.Sprintf("all the %d Subnets of SubnetSet %s/%s run out of IPs", len(subnets), obj.Namespace, obj.Name),
+		}
+	}
+	log.Info("creating a Subnet for SubnetSet", "SubnetSet", obj.Name, "Namespace", obj.Namespace, "Subnets", len(subnets))
+	return s.createSubnetSetSubnet(obj, vpcPath)
+}
+
+// ScaleSubnetSet keeps at least one Subnet for the SubnetSet. If the exhaustion policy is auto_create, it creates one
+// more Subnet when the utilization of all the Subnets reaches the scale threshold. It returns the created Subnet or nil.
+func (s *SubnetService) ScaleSubnetSet(obj *v1alpha1.SubnetSet, vpcPath string) (*model.VpcSubnet, error) {
+	subnets := s.GetSubnetsByIndex(common.TagScopeSubnetSetCRUID, string(obj.UID))
+	if len(subnets) == 0 {
+		log.Info("creating the first Subnet for SubnetSet", "SubnetSet", obj.Name, "Namespace", obj.Namespace)
+		return s.createSubnetSetSubnet(obj, vpcPath)
+	}
+	if s.NSXConfig.GetSubnetExhaustionPolicy() != config.SubnetExhaustionPolicyAutoCreate {
+		return nil, nil
+	}
+	threshold := s.NSXConfig.GetSubnetSetScaleThreshold()
+	for i := range subnets {
+		usage, err := s.getSubnetUsage(&subnets[i])
+		if err != nil {
+			return nil, err
+		}
+		if !usage.reported || usage.utilization() < threshold {
+			return nil, nil
+		}
+	}
+	log.Info("utilization of all the Subnets of SubnetSet reaches the threshold, creating a Subnet", "SubnetSet", obj.Name,
+		"Namespace", obj.Namespace, "Subnets", len(subnets), "threshold", threshold)
+	return s.createSubnetSetSubnet(obj, vpcPath)
+}
+
+// UpdateSubnetSetStatus reports the Subnets of the SubnetSet with their usage and whether all of them run out of IPs.
+func (s *SubnetService) UpdateSubnetSetStatus(obj *v1alpha1.SubnetSet) error {
+	subnets := s.GetSubnetsByIndex(common.TagScopeSubnetSetCRUID, string(obj.UID))
+	// the Subnets are listed in a stable order so that the status is not updated for nothing
+	sort.Slice(subnets, func(i, j int) bool { return *subnets[i].Path < *subnets[j].Path })
+	subnetInfos := make([]v1alpha1.SubnetInfo, 0, len(subnets))
+	exhausted := len(subnets) > 0
+	for i := range subnets {
+		usage, err := s.getSubnetUsage(&subnets[i])
+		if err != nil {
+			return err
+		}
+		subnetInfos = append(subnetInfos, v1alpha1.SubnetInfo{
+			NSXResourcePath: *subnets[i].Path,
+			IPAddresses:     subnets[i].IpAddresses,
+			Total:           usage.total,
+			Used:            usage.used(),
+			Utilization:     usage.utilization(),
+		})
+		if usage.hasAvailableIP() {
+			exhausted = false
+		}
+	}
+	obj.Status.Subnets = subnetInfos
+
+	condition := v1alpha1.Condition{
+		Type:   v1alpha1.IPExhausted,
+		Status: v1.ConditionFalse,
+	}
+	if exhausted {
+		condition.Status = v1.ConditionTrue
+		condition.Reason = ReasonSubnetSetExhausted
+		condition.Message = fmt.Sprintf("all the %d Subnets run out of IPs, Subnet exhaustion policy is %s",
+			len(subnets), s.NSXConfig.GetSubnetExhaustionPolicy())
+	}
+	mergeSubnetSetStatusCondition(obj, &condition)
+	return s.Client.Status().Update(context.TODO(), obj)
+}
+
+// UpdateSubnetSetCondition merges the condition into the status of the SubnetSet.
+func (s *SubnetService) UpdateSubnetSetCondition(obj *v1alpha1.SubnetSet, condition *v1alpha1.Condition) error {
+	mergeSubnetSetStatusCondition(obj, condition)
+	return s.Client.Status().Update(context.TODO(), obj)
+}
+
+// subnetSetPool is the pool of the SubnetSet reported in the exhaustion metric.
+func subnetSetPool(obj *v1alpha1.SubnetSet) string {
+	return fmt.Sprintf("subnetset/%s/%s", obj.Namespace, obj.Name)
+}
+
+func mergeSubnetSetStatusCondition(obj *v1alpha1.SubnetSet, newCondition *v1alpha1.Condition) {
+	for i := range obj.Status.Conditions {
+		matchedCondition := &obj.Status.Conditions[i]
+		if matchedCondition.Type != newCondition.Type {
+			continue
+		}
+		if matchedCondition.Status != newCondition.Status {
+			matchedCondition.LastTransitionTime = metav1.Now()
+		}
+		matchedCondition.Status = newCondition.Status
+		matchedCondition.Reason = newCondition.Reason
+		matchedCondition.Message = newCondition.Message
+		return
+	}
+	newCondition.LastTransitionTime = metav1.Now()
+	obj.Status.Conditions = append(obj.Status.Conditions, *newCondition)
+}
@@ -0,0 +1,332 @@
+package subnet
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/mock/nsxsimulator"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/ipam"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+type fakeSubnetsClient struct {
+	vpcs.SubnetsClient
+	subnets  map[string]model.VpcSubnet
+	patched  int
+	patchErr error
+}
+
+func (c *fakeSubnetsClient) Patch(_ string, _ string, _ string, id string, subnet model.VpcSubnet) error {
+	if c.patchErr != nil {
+		return c.patchErr
+	}
+	c.patched++
+	subnet.IpAddresses = []string{"10.0.0.0/26"}
+	c.subnets[id] = subnet
+	return nil
+}
+
+func (c *fakeSubnetsClient) Get(_ string, _ string, _ string, id string) (model.VpcSubnet, error) {
+	return c.subnets[id], nil
+}
+
+func (c *fakeSubnetsClient) Delete(_ string, _ string, _ string, id string) error {
+	delete(c.subnets, id)
+	return nil
+}
+
+type fakeIPPoolClient struct {
+	subnets.IpPoolsClient
+	// available IPs of the Subnets
+	available map[string]int64
+}
+
+func (c *fakeIPPoolClient) List(_ string, _ string, _ string, subnetID string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.IpAddressPoolListResult, error) {
+	available, ok := c.available[subnetID]
+	if !ok {
+		return model.IpAddressPoolListResult{}, nil
+	}
+	return model.IpAddressPoolListResult{Results: []model.IpAddressPool{
+		{Id: String("static-ipv4-default"), PoolUsage: &model.PolicyPoolUsage{TotalIps: Int64(64), AvailableIps: Int64(available)}},
+	}}, nil
+}
+
+func newFakeSubnetService(policy string, objs ...apimachineryruntime.Object) (*SubnetService, *fakeSubnetsClient, *fakeIPPoolClient) {
+	scheme := apimachineryruntime.NewScheme()
+	v1alpha1.AddToScheme(scheme)
+	subnetsClient := &fakeSubnetsClient{subnets: map[string]model.VpcSubnet{}}
+	ipPoolClient := &fakeIPPoolClient{available: map[string]int64{}}
+	s := &SubnetService{
+		Service: common.Service{
+			Client:    fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+			NSXClient: &nsx.Client{VPCSubnetClient: subnetsClient, IPPoolClient: ipPoolClient},
+			NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one", SubnetExhaustionPolicy: policy}},
+		},
+		subnetStore:     newSubnetStore(),
+		reservedIPStore: newReservedIPStore(),
+	}
+	s.allocator = ipam.NewIPAMService(s.Service)
+	return s, subnetsClient, ipPoolClient
+}
+
+func TestSubnetService_CreateOrUpdateAndDeleteSubnet(t *testing.T) {
+	s, subnetsClient, _ := newFakeSubnetService(config.SubnetExhaustionPolicyFail)
+	obj := &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1", UID: "uid1"}}
+
+	nsxSubnet, err := s.CreateOrUpdateSubnet(obj, vpcPath)
+	assert.Nil(t, err)
+	// the realized Subnet is stored
+	assert.Equal(t, []string{"10.0.0.0/26"}, nsxSubnet.IpAddresses)
+	assert.Equal(t, 1, len(s.GetSubnetsByIndex(common.TagScopeSubnetCRUID, "uid1")))
+
+	_, err = s.CreateOrUpdateSubnet(obj, vpcPath)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, subnetsClient.patched)
+
+	obj.Spec.IPv4SubnetSize = 128
+	nsxSubnet, err = s.CreateOrUpdateSubnet(obj, vpcPath)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, subnetsClient.patched)
+	assert.Equal(t, int64(128), *nsxSubnet.Ipv4SubnetSize)
+
+	assert.Equal(t, []string{"uid1"}, s.ListSubnetCRUID().List())
+	assert.Equal(t, nsxSubnet, s.GetSubnetByPath(*nsxSubnet.Path))
+	assert.Nil(t, s.GetSubnetByPath("/orgs/default/projects/p1/vpcs/vpc2/subnets/subnet_uid1"))
+
+	// the Subnet is not patched with a relay DHCP config without the relay config path
+	obj.Spec.DHCPConfig.Mode = v1alpha1.DHCPModeRelay
+	_, err = s.CreateOrUpdateSubnet(obj, vpcPath)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, subnetsClient.patched)
+
+	assert.Nil(t, s.DeleteSubnetsByCRUID(obj.UID))
+	assert.Equal(t, 0, len(subnetsClient.subnets))
+	assert.Equal(t, 0, len(s.GetSubnetsByIndex(common.TagScopeSubnetCRUID, "uid1")))
+
+	// the IP blocks of the VPC run out of IPs
+	obj.Spec.DHCPConfig.Mode = ""
+	subnetsClient.patchErr = vapierrors.UnableToAllocateResource{}
+	_, err = s.CreateOrUpdateSubnet(obj, vpcPath)
+	exhausted := nsxutil.IPExhaustedError{}
+	assert.True(t, errors.As(err, &exhausted))
+	assert.Equal(t, vpcPath, exhausted.Pool)
+}
+
+type fakeSubnetStatusClient struct {
+	subnets.StatusClient
+}
+
+func (c *fakeSubnetStatusClient) List(_ string, _ string, _ string, _ string) (model.VpcSubnetStatusListResult, error) {
+	return model.VpcSubnetStatusListResult{Results: []model.VpcSubnetStatus{
+		{GatewayAddress: String("10.0.0.1/26"), DhcpServerAddress: String("10.0.0.2/26")},
+		{GatewayAddress: String("fd00::1/64")},
+	}}, nil
+}
+
+func TestSubnetService_GetSubnetStatus(t *testing.T) {
+	s, _, _ := newFakeSubnetService(config.SubnetExhaustionPolicyFail)
+	s.NSXClient.SubnetStatusClient = &fakeSubnetStatusClient{}
+	nsxSubnet := &model.VpcSubnet{Id: String("subnet1"), Path: String(vpcPath + "/subnets/subnet1")}
+
+	gatewayAddresses, dhcpServerAddresses, err := s.GetSubnetStatus(nsxSubnet)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.1/26", "fd00::1/64"}, gatewayAddresses)
+	assert.Equal(t, []string{"10.0.0.2/26"}, dhcpServerAddresses)
+}
+
+func TestSubnetService_GetIPPoolUsage(t *testing.T) {
+	s, _, ipPoolClient := newFakeSubnetService(config.SubnetExhaustionPolicyFail)
+	nsxSubnet := &model.VpcSubnet{Id: String("subnet1"), Path: String(vpcPath + "/subnets/subnet1")}
+
+	usage, err := s.GetIPPoolUsage(nsxSubnet)
+	assert.Nil(t, err)
+	assert.Nil(t, usage.AvailableIps)
+
+	ipPoolClient.available["subnet1"] = 10
+	usage, err = s.GetIPPoolUsage(nsxSubnet)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), *usage.AvailableIps)
+	assert.Equal(t, int64(64), *usage.TotalIps)
+
+	_, err = s.GetIPPoolUsage(&model.VpcSubnet{Id: String("subnet1"), Path: String("/infra/subnets/subnet1")})
+	assert.NotNil(t, err)
+}
+
+func TestSubnetService_GetSubnetIPUsage(t *testing.T) {
+	s, _, ipPoolClient := newFakeSubnetService(config.SubnetExhaustionPolicyFail)
+	nsxSubnet := &model.VpcSubnet{Id: String("subnet1"), Path: String(vpcPath + "/subnets/subnet1")}
+
+	usage, err := s.GetSubnetIPUsage(nsxSubnet)
+	assert.Nil(t, err)
+	assert.Nil(t, usage)
+
+	ipPoolClient.available["subnet1"] = 10
+	usage, err = s.GetSubnetIPUsage(nsxSubnet)
+	assert.Nil(t, err)
+	assert.Equal(t, &v1alpha1.SubnetIPUsage{Total: 64, Used: 54, Available: 10, Utilization: 84}, usage)
+}
+
+var errSubnetSetExhausted = nsxutil.IPExhaustedError{
+	Pool: "subnetset/ns1/set1",
+	Desc: "all the 1 Subnets of SubnetSet ns1/set1 run out of IPs",
+}
+
+func TestSubnetService_GetAvailableSubnet(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		wantErr    error
+		wantSubnet string
+	}{
+		{name: "fail", policy: config.SubnetExhaustionPolicyFail, wantErr: errSubnetSetExhausted},
+		{name: "default", policy: "", wantErr: errSubnetSetExhausted},
+		{name: "auto-create", policy: config.SubnetExhaustionPolicyAutoCreate, wantSubnet: "subnetset_uid2_1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &v1alpha1.SubnetSet{ObjectMeta: metav1.ObjectMeta{Name: "set1", Namespace: "ns1", UID: "uid2"}}
+			s, subnetsClient, ipPoolClient := newFakeSubnetService(tt.policy)
+
+			// the first Subnet is created regardless of the policy
+			nsxSubnet, err := s.GetAvailableSubnet(obj, vpcPath)
+			assert.Nil(t, err)
+			assert.Equal(t, "subnetset_uid2_0", *nsxSubnet.Id)
+
+			ipPoolClient.available["subnetset_uid2_0"] = 1
+			nsxSubnet, err = s.GetAvailableSubnet(obj, vpcPath)
+			assert.Nil(t, err)
+			assert.Equal(t, "subnetset_uid2_0", *nsxSubnet.Id)
+			assert.Equal(t, 1, subnetsClient.patched)
+
+			ipPoolClient.available["subnetset_uid2_0"] = 0
+			nsxSubnet, err = s.GetAvailableSubnet(obj, vpcPath)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantSubnet != "" {
+				assert.Equal(t, tt.wantSubnet, *nsxSubnet.Id)
+				assert.Equal(t, 2, len(s.GetSubnetsByIndex(common.TagScopeSubnetSetCRUID, "uid2")))
+			}
+		})
+	}
+}
+
+func TestSubnetService_UpdateSubnetSetStatus(t *testing.T) {
+	obj := &v1alpha1.SubnetSet{ObjectMeta: metav1.ObjectMeta{Name: "set1", Namespace: "ns1", UID: "uid2"}}
+	s, _, ipPoolClient := newFakeSubnetService(config.SubnetExhaustionPolicyFail, obj)
+	_, err := s.GetAvailableSubnet(obj, vpcPath)
+	assert.Nil(t, err)
+
+	ipPoolClient.available["subnetset_uid2_0"] = 0
+	assert.Nil(t, s.UpdateSubnetSetStatus(obj))
+	got := &v1alpha1.SubnetSet{}
+	assert.Nil(t, s.Client.Get(context.TODO(), types.NamespacedName{Namespace: "ns1", Name: "set1"}, got))
+	assert.Equal(t, []v1alpha1.SubnetInfo{{NSXResourcePath: vpcPath + "/subnets/subnetset_uid2_0", IPAddresses: []string{"10.0.0.0/26"},
+		Total: 64, Used: 64, Utilization: 100}}, got.Status.Subnets)
+	assert.Equal(t, 1, len(got.Status.Conditions))
+	assert.Equal(t, v1alpha1.IPExhausted, got.Status.Conditions[0].Type)
+	assert.Equal(t, v1.ConditionTrue, got.Status.Conditions[0].Status)
+	assert.Equal(t, ReasonSubnetSetExhausted, got.Status.Conditions[0].Reason)
+
+	ipPoolClient.available["subnetset_uid2_0"] = 5
+	assert.Nil(t, s.UpdateSubnetSetStatus(got))
+	assert.Nil(t, s.Client.Get(context.TODO(), types.NamespacedName{Namespace: "ns1", Name: "set1"}, got))
+	assert.Equal(t, 1, len(got.Status.Conditions))
+	assert.Equal(t, v1.ConditionFalse, got.Status.Conditions[0].Status)
+	assert.Equal(t, "", got.Status.Conditions[0].Reason)
+	assert.Equal(t, 92, got.Status.Subnets[0].Utilization)
+}
+
+func TestSubnetService_ScaleSubnetSet(t *testing.T) {
+	obj := &v1alpha1.SubnetSet{ObjectMeta: metav1.ObjectMeta{Name: "set1", Namespace: "ns1", UID: "uid2"}}
+	for _, policy := range []string{config.SubnetExhaustionPolicyFail, config.SubnetExhaustionPolicyAutoCreate} {
+		t.Run(policy, func(t *testing.T) {
+			s, _, ipPoolClient := newFakeSubnetService(policy)
+
+			// the first Subnet is created regardless of the policy
+			nsxSubnet, err := s.ScaleSubnetSet(obj, vpcPath)
+			assert.Nil(t, err)
+			assert.Equal(t, "subnetset_uid2_0", *nsxSubnet.Id)
+
+			// the usage is not reported yet
+			nsxSubnet, err = s.ScaleSubnetSet(obj, vpcPath)
+			assert.Nil(t, err)
+			assert.Nil(t, nsxSubnet)
+
+			// 48 of the 64 IPs are used, below the default threshold
+			ipPoolClient.available["subnetset_uid2_0"] = 16
+			nsxSubnet, err = s.ScaleSubnetSet(obj, vpcPath)
+			assert.Nil(t, err)
+			assert.Nil(t, nsxSubnet)
+
+			// 56 of the 64 IPs are used
+			ipPoolClient.available["subnetset_uid2_0"] = 8
+			nsxSubnet, err = s.ScaleSubnetSet(obj, vpcPath)
+			assert.Nil(t, err)
+			if policy == config.SubnetExhaustionPolicyFail {
+				assert.Nil(t, nsxSubnet)
+				return
+			}
+			assert.Equal(t, "subnetset_uid2_1", *nsxSubnet.Id)
+
+			// the new Subnet is below the threshold
+			ipPoolClient.available["subnetset_uid2_1"] = 64
+			nsxSubnet, err = s.ScaleSubnetSet(obj, vpcPath)
+			assert.Nil(t, err)
+			assert.Nil(t, nsxSubnet)
+			assert.Equal(t, []string{"uid2"}, s.ListSubnetSetCRUID().List())
+
+			assert.Nil(t, s.DeleteSubnetSetSubnets(obj.UID))
+			assert.Equal(t, 0, s.ListSubnetSetCRUID().Len())
+		})
+	}
+}
+
+func TestSubnetService_NSXProfiles(t *testing.T) {
+	for _, profile := range nsxsimulator.Profiles {
+		t.Run(profile.Name, func(t *testing.T) {
+			sim := nsxsimulator.NewSimulator(profile)
+			defer sim.Close()
+			cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}}
+			s, err := InitializeSubnetService(common.Service{NSXClient: sim.NewNSXClient(cf), NSXConfig: cf})
+			assert.Nil(t, err)
+			// the search pages are shrunk to the max size of the release
+			assert.NotEmpty(t, sim.SearchPageSizes())
+			for _, pageSize := range sim.SearchPageSizes() {
+				assert.Equal(t, profile.MaxPageSize, pageSize)
+			}
+
+			obj := &v1alpha1.SubnetSet{ObjectMeta: metav1.ObjectMeta{Name: "set1", Namespace: "ns1", UID: "uid2"}}
+			nsxSubnet, err := s.GetAvailableSubnet(obj, vpcPath)
+			if !profile.Supports("/policy/api/v1/orgs/") {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, int64(64), *nsxSubnet.Ipv4SubnetSize)
+
+			sim.AddObject(*nsxSubnet.Path+"/ip-pools/pool1", map[string]interface{}{
+				"pool_usage": map[string]int64{"total_ips": 64, "available_ips": 0},
+			})
+			_, err = s.GetAvailableSubnet(obj, vpcPath)
+			assert.Equal(t, errSubnetSetExhausted, err)
+
+			assert.Nil(t, s.DeleteSubnet(*nsxSubnet))
+			assert.Nil(t, sim.Object(*nsxSubnet.Path))
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package subnetport
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+var (
+	String = common.String
+	Bool   = common.Bool
+)
+
+func buildSubnetPortPath(subnetPath, id string) string {
+	return fmt.Sprintf("%s/ports/%s", subnetPath, id)
+}
+
+// parseSubnetPortPath gets the org, project, VPC and Subnet IDs from the path
+// /orgs/<org>/projects/<project>/vpcs/<vpc>/subnets/<subnet>/ports/<port>.
+func parseSubnetPortPath(path string) (string, string, string, string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 10 || parts[0] != "orgs" || parts[2] != "projects" || parts[4] != "vpcs" || parts[6] != "subnets" || parts[8] != "ports" {
+		return "", "", "", "", fmt.Errorf("invalid SubnetPort path %s", path)
+	}
+	return parts[1], parts[3], parts[5], parts[7], nil
+}
+
+// allocateAddresses returns how the addresses of the port are allocated. The port gets its IP by DHCP if the Subnet
+// runs a DHCP server, otherwise the IP and MAC addresses are allocated from the pools of the Subnet unless bound.
+func allocateAddresses(nsxSubnet *model.VpcSubnet, bindings []v1alpha1.PortAddressBinding) *string {
+	if nsxSubnet.DhcpConfig != nil && nsxSubnet.DhcpConfig.EnableDhcp != nil && *nsxSubnet.DhcpConfig.EnableDhcp {
+		return String(model.PortAttachment_ALLOCATE_ADDRESSES_DHCP)
+	}
+	var ipBound, macBound bool
+	for _, binding := range bindings {
+		ipBound = ipBound || binding.IPAddress != ""
+		macBound = macBound || binding.MACAddress != ""
+	}
+	switch {
+	case ipBound && macBound:
+		return String(model.PortAttachment_ALLOCATE_ADDRESSES_NONE)
+	case ipBound:
+		return String(model.PortAttachment_ALLOCATE_ADDRESSES_MAC_POOL)
+	case macBound:
+		return String(model.PortAttachment_ALLOCATE_ADDRESSES_IP_POOL)
+	default:
+		return String(model.PortAttachment_ALLOCATE_ADDRESSES_BOTH)
+	}
+}
+
+func buildAddressBindings(bindings []v1alpha1.PortAddressBinding) []model.PortAddressBindingEntry {
+	var entries []model.PortAddressBindingEntry
+	for _, binding := range bindings {
+		entry := model.PortAddressBindingEntry{}
+		if binding.IPAddress != "" {
+			entry.IpAddress = String(binding.IPAddress)
+		}
+		if binding.MACAddress != "" {
+			entry.MacAddress = String(binding.MACAddress)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// addressBindings merges the IP requested by the annotation into the address bindings of the spec, the requested IP
+// conflicting with the bound IP or in a Subnet with DHCP enabled is rejected.
+func addressBindings(obj *v1alpha1.SubnetPort, nsxSubnet *model.VpcSubnet) ([]v1alpha1.PortAddressBinding, error) {
+	ip := obj.Annotations[common.AnnotationIPAddress]
+	if ip == "" {
+		return obj.Spec.AddressBindings, nil
+	}
+	if net.ParseIP(ip) == nil {
+		return nil, nsxutil.RestrictionError{Desc: fmt.Sprintf("invalid IP address %q in annotation %s", ip, common.AnnotationIPAddress)}
+	}
+	if nsxSubnet.DhcpConfig != nil && nsxSubnet.DhcpConfig.EnableDhcp != nil && *nsxSubnet.DhcpConfig.EnableDhcp {
+		return nil, nsxutil.RestrictionError{Desc: fmt.Sprintf("IP address cannot be requested in Subnet %s with DHCP enabled", *nsxSubnet.Id)}
+	}
+	if len(obj.Spec.AddressBindings) == 0 {
+		return []v1alpha1.PortAddressBinding{{IPAddress: ip}}, nil
+	}
+	binding := obj.Spec.AddressBindings[0]
+	if binding.IPAddress != "" && !net.ParseIP(binding.IPAddress).Equal(net.ParseIP(ip)) {
+		return nil, nsxutil.RestrictionError{Desc: fmt.Sprintf("IP address %s in annotation %s conflicts with the bound IP address %s",
+			ip, common.AnnotationIPAddress, binding.IPAddress)}
+	}
+	binding.IPAddress = ip
+	return []v1alpha1.PortAddressBinding{binding}, nil
+}
+
+// ReservedIP returns the IP reserved in the IP pool of the Subnet for the NSX port, or "" if none is reserved.
+func ReservedIP(nsxPort *model.SegmentPort) string {
+	if ips := filterTag(nsxPort.Tags, common.TagScopeSubnetPortIPAddress); len(ips) > 0 {
+		return ips[0]
+	}
+	return ""
+}
+
+// IPAllocationID returns the ID of the IP allocation reserving the requested IP of the SubnetPort CR.
+func IPAllocationID(uid types.UID) string {
+	return fmt.Sprintf("port_%s", uid)
+}
+
+// buildSubnetPort builds the NSX port of the SubnetPort CR in the Subnet, the UID of the CR is used as the VIF ID.
+// The profiles of the security features are recorded in a tag if security is configured.
+func (s *SubnetPortService) buildSubnetPort(obj *v1alpha1.SubnetPort, nsxSubnet *model.VpcSubnet, security *portSecurity) (*model.SegmentPort, error) {
+	bindings, err := addressBindings(obj, nsxSubnet)
+	if err != nil {
+		return nil, err
+	}
+	mac := ""
+	if len(bindings) == 0 || bindings[0].MACAddress == "" {
+		if mac, err = s.allocateMAC(obj.UID); err != nil {
+			return nil, err
+		}
+	}
+	if mac != "" {
+		if len(bindings) == 0 {
+			bindings = []v1alpha1.PortAddressBinding{{MACAddress: mac}}
+		} else {
+			bindings = []v1alpha1.PortAddressBinding{{IPAddress: bindings[0].IPAddress, MACAddress: mac}}
+		}
+	}
+	id := fmt.Sprintf("port_%s", obj.UID)
+	tags := []model.Tag{
+		{Scope: String(common.TagScopeCluster), Tag: String(s.NSXConfig.Cluster)},
+		{Scope: String(common.TagScopeNamespace), Tag: String(obj.Namespace)},
+		{Scope: String(common.TagScopeSubnetPortCRName), Tag: String(obj.Name)},
+		{Scope: String(common.TagScopeSubnetPortCRUID), Tag: String(string(obj.UID))},
+	}
+	if ip := obj.Annotations[common.AnnotationIPAddress]; ip != "" {
+		tags = append(tags, model.Tag{Scope: String(common.TagScopeSubnetPortIPAddress), Tag: String(ip)})
+	}
+	if mac != "" {
+		tags = append(tags, model.Tag{Scope: String(common.TagScopeSubnetPortMACAddress), Tag: String(mac)})
+	}
+	if security != nil {
+		tags = append(tags, model.Tag{Scope: String(common.TagScopeSubnetPortSecurity), Tag: String(s.securityTag(security))})
+	}
+	tags = s.AppendLabelTags(tags, obj.Namespace, obj.Labels)
+	return &model.SegmentPort{
+		Id:          String(id),
+		DisplayName: String(fmt.Sprintf("%s-%s", obj.Namespace, obj.Name)),
+		Path:        String(buildSubnetPortPath(*nsxSubnet.Path, id)),
+		ParentPath:  nsxSubnet.Path,
+		Attachment: &model.PortAttachment{
+			Id:                String(string(obj.UID)),
+			Type_:             String(model.PortAttachment_TYPE_STATIC),
+			AllocateAddresses: allocateAddresses(nsxSubnet, bindings),
+		},
+		AddressBindings: buildAddressBindings(bindings),
+		Tags:            tags,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package subnetport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestParseSubnetPortPath(t *testing.T) {
+	org, project, vpcID, subnetID, err := parseSubnetPortPath("/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1/ports/port1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"default", "p1", "vpc1", "subnet1"}, []string{org, project, vpcID, subnetID})
+
+	_, _, _, _, err = parseSubnetPortPath("/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1")
+	assert.NotNil(t, err)
+}
+
+func TestAllocateAddresses(t *testing.T) {
+	dhcpSubnet := &model.VpcSubnet{DhcpConfig: &model.DhcpConfig{EnableDhcp: common.Bool(true)}}
+	staticSubnet := &model.VpcSubnet{DhcpConfig: &model.DhcpConfig{EnableDhcp: common.Bool(false)}}
+	tests := []struct {
+		name      string
+		nsxSubnet *model.VpcSubnet
+		bindings  []v1alpha1.PortAddressBinding
+		expected  string
+	}{
+		{"dhcp", dhcpSubnet, []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5"}}, model.PortAttachment_ALLOCATE_ADDRESSES_DHCP},
+		{"pools", staticSubnet, nil, model.PortAttachment_ALLOCATE_ADDRESSES_BOTH},
+		{"ip bound", staticSubnet, []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5"}}, model.PortAttachment_ALLOCATE_ADDRESSES_MAC_POOL},
+		{"mac bound", staticSubnet, []v1alpha1.PortAddressBinding{{MACAddress: "00:50:56:00:00:01"}}, model.PortAttachment_ALLOCATE_ADDRESSES_IP_POOL},
+		{"both bound", &model.VpcSubnet{}, []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5", MACAddress: "00:50:56:00:00:01"}}, model.PortAttachment_ALLOCATE_ADDRESSES_NONE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, *allocateAddresses(tt.nsxSubnet, tt.bindings))
+		})
+	}
+}
+
+func TestBuildSubnetPort(t *testing.T) {
+	s := &SubnetPortService{Service: common.Service{
+		NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}},
+	}}
+	obj := &v1alpha1.SubnetPort{
+		ObjectMeta: metav1.ObjectMeta{Name: "port1", Namespace: "ns1", UID: "uid1"},
+		Spec:       v1alpha1.SubnetPortSpec{AddressBindings: []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5"}}},
+	}
+	nsxSubnet := &model.VpcSubnet{Path: String("/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1")}
+	nsxPort, err := s.buildSubnetPort(obj, nsxSubnet, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "port_uid1", *nsxPort.Id)
+	assert.Equal(t, "ns1-port1", *nsxPort.DisplayName)
+	assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1/ports/port_uid1", *nsxPort.Path)
+	assert.Equal(t, nsxSubnet.Path, nsxPort.ParentPath)
+	assert.Equal(t, "uid1", *nsxPort.Attachment.Id)
+	assert.Equal(t, model.PortAttachment_ALLOCATE_ADDRESSES_MAC_POOL, *nsxPort.Attachment.AllocateAddresses)
+	assert.Equal(t, []model.PortAddressBindingEntry{{IpAddress: String("10.0.0.5")}}, nsxPort.AddressBindings)
+	assert.Equal(t, []string{"uid1"}, filterTag(nsxPort.Tags, common.TagScopeSubnetPortCRUID))
+	assert.Equal(t, []string{"port1"}, filterTag(nsxPort.Tags, common.TagScopeSubnetPortCRName))
+	assert.Equal(t, "", ReservedIP(nsxPort))
+
+	// the requested IP is bound to the port and recorded as reserved
+	obj.Spec.AddressBindings = []v1alpha1.PortAddressBinding{{MACAddress: "00:50:56:00:00:01"}}
+	obj.Annotations = map[string]string{common.AnnotationIPAddress: "10.0.0.6"}
+	nsxPort, err = s.buildSubnetPort(obj, nsxSubnet, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.PortAddressBindingEntry{{IpAddress: String("10.0.0.6"), MacAddress: String("00:50:56:00:00:01")}}, nsxPort.AddressBindings)
+	assert.Equal(t, model.PortAttachment_ALLOCATE_ADDRESSES_NONE, *nsxPort.Attachment.AllocateAddresses)
+	assert.Equal(t, "10.0.0.6", ReservedIP(nsxPort))
+
+	// the port labels configured to be propagated are tagged
+	s.NSXConfig.LabelTagKeys = []string{"app"}
+	obj.Labels = map[string]string{"app": "web", "tier": "frontend"}
+	nsxPort, err = s.buildSubnetPort(obj, nsxSubnet, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"web"}, filterTag(nsxPort.Tags, "app"))
+	assert.Empty(t, filterTag(nsxPort.Tags, "tier"))
+}
+
+func TestAddressBindings(t *testing.T) {
+	staticSubnet := &model.VpcSubnet{Id: String("subnet1")}
+	dhcpSubnet := &model.VpcSubnet{Id: String("subnet1"), DhcpConfig: &model.DhcpConfig{EnableDhcp: common.Bool(true)}}
+	tests := []struct {
+		name      string
+		ip        string
+		bindings  []v1alpha1.PortAddressBinding
+		nsxSubnet *model.VpcSubnet
+		expected  []v1alpha1.PortAddressBinding
+		wantErr   bool
+	}{
+		{name: "no request", bindings: []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5"}}, nsxSubnet: dhcpSubnet,
+			expected: []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5"}}},
+		{name: "request", ip: "10.0.0.6", nsxSubnet: staticSubnet, expected: []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.6"}}},
+		{name: "same as bound", ip: "10.0.0.5", bindings: []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5"}}, nsxSubnet: staticSubnet,
+			expected: []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5"}}},
+		{name: "conflict with bound", ip: "10.0.0.6", bindings: []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5"}}, nsxSubnet: staticSubnet, wantErr: true},
+		{name: "invalid", ip: "10.0.0", nsxSubnet: staticSubnet, wantErr: true},
+		{name: "dhcp", ip: "10.0.0.6", nsxSubnet: dhcpSubnet, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &v1alpha1.SubnetPort{Spec: v1alpha1.SubnetPortSpec{AddressBindings: tt.bindings}}
+			if tt.ip != "" {
+				obj.Annotations = map[string]string{common.AnnotationIPAddress: tt.ip}
+			}
+			bindings, err := addressBindings(obj, tt.nsxSubnet)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expected, bindings)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package subnetport
+
+import (
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type SubnetPort model.SegmentPort
+
+type Comparable = common.Comparable
+
+func (port *SubnetPort) Key() string {
+	return *port.Id
+}
+
+// Value only compares the attachment fields set by NSX Operator, NSX fills in the others.
+func (port *SubnetPort) Value() data.DataValue {
+	p := &SubnetPort{
+		Id:              port.Id,
+		DisplayName:     port.DisplayName,
+		Tags:            port.Tags,
+		AddressBindings: port.AddressBindings,
+	}
+	if port.Attachment != nil {
+		p.Attachment = &model.PortAttachment{
+			Id:                port.Attachment.Id,
+			Type_:             port.Attachment.Type_,
+			AllocateAddresses: port.Attachment.AllocateAddresses,
+		}
+	}
+	dataValue, _ := ComparableToSubnetPort(p).GetDataValue__()
+	return dataValue
+}
+
+func SubnetPortToComparable(port *model.SegmentPort) Comparable {
+	return (*SubnetPort)(port)
+}
+
+func ComparableToSubnetPort(port Comparable) *model.SegmentPort {
+	return (*model.SegmentPort)(port.(*SubnetPort))
+}
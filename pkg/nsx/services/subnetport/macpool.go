@@ -0,0 +1,57 @@
+package subnetport
+
+import (
+	"errors"
+	"hash/fnv"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// indexKeyMACAddress indexes the NSX ports by the MACs bound to them, the MACs bound to the ports in the store are
+// not allocated again.
+const indexKeyMACAddress = "macAddress"
+
+var ErrMACPoolExhausted = errors.New("no MAC is available in the MAC pool")
+
+// allocateMAC returns the MAC allocated from the MAC pool for the SubnetPort CR. The MAC allocated before is kept,
+// otherwise the search starts from an offset derived from the CR UID, so that the same CR gets the same MAC as long
+// as it is free. It returns "" if no MAC pool is configured and NSX allocates the MAC.
+func (s *SubnetPortService) allocateMAC(uid types.UID) (string, error) {
+	ranges := s.NSXConfig.GetMACPoolRanges()
+	if len(ranges) == 0 {
+		return "", nil
+	}
+	if nsxPort := s.GetSubnetPortByCRUID(uid); nsxPort != nil {
+		if macs := filterTag(nsxPort.Tags, common.TagScopeSubnetPortMACAddress); len(macs) > 0 {
+			return macs[0], nil
+		}
+	}
+	var size uint64
+	for _, r := range ranges {
+		size += r.End - r.Start + 1
+	}
+	h := fnv.New64a()
+	h.Write([]byte(uid))
+	offset := h.Sum64() % size
+	for i := uint64(0); i < size; i++ {
+		mac := config.FormatMAC(macAt(ranges, (offset+i)%size))
+		if len(s.subnetPortStore.GetByIndex(indexKeyMACAddress, mac)) == 0 {
+			return mac, nil
+		}
+	}
+	return "", ErrMACPoolExhausted
+}
+
+// macAt returns the MAC at the offset of the MAC ranges taken as a whole.
+func macAt(ranges []config.MACRange, offset uint64) uint64 {
+	for _, r := range ranges {
+		if offset <= r.End-r.Start {
+			return r.Start + offset
+		}
+		offset -= r.End - r.Start + 1
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package subnetport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestMACAt(t *testing.T) {
+	ranges := []config.MACRange{{Start: 0x10, End: 0x11}, {Start: 0x20, End: 0x22}}
+	var macs []uint64
+	for i := uint64(0); i < 5; i++ {
+		macs = append(macs, macAt(ranges, i))
+	}
+	assert.Equal(t, []uint64{0x10, 0x11, 0x20, 0x21, 0x22}, macs)
+}
+
+func TestSubnetPortService_AllocateMAC(t *testing.T) {
+	s, portsClient := newFakeSubnetPortService()
+	nsxSubnet := &model.VpcSubnet{Path: String("/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1")}
+	newSubnetPort := func(uid types.UID) *v1alpha1.SubnetPort {
+		return &v1alpha1.SubnetPort{ObjectMeta: metav1.ObjectMeta{Name: string(uid), Namespace: "ns1", UID: uid}}
+	}
+
+	// NSX allocates the MAC without the MAC pool
+	nsxPort, err := s.CreateOrUpdateSubnetPort(newSubnetPort("uid1"), nsxSubnet, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, nsxPort.AddressBindings)
+	assert.Nil(t, s.DeleteSubnetPortsByCRUID("uid1"))
+
+	s.NSXConfig.MACPoolRanges = []string{"02:50:56:00:00:00-02:50:56:00:00:01", "02:50:56:00:01:00-02:50:56:00:01:00"}
+	mac, err := s.allocateMAC("uid1")
+	assert.Nil(t, err)
+	// the MAC is deterministic for the CR
+	again, err := s.allocateMAC("uid1")
+	assert.Nil(t, err)
+	assert.Equal(t, mac, again)
+
+	// the ports get distinct MACs from the pool
+	macs := map[string]types.UID{}
+	for _, uid := range []types.UID{"uid1", "uid2", "uid3"} {
+		nsxPort, err = s.CreateOrUpdateSubnetPort(newSubnetPort(uid), nsxSubnet, nil)
+		assert.Nil(t, err)
+		portMAC := *nsxPort.AddressBindings[0].MacAddress
+		assert.Equal(t, []string{portMAC}, filterTag(nsxPort.Tags, common.TagScopeSubnetPortMACAddress))
+		assert.Equal(t, model.PortAttachment_ALLOCATE_ADDRESSES_IP_POOL, *nsxPort.Attachment.AllocateAddresses)
+		macs[portMAC] = uid
+	}
+	assert.Equal(t, 3, len(macs))
+	assert.Equal(t, types.UID("uid1"), macs[mac])
+	assert.Equal(t, 3, len(portsClient.ports))
+
+	// the MAC allocated before is kept
+	nsxPort, err = s.CreateOrUpdateSubnetPort(newSubnetPort("uid1"), nsxSubnet, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, mac, *nsxPort.AddressBindings[0].MacAddress)
+
+	// the MAC bound in the spec is not allocated from the pool
+	obj := newSubnetPort("uid4")
+	obj.Spec.AddressBindings = []v1alpha1.PortAddressBinding{{MACAddress: "00:50:56:00:00:01"}}
+	nsxPort, err = s.CreateOrUpdateSubnetPort(obj, nsxSubnet, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(filterTag(nsxPort.Tags, common.TagScopeSubnetPortMACAddress)))
+
+	_, err = s.CreateOrUpdateSubnetPort(newSubnetPort("uid5"), nsxSubnet, nil)
+	assert.Equal(t, ErrMACPoolExhausted, err)
+
+	// the MAC is released with the port
+	assert.Nil(t, s.DeleteSubnetPortsByCRUID("uid1"))
+	nsxPort, err = s.CreateOrUpdateSubnetPort(newSubnetPort("uid5"), nsxSubnet, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, mac, *nsxPort.AddressBindings[0].MacAddress)
+}
@@ -0,0 +1,170 @@
+package subnetport
+
+import (
+	"fmt"
+
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+const (
+	// securityBindingMapID is the ID of the profile binding map of the port, a port has at most one.
+	securityBindingMapID = "default"
+)
+
+var enforceRevisionCheck = false
+
+// portSecurity is the effective security features of the port.
+type portSecurity struct {
+	spoofGuard      bool
+	dhcpServerBlock bool
+	raGuard         bool
+	bpduFilter      bool
+}
+
+// resolvePortSecurity merges the security features of the SubnetPort CR over the defaults of its Subnet, the features
+// set by neither follow the NSX defaults. nil is returned if neither configures security, the port is then left with
+// the NSX default profiles.
+func resolvePortSecurity(port, subnet *v1alpha1.PortSecurity) *portSecurity {
+	if port == nil && subnet == nil {
+		return nil
+	}
+	pick := func(get func(*v1alpha1.PortSecurity) *bool, defaultValue bool) bool {
+		for _, security := range []*v1alpha1.PortSecurity{port, subnet} {
+			if security != nil && get(security) != nil {
+				return *get(security)
+			}
+		}
+		return defaultValue
+	}
+	return &portSecurity{
+		spoofGuard:      pick(func(s *v1alpha1.PortSecurity) *bool { return s.SpoofGuard }, false),
+		dhcpServerBlock: pick(func(s *v1alpha1.PortSecurity) *bool { return s.DHCPServerBlock }, true),
+		raGuard:         pick(func(s *v1alpha1.PortSecurity) *bool { return s.RAGuard }, true),
+		bpduFilter:      pick(func(s *v1alpha1.PortSecurity) *bool { return s.BPDUFilter }, true),
+	}
+}
+
+func bit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// segmentSecurityProfileID returns the ID of the operator owned segment security profile of the features, the profiles
+// are shared by the ports of the cluster and named by the DHCP server block, RA guard and BPDU filter bits.
+func (s *SubnetPortService) segmentSecurityProfileID(security *portSecurity) string {
+	return fmt.Sprintf("%s_ssp_%d%d%d", s.NSXConfig.Cluster, bit(security.dhcpServerBlock), bit(security.raGuard), bit(security.bpduFilter))
+}
+
+// spoofGuardProfileID returns the ID of the operator owned SpoofGuard profile of the features.
+func (s *SubnetPortService) spoofGuardProfileID(security *portSecurity) string {
+	return fmt.Sprintf("%s_sg_%d", s.NSXConfig.Cluster, bit(security.spoofGuard))
+}
+
+// securityTag returns the value of the tag recording the profiles bound to the port.
+func (s *SubnetPortService) securityTag(security *portSecurity) string {
+	return fmt.Sprintf("%s,%s", s.segmentSecurityProfileID(security), s.spoofGuardProfileID(security))
+}
+
+// ensureSecurityProfiles creates the profiles of the features in the NSX Project and returns their paths, the profiles
+// created since start are not patched again.
+func (s *SubnetPortService) ensureSecurityProfiles(org, project string, security *portSecurity) (string, string, error) {
+	s.profileLock.Lock()
+	defer s.profileLock.Unlock()
+	tags := []model.Tag{{Scope: String(common.TagScopeCluster), Tag: String(s.NSXConfig.Cluster)}}
+
+	sspID := s.segmentSecurityProfileID(security)
+	sspPath := fmt.Sprintf("/orgs/%s/projects/%s/infra/segment-security-profiles/%s", org, project, sspID)
+	if !s.securityProfiles.Has(sspPath) {
+		profile := model.SegmentSecurityProfile{
+			Id:                       String(sspID),
+			DisplayName:              String(sspID),
+			DhcpServerBlockEnabled:   Bool(security.dhcpServerBlock),
+			DhcpServerBlockV6Enabled: Bool(security.dhcpServerBlock),
+			RaGuardEnabled:           Bool(security.raGuard),
+			BpduFilterEnable:         Bool(security.bpduFilter),
+			Tags:                     tags,
+		}
+		if err := s.NSXClient.SegmentSecurityProfileClient.Patch(org, project, sspID, profile, nil); err != nil {
+			return "", "", err
+		}
+		s.securityProfiles.Insert(sspPath)
+	}
+
+	sgID := s.spoofGuardProfileID(security)
+	sgPath := fmt.Sprintf("/orgs/%s/projects/%s/infra/spoofguard-profiles/%s", org, project, sgID)
+	if !s.securityProfiles.Has(sgPath) {
+		profile := model.SpoofGuardProfile{
+			Id:                      String(sgID),
+			DisplayName:             String(sgID),
+			AddressBindingAllowlist: Bool(security.spoofGuard),
+			Tags:                    tags,
+		}
+		if err := s.NSXClient.SpoofGuardProfileClient.Patch(org, project, sgID, profile, nil); err != nil {
+			return "", "", err
+		}
+		s.securityProfiles.Insert(sgPath)
+	}
+	return sspPath, sgPath, nil
+}
+
+// patchSecurityBinding binds the profiles to the NSX port, or removes the binding if markedForDelete is true. There is
+// no binding map API for VPC Subnet ports, so it is patched with OrgRoot API.
+func (s *SubnetPortService) patchSecurityBinding(nsxPort *model.SegmentPort, sspPath, sgPath string, markedForDelete bool) error {
+	org, project, vpcID, subnetID, err := parseSubnetPortPath(*nsxPort.Path)
+	if err != nil {
+		return err
+	}
+	bindingMap := &model.PortSecurityProfileBindingMap{
+		Id:              String(securityBindingMapID),
+		ResourceType:    String("PortSecurityProfileBindingMap"),
+		MarkedForDelete: Bool(markedForDelete),
+	}
+	if !markedForDelete {
+		bindingMap.SegmentSecurityProfilePath = String(sspPath)
+		bindingMap.SpoofguardProfilePath = String(sgPath)
+	}
+	childBindingMap := model.ChildPortSecurityProfileBindingMap{
+		ResourceType:                  "ChildPortSecurityProfileBindingMap",
+		PortSecurityProfileBindingMap: bindingMap,
+	}
+	dataValue, errs := common.NewConverter().ConvertToVapi(childBindingMap, model.ChildPortSecurityProfileBindingMapBindingType())
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	children := []*data.StructValue{dataValue.(*data.StructValue)}
+	targets := []struct{ targetType, id string }{
+		{"VpcSubnetPort", *nsxPort.Id},
+		{"VpcSubnet", subnetID},
+		{"Vpc", vpcID},
+		{"Project", project},
+		{"Org", org},
+	}
+	for _, target := range targets {
+		childReference := model.ChildResourceReference{
+			Id:           String(target.id),
+			ResourceType: "ChildResourceReference",
+			TargetType:   String(target.targetType),
+			Children:     children,
+		}
+		dataValue, errs := common.NewConverter().ConvertToVapi(childReference, model.ChildResourceReferenceBindingType())
+		if len(errs) > 0 {
+			return errs[0]
+		}
+		children = []*data.StructValue{dataValue.(*data.StructValue)}
+	}
+	orgRoot := model.OrgRoot{
+		Children:     children,
+		ResourceType: String("OrgRoot"),
+	}
+	if err := s.NSXClient.OrgRootClient.Patch(orgRoot, &enforceRevisionCheck); err != nil {
+		return err
+	}
+	log.Info("patched security profile binding of SubnetPort", "SubnetPort.Id", *nsxPort.Id, "markedForDelete", markedForDelete)
+	return nil
+}
@@ -0,0 +1,157 @@
+package subnetport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	nsx_policy "github.com/vmware/vsphere-automation-sdk-go/services/nsxt"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	project_infra "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type fakeSegmentSecurityProfileClient struct {
+	project_infra.SegmentSecurityProfilesClient
+	profiles map[string]model.SegmentSecurityProfile
+	patched  int
+}
+
+func (c *fakeSegmentSecurityProfileClient) Patch(_ string, _ string, id string, profile model.SegmentSecurityProfile, _ *bool) error {
+	c.patched++
+	c.profiles[id] = profile
+	return nil
+}
+
+type fakeSpoofGuardProfileClient struct {
+	project_infra.SpoofguardProfilesClient
+	profiles map[string]model.SpoofGuardProfile
+	patched  int
+}
+
+func (c *fakeSpoofGuardProfileClient) Patch(_ string, _ string, id string, profile model.SpoofGuardProfile, _ *bool) error {
+	c.patched++
+	c.profiles[id] = profile
+	return nil
+}
+
+type fakeOrgRootClient struct {
+	nsx_policy.OrgRootClient
+	orgRoots []model.OrgRoot
+}
+
+func (c *fakeOrgRootClient) Patch(orgRoot model.OrgRoot, _ *bool) error {
+	c.orgRoots = append(c.orgRoots, orgRoot)
+	return nil
+}
+
+// bindingMapOf unwraps the binding map from the OrgRoot and checks the references to the port.
+func bindingMapOf(t *testing.T, orgRoot model.OrgRoot) *model.PortSecurityProfileBindingMap {
+	children := orgRoot.Children
+	for _, targetType := range []string{"Org", "Project", "Vpc", "VpcSubnet", "VpcSubnetPort"} {
+		require.Len(t, children, 1)
+		obj, errs := common.NewConverter().ConvertToGolang(children[0], model.ChildResourceReferenceBindingType())
+		require.Empty(t, errs)
+		reference := obj.(model.ChildResourceReference)
+		assert.Equal(t, targetType, *reference.TargetType)
+		children = reference.Children
+	}
+	require.Len(t, children, 1)
+	obj, errs := common.NewConverter().ConvertToGolang(children[0], model.ChildPortSecurityProfileBindingMapBindingType())
+	require.Empty(t, errs)
+	return obj.(model.ChildPortSecurityProfileBindingMap).PortSecurityProfileBindingMap
+}
+
+func TestResolvePortSecurity(t *testing.T) {
+	on, off := Bool(true), Bool(false)
+	tests := []struct {
+		name     string
+		port     *v1alpha1.PortSecurity
+		subnet   *v1alpha1.PortSecurity
+		expected *portSecurity
+	}{
+		{name: "not configured"},
+		{
+			name:     "NSX defaults",
+			port:     &v1alpha1.PortSecurity{},
+			expected: &portSecurity{dhcpServerBlock: true, raGuard: true, bpduFilter: true},
+		},
+		{
+			name:     "subnet defaults",
+			subnet:   &v1alpha1.PortSecurity{SpoofGuard: on, RAGuard: off},
+			expected: &portSecurity{spoofGuard: true, dhcpServerBlock: true, bpduFilter: true},
+		},
+		{
+			name:     "port overrides subnet",
+			port:     &v1alpha1.PortSecurity{SpoofGuard: off, BPDUFilter: off},
+			subnet:   &v1alpha1.PortSecurity{SpoofGuard: on, DHCPServerBlock: off},
+			expected: &portSecurity{raGuard: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolvePortSecurity(tt.port, tt.subnet))
+		})
+	}
+}
+
+func TestSubnetPortService_SecurityProfiles(t *testing.T) {
+	s, portsClient := newFakeSubnetPortService()
+	sspClient := &fakeSegmentSecurityProfileClient{profiles: map[string]model.SegmentSecurityProfile{}}
+	sgClient := &fakeSpoofGuardProfileClient{profiles: map[string]model.SpoofGuardProfile{}}
+	orgRootClient := &fakeOrgRootClient{}
+	s.NSXClient.SegmentSecurityProfileClient = sspClient
+	s.NSXClient.SpoofGuardProfileClient = sgClient
+	s.NSXClient.OrgRootClient = orgRootClient
+	nsxSubnet := &model.VpcSubnet{Path: String("/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1")}
+
+	// the port without security is left with the NSX default profiles
+	obj := &v1alpha1.SubnetPort{ObjectMeta: metav1.ObjectMeta{Name: "port1", Namespace: "ns1", UID: "uid1"}}
+	nsxPort, err := s.CreateOrUpdateSubnetPort(obj, nsxSubnet, nil)
+	require.Nil(t, err)
+	assert.Empty(t, filterTag(nsxPort.Tags, common.TagScopeSubnetPortSecurity))
+	assert.Empty(t, orgRootClient.orgRoots)
+
+	// the Subnet defaults enable SpoofGuard
+	nsxPort, err = s.CreateOrUpdateSubnetPort(obj, nsxSubnet, &v1alpha1.PortSecurity{SpoofGuard: Bool(true)})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"k8scl-one_ssp_111,k8scl-one_sg_1"}, filterTag(nsxPort.Tags, common.TagScopeSubnetPortSecurity))
+	assert.True(t, *sgClient.profiles["k8scl-one_sg_1"].AddressBindingAllowlist)
+	ssp := sspClient.profiles["k8scl-one_ssp_111"]
+	assert.True(t, *ssp.DhcpServerBlockEnabled)
+	assert.True(t, *ssp.RaGuardEnabled)
+	assert.True(t, *ssp.BpduFilterEnable)
+	require.Len(t, orgRootClient.orgRoots, 1)
+	bindingMap := bindingMapOf(t, orgRootClient.orgRoots[0])
+	assert.False(t, *bindingMap.MarkedForDelete)
+	assert.Equal(t, "/orgs/default/projects/p1/infra/segment-security-profiles/k8scl-one_ssp_111", *bindingMap.SegmentSecurityProfilePath)
+	assert.Equal(t, "/orgs/default/projects/p1/infra/spoofguard-profiles/k8scl-one_sg_1", *bindingMap.SpoofguardProfilePath)
+
+	// another port shares the profiles without patching them again
+	obj2 := &v1alpha1.SubnetPort{
+		ObjectMeta: metav1.ObjectMeta{Name: "port2", Namespace: "ns1", UID: "uid2"},
+		Spec:       v1alpha1.SubnetPortSpec{Security: &v1alpha1.PortSecurity{SpoofGuard: Bool(true)}},
+	}
+	_, err = s.CreateOrUpdateSubnetPort(obj2, nsxSubnet, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 1, sspClient.patched)
+	assert.Equal(t, 1, sgClient.patched)
+	assert.Len(t, orgRootClient.orgRoots, 2)
+
+	// the unchanged port is not bound again
+	patched := portsClient.patched
+	_, err = s.CreateOrUpdateSubnetPort(obj2, nsxSubnet, nil)
+	require.Nil(t, err)
+	assert.Equal(t, patched, portsClient.patched)
+	assert.Len(t, orgRootClient.orgRoots, 2)
+
+	// the binding is removed once security is no longer configured
+	nsxPort, err = s.CreateOrUpdateSubnetPort(obj, nsxSubnet, nil)
+	require.Nil(t, err)
+	assert.Empty(t, filterTag(nsxPort.Tags, common.TagScopeSubnetPortSecurity))
+	require.Len(t, orgRootClient.orgRoots, 3)
+	assert.True(t, *bindingMapOf(t, orgRootClient.orgRoots[2]).MarkedForDelete)
+}
@@ -0,0 +1,91 @@
+package subnetport
+
+import (
+	"errors"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+// keyFunc is used to get the key of a resource, usually, which is the ID of the resource
+func keyFunc(obj interface{}) (string, error) {
+	switch v := obj.(type) {
+	case model.SegmentPort:
+		return *v.Id, nil
+	default:
+		return "", errors.New("keyFunc doesn't support unknown type")
+	}
+}
+
+// subnetPortIndexFunc indexes the NSX ports by the UID of the SubnetPort CR they are created for.
+func subnetPortIndexFunc(obj interface{}) ([]string, error) {
+	switch o := obj.(type) {
+	case model.SegmentPort:
+		return filterTag(o.Tags, common.TagScopeSubnetPortCRUID), nil
+	default:
+		return nil, errors.New("subnetPortIndexFunc doesn't support unknown type")
+	}
+}
+
+// macAddressIndexFunc indexes the NSX ports by the MACs bound to them.
+func macAddressIndexFunc(obj interface{}) ([]string, error) {
+	switch o := obj.(type) {
+	case model.SegmentPort:
+		var macs []string
+		for _, binding := range o.AddressBindings {
+			if binding.MacAddress == nil {
+				continue
+			}
+			if mac, err := config.ParseMAC(*binding.MacAddress); err == nil {
+				macs = append(macs, config.FormatMAC(mac))
+			}
+		}
+		return macs, nil
+	default:
+		return nil, errors.New("macAddressIndexFunc doesn't support unknown type")
+	}
+}
+
+func filterTag(tags []model.Tag, tagScope string) []string {
+	res := make([]string, 0, 5)
+	for _, tag := range tags {
+		if *tag.Scope == tagScope {
+			res = append(res, *tag.Tag)
+		}
+	}
+	return res
+}
+
+// SubnetPortStore is a store for NSX VPC Subnet ports
+type SubnetPortStore struct {
+	common.ResourceStore
+}
+
+func (subnetPortStore *SubnetPortStore) Operate(i interface{}) error {
+	if i == nil {
+		return nil
+	}
+	port := i.(*model.SegmentPort)
+	if port.MarkedForDelete != nil && *port.MarkedForDelete {
+		if err := subnetPortStore.Delete(*port); err != nil {
+			return err
+		}
+		log.V(1).Info("delete SubnetPort from store", "SubnetPort", port)
+	} else {
+		if err := subnetPortStore.Add(*port); err != nil {
+			return err
+		}
+		log.V(1).Info("add SubnetPort to store", "SubnetPort", port)
+	}
+	return nil
+}
+
+func (subnetPortStore *SubnetPortStore) GetByIndex(key string, value string) []model.SegmentPort {
+	ports := make([]model.SegmentPort, 0)
+	for _, port := range subnetPortStore.ResourceStore.GetByIndex(key, value) {
+		ports = append(ports, port.(model.SegmentPort))
+	}
+	return ports
+}
@@ -0,0 +1,54 @@
+package subnetport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func newSubnetPortStore() *SubnetPortStore {
+	return &SubnetPortStore{ResourceStore: common.ResourceStore{
+		Indexer: cache.NewIndexer(keyFunc, cache.Indexers{
+			common.TagScopeSubnetPortCRUID: subnetPortIndexFunc,
+			indexKeyMACAddress:             macAddressIndexFunc,
+		}),
+		BindingType: model.SegmentPortBindingType(),
+	}}
+}
+
+func TestSubnetPortStore_Operate(t *testing.T) {
+	subnetPortStore := newSubnetPortStore()
+	port1 := model.SegmentPort{
+		Id:   String("port_uid1"),
+		Tags: []model.Tag{{Scope: String(common.TagScopeSubnetPortCRUID), Tag: String("uid1")}},
+	}
+	port2 := model.SegmentPort{
+		Id:              String("port_uid2"),
+		Tags:            []model.Tag{{Scope: String(common.TagScopeSubnetPortCRUID), Tag: String("uid2")}},
+		AddressBindings: []model.PortAddressBindingEntry{{MacAddress: String("02:50:56:00:00:0A")}},
+	}
+	assert.Nil(t, subnetPortStore.Operate(&port1))
+	assert.Nil(t, subnetPortStore.Operate(&port2))
+	assert.Nil(t, subnetPortStore.Operate(nil))
+
+	assert.Equal(t, []model.SegmentPort{port1}, subnetPortStore.GetByIndex(common.TagScopeSubnetPortCRUID, "uid1"))
+	assert.Equal(t, 0, len(subnetPortStore.GetByIndex(common.TagScopeSubnetPortCRUID, "uid3")))
+	// the MACs are indexed in the canonical form
+	assert.Equal(t, []model.SegmentPort{port2}, subnetPortStore.GetByIndex(indexKeyMACAddress, "02:50:56:00:00:0a"))
+
+	port1.MarkedForDelete = &MarkedForDelete
+	assert.Nil(t, subnetPortStore.Operate(&port1))
+	assert.Equal(t, 0, len(subnetPortStore.GetByIndex(common.TagScopeSubnetPortCRUID, "uid1")))
+	assert.Equal(t, 1, len(subnetPortStore.List()))
+
+	_, err := keyFunc(model.VpcSubnet{})
+	assert.NotNil(t, err)
+	_, err = subnetPortIndexFunc(model.VpcSubnet{})
+	assert.NotNil(t, err)
+	_, err = macAddressIndexFunc(model.VpcSubnet{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,179 @@
+package subnetport
+
+import (
+	"sync"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+var (
+	log                    = logger.Log
+	ResourceTypeSubnetPort = common.ResourceTypeSubnetPort
+	MarkedForDelete        = true
+)
+
+type SubnetPortService struct {
+	common.Service
+	subnetPortStore *SubnetPortStore
+	// macLock serializes the MAC allocations until the allocated MACs are added to the store
+	macLock sync.Mutex
+	// securityProfiles is the paths of the security profiles patched since start
+	securityProfiles sets.String
+	profileLock      sync.Mutex
+}
+
+// InitializeSubnetPort sync NSX resources
+func InitializeSubnetPort(service common.Service) (*SubnetPortService, error) {
+	wg := sync.WaitGroup{}
+	wgDone := make(chan bool)
+	fatalErrors := make(chan error)
+
+	wg.Add(1)
+
+	subnetPortService := &SubnetPortService{Service: service, securityProfiles: sets.NewString()}
+
+	subnetPortService.subnetPortStore = &SubnetPortStore{ResourceStore: common.ResourceStore{
+		Indexer: common.NewIndexer(keyFunc, cache.Indexers{
+			common.TagScopeSubnetPortCRUID: subnetPortIndexFunc,
+			indexKeyMACAddress:             macAddressIndexFunc,
+		}),
+		BindingType: model.SegmentPortBindingType(),
+	}}
+
+	go subnetPortService.InitializeResourceStore(&wg, fatalErrors, ResourceTypeSubnetPort, subnetPortService.subnetPortStore)
+
+	go func() {
+		wg.Wait()
+		close(wgDone)
+	}()
+
+	select {
+	case <-wgDone:
+		break
+	case err := <-fatalErrors:
+		close(fatalErrors)
+		return subnetPortService, err
+	}
+
+	return subnetPortService, nil
+}
+
+// CreateOrUpdateSubnetPort creates or updates the NSX port of the SubnetPort CR in the NSX Subnet, subnetSecurity is
+// the default security features of the Subnet.
+func (s *SubnetPortService) CreateOrUpdateSubnetPort(obj *v1alpha1.SubnetPort, nsxSubnet *model.VpcSubnet, subnetSecurity *v1alpha1.PortSecurity) (*model.SegmentPort, error) {
+	if len(s.NSXConfig.GetMACPoolRanges()) > 0 {
+		s.macLock.Lock()
+		defer s.macLock.Unlock()
+	}
+	security := resolvePortSecurity(obj.Spec.Security, subnetSecurity)
+	nsxPort, err := s.buildSubnetPort(obj, nsxSubnet, security)
+	if err != nil {
+		return nil, err
+	}
+	secured := false
+	if existing := s.subnetPortStore.GetByKey(*nsxPort.Id); existing != nil {
+		existingPort := existing.(model.SegmentPort)
+		if !common.CompareResource(SubnetPortToComparable(&existingPort), SubnetPortToComparable(nsxPort)) {
+			log.Info("SubnetPort is not changed, skip updating it", "SubnetPort.Id", *nsxPort.Id)
+			return &existingPort, nil
+		}
+		secured = len(filterTag(existingPort.Tags, common.TagScopeSubnetPortSecurity)) > 0
+	}
+	org, project, vpcID, subnetID, err := parseSubnetPortPath(*nsxPort.Path)
+	if err != nil {
+		return nil, err
+	}
+	var sspPath, sgPath string
+	if security != nil {
+		if sspPath, sgPath, err = s.ensureSecurityProfiles(org, project, security); err != nil {
+			return nil, err
+		}
+	}
+	// the ports are updated on the label churn of their Pods, the successive updates are coalesced by the write queue
+	if err := s.WriteQueue.Write(*nsxPort.Path, func() error {
+		return s.NSXClient.PortClient.Patch(org, project, vpcID, subnetID, *nsxPort.Id, *nsxPort)
+	}); err != nil {
+		return nil, err
+	}
+	if security != nil || secured {
+		if err := s.patchSecurityBinding(nsxPort, sspPath, sgPath, security == nil); err != nil {
+			return nil, err
+		}
+	}
+	realized, err := s.NSXClient.PortClient.Get(org, project, vpcID, subnetID, *nsxPort.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.subnetPortStore.Operate(&realized); err != nil {
+		return nil, err
+	}
+	log.Info("successfully created or updated SubnetPort", "SubnetPort", realized)
+	return &realized, nil
+}
+
+// ValidateSubnetPort checks the addresses of the SubnetPort CR can be bound to the port in the NSX Subnet.
+func (s *SubnetPortService) ValidateSubnetPort(obj *v1alpha1.SubnetPort, nsxSubnet *model.VpcSubnet) error {
+	_, err := addressBindings(obj, nsxSubnet)
+	return err
+}
+
+// GetSubnetPortState reads the attachment state and the realized address bindings of the NSX port.
+func (s *SubnetPortService) GetSubnetPortState(nsxPort *model.SegmentPort) (*model.SegmentPortState, error) {
+	org, project, vpcID, subnetID, err := parseSubnetPortPath(*nsxPort.Path)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.NSXClient.PortStateClient.Get(org, project, vpcID, subnetID, *nsxPort.Id, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// DeleteSubnetPort deletes the NSX port and removes it from the store.
+func (s *SubnetPortService) DeleteSubnetPort(nsxPort model.SegmentPort) error {
+	org, project, vpcID, subnetID, err := parseSubnetPortPath(*nsxPort.Path)
+	if err != nil {
+		return err
+	}
+	if err := s.NSXClient.PortClient.Delete(org, project, vpcID, subnetID, *nsxPort.Id); err != nil {
+		return err
+	}
+	nsxPort.MarkedForDelete = &MarkedForDelete
+	if err := s.subnetPortStore.Operate(&nsxPort); err != nil {
+		return err
+	}
+	log.Info("successfully deleted SubnetPort", "SubnetPort", nsxPort)
+	return nil
+}
+
+// DeleteSubnetPortsByCRUID deletes the NSX ports created for the SubnetPort CR.
+func (s *SubnetPortService) DeleteSubnetPortsByCRUID(uid types.UID) error {
+	for _, nsxPort := range s.subnetPortStore.GetByIndex(common.TagScopeSubnetPortCRUID, string(uid)) {
+		if err := s.DeleteSubnetPort(nsxPort); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSubnetPortByCRUID returns the NSX port of the SubnetPort CR, or nil if it is not created yet.
+func (s *SubnetPortService) GetSubnetPortByCRUID(uid types.UID) *model.SegmentPort {
+	nsxPorts := s.subnetPortStore.GetByIndex(common.TagScopeSubnetPortCRUID, string(uid))
+	if len(nsxPorts) == 0 {
+		return nil
+	}
+	return &nsxPorts[0]
+}
+
+// ListSubnetPortCRUID returns the UIDs of the SubnetPort CRs which have NSX ports.
+func (s *SubnetPortService) ListSubnetPortCRUID() sets.String {
+	return s.subnetPortStore.ListIndexFuncValues(common.TagScopeSubnetPortCRUID)
+}
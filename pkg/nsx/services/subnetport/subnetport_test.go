@@ -0,0 +1,93 @@
+package subnetport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/subnets/ports"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type fakePortsClient struct {
+	subnets.PortsClient
+	ports   map[string]model.SegmentPort
+	patched int
+}
+
+func (c *fakePortsClient) Patch(_ string, _ string, _ string, _ string, id string, port model.SegmentPort) error {
+	c.patched++
+	c.ports[id] = port
+	return nil
+}
+
+func (c *fakePortsClient) Get(_ string, _ string, _ string, _ string, id string) (model.SegmentPort, error) {
+	return c.ports[id], nil
+}
+
+func (c *fakePortsClient) Delete(_ string, _ string, _ string, _ string, id string) error {
+	delete(c.ports, id)
+	return nil
+}
+
+type fakePortStateClient struct {
+	ports.StateClient
+}
+
+func (c *fakePortStateClient) Get(_ string, _ string, _ string, _ string, id string, _ *string, _ *string) (model.SegmentPortState, error) {
+	return model.SegmentPortState{
+		Attachment: &model.SegmentPortAttachmentState{Id: String(id), State: String(model.SegmentPortAttachmentState_STATE_ATTACHED)},
+	}, nil
+}
+
+func newFakeSubnetPortService() (*SubnetPortService, *fakePortsClient) {
+	portsClient := &fakePortsClient{ports: map[string]model.SegmentPort{}}
+	return &SubnetPortService{
+		Service: common.Service{
+			NSXClient: &nsx.Client{PortClient: portsClient, PortStateClient: &fakePortStateClient{}},
+			NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}},
+		},
+		subnetPortStore:  newSubnetPortStore(),
+		securityProfiles: sets.NewString(),
+	}, portsClient
+}
+
+func TestSubnetPortService_CreateOrUpdateAndDeleteSubnetPort(t *testing.T) {
+	s, portsClient := newFakeSubnetPortService()
+	obj := &v1alpha1.SubnetPort{ObjectMeta: metav1.ObjectMeta{Name: "port1", Namespace: "ns1", UID: "uid1"}}
+	nsxSubnet := &model.VpcSubnet{Path: String("/orgs/default/projects/p1/vpcs/vpc1/subnets/subnet1")}
+
+	nsxPort, err := s.CreateOrUpdateSubnetPort(obj, nsxSubnet, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "port_uid1", *nsxPort.Id)
+	assert.Equal(t, 1, portsClient.patched)
+	assert.Equal(t, nsxPort, s.GetSubnetPortByCRUID("uid1"))
+	assert.Equal(t, []string{"uid1"}, s.ListSubnetPortCRUID().List())
+
+	// the unchanged port is not patched again
+	_, err = s.CreateOrUpdateSubnetPort(obj, nsxSubnet, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, portsClient.patched)
+
+	// the port is patched with the new address binding
+	obj.Spec.AddressBindings = []v1alpha1.PortAddressBinding{{IPAddress: "10.0.0.5"}}
+	nsxPort, err = s.CreateOrUpdateSubnetPort(obj, nsxSubnet, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, portsClient.patched)
+	assert.Equal(t, "10.0.0.5", *nsxPort.AddressBindings[0].IpAddress)
+
+	state, err := s.GetSubnetPortState(nsxPort)
+	assert.Nil(t, err)
+	assert.Equal(t, model.SegmentPortAttachmentState_STATE_ATTACHED, *state.Attachment.State)
+
+	assert.Nil(t, s.DeleteSubnetPortsByCRUID("uid1"))
+	assert.Equal(t, 0, len(portsClient.ports))
+	assert.Nil(t, s.GetSubnetPortByCRUID("uid1"))
+}
@@ -0,0 +1,199 @@
+package vpc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+const (
+	// ConnectivityProfileIsolated VPCs have no service gateway, workloads can only talk inside the VPC.
+	ConnectivityProfileIsolated = "isolated"
+	// ConnectivityProfilePrivate VPCs reach the outside through the Tier0 gateway with SNAT.
+	ConnectivityProfilePrivate = "private"
+	// ConnectivityProfilePublic VPCs are routed to the Tier0 gateway without SNAT.
+	ConnectivityProfilePublic  = "public"
+	DefaultConnectivityProfile = ConnectivityProfilePrivate
+
+	// defaultSecurityPolicyID is the VPC security policy holding the default rule of the connectivity profile.
+	defaultSecurityPolicyID = "default_policy"
+	defaultRuleID           = "default_rule"
+	// defaultSecurityPolicySequence keeps the default rule after the security policies of the VPC.
+	defaultSecurityPolicySequence = int64(1000000)
+)
+
+var (
+	String = common.String
+	Int64  = common.Int64
+	Bool   = func(b bool) *bool { return &b }
+
+	// builtinConnectivityProfiles are overridden by the profiles with the same names defined in the config.
+	builtinConnectivityProfiles = map[string]*config.VPCConnectivityProfile{
+		ConnectivityProfileIsolated: {Name: ConnectivityProfileIsolated},
+		ConnectivityProfilePrivate:  {Name: ConnectivityProfilePrivate, ExternalConnectivity: true, EnableSNAT: true},
+		ConnectivityProfilePublic:   {Name: ConnectivityProfilePublic, ExternalConnectivity: true},
+	}
+)
+
+// GetConnectivityProfileName returns the name of the VPC connectivity profile selected by the Namespace,
+// the annotation takes precedence over the label.
+func GetConnectivityProfileName(ns *v1.Namespace) string {
+	profile, ok := ns.Annotations[common.AnnotationVPCConnectivityProfile]
+	if !ok {
+		profile, ok = ns.Labels[common.AnnotationVPCConnectivityProfile]
+	}
+	if !ok || profile == "" {
+		return DefaultConnectivityProfile
+	}
+	return profile
+}
+
+// GetConnectivityProfile returns the VPC connectivity profile selected by the Namespace,
+// which is either defined in the config or a builtin one.
+func (s *VPCService) GetConnectivityProfile(ns *v1.Namespace) (*config.VPCConnectivityProfile, error) {
+	name := GetConnectivityProfileName(ns)
+	if profile, ok := LookupConnectivityProfile(s.NSXConfig, name); ok {
+		return profile, nil
+	}
+	return nil, nsxutil.RestrictionError{Desc: fmt.Sprintf("unsupported VPC connectivity profile %q of Namespace %s", name, ns.Name)}
+}
+
+// LookupConnectivityProfile returns the VPC connectivity profile of the name defined in the config, or the builtin
+// one, and whether there is one.
+func LookupConnectivityProfile(cf *config.NSXOperatorConfig, name string) (*config.VPCConnectivityProfile, bool) {
+	if profile, ok := cf.VPCConnectivityProfiles[name]; ok {
+		return profile, true
+	}
+	profile, ok := builtinConnectivityProfiles[name]
+	return profile, ok
+}
+
+// GetVPCNetworkConfigName returns the name of the VPCNetworkConfiguration used by the Namespace.
+func GetVPCNetworkConfigName(ns *v1.Namespace) string {
+	if name := ns.Annotations[common.AnnotationVPCNetworkConfig]; name != "" {
+		return name
+	}
+	return common.DefaultVPCNetworkConfigName
+}
+
+func buildVPCPath(org, project, id string) string {
+	return fmt.Sprintf("%s/vpcs/%s", common.BuildProjectPath(org, project), id)
+}
+
+// getProject returns the NSX Project of the VPCs of the VPCNetworkConfiguration. The Project NSX Operator runs in
+// is used if the VPCNetworkConfiguration doesn't set one, and the VPCs can't be created in the other Projects.
+func (s *VPCService) getProject(nc *v1alpha1.VPCNetworkConfiguration) (string, error) {
+	project := nc.Spec.NSXTProject
+	if !s.NSXConfig.InProject() {
+		if project == "" {
+			return "", nsxutil.RestrictionError{Desc: fmt.Sprintf("NSX-T Project is not set in VPCNetworkConfiguration %s", nc.Name)}
+		}
+		return project, nil
+	}
+	if project == "" {
+		return s.NSXConfig.NsxProject, nil
+	}
+	if project != s.NSXConfig.NsxProject {
+		return "", nsxutil.RestrictionError{Desc: fmt.Sprintf("NSX-T Project %s of VPCNetworkConfiguration %s is not the Project %s of NSX Operator", project, nc.Name, s.NSXConfig.NsxProject)}
+	}
+	return project, nil
+}
+
+func (s *VPCService) buildNSXVPC(obj *v1alpha1.VPC, nc *v1alpha1.VPCNetworkConfiguration, profile *config.VPCConnectivityProfile) (*model.Vpc, error) {
+	project, err := s.getProject(nc)
+	if err != nil {
+		return nil, err
+	}
+	id := fmt.Sprintf("vpc_%s", obj.UID)
+	nsxVPC := &model.Vpc{
+		Id:                String(id),
+		DisplayName:       String(fmt.Sprintf("%s-%s", obj.Namespace, obj.Name)),
+		Path:              String(buildVPCPath(s.NSXConfig.GetNsxOrg(), project, id)),
+		PrivateIpv4Blocks: nc.Spec.PrivateIPv4CIDRs,
+		Tags:              s.buildBasicTags(obj, profile),
+	}
+	if !profile.ExternalConnectivity {
+		nsxVPC.ServiceGateway = &model.ServiceGateway{Disable: Bool(true)}
+		return nsxVPC, nil
+	}
+	if nc.Spec.DefaultGatewayPath == "" {
+		return nil, nsxutil.RestrictionError{Desc: fmt.Sprintf("default gateway is required by VPC connectivity profile %s but not set in VPCNetworkConfiguration %s", profile.Name, nc.Name)}
+	}
+	nsxVPC.DefaultGatewayPath = String(nc.Spec.DefaultGatewayPath)
+	nsxVPC.PublicIpv4Blocks = nc.Spec.ExternalIPv4Blocks
+	nsxVPC.ServiceGateway = &model.ServiceGateway{
+		Disable:  Bool(false),
+		AutoSnat: Bool(profile.EnableSNAT),
+	}
+	return nsxVPC, nil
+}
+
+// buildDefaultSecurityPolicy builds the VPC security policy with the default rule of the connectivity profile,
+// it returns nil if the profile has no default rule.
+func (s *VPCService) buildDefaultSecurityPolicy(obj *v1alpha1.VPC, profile *config.VPCConnectivityProfile) *model.SecurityPolicy {
+	if profile.DefaultRuleAction == "" {
+		return nil
+	}
+	tags := s.buildBasicTags(obj, profile)
+	return &model.SecurityPolicy{
+		Id:             String(defaultSecurityPolicyID),
+		DisplayName:    String(fmt.Sprintf("%s-default", profile.Name)),
+		Category:       String("Application"),
+		SequenceNumber: Int64(defaultSecurityPolicySequence),
+		Rules: []model.Rule{
+			{
+				Id:                String(defaultRuleID),
+				DisplayName:       String(fmt.Sprintf("%s-default-%s", profile.Name, strings.ToLower(profile.DefaultRuleAction))),
+				Action:            String(profile.DefaultRuleAction),
+				Direction:         String(model.Rule_DIRECTION_IN_OUT),
+				SourceGroups:      []string{"ANY"},
+				DestinationGroups: []string{"ANY"},
+				Services:          []string{"ANY"},
+				SequenceNumber:    Int64(0),
+				Tags:              tags,
+			},
+		},
+		Tags: tags,
+	}
+}
+
+func (s *VPCService) buildBasicTags(obj *v1alpha1.VPC, profile *config.VPCConnectivityProfile) []model.Tag {
+	tags := []model.Tag{
+		{
+			Scope: String(common.TagScopeCluster),
+			Tag:   String(s.NSXConfig.Cluster),
+		},
+		{
+			Scope: String(common.TagScopeNamespace),
+			Tag:   String(obj.Namespace),
+		},
+		{
+			Scope: String(common.TagScopeVPCCRName),
+			Tag:   String(obj.Name),
+		},
+		{
+			Scope: String(common.TagScopeVPCCRUID),
+			Tag:   String(string(obj.UID)),
+		},
+		{
+			Scope: String(common.TagScopeVPCConnectivityProfile),
+			Tag:   String(profile.Name),
+		},
+	}
+	// the default rule action is tagged so that the VPC is updated once it changes
+	if profile.DefaultRuleAction != "" {
+		tags = append(tags, model.Tag{Scope: String(common.TagScopeVPCDefaultRuleAction), Tag: String(profile.DefaultRuleAction)})
+	}
+	// the deletion policy is tagged so that the GC still honors it once the VPC CR is gone
+	if obj.Spec.DeletionPolicy != "" {
+		tags = append(tags, model.Tag{Scope: String(common.TagScopeVPCDeletionPolicy), Tag: String(string(obj.Spec.DeletionPolicy))})
+	}
+	return tags
+}
@@ -0,0 +1,149 @@
+package vpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	nsxutil "github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func TestGetConnectivityProfile(t *testing.T) {
+	s := &VPCService{Service: common.Service{NSXConfig: &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		VPCConnectivityProfiles: map[string]*config.VPCConnectivityProfile{
+			"dmz":    {Name: "dmz", ExternalConnectivity: true},
+			"public": {Name: "public", ExternalConnectivity: true, DefaultRuleAction: "ALLOW"},
+		},
+	}}}
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		want        *config.VPCConnectivityProfile
+		wantErr     bool
+	}{
+		{"default", nil, nil, builtinConnectivityProfiles[ConnectivityProfilePrivate], false},
+		{"label", map[string]string{common.AnnotationVPCConnectivityProfile: "dmz"}, nil, s.NSXConfig.VPCConnectivityProfiles["dmz"], false},
+		{"annotation", nil, map[string]string{common.AnnotationVPCConnectivityProfile: "isolated"}, builtinConnectivityProfiles[ConnectivityProfileIsolated], false},
+		{"annotation over label", map[string]string{common.AnnotationVPCConnectivityProfile: "dmz"},
+			map[string]string{common.AnnotationVPCConnectivityProfile: "isolated"}, builtinConnectivityProfiles[ConnectivityProfileIsolated], false},
+		{"config over builtin", map[string]string{common.AnnotationVPCConnectivityProfile: "public"}, nil, s.NSXConfig.VPCConnectivityProfiles["public"], false},
+		{"invalid", nil, map[string]string{common.AnnotationVPCConnectivityProfile: "open"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: tt.labels, Annotations: tt.annotations}}
+			got, err := s.GetConnectivityProfile(ns)
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr {
+				assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestGetVPCNetworkConfigName(t *testing.T) {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}}
+	assert.Equal(t, common.DefaultVPCNetworkConfigName, GetVPCNetworkConfigName(ns))
+	ns.Annotations = map[string]string{common.AnnotationVPCNetworkConfig: "nc1"}
+	assert.Equal(t, "nc1", GetVPCNetworkConfigName(ns))
+}
+
+func TestBuildNSXVPC(t *testing.T) {
+	s := &VPCService{Service: common.Service{NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}}}}
+	obj := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"}}
+	nc := &v1alpha1.VPCNetworkConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.VPCNetworkConfigurationSpec{
+			NSXTProject:        "p1",
+			DefaultGatewayPath: "/infra/tier-0s/t0",
+			ExternalIPv4Blocks: []string{"/infra/ip-blocks/b1"},
+			PrivateIPv4CIDRs:   []string{"172.16.0.0/16"},
+		},
+	}
+
+	isolated := builtinConnectivityProfiles[ConnectivityProfileIsolated]
+	private := builtinConnectivityProfiles[ConnectivityProfilePrivate]
+	public := builtinConnectivityProfiles[ConnectivityProfilePublic]
+	nsxVPC, err := s.buildNSXVPC(obj, nc, isolated)
+	assert.Nil(t, err)
+	assert.Equal(t, "vpc_uid1", *nsxVPC.Id)
+	assert.Equal(t, "ns1-vpc1", *nsxVPC.DisplayName)
+	assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc_uid1", *nsxVPC.Path)
+	assert.True(t, *nsxVPC.ServiceGateway.Disable)
+	assert.Nil(t, nsxVPC.DefaultGatewayPath)
+	assert.Nil(t, nsxVPC.PublicIpv4Blocks)
+	assert.Equal(t, []string{"172.16.0.0/16"}, nsxVPC.PrivateIpv4Blocks)
+	assert.Equal(t, ConnectivityProfileIsolated, *nsxVPC.Tags[4].Tag)
+	assert.Equal(t, 5, len(nsxVPC.Tags))
+
+	nsxVPC, err = s.buildNSXVPC(obj, nc, private)
+	assert.Nil(t, err)
+	assert.False(t, *nsxVPC.ServiceGateway.Disable)
+	assert.True(t, *nsxVPC.ServiceGateway.AutoSnat)
+	assert.Equal(t, "/infra/tier-0s/t0", *nsxVPC.DefaultGatewayPath)
+	assert.Equal(t, []string{"/infra/ip-blocks/b1"}, nsxVPC.PublicIpv4Blocks)
+
+	nsxVPC, err = s.buildNSXVPC(obj, nc, public)
+	assert.Nil(t, err)
+	assert.False(t, *nsxVPC.ServiceGateway.Disable)
+	assert.False(t, *nsxVPC.ServiceGateway.AutoSnat)
+
+	nsxVPC, err = s.buildNSXVPC(obj, nc, &config.VPCConnectivityProfile{Name: "restricted", DefaultRuleAction: "DROP"})
+	assert.Nil(t, err)
+	assert.Equal(t, "DROP", *nsxVPC.Tags[5].Tag)
+
+	nc.Spec.DefaultGatewayPath = ""
+	_, err = s.buildNSXVPC(obj, nc, public)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+	_, err = s.buildNSXVPC(obj, nc, isolated)
+	assert.Nil(t, err)
+
+	nc.Spec.NSXTProject = ""
+	_, err = s.buildNSXVPC(obj, nc, isolated)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+}
+
+func TestBuildNSXVPCInProject(t *testing.T) {
+	s := &VPCService{Service: common.Service{NSXConfig: &config.NSXOperatorConfig{
+		CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"},
+		NsxConfig: &config.NsxConfig{NsxOrg: "org1", NsxProject: "tenant1"},
+	}}}
+	obj := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"}}
+	nc := &v1alpha1.VPCNetworkConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	isolated := builtinConnectivityProfiles[ConnectivityProfileIsolated]
+
+	// the VPC is created in the Project of NSX Operator
+	nsxVPC, err := s.buildNSXVPC(obj, nc, isolated)
+	assert.Nil(t, err)
+	assert.Equal(t, "/orgs/org1/projects/tenant1/vpcs/vpc_uid1", *nsxVPC.Path)
+	nc.Spec.NSXTProject = "tenant1"
+	_, err = s.buildNSXVPC(obj, nc, isolated)
+	assert.Nil(t, err)
+
+	// the Projects of the other tenants are not allowed
+	nc.Spec.NSXTProject = "tenant2"
+	_, err = s.buildNSXVPC(obj, nc, isolated)
+	assert.ErrorAs(t, err, &nsxutil.RestrictionError{})
+}
+
+func TestBuildDefaultSecurityPolicy(t *testing.T) {
+	s := &VPCService{Service: common.Service{NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}}}}
+	obj := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"}}
+
+	assert.Nil(t, s.buildDefaultSecurityPolicy(obj, builtinConnectivityProfiles[ConnectivityProfilePrivate]))
+	policy := s.buildDefaultSecurityPolicy(obj, &config.VPCConnectivityProfile{Name: "restricted", DefaultRuleAction: "REJECT"})
+	assert.Equal(t, defaultSecurityPolicyID, *policy.Id)
+	assert.Equal(t, 1, len(policy.Rules))
+	assert.Equal(t, "REJECT", *policy.Rules[0].Action)
+	assert.Equal(t, "restricted-default-reject", *policy.Rules[0].DisplayName)
+	assert.Equal(t, []string{"ANY"}, policy.Rules[0].SourceGroups)
+}
@@ -0,0 +1,38 @@
+package vpc
+
+import (
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type Vpc model.Vpc
+
+type Comparable = common.Comparable
+
+func (vpc *Vpc) Key() string {
+	return *vpc.Id
+}
+
+func (vpc *Vpc) Value() data.DataValue {
+	v := &Vpc{
+		Id:                 vpc.Id,
+		DisplayName:        vpc.DisplayName,
+		Tags:               vpc.Tags,
+		DefaultGatewayPath: vpc.DefaultGatewayPath,
+		PrivateIpv4Blocks:  vpc.PrivateIpv4Blocks,
+		PublicIpv4Blocks:   vpc.PublicIpv4Blocks,
+		ServiceGateway:     vpc.ServiceGateway,
+	}
+	dataValue, _ := ComparableToVpc(v).GetDataValue__()
+	return dataValue
+}
+
+func VpcToComparable(vpc *model.Vpc) Comparable {
+	return (*Vpc)(vpc)
+}
+
+func ComparableToVpc(vpc Comparable) *model.Vpc {
+	return (*model.Vpc)(vpc.(*Vpc))
+}
@@ -0,0 +1,26 @@
+package vpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func TestVpc_Value(t *testing.T) {
+	v1 := &model.Vpc{
+		Id:             String("vpc1"),
+		DisplayName:    String("ns1-vpc1"),
+		ServiceGateway: &model.ServiceGateway{Disable: Bool(false), AutoSnat: Bool(true)},
+	}
+	v2 := *v1
+	// fields not managed by the operator are ignored
+	v2.Revision = common.Int64(3)
+	assert.False(t, common.CompareResource(VpcToComparable(v1), VpcToComparable(&v2)))
+
+	v2.ServiceGateway = &model.ServiceGateway{Disable: Bool(true)}
+	assert.True(t, common.CompareResource(VpcToComparable(v1), VpcToComparable(&v2)))
+	assert.Equal(t, "vpc1", VpcToComparable(v1).Key())
+}
@@ -0,0 +1,226 @@
+package vpc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+)
+
+const (
+	// IPBlockUsageSNAT is the type of the IPs translated by SNAT rules.
+	IPBlockUsageSNAT = "snat"
+	// IPBlockUsageNAT is the type of the IPs used by the other NAT rules, e.g. DNAT.
+	IPBlockUsageNAT = "nat"
+	// IPBlockUsageIPAllocation is the type of the IPs allocated to the VPCs, e.g. LB VIPs.
+	IPBlockUsageIPAllocation = "ip_allocation"
+
+	ipBlockPathPrefix = "/infra/ip-blocks/"
+)
+
+// IPBlockUsage is the usage of an external IP block, the used IPs are counted by type.
+type IPBlockUsage struct {
+	Path  string
+	CIDR  string
+	Total int64
+	Used  map[string]int64
+}
+
+// UsedIPs returns the number of the used IPs of all types.
+func (u *IPBlockUsage) UsedIPs() int64 {
+	var used int64
+	for _, count := range u.Used {
+		used += count
+	}
+	return used
+}
+
+// Utilization returns the percentage of the used IPs.
+func (u *IPBlockUsage) Utilization() int {
+	if u.Total == 0 {
+		return 0
+	}
+	return int(u.UsedIPs() * 100 / u.Total)
+}
+
+// ipBlockUsageKeyFunc keys the IP block usages by the IP block path.
+func ipBlockUsageKeyFunc(obj interface{}) (string, error) {
+	switch v := obj.(type) {
+	case *IPBlockUsage:
+		return v.Path, nil
+	default:
+		return "", errors.New("ipBlockUsageKeyFunc doesn't support unknown type")
+	}
+}
+
+// GetExternalIPBlockUsage returns the last synced usage of the IP block.
+func (s *VPCService) GetExternalIPBlockUsage(path string) *IPBlockUsage {
+	obj, exists, _ := s.ipBlockUsageStore.GetByKey(path)
+	if !exists {
+		return nil
+	}
+	return obj.(*IPBlockUsage)
+}
+
+// SyncExternalIPBlockUsage counts the IPs of the external IP blocks used by the SNAT IPs, NAT rules and IP allocations
+// of all the VPCs, and keeps the usages in the store. An IP used by several objects is only counted once.
+func (s *VPCService) SyncExternalIPBlockUsage(paths []string) ([]*IPBlockUsage, error) {
+	var usages []*IPBlockUsage
+	blocks := make(map[string]*net.IPNet)
+	for _, path := range paths {
+		usage, err := s.getIPBlock(path)
+		if err != nil {
+			return nil, err
+		}
+		_, ipNet, err := net.ParseCIDR(usage.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s of IP block %s: %w", usage.CIDR, path, err)
+		}
+		blocks[path] = ipNet
+		usages = append(usages, usage)
+	}
+
+	// used records the type of each used IP or CIDR, the NAT rules take precedence over the IP allocations.
+	used := make(map[string]string)
+	for _, obj := range s.vpcStore.List() {
+		nsxVPC := obj.(model.Vpc)
+		org, project, err := parseVPCPath(&nsxVPC)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.listNATRuleIPs(org, project, *nsxVPC.Id, used); err != nil {
+			return nil, err
+		}
+		if err := s.listAllocationIPs(org, project, *nsxVPC.Id, used); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, usage := range usages {
+		usage.Used = make(map[string]int64)
+		for address, usageType := range used {
+			usage.Used[usageType] += countIPsInBlock(address, blocks[usage.Path])
+		}
+		if err := s.ipBlockUsageStore.Add(usage); err != nil {
+			return nil, err
+		}
+	}
+	return usages, nil
+}
+
+// getIPBlock reads the CIDR of the IP block, the CIDR of a synced IP block is taken from the store.
+func (s *VPCService) getIPBlock(path string) (*IPBlockUsage, error) {
+	if cached := s.GetExternalIPBlockUsage(path); cached != nil {
+		return &IPBlockUsage{Path: path, CIDR: cached.CIDR, Total: cached.Total}, nil
+	}
+	var block model.IpAddressBlock
+	var err error
+	if org, project, id, ok := parseProjectIPBlockPath(path); ok {
+		block, err = s.NSXClient.ProjectIPBlockClient.Get(org, project, id)
+	} else if strings.HasPrefix(path, ipBlockPathPrefix) {
+		block, err = s.NSXClient.IPBlockClient.Get(strings.TrimPrefix(path, ipBlockPathPrefix))
+	} else {
+		return nil, fmt.Errorf("unsupported IP block path %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block.Cidr == nil {
+		return nil, fmt.Errorf("IP block %s has no CIDR", path)
+	}
+	_, ipNet, err := net.ParseCIDR(*block.Cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %s of IP block %s: %w", *block.Cidr, path, err)
+	}
+	return &IPBlockUsage{Path: path, CIDR: *block.Cidr, Total: cidrSize(ipNet)}, nil
+}
+
+// parseProjectIPBlockPath gets the org, project and ID from the path /orgs/<org>/projects/<project>/infra/ip-blocks/<id>.
+func parseProjectIPBlockPath(path string) (string, string, string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 7 || parts[0] != "orgs" || parts[2] != "projects" || parts[4] != "infra" || parts[5] != "ip-blocks" {
+		return "", "", "", false
+	}
+	return parts[1], parts[3], parts[6], true
+}
+
+func (s *VPCService) listNATRuleIPs(org, project, vpcID string, used map[string]string) error {
+	var cursor *string
+	for {
+		rules, err := s.NSXClient.NATRuleClient.List(org, project, vpcID, DefaultSNATNatID, cursor, nil, nil, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+		for _, rule := range rules.Results {
+			if rule.Action == nil {
+				continue
+			}
+			switch *rule.Action {
+			case model.PolicyNatRule_ACTION_SNAT:
+				if rule.TranslatedNetwork != nil {
+					used[*rule.TranslatedNetwork] = IPBlockUsageSNAT
+				}
+			case model.PolicyNatRule_ACTION_DNAT:
+				if rule.DestinationNetwork != nil {
+					used[*rule.DestinationNetwork] = IPBlockUsageNAT
+				}
+			}
+		}
+		if rules.Cursor == nil || *rules.Cursor == "" {
+			return nil
+		}
+		cursor = rules.Cursor
+	}
+}
+
+func (s *VPCService) listAllocationIPs(org, project, vpcID string, used map[string]string) error {
+	var cursor *string
+	for {
+		allocations, err := s.NSXClient.VPCIPAllocationClient.List(org, project, vpcID, cursor, nil, nil, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+		for _, allocation := range allocations.Results {
+			if allocation.AllocationIp == nil {
+				continue
+			}
+			if allocation.IpAddressBlockVisibility != nil && *allocation.IpAddressBlockVisibility != model.VpcIpAddressAllocation_IP_ADDRESS_BLOCK_VISIBILITY_PUBLIC {
+				continue
+			}
+			if _, ok := used[*allocation.AllocationIp]; !ok {
+				used[*allocation.AllocationIp] = IPBlockUsageIPAllocation
+			}
+		}
+		if allocations.Cursor == nil || *allocations.Cursor == "" {
+			return nil
+		}
+		cursor = allocations.Cursor
+	}
+}
+
+// countIPsInBlock returns the number of IPs of the address in the block, the address is either an IP or a CIDR.
+func countIPsInBlock(address string, block *net.IPNet) int64 {
+	if ip := net.ParseIP(address); ip != nil {
+		if block.Contains(ip) {
+			return 1
+		}
+		return 0
+	}
+	ip, ipNet, err := net.ParseCIDR(address)
+	if err != nil || !block.Contains(ip) {
+		return 0
+	}
+	return cidrSize(ipNet)
+}
+
+// cidrSize returns the number of IPs of the CIDR, it is capped for the IPv6 CIDRs which don't fit in int64.
+func cidrSize(ipNet *net.IPNet) int64 {
+	ones, bits := ipNet.Mask.Size()
+	if bits-ones >= 63 {
+		return math.MaxInt64
+	}
+	return 1 << (bits - ones)
+}
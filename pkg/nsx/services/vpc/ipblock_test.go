@@ -0,0 +1,120 @@
+package vpc
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	project_infra "github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/infra"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs/nat"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+type fakeIPBlockClient struct {
+	infra.IpBlocksClient
+	cidrs map[string]string
+	gets  int
+}
+
+func (c *fakeIPBlockClient) Get(id string) (model.IpAddressBlock, error) {
+	c.gets++
+	cidr := c.cidrs[id]
+	return model.IpAddressBlock{Id: &id, Cidr: &cidr}, nil
+}
+
+type fakeProjectIPBlockClient struct {
+	project_infra.IpBlocksClient
+}
+
+func (c *fakeProjectIPBlockClient) Get(_ string, _ string, id string) (model.IpAddressBlock, error) {
+	cidr := "10.0.2.0/24"
+	return model.IpAddressBlock{Id: &id, Cidr: &cidr}, nil
+}
+
+type fakeNATRuleClient struct {
+	nat.NatRulesClient
+	rules []model.PolicyNatRule
+}
+
+func (c *fakeNATRuleClient) List(_ string, _ string, _ string, _ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.PolicyNatRuleListResult, error) {
+	return model.PolicyNatRuleListResult{Results: c.rules}, nil
+}
+
+type fakeIPAllocationClient struct {
+	vpcs.IpAddressAllocationsClient
+	allocations []model.VpcIpAddressAllocation
+}
+
+func (c *fakeIPAllocationClient) List(_ string, _ string, _ string, _ *string, _ *bool, _ *string, _ *int64, _ *bool, _ *string) (model.VpcIpAddressAllocationListResult, error) {
+	return model.VpcIpAddressAllocationListResult{Results: c.allocations}, nil
+}
+
+func TestVPCService_SyncExternalIPBlockUsage(t *testing.T) {
+	snat, dnat := model.PolicyNatRule_ACTION_SNAT, model.PolicyNatRule_ACTION_DNAT
+	public := model.VpcIpAddressAllocation_IP_ADDRESS_BLOCK_VISIBILITY_PUBLIC
+	private := "PRIVATE"
+	str := func(s string) *string { return &s }
+	blockClient := &fakeIPBlockClient{cidrs: map[string]string{"b1": "10.0.0.0/24", "b2": "10.0.1.0/28"}}
+	s := &VPCService{
+		Service: common.Service{NSXClient: &nsx.Client{
+			IPBlockClient:        blockClient,
+			ProjectIPBlockClient: &fakeProjectIPBlockClient{},
+			NATRuleClient: &fakeNATRuleClient{rules: []model.PolicyNatRule{
+				{Action: &snat, TranslatedNetwork: str("10.0.0.1")},
+				{Action: &dnat, DestinationNetwork: str("10.0.0.2")},
+				{Action: &snat, TranslatedNetwork: str("10.0.1.0/30")},
+			}},
+			VPCIPAllocationClient: &fakeIPAllocationClient{allocations: []model.VpcIpAddressAllocation{
+				// the SNAT IP is allocated too and counted only once
+				{AllocationIp: str("10.0.0.1"), IpAddressBlockVisibility: &public},
+				{AllocationIp: str("10.0.0.3"), IpAddressBlockVisibility: &public},
+				{AllocationIp: str("10.0.0.4"), IpAddressBlockVisibility: &private},
+				{AllocationIp: str("192.168.0.1"), IpAddressBlockVisibility: &public},
+			}},
+		}},
+		vpcStore: &VPCStore{ResourceStore: common.ResourceStore{
+			Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc}),
+			BindingType: model.VpcBindingType(),
+		}},
+		ipBlockUsageStore: cache.NewIndexer(ipBlockUsageKeyFunc, cache.Indexers{}),
+	}
+	assert.Nil(t, s.vpcStore.Add(model.Vpc{Id: str("vpc1"), Path: str("/orgs/default/projects/p1/vpcs/vpc1")}))
+
+	usages, err := s.SyncExternalIPBlockUsage([]string{"/infra/ip-blocks/b1", "/infra/ip-blocks/b2"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(usages))
+	assert.Equal(t, int64(256), usages[0].Total)
+	assert.Equal(t, map[string]int64{IPBlockUsageSNAT: 1, IPBlockUsageNAT: 1, IPBlockUsageIPAllocation: 1}, usages[0].Used)
+	assert.Equal(t, int64(3), usages[0].UsedIPs())
+	assert.Equal(t, 1, usages[0].Utilization())
+	assert.Equal(t, int64(4), usages[1].Used[IPBlockUsageSNAT])
+	assert.Equal(t, 25, usages[1].Utilization())
+	assert.Equal(t, usages[1], s.GetExternalIPBlockUsage("/infra/ip-blocks/b2"))
+
+	// the CIDRs of the synced IP blocks are not read again
+	_, err = s.SyncExternalIPBlockUsage([]string{"/infra/ip-blocks/b1"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, blockClient.gets)
+
+	// the IP blocks of the NSX Project
+	usages, err = s.SyncExternalIPBlockUsage([]string{"/orgs/default/projects/p1/infra/ip-blocks/b3"})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.2.0/24", usages[0].CIDR)
+
+	_, err = s.SyncExternalIPBlockUsage([]string{"/infra/b4"})
+	assert.NotNil(t, err)
+}
+
+func TestCountIPsInBlock(t *testing.T) {
+	_, block, _ := net.ParseCIDR("10.0.0.0/24")
+	assert.Equal(t, int64(1), countIPsInBlock("10.0.0.10", block))
+	assert.Equal(t, int64(0), countIPsInBlock("10.0.1.10", block))
+	assert.Equal(t, int64(16), countIPsInBlock("10.0.0.16/28", block))
+	assert.Equal(t, int64(0), countIPsInBlock("invalid", block))
+}
@@ -68,20 +68,20 @@ func (vs *VPCStore) Operate(i interface{}) error {
 
 func (vs *VPCStore) GetVPCsByNamespace(ns string) []model.Vpc {
 	var ret []model.Vpc
-	vpcs := vs.List()
-	if len(vpcs) == 0 {
-		log.V(1).Info("No vpc found in vpc store")
-		return ret
+	for _, vpc := range vs.ListByNamespace(ns) {
+		ret = append(ret, vpc.(model.Vpc))
 	}
-
-	for _, vpc := range vpcs {
-		mvpc := vpc.(model.Vpc)
-		tags := mvpc.Tags
-		for _, tag := range tags {
-			if *tag.Scope == common.TagScopeNamespace && *tag.Tag == ns {
-				ret = append(ret, mvpc)
-			}
-		}
+	if len(ret) == 0 {
+		log.V(1).Info("No vpc found in vpc store", "namespace", ns)
 	}
 	return ret
 }
+
+func (vs *VPCStore) GetVPCByCRUID(uid string) *model.Vpc {
+	vpcs := vs.GetByIndex(common.TagScopeVPCCRUID, uid)
+	if len(vpcs) == 0 {
+		return nil
+	}
+	vpc := vpcs[0].(model.Vpc)
+	return &vpc
+}
@@ -107,8 +107,8 @@ func Test_InitializeVPCStore(t *testing.T) {
 			},
 		},
 	}
-	vpcCacheIndexer := cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc})
-	vpcStore = &VPCStore{ResourceStore: common.ResourceStore{
+	vpcCacheIndexer := common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc})
+	vpcStore := &VPCStore{ResourceStore: common.ResourceStore{
 		Indexer:     vpcCacheIndexer,
 		BindingType: model.VpcBindingType(),
 	}}
@@ -134,12 +134,12 @@ func Test_InitializeVPCStore(t *testing.T) {
 }
 
 func TestVPCStore_CRUDResource(t *testing.T) {
-	vpcCacheIndexer := cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc})
+	vpcCacheIndexer := common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc})
 	resourceStore := common.ResourceStore{
 		Indexer:     vpcCacheIndexer,
 		BindingType: model.VpcBindingType(),
 	}
-	vpcStore = &VPCStore{ResourceStore: resourceStore}
+	vpcStore := &VPCStore{ResourceStore: resourceStore}
 	type args struct {
 		i interface{}
 	}
@@ -158,7 +158,7 @@ func TestVPCStore_CRUDResource(t *testing.T) {
 }
 
 func TestVPCStore_CRUDResource_List(t *testing.T) {
-	vpcCacheIndexer := cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc})
+	vpcCacheIndexer := common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc})
 	resourceStore := common.ResourceStore{
 		Indexer:     vpcCacheIndexer,
 		BindingType: model.VpcBindingType(),
@@ -250,3 +250,22 @@ func TestVPCStore_CRUDResource_List(t *testing.T) {
 		})
 	}
 }
+
+func TestVPCStore_GetVPCByCRUID(t *testing.T) {
+	vpcCacheIndexer := common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc})
+	vpcStore := &VPCStore{ResourceStore: common.ResourceStore{
+		Indexer:     vpcCacheIndexer,
+		BindingType: model.VpcBindingType(),
+	}}
+	vpc1 := model.Vpc{
+		DisplayName: &vpcName1,
+		Id:          &vpcID1,
+		Tags:        basicTags,
+	}
+	assert.NoError(t, vpcStore.Operate(&vpc1))
+
+	got := vpcStore.GetVPCByCRUID(tagValueVPCCRUID)
+	assert.NotNil(t, got)
+	assert.Equal(t, vpcID1, *got.Id)
+	assert.Nil(t, vpcStore.GetVPCByCRUID("invalid"))
+}
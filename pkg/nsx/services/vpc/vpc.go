@@ -1,26 +1,44 @@
 package vpc
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
 	"sync"
 
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
 	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/tracing"
+)
+
+const (
+	// DefaultSNATNatID is the NAT section holding the SNAT rules of a VPC.
+	DefaultSNATNatID = "USER"
 )
 
 var (
 	log             = logger.Log
 	ResourceTypeVPC = common.ResourceTypeVPC
 	NewConverter    = common.NewConverter
-	// The following variables are defined as interface, they should be initialized as concrete type
-	vpcStore common.Store
+	MarkedForDelete = true
 )
 
 type VPCService struct {
 	common.Service
 	vpcStore *VPCStore
+	// ipBlockUsageStore keeps the usages of the external IP blocks synced by SyncExternalIPBlockUsage
+	ipBlockUsageStore cache.Indexer
 }
 
 // InitializeVPC sync NSX resources
@@ -34,11 +52,13 @@ func InitializeVPC(service common.Service) (*VPCService, error) {
 	VPCService := &VPCService{Service: service}
 
 	VPCService.vpcStore = &VPCStore{ResourceStore: common.ResourceStore{
-		Indexer:     cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc}),
+		Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc}),
 		BindingType: model.VpcBindingType(),
 	}}
 
-	go VPCService.InitializeResourceStore(&wg, fatalErrors, ResourceTypeVPC, vpcStore)
+	VPCService.ipBlockUsageStore = cache.NewIndexer(ipBlockUsageKeyFunc, cache.Indexers{})
+
+	go VPCService.InitializeResourceStore(&wg, fatalErrors, ResourceTypeVPC, VPCService.vpcStore)
 
 	go func() {
 		wg.Wait()
@@ -59,3 +79,289 @@ func InitializeVPC(service common.Service) (*VPCService, error) {
 func (s *VPCService) GetVPCsByNamespace(namespace string) []model.Vpc {
 	return s.vpcStore.GetVPCsByNamespace(namespace)
 }
+
+func (s *VPCService) GetVPCByCRUID(uid string) *model.Vpc {
+	return s.vpcStore.GetVPCByCRUID(uid)
+}
+
+// CreateOrUpdateVPC creates or updates the NSX VPC of the VPC CR in the Project of the VPCNetworkConfiguration,
+// the connectivity profile decides how the VPC is connected to the outside and its default rule. The NSX requests are
+// traced as part of the span of ctx.
+func (s *VPCService) CreateOrUpdateVPC(ctx context.Context, obj *v1alpha1.VPC, nc *v1alpha1.VPCNetworkConfiguration, profile *config.VPCConnectivityProfile) (nsxVPC *model.Vpc, err error) {
+	ctx, span := tracing.Start(ctx, "CreateOrUpdateVPC", trace.SpanKindInternal,
+		attribute.String("k8s.namespace.name", obj.Namespace),
+		attribute.String("name", obj.Name),
+	)
+	defer func() { tracing.End(span, err) }()
+	nsxClient := s.NSXClient.WithContext(ctx)
+
+	nsxVPC, err = s.buildNSXVPC(obj, nc, profile)
+	if err != nil {
+		log.Error(err, "failed to build VPC", "VPC", obj.Name, "Namespace", obj.Namespace)
+		return nil, err
+	}
+
+	existingVPC := s.vpcStore.GetVPCByCRUID(string(obj.UID))
+	if existingVPC != nil {
+		if existingVPC.Path != nil && *existingVPC.Path != *nsxVPC.Path {
+			return nil, fmt.Errorf("VPC %s can not be moved to %s", *existingVPC.Path, *nsxVPC.Path)
+		}
+		if !common.CompareResource(VpcToComparable(existingVPC), VpcToComparable(nsxVPC)) {
+			log.Info("VPC is not changed, skip updating it", "VPC.Id", *nsxVPC.Id)
+			return existingVPC, nil
+		}
+	}
+
+	org, project, err := parseVPCPath(nsxVPC)
+	if err != nil {
+		return nil, err
+	}
+	if err := nsxClient.VPCClient.Patch(org, project, *nsxVPC.Id, *nsxVPC); err != nil {
+		return nil, err
+	}
+	// the default security policy is only deleted if the VPC had a default rule
+	if policy := s.buildDefaultSecurityPolicy(obj, profile); policy != nil || hasDefaultRule(existingVPC) {
+		if err := s.applyDefaultSecurityPolicy(nsxClient, org, project, *nsxVPC.Id, policy); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.vpcStore.Operate(nsxVPC); err != nil {
+		return nil, err
+	}
+	log.Info("successfully created or updated VPC", "VPC", nsxVPC)
+	return nsxVPC, nil
+}
+
+// DeleteVPC deletes the NSX VPC created for the VPC CR with the given UID.
+func (s *VPCService) DeleteVPC(uid types.UID) error {
+	nsxVPC := s.vpcStore.GetVPCByCRUID(string(uid))
+	if nsxVPC == nil {
+		log.Info("VPC is not found in store, skip deleting it", "UID", uid)
+		return nil
+	}
+	org, project, err := parseVPCPath(nsxVPC)
+	if err != nil {
+		return err
+	}
+	if hasDefaultRule(nsxVPC) {
+		if err := s.applyDefaultSecurityPolicy(s.NSXClient, org, project, *nsxVPC.Id, nil); err != nil {
+			return err
+		}
+	}
+	if err := s.NSXClient.VPCClient.Delete(org, project, *nsxVPC.Id); err != nil {
+		return err
+	}
+	nsxVPC.MarkedForDelete = &MarkedForDelete
+	if err := s.vpcStore.Operate(nsxVPC); err != nil {
+		return err
+	}
+	log.Info("successfully deleted VPC", "VPC", nsxVPC)
+	return nil
+}
+
+// RetainVPC orphans the NSX VPC created for the VPC CR with the given UID instead of deleting it. The tags of the
+// cluster and the CR UID are replaced with the retained cluster tag, so that the VPC is neither loaded into the
+// store nor garbage collected by the cluster any more.
+func (s *VPCService) RetainVPC(uid types.UID) error {
+	nsxVPC := s.vpcStore.GetVPCByCRUID(string(uid))
+	if nsxVPC == nil {
+		log.Info("VPC is not found in store, skip retaining it", "UID", uid)
+		return nil
+	}
+	org, project, err := parseVPCPath(nsxVPC)
+	if err != nil {
+		return err
+	}
+	retained := model.Vpc{Id: nsxVPC.Id, Tags: buildRetainedTags(nsxVPC.Tags)}
+	if err := s.NSXClient.VPCClient.Patch(org, project, *nsxVPC.Id, retained); err != nil {
+		return err
+	}
+	nsxVPC.MarkedForDelete = &MarkedForDelete
+	if err := s.vpcStore.Operate(nsxVPC); err != nil {
+		return err
+	}
+	log.Info("successfully retained VPC", "VPC", nsxVPC)
+	return nil
+}
+
+func buildRetainedTags(tags []model.Tag) []model.Tag {
+	retained := make([]model.Tag, 0, len(tags))
+	for _, tag := range tags {
+		switch *tag.Scope {
+		case common.TagScopeCluster:
+			retained = append(retained, model.Tag{Scope: String(common.TagScopeRetainedCluster), Tag: tag.Tag})
+		case common.TagScopeVPCCRUID:
+		default:
+			retained = append(retained, tag)
+		}
+	}
+	return retained
+}
+
+// applyDefaultSecurityPolicy patches the default security policy of the VPC, or deletes it if policy is nil.
+func (s *VPCService) applyDefaultSecurityPolicy(nsxClient *nsx.Client, org, project, vpcID string, policy *model.SecurityPolicy) error {
+	if policy == nil {
+		return nsxClient.VPCSecurityPolicyClient.Delete(org, project, vpcID, defaultSecurityPolicyID)
+	}
+	return nsxClient.VPCSecurityPolicyClient.Patch(org, project, vpcID, defaultSecurityPolicyID, *policy)
+}
+
+// hasDefaultRule returns whether the VPC was created with the default security policy, i.e. it has the tag of the
+// default rule action.
+func hasDefaultRule(nsxVPC *model.Vpc) bool {
+	if nsxVPC == nil {
+		return false
+	}
+	for _, tag := range nsxVPC.Tags {
+		if tag.Scope != nil && *tag.Scope == common.TagScopeVPCDefaultRuleAction {
+			return true
+		}
+	}
+	return false
+}
+
+// ListVPCCRUID returns the UIDs of the VPC CRs which have NSX VPCs.
+func (s *VPCService) ListVPCCRUID() sets.String {
+	return s.vpcStore.ListIndexFuncValues(common.TagScopeVPCCRUID)
+}
+
+// VPCRetained tells if the NSX VPC of the VPC CR with the given UID is tagged with the Retain deletion policy, so that
+// it is retained rather than deleted when the VPC CR is gone.
+func (s *VPCService) VPCRetained(uid string) bool {
+	nsxVPC := s.vpcStore.GetVPCByCRUID(uid)
+	if nsxVPC == nil {
+		return false
+	}
+	for _, tag := range nsxVPC.Tags {
+		if *tag.Scope == common.TagScopeVPCDeletionPolicy {
+			return *tag.Tag == string(v1alpha1.DeletionPolicyRetain)
+		}
+	}
+	return false
+}
+
+// VPCNetworkInfo is the network information of an NSX VPC read from NSX.
+type VPCNetworkInfo struct {
+	Path               string
+	DefaultGatewayPath string
+	DefaultSNATIP      string
+	GatewayAddresses   []string
+	DNSServers         []string
+}
+
+// GetVPCNetworkInfo reads the network information of the NSX VPC created for the VPC CR from NSX,
+// it returns nil if there is no NSX VPC for the VPC CR. The NSX requests are traced as part of the span of ctx.
+func (s *VPCService) GetVPCNetworkInfo(ctx context.Context, uid string) (info *VPCNetworkInfo, err error) {
+	cached := s.vpcStore.GetVPCByCRUID(uid)
+	if cached == nil {
+		return nil, nil
+	}
+	ctx, span := tracing.Start(ctx, "GetVPCNetworkInfo", trace.SpanKindInternal, attribute.String("nsx.path", *cached.Path))
+	defer func() { tracing.End(span, err) }()
+	nsxClient := s.NSXClient.WithContext(ctx)
+
+	org, project, err := parseVPCPath(cached)
+	if err != nil {
+		return nil, err
+	}
+	nsxVPC, err := nsxClient.VPCClient.Get(org, project, *cached.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.vpcStore.Operate(&nsxVPC); err != nil {
+		return nil, err
+	}
+
+	info = &VPCNetworkInfo{Path: *cached.Path}
+	if nsxVPC.DefaultGatewayPath != nil {
+		info.DefaultGatewayPath = *nsxVPC.DefaultGatewayPath
+	}
+	if nsxVPC.DhcpConfig != nil && nsxVPC.DhcpConfig.DnsClientConfig != nil {
+		info.DNSServers = nsxVPC.DhcpConfig.DnsClientConfig.DnsServerIps
+	}
+	if info.DefaultSNATIP, err = getDefaultSNATIP(nsxClient, org, project, *cached.Id); err != nil {
+		return nil, err
+	}
+	if info.GatewayAddresses, err = getGatewayAddresses(nsxClient, org, project, *cached.Id); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// getDefaultSNATIP returns the translated IP of the first enabled SNAT rule of the VPC.
+func getDefaultSNATIP(nsxClient *nsx.Client, org, project, vpcID string) (string, error) {
+	var cursor *string
+	for {
+		rules, err := nsxClient.NATRuleClient.List(org, project, vpcID, DefaultSNATNatID, cursor, nil, nil, nil, nil, nil)
+		if err != nil {
+			return "", err
+		}
+		for _, rule := range rules.Results {
+			if rule.Action == nil || *rule.Action != model.PolicyNatRule_ACTION_SNAT || rule.TranslatedNetwork == nil {
+				continue
+			}
+			if rule.Enabled != nil && !*rule.Enabled {
+				continue
+			}
+			return *rule.TranslatedNetwork, nil
+		}
+		if rules.Cursor == nil || *rules.Cursor == "" {
+			return "", nil
+		}
+		cursor = rules.Cursor
+	}
+}
+
+// getGatewayAddresses returns the gateway addresses of the Subnets in the VPC, the gateway is the first IP of a Subnet CIDR.
+func getGatewayAddresses(nsxClient *nsx.Client, org, project, vpcID string) ([]string, error) {
+	var gateways []string
+	var cursor *string
+	for {
+		subnets, err := nsxClient.VPCSubnetClient.List(org, project, vpcID, cursor, nil, nil, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, subnet := range subnets.Results {
+			for _, cidr := range subnet.IpAddresses {
+				gateway, err := gatewayAddress(cidr)
+				if err != nil {
+					log.Error(err, "invalid Subnet CIDR", "Subnet", subnet.Path)
+					continue
+				}
+				gateways = append(gateways, gateway)
+			}
+		}
+		if subnets.Cursor == nil || *subnets.Cursor == "" {
+			break
+		}
+		cursor = subnets.Cursor
+	}
+	sort.Strings(gateways)
+	return gateways, nil
+}
+
+func gatewayAddress(cidr string) (string, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	ip := ipNet.IP
+	gateway := make(net.IP, len(ip))
+	copy(gateway, ip)
+	for i := len(gateway) - 1; i >= 0; i-- {
+		gateway[i]++
+		if gateway[i] != 0 {
+			break
+		}
+	}
+	return gateway.String(), nil
+}
+
+// parseVPCPath gets the org and project from the path /orgs/<org>/projects/<project>/vpcs/<id>.
+func parseVPCPath(vpc *model.Vpc) (string, string, error) {
+	if vpc.Path == nil {
+		return "", "", fmt.Errorf("VPC %s has no path", *vpc.Id)
+	}
+	org, project, _, err := common.ParseVPCPath(*vpc.Path)
+	return org, project, err
+}
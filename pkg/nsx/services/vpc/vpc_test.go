@@ -1,12 +1,21 @@
 package vpc
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/orgs/projects/vpcs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/mock/nsxsimulator"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
 )
 
@@ -46,7 +55,7 @@ var (
 )
 
 func TestVPC_GetVPCsByNamespace(t *testing.T) {
-	vpcCacheIndexer := cache.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc})
+	vpcCacheIndexer := common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc})
 	resourceStore := common.ResourceStore{
 		Indexer:     vpcCacheIndexer,
 		BindingType: model.VpcBindingType(),
@@ -142,3 +151,212 @@ func TestVPC_GetVPCsByNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestGatewayAddress(t *testing.T) {
+	gateway, err := gatewayAddress("172.16.0.16/28")
+	assert.Nil(t, err)
+	assert.Equal(t, "172.16.0.17", gateway)
+	gateway, err = gatewayAddress("172.16.0.5/24")
+	assert.Nil(t, err)
+	assert.Equal(t, "172.16.0.1", gateway)
+	gateway, err = gatewayAddress("fd00::/64")
+	assert.Nil(t, err)
+	assert.Equal(t, "fd00::1", gateway)
+	_, err = gatewayAddress("invalid")
+	assert.NotNil(t, err)
+}
+
+func TestParseVPCPath(t *testing.T) {
+	org, project, err := parseVPCPath(&model.Vpc{Id: &vpcID1, Path: common.String("/orgs/default/projects/p1/vpcs/vpc1")})
+	assert.Nil(t, err)
+	assert.Equal(t, "default", org)
+	assert.Equal(t, "p1", project)
+	_, _, err = parseVPCPath(&model.Vpc{Id: &vpcID1, Path: common.String("/infra/vpcs/vpc1")})
+	assert.NotNil(t, err)
+	_, _, err = parseVPCPath(&model.Vpc{Id: &vpcID1})
+	assert.NotNil(t, err)
+}
+
+type fakeSecurityPolicyClient struct {
+	vpcs.SecurityPoliciesClient
+	policies map[string]model.SecurityPolicy
+	deleted  []string
+}
+
+func (c *fakeSecurityPolicyClient) Patch(_ string, _ string, vpcID string, _ string, policy model.SecurityPolicy) error {
+	c.policies[vpcID] = policy
+	return nil
+}
+
+func (c *fakeSecurityPolicyClient) Delete(_ string, _ string, vpcID string, _ string) error {
+	delete(c.policies, vpcID)
+	c.deleted = append(c.deleted, vpcID)
+	return nil
+}
+
+type fakeVPCClient struct {
+	projects.VpcsClient
+	patched []model.Vpc
+	deleted []string
+}
+
+func (c *fakeVPCClient) Patch(_ string, _ string, _ string, vpc model.Vpc) error {
+	c.patched = append(c.patched, vpc)
+	return nil
+}
+
+func (c *fakeVPCClient) Delete(_ string, project string, vpcID string) error {
+	c.deleted = append(c.deleted, project+"/"+vpcID)
+	return nil
+}
+
+func TestVPCService_CreateOrUpdateAndDeleteVPC(t *testing.T) {
+	vpcClient := &fakeVPCClient{}
+	policyClient := &fakeSecurityPolicyClient{policies: map[string]model.SecurityPolicy{}}
+	s := &VPCService{
+		Service: common.Service{
+			NSXClient: &nsx.Client{VPCClient: vpcClient, VPCSecurityPolicyClient: policyClient},
+			NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: cluster}},
+		},
+		vpcStore: &VPCStore{ResourceStore: common.ResourceStore{
+			Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc}),
+			BindingType: model.VpcBindingType(),
+		}},
+	}
+	obj := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"}}
+	nc := &v1alpha1.VPCNetworkConfiguration{Spec: v1alpha1.VPCNetworkConfigurationSpec{NSXTProject: "p1"}}
+
+	isolated := builtinConnectivityProfiles[ConnectivityProfileIsolated]
+	public := builtinConnectivityProfiles[ConnectivityProfilePublic]
+	nsxVPC, err := s.CreateOrUpdateVPC(context.TODO(), obj, nc, isolated)
+	assert.Nil(t, err)
+	assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc_uid1", *nsxVPC.Path)
+	assert.Equal(t, 1, len(vpcClient.patched))
+	assert.Equal(t, []string{"uid1"}, s.ListVPCCRUID().List())
+
+	// unchanged VPC is not patched again
+	_, err = s.CreateOrUpdateVPC(context.TODO(), obj, nc, isolated)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(vpcClient.patched))
+
+	// VPC is patched after the connectivity profile changed
+	nc.Spec.DefaultGatewayPath = "/infra/tier-0s/t0"
+	_, err = s.CreateOrUpdateVPC(context.TODO(), obj, nc, public)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(vpcClient.patched))
+	assert.Equal(t, 0, len(policyClient.policies))
+	// the default security policy the VPC never had isn't deleted
+	assert.Empty(t, policyClient.deleted)
+
+	// VPC is patched with the default rule after the default rule action changed
+	drop := &config.VPCConnectivityProfile{Name: "public", ExternalConnectivity: true, DefaultRuleAction: "DROP"}
+	_, err = s.CreateOrUpdateVPC(context.TODO(), obj, nc, drop)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(vpcClient.patched))
+	assert.Equal(t, "DROP", *policyClient.policies["vpc_uid1"].Rules[0].Action)
+
+	// the default security policy is deleted once the default rule is removed
+	_, err = s.CreateOrUpdateVPC(context.TODO(), obj, nc, public)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(policyClient.policies))
+	assert.Equal(t, []string{"vpc_uid1"}, policyClient.deleted)
+	_, err = s.CreateOrUpdateVPC(context.TODO(), obj, nc, drop)
+	assert.Nil(t, err)
+
+	// VPC can't be moved to another project
+	nc.Spec.NSXTProject = "p2"
+	_, err = s.CreateOrUpdateVPC(context.TODO(), obj, nc, public)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, s.DeleteVPC(obj.UID))
+	assert.Equal(t, []string{"p1/vpc_uid1"}, vpcClient.deleted)
+	assert.Equal(t, 0, len(policyClient.policies))
+	assert.Equal(t, []string{"vpc_uid1", "vpc_uid1"}, policyClient.deleted)
+	assert.Equal(t, 0, s.ListVPCCRUID().Len())
+	// deleting a VPC not in store is a no-op
+	assert.Nil(t, s.DeleteVPC(obj.UID))
+	assert.Equal(t, 1, len(vpcClient.deleted))
+}
+
+func TestVPCService_NSXProfiles(t *testing.T) {
+	for _, profile := range nsxsimulator.Profiles {
+		t.Run(profile.Name, func(t *testing.T) {
+			sim := nsxsimulator.NewSimulator(profile)
+			defer sim.Close()
+			// more VPCs than a search page of any release
+			for i := 0; i < 1200; i++ {
+				sim.AddObject(fmt.Sprintf("/orgs/default/projects/p1/vpcs/vpc_%d", i), map[string]interface{}{
+					"resource_type": common.ResourceTypeVPC,
+					"tags": []map[string]string{
+						{"scope": common.TagScopeCluster, "tag": cluster},
+						{"scope": common.TagScopeVPCCRUID, "tag": fmt.Sprintf("uid%d", i)},
+					},
+				})
+			}
+			cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: cluster}}
+			s, err := InitializeVPC(common.Service{NSXClient: sim.NewNSXClient(cf), NSXConfig: cf})
+			assert.Nil(t, err)
+			// the search pages are shrunk to the max size of the release
+			assert.NotEmpty(t, sim.SearchPageSizes())
+			for _, pageSize := range sim.SearchPageSizes() {
+				assert.Equal(t, profile.MaxPageSize, pageSize)
+			}
+			assert.Equal(t, 1200, s.ListVPCCRUID().Len())
+
+			obj := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid-new"}}
+			nc := &v1alpha1.VPCNetworkConfiguration{Spec: v1alpha1.VPCNetworkConfigurationSpec{NSXTProject: "p1"}}
+			_, err = s.CreateOrUpdateVPC(context.TODO(), obj, nc, builtinConnectivityProfiles[ConnectivityProfileIsolated])
+			if !profile.Supports("/policy/api/v1/orgs/") {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.NotNil(t, sim.Object("/orgs/default/projects/p1/vpcs/vpc_uid-new"))
+
+			info, err := s.GetVPCNetworkInfo(context.TODO(), "uid-new")
+			assert.Nil(t, err)
+			assert.Equal(t, "/orgs/default/projects/p1/vpcs/vpc_uid-new", info.Path)
+			assert.Empty(t, info.GatewayAddresses)
+
+			assert.Nil(t, s.DeleteVPC(obj.UID))
+			assert.Nil(t, sim.Object("/orgs/default/projects/p1/vpcs/vpc_uid-new"))
+		})
+	}
+}
+
+func TestVPCService_RetainVPC(t *testing.T) {
+	vpcClient := &fakeVPCClient{}
+	s := &VPCService{
+		Service: common.Service{
+			NSXClient: &nsx.Client{VPCClient: vpcClient, VPCSecurityPolicyClient: &fakeSecurityPolicyClient{policies: map[string]model.SecurityPolicy{}}},
+			NSXConfig: &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{Cluster: cluster}},
+		},
+		vpcStore: &VPCStore{ResourceStore: common.ResourceStore{
+			Indexer:     common.NewIndexer(keyFunc, cache.Indexers{common.TagScopeVPCCRUID: indexFunc}),
+			BindingType: model.VpcBindingType(),
+		}},
+	}
+	obj := &v1alpha1.VPC{ObjectMeta: metav1.ObjectMeta{Name: "vpc1", Namespace: "ns1", UID: "uid1"}}
+	nc := &v1alpha1.VPCNetworkConfiguration{Spec: v1alpha1.VPCNetworkConfigurationSpec{NSXTProject: "p1"}}
+	_, err := s.CreateOrUpdateVPC(context.TODO(), obj, nc, builtinConnectivityProfiles[ConnectivityProfileIsolated])
+	assert.Nil(t, err)
+
+	assert.Nil(t, s.RetainVPC(obj.UID))
+	assert.Equal(t, 0, len(vpcClient.deleted))
+	assert.Equal(t, 2, len(vpcClient.patched))
+	retained := vpcClient.patched[1]
+	assert.Equal(t, "vpc_uid1", *retained.Id)
+	scopes := map[string]string{}
+	for _, tag := range retained.Tags {
+		scopes[*tag.Scope] = *tag.Tag
+	}
+	assert.Equal(t, cluster, scopes[common.TagScopeRetainedCluster])
+	assert.Equal(t, "ns1", scopes[common.TagScopeNamespace])
+	assert.NotContains(t, scopes, common.TagScopeCluster)
+	assert.NotContains(t, scopes, common.TagScopeVPCCRUID)
+	assert.Equal(t, 0, s.ListVPCCRUID().Len())
+
+	// retaining a VPC not in store is a no-op
+	assert.Nil(t, s.RetainVPC(obj.UID))
+	assert.Equal(t, 2, len(vpcClient.patched))
+}
@@ -0,0 +1,68 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/core"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/protocol/client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/tracing"
+)
+
+// contextConnector sends the requests of the SDK client with ctx, so that the spans of the requests are children of
+// the span of ctx.
+type contextConnector struct {
+	client.Connector
+	ctx context.Context
+}
+
+func (c *contextConnector) NewExecutionContext() *core.ExecutionContext {
+	executionContext := c.Connector.NewExecutionContext()
+	executionContext.WithContext(c.ctx)
+	return executionContext
+}
+
+// WithContext returns the client sending the requests with ctx, so that the requests are traced as part of the span of
+// ctx, e.g. the reconcile of a CR, and logged with the correlation of ctx. The client itself is returned if the span
+// of ctx is not recorded and ctx carries no correlation, as the SDK clients bound to ctx are created on every call.
+func (client *Client) WithContext(ctx context.Context) *Client {
+	if client.connector == nil || (!tracing.IsRecording(ctx) && !logger.HasCorrelation(ctx)) {
+		return client
+	}
+	bound := newClient(client.NsxConfig, bindConnector(client.connector, ctx))
+	bound.RestConnector = client.RestConnector
+	bound.NSXChecker = client.NSXChecker
+	bound.NSXVerChecker = client.NSXVerChecker
+	return bound
+}
+
+// bindConnector returns the function creating the connectors of connector bound to ctx.
+func bindConnector(connector func() client.Connector, ctx context.Context) func() client.Connector {
+	return func() client.Connector {
+		return &contextConnector{Connector: connector(), ctx: ctx}
+	}
+}
+
+// startRequestSpan starts the span of the request sent to NSX, the trace context is propagated to NSX in the
+// traceparent header.
+func startRequestSpan(r *http.Request) (*http.Request, trace.Span) {
+	ctx, span := tracing.Start(r.Context(), "NSX "+r.Method+" "+resourceTypeOf(r.URL.Path), trace.SpanKindClient,
+		attribute.String("http.method", r.Method),
+		attribute.String("http.target", r.URL.Path),
+	)
+	if !span.IsRecording() {
+		return r, span
+	}
+	r = r.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+	return r, span
+}
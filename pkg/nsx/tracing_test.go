@@ -0,0 +1,100 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package nsx
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/ratelimiter"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
+)
+
+func useSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+func TestClient_WithContext(t *testing.T) {
+	cf := config.NSXOperatorConfig{NsxConfig: &config.NsxConfig{NsxApiUser: "1", NsxApiPassword: "1"}}
+	cf.VCConfig = &config.VCConfig{}
+	client := GetClient(&cf)
+	assert.NotNil(t, client)
+
+	// the client is reused if the reconcile is not traced
+	assert.Same(t, client, client.WithContext(context.TODO()))
+
+	useSpanRecorder(t)
+	ctx, span := otel.Tracer("test").Start(context.TODO(), "reconcile")
+	defer span.End()
+	bound := client.WithContext(ctx)
+	assert.NotSame(t, client, bound)
+	assert.Equal(t, client.NSXChecker, bound.NSXChecker)
+	assert.NotNil(t, bound.VPCClient)
+}
+
+func TestTransport_RoundTripTraced(t *testing.T) {
+	recorder := useSpanRecorder(t)
+	ctx, parent := otel.Tracer("test").Start(context.TODO(), "reconcile")
+
+	config := NewConfig("127.0.0.1", "admin", "passw0rd", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, []string{})
+	cluster := &Cluster{}
+	tr := cluster.createTransport(idleConnTimeout)
+	client := cluster.createHTTPClient(tr, timeout)
+	noBClient := cluster.createNoBalancerClient(timeout, idleConnTimeout)
+	eps, _ := cluster.createEndpoints(config.APIManagers, &client, &noBClient, ratelimiter.NewRateLimiter(config.APIRateMode), nil)
+	eps[0].setStatus(UP)
+	tr.endpoints = eps
+	tr.config = config
+
+	// the trace context is sent to NSX
+	var traceparent string
+	tr.Base = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		traceparent = r.Header.Get("traceparent")
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+	})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPatch, "https://127.0.0.1/policy/api/v1/orgs/default/projects/p1/vpcs/vpc1", nil)
+	_, err := tr.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "", req.Header.Get("traceparent"))
+
+	// the mutations rejected while paused are traced as failed
+	util.SetMutationsPaused(true, "change freeze")
+	defer util.SetMutationsPaused(false, "")
+	req, _ = http.NewRequestWithContext(ctx, http.MethodDelete, "https://127.0.0.1/policy/api/v1/orgs/default/projects/p1/vpcs/vpc1", nil)
+	_, err = tr.RoundTrip(req)
+	assert.True(t, errors.As(err, &util.MutationPausedError{}))
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Equal(t, 3, len(spans))
+	assert.Equal(t, "NSX PATCH vpcs", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Contains(t, traceparent, spans[0].SpanContext().SpanID().String())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "NSX DELETE vpcs", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -5,72 +5,196 @@ package nsx
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/audit"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
 	"github.com/vmware-tanzu/nsx-operator/pkg/third_party/retry"
+	"github.com/vmware-tanzu/nsx-operator/pkg/tracing"
+)
+
+const (
+	// nsxRequestIDHeader is the header of the NSX responses with the ID NSX logs the request with.
+	nsxRequestIDHeader = "X-Nsx-Requestid"
+	// retryBudgetRequests is the number of the requests earning a retry.
+	retryBudgetRequests = 5
+	// retryBudgetMax is the max retries which can be spent in a row.
+	retryBudgetMax = 10
 )
 
+// retryBudget stops retrying the requests once the retries spent outgrow the requests sent, so that the retries
+// cannot multiply the load of an NSX manager which is already failing.
+type retryBudget struct {
+	// debt is the retries spent and not earned back yet, counted in requests.
+	debt int
+	sync.Mutex
+}
+
+// deposit earns a part of a retry for a request.
+func (b *retryBudget) deposit() {
+	b.Lock()
+	defer b.Unlock()
+	if b.debt > 0 {
+		b.debt--
+	}
+}
+
+// withdraw spends a retry, it returns false if the budget is spent.
+func (b *retryBudget) withdraw() bool {
+	b.Lock()
+	defer b.Unlock()
+	if b.debt+retryBudgetRequests > retryBudgetMax*retryBudgetRequests {
+		return false
+	}
+	b.debt += retryBudgetRequests
+	return true
+}
+
 // Transport is used in http.Client to replace default implement.
 // It selects the endpoint before sending HTTP reqeust and  it will retry the request based on HTTP response.
 type Transport struct {
 	Base      http.RoundTripper
 	endpoints []*Endpoint
 	config    *Config
+	// failovers counts the requests moved to another endpoint since the one they were sent to went DOWN.
+	failovers uint64
+	// active is the host of the endpoint serving the latest request.
+	active atomic.Value
+	budget retryBudget
+	sync.RWMutex
 }
 
 // RoundTrip is the core of the transport. It accepts a request,
 // replaces host with the URl provided by the endpoint.
 // It will block the request if the speed is too fast.
-// It will retry the request if nsx-t returns error and error type is retriable or ground
+// It will retry the request if nsx-t returns error and error type is retriable or ground, the retries stick to the
+// endpoint selected first until it goes DOWN, and then fail over to another endpoint.
+// It rejects the request modifying NSX resources if the mutations are paused, and the requests sent while the circuit
+// breakers of all the endpoints are open.
 // It returns the response to the caller.
 func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
 	var resp *http.Response
 	var resul error
+	var ep *Endpoint
+	var requestID string
+	log, wireLog := logger.FromContext(r.Context(), log), logger.FromContext(r.Context(), wireLog)
+
+	// the body of the mutations is read for the audit trail
+	mutation := util.IsMutationMethod(r.Method)
+	var body []byte
+	if wire := wireLog.V(2); wire.Enabled() || mutation {
+		if r.Body != nil {
+			body, _ = ioutil.ReadAll(r.Body)
+			r.Body.Close()
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		wire.Info("NSX request", "method", r.Method, "request", r.URL, "body", string(body))
+	}
+
+	r, span := startRequestSpan(r)
+	defer func() {
+		if ep != nil {
+			span.SetAttributes(attribute.String("nsx.endpoint", ep.Host()))
+		}
+		if resp != nil {
+			span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+		}
+		tracing.End(span, resul)
+		if mutation {
+			auditMutation(r, body, ep, resp, requestID, resul)
+		}
+	}()
+
+	if paused, reason := util.MutationsPaused(); paused && mutation && !util.PauseExempt(r.URL.Path) {
+		err := util.MutationPausedError{Desc: "NSX mutations are paused: " + reason}
+		log.Info("reject request since NSX mutations are paused", "request", r.URL, "method", r.Method)
+		resul = err
+		return nil, err
+	}
 
+	if timeout := t.requestTimeout(r); timeout > 0 {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	t.budget.deposit()
 	retry.Do(
 		func() error {
-			ep, err := t.selectEndpoint()
-			if err != nil {
-				log.Error(err, "endpoint is unavailable")
-				return err
+			if ep == nil || ep.Status() == DOWN || !ep.breaker.allow() {
+				next, err := t.selectEndpoint()
+				if err != nil {
+					log.Error(err, "endpoint is unavailable")
+					resp, resul = nil, err
+					return err
+				}
+				if ep != nil && next != ep {
+					log.Info("fail over to another endpoint", "from", ep.Host(), "to", next.Host(), "request", r.URL)
+					atomic.AddUint64(&t.failovers, 1)
+				}
+				ep = next
 			}
+			t.active.Store(ep.Host())
+			ep.increaseRequestNumber()
 			ep.increaseConnNumber()
 			defer ep.decreaseConnNumber()
 
 			r.URL.Host = ep.Host()
 			ep.UpdateHttpRequestAuth(r)
 			start := time.Now()
-			ep.wait()
+			ep.wait(r)
 			waitTime := time.Since(start)
 			if resp, resul = t.base().RoundTrip(r); resul != nil {
+				observeAPIRequest(r, ep.Host(), nil, waitTime, time.Since(start)-waitTime)
 				ep.setStatus(DOWN)
+				ep.breaker.failure()
 				return handleRoundTripError(resul, ep)
 			}
 			transTime := time.Since(start) - waitTime
-			ep.adjustRate(waitTime, resp.StatusCode)
-			log.V(1).Info("RoundTrip request", "request", r.URL, "method", r.Method, "transTime", transTime)
+			observeAPIRequest(r, ep.Host(), resp, waitTime, transTime)
 			if resp == nil {
 				return nil
 			}
+			requestID = resp.Header.Get(nsxRequestIDHeader)
+			log.V(1).Info("RoundTrip request", "request", r.URL, "method", r.Method, "transTime", transTime, logger.CorrelationKeyNSXRequestID, requestID)
 
 			body, err := ioutil.ReadAll(resp.Body)
 			resp.Body.Close()
 			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			wireLog.V(2).Info("NSX response", "method", r.Method, "request", r.URL, "endpoint", ep.Host(), "status", resp.StatusCode,
+				logger.CorrelationKeyNSXRequestID, requestID, "body", string(body))
 
+			// the requests rejected for themselves, e.g. the search pages too large, don't slow down the endpoint
+			if !clientError(body) {
+				ep.adjustRate(r, waitTime, resp.StatusCode)
+			}
 			if err != nil {
 				log.Error(err, "failed to extract HTTP body")
+				ep.breaker.failure()
 				return util.CreateGeneralManagerError(ep.Host(), "extract http", err.Error())
 			}
+			if endpointFailure(resp.StatusCode, body) {
+				ep.breaker.failure()
+			} else {
+				ep.breaker.success()
+			}
 
 			if err = util.InitErrorFromResponse(ep.Host(), resp.StatusCode, body); err == nil {
 				ep.setAliveTime(start.Add(transTime))
 				return nil
 			}
+			log.Info("NSX request failed", "request", r.URL, "method", r.Method, "status", resp.StatusCode, "error", err.Error(),
+				logger.CorrelationKeyNSXRequestID, requestID)
 			if util.ShouldRegenerate(err) {
 				if t.config.TokenProvider != nil {
 					t.config.TokenProvider.GetToken(true)
@@ -80,9 +204,11 @@ func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
 			}
 			return err
 		}, retry.RetryIf(func(err error) bool {
-			if util.ShouldGroundPoint(err) {
-				return true
-			} else if util.ShouldRetry(err) {
+			if util.ShouldGroundPoint(err) || util.ShouldRetry(err) {
+				if !t.budget.withdraw() {
+					log.Info("retry budget is spent, stop retrying", "request", r.URL, "error", err.Error())
+					return false
+				}
 				return true
 			} else {
 				log.V(1).Info("error is configrated as not retriable", "error", err.Error())
@@ -94,6 +220,51 @@ func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
 	return resp, resul
 }
 
+// auditMutation adds the mutation to the audit trail, the initiating CR is read from the tags of the resources sent, or
+// from the correlation of the reconcile sending it.
+func auditMutation(r *http.Request, body []byte, ep *Endpoint, resp *http.Response, requestID string, err error) {
+	summary, initiator := audit.Summarize(r.Method, body)
+	ctx := r.Context()
+	if name := logger.CorrelationValue(ctx, logger.CorrelationKeyName); initiator == nil && name != "" {
+		initiator = audit.NewInitiator(logger.CorrelationValue(ctx, logger.CorrelationKeyController),
+			logger.CorrelationValue(ctx, logger.CorrelationKeyNamespace), name, logger.CorrelationValue(ctx, logger.CorrelationKeyUID))
+	}
+	record := audit.Record{
+		Time:      time.Now(),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Summary:   summary,
+		Initiator: initiator,
+		Outcome:   audit.OutcomeSucceeded,
+		RequestID: requestID,
+	}
+	if ep != nil {
+		record.Endpoint = ep.Host()
+	}
+	if resp != nil {
+		record.StatusCode = resp.StatusCode
+	}
+	if err != nil {
+		record.Outcome, record.Error = audit.OutcomeFailed, err.Error()
+		if _, ok := err.(util.MutationPausedError); ok {
+			record.Outcome = audit.OutcomeRejected
+		}
+	}
+	audit.Add(record)
+}
+
+// requestTimeout returns the timeout of the read or write request, 0 if it isn't configured. The body of the response
+// is read in RoundTrip, so the request can be canceled once RoundTrip returns.
+func (t *Transport) requestTimeout(r *http.Request) time.Duration {
+	if t.config == nil {
+		return 0
+	}
+	if util.IsMutationMethod(r.Method) {
+		return time.Duration(t.config.WriteTimeout) * time.Second
+	}
+	return time.Duration(t.config.ReadTimeout) * time.Second
+}
+
 func handleRoundTripError(err error, ep *Endpoint) error {
 	log.Error(err, "request failed")
 	errString := err.Error()
@@ -107,6 +278,17 @@ func handleRoundTripError(err error, ep *Endpoint) error {
 	}
 }
 
+// activeEndpoint returns the host of the endpoint serving the latest request, or "" if no request is sent yet.
+func (t *Transport) activeEndpoint() string {
+	host, _ := t.active.Load().(string)
+	return host
+}
+
+// failoverNumber returns the number of the requests failed over to another endpoint.
+func (t *Transport) failoverNumber() uint64 {
+	return atomic.LoadUint64(&t.failovers)
+}
+
 func (t *Transport) base() http.RoundTripper {
 	if t.Base != nil {
 		return t.Base
@@ -114,19 +296,41 @@ func (t *Transport) base() http.RoundTripper {
 	return http.DefaultTransport
 }
 
+func (t *Transport) setEndpoints(eps []*Endpoint) {
+	t.Lock()
+	t.endpoints = eps
+	t.Unlock()
+}
+
 func (t *Transport) selectEndpoint() (*Endpoint, error) {
+	t.RLock()
+	defer t.RUnlock()
 	small := 100
 	index := -1
+	up := 0
 	for i, ep := range t.endpoints {
 		if ep.Status() == DOWN {
 			continue
 		}
+		up++
+		if !ep.breaker.available() {
+			continue
+		}
 		conn := ep.ConnNumber()
 		if conn < small {
 			small = conn
 			index = i
 		}
 	}
+	// the half-open breaker of the endpoint selected lets only one probe through
+	if index != -1 && !t.endpoints[index].breaker.allow() {
+		index = -1
+	}
+	if index == -1 && up > 0 {
+		err := util.NSXUnavailableError{Desc: "circuit breakers of all the NSX endpoints are open"}
+		log.Error(err, "select endpoint failed")
+		return nil, err
+	}
 	if index == -1 {
 		var eps []string
 		for _, i := range t.endpoints {
@@ -6,6 +6,7 @@ package nsx
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -15,8 +16,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/audit"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/auth"
 	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/ratelimiter"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/util"
 )
 
 var (
@@ -148,6 +151,185 @@ func TestTransport_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestTransport_RoundTripPaused(t *testing.T) {
+	util.SetMutationsPaused(true, "change freeze")
+	defer util.SetMutationsPaused(false, "")
+
+	tr := &Transport{}
+	r, _ := http.NewRequest(http.MethodPatch, "https://10.0.0.1/policy/api/v1/infra", nil)
+	resp, err := tr.RoundTrip(r)
+	assert.Nil(t, resp)
+	assert.True(t, errors.As(err, &util.MutationPausedError{}))
+}
+
+func TestTransport_RoundTripPauseExempt(t *testing.T) {
+	util.SetMutationsPaused(true, "change freeze")
+	defer util.SetMutationsPaused(false, "")
+	util.ExemptFromPause("/policy/api/v1/infra/domains/default/groups/exempt")
+
+	tr := &Transport{Base: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("sent")
+	})}
+	r, _ := http.NewRequest(http.MethodPut, "https://10.0.0.1/policy/api/v1/infra/domains/default/groups/exempt", nil)
+	_, err := tr.RoundTrip(r)
+	assert.False(t, errors.As(err, &util.MutationPausedError{}))
+	r, _ = http.NewRequest(http.MethodPut, "https://10.0.0.1/policy/api/v1/infra/domains/default/groups/other", nil)
+	_, err = tr.RoundTrip(r)
+	assert.True(t, errors.As(err, &util.MutationPausedError{}))
+}
+
+func TestTransport_RoundTripAudit(t *testing.T) {
+	util.SetMutationsPaused(true, "change freeze")
+	defer util.SetMutationsPaused(false, "")
+
+	tr := &Transport{}
+	body := `{"resource_type": "StaticRoutes", "tags": [{"scope": "nsx-op/staticroute_cr_name", "tag": "sr1"}, {"scope": "nsx-op/namespace", "tag": "ns1"}]}`
+	r, _ := http.NewRequest(http.MethodPatch, "https://10.0.0.1/policy/api/v1/infra/tier-1s/t1/static-routes/audit-test", strings.NewReader(body))
+	tr.RoundTrip(r)
+	r, _ = http.NewRequest(http.MethodGet, "https://10.0.0.1/policy/api/v1/infra/tier-1s/t1/static-routes/audit-test", nil)
+	tr.RoundTrip(r)
+
+	records := audit.Records("/policy/api/v1/infra/tier-1s/t1/static-routes/audit-test")
+	assert.Len(t, records, 1)
+	assert.Equal(t, audit.OutcomeRejected, records[0].Outcome)
+	assert.Equal(t, "upsert 1 StaticRoutes", records[0].Summary)
+	assert.Equal(t, "StaticRoute ns1/sr1", records[0].Initiator.String())
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTransport_RoundTripFailover(t *testing.T) {
+	a := "127.0.0.1, 127.0.0.2"
+	config := NewConfig(a, "admin", "passw0rd", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, []string{})
+	cluster := &Cluster{}
+	tr := cluster.createTransport(idleConnTimeout)
+	client := cluster.createHTTPClient(tr, timeout)
+	noBClient := cluster.createNoBalancerClient(timeout, idleConnTimeout)
+	r := ratelimiter.NewRateLimiter(config.APIRateMode)
+	eps, _ := cluster.createEndpoints(config.APIManagers, &client, &noBClient, r, nil)
+	for _, ep := range eps {
+		ep.setStatus(UP)
+	}
+	tr.endpoints = eps
+	tr.config = config
+	assert.Equal(t, "", tr.activeEndpoint())
+
+	// the retries of an aborted transaction stick to the endpoint
+	var hosts []string
+	tr.Base = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		hosts = append(hosts, r.URL.Host)
+		if len(hosts) == 1 {
+			body := `{"httpStatus":"INTERNAL_SERVER_ERROR","error_code":607,"error_message":"transaction aborted"}`
+			return &http.Response{StatusCode: http.StatusInternalServerError, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+	})
+	req, _ := http.NewRequest(http.MethodGet, "https://127.0.0.1/policy/api/v1/infra", nil)
+	_, err := tr.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{eps[0].Host(), eps[0].Host()}, hosts)
+	assert.Equal(t, uint64(0), tr.failoverNumber())
+	assert.Equal(t, eps[0].Host(), tr.activeEndpoint())
+	assert.Equal(t, uint64(2), eps[0].RequestNumber())
+
+	// the request fails over to another endpoint when the endpoint is unreachable
+	hosts = nil
+	tr.Base = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		hosts = append(hosts, r.URL.Host)
+		if r.URL.Host == eps[0].Host() {
+			return nil, errors.New("dial tcp: connection refused")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+	})
+	req, _ = http.NewRequest(http.MethodGet, "https://127.0.0.1/policy/api/v1/infra", nil)
+	_, err = tr.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{eps[0].Host(), eps[1].Host()}, hosts)
+	assert.Equal(t, DOWN, eps[0].Status())
+	assert.Equal(t, uint64(1), tr.failoverNumber())
+	assert.Equal(t, eps[1].Host(), tr.activeEndpoint())
+}
+
+func TestTransport_RoundTripBreaker(t *testing.T) {
+	a := "127.0.0.1"
+	config := NewConfig(a, "admin", "passw0rd", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, []string{})
+	cluster := &Cluster{}
+	tr := cluster.createTransport(idleConnTimeout)
+	client := cluster.createHTTPClient(tr, timeout)
+	noBClient := cluster.createNoBalancerClient(timeout, idleConnTimeout)
+	r := ratelimiter.NewRateLimiter(config.APIRateMode)
+	eps, _ := cluster.createEndpoints(config.APIManagers, &client, &noBClient, r, nil)
+	eps[0].setStatus(UP)
+	eps[0].breaker = newCircuitBreaker(eps[0].Host(), 2, time.Minute)
+	tr.endpoints = eps
+	tr.config = config
+
+	sent := 0
+	tr.Base = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent++
+		body := `{"httpStatus":"INTERNAL_SERVER_ERROR","error_code":98,"error_message":"cannot connect to server"}`
+		return &http.Response{StatusCode: http.StatusInternalServerError, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+	})
+	req, _ := http.NewRequest(http.MethodGet, "https://127.0.0.1/policy/api/v1/infra", nil)
+	_, err := tr.RoundTrip(req)
+	// the breaker opens after 2 failures, and the retry is short-circuited
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, BreakerOpen, eps[0].BreakerState())
+	assert.True(t, errors.As(err, &util.NSXUnavailableError{}))
+
+	_, err = tr.RoundTrip(req)
+	assert.Equal(t, 2, sent)
+	assert.True(t, errors.As(err, &util.NSXUnavailableError{}))
+}
+
+func TestTransport_RoundTripBreakerPageMax(t *testing.T) {
+	a := "127.0.0.1"
+	config := NewConfig(a, "admin", "passw0rd", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, []string{})
+	cluster := &Cluster{}
+	tr := cluster.createTransport(idleConnTimeout)
+	client := cluster.createHTTPClient(tr, timeout)
+	noBClient := cluster.createNoBalancerClient(timeout, idleConnTimeout)
+	r := ratelimiter.NewRateLimiter(config.APIRateMode)
+	eps, _ := cluster.createEndpoints(config.APIManagers, &client, &noBClient, r, nil)
+	eps[0].setStatus(UP)
+	eps[0].breaker = newCircuitBreaker(eps[0].Host(), 2, time.Minute)
+	eps[0].buckets = ratelimiter.NewBuckets(ratelimiter.FIXRATE, nil)
+	tr.endpoints = eps
+	tr.config = config
+
+	tr.Base = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		body := `{"httpStatus":"SERVICE_UNAVAILABLE","error_code":60576,"error_message":"page size exceeds max"}`
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+	})
+	// the pages rejected as too large neither open the breaker nor back off the endpoint
+	req, _ := http.NewRequest(http.MethodGet, "https://127.0.0.1/policy/api/v1/search/query", nil)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := tr.RoundTrip(req)
+		assert.False(t, errors.As(err, &util.NSXUnavailableError{}))
+	}
+	assert.Equal(t, BreakerClosed, eps[0].BreakerState())
+	assert.Less(t, time.Since(start), ratelimiter.MinBackoff)
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := &retryBudget{}
+	for i := 0; i < retryBudgetMax; i++ {
+		assert.True(t, b.withdraw())
+	}
+	assert.False(t, b.withdraw())
+	// each request earns a part of a retry
+	for i := 0; i < retryBudgetRequests; i++ {
+		b.deposit()
+	}
+	assert.True(t, b.withdraw())
+	assert.False(t, b.withdraw())
+}
+
 func Test_handleRoundTripError(t *testing.T) {
 	a := "127.0.0.1, 127.0.0.2, 127.0.0.3"
 	config := NewConfig(a, "admin", "passw0rd", "", 10, 3, 20, 20, true, true, true, ratelimiter.AIMD, nil, nil, []string{})
@@ -227,3 +409,27 @@ func TestTransport_selectEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestTransport_requestTimeout(t *testing.T) {
+	tr := &Transport{}
+	get, _ := http.NewRequest(http.MethodGet, "https://10.0.0.1/policy/api/v1/infra", nil)
+	patch, _ := http.NewRequest(http.MethodPatch, "https://10.0.0.1/policy/api/v1/infra", nil)
+	assert.Equal(t, time.Duration(0), tr.requestTimeout(get))
+
+	tr.config = &Config{ReadTimeout: 10, WriteTimeout: 60}
+	assert.Equal(t, 10*time.Second, tr.requestTimeout(get))
+	assert.Equal(t, 60*time.Second, tr.requestTimeout(patch))
+
+	// the request carries the deadline of its timeout
+	eps, _ := (&Cluster{}).createEndpoints([]string{"127.0.0.1"}, &http.Client{}, &http.Client{}, ratelimiter.NewRateLimiter(ratelimiter.AIMD), nil)
+	eps[0].setStatus(UP)
+	tr.endpoints = eps
+	var deadline time.Time
+	tr.Base = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		deadline, _ = r.Context().Deadline()
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+	})
+	_, err := tr.RoundTrip(get)
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), deadline, time.Second)
+}
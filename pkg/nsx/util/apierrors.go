@@ -0,0 +1,188 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	vapistd "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/bindings"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+)
+
+// NSXAPIError is the failure of an NSX API request decoded from the NSX API error body, it is embedded in the typed
+// errors below so that the callers can tell the failures apart with errors.As.
+type NSXAPIError struct {
+	// ErrorCode is the NSX error code, 0 if the error body doesn't carry one.
+	ErrorCode int64
+	// HTTPStatus is the HTTP status the error body carries, e.g. TOO_MANY_REQUESTS, empty if it doesn't carry one.
+	HTTPStatus string
+	Desc       string
+	// Err is the error of the SDK or the HTTP client the error is decoded from.
+	Err error
+}
+
+func (err NSXAPIError) Error() string {
+	return err.Desc
+}
+
+func (err NSXAPIError) Unwrap() error {
+	return err.Err
+}
+
+// NotFoundError is returned when the NSX resource, or a resource it refers to, doesn't exist.
+type NotFoundError struct {
+	NSXAPIError
+}
+
+// ConflictError is returned when the NSX resource was changed concurrently, or its revision is stale.
+type ConflictError struct {
+	NSXAPIError
+}
+
+// UnauthorizedError is returned when NSX rejects the credentials of NSX Operator, or NSX Operator isn't permitted to
+// do the request.
+type UnauthorizedError struct {
+	NSXAPIError
+}
+
+// ThrottledError is returned when NSX is too busy to serve the request, it should be sent again later.
+type ThrottledError struct {
+	NSXAPIError
+}
+
+// UnavailableError is returned when NSX fails to serve the request for an outage rather than for its load, e.g. a
+// manager being restarted, it should be sent again once NSX is back.
+type UnavailableError struct {
+	NSXAPIError
+}
+
+// ValidationFailedError is returned when NSX rejects the request as invalid, it fails again until the request is
+// changed.
+type ValidationFailedError struct {
+	NSXAPIError
+}
+
+// staleRevisionErrorCodes are the NSX error codes of the requests rejected as the revision is stale.
+var staleRevisionErrorCodes = map[int64]bool{500090: true, 500087: true, 500232: true, 100148: true}
+
+// throttledErrorCodes are the NSX error codes of the requests rejected as NSX Operator exceeds the API rate limits.
+var throttledErrorCodes = map[int64]bool{102: true}
+
+// httpStatusTooManyRequests is the HTTP status of the error body of a 429 response.
+const httpStatusTooManyRequests = "TOO_MANY_REQUESTS"
+
+// TransNSXError translates the errors of the NSX API requests returned by the SDK or the HTTP client to the typed
+// errors decoded from the NSX API error body. The other errors, and the errors already translated, are returned as
+// they are.
+func TransNSXError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch e := err.(type) {
+	case NotFoundError, ConflictError, UnauthorizedError, ThrottledError, UnavailableError, ValidationFailedError:
+		return err
+	case vapierrors.NotFound:
+		return NotFoundError{newNSXAPIError(err, e.Messages, e.Data)}
+	case vapierrors.ConcurrentChange:
+		return ConflictError{newNSXAPIError(err, e.Messages, e.Data)}
+	case vapierrors.AlreadyExists:
+		return ConflictError{newNSXAPIError(err, e.Messages, e.Data)}
+	case vapierrors.Unauthenticated:
+		return UnauthorizedError{newNSXAPIError(err, e.Messages, e.Data)}
+	case vapierrors.Unauthorized:
+		return UnauthorizedError{newNSXAPIError(err, e.Messages, e.Data)}
+	case vapierrors.ServiceUnavailable:
+		// both 429 and 503 responses are reported as ServiceUnavailable, the throttled ones are told by the error body
+		apiErr := newNSXAPIError(err, e.Messages, e.Data)
+		if apiErr.HTTPStatus == httpStatusTooManyRequests || throttledErrorCodes[apiErr.ErrorCode] {
+			return ThrottledError{apiErr}
+		}
+		return UnavailableError{apiErr}
+	case vapierrors.InvalidRequest:
+		apiErr := newNSXAPIError(err, e.Messages, e.Data)
+		if staleRevisionErrorCodes[apiErr.ErrorCode] {
+			return ConflictError{apiErr}
+		}
+		return ValidationFailedError{apiErr}
+	case vapierrors.InvalidArgument:
+		return ValidationFailedError{newNSXAPIError(err, e.Messages, e.Data)}
+	}
+	// the errors decoded by InitErrorFromResponse from the responses of the HTTP client
+	var nsxErr NsxError
+	if !errors.As(err, &nsxErr) {
+		return err
+	}
+	apiErr := NSXAPIError{Desc: err.Error(), Err: err}
+	switch e := nsxErr.(type) {
+	case *ResourceNotFound:
+		apiErr.ErrorCode = int64(e.ErrorCode)
+		return NotFoundError{apiErr}
+	case *BackendResourceNotFound:
+		apiErr.ErrorCode = int64(e.ErrorCode)
+		return NotFoundError{apiErr}
+	case *StaleRevision:
+		apiErr.ErrorCode = int64(e.ErrorCode)
+		return ConflictError{apiErr}
+	case *InvalidCredentials, *ClientCertificateNotTrusted, *BadXSRFToken:
+		return UnauthorizedError{apiErr}
+	case *TooManyRequests:
+		apiErr.ErrorCode = int64(e.ErrorCode)
+		return ThrottledError{apiErr}
+	case *ServiceUnavailable:
+		apiErr.ErrorCode = int64(e.ErrorCode)
+		if throttledErrorCodes[apiErr.ErrorCode] {
+			return ThrottledError{apiErr}
+		}
+		return UnavailableError{apiErr}
+	}
+	return err
+}
+
+// newNSXAPIError decodes the NSX API error body the vAPI error carries.
+func newNSXAPIError(err error, messages []vapistd.LocalizableMessage, errorData *data.StructValue) NSXAPIError {
+	apiErr := NSXAPIError{Err: err}
+	var msgs []string
+	for _, message := range messages {
+		if message.DefaultMessage != "" {
+			msgs = append(msgs, message.DefaultMessage)
+		}
+	}
+	if errorData != nil {
+		if status, err := errorData.Field("httpStatus"); err == nil {
+			if status, ok := status.(*data.StringValue); ok {
+				apiErr.HTTPStatus = status.Value()
+			}
+		}
+		converter := bindings.NewTypeConverter()
+		converter.SetMode(bindings.REST)
+		if dataError, errs := converter.ConvertToGolang(errorData, model.ApiErrorBindingType()); len(errs) == 0 {
+			if e, ok := dataError.(model.ApiError); ok {
+				if e.ErrorCode != nil {
+					apiErr.ErrorCode = *e.ErrorCode
+				}
+				if e.ErrorMessage != nil {
+					msgs = append(msgs, *e.ErrorMessage)
+				}
+				for _, related := range e.RelatedErrors {
+					if related.ErrorMessage != nil {
+						msgs = append(msgs, *related.ErrorMessage)
+					}
+				}
+			}
+		}
+	}
+	apiErr.Desc = err.Error()
+	if len(msgs) > 0 {
+		apiErr.Desc = strings.Join(msgs, "; ")
+	}
+	if apiErr.ErrorCode != 0 {
+		apiErr.Desc = fmt.Sprintf("%s (error code %d)", apiErr.Desc, apiErr.ErrorCode)
+	}
+	return apiErr
+}
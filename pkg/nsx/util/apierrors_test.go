@@ -0,0 +1,96 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	vapistd "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std"
+	vapierrors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/bindings"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+)
+
+func apiErrorData(t *testing.T, code int64, message string, related ...string) *data.StructValue {
+	apiError := model.ApiError{ErrorCode: &code, ErrorMessage: &message}
+	for i := range related {
+		apiError.RelatedErrors = append(apiError.RelatedErrors, model.RelatedApiError{ErrorMessage: &related[i]})
+	}
+	converter := bindings.NewTypeConverter()
+	converter.SetMode(bindings.REST)
+	dataValue, errs := converter.ConvertToVapi(apiError, model.ApiErrorBindingType())
+	assert.Empty(t, errs)
+	return dataValue.(*data.StructValue)
+}
+
+// tooManyRequestsData is the error body of a 429 response.
+func tooManyRequestsData(t *testing.T) *data.StructValue {
+	errorData := apiErrorData(t, 0, "Too many requests")
+	errorData.SetField("httpStatus", data.NewStringValue("TOO_MANY_REQUESTS"))
+	return errorData
+}
+
+func TestTransNSXError(t *testing.T) {
+	assert.Nil(t, TransNSXError(nil))
+	other := errors.New("connection refused")
+	assert.Equal(t, other, TransNSXError(other))
+
+	tests := []struct {
+		name string
+		err  error
+		want interface{}
+	}{
+		{"not found", vapierrors.NotFound{Data: apiErrorData(t, 500012, "Segment seg1 not found")}, NotFoundError{}},
+		{"concurrent change", vapierrors.ConcurrentChange{}, ConflictError{}},
+		{"already exists", vapierrors.AlreadyExists{}, ConflictError{}},
+		{"unauthenticated", vapierrors.Unauthenticated{}, UnauthorizedError{}},
+		{"unauthorized", vapierrors.Unauthorized{}, UnauthorizedError{}},
+		{"service unavailable", vapierrors.ServiceUnavailable{}, UnavailableError{}},
+		{"service unavailable too many requests", vapierrors.ServiceUnavailable{Data: tooManyRequestsData(t)}, ThrottledError{}},
+		{"service unavailable rate exceeded", vapierrors.ServiceUnavailable{Data: apiErrorData(t, 102, "Client exceeded the request rate")}, ThrottledError{}},
+		{"stale revision", vapierrors.InvalidRequest{Data: apiErrorData(t, 500090, "The object was modified by somebody else")}, ConflictError{}},
+		{"invalid request", vapierrors.InvalidRequest{Data: apiErrorData(t, 500045, "Invalid CIDR")}, ValidationFailedError{}},
+		{"invalid argument", vapierrors.InvalidArgument{}, ValidationFailedError{}},
+		{"resource not found", CreateResourceNotFound("192.168.1.1", "ippool"), NotFoundError{}},
+		{"nsx stale revision", CreateStaleRevision("Segment", "seg1", "", "", "", "1", "1"), ConflictError{}},
+		{"invalid credentials", CreateInvalidCredentials("bad password"), UnauthorizedError{}},
+		{"too many requests", fmt.Errorf("wrapped: %w", CreateTooManyRequests("Segment", "seg1", "", "", "", "1", "1")), ThrottledError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TransNSXError(tt.err)
+			assert.IsType(t, tt.want, got)
+			assert.Equal(t, tt.err, errors.Unwrap(got))
+			// translating again keeps the typed error
+			assert.Equal(t, got, TransNSXError(got))
+		})
+	}
+
+	// the errors decoded from the responses are shared, so they are translated right after they are decoded
+	assert.IsType(t, UnavailableError{}, TransNSXError(InitErrorFromResponse("127.0.0.1", 503, []byte(`{"error_code":98,"error_message":"Service is restarting"}`))))
+	assert.IsType(t, ThrottledError{}, TransNSXError(InitErrorFromResponse("127.0.0.1", 503, []byte(`{"error_code":102,"error_message":"Client exceeded the request rate"}`))))
+
+	var notDecoded NsxError = CreateNsxSearchTimeout()
+	assert.Equal(t, notDecoded, TransNSXError(notDecoded))
+}
+
+func TestNewNSXAPIError(t *testing.T) {
+	err := vapierrors.InvalidRequest{
+		Messages: []vapistd.LocalizableMessage{{DefaultMessage: "Request is invalid"}},
+		Data:     apiErrorData(t, 500045, "Invalid CIDR", "Prefix length 33 is out of range"),
+	}
+	validationFailed := ValidationFailedError{}
+	assert.True(t, errors.As(TransNSXError(err), &validationFailed))
+	assert.Equal(t, int64(500045), validationFailed.ErrorCode)
+	assert.Equal(t, "Request is invalid; Invalid CIDR; Prefix length 33 is out of range (error code 500045)", validationFailed.Error())
+
+	notFound := NotFoundError{}
+	assert.True(t, errors.As(TransNSXError(vapierrors.NotFound{}), &notFound))
+	assert.Equal(t, int64(0), notFound.ErrorCode)
+	assert.Equal(t, vapierrors.NotFound{}.Error(), notFound.Error())
+}
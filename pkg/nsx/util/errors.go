@@ -578,3 +578,33 @@ type RestrictionError struct {
 func (err RestrictionError) Error() string {
 	return err.Desc
 }
+
+// MutationPausedError is returned when a request modifying NSX resources is sent while the mutations are paused.
+type MutationPausedError struct {
+	Desc string
+}
+
+func (err MutationPausedError) Error() string {
+	return err.Desc
+}
+
+// NSXUnavailableError is returned when the circuit breakers of all the NSX manager endpoints are open or all the
+// endpoints are DOWN, the callers should try again later instead of retrying immediately.
+type NSXUnavailableError struct {
+	Desc string
+}
+
+func (err NSXUnavailableError) Error() string {
+	return err.Desc
+}
+
+// IPExhaustedError is returned when an IP allocator cannot satisfy a request because the pool runs out of IPs.
+type IPExhaustedError struct {
+	// Pool is the IP pool, IP block or SubnetSet which runs out of IPs.
+	Pool string
+	Desc string
+}
+
+func (err IPExhaustedError) Error() string {
+	return err.Desc
+}
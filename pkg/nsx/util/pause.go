@@ -0,0 +1,62 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package util
+
+import (
+	"net/http"
+	"sync"
+)
+
+// pauseState holds the global switch which pauses all the NSX mutations.
+// Read requests are still allowed when the mutations are paused, so that status and metrics keep being updated.
+var pauseState = struct {
+	sync.RWMutex
+	paused bool
+	reason string
+	// exempt are the paths of the NSX API whose mutations are not paused.
+	exempt map[string]bool
+}{exempt: map[string]bool{}}
+
+// SetMutationsPaused turns on or off the global NSX mutation pause switch.
+func SetMutationsPaused(paused bool, reason string) {
+	pauseState.Lock()
+	defer pauseState.Unlock()
+	if pauseState.paused != paused {
+		log.Info("NSX mutation pause switch changed", "paused", paused, "reason", reason)
+	}
+	pauseState.paused = paused
+	pauseState.reason = reason
+}
+
+// MutationsPaused returns whether the NSX mutations are paused and the reason of the pause.
+func MutationsPaused() (bool, string) {
+	pauseState.RLock()
+	defer pauseState.RUnlock()
+	return pauseState.paused, pauseState.reason
+}
+
+// ExemptFromPause lets the mutations of the NSX API path go on when the mutations are paused, e.g. the heartbeat of
+// the active NSX Operator, which would lose the leadership without the standby one being able to take over.
+func ExemptFromPause(path string) {
+	pauseState.Lock()
+	defer pauseState.Unlock()
+	pauseState.exempt[path] = true
+}
+
+// PauseExempt returns whether the mutations of the NSX API path go on when the mutations are paused.
+func PauseExempt(path string) bool {
+	pauseState.RLock()
+	defer pauseState.RUnlock()
+	return pauseState.exempt[path]
+}
+
+// IsMutationMethod returns true if the HTTP method modifies resources on NSX.
+func IsMutationMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
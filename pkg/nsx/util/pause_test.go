@@ -0,0 +1,37 @@
+/* Copyright © 2022 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package util
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMutationsPaused(t *testing.T) {
+	defer SetMutationsPaused(false, "")
+
+	paused, _ := MutationsPaused()
+	assert.False(t, paused)
+
+	SetMutationsPaused(true, "change freeze")
+	paused, reason := MutationsPaused()
+	assert.True(t, paused)
+	assert.Equal(t, "change freeze", reason)
+
+	SetMutationsPaused(false, "")
+	paused, reason = MutationsPaused()
+	assert.False(t, paused)
+	assert.Equal(t, "", reason)
+}
+
+func TestIsMutationMethod(t *testing.T) {
+	assert.False(t, IsMutationMethod(http.MethodGet))
+	assert.False(t, IsMutationMethod(http.MethodHead))
+	assert.True(t, IsMutationMethod(http.MethodPatch))
+	assert.True(t, IsMutationMethod(http.MethodPut))
+	assert.True(t, IsMutationMethod(http.MethodPost))
+	assert.True(t, IsMutationMethod(http.MethodDelete))
+}
@@ -0,0 +1,215 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	// tracesPath is the path of the OTLP/HTTP endpoint receiving the spans.
+	tracesPath    = "/v1/traces"
+	exportTimeout = 10 * time.Second
+)
+
+// OTLPExporter sends the spans to an OTLP/HTTP endpoint, e.g. the OpenTelemetry Collector, in the JSON encoding of
+// OTLP.
+type OTLPExporter struct {
+	url    string
+	client *http.Client
+}
+
+// NewOTLPExporter creates the exporter sending the spans to endpoint, e.g. http://otel-collector:4318.
+func NewOTLPExporter(endpoint string) *OTLPExporter {
+	return &OTLPExporter{
+		url:    strings.TrimSuffix(endpoint, "/") + tracesPath,
+		client: &http.Client{Timeout: exportTimeout},
+	}
+}
+
+// ExportSpans sends the spans in one request, they are dropped if the endpoint fails to receive them.
+func (e *OTLPExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(otlpRequest(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to export %d spans to %s: %s %s", len(spans), e.url, resp.Status, message)
+	}
+	return nil
+}
+
+// Shutdown does nothing, the spans are sent as soon as they are exported.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// The OTLP JSON encoding of the spans, the IDs are in hex and the 64-bit integers are strings.
+type exportTraceServiceRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+type scope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Events            []event    `json:"events,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type event struct {
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []keyValue `json:"attributes,omitempty"`
+}
+
+type status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// The status codes of OTLP, they are not numbered like the codes of the SDK.
+const (
+	statusCodeOK    = 1
+	statusCodeError = 2
+)
+
+// otlpRequest groups the spans by resource and instrumentation scope.
+func otlpRequest(spans []sdktrace.ReadOnlySpan) *exportTraceServiceRequest {
+	request := &exportTraceServiceRequest{}
+	resourceIndex := map[attribute.Distinct]int{}
+	scopeIndex := map[attribute.Distinct]map[string]int{}
+	for _, s := range spans {
+		resourceKey := s.Resource().Equivalent()
+		ri, ok := resourceIndex[resourceKey]
+		if !ok {
+			ri = len(request.ResourceSpans)
+			resourceIndex[resourceKey] = ri
+			scopeIndex[resourceKey] = map[string]int{}
+			request.ResourceSpans = append(request.ResourceSpans, resourceSpans{Resource: resource{Attributes: keyValues(s.Resource().Attributes())}})
+		}
+		rs := &request.ResourceSpans[ri]
+		scopeKey := s.InstrumentationScope().Name + "@" + s.InstrumentationScope().Version
+		si, ok := scopeIndex[resourceKey][scopeKey]
+		if !ok {
+			si = len(rs.ScopeSpans)
+			scopeIndex[resourceKey][scopeKey] = si
+			rs.ScopeSpans = append(rs.ScopeSpans, scopeSpans{Scope: scope{Name: s.InstrumentationScope().Name, Version: s.InstrumentationScope().Version}})
+		}
+		rs.ScopeSpans[si].Spans = append(rs.ScopeSpans[si].Spans, otlpSpan(s))
+	}
+	return request
+}
+
+func otlpSpan(s sdktrace.ReadOnlySpan) span {
+	result := span{
+		TraceID:           s.SpanContext().TraceID().String(),
+		SpanID:            s.SpanContext().SpanID().String(),
+		Name:              s.Name(),
+		Kind:              int(s.SpanKind()),
+		StartTimeUnixNano: strconv.FormatInt(s.StartTime().UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.EndTime().UnixNano(), 10),
+		Attributes:        keyValues(s.Attributes()),
+	}
+	if s.Parent().HasSpanID() {
+		result.ParentSpanID = s.Parent().SpanID().String()
+	}
+	for _, e := range s.Events() {
+		result.Events = append(result.Events, event{
+			TimeUnixNano: strconv.FormatInt(e.Time.UnixNano(), 10),
+			Name:         e.Name,
+			Attributes:   keyValues(e.Attributes),
+		})
+	}
+	switch s.Status().Code {
+	case codes.Ok:
+		result.Status.Code = statusCodeOK
+	case codes.Error:
+		result.Status = status{Code: statusCodeError, Message: s.Status().Description}
+	}
+	return result
+}
+
+func keyValues(attrs []attribute.KeyValue) []keyValue {
+	var result []keyValue
+	for _, attr := range attrs {
+		value := anyValue{}
+		switch attr.Value.Type() {
+		case attribute.BOOL:
+			b := attr.Value.AsBool()
+			value.BoolValue = &b
+		case attribute.INT64:
+			i := strconv.FormatInt(attr.Value.AsInt64(), 10)
+			value.IntValue = &i
+		case attribute.FLOAT64:
+			f := attr.Value.AsFloat64()
+			value.DoubleValue = &f
+		default:
+			s := attr.Value.Emit()
+			value.StringValue = &s
+		}
+		result = append(result, keyValue{Key: string(attr.Key), Value: value})
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestOTLPExporter_ExportSpans(t *testing.T) {
+	var received exportTraceServiceRequest
+	var path, contentType string
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		body, _ := ioutil.ReadAll(r.Body)
+		received = exportTraceServiceRequest{}
+		assert.Nil(t, json.Unmarshal(body, &received))
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(recorder),
+		sdktrace.WithResource(sdkresource.NewSchemaless(attribute.String("service.name", "nsx-operator"))),
+	)
+	ctx, parent := provider.Tracer(TracerName).Start(context.TODO(), "Reconcile vpc", trace.WithAttributes(attribute.String("name", "vpc1")))
+	_, child := provider.Tracer(TracerName).Start(ctx, "NSX PATCH vpcs", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("http.status_code", 200), attribute.Bool("retried", false)))
+	child.End()
+	End(parent, errors.New("failed"))
+
+	exporter := NewOTLPExporter(server.URL + "/")
+	assert.Nil(t, exporter.ExportSpans(context.TODO(), recorder.Ended()))
+	assert.Equal(t, tracesPath, path)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, 1, len(received.ResourceSpans))
+	assert.Equal(t, "service.name", received.ResourceSpans[0].Resource.Attributes[0].Key)
+	assert.Equal(t, 1, len(received.ResourceSpans[0].ScopeSpans))
+	scopeSpans := received.ResourceSpans[0].ScopeSpans[0]
+	assert.Equal(t, TracerName, scopeSpans.Scope.Name)
+	assert.Equal(t, 2, len(scopeSpans.Spans))
+
+	nsxSpan := scopeSpans.Spans[0]
+	assert.Equal(t, "NSX PATCH vpcs", nsxSpan.Name)
+	assert.Equal(t, int(trace.SpanKindClient), nsxSpan.Kind)
+	assert.Equal(t, parent.SpanContext().SpanID().String(), nsxSpan.ParentSpanID)
+	assert.Equal(t, parent.SpanContext().TraceID().String(), nsxSpan.TraceID)
+	assert.Equal(t, "200", *nsxSpan.Attributes[0].Value.IntValue)
+	assert.False(t, *nsxSpan.Attributes[1].Value.BoolValue)
+	assert.Equal(t, 0, nsxSpan.Status.Code)
+
+	reconcileSpan := scopeSpans.Spans[1]
+	assert.Equal(t, "", reconcileSpan.ParentSpanID)
+	assert.Equal(t, "vpc1", *reconcileSpan.Attributes[0].Value.StringValue)
+	assert.Equal(t, status{Code: statusCodeError, Message: "failed"}, reconcileSpan.Status)
+	assert.Equal(t, "exception", reconcileSpan.Events[0].Name)
+
+	// the spans are dropped if the endpoint rejects them
+	statusCode = http.StatusBadRequest
+	assert.NotNil(t, exporter.ExportSpans(context.TODO(), recorder.Ended()))
+	assert.Nil(t, exporter.ExportSpans(context.TODO(), nil))
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+const (
+	// TracerName is the name of the tracer of the spans of NSX Operator.
+	TracerName  = "github.com/vmware-tanzu/nsx-operator"
+	serviceName = "nsx-operator"
+)
+
+var log = logf.Log.WithName("tracing")
+
+// Init exports the spans to the OTLP endpoint of cf, it returns the function flushing the spans not exported yet and
+// stopping the exporter. Nothing is traced if no endpoint is configured.
+func Init(cf *config.NSXOperatorConfig) func(context.Context) error {
+	if cf.TracingEndpoint == "" {
+		return func(context.Context) error { return nil }
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(NewOTLPExporter(cf.TracingEndpoint)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cf.TracingSampleRatio))),
+		sdktrace.WithResource(sdkresource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+			attribute.String("k8s.cluster.name", cf.Cluster),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	log.Info("exporting traces", "endpoint", cf.TracingEndpoint, "sampleRatio", cf.TracingSampleRatio)
+	return provider.Shutdown
+}
+
+// Start starts a span as a child of the span of ctx, the span is not recorded unless tracing is enabled.
+func Start(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// End ends the span, it is marked as failed if err is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// IsRecording returns whether the span of ctx is recorded, so that the callers can skip the work only needed to trace.
+func IsRecording(ctx context.Context) bool {
+	return trace.SpanFromContext(ctx).IsRecording()
+}
@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestInit(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(provider)
+
+	// nothing is traced without an endpoint
+	cf := &config.NSXOperatorConfig{DefaultConfig: &config.DefaultConfig{}, CoeConfig: &config.CoeConfig{Cluster: "k8scl-one"}}
+	shutdown := Init(cf)
+	assert.Nil(t, shutdown(context.TODO()))
+	ctx, span := Start(context.TODO(), "Reconcile vpc", trace.SpanKindInternal)
+	assert.False(t, span.IsRecording())
+	assert.False(t, IsRecording(ctx))
+
+	cf.TracingEndpoint = "http://127.0.0.1:4318"
+	cf.TracingSampleRatio = 1
+	shutdown = Init(cf)
+	ctx, span = Start(context.TODO(), "Reconcile vpc", trace.SpanKindInternal)
+	assert.True(t, IsRecording(ctx))
+	_, child := Start(ctx, "NSX GET vpcs", trace.SpanKindClient)
+	assert.Equal(t, span.SpanContext().TraceID(), child.SpanContext().TraceID())
+	End(child, nil)
+	End(span, nil)
+	shutdown(context.TODO())
+
+	// the traces are not sampled with ratio 0
+	cf.TracingSampleRatio = 0
+	shutdown = Init(cf)
+	ctx, _ = Start(context.TODO(), "Reconcile vpc", trace.SpanKindInternal)
+	assert.False(t, IsRecording(ctx))
+	assert.Nil(t, shutdown(context.TODO()))
+}
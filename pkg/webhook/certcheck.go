@@ -0,0 +1,59 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package webhook
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// CertChecker returns the readiness check of the webhook server, it fails until the server serves TLS and while its
+// certificate can't be loaded or is expired, so that the API server doesn't call a webhook rejecting the handshakes.
+func CertChecker(server *crwebhook.Server) healthz.Checker {
+	started := server.StartedChecker()
+	return func(req *http.Request) error {
+		if err := checkCert(server); err != nil {
+			return err
+		}
+		return started(req)
+	}
+}
+
+// certFiles returns the paths of the certificate and the key the webhook server serves.
+func certFiles(server *crwebhook.Server) (string, string) {
+	certDir, certName, keyName := server.CertDir, server.CertName, server.KeyName
+	if certDir == "" {
+		certDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+	}
+	if certName == "" {
+		certName = "tls.crt"
+	}
+	if keyName == "" {
+		keyName = "tls.key"
+	}
+	return filepath.Join(certDir, certName), filepath.Join(certDir, keyName)
+}
+
+func checkCert(server *crwebhook.Server) error {
+	cert, err := tls.LoadX509KeyPair(certFiles(server))
+	if err != nil {
+		return fmt.Errorf("failed to load the webhook certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse the webhook certificate: %w", err)
+	}
+	if now := time.Now(); now.After(leaf.NotAfter) || now.Before(leaf.NotBefore) {
+		return fmt.Errorf("webhook certificate is only valid from %s to %s", leaf.NotBefore, leaf.NotAfter)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func writeCert(t *testing.T, dir string, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "nsx-operator-webhook"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "tls.crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "tls.key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+}
+
+func TestCheckCert(t *testing.T) {
+	dir := t.TempDir()
+	server := &crwebhook.Server{CertDir: dir}
+	assert.ErrorContains(t, checkCert(server), "failed to load the webhook certificate")
+
+	writeCert(t, dir, time.Now().Add(-time.Hour))
+	assert.ErrorContains(t, checkCert(server), "webhook certificate is only valid from")
+
+	writeCert(t, dir, time.Now().Add(time.Hour))
+	assert.Nil(t, checkCert(server))
+	// the server is not started
+	assert.NotNil(t, CertChecker(server)(nil))
+}
@@ -0,0 +1,342 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+)
+
+var log = logger.Log
+
+const (
+	// certCheckInterval is the interval to check if the webhook certificate is to be rotated, or was rotated by
+	// another replica.
+	certCheckInterval = time.Hour
+	// caKeyKey is the key of the private key of the CA in the Secret of the webhook certificate.
+	caKeyKey = "ca.key"
+)
+
+// CertRotator generates the serving certificate of the webhooks and the CA signing it into a Secret, serves it with
+// the webhook server and injects the CA bundle into the ValidatingWebhookConfigurations, the
+// MutatingWebhookConfigurations and the conversion webhooks of the CRDs of the webhook Service. It rotates them once
+// two thirds of their validity elapsed, the bundle keeps the previous CA until the API servers trust the new one. It
+// runs in all the replicas, which all serve the webhooks, the replicas take the certificate of the Secret generated
+// by the first one.
+type CertRotator struct {
+	Client client.Client
+	// Reader reads the Secret and the webhook configurations from the API server, they are not cached.
+	Reader      client.Reader
+	Namespace   string
+	SecretName  string
+	ServiceName string
+	Validity    time.Duration
+	certFile    string
+	keyFile     string
+	now         func() time.Time
+}
+
+func NewCertRotator(cf *config.NSXOperatorConfig, server *crwebhook.Server, c client.Client, reader client.Reader) *CertRotator {
+	namespace, name := cf.GetWebhookCertSecret()
+	certFile, keyFile := certFiles(server)
+	return &CertRotator{
+		Client:      c,
+		Reader:      reader,
+		Namespace:   namespace,
+		SecretName:  name,
+		ServiceName: cf.WebhookService,
+		Validity:    cf.GetWebhookCertValidity(),
+		certFile:    certFile,
+		keyFile:     keyFile,
+		now:         time.Now,
+	}
+}
+
+// Start checks the webhook certificate every certCheckInterval until ctx is done, Ensure must have succeeded once
+// before the webhook server starts.
+func (r *CertRotator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(certCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Ensure(ctx); err != nil {
+				log.Error(err, "failed to rotate the webhook certificate")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false, the standby replicas serve the webhooks too.
+func (r *CertRotator) NeedLeaderElection() bool {
+	return false
+}
+
+// Ensure generates the webhook certificate if the Secret has none or it is to be rotated, injects the CA bundle into
+// the webhook configurations and the CRDs and writes the certificate for the webhook server, which reloads it. The CA
+// bundle is injected before the certificate is served, so that the API servers trust it.
+func (r *CertRotator) Ensure(ctx context.Context) error {
+	secret, err := r.ensureSecret(ctx)
+	if err != nil {
+		return err
+	}
+	caBundle := secret.Data[corev1.ServiceAccountRootCAKey]
+	if err := r.injectCABundle(ctx, caBundle); err != nil {
+		return err
+	}
+	return r.writeCert(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+}
+
+func (r *CertRotator) ensureSecret(ctx context.Context) (*corev1.Secret, error) {
+	// the replicas race to create or rotate the certificate, the losers take the one of the winner
+	for attempt := 0; ; attempt++ {
+		secret := &corev1.Secret{}
+		err := r.Reader.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: r.SecretName}, secret)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		exists := err == nil
+		if exists && !r.needsRotation(secret.Data) {
+			return secret, nil
+		}
+		data, err := r.generate(secret.Data[corev1.ServiceAccountRootCAKey])
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			secret.Data = data
+			err = r.Client.Update(ctx, secret)
+		} else {
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: r.Namespace, Name: r.SecretName},
+				Type:       corev1.SecretTypeTLS,
+				Data:       data,
+			}
+			err = r.Client.Create(ctx, secret)
+		}
+		if err == nil {
+			log.Info("generated the webhook certificate", "secret", r.Namespace+"/"+r.SecretName, "validity", r.Validity)
+			return secret, nil
+		}
+		if attempt > 0 || !(apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)) {
+			return nil, err
+		}
+	}
+}
+
+// needsRotation returns whether the certificate of the Secret is missing, doesn't match the Service or two thirds of
+// its validity elapsed.
+func (r *CertRotator) needsRotation(data map[string][]byte) bool {
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey, corev1.ServiceAccountRootCAKey, caKeyKey} {
+		if len(data[key]) == 0 {
+			return true
+		}
+	}
+	cert, err := parseCert(data[corev1.TLSCertKey])
+	if err != nil || cert.VerifyHostname(r.dnsNames()[2]) != nil {
+		return true
+	}
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	return r.now().After(cert.NotBefore.Add(lifetime * 2 / 3))
+}
+
+func (r *CertRotator) dnsNames() []string {
+	return []string{
+		r.ServiceName,
+		r.ServiceName + "." + r.Namespace,
+		r.ServiceName + "." + r.Namespace + ".svc",
+		r.ServiceName + "." + r.Namespace + ".svc.cluster.local",
+	}
+}
+
+// generate returns the data of the Secret with a new CA and serving certificate, the bundle also has the first CA of
+// previousBundle while it is valid.
+func (r *CertRotator) generate(previousBundle []byte) (map[string][]byte, error) {
+	now := r.now()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(now.UnixNano()),
+		Subject:               pkix.Name{CommonName: r.ServiceName + "-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(r.Validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano() + 1),
+		Subject:      pkix.Name{CommonName: r.dnsNames()[2]},
+		DNSNames:     r.dnsNames(),
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(r.Validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	caKeyPEM, err := encodeKey(caKey)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	if previous, err := parseCert(previousBundle); err == nil && now.Before(previous.NotAfter) {
+		caBundle = append(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: previous.Raw})...)
+	}
+	return map[string][]byte{
+		corev1.TLSCertKey:              pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		corev1.TLSPrivateKeyKey:        keyPEM,
+		corev1.ServiceAccountRootCAKey: caBundle,
+		caKeyKey:                       caKeyPEM,
+	}, nil
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// parseCert parses the first certificate of the PEM data.
+func parseCert(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// matchesService returns whether a webhook calls the Service name in namespace.
+func matchesService(service *admissionregistrationv1.ServiceReference, namespace, name string) bool {
+	return service != nil && service.Namespace == namespace && service.Name == name
+}
+
+// injectCABundle sets caBundle in the webhooks of the webhook configurations and the conversion webhooks of the CRDs
+// which call the webhook Service.
+func (r *CertRotator) injectCABundle(ctx context.Context, caBundle []byte) error {
+	validatingConfigs := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := r.Reader.List(ctx, validatingConfigs); err != nil {
+		return err
+	}
+	for i := range validatingConfigs.Items {
+		webhookConfig := &validatingConfigs.Items[i]
+		changed := false
+		for j := range webhookConfig.Webhooks {
+			clientConfig := &webhookConfig.Webhooks[j].ClientConfig
+			if matchesService(clientConfig.Service, r.Namespace, r.ServiceName) && !bytes.Equal(clientConfig.CABundle, caBundle) {
+				clientConfig.CABundle, changed = caBundle, true
+			}
+		}
+		if changed {
+			if err := r.Client.Update(ctx, webhookConfig); err != nil {
+				return err
+			}
+			log.Info("injected the webhook CA bundle", "validatingWebhookConfiguration", webhookConfig.Name)
+		}
+	}
+	mutatingConfigs := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+	if err := r.Reader.List(ctx, mutatingConfigs); err != nil {
+		return err
+	}
+	for i := range mutatingConfigs.Items {
+		webhookConfig := &mutatingConfigs.Items[i]
+		changed := false
+		for j := range webhookConfig.Webhooks {
+			clientConfig := &webhookConfig.Webhooks[j].ClientConfig
+			if matchesService(clientConfig.Service, r.Namespace, r.ServiceName) && !bytes.Equal(clientConfig.CABundle, caBundle) {
+				clientConfig.CABundle, changed = caBundle, true
+			}
+		}
+		if changed {
+			if err := r.Client.Update(ctx, webhookConfig); err != nil {
+				return err
+			}
+			log.Info("injected the webhook CA bundle", "mutatingWebhookConfiguration", webhookConfig.Name)
+		}
+	}
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := r.Reader.List(ctx, crds); err != nil {
+		return err
+	}
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		conversion := crd.Spec.Conversion
+		if conversion == nil || conversion.Strategy != apiextensionsv1.WebhookConverter || conversion.Webhook == nil ||
+			conversion.Webhook.ClientConfig == nil {
+			continue
+		}
+		clientConfig := conversion.Webhook.ClientConfig
+		service := clientConfig.Service
+		if service == nil || service.Namespace != r.Namespace || service.Name != r.ServiceName || bytes.Equal(clientConfig.CABundle, caBundle) {
+			continue
+		}
+		clientConfig.CABundle = caBundle
+		if err := r.Client.Update(ctx, crd); err != nil {
+			return err
+		}
+		log.Info("injected the webhook CA bundle", "crd", crd.Name)
+	}
+	return nil
+}
+
+// writeCert writes the certificate for the webhook server if it changed, the server watches the files and reloads
+// them.
+func (r *CertRotator) writeCert(cert, key []byte) error {
+	if current, err := os.ReadFile(r.certFile); err == nil && bytes.Equal(current, cert) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(r.certFile), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.keyFile, key, 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(r.certFile, cert, 0600)
+}
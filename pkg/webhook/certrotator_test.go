@@ -0,0 +1,99 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestCertRotator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme))
+	assert.Nil(t, apiextensionsv1.AddToScheme(scheme))
+	service := &admissionregistrationv1.ServiceReference{Namespace: "vmware-system-nsx", Name: "nsx-operator-webhook"}
+	otherService := &admissionregistrationv1.ServiceReference{Namespace: "vmware-system-nsx", Name: "other"}
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "nsx-operator"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "cidr.nsx.vmware.com", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: service}},
+			{Name: "other.nsx.vmware.com", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: otherService}},
+		},
+	}
+	mutating := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "nsx-operator"},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{Name: "defaulting.nsx.vmware.com", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: service}},
+		},
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnets.nsx.vmware.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{Conversion: &apiextensionsv1.CustomResourceConversion{
+			Strategy: apiextensionsv1.WebhookConverter,
+			Webhook: &apiextensionsv1.WebhookConversion{ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{Namespace: service.Namespace, Name: service.Name},
+			}},
+		}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(validating, mutating, crd).Build()
+	cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{
+		WebhookCertSecret: "vmware-system-nsx/nsx-operator-webhook-cert",
+		WebhookService:    "nsx-operator-webhook",
+	}}
+	server := &crwebhook.Server{CertDir: t.TempDir()}
+	r := NewCertRotator(cf, server, c, c)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	// the certificate is generated, injected and served
+	assert.Nil(t, r.Ensure(context.TODO()))
+	assert.Nil(t, checkCert(server))
+	secret := &corev1.Secret{}
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "vmware-system-nsx", Name: "nsx-operator-webhook-cert"}, secret))
+	caBundle := secret.Data[corev1.ServiceAccountRootCAKey]
+	assert.Equal(t, 1, bytes.Count(caBundle, []byte("BEGIN CERTIFICATE")))
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Name: "nsx-operator"}, validating))
+	assert.Equal(t, caBundle, validating.Webhooks[0].ClientConfig.CABundle)
+	assert.Empty(t, validating.Webhooks[1].ClientConfig.CABundle)
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Name: "nsx-operator"}, mutating))
+	assert.Equal(t, caBundle, mutating.Webhooks[0].ClientConfig.CABundle)
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Name: "subnets.nsx.vmware.com"}, crd))
+	assert.Equal(t, caBundle, crd.Spec.Conversion.Webhook.ClientConfig.CABundle)
+	cert, err := parseCert(secret.Data[corev1.TLSCertKey])
+	assert.Nil(t, err)
+	assert.Nil(t, cert.VerifyHostname("nsx-operator-webhook.vmware-system-nsx.svc"))
+
+	// the certificate is kept until two thirds of its validity elapsed
+	assert.Nil(t, r.Ensure(context.TODO()))
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "vmware-system-nsx", Name: "nsx-operator-webhook-cert"}, secret))
+	assert.Equal(t, caBundle, secret.Data[corev1.ServiceAccountRootCAKey])
+
+	// the rotated bundle keeps the previous CA
+	now = now.Add(r.Validity * 3 / 4)
+	assert.Nil(t, r.Ensure(context.TODO()))
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "vmware-system-nsx", Name: "nsx-operator-webhook-cert"}, secret))
+	rotatedBundle := secret.Data[corev1.ServiceAccountRootCAKey]
+	assert.Equal(t, 2, bytes.Count(rotatedBundle, []byte("BEGIN CERTIFICATE")))
+	assert.True(t, bytes.HasSuffix(rotatedBundle, caBundle))
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Name: "nsx-operator"}, validating))
+	assert.Equal(t, rotatedBundle, validating.Webhooks[0].ClientConfig.CABundle)
+	rotated, err := parseCert(secret.Data[corev1.TLSCertKey])
+	assert.Nil(t, err)
+	assert.NotEqual(t, cert.SerialNumber, rotated.SerialNumber)
+}
@@ -0,0 +1,159 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package cidr
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// interval is the range of the addresses of a CIDR from start to end inclusive.
+type interval struct {
+	start netip.Addr
+	end   netip.Addr
+	cidr  string
+	owner string
+}
+
+// Overlap is a CIDR of an owner overlapping with a requested CIDR.
+type Overlap struct {
+	CIDR      string
+	Owner     string
+	Requested string
+}
+
+func (o Overlap) String() string {
+	return fmt.Sprintf("%s overlaps with %s of %s", o.Requested, o.CIDR, o.Owner)
+}
+
+// Index is an in-memory interval index of the CIDRs keyed by their owners. The intervals are sorted by their start
+// addresses, the IPv4 ones before the IPv6 ones, and maxEnd[i] is the max end address of intervals[0..i], so a lookup
+// only visits the intervals starting before the end of the requested CIDR and ending after its start.
+type Index struct {
+	lock      sync.RWMutex
+	intervals []interval
+	maxEnd    []netip.Addr
+	owners    map[string][]string
+}
+
+func NewIndex() *Index {
+	return &Index{owners: make(map[string][]string)}
+}
+
+// ParseCIDR returns the address range of the CIDR.
+func ParseCIDR(cidr string) (netip.Addr, netip.Addr, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, err
+	}
+	prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()).Masked()
+	end := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(end)*8; i++ {
+		end[i/8] |= 1 << (7 - i%8)
+	}
+	last, _ := netip.AddrFromSlice(end)
+	return prefix.Addr(), last, nil
+}
+
+// Set replaces the CIDRs of the owner, the invalid CIDRs are ignored.
+func (idx *Index) Set(owner string, cidrs []string) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	idx.remove(owner)
+	var added []string
+	for _, cidr := range cidrs {
+		start, end, err := ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		i := sort.Search(len(idx.intervals), func(i int) bool { return idx.intervals[i].start.Compare(start) > 0 })
+		idx.intervals = append(idx.intervals, interval{})
+		copy(idx.intervals[i+1:], idx.intervals[i:])
+		idx.intervals[i] = interval{start: start, end: end, cidr: cidr, owner: owner}
+		added = append(added, cidr)
+	}
+	if len(added) > 0 {
+		idx.owners[owner] = added
+	}
+	idx.updateMaxEnd()
+}
+
+// Delete removes the CIDRs of the owner.
+func (idx *Index) Delete(owner string) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	idx.remove(owner)
+	idx.updateMaxEnd()
+}
+
+// Get returns the CIDRs of the owner.
+func (idx *Index) Get(owner string) []string {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+	return idx.owners[owner]
+}
+
+// ListByOwnerPrefix returns the CIDRs of the owners whose keys start with the prefix.
+func (idx *Index) ListByOwnerPrefix(prefix string) []string {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+	var cidrs []string
+	for owner, ownerCIDRs := range idx.owners {
+		if strings.HasPrefix(owner, prefix) {
+			cidrs = append(cidrs, ownerCIDRs...)
+		}
+	}
+	return cidrs
+}
+
+// Overlaps returns the CIDRs of the other owners overlapping with the CIDRs requested by the owner, the requested CIDRs
+// must be valid.
+func (idx *Index) Overlaps(owner string, cidrs []string) ([]Overlap, error) {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+	var overlaps []Overlap
+	for _, cidr := range cidrs {
+		start, end, err := ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		i := sort.Search(len(idx.intervals), func(i int) bool { return idx.intervals[i].start.Compare(end) > 0 })
+		for i--; i >= 0 && idx.maxEnd[i].Compare(start) >= 0; i-- {
+			existing := idx.intervals[i]
+			// the IPv4 and IPv6 intervals are compared only with the intervals of the same family
+			if existing.owner == owner || existing.start.Is4() != start.Is4() || existing.end.Compare(start) < 0 {
+				continue
+			}
+			overlaps = append(overlaps, Overlap{CIDR: existing.cidr, Owner: existing.owner, Requested: cidr})
+		}
+	}
+	return overlaps, nil
+}
+
+func (idx *Index) remove(owner string) {
+	if _, ok := idx.owners[owner]; !ok {
+		return
+	}
+	delete(idx.owners, owner)
+	intervals := idx.intervals[:0]
+	for _, existing := range idx.intervals {
+		if existing.owner != owner {
+			intervals = append(intervals, existing)
+		}
+	}
+	idx.intervals = intervals
+}
+
+func (idx *Index) updateMaxEnd() {
+	idx.maxEnd = make([]netip.Addr, len(idx.intervals))
+	for i, existing := range idx.intervals {
+		idx.maxEnd[i] = existing.end
+		if i > 0 && idx.maxEnd[i-1].Compare(existing.end) > 0 {
+			idx.maxEnd[i] = idx.maxEnd[i-1]
+		}
+	}
+}
@@ -0,0 +1,67 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package cidr
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCIDR(t *testing.T) {
+	start, end, err := ParseCIDR("10.0.1.5/24")
+	assert.Nil(t, err)
+	assert.Equal(t, netip.MustParseAddr("10.0.1.0"), start)
+	assert.Equal(t, netip.MustParseAddr("10.0.1.255"), end)
+
+	start, end, err = ParseCIDR("fd00:1::/112")
+	assert.Nil(t, err)
+	assert.Equal(t, netip.MustParseAddr("fd00:1::"), start)
+	assert.Equal(t, netip.MustParseAddr("fd00:1::ffff"), end)
+
+	_, _, err = ParseCIDR("10.0.0.0")
+	assert.NotNil(t, err)
+}
+
+func TestIndex(t *testing.T) {
+	index := NewIndex()
+	index.Set("transport", []string{"192.168.0.0/16"})
+	index.Set("Subnet/ns1/subnet1", []string{"10.0.0.0/8", "invalid"})
+	index.Set("Subnet/ns1/subnet2", []string{"172.16.0.0/24", "fd00::/64"})
+	index.Set("StaticRoute/ns1/route1", []string{"172.16.1.0/24"})
+	assert.Equal(t, []string{"10.0.0.0/8"}, index.Get("Subnet/ns1/subnet1"))
+	assert.ElementsMatch(t, []string{"10.0.0.0/8", "172.16.0.0/24", "fd00::/64"}, index.ListByOwnerPrefix("Subnet/ns1/"))
+	assert.Nil(t, index.ListByOwnerPrefix("Subnet/ns2/"))
+
+	overlaps, err := index.Overlaps("Subnet/ns2/subnet3", []string{"10.20.0.0/16", "172.16.0.0/23", "fd00::1:0/112", "11.0.0.0/8"})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []Overlap{
+		{CIDR: "10.0.0.0/8", Owner: "Subnet/ns1/subnet1", Requested: "10.20.0.0/16"},
+		{CIDR: "172.16.0.0/24", Owner: "Subnet/ns1/subnet2", Requested: "172.16.0.0/23"},
+		{CIDR: "172.16.1.0/24", Owner: "StaticRoute/ns1/route1", Requested: "172.16.0.0/23"},
+		{CIDR: "fd00::/64", Owner: "Subnet/ns1/subnet2", Requested: "fd00::1:0/112"},
+	}, overlaps)
+
+	// a big interval before the requested CIDR still overlaps with it
+	overlaps, err = index.Overlaps("Subnet/ns2/subnet3", []string{"10.255.255.0/24"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Overlap{{CIDR: "10.0.0.0/8", Owner: "Subnet/ns1/subnet1", Requested: "10.255.255.0/24"}}, overlaps)
+
+	// the CIDRs of the owner itself are excluded
+	overlaps, err = index.Overlaps("Subnet/ns1/subnet1", []string{"10.0.0.0/16"})
+	assert.Nil(t, err)
+	assert.Nil(t, overlaps)
+
+	_, err = index.Overlaps("Subnet/ns2/subnet3", []string{"10.0.0.0/33"})
+	assert.NotNil(t, err)
+
+	index.Set("Subnet/ns1/subnet1", []string{"10.1.0.0/16"})
+	index.Delete("StaticRoute/ns1/route1")
+	overlaps, err = index.Overlaps("Subnet/ns2/subnet3", []string{"10.20.0.0/16", "172.16.1.0/24"})
+	assert.Nil(t, err)
+	assert.Nil(t, overlaps)
+	assert.Equal(t, "10.1.0.0/16 overlaps with 10.1.0.0/16 of Subnet/ns1/subnet1",
+		Overlap{CIDR: "10.1.0.0/16", Owner: "Subnet/ns1/subnet1", Requested: "10.1.0.0/16"}.String())
+}
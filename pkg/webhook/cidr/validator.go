@@ -0,0 +1,252 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package cidr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/webhook"
+)
+
+const (
+	// WebhookPath is the path the CIDR validating webhook is served at, the ValidatingWebhookConfiguration routes the
+	// CREATE and UPDATE requests of the Subnets, IPPools and StaticRoutes to it.
+	WebhookPath = "/validate-nsx-vmware-com-v1alpha1-cidr"
+
+	// TransportOwner and ExternalOwner own the configured transport and external CIDRs in the index.
+	TransportOwner = "config/transport_cidrs"
+	ExternalOwner  = "config/external_cidrs"
+)
+
+var log = logger.Log
+
+// Validator rejects the Subnets, IPPools and StaticRoutes whose CIDRs overlap with the CIDRs of the other ones or with
+// the configured transport and external CIDRs, and the StaticRoutes whose next hops are not in the connected Subnets.
+// The index is kept up to date from the informers of the CRs, so two CRs with overlapping CIDRs created at the same
+// time may both be admitted.
+type Validator struct {
+	Index *Index
+	// kinds are the kinds of the CRs validated, the CRs of the disabled controllers are neither watched nor validated
+	kinds   map[string]bool
+	decoder *admission.Decoder
+}
+
+// kindControllers are the controllers of the kinds validated.
+var kindControllers = map[string]string{
+	"Subnet":      config.ControllerSubnet,
+	"IPPool":      config.ControllerIPPool,
+	"StaticRoute": config.ControllerStaticRoute,
+}
+
+func NewValidator(cf *config.NSXOperatorConfig, scheme *runtime.Scheme) (*Validator, error) {
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		return nil, err
+	}
+	index := NewIndex()
+	index.Set(TransportOwner, cf.TransportCIDRs)
+	index.Set(ExternalOwner, cf.ExternalCIDRs)
+	kinds := map[string]bool{}
+	for kind, controller := range kindControllers {
+		if cf.ControllerEnabled(controller) {
+			kinds[kind] = true
+		}
+	}
+	return &Validator{Index: index, kinds: kinds, decoder: decoder}, nil
+}
+
+// OwnerKey returns the key of the CR in the index.
+func OwnerKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// newObject returns an empty CR of the kind, nil if the CIDRs of the kind are not validated.
+func (v *Validator) newObject(kind string) client.Object {
+	if !v.kinds[kind] {
+		return nil
+	}
+	switch kind {
+	case "Subnet":
+		return &v1alpha1.Subnet{}
+	case "IPPool":
+		return &v1alpha1.IPPool{}
+	case "StaticRoute":
+		return &v1alpha1.StaticRoute{}
+	}
+	return nil
+}
+
+// CIDRsOf returns the kind and the CIDRs of the CR, the requested CIDRs of the Subnets and StaticRoutes and the CIDRs
+// allocated to the Subnets and IPPools.
+func CIDRsOf(obj client.Object) (string, []string) {
+	var cidrs []string
+	switch o := obj.(type) {
+	case *v1alpha1.Subnet:
+		cidrs = append(cidrs, o.Spec.IPAddresses...)
+		for _, cidr := range o.Status.IPAddresses {
+			if !contains(cidrs, cidr) {
+				cidrs = append(cidrs, cidr)
+			}
+		}
+		return "Subnet", cidrs
+	case *v1alpha1.IPPool:
+		for _, subnet := range o.Status.Subnets {
+			if subnet.CIDR != "" {
+				cidrs = append(cidrs, subnet.CIDR)
+			}
+		}
+		return "IPPool", cidrs
+	case *v1alpha1.StaticRoute:
+		if o.Spec.Network != "" {
+			cidrs = append(cidrs, o.Spec.Network)
+		}
+		return "StaticRoute", cidrs
+	}
+	return "", nil
+}
+
+func contains(cidrs []string, cidr string) bool {
+	for _, c := range cidrs {
+		if c == cidr {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle rejects the CR if its CIDRs are invalid or overlap with the CIDRs in the index.
+func (v *Validator) Handle(_ context.Context, req admission.Request) admission.Response {
+	obj := v.newObject(req.Kind.Kind)
+	if obj == nil {
+		return admission.Allowed("")
+	}
+	if err := v.decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if route, ok := obj.(*v1alpha1.StaticRoute); ok {
+		if msg := v.validateNextHops(route); msg != "" {
+			log.Info("rejected StaticRoute with invalid next hops", "namespace", route.Namespace, "name", route.Name, "reason", msg)
+			return admission.Denied(msg)
+		}
+	}
+	kind, cidrs := CIDRsOf(obj)
+	overlaps, err := v.Index.Overlaps(OwnerKey(kind, obj.GetNamespace(), obj.GetName()), cidrs)
+	if err != nil {
+		return admission.Denied(fmt.Sprintf("invalid CIDR: %v", err))
+	}
+	if len(overlaps) > 0 {
+		var msgs []string
+		for _, overlap := range overlaps {
+			msgs = append(msgs, overlap.String())
+		}
+		log.Info("rejected CIDRs overlapping with existing CIDRs", "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName(), "overlaps", msgs)
+		return admission.Denied("CIDR " + strings.Join(msgs, ", "))
+	}
+	return admission.Allowed("")
+}
+
+// validateNextHops returns why the next hops of the StaticRoute are rejected, or "" if they are valid. The next hops must
+// be unique and within the CIDRs of the Subnets of the Namespace if it has any, the Namespaces without Subnets and the
+// routes of the Tier1 and Tier0 scopes route through a gateway whose connected segments are not known here.
+func (v *Validator) validateNextHops(route *v1alpha1.StaticRoute) string {
+	var connected []netip.Prefix
+	if route.Spec.Scope != v1alpha1.StaticRouteScopeTier1 && route.Spec.Scope != v1alpha1.StaticRouteScopeTier0 {
+		for _, cidr := range v.Index.ListByOwnerPrefix(OwnerKey("Subnet", route.Namespace, "")) {
+			if prefix, err := netip.ParsePrefix(cidr); err == nil {
+				connected = append(connected, prefix.Masked())
+			}
+		}
+	}
+	seen := map[netip.Addr]bool{}
+	for _, nextHop := range route.Spec.NextHops {
+		addr, err := netip.ParseAddr(nextHop.IPAddress)
+		if err != nil {
+			return fmt.Sprintf("invalid next hop %s", nextHop.IPAddress)
+		}
+		addr = addr.Unmap()
+		if seen[addr] {
+			return fmt.Sprintf("duplicate next hop %s", nextHop.IPAddress)
+		}
+		seen[addr] = true
+		if nextHop.AdminDistance < 0 || nextHop.AdminDistance > 255 {
+			return fmt.Sprintf("admin distance %d of next hop %s is out of range 1-255", nextHop.AdminDistance, nextHop.IPAddress)
+		}
+		if len(connected) > 0 && !containsAddr(connected, addr) {
+			return fmt.Sprintf("next hop %s is not within the Subnets of Namespace %s", nextHop.IPAddress, route.Namespace)
+		}
+	}
+	return ""
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Update indexes the CIDRs of the CR.
+func (v *Validator) Update(obj interface{}) {
+	o, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+	kind, cidrs := CIDRsOf(o)
+	if kind == "" {
+		return
+	}
+	v.Index.Set(OwnerKey(kind, o.GetNamespace(), o.GetName()), cidrs)
+}
+
+// Delete removes the CIDRs of the CR from the index.
+func (v *Validator) Delete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	o, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+	if kind, _ := CIDRsOf(o); kind != "" {
+		v.Index.Delete(OwnerKey(kind, o.GetNamespace(), o.GetName()))
+	}
+}
+
+// SetupWithManager indexes the CIDRs of the existing and future CRs from the informers of the manager cache, and serves
+// the webhook with the webhook server of the manager.
+func (v *Validator) SetupWithManager(mgr ctrl.Manager) error {
+	for _, obj := range []client.Object{&v1alpha1.Subnet{}, &v1alpha1.IPPool{}, &v1alpha1.StaticRoute{}} {
+		if kind, _ := CIDRsOf(obj); !v.kinds[kind] {
+			continue
+		}
+		informer, err := mgr.GetCache().GetInformer(context.TODO(), obj)
+		if err != nil {
+			return err
+		}
+		if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    v.Update,
+			UpdateFunc: func(_, newObj interface{}) { v.Update(newObj) },
+			DeleteFunc: v.Delete,
+		}); err != nil {
+			return err
+		}
+	}
+	webhook.Register(mgr, WebhookPath, v)
+	return nil
+}
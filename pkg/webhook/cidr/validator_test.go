@@ -0,0 +1,205 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package cidr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func newRequest(t *testing.T, kind string, obj client.Object) admission.Request {
+	raw, err := json.Marshal(obj)
+	assert.Nil(t, err)
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: v1alpha1.GroupVersion.Group, Version: v1alpha1.GroupVersion.Version, Kind: kind},
+		Operation: admissionv1.Create,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+}
+
+func TestValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{
+		TransportCIDRs: []string{"192.168.0.0/16"},
+		ExternalCIDRs:  []string{"100.64.0.0/10"},
+	}}
+	v, err := NewValidator(cf, scheme)
+	assert.Nil(t, err)
+
+	subnet1 := &v1alpha1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1"},
+		Spec:       v1alpha1.SubnetSpec{IPAddresses: []string{"10.0.0.0/24"}},
+		Status:     v1alpha1.SubnetStatus{IPAddresses: []string{"10.0.0.0/24"}},
+	}
+	v.Update(subnet1)
+	pool := &v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1"},
+		Status:     v1alpha1.IPPoolStatus{Subnets: []v1alpha1.SubnetResult{{Name: "s1", CIDR: "10.1.0.0/24"}, {Name: "s2"}}},
+	}
+	v.Update(pool)
+	assert.Equal(t, []string{"10.0.0.0/24"}, v.Index.Get(OwnerKey("Subnet", "ns1", "subnet1")))
+	assert.Equal(t, []string{"10.1.0.0/24"}, v.Index.Get(OwnerKey("IPPool", "ns1", "pool1")))
+
+	tests := []struct {
+		name    string
+		kind    string
+		obj     client.Object
+		allowed bool
+	}{
+		{
+			name:    "subnet without overlap",
+			kind:    "Subnet",
+			obj:     &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet2", Namespace: "ns1"}, Spec: v1alpha1.SubnetSpec{IPAddresses: []string{"10.0.1.0/24"}}},
+			allowed: true,
+		},
+		{
+			name: "subnet overlapping with a Subnet",
+			kind: "Subnet",
+			obj:  &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet2", Namespace: "ns2"}, Spec: v1alpha1.SubnetSpec{IPAddresses: []string{"10.0.0.128/25"}}},
+		},
+		{
+			name:    "update of the subnet itself",
+			kind:    "Subnet",
+			obj:     subnet1,
+			allowed: true,
+		},
+		{
+			name: "static route overlapping with an IPPool",
+			kind: "StaticRoute",
+			obj:  &v1alpha1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "ns1"}, Spec: v1alpha1.StaticRouteSpec{Network: "10.1.0.0/16"}},
+		},
+		{
+			name: "static route overlapping with the transport CIDRs",
+			kind: "StaticRoute",
+			obj:  &v1alpha1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "ns1"}, Spec: v1alpha1.StaticRouteSpec{Network: "192.168.10.0/24"}},
+		},
+		{
+			name: "subnet overlapping with the external CIDRs",
+			kind: "Subnet",
+			obj:  &v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet3", Namespace: "ns1"}, Spec: v1alpha1.SubnetSpec{IPAddresses: []string{"100.100.0.0/24"}}},
+		},
+		{
+			name: "invalid CIDR",
+			kind: "StaticRoute",
+			obj:  &v1alpha1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "route2", Namespace: "ns1"}, Spec: v1alpha1.StaticRouteSpec{Network: "10.2.0.0"}},
+		},
+		{
+			name: "static route with ECMP next hops",
+			kind: "StaticRoute",
+			obj: &v1alpha1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "route3", Namespace: "ns1"}, Spec: v1alpha1.StaticRouteSpec{
+				Network:  "10.3.0.0/16",
+				NextHops: []v1alpha1.NextHop{{IPAddress: "10.0.0.1"}, {IPAddress: "10.0.0.2", AdminDistance: 2}},
+			}},
+			allowed: true,
+		},
+		{
+			name: "static route with duplicate next hops",
+			kind: "StaticRoute",
+			obj: &v1alpha1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "route3", Namespace: "ns1"}, Spec: v1alpha1.StaticRouteSpec{
+				Network:  "10.3.0.0/16",
+				NextHops: []v1alpha1.NextHop{{IPAddress: "10.0.0.1"}, {IPAddress: "10.0.0.1", AdminDistance: 2}},
+			}},
+		},
+		{
+			name: "static route with admin distance out of range",
+			kind: "StaticRoute",
+			obj: &v1alpha1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "route3", Namespace: "ns1"}, Spec: v1alpha1.StaticRouteSpec{
+				Network:  "10.3.0.0/16",
+				NextHops: []v1alpha1.NextHop{{IPAddress: "10.0.0.1", AdminDistance: 256}},
+			}},
+		},
+		{
+			name: "static route with next hop out of the Subnets",
+			kind: "StaticRoute",
+			obj: &v1alpha1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "route3", Namespace: "ns1"}, Spec: v1alpha1.StaticRouteSpec{
+				Network:  "10.3.0.0/16",
+				NextHops: []v1alpha1.NextHop{{IPAddress: "10.0.0.1"}, {IPAddress: "10.9.0.1"}},
+			}},
+		},
+		{
+			name: "tier0 static route with next hop out of the Subnets",
+			kind: "StaticRoute",
+			obj: &v1alpha1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "route3", Namespace: "ns1"}, Spec: v1alpha1.StaticRouteSpec{
+				Scope:    v1alpha1.StaticRouteScopeTier0,
+				Network:  "10.3.0.0/16",
+				NextHops: []v1alpha1.NextHop{{IPAddress: "10.9.0.1"}},
+			}},
+			allowed: true,
+		},
+		{
+			name: "static route in Namespace without Subnets",
+			kind: "StaticRoute",
+			obj: &v1alpha1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "route3", Namespace: "ns3"}, Spec: v1alpha1.StaticRouteSpec{
+				Network:  "10.3.0.0/16",
+				NextHops: []v1alpha1.NextHop{{IPAddress: "10.9.0.1"}},
+			}},
+			allowed: true,
+		},
+		{
+			name:    "ippool without allocated CIDRs",
+			kind:    "IPPool",
+			obj:     &v1alpha1.IPPool{ObjectMeta: metav1.ObjectMeta{Name: "pool2", Namespace: "ns1"}},
+			allowed: true,
+		},
+		{
+			name:    "other kind",
+			kind:    "SubnetSet",
+			obj:     &v1alpha1.SubnetSet{ObjectMeta: metav1.ObjectMeta{Name: "subnetset1", Namespace: "ns1"}},
+			allowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := v.Handle(context.TODO(), newRequest(t, tt.kind, tt.obj))
+			assert.Equal(t, tt.allowed, resp.Allowed, resp.Result)
+		})
+	}
+
+	v.Delete(toolscache.DeletedFinalStateUnknown{Obj: pool})
+	v.Delete(subnet1)
+	assert.Nil(t, v.Index.Get(OwnerKey("IPPool", "ns1", "pool1")))
+	assert.Nil(t, v.Index.Get(OwnerKey("Subnet", "ns1", "subnet1")))
+	resp := v.Handle(context.TODO(), newRequest(t, "Subnet", &v1alpha1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet2", Namespace: "ns2"}, Spec: v1alpha1.SubnetSpec{IPAddresses: []string{"10.0.0.128/25"}},
+	}))
+	assert.True(t, resp.Allowed)
+}
+
+func TestValidator_DisabledControllers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{
+		TransportCIDRs:      []string{"192.168.0.0/16"},
+		DisabledControllers: []string{config.ControllerStaticRoute},
+	}}
+	v, err := NewValidator(cf, scheme)
+	assert.Nil(t, err)
+
+	// the StaticRoutes are not validated if the StaticRoute controller is disabled
+	route := &v1alpha1.StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: "ns1"},
+		Spec:       v1alpha1.StaticRouteSpec{Network: "192.168.1.0/24"},
+	}
+	assert.True(t, v.Handle(context.TODO(), newRequest(t, "StaticRoute", route)).Allowed)
+	subnet := &v1alpha1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1"},
+		Spec:       v1alpha1.SubnetSpec{IPAddresses: []string{"192.168.1.0/24"}},
+	}
+	assert.False(t, v.Handle(context.TODO(), newRequest(t, "Subnet", subnet)).Allowed)
+}
@@ -0,0 +1,148 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package webhook
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+// configCheckInterval is the interval to restore the failure policy and the Namespace exclusions of the webhooks.
+const configCheckInterval = 10 * time.Minute
+
+// Configurator sets the failure policy and the Namespace exclusions of the config in the webhooks of the
+// ValidatingWebhookConfigurations and the MutatingWebhookConfigurations which call the webhook Service, so that e.g.
+// the requests of kube-system or of all the Namespaces are not blocked while NSX Operator is down. The excluded
+// Namespaces replace the NotIn expression on the kubernetes.io/metadata.name label of the namespaceSelector of the
+// webhooks, the other expressions are kept. The failure policy and the namespaceSelector of the webhook
+// configurations are kept if they are not configured.
+type Configurator struct {
+	Client client.Client
+	// Reader reads the webhook configurations from the API server, they are not cached.
+	Reader             client.Reader
+	Namespace          string
+	ServiceName        string
+	FailurePolicy      *admissionregistrationv1.FailurePolicyType
+	ExcludedNamespaces []string
+}
+
+func NewConfigurator(cf *config.NSXOperatorConfig, c client.Client, reader client.Reader) *Configurator {
+	namespace, name := cf.GetWebhookService()
+	configurator := &Configurator{Client: c, Reader: reader, Namespace: namespace, ServiceName: name}
+	if cf.WebhookFailurePolicy != "" {
+		failurePolicy := admissionregistrationv1.FailurePolicyType(cf.WebhookFailurePolicy)
+		configurator.FailurePolicy = &failurePolicy
+	}
+	if len(cf.WebhookExcludedNamespaces) > 0 {
+		configurator.ExcludedNamespaces = append([]string(nil), cf.WebhookExcludedNamespaces...)
+		sort.Strings(configurator.ExcludedNamespaces)
+	}
+	return configurator
+}
+
+// Start configures the webhooks, and restores their configuration every configCheckInterval until ctx is done.
+func (c *Configurator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(configCheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := c.Ensure(ctx); err != nil {
+			log.Error(err, "failed to configure the webhooks")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns true, only the leader updates the webhook configurations.
+func (c *Configurator) NeedLeaderElection() bool {
+	return true
+}
+
+// Ensure sets the failure policy and the Namespace exclusions in the webhooks which call the webhook Service.
+func (c *Configurator) Ensure(ctx context.Context) error {
+	validatingConfigs := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := c.Reader.List(ctx, validatingConfigs); err != nil {
+		return err
+	}
+	for i := range validatingConfigs.Items {
+		webhookConfig := &validatingConfigs.Items[i]
+		changed := false
+		for j := range webhookConfig.Webhooks {
+			wh := &webhookConfig.Webhooks[j]
+			if matchesService(wh.ClientConfig.Service, c.Namespace, c.ServiceName) && c.configure(&wh.FailurePolicy, &wh.NamespaceSelector) {
+				changed = true
+			}
+		}
+		if changed {
+			if err := c.Client.Update(ctx, webhookConfig); err != nil {
+				return err
+			}
+			log.Info("configured the webhooks", "validatingWebhookConfiguration", webhookConfig.Name)
+		}
+	}
+	mutatingConfigs := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+	if err := c.Reader.List(ctx, mutatingConfigs); err != nil {
+		return err
+	}
+	for i := range mutatingConfigs.Items {
+		webhookConfig := &mutatingConfigs.Items[i]
+		changed := false
+		for j := range webhookConfig.Webhooks {
+			wh := &webhookConfig.Webhooks[j]
+			if matchesService(wh.ClientConfig.Service, c.Namespace, c.ServiceName) && c.configure(&wh.FailurePolicy, &wh.NamespaceSelector) {
+				changed = true
+			}
+		}
+		if changed {
+			if err := c.Client.Update(ctx, webhookConfig); err != nil {
+				return err
+			}
+			log.Info("configured the webhooks", "mutatingWebhookConfiguration", webhookConfig.Name)
+		}
+	}
+	return nil
+}
+
+// configure sets the failure policy and the Namespace exclusions of a webhook, and returns whether they changed.
+func (c *Configurator) configure(failurePolicy **admissionregistrationv1.FailurePolicyType, selector **metav1.LabelSelector) bool {
+	changed := false
+	if c.FailurePolicy != nil && (*failurePolicy == nil || **failurePolicy != *c.FailurePolicy) {
+		policy := *c.FailurePolicy
+		*failurePolicy, changed = &policy, true
+	}
+	if len(c.ExcludedNamespaces) == 0 {
+		return changed
+	}
+	desired := &metav1.LabelSelector{}
+	if *selector != nil {
+		desired = (*selector).DeepCopy()
+	}
+	expressions := desired.MatchExpressions[:0]
+	for _, expression := range desired.MatchExpressions {
+		if expression.Key != corev1.LabelMetadataName || expression.Operator != metav1.LabelSelectorOpNotIn {
+			expressions = append(expressions, expression)
+		}
+	}
+	desired.MatchExpressions = append(expressions, metav1.LabelSelectorRequirement{
+		Key:      corev1.LabelMetadataName,
+		Operator: metav1.LabelSelectorOpNotIn,
+		Values:   append([]string(nil), c.ExcludedNamespaces...),
+	})
+	if !equality.Semantic.DeepEqual(*selector, desired) {
+		*selector, changed = desired, true
+	}
+	return changed
+}
@@ -0,0 +1,77 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func TestConfigurator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme))
+	service := &admissionregistrationv1.ServiceReference{Namespace: "vmware-system-nsx", Name: "nsx-operator-webhook"}
+	otherService := &admissionregistrationv1.ServiceReference{Namespace: "vmware-system-nsx", Name: "other"}
+	fail := admissionregistrationv1.Fail
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "nsx-operator"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name:          "namespace.nsx.vmware.com",
+				ClientConfig:  admissionregistrationv1.WebhookClientConfig{Service: service},
+				FailurePolicy: &fail,
+				NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tenant", Operator: metav1.LabelSelectorOpExists},
+					{Key: "kubernetes.io/metadata.name", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"default"}},
+				}},
+			},
+			{Name: "other.nsx.vmware.com", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: otherService}, FailurePolicy: &fail},
+		},
+	}
+	mutating := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "nsx-operator"},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{Name: "defaulting.nsx.vmware.com", ClientConfig: admissionregistrationv1.WebhookClientConfig{Service: service}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(validating, mutating).Build()
+	cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{
+		WebhookService:            "nsx-operator-webhook",
+		WebhookFailurePolicy:      "Ignore",
+		WebhookExcludedNamespaces: []string{"vmware-system-nsx", "kube-system"},
+	}, K8sConfig: &config.K8sConfig{}}
+	configurator := NewConfigurator(cf, c, c)
+	assert.Nil(t, configurator.Ensure(context.TODO()))
+
+	excluded := metav1.LabelSelectorRequirement{
+		Key:      "kubernetes.io/metadata.name",
+		Operator: metav1.LabelSelectorOpNotIn,
+		Values:   []string{"kube-system", "vmware-system-nsx"},
+	}
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Name: "nsx-operator"}, validating))
+	assert.Equal(t, admissionregistrationv1.Ignore, *validating.Webhooks[0].FailurePolicy)
+	assert.Equal(t, []metav1.LabelSelectorRequirement{{Key: "tenant", Operator: metav1.LabelSelectorOpExists}, excluded},
+		validating.Webhooks[0].NamespaceSelector.MatchExpressions)
+	assert.Equal(t, admissionregistrationv1.Fail, *validating.Webhooks[1].FailurePolicy)
+	assert.Nil(t, validating.Webhooks[1].NamespaceSelector)
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Name: "nsx-operator"}, mutating))
+	assert.Equal(t, admissionregistrationv1.Ignore, *mutating.Webhooks[0].FailurePolicy)
+	assert.Equal(t, []metav1.LabelSelectorRequirement{excluded}, mutating.Webhooks[0].NamespaceSelector.MatchExpressions)
+
+	// the configured webhooks are not updated again
+	resourceVersion := validating.ResourceVersion
+	assert.Nil(t, configurator.Ensure(context.TODO()))
+	assert.Nil(t, c.Get(context.TODO(), client.ObjectKey{Name: "nsx-operator"}, validating))
+	assert.Equal(t, resourceVersion, validating.ResourceVersion)
+}
@@ -0,0 +1,134 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package defaulting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/webhook"
+)
+
+// WebhookPath is the path the defaulting webhook is served at, the MutatingWebhookConfiguration routes the CREATE and
+// UPDATE requests of the Subnets, SubnetSets, StaticRoutes, RouteAdvertisements, PrefixLists and RouteMaps to it.
+const WebhookPath = "/mutate-nsx-vmware-com-v1alpha1"
+
+// Defaulter fills in the fields omitted in the CRs at admission with the values NSX Operator realizes them with, so
+// the stored CRs show what is programmed on NSX. The controllers fall back to the same defaults for the CRs admitted
+// without it, see the defaults of v1alpha1.
+type Defaulter struct {
+	NSXConfig *config.NSXOperatorConfig
+	decoder   *admission.Decoder
+}
+
+func NewDefaulter(cf *config.NSXOperatorConfig, scheme *runtime.Scheme) (*Defaulter, error) {
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return &Defaulter{NSXConfig: cf, decoder: decoder}, nil
+}
+
+// Handle patches the CR of the request with the defaults of its omitted fields.
+func (d *Defaulter) Handle(_ context.Context, req admission.Request) admission.Response {
+	var obj client.Object
+	switch req.Kind.Kind {
+	case "Subnet":
+		obj = &v1alpha1.Subnet{}
+	case "SubnetSet":
+		obj = &v1alpha1.SubnetSet{}
+	case "StaticRoute":
+		obj = &v1alpha1.StaticRoute{}
+	case "RouteAdvertisement":
+		obj = &v1alpha1.RouteAdvertisement{}
+	case "PrefixList":
+		obj = &v1alpha1.PrefixList{}
+	case "RouteMap":
+		obj = &v1alpha1.RouteMap{}
+	default:
+		return admission.Allowed("")
+	}
+	if err := d.decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	d.Default(obj, req.Operation == admissionv1.Create)
+	defaulted, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, defaulted)
+}
+
+// Default fills in the omitted fields of obj. The size of the Subnets defaults to the default_subnet_prefix_length of
+// the config only when they are created, the Subnets created before it changes keep being realized with the previous
+// one.
+func (d *Defaulter) Default(obj client.Object, create bool) {
+	switch o := obj.(type) {
+	case *v1alpha1.Subnet:
+		if create && o.Spec.IPv4SubnetSize == 0 {
+			o.Spec.IPv4SubnetSize = d.NSXConfig.DefaultSubnetSize()
+		}
+		o.Spec.AccessMode = defaultAccessMode(o.Spec.AccessMode)
+		o.Spec.DHCPConfig.Mode = v1alpha1.DefaultDHCPMode(o.Spec.DHCPConfig)
+	case *v1alpha1.SubnetSet:
+		if create && o.Spec.IPv4SubnetSize == 0 {
+			o.Spec.IPv4SubnetSize = d.NSXConfig.DefaultSubnetSize()
+		}
+		o.Spec.AccessMode = defaultAccessMode(o.Spec.AccessMode)
+		o.Spec.DHCPConfig.Mode = v1alpha1.DefaultDHCPMode(o.Spec.DHCPConfig)
+	case *v1alpha1.StaticRoute:
+		if o.Spec.Scope == "" {
+			o.Spec.Scope = v1alpha1.StaticRouteScopeVPC
+		}
+		for i := range o.Spec.NextHops {
+			if o.Spec.NextHops[i].AdminDistance == 0 {
+				o.Spec.NextHops[i].AdminDistance = v1alpha1.DefaultAdminDistance
+			}
+		}
+	case *v1alpha1.RouteAdvertisement:
+		for i := range o.Spec.Rules {
+			o.Spec.Rules[i].Action = defaultAction(o.Spec.Rules[i].Action)
+			if o.Spec.Rules[i].PrefixOperator == "" {
+				o.Spec.Rules[i].PrefixOperator = v1alpha1.RouteAdvertisementPrefixGE
+			}
+		}
+	case *v1alpha1.PrefixList:
+		for i := range o.Spec.Prefixes {
+			o.Spec.Prefixes[i].Action = defaultAction(o.Spec.Prefixes[i].Action)
+		}
+	case *v1alpha1.RouteMap:
+		for i := range o.Spec.Entries {
+			o.Spec.Entries[i].Action = defaultAction(o.Spec.Entries[i].Action)
+		}
+	}
+}
+
+func defaultAccessMode(mode v1alpha1.AccessMode) v1alpha1.AccessMode {
+	if mode == "" {
+		return v1alpha1.AccessMode(v1alpha1.AccessModePrivate)
+	}
+	return mode
+}
+
+func defaultAction(action v1alpha1.RouteAdvertisementAction) v1alpha1.RouteAdvertisementAction {
+	if action == "" {
+		return v1alpha1.RouteAdvertisementPermit
+	}
+	return action
+}
+
+// SetupWithManager serves the webhook with the webhook server of the manager.
+func (d *Defaulter) SetupWithManager(mgr ctrl.Manager) error {
+	webhook.Register(mgr, WebhookPath, d)
+	return nil
+}
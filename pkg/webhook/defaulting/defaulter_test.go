@@ -0,0 +1,94 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package defaulting
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+func newRequest(t *testing.T, kind string, operation admissionv1.Operation, obj client.Object) admission.Request {
+	raw, err := json.Marshal(obj)
+	assert.Nil(t, err)
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: v1alpha1.GroupVersion.Group, Version: v1alpha1.GroupVersion.Version, Kind: kind},
+		Operation: operation,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+}
+
+func patchPaths(resp admission.Response) []string {
+	var paths []string
+	for _, patch := range resp.Patches {
+		paths = append(paths, patch.Path)
+	}
+	return paths
+}
+
+func TestDefaulter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{}}
+	d, err := NewDefaulter(cf, scheme)
+	assert.Nil(t, err)
+	meta := metav1.ObjectMeta{Name: "obj1", Namespace: "ns1"}
+
+	// the omitted fields of a created Subnet are filled in
+	subnet := &v1alpha1.Subnet{ObjectMeta: meta, Spec: v1alpha1.SubnetSpec{DHCPConfig: v1alpha1.DHCPConfig{EnableDHCP: true}}}
+	resp := d.Handle(context.TODO(), newRequest(t, "Subnet", admissionv1.Create, subnet))
+	assert.True(t, resp.Allowed)
+	assert.ElementsMatch(t, []string{"/spec/ipv4SubnetSize", "/spec/accessMode", "/spec/DHCPConfig/mode"}, patchPaths(resp))
+
+	// the size of an updated Subnet is not defaulted, the set fields are kept
+	subnet.Spec.AccessMode = v1alpha1.AccessMode(v1alpha1.AccessModePublic)
+	subnet.Spec.DHCPConfig.Mode = v1alpha1.DHCPModeStatic
+	resp = d.Handle(context.TODO(), newRequest(t, "Subnet", admissionv1.Update, subnet))
+	assert.True(t, resp.Allowed)
+	assert.Empty(t, resp.Patches)
+
+	// the kinds which are not defaulted are allowed as is
+	resp = d.Handle(context.TODO(), newRequest(t, "IPPool", admissionv1.Create, &v1alpha1.IPPool{ObjectMeta: meta}))
+	assert.True(t, resp.Allowed)
+	assert.Empty(t, resp.Patches)
+
+	subnetSet := &v1alpha1.SubnetSet{ObjectMeta: meta}
+	d.Default(subnetSet, true)
+	assert.Equal(t, cf.DefaultSubnetSize(), subnetSet.Spec.IPv4SubnetSize)
+	assert.Equal(t, v1alpha1.AccessMode(v1alpha1.AccessModePrivate), subnetSet.Spec.AccessMode)
+	assert.Equal(t, v1alpha1.DHCPModeStatic, subnetSet.Spec.DHCPConfig.Mode)
+
+	route := &v1alpha1.StaticRoute{ObjectMeta: meta, Spec: v1alpha1.StaticRouteSpec{
+		Network:  "172.16.0.0/16",
+		NextHops: []v1alpha1.NextHop{{IPAddress: "10.0.0.1"}, {IPAddress: "10.0.0.2", AdminDistance: 5}},
+	}}
+	d.Default(route, true)
+	assert.Equal(t, v1alpha1.StaticRouteScopeVPC, route.Spec.Scope)
+	assert.Equal(t, v1alpha1.DefaultAdminDistance, route.Spec.NextHops[0].AdminDistance)
+	assert.Equal(t, 5, route.Spec.NextHops[1].AdminDistance)
+
+	advertisement := &v1alpha1.RouteAdvertisement{ObjectMeta: meta, Spec: v1alpha1.RouteAdvertisementSpec{
+		Rules: []v1alpha1.RouteAdvertisementRule{{}, {Action: v1alpha1.RouteAdvertisementDeny}},
+	}}
+	d.Default(advertisement, true)
+	assert.Equal(t, v1alpha1.RouteAdvertisementPermit, advertisement.Spec.Rules[0].Action)
+	assert.Equal(t, v1alpha1.RouteAdvertisementPrefixGE, advertisement.Spec.Rules[0].PrefixOperator)
+	assert.Equal(t, v1alpha1.RouteAdvertisementDeny, advertisement.Spec.Rules[1].Action)
+
+	routeMap := &v1alpha1.RouteMap{ObjectMeta: meta, Spec: v1alpha1.RouteMapSpec{Entries: []v1alpha1.RouteMapEntry{{}}}}
+	d.Default(routeMap, true)
+	assert.Equal(t, v1alpha1.RouteAdvertisementPermit, routeMap.Spec.Entries[0].Action)
+}
@@ -0,0 +1,117 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package deletion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/webhook"
+)
+
+// WebhookPath is the path the deletion validating webhook is served at, the ValidatingWebhookConfiguration routes the
+// DELETE requests of the IPPools, Subnets, SubnetSets and VPCs to it.
+const WebhookPath = "/validate-nsx-vmware-com-v1alpha1-deletion"
+
+// maxBlockers is the number of the dependents listed in the denial, the others are counted.
+const maxBlockers = 10
+
+var log = logger.Log
+
+// Validator rejects the deletion of the CRs which other CRs of their Namespace still depend on: the IPPools with
+// IPAddressAllocations, the Subnets and SubnetSets with SubnetPorts, and the VPCs with Subnets, SubnetSets or
+// SubnetPorts. The NSX resources of the CRs can't be deleted before the ones of their dependents, the CRs would
+// otherwise be stuck on their finalizers. The denial lists the dependents to delete first.
+type Validator struct {
+	Client client.Client
+}
+
+func NewValidator(c client.Client) *Validator {
+	return &Validator{Client: c}
+}
+
+// Handle rejects the deletion of the CR of the request if it has dependents.
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Delete {
+		return admission.Allowed("")
+	}
+	blockers, err := v.Blockers(ctx, req.Kind.Kind, req.Namespace, req.Name)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(blockers) == 0 {
+		return admission.Allowed("")
+	}
+	log.Info("rejected deletion of CR in use", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name, "blockers", len(blockers))
+	msg := strings.Join(blockers, ", ")
+	if len(blockers) > maxBlockers {
+		msg = fmt.Sprintf("%s and %d more", strings.Join(blockers[:maxBlockers], ", "), len(blockers)-maxBlockers)
+	}
+	return admission.Denied(fmt.Sprintf("%s %s/%s is in use by %s, delete them first", req.Kind.Kind, req.Namespace, req.Name, msg))
+}
+
+// Blockers returns the dependents of the CR of kind in the Namespace as "Kind name", in the order they are listed.
+func (v *Validator) Blockers(ctx context.Context, kind, namespace, name string) ([]string, error) {
+	var blockers []string
+	switch kind {
+	case "IPPool":
+		allocations := &v1alpha1.IPAddressAllocationList{}
+		if err := v.Client.List(ctx, allocations, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for _, allocation := range allocations.Items {
+			if allocation.Spec.IPPoolName == name {
+				blockers = append(blockers, "IPAddressAllocation "+allocation.Name)
+			}
+		}
+	case "Subnet", "SubnetSet":
+		ports := &v1alpha1.SubnetPortList{}
+		if err := v.Client.List(ctx, ports, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for _, port := range ports.Items {
+			if (kind == "Subnet" && port.Spec.Subnet == name) || (kind == "SubnetSet" && port.Spec.SubnetSet == name) {
+				blockers = append(blockers, "SubnetPort "+port.Name)
+			}
+		}
+	case "VPC":
+		subnets := &v1alpha1.SubnetList{}
+		if err := v.Client.List(ctx, subnets, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for _, subnet := range subnets.Items {
+			blockers = append(blockers, "Subnet "+subnet.Name)
+		}
+		subnetSets := &v1alpha1.SubnetSetList{}
+		if err := v.Client.List(ctx, subnetSets, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for _, subnetSet := range subnetSets.Items {
+			blockers = append(blockers, "SubnetSet "+subnetSet.Name)
+		}
+		ports := &v1alpha1.SubnetPortList{}
+		if err := v.Client.List(ctx, ports, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for _, port := range ports.Items {
+			blockers = append(blockers, "SubnetPort "+port.Name)
+		}
+	}
+	return blockers, nil
+}
+
+// SetupWithManager serves the webhook with the webhook server of the manager.
+func (v *Validator) SetupWithManager(mgr ctrl.Manager) error {
+	webhook.Register(mgr, WebhookPath, v)
+	return nil
+}
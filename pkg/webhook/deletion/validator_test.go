@@ -0,0 +1,84 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package deletion
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+)
+
+func newRequest(operation admissionv1.Operation, kind, namespace, name string) admission.Request {
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: v1alpha1.GroupVersion.Group, Version: v1alpha1.GroupVersion.Version, Kind: kind},
+		Operation: operation,
+		Namespace: namespace,
+		Name:      name,
+	}}
+}
+
+func TestValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	objs := []client.Object{
+		&v1alpha1.IPAddressAllocation{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "allocation1"}, Spec: v1alpha1.IPAddressAllocationSpec{IPPoolName: "pool1"}},
+		&v1alpha1.IPAddressAllocation{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "allocation2"}, Spec: v1alpha1.IPAddressAllocationSpec{IPPoolName: "pool1"}},
+		&v1alpha1.Subnet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "subnet1"}},
+		&v1alpha1.SubnetPort{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "port1"}, Spec: v1alpha1.SubnetPortSpec{Subnet: "subnet1"}},
+	}
+	for i := 0; i < 12; i++ {
+		objs = append(objs, &v1alpha1.SubnetPort{ObjectMeta: metav1.ObjectMeta{Namespace: "ns3", Name: fmt.Sprintf("port%02d", i)}, Spec: v1alpha1.SubnetPortSpec{SubnetSet: "subnetset1"}})
+	}
+	v := NewValidator(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build())
+
+	tests := []struct {
+		name    string
+		req     admission.Request
+		allowed bool
+		message string
+	}{
+		{name: "not a deletion", req: newRequest(admissionv1.Create, "IPPool", "ns1", "pool1"), allowed: true},
+		{
+			name:    "IPPool with allocation",
+			req:     newRequest(admissionv1.Delete, "IPPool", "ns1", "pool1"),
+			message: "IPPool ns1/pool1 is in use by IPAddressAllocation allocation1, delete them first",
+		},
+		{name: "IPPool without allocations", req: newRequest(admissionv1.Delete, "IPPool", "ns1", "pool2"), allowed: true},
+		{
+			name:    "Subnet with port",
+			req:     newRequest(admissionv1.Delete, "Subnet", "ns1", "subnet1"),
+			message: "Subnet ns1/subnet1 is in use by SubnetPort port1, delete them first",
+		},
+		{
+			name:    "SubnetSet with many ports",
+			req:     newRequest(admissionv1.Delete, "SubnetSet", "ns3", "subnetset1"),
+			message: "SubnetSet ns3/subnetset1 is in use by SubnetPort port00, SubnetPort port01, SubnetPort port02, SubnetPort port03, SubnetPort port04, SubnetPort port05, SubnetPort port06, SubnetPort port07, SubnetPort port08, SubnetPort port09 and 2 more, delete them first",
+		},
+		{
+			name:    "VPC with Subnet and port",
+			req:     newRequest(admissionv1.Delete, "VPC", "ns1", "vpc1"),
+			message: "VPC ns1/vpc1 is in use by Subnet subnet1, SubnetPort port1, delete them first",
+		},
+		{name: "VPC without Subnets", req: newRequest(admissionv1.Delete, "VPC", "ns2", "vpc2"), allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := v.Handle(context.TODO(), tt.req)
+			assert.Equal(t, tt.allowed, resp.Allowed)
+			if !tt.allowed {
+				assert.Equal(t, tt.message, string(resp.Result.Reason))
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+// AnnotationBypass set to "true" on an object admits its requests without the webhooks of NSX Operator, if the user
+// of the request is permitted to bypass them. It is the emergency exit when a webhook wrongly rejects the requests.
+const AnnotationBypass = "nsx.vmware.com/webhook-bypass"
+
+// guard guards the webhooks registered by Register, it is set by SetGuard before the webhooks are registered.
+var guard *Guard
+
+// Guard admits the requests the webhooks must not handle: the ones in the excluded Namespaces, in case the webhook
+// configurations don't exclude them, and the ones of the objects with the bypass annotation whose user is permitted
+// to bypass the webhooks, i.e. to "bypass" the "webhooks" resource of the nsx.vmware.com group in the Namespace.
+type Guard struct {
+	Client             client.Client
+	ExcludedNamespaces sets.String
+}
+
+func NewGuard(cf *config.NSXOperatorConfig, c client.Client) *Guard {
+	return &Guard{Client: c, ExcludedNamespaces: sets.NewString(cf.WebhookExcludedNamespaces...)}
+}
+
+// SetGuard guards the webhooks registered by Register afterwards with g.
+func SetGuard(g *Guard) {
+	guard = g
+}
+
+// Register serves handler at path with the webhook server of the manager, guarded by the Guard set by SetGuard. The
+// updates changing the ImmutableFields are rejected before handler handles them.
+func Register(mgr ctrl.Manager, path string, handler admission.Handler) {
+	handler = &immutableHandler{handler: handler}
+	if guard != nil {
+		handler = guard.Wrap(handler)
+	}
+	mgr.GetWebhookServer().Register(path, &crwebhook.Admission{Handler: handler})
+}
+
+// Wrap returns handler guarded by g.
+func (g *Guard) Wrap(handler admission.Handler) admission.Handler {
+	return &guardedHandler{guard: g, handler: handler}
+}
+
+type guardedHandler struct {
+	guard   *Guard
+	handler admission.Handler
+}
+
+func (h *guardedHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	namespace := requestNamespace(req)
+	if h.guard.ExcludedNamespaces.Has(namespace) {
+		return admission.Allowed("Namespace " + namespace + " is excluded from the webhooks")
+	}
+	bypassed, err := h.guard.bypassed(ctx, req, namespace)
+	if err != nil {
+		// the webhook still handles the request if the bypass can't be authorized
+		log.Error(err, "failed to authorize the webhook bypass", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name)
+	}
+	if bypassed {
+		log.Info("bypassed the webhook", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name,
+			"operation", req.Operation, "user", req.UserInfo.Username)
+		return admission.Allowed("webhook bypassed by the " + AnnotationBypass + " annotation")
+	}
+	return h.handler.Handle(ctx, req)
+}
+
+// requestNamespace returns the Namespace of the object of the request, or its name if it is a Namespace.
+func requestNamespace(req admission.Request) string {
+	if req.Kind.Group == "" && req.Kind.Kind == "Namespace" {
+		return req.Name
+	}
+	return req.Namespace
+}
+
+// bypassed returns whether the object of the request has the bypass annotation and its user is permitted to bypass
+// the webhooks in the Namespace.
+func (g *Guard) bypassed(ctx context.Context, req admission.Request, namespace string) (bool, error) {
+	raw := req.Object.Raw
+	if req.Operation == admissionv1.Delete {
+		raw = req.OldObject.Raw
+	}
+	if len(raw) == 0 {
+		return false, nil
+	}
+	obj := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return false, err
+	}
+	if obj.Annotations[AnnotationBypass] != "true" {
+		return false, nil
+	}
+	extra := make(map[string]authorizationv1.ExtraValue, len(req.UserInfo.Extra))
+	for k, val := range req.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(val)
+	}
+	review := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace: namespace,
+			Verb:      "bypass",
+			Group:     v1alpha1.GroupVersion.Group,
+			Resource:  "webhooks",
+		},
+		User:   req.UserInfo.Username,
+		Groups: req.UserInfo.Groups,
+		UID:    req.UserInfo.UID,
+		Extra:  extra,
+	}}
+	if err := g.Client.Create(ctx, review); err != nil {
+		return false, err
+	}
+	if !review.Status.Allowed {
+		log.Info("ignored the webhook bypass of unauthorized user", "kind", req.Kind.Kind, "namespace", req.Namespace,
+			"name", req.Name, "user", req.UserInfo.Username)
+	}
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,89 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+)
+
+// fakeAuthorizationClient allows the SubjectAccessReviews of the admin user.
+type fakeAuthorizationClient struct {
+	client.Client
+}
+
+func (c *fakeAuthorizationClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	review := obj.(*authorizationv1.SubjectAccessReview)
+	attributes := review.Spec.ResourceAttributes
+	review.Status.Allowed = review.Spec.User == "admin" && attributes.Verb == "bypass" && attributes.Resource == "webhooks"
+	return nil
+}
+
+// denyingHandler rejects all the requests.
+type denyingHandler struct{}
+
+func (denyingHandler) Handle(context.Context, admission.Request) admission.Response {
+	return admission.Denied("denied")
+}
+
+func newGuardRequest(t *testing.T, operation admissionv1.Operation, kind, namespace, user string, annotations map[string]string) admission.Request {
+	raw, err := json.Marshal(&metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "obj1", Annotations: annotations}})
+	assert.Nil(t, err)
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Kind: kind},
+		Operation: operation,
+		Namespace: namespace,
+		Name:      "obj1",
+		UserInfo:  authenticationv1.UserInfo{Username: user},
+	}}
+	if operation == admissionv1.Delete {
+		req.OldObject = runtime.RawExtension{Raw: raw}
+	} else {
+		req.Object = runtime.RawExtension{Raw: raw}
+	}
+	return req
+}
+
+func TestGuard(t *testing.T) {
+	cf := &config.NSXOperatorConfig{CoeConfig: &config.CoeConfig{WebhookExcludedNamespaces: []string{"kube-system"}}}
+	handler := NewGuard(cf, &fakeAuthorizationClient{}).Wrap(denyingHandler{})
+	bypass := map[string]string{AnnotationBypass: "true"}
+
+	tests := []struct {
+		name    string
+		req     admission.Request
+		allowed bool
+	}{
+		{name: "handled", req: newGuardRequest(t, admissionv1.Create, "Subnet", "ns1", "admin", nil)},
+		{name: "excluded Namespace", req: newGuardRequest(t, admissionv1.Create, "Subnet", "kube-system", "user1", nil), allowed: true},
+		{name: "Namespace not excluded", req: newGuardRequest(t, admissionv1.Update, "Namespace", "", "user1", nil)},
+		{name: "bypassed", req: newGuardRequest(t, admissionv1.Create, "Subnet", "ns1", "admin", bypass), allowed: true},
+		{name: "bypassed deletion", req: newGuardRequest(t, admissionv1.Delete, "Subnet", "ns1", "admin", bypass), allowed: true},
+		{name: "bypass not permitted", req: newGuardRequest(t, admissionv1.Create, "Subnet", "ns1", "user1", bypass)},
+		{name: "bypass disabled", req: newGuardRequest(t, admissionv1.Create, "Subnet", "ns1", "admin", map[string]string{AnnotationBypass: "false"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := handler.Handle(context.TODO(), tt.req)
+			assert.Equal(t, tt.allowed, resp.Allowed)
+		})
+	}
+
+	// a Namespace excluded is admitted
+	req := newGuardRequest(t, admissionv1.Update, "Namespace", "", "user1", nil)
+	req.Name = "kube-system"
+	assert.True(t, handler.Handle(context.TODO(), req).Allowed)
+}
@@ -0,0 +1,139 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+)
+
+// ImmutableWebhookPath is the path the webhook enforcing only the immutable fields is served at, for the CRs no other
+// webhook validates, the ValidatingWebhookConfiguration routes the UPDATE requests of the CRs of ImmutableFields to it.
+const ImmutableWebhookPath = "/validate-nsx-vmware-com-v1alpha1-immutable"
+
+// ImmutableField is a field of a CR which can't change once the CR is created, its NSX resources are realized with it.
+type ImmutableField struct {
+	// Path of the field, e.g. spec.vpcName.
+	Path string
+	// Default is the value the field is realized with when it is omitted, so that filling it in is not a change.
+	Default interface{}
+}
+
+// ImmutableFields are the immutable fields of the CRs by group and kind, the webhooks registered by Register reject
+// the updates of the CRs changing them.
+var ImmutableFields = map[schema.GroupKind][]ImmutableField{
+	{Group: v1alpha1.GroupVersion.Group, Kind: "NSXServiceAccount"}: {{Path: "spec.vpcName"}},
+	{Group: v1alpha1.GroupVersion.Group, Kind: "Subnet"}: {
+		{Path: "spec.ipAddresses"},
+		{Path: "spec.ipv4SubnetSize"},
+		{Path: "spec.accessMode", Default: v1alpha1.AccessModePrivate},
+	},
+	{Group: v1alpha1.GroupVersion.Group, Kind: "SubnetSet"}: {
+		{Path: "spec.ipv4SubnetSize"},
+		{Path: "spec.accessMode", Default: v1alpha1.AccessModePrivate},
+	},
+	{Group: v1alpha1.GroupVersion.Group, Kind: "SubnetPort"}:          {{Path: "spec.subnet"}, {Path: "spec.subnetSet"}},
+	{Group: v1alpha1.GroupVersion.Group, Kind: "IPAddressAllocation"}: {{Path: "spec.ipPoolName"}},
+	{Group: v1alpha1.GroupVersion.Group, Kind: "IPPool"}: {
+		{Path: "spec.type", Default: v1alpha1.IPPoolTypePrivate},
+		{Path: "spec.ipBlockPath"},
+	},
+}
+
+// CheckImmutableFields returns the immutable fields of the CR of kind gk which differ between its old and new JSON.
+func CheckImmutableFields(gk schema.GroupKind, oldRaw, newRaw []byte) (field.ErrorList, error) {
+	fields := ImmutableFields[gk]
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	var oldObj, newObj map[string]interface{}
+	if err := json.Unmarshal(oldRaw, &oldObj); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(newRaw, &newObj); err != nil {
+		return nil, err
+	}
+	var errs field.ErrorList
+	for _, f := range fields {
+		oldValue, err := fieldValue(oldObj, f)
+		if err != nil {
+			return nil, err
+		}
+		newValue, err := fieldValue(newObj, f)
+		if err != nil {
+			return nil, err
+		}
+		if !equality.Semantic.DeepEqual(oldValue, newValue) {
+			errs = append(errs, field.Invalid(field.NewPath(f.Path), newValue, "field is immutable"))
+		}
+	}
+	return errs, nil
+}
+
+// fieldValue returns the value of the field in the JSON object, or its default if it is omitted. The default is
+// converted like the JSON values, e.g. the numbers to float64.
+func fieldValue(obj map[string]interface{}, f ImmutableField) (interface{}, error) {
+	var value interface{} = obj
+	for _, name := range strings.Split(f.Path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			value = nil
+			break
+		}
+		value = m[name]
+	}
+	if value != nil || f.Default == nil {
+		return value, nil
+	}
+	raw, err := json.Marshal(f.Default)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(raw, &value)
+	return value, err
+}
+
+// immutableHandler rejects the updates changing the immutable fields before handler handles them. The patches, the
+// merge patches and the server-side applies are UPDATE requests of the patched CR too.
+type immutableHandler struct {
+	handler admission.Handler
+}
+
+func (h *immutableHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Update || req.SubResource != "" {
+		return h.handler.Handle(ctx, req)
+	}
+	gk := schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind}
+	errs, err := CheckImmutableFields(gk, req.OldObject.Raw, req.Object.Raw)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if len(errs) > 0 {
+		log.Info("rejected update of immutable fields", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name,
+			"user", req.UserInfo.Username, "errors", errs.ToAggregate().Error())
+		status := apierrors.NewInvalid(gk, req.Name, errs).ErrStatus
+		return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{Allowed: false, Result: &status}}
+	}
+	return h.handler.Handle(ctx, req)
+}
+
+// RegisterImmutableWebhook serves the webhook enforcing only the immutable fields with the webhook server of the
+// manager.
+func RegisterImmutableWebhook(mgr ctrl.Manager) {
+	Register(mgr, ImmutableWebhookPath, admission.HandlerFunc(func(context.Context, admission.Request) admission.Response {
+		return admission.Allowed("")
+	}))
+}
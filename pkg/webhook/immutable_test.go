@@ -0,0 +1,119 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+)
+
+// allowingHandler admits all the requests.
+type allowingHandler struct{}
+
+func (allowingHandler) Handle(context.Context, admission.Request) admission.Response {
+	return admission.Allowed("")
+}
+
+func newUpdateRequest(t *testing.T, kind string, old, obj client.Object) admission.Request {
+	oldRaw, err := json.Marshal(old)
+	assert.Nil(t, err)
+	raw, err := json.Marshal(obj)
+	assert.Nil(t, err)
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: v1alpha1.GroupVersion.Group, Version: v1alpha1.GroupVersion.Version, Kind: kind},
+		Operation: admissionv1.Update,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Object:    runtime.RawExtension{Raw: raw},
+		OldObject: runtime.RawExtension{Raw: oldRaw},
+	}}
+}
+
+func TestImmutableFields(t *testing.T) {
+	handler := &immutableHandler{handler: allowingHandler{}}
+	meta := metav1.ObjectMeta{Namespace: "ns1", Name: "obj1"}
+	newSubnet := func(spec v1alpha1.SubnetSpec) *v1alpha1.Subnet {
+		return &v1alpha1.Subnet{ObjectMeta: meta, Spec: spec}
+	}
+
+	tests := []struct {
+		name    string
+		req     admission.Request
+		allowed bool
+		message string
+	}{
+		{
+			name:    "unchanged",
+			req:     newUpdateRequest(t, "Subnet", newSubnet(v1alpha1.SubnetSpec{IPv4SubnetSize: 64}), newSubnet(v1alpha1.SubnetSpec{IPv4SubnetSize: 64})),
+			allowed: true,
+		},
+		{
+			name:    "mutable field changed",
+			req:     newUpdateRequest(t, "Subnet", newSubnet(v1alpha1.SubnetSpec{}), newSubnet(v1alpha1.SubnetSpec{DHCPConfig: v1alpha1.DHCPConfig{EnableDHCP: true}})),
+			allowed: true,
+		},
+		{
+			name:    "default filled in",
+			req:     newUpdateRequest(t, "Subnet", newSubnet(v1alpha1.SubnetSpec{}), newSubnet(v1alpha1.SubnetSpec{AccessMode: "private"})),
+			allowed: true,
+		},
+		{
+			name:    "changed from the default",
+			req:     newUpdateRequest(t, "Subnet", newSubnet(v1alpha1.SubnetSpec{}), newSubnet(v1alpha1.SubnetSpec{AccessMode: "public"})),
+			message: `Subnet.nsx.vmware.com "obj1" is invalid: spec.accessMode: Invalid value: "public": field is immutable`,
+		},
+		{
+			name: "fields changed",
+			req: newUpdateRequest(t, "Subnet",
+				newSubnet(v1alpha1.SubnetSpec{IPv4SubnetSize: 64, IPAddresses: []string{"10.0.0.0/26"}}),
+				newSubnet(v1alpha1.SubnetSpec{IPv4SubnetSize: 32, IPAddresses: []string{"10.0.1.0/27"}})),
+			message: `Subnet.nsx.vmware.com "obj1" is invalid: [spec.ipAddresses: Invalid value: []interface {}{"10.0.1.0/27"}: field is immutable, spec.ipv4SubnetSize: Invalid value: 32: field is immutable]`,
+		},
+		{
+			name:    "field removed",
+			req:     newUpdateRequest(t, "Subnet", newSubnet(v1alpha1.SubnetSpec{IPv4SubnetSize: 64}), newSubnet(v1alpha1.SubnetSpec{})),
+			message: `Subnet.nsx.vmware.com "obj1" is invalid: spec.ipv4SubnetSize: Invalid value: "null": field is immutable`,
+		},
+		{
+			name: "NSXServiceAccount",
+			req: newUpdateRequest(t, "NSXServiceAccount",
+				&v1alpha1.NSXServiceAccount{ObjectMeta: meta, Spec: v1alpha1.NSXServiceAccountSpec{VPCName: "vpc1"}},
+				&v1alpha1.NSXServiceAccount{ObjectMeta: meta, Spec: v1alpha1.NSXServiceAccountSpec{VPCName: "vpc2"}}),
+			message: `NSXServiceAccount.nsx.vmware.com "obj1" is invalid: spec.vpcName: Invalid value: "vpc2": field is immutable`,
+		},
+		{
+			name: "kind without immutable fields",
+			req: newUpdateRequest(t, "StaticRoute",
+				&v1alpha1.StaticRoute{ObjectMeta: meta, Spec: v1alpha1.StaticRouteSpec{Network: "10.0.0.0/24"}},
+				&v1alpha1.StaticRoute{ObjectMeta: meta, Spec: v1alpha1.StaticRouteSpec{Network: "10.0.1.0/24"}}),
+			allowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := handler.Handle(context.TODO(), tt.req)
+			assert.Equal(t, tt.allowed, resp.Allowed)
+			if !tt.allowed {
+				assert.Equal(t, int32(http.StatusUnprocessableEntity), resp.Result.Code)
+				assert.Equal(t, tt.message, resp.Result.Message)
+			}
+		})
+	}
+
+	// the status updates are not checked
+	req := newUpdateRequest(t, "Subnet", newSubnet(v1alpha1.SubnetSpec{}), newSubnet(v1alpha1.SubnetSpec{AccessMode: "public"}))
+	req.SubResource = "status"
+	assert.True(t, handler.Handle(context.TODO(), req).Allowed)
+}
@@ -0,0 +1,119 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/vpc"
+	"github.com/vmware-tanzu/nsx-operator/pkg/webhook"
+)
+
+// WebhookPath is the path the Namespace validating webhook is served at, the ValidatingWebhookConfiguration routes the
+// CREATE and UPDATE requests of the Namespaces to it.
+const WebhookPath = "/validate-v1-namespace"
+
+var log = logger.Log
+
+// Validator rejects the Namespaces whose annotations select what NSX Operator can't realize: a VPCNetworkConfiguration
+// which doesn't exist, a VPC connectivity profile which is neither defined in the config nor builtin, or an
+// enforcement point which is not one of the config. The enforcement point is immutable, the NSX resources of the
+// Namespace are realized on it. Only the selections which are set or changed are checked, so that the other updates of
+// the existing Namespaces are not blocked.
+type Validator struct {
+	NSXConfig *config.NSXOperatorConfig
+	Client    client.Client
+	decoder   *admission.Decoder
+}
+
+func NewValidator(cf *config.NSXOperatorConfig, scheme *runtime.Scheme, c client.Client) (*Validator, error) {
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{NSXConfig: cf, Client: c, decoder: decoder}, nil
+}
+
+// Handle rejects the Namespace if one of the selections of its annotations is invalid or changes the enforcement
+// point.
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Kind.Kind != "Namespace" {
+		return admission.Allowed("")
+	}
+	ns := &v1.Namespace{}
+	if err := v.decoder.Decode(req, ns); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	old := &v1.Namespace{}
+	if req.Operation == admissionv1.Update {
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	msg, err := v.validate(ctx, old, ns, req.Operation == admissionv1.Update)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if msg != "" {
+		log.Info("rejected Namespace with invalid annotations", "name", ns.Name, "user", req.UserInfo.Username, "reason", msg)
+		return admission.Denied(msg)
+	}
+	return admission.Allowed("")
+}
+
+// validate returns why the selections of ns are invalid, or "" if they are valid. old is the Namespace before the
+// update.
+func (v *Validator) validate(ctx context.Context, old, ns *v1.Namespace, update bool) (string, error) {
+	if name := ns.Annotations[common.AnnotationVPCNetworkConfig]; name != "" && name != old.Annotations[common.AnnotationVPCNetworkConfig] {
+		nc := &v1alpha1.VPCNetworkConfiguration{}
+		if err := v.Client.Get(ctx, client.ObjectKey{Name: name}, nc); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return "", err
+			}
+			return fmt.Sprintf("VPCNetworkConfiguration %q of annotation %s does not exist", name, common.AnnotationVPCNetworkConfig), nil
+		}
+	}
+	if profile := vpc.GetConnectivityProfileName(ns); profile != vpc.GetConnectivityProfileName(old) || !update {
+		if _, ok := vpc.LookupConnectivityProfile(v.NSXConfig, profile); !ok {
+			return fmt.Sprintf("VPC connectivity profile %q of %s is not supported", profile, common.AnnotationVPCConnectivityProfile), nil
+		}
+	}
+	enforcementPoint, oldEnforcementPoint := v.enforcementPoint(ns), v.enforcementPoint(old)
+	if update && enforcementPoint != oldEnforcementPoint {
+		return fmt.Sprintf("annotation %s is immutable, it can't be changed from %q to %q", common.AnnotationEnforcementPoint, oldEnforcementPoint, enforcementPoint), nil
+	}
+	if !update && !v.NSXConfig.EnforcementPointAllowed(enforcementPoint) {
+		return fmt.Sprintf("enforcement point %q of annotation %s is not one of %v", enforcementPoint, common.AnnotationEnforcementPoint, v.NSXConfig.EnforcementPoints), nil
+	}
+	return "", nil
+}
+
+// enforcementPoint returns the enforcement point selected by the Namespace, the default one of the config if it
+// selects none.
+func (v *Validator) enforcementPoint(ns *v1.Namespace) string {
+	if enforcementPoint := ns.Annotations[common.AnnotationEnforcementPoint]; enforcementPoint != "" {
+		return enforcementPoint
+	}
+	return v.NSXConfig.GetEnforcementPoint()
+}
+
+// SetupWithManager serves the webhook with the webhook server of the manager.
+func (v *Validator) SetupWithManager(mgr ctrl.Manager) error {
+	webhook.Register(mgr, WebhookPath, v)
+	return nil
+}
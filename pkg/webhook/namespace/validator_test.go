@@ -0,0 +1,110 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package namespace
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/config"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/common"
+)
+
+func newNamespace(annotations map[string]string) *v1.Namespace {
+	return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Annotations: annotations}}
+}
+
+func newRequest(t *testing.T, old, ns *v1.Namespace) admission.Request {
+	raw, err := json.Marshal(ns)
+	assert.Nil(t, err)
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Namespace"},
+		Operation: admissionv1.Create,
+		Name:      ns.Name,
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+	if old != nil {
+		oldRaw, err := json.Marshal(old)
+		assert.Nil(t, err)
+		req.Operation = admissionv1.Update
+		req.OldObject = runtime.RawExtension{Raw: oldRaw}
+	}
+	return req
+}
+
+func TestValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme))
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha1.VPCNetworkConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "nc1"}},
+	).Build()
+	cf := &config.NSXOperatorConfig{
+		NsxConfig: &config.NsxConfig{EnforcementPoints: []string{"default", "ep2"}},
+		CoeConfig: &config.CoeConfig{},
+		VPCConnectivityProfiles: map[string]*config.VPCConnectivityProfile{
+			"nosnat": {Name: "nosnat", ExternalConnectivity: true},
+		},
+	}
+	v, err := NewValidator(cf, scheme, c)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name    string
+		old     *v1.Namespace
+		ns      *v1.Namespace
+		allowed bool
+	}{
+		{name: "no annotations", ns: newNamespace(nil), allowed: true},
+		{
+			name: "valid selections",
+			ns: newNamespace(map[string]string{common.AnnotationVPCNetworkConfig: "nc1",
+				common.AnnotationVPCConnectivityProfile: "nosnat", common.AnnotationEnforcementPoint: "ep2"}),
+			allowed: true,
+		},
+		{name: "unknown VPCNetworkConfiguration", ns: newNamespace(map[string]string{common.AnnotationVPCNetworkConfig: "nc2"})},
+		{name: "unknown connectivity profile", ns: newNamespace(map[string]string{common.AnnotationVPCConnectivityProfile: "snat"})},
+		{name: "unknown enforcement point", ns: newNamespace(map[string]string{common.AnnotationEnforcementPoint: "ep3"})},
+		{
+			name:    "builtin connectivity profile changed",
+			old:     newNamespace(nil),
+			ns:      newNamespace(map[string]string{common.AnnotationVPCConnectivityProfile: "public"}),
+			allowed: true,
+		},
+		{
+			name: "enforcement point changed",
+			old:  newNamespace(nil),
+			ns:   newNamespace(map[string]string{common.AnnotationEnforcementPoint: "ep2"}),
+		},
+		{
+			name:    "default enforcement point set",
+			old:     newNamespace(nil),
+			ns:      newNamespace(map[string]string{common.AnnotationEnforcementPoint: "default"}),
+			allowed: true,
+		},
+		{
+			name:    "unchanged invalid selection",
+			old:     newNamespace(map[string]string{common.AnnotationVPCNetworkConfig: "nc2"}),
+			ns:      newNamespace(map[string]string{common.AnnotationVPCNetworkConfig: "nc2", "team": "a"}),
+			allowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := v.Handle(context.TODO(), newRequest(t, tt.old, tt.ns))
+			assert.Equal(t, tt.allowed, resp.Allowed, resp.Result)
+		})
+	}
+}
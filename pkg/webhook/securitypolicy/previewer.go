@@ -0,0 +1,103 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package securitypolicy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/securitypolicy"
+	"github.com/vmware-tanzu/nsx-operator/pkg/webhook"
+)
+
+// PreviewWebhookPath is the path the SecurityPolicy realization preview webhook is served at, the
+// MutatingWebhookConfiguration routes the CREATE and UPDATE requests of the SecurityPolicies to it with sideEffects
+// None, so that the API server routes the dry-run requests too.
+const PreviewWebhookPath = "/mutate-nsx-vmware-com-v1alpha1-securitypolicy-preview"
+
+// AnnotationRealizationPreview is set on the SecurityPolicies of the dry-run requests to the JSON of what realizing
+// them would create on NSX.
+const AnnotationRealizationPreview = "nsx.vmware.com/realization-preview"
+
+// previewUID stands for the UID of the SecurityPolicies created, the API server assigns it after the mutating
+// webhooks, in the NSX IDs of the preview.
+const previewUID = "UID"
+
+// PreviewService builds what realizing a SecurityPolicy would create on NSX without calling NSX.
+type PreviewService interface {
+	PreviewSecurityPolicy(obj *v1alpha1.SecurityPolicy) (*securitypolicy.RealizationPreview, error)
+}
+
+// Previewer annotates the SecurityPolicies of the dry-run requests, e.g. of `kubectl apply --dry-run=server`, with
+// the NSX paths of the security policy and the groups, and the number of the rules, realizing them would create, and
+// how many of the rules and groups realized of them would change. It removes the annotation from the SecurityPolicies
+// of the other requests, so that the preview of a dry-run output applied is not stored.
+type Previewer struct {
+	Service PreviewService
+	decoder *admission.Decoder
+}
+
+func NewPreviewer(scheme *runtime.Scheme, service PreviewService) (*Previewer, error) {
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return &Previewer{Service: service, decoder: decoder}, nil
+}
+
+// Handle patches the SecurityPolicy of a dry-run request with its realization preview, the requests are admitted with
+// a warning if it can't be built.
+func (p *Previewer) Handle(_ context.Context, req admission.Request) admission.Response {
+	if req.Kind.Kind != "SecurityPolicy" {
+		return admission.Allowed("")
+	}
+	policy := &v1alpha1.SecurityPolicy{}
+	if err := p.decoder.Decode(req, policy); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if req.DryRun == nil || !*req.DryRun {
+		if _, ok := policy.Annotations[AnnotationRealizationPreview]; !ok {
+			return admission.Allowed("")
+		}
+		delete(policy.Annotations, AnnotationRealizationPreview)
+		return patchResponse(req, policy)
+	}
+	previewed := policy.DeepCopy()
+	if previewed.UID == "" {
+		previewed.UID = previewUID
+	}
+	preview, err := p.Service.PreviewSecurityPolicy(previewed)
+	if err != nil {
+		return admission.Allowed("").WithWarnings("SecurityPolicy " + policy.Namespace + "/" + policy.Name + " can't be realized: " + err.Error())
+	}
+	previewJSON, err := json.Marshal(preview)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if policy.Annotations == nil {
+		policy.Annotations = map[string]string{}
+	}
+	policy.Annotations[AnnotationRealizationPreview] = string(previewJSON)
+	return patchResponse(req, policy)
+}
+
+func patchResponse(req admission.Request, policy *v1alpha1.SecurityPolicy) admission.Response {
+	patched, err := json.Marshal(policy)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, patched)
+}
+
+// SetupWithManager serves the webhook with the webhook server of the manager.
+func (p *Previewer) SetupWithManager(mgr ctrl.Manager) error {
+	webhook.Register(mgr, PreviewWebhookPath, p)
+	return nil
+}
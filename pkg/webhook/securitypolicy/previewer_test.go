@@ -0,0 +1,76 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package securitypolicy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/nsx/services/securitypolicy"
+)
+
+// fakePreviewService previews the SecurityPolicies with rules, and fails to build the others.
+type fakePreviewService struct {
+	uid string
+}
+
+func (s *fakePreviewService) PreviewSecurityPolicy(obj *v1alpha1.SecurityPolicy) (*securitypolicy.RealizationPreview, error) {
+	s.uid = string(obj.UID)
+	if len(obj.Spec.Rules) == 0 {
+		return nil, errors.New("no rules")
+	}
+	return &securitypolicy.RealizationPreview{
+		Path:      "/infra/domains/k8scl-one/security-policies/sp_" + string(obj.UID),
+		Groups:    []string{"/infra/domains/k8scl-one/groups/sp_" + string(obj.UID) + "_scope"},
+		RuleCount: len(obj.Spec.Rules),
+	}, nil
+}
+
+func TestPreviewer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	service := &fakePreviewService{}
+	p, err := NewPreviewer(scheme, service)
+	assert.Nil(t, err)
+	dryRun := true
+
+	// the dry-run of the creation is annotated with the preview
+	policy := newPolicy("ns1", 10)
+	policy.Spec.Rules = []v1alpha1.SecurityPolicyRule{{Name: "rule1"}}
+	req := newRequest(t, nil, policy)
+	req.DryRun = &dryRun
+	resp := p.Handle(context.TODO(), req)
+	assert.True(t, resp.Allowed)
+	assert.Equal(t, previewUID, service.uid)
+	assert.Len(t, resp.Patches, 1)
+	assert.Equal(t, []string{"add", "/metadata/annotations"}, []string{resp.Patches[0].Operation, resp.Patches[0].Path})
+	assert.Equal(t, map[string]interface{}{
+		AnnotationRealizationPreview: `{"path":"/infra/domains/k8scl-one/security-policies/sp_UID","groups":["/infra/domains/k8scl-one/groups/sp_UID_scope"],"ruleCount":1,"changedRules":0,"staleRules":0,"changedGroups":0,"staleGroups":0}`,
+	}, resp.Patches[0].Value)
+
+	// the SecurityPolicy which can't be realized is admitted with a warning
+	policy.Spec.Rules = nil
+	req = newRequest(t, nil, policy)
+	req.DryRun = &dryRun
+	resp = p.Handle(context.TODO(), req)
+	assert.True(t, resp.Allowed)
+	assert.Empty(t, resp.Patches)
+	assert.Equal(t, []string{"SecurityPolicy ns1/policy1 can't be realized: no rules"}, resp.Warnings)
+
+	// the preview is removed from the SecurityPolicies stored
+	policy.Annotations = map[string]string{AnnotationRealizationPreview: "{}", "team": "app"}
+	resp = p.Handle(context.TODO(), newRequest(t, nil, policy))
+	assert.True(t, resp.Allowed)
+	assert.Len(t, resp.Patches, 1)
+	assert.Equal(t, []string{"remove", "/metadata/annotations/nsx.vmware.com~1realization-preview"}, []string{resp.Patches[0].Operation, resp.Patches[0].Path})
+	delete(policy.Annotations, AnnotationRealizationPreview)
+	resp = p.Handle(context.TODO(), newRequest(t, nil, policy))
+	assert.True(t, resp.Allowed)
+	assert.Empty(t, resp.Patches)
+}
@@ -0,0 +1,113 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package securitypolicy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/webhook"
+)
+
+// WebhookPath is the path the SecurityPolicy validating webhook is served at, the ValidatingWebhookConfiguration routes
+// the CREATE and UPDATE requests of the SecurityPolicies to it.
+const WebhookPath = "/validate-nsx-vmware-com-v1alpha1-securitypolicy"
+
+var log = logger.Log
+
+// Validator rejects the SecurityPolicies whose priority is not within the SecurityPolicyPriorityRanges selecting their
+// Namespace, so that the application teams can't override the baseline SecurityPolicies of the cluster admins. The
+// priority is only checked when the SecurityPolicy is created or its priority changes, so that the other updates of
+// the existing SecurityPolicies are not blocked when a range is added.
+type Validator struct {
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+func NewValidator(scheme *runtime.Scheme, c client.Client) (*Validator, error) {
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{Client: c, decoder: decoder}, nil
+}
+
+// Handle rejects the SecurityPolicy if its priority is out of a range of its Namespace.
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Kind.Kind != "SecurityPolicy" {
+		return admission.Allowed("")
+	}
+	policy := &v1alpha1.SecurityPolicy{}
+	if err := v.decoder.Decode(req, policy); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if req.Operation == admissionv1.Update {
+		old := &v1alpha1.SecurityPolicy{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if old.Spec.Priority == policy.Spec.Priority {
+			return admission.Allowed("")
+		}
+	}
+	msg, err := v.checkPriority(ctx, policy)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if msg != "" {
+		log.Info("rejected SecurityPolicy with priority out of range", "namespace", policy.Namespace, "name", policy.Name, "user", req.UserInfo.Username, "reason", msg)
+		return admission.Denied(msg)
+	}
+	return admission.Allowed("")
+}
+
+// checkPriority returns why the priority of the SecurityPolicy is not allowed in its Namespace, or "" if it is.
+func (v *Validator) checkPriority(ctx context.Context, policy *v1alpha1.SecurityPolicy) (string, error) {
+	ranges := &v1alpha1.SecurityPolicyPriorityRangeList{}
+	if err := v.Client.List(ctx, ranges); err != nil {
+		return "", err
+	}
+	if len(ranges.Items) == 0 {
+		return "", nil
+	}
+	ns := &v1.Namespace{}
+	if err := v.Client.Get(ctx, client.ObjectKey{Name: policy.Namespace}, ns); err != nil {
+		return "", err
+	}
+	for _, priorityRange := range ranges.Items {
+		if priorityRange.Spec.NamespaceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(priorityRange.Spec.NamespaceSelector)
+			if err != nil {
+				log.Error(err, "invalid namespaceSelector of SecurityPolicyPriorityRange", "name", priorityRange.Name)
+				continue
+			}
+			if !selector.Matches(labels.Set(ns.Labels)) {
+				continue
+			}
+		}
+		if policy.Spec.Priority < priorityRange.Spec.Min || policy.Spec.Priority > priorityRange.Spec.Max {
+			return fmt.Sprintf("priority %d is not within [%d, %d] allowed in Namespace %s by SecurityPolicyPriorityRange %s",
+				policy.Spec.Priority, priorityRange.Spec.Min, priorityRange.Spec.Max, policy.Namespace, priorityRange.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// SetupWithManager serves the webhook with the webhook server of the manager.
+func (v *Validator) SetupWithManager(mgr ctrl.Manager) error {
+	webhook.Register(mgr, WebhookPath, v)
+	return nil
+}
@@ -0,0 +1,108 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package securitypolicy
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+)
+
+func newPolicy(namespace string, priority int) *v1alpha1.SecurityPolicy {
+	return &v1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "policy1"},
+		Spec:       v1alpha1.SecurityPolicySpec{Priority: priority},
+	}
+}
+
+func newRequest(t *testing.T, old, policy *v1alpha1.SecurityPolicy) admission.Request {
+	raw, err := json.Marshal(policy)
+	assert.Nil(t, err)
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: v1alpha1.GroupVersion.Group, Version: v1alpha1.GroupVersion.Version, Kind: "SecurityPolicy"},
+		Operation: admissionv1.Create,
+		Namespace: policy.Namespace,
+		Name:      policy.Name,
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+	if old != nil {
+		oldRaw, err := json.Marshal(old)
+		assert.Nil(t, err)
+		req.Operation = admissionv1.Update
+		req.OldObject = runtime.RawExtension{Raw: oldRaw}
+	}
+	return req
+}
+
+func TestValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme))
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app1", Labels: map[string]string{"tenant": "application"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+		&v1alpha1.SecurityPolicyPriorityRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "application-teams"},
+			Spec: v1alpha1.SecurityPolicyPriorityRangeSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "application"}},
+				Min:               100,
+				Max:               1000,
+			},
+		},
+		&v1alpha1.SecurityPolicyPriorityRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "all"},
+			Spec:       v1alpha1.SecurityPolicyPriorityRangeSpec{Min: 0, Max: 900},
+		},
+	).Build()
+	v, err := NewValidator(scheme, c)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name    string
+		old     *v1alpha1.SecurityPolicy
+		policy  *v1alpha1.SecurityPolicy
+		allowed bool
+		message string
+	}{
+		{name: "within the ranges", policy: newPolicy("app1", 500), allowed: true},
+		{
+			name:    "below the range of the Namespace",
+			policy:  newPolicy("app1", 10),
+			message: "priority 10 is not within [100, 1000] allowed in Namespace app1 by SecurityPolicyPriorityRange application-teams",
+		},
+		{
+			name:    "above the range of all the Namespaces",
+			policy:  newPolicy("app1", 950),
+			message: "priority 950 is not within [0, 900] allowed in Namespace app1 by SecurityPolicyPriorityRange all",
+		},
+		{name: "Namespace not selected", policy: newPolicy("admin", 10), allowed: true},
+		{name: "priority unchanged", old: newPolicy("app1", 10), policy: newPolicy("app1", 10), allowed: true},
+		{
+			name:    "priority changed",
+			old:     newPolicy("app1", 500),
+			policy:  newPolicy("app1", 50),
+			message: "priority 50 is not within [100, 1000] allowed in Namespace app1 by SecurityPolicyPriorityRange application-teams",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := v.Handle(context.TODO(), newRequest(t, tt.old, tt.policy))
+			assert.Equal(t, tt.allowed, resp.Allowed)
+			if !tt.allowed {
+				assert.Equal(t, tt.message, string(resp.Result.Reason))
+			}
+		})
+	}
+}
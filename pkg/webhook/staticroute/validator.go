@@ -0,0 +1,210 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package staticroute
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/logger"
+	"github.com/vmware-tanzu/nsx-operator/pkg/webhook"
+	"github.com/vmware-tanzu/nsx-operator/pkg/webhook/cidr"
+)
+
+// WebhookPath is the path the StaticRoute validating webhook is served at, the ValidatingWebhookConfiguration routes
+// the CREATE and UPDATE requests of the StaticRoutes to it.
+const WebhookPath = "/validate-nsx-vmware-com-v1alpha1-staticroute"
+
+var log = logger.Log
+
+// Validator rejects the StaticRoutes conflicting with the routes of the VPC of their Namespace: the other StaticRoutes
+// for the same or an overlapping network, and the connected Subnets. The routes are indexed by owner in the same
+// interval index as the CIDR webhook, so the lookup of a route only visits the overlapping ones. It also rejects the
+// StaticRoutes of the Tier1 and Tier0 scopes requested by the users not permitted to program routes above the VPC.
+type Validator struct {
+	Index   *cidr.Index
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+func NewValidator(scheme *runtime.Scheme, c client.Client) (*Validator, error) {
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{Index: cidr.NewIndex(), Client: c, decoder: decoder}, nil
+}
+
+// Handle rejects the StaticRoute if its scope is not permitted for the user, or if its network duplicates or shadows a
+// route of its Namespace.
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Kind.Kind != "StaticRoute" {
+		return admission.Allowed("")
+	}
+	route := &v1alpha1.StaticRoute{}
+	if err := v.decoder.Decode(req, route); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if msg, err := v.authorizeScope(ctx, req, route); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	} else if msg != "" {
+		log.Info("rejected StaticRoute with unauthorized scope", "namespace", route.Namespace, "name", route.Name, "user", req.UserInfo.Username, "reason", msg)
+		return admission.Denied(msg)
+	}
+	conflicts, err := v.Conflicts(route)
+	if err != nil {
+		return admission.Denied(fmt.Sprintf("invalid network: %v", err))
+	}
+	if len(conflicts) > 0 {
+		log.Info("rejected StaticRoute conflicting with existing routes", "namespace", route.Namespace, "name", route.Name, "conflicts", conflicts)
+		return admission.Denied("network " + strings.Join(conflicts, ", "))
+	}
+	return admission.Allowed("")
+}
+
+// authorizeScope returns why the user of the request is not permitted to program the StaticRoute on the gateway of its
+// scope, or "" if it is. The Tier1 and Tier0 scopes require the permission to create the staticroutes/tier1 or
+// staticroutes/tier0 subresource in the Namespace, which is checked only when the scope or gateway is set or changed
+// so that the other updates of the existing routes are not blocked.
+func (v *Validator) authorizeScope(ctx context.Context, req admission.Request, route *v1alpha1.StaticRoute) (string, error) {
+	if !isGatewayScope(route.Spec.Scope) {
+		return "", nil
+	}
+	if req.Operation == admissionv1.Update {
+		old := &v1alpha1.StaticRoute{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return "", err
+		}
+		if old.Spec.Scope == route.Spec.Scope && old.Spec.Gateway == route.Spec.Gateway {
+			return "", nil
+		}
+	}
+	subresource := strings.ToLower(string(route.Spec.Scope))
+	extra := make(map[string]authorizationv1.ExtraValue, len(req.UserInfo.Extra))
+	for k, val := range req.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(val)
+	}
+	review := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace:   route.Namespace,
+			Verb:        "create",
+			Group:       v1alpha1.GroupVersion.Group,
+			Resource:    "staticroutes",
+			Subresource: subresource,
+		},
+		User:   req.UserInfo.Username,
+		Groups: req.UserInfo.Groups,
+		UID:    req.UserInfo.UID,
+		Extra:  extra,
+	}}
+	if err := v.Client.Create(ctx, review); err != nil {
+		return "", err
+	}
+	if !review.Status.Allowed {
+		return fmt.Sprintf("user %s is not permitted to create StaticRoutes of scope %s, it requires the permission to create staticroutes/%s", req.UserInfo.Username, route.Spec.Scope, subresource), nil
+	}
+	return "", nil
+}
+
+func isGatewayScope(scope v1alpha1.StaticRouteScope) bool {
+	return scope == v1alpha1.StaticRouteScopeTier1 || scope == v1alpha1.StaticRouteScopeTier0
+}
+
+// Conflicts returns the routes of the Namespace the network of the StaticRoute duplicates or shadows, the routes of
+// the Tier1 and Tier0 scopes are not programmed on the VPC so they do not conflict.
+func (v *Validator) Conflicts(route *v1alpha1.StaticRoute) ([]string, error) {
+	if route.Spec.Network == "" || isGatewayScope(route.Spec.Scope) {
+		return nil, nil
+	}
+	overlaps, err := v.Index.Overlaps(cidr.OwnerKey("StaticRoute", route.Namespace, route.Name), []string{route.Spec.Network})
+	if err != nil {
+		return nil, err
+	}
+	start, end, _ := cidr.ParseCIDR(route.Spec.Network)
+	var conflicts []string
+	for _, overlap := range overlaps {
+		parts := strings.SplitN(overlap.Owner, "/", 3)
+		if len(parts) != 3 || parts[1] != route.Namespace {
+			continue
+		}
+		kind, name := parts[0], parts[2]
+		if kind == "Subnet" {
+			conflicts = append(conflicts, fmt.Sprintf("%s shadows connected Subnet %s (%s)", overlap.Requested, name, overlap.CIDR))
+			continue
+		}
+		existingStart, existingEnd, _ := cidr.ParseCIDR(overlap.CIDR)
+		switch {
+		case existingStart == start && existingEnd == end:
+			conflicts = append(conflicts, fmt.Sprintf("%s duplicates StaticRoute %s", overlap.Requested, name))
+		case existingStart.Compare(start) <= 0 && existingEnd.Compare(end) >= 0:
+			conflicts = append(conflicts, fmt.Sprintf("%s shadows StaticRoute %s (%s)", overlap.Requested, name, overlap.CIDR))
+		default:
+			conflicts = append(conflicts, fmt.Sprintf("%s is shadowed by StaticRoute %s (%s)", overlap.Requested, name, overlap.CIDR))
+		}
+	}
+	return conflicts, nil
+}
+
+// Update indexes the network of the StaticRoute or the CIDRs of the Subnet.
+func (v *Validator) Update(obj interface{}) {
+	o, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+	kind, cidrs := cidr.CIDRsOf(o)
+	if kind != "StaticRoute" && kind != "Subnet" {
+		return
+	}
+	if route, ok := o.(*v1alpha1.StaticRoute); ok && isGatewayScope(route.Spec.Scope) {
+		// the routes above the VPC do not conflict with the routes of the Namespace
+		v.Index.Delete(cidr.OwnerKey(kind, o.GetNamespace(), o.GetName()))
+		return
+	}
+	v.Index.Set(cidr.OwnerKey(kind, o.GetNamespace(), o.GetName()), cidrs)
+}
+
+// Delete removes the routes of the StaticRoute or Subnet from the index.
+func (v *Validator) Delete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	o, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+	if kind, _ := cidr.CIDRsOf(o); kind == "StaticRoute" || kind == "Subnet" {
+		v.Index.Delete(cidr.OwnerKey(kind, o.GetNamespace(), o.GetName()))
+	}
+}
+
+// SetupWithManager indexes the routes of the existing and future StaticRoutes and Subnets from the informers of the
+// manager cache, and serves the webhook with the webhook server of the manager.
+func (v *Validator) SetupWithManager(mgr ctrl.Manager) error {
+	for _, obj := range []client.Object{&v1alpha1.StaticRoute{}, &v1alpha1.Subnet{}} {
+		informer, err := mgr.GetCache().GetInformer(context.TODO(), obj)
+		if err != nil {
+			return err
+		}
+		if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    v.Update,
+			UpdateFunc: func(_, newObj interface{}) { v.Update(newObj) },
+			DeleteFunc: v.Delete,
+		}); err != nil {
+			return err
+		}
+	}
+	webhook.Register(mgr, WebhookPath, v)
+	return nil
+}
@@ -0,0 +1,195 @@
+/* Copyright © 2023 VMware, Inc. All Rights Reserved.
+   SPDX-License-Identifier: Apache-2.0 */
+
+package staticroute
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/vmware-tanzu/nsx-operator/pkg/apis/v1alpha1"
+	"github.com/vmware-tanzu/nsx-operator/pkg/webhook/cidr"
+)
+
+func newRequest(t *testing.T, kind string, obj client.Object) admission.Request {
+	raw, err := json.Marshal(obj)
+	assert.Nil(t, err)
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: v1alpha1.GroupVersion.Group, Version: v1alpha1.GroupVersion.Version, Kind: kind},
+		Operation: admissionv1.Create,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+}
+
+// fakeAuthorizationClient allows the SubjectAccessReviews of the users for the subresources they are granted.
+type fakeAuthorizationClient struct {
+	client.Client
+	granted map[string]string
+	reviews int
+}
+
+func (c *fakeAuthorizationClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	review := obj.(*authorizationv1.SubjectAccessReview)
+	c.reviews++
+	attributes := review.Spec.ResourceAttributes
+	review.Status.Allowed = attributes.Verb == "create" && attributes.Resource == "staticroutes" &&
+		c.granted[review.Spec.User] == attributes.Subresource
+	return nil
+}
+
+func newStaticRoute(namespace, name, network string) *v1alpha1.StaticRoute {
+	return &v1alpha1.StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       v1alpha1.StaticRouteSpec{Network: network, NextHops: []v1alpha1.NextHop{{IPAddress: "10.0.0.1"}}},
+	}
+}
+
+func TestValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	v, err := NewValidator(scheme, nil)
+	assert.Nil(t, err)
+
+	subnet := &v1alpha1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1", Namespace: "ns1"},
+		Status:     v1alpha1.SubnetStatus{IPAddresses: []string{"10.0.0.0/24"}},
+	}
+	v.Update(subnet)
+	route1 := newStaticRoute("ns1", "route1", "172.16.0.0/16")
+	v.Update(route1)
+	v.Update(&v1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "ns1"},
+		Status:     v1alpha1.IPPoolStatus{Subnets: []v1alpha1.SubnetResult{{Name: "s1", CIDR: "192.168.0.0/24"}}},
+	})
+	assert.Equal(t, []string{"172.16.0.0/16"}, v.Index.Get(cidr.OwnerKey("StaticRoute", "ns1", "route1")))
+	assert.Nil(t, v.Index.Get(cidr.OwnerKey("IPPool", "ns1", "pool1")))
+
+	tests := []struct {
+		name    string
+		obj     *v1alpha1.StaticRoute
+		message string
+	}{
+		{
+			name: "route without conflict",
+			obj:  newStaticRoute("ns1", "route2", "172.17.0.0/16"),
+		},
+		{
+			name: "update of the route itself",
+			obj:  route1,
+		},
+		{
+			name:    "duplicate route",
+			obj:     newStaticRoute("ns1", "route2", "172.16.0.1/16"),
+			message: "network 172.16.0.1/16 duplicates StaticRoute route1",
+		},
+		{
+			name:    "route more specific than a route",
+			obj:     newStaticRoute("ns1", "route2", "172.16.1.0/24"),
+			message: "network 172.16.1.0/24 shadows StaticRoute route1 (172.16.0.0/16)",
+		},
+		{
+			name:    "route less specific than a route",
+			obj:     newStaticRoute("ns1", "route2", "172.0.0.0/8"),
+			message: "network 172.0.0.0/8 is shadowed by StaticRoute route1 (172.16.0.0/16)",
+		},
+		{
+			name:    "route shadowing a connected Subnet",
+			obj:     newStaticRoute("ns1", "route2", "10.0.0.128/25"),
+			message: "network 10.0.0.128/25 shadows connected Subnet subnet1 (10.0.0.0/24)",
+		},
+		{
+			name: "route conflicting with the routes of another Namespace",
+			obj:  newStaticRoute("ns2", "route1", "172.16.0.0/16"),
+		},
+		{
+			name: "route overlapping with an IPPool",
+			obj:  newStaticRoute("ns1", "route2", "192.168.0.0/16"),
+		},
+		{
+			name:    "invalid network",
+			obj:     newStaticRoute("ns1", "route2", "172.18.0.0"),
+			message: "invalid network: netip.ParsePrefix(\"172.18.0.0\"): no '/'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := v.Handle(context.TODO(), newRequest(t, "StaticRoute", tt.obj))
+			assert.Equal(t, tt.message == "", resp.Allowed, resp.Result)
+			if tt.message != "" {
+				assert.Equal(t, tt.message, string(resp.Result.Reason))
+			}
+		})
+	}
+	assert.True(t, v.Handle(context.TODO(), newRequest(t, "Subnet", subnet)).Allowed)
+
+	v.Delete(toolscache.DeletedFinalStateUnknown{Obj: route1})
+	v.Delete(subnet)
+	assert.Nil(t, v.Index.Get(cidr.OwnerKey("StaticRoute", "ns1", "route1")))
+	assert.True(t, v.Handle(context.TODO(), newRequest(t, "StaticRoute", newStaticRoute("ns1", "route2", "172.16.0.0/16"))).Allowed)
+	assert.True(t, v.Handle(context.TODO(), newRequest(t, "StaticRoute", newStaticRoute("ns1", "route2", "10.0.0.0/24"))).Allowed)
+}
+
+func TestValidator_Scope(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	authorizationClient := &fakeAuthorizationClient{granted: map[string]string{"admin": "tier0"}}
+	v, err := NewValidator(scheme, authorizationClient)
+	assert.Nil(t, err)
+	v.Update(newStaticRoute("ns1", "route1", "172.16.0.0/16"))
+
+	newScopedRequest := func(user string, scope v1alpha1.StaticRouteScope) admission.Request {
+		route := newStaticRoute("ns1", "route2", "172.16.0.0/16")
+		route.Spec.Scope = scope
+		req := newRequest(t, "StaticRoute", route)
+		req.UserInfo = authenticationv1.UserInfo{Username: user}
+		return req
+	}
+
+	// the routes above the VPC do not conflict with the routes of the Namespace
+	assert.True(t, v.Handle(context.TODO(), newScopedRequest("admin", v1alpha1.StaticRouteScopeTier0)).Allowed)
+	resp := v.Handle(context.TODO(), newScopedRequest("admin", v1alpha1.StaticRouteScopeTier1))
+	assert.False(t, resp.Allowed)
+	assert.Equal(t, "user admin is not permitted to create StaticRoutes of scope Tier1, it requires the permission to create staticroutes/tier1", string(resp.Result.Reason))
+	assert.False(t, v.Handle(context.TODO(), newScopedRequest("dev", v1alpha1.StaticRouteScopeTier0)).Allowed)
+	assert.Equal(t, 3, authorizationClient.reviews)
+
+	resp = v.Handle(context.TODO(), newScopedRequest("dev", v1alpha1.StaticRouteScopeVPC))
+	assert.False(t, resp.Allowed)
+	assert.Equal(t, "network 172.16.0.0/16 duplicates StaticRoute route1", string(resp.Result.Reason))
+	assert.Equal(t, 3, authorizationClient.reviews)
+
+	// the updates keeping the scope and gateway are not reviewed
+	req := newScopedRequest("dev", v1alpha1.StaticRouteScopeTier0)
+	req.Operation = admissionv1.Update
+	req.OldObject = req.Object
+	assert.True(t, v.Handle(context.TODO(), req).Allowed)
+	assert.Equal(t, 3, authorizationClient.reviews)
+
+	old := newStaticRoute("ns1", "route2", "172.16.0.0/16")
+	old.Spec.Scope = v1alpha1.StaticRouteScopeTier0
+	old.Spec.Gateway = "t0"
+	raw, err := json.Marshal(old)
+	assert.Nil(t, err)
+	req.OldObject = runtime.RawExtension{Raw: raw}
+	assert.False(t, v.Handle(context.TODO(), req).Allowed)
+	assert.Equal(t, 4, authorizationClient.reviews)
+
+	// the route is no longer indexed once it moves above the VPC
+	route1 := newStaticRoute("ns1", "route1", "172.16.0.0/16")
+	route1.Spec.Scope = v1alpha1.StaticRouteScopeTier0
+	v.Update(route1)
+	assert.Nil(t, v.Index.Get(cidr.OwnerKey("StaticRoute", "ns1", "route1")))
+}